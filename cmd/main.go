@@ -5,6 +5,7 @@ package main
 import (
 	"andorralee/internal/config"
 	"andorralee/internal/handlers"
+	"andorralee/internal/services"
 	"andorralee/routers" // 导入路由包
 	"fmt"
 	"os"
@@ -24,35 +25,96 @@ func main() {
 		fmt.Println("创建dm_home目录失败:", err)
 	}
 
+	// 数据库迁移命令: andorralee migrate status|up|down，执行后退出，不启动服务
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+
 	// 初始化配置
 	// 尝试初始化Docker客户端，但允许失败
 	if err := config.InitDockerClient(); err != nil {
 		fmt.Println("警告: Docker服务未启动或不可用，部分功能将不可用")
 	}
 
-	// 尝试初始化MySQL，但允许失败
-	if err := config.InitMySQL(); err != nil {
-		fmt.Println("警告: MySQL数据库连接失败，相关功能将不可用")
+	// 尝试初始化主数据库(由DB_BACKEND决定MySQL、SQLite或达梦)，但允许失败
+	if err := config.InitDatabase(); err != nil {
+		fmt.Println("警告: 数据库连接失败，相关功能将不可用:", err)
 	} else {
 		// 初始化数据库表
 		if err := config.InitTables(); err != nil {
-			fmt.Println("警告: MySQL数据库表初始化失败，相关功能可能不可用:", err)
+			fmt.Println("警告: 数据库表初始化失败，相关功能可能不可用:", err)
 		}
 	}
 
-	// 尝试初始化达梦数据库，但允许失败
-	if err := config.InitDameng(); err != nil {
-		fmt.Println("警告: 达梦数据库连接失败，相关功能将不可用")
-	} else {
-		fmt.Println("达梦数据库连接成功！")
+	// 主数据库不是达梦时，尝试单独初始化达梦数据库，但允许失败
+	if config.DamengDB == nil {
+		if err := config.InitDameng(); err != nil {
+			fmt.Println("警告: 达梦数据库连接失败，相关功能将不可用")
+		} else {
+			fmt.Println("达梦数据库连接成功！")
+			if err := config.InitDamengTables(); err != nil {
+				fmt.Println("警告: 达梦数据库表初始化失败，相关功能可能不可用:", err)
+			}
+		}
 	}
 
 	// 初始化路由
 	r := routers.SetupRouter() // 通过路由包获取已配置的 Gin 引擎
 
 	// 初始化默认蜜签
-	handlers.CreateDefaultHoneyTokens()
-	fmt.Println("✅ 默认蜜签初始化完成")
+	if err := handlers.CreateDefaultHoneyTokens(); err != nil {
+		fmt.Println("警告: 默认蜜签初始化失败:", err)
+	} else {
+		fmt.Println("✅ 默认蜜签初始化完成")
+	}
+
+	// 定期结束空闲超时的攻击会话
+	services.StartAttackSessionReaper()
+
+	// 定期按保留策略归档并删除过期日志
+	if config.DB != nil {
+		services.StartRetentionJob()
+	}
+
+	// 启动时及定期对账Docker容器与蜜罐实例记录
+	if config.DockerCli != nil {
+		services.StartInstanceReconciler()
+	}
+
+	// 按需自动启动容器日志采集
+	if os.Getenv("LOG_INGESTION_AUTOSTART") == "true" {
+		if err := services.GetLogIngestionManager().Start(); err != nil {
+			fmt.Println("警告: 容器日志采集启动失败:", err)
+		}
+	}
+
+	// 按需启动内置syslog接收器，接收Docker之外的蜜罐和传感器日志
+	if os.Getenv("SYSLOG_AUTOSTART") == "true" {
+		if err := services.GetSyslogReceiver().Start(); err != nil {
+			fmt.Println("警告: syslog接收器启动失败:", err)
+		}
+	}
+
+	// 按需启动内置SSH蜜罐，不依赖Docker和蜜罐镜像
+	if os.Getenv("SSH_HONEYPOT_AUTOSTART") == "true" {
+		if err := services.GetSSHHoneypot().Start(); err != nil {
+			fmt.Println("警告: 内置SSH蜜罐启动失败:", err)
+		}
+	}
+
+	// 按需启动内置HTTP/HTTPS蜜罐
+	if os.Getenv("HTTP_HONEYPOT_AUTOSTART") == "true" {
+		if err := services.GetHTTPHoneypot().Start(); err != nil {
+			fmt.Println("警告: 内置HTTP蜜罐启动失败:", err)
+		}
+	}
+
+	// 按需启动内置Redis蜜罐，替代redispot镜像
+	if os.Getenv("REDIS_HONEYPOT_AUTOSTART") == "true" {
+		if err := services.GetRedisHoneypot().Start(); err != nil {
+			fmt.Println("警告: 内置Redis蜜罐启动失败:", err)
+		}
+	}
 
 	fmt.Println("服务启动中，监听端口: 8081...")
 	// 启动服务
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/migrations"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+const migrateUsage = `用法:
+  andorralee migrate status        查看迁移状态
+  andorralee migrate up [版本号]   执行未执行的迁移，不指定版本号时执行到最新版本
+  andorralee migrate down [数量]   回滚最近执行的迁移，默认回滚1个`
+
+// runMigrate 执行 migrate 子命令，返回进程退出码
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Println(migrateUsage)
+		return 2
+	}
+
+	// 可选的数字参数: up的目标版本或down的回滚数量
+	number := 0
+	if len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 0 {
+			fmt.Println("参数必须是非负整数:", args[1])
+			return 2
+		}
+		number = n
+	}
+
+	if err := config.InitDatabase(); err != nil {
+		fmt.Println("数据库连接失败:", err)
+		return 1
+	}
+	runner, err := migrations.NewRunner(config.DB)
+	if err != nil {
+		fmt.Println("数据库迁移初始化失败:", err)
+		return 1
+	}
+
+	var done []migrations.Status
+	switch args[0] {
+	case "status":
+		return printMigrationStatus(runner)
+	case "up":
+		done, err = runner.Up(number)
+		for _, m := range done {
+			fmt.Printf("已执行: %d_%s\n", m.Version, m.Name)
+		}
+	case "down":
+		if number == 0 {
+			number = 1
+		}
+		done, err = runner.Down(number)
+		for _, m := range done {
+			fmt.Printf("已回滚: %d_%s\n", m.Version, m.Name)
+		}
+	default:
+		fmt.Println(migrateUsage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	if len(done) == 0 {
+		fmt.Println("没有需要执行的迁移")
+	}
+	return 0
+}
+
+// printMigrationStatus 以表格输出迁移状态
+func printMigrationStatus(runner *migrations.Runner) int {
+	statuses, err := runner.Status()
+	if err != nil {
+		fmt.Println("获取迁移状态失败:", err)
+		return 1
+	}
+
+	fmt.Println("数据库类型:", runner.Backend())
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "版本\t名称\t状态\t执行时间")
+	for _, s := range statuses {
+		state, appliedAt := "未执行", "-"
+		if s.Applied {
+			state, appliedAt = "已执行", s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+	}
+	w.Flush()
+	return 0
+}
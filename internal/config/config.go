@@ -1,27 +1,43 @@
 package config
 
 import (
+	"andorralee/internal/migrations"
 	"andorralee/internal/repositories"
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 
 	"github.com/docker/docker/client"
+	"github.com/glebarez/sqlite"
 	dameng "github.com/godoes/gorm-dameng"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
 
+const (
+	// DBBackendMySQL 使用MySQL作为主数据库
+	DBBackendMySQL = "mysql"
+	// DBBackendSQLite 使用嵌入式SQLite作为主数据库，适合单节点传感器和开发环境
+	DBBackendSQLite = "sqlite"
+	// DBBackendDameng 使用达梦数据库作为主数据库
+	DBBackendDameng = "dameng"
+)
+
 var (
 	DockerCli *client.Client
 	MySQLDB   *gorm.DB
 	DamengDB  *gorm.DB
+	// DB 主数据库连接，由DB_BACKEND决定使用MySQL、SQLite还是达梦
+	DB *gorm.DB
 )
 
 // Config 应用配置
 type Config struct {
-	MySQL struct {
+	DBBackend   string // 主数据库类型: mysql/sqlite/dameng
+	AutoMigrate bool   // 启动时是否自动执行未执行的数据库迁移
+	MySQL       struct {
 		Host     string
 		Port     string
 		User     string
@@ -35,11 +51,67 @@ type Config struct {
 		Password string
 		Database string
 	}
+	SQLite struct {
+		Path string // 数据库文件路径
+	}
+	Syslog SyslogConfig
+	SSH    SSHHoneypotConfig
+	HTTP   HTTPHoneypotConfig
+	Redis  RedisHoneypotConfig
+}
+
+// SyslogConfig 内置syslog接收器配置，监听地址为空表示不启用对应传输方式
+type SyslogConfig struct {
+	UDPAddr     string // UDP监听地址，如 :5514
+	TCPAddr     string // TCP监听地址
+	TLSAddr     string // TLS监听地址
+	TLSCertFile string // TLS服务端证书
+	TLSKeyFile  string // TLS服务端私钥
+	TLSCAFile   string // 校验传感器客户端证书的CA，配置后要求客户端提供证书
+	Sensors     string // 源IP到传感器标识的映射，如 10.0.0.5=dmz-ssh,10.0.0.6=lab-web
+	Parsers     string // 应用名到解析器的映射，如 sshd-honeypot=cowrie
+}
+
+// SSHHoneypotConfig 内置SSH蜜罐配置
+type SSHHoneypotConfig struct {
+	Addr          string // 监听地址，如 :2222
+	Banner        string // 服务端版本字符串，必须以SSH-2.0-开头
+	LoginBanner   string // 认证前发送给客户端的提示信息，为空时不发送
+	HostKeys      string // 主机私钥文件，逗号分隔，为空时在HostKeyDir中生成并复用
+	HostKeyDir    string // 自动生成的主机私钥目录
+	AuthPolicy    string // 认证策略: reject(全部拒绝)、accept(全部接受)、credentials(只接受Credentials中的口令)
+	Credentials   string // credentials策略接受的口令，如 root:123456,admin:admin
+	MaxAuthTries  int    // 每个连接最多尝试认证的次数
+	MaxSessions   int    // 同时保持的最大连接数
+	Hostname      string // 认证成功后模拟shell使用的主机名
+	ContainerName string // 写入日志的蜜罐名称
+}
+
+// HTTPHoneypotConfig 内置HTTP/HTTPS蜜罐配置，监听地址为空表示不启用对应协议
+type HTTPHoneypotConfig struct {
+	Addr          string // HTTP监听地址，如 :8088
+	TLSAddr       string // HTTPS监听地址，如 :8443
+	TLSCertFile   string // HTTPS证书，为空时使用启动时生成的自签名证书
+	TLSKeyFile    string // HTTPS私钥
+	Personality   string // 伪装的应用: admin、tomcat、phpmyadmin、router、wordpress
+	MaxBodySize   int    // 记录的请求体最大字节数
+	ContainerName string // 写入日志的蜜罐名称
+}
+
+// RedisHoneypotConfig 内置Redis蜜罐配置
+type RedisHoneypotConfig struct {
+	Addr          string // 监听地址，如 :6379
+	Password      string // requirepass口令，为空时不要求认证
+	Version       string // INFO中返回的Redis版本
+	MaxSessions   int    // 同时保持的最大连接数
+	ContainerName string // 写入日志的蜜罐名称
 }
 
 // LoadConfig 从环境变量加载配置
 func LoadConfig() *Config {
 	config := &Config{}
+	config.DBBackend = getEnv("DB_BACKEND", DBBackendMySQL)
+	config.AutoMigrate = getEnv("DB_AUTO_MIGRATE", "true") != "false"
 
 	// MySQL配置
 	config.MySQL.Host = getEnv("MYSQL_HOST", "localhost")
@@ -55,6 +127,48 @@ func LoadConfig() *Config {
 	config.Dameng.Password = getEnv("DAMENG_PASSWORD", "Dm123456")
 	config.Dameng.Database = getEnv("DAMENG_DATABASE", "DOCKER_OPS")
 
+	// SQLite配置
+	config.SQLite.Path = getEnv("SQLITE_PATH", "data/andorralee.db")
+
+	// syslog接收器配置
+	config.Syslog.UDPAddr = getEnv("SYSLOG_UDP_ADDR", ":5514")
+	config.Syslog.TCPAddr = getEnv("SYSLOG_TCP_ADDR", ":5514")
+	config.Syslog.TLSAddr = os.Getenv("SYSLOG_TLS_ADDR")
+	config.Syslog.TLSCertFile = os.Getenv("SYSLOG_TLS_CERT")
+	config.Syslog.TLSKeyFile = os.Getenv("SYSLOG_TLS_KEY")
+	config.Syslog.TLSCAFile = os.Getenv("SYSLOG_TLS_CA")
+	config.Syslog.Sensors = os.Getenv("SYSLOG_SENSORS")
+	config.Syslog.Parsers = os.Getenv("SYSLOG_PARSERS")
+
+	// 内置SSH蜜罐配置
+	config.SSH.Addr = getEnv("SSH_HONEYPOT_ADDR", ":2222")
+	config.SSH.Banner = getEnv("SSH_HONEYPOT_BANNER", "SSH-2.0-OpenSSH_8.2p1 Ubuntu-4ubuntu0.5")
+	config.SSH.LoginBanner = os.Getenv("SSH_HONEYPOT_LOGIN_BANNER")
+	config.SSH.HostKeys = os.Getenv("SSH_HONEYPOT_HOST_KEYS")
+	config.SSH.HostKeyDir = getEnv("SSH_HONEYPOT_HOST_KEY_DIR", "data/ssh_host_keys")
+	config.SSH.AuthPolicy = getEnv("SSH_HONEYPOT_AUTH_POLICY", "reject")
+	config.SSH.Credentials = os.Getenv("SSH_HONEYPOT_CREDENTIALS")
+	config.SSH.MaxAuthTries = getEnvInt("SSH_HONEYPOT_MAX_AUTH_TRIES", 6)
+	config.SSH.MaxSessions = getEnvInt("SSH_HONEYPOT_MAX_SESSIONS", 256)
+	config.SSH.Hostname = getEnv("SSH_HONEYPOT_HOSTNAME", "svr04")
+	config.SSH.ContainerName = getEnv("SSH_HONEYPOT_NAME", "native-ssh")
+
+	// 内置HTTP蜜罐配置
+	config.HTTP.Addr = getEnv("HTTP_HONEYPOT_ADDR", ":8088")
+	config.HTTP.TLSAddr = os.Getenv("HTTP_HONEYPOT_TLS_ADDR")
+	config.HTTP.TLSCertFile = os.Getenv("HTTP_HONEYPOT_TLS_CERT")
+	config.HTTP.TLSKeyFile = os.Getenv("HTTP_HONEYPOT_TLS_KEY")
+	config.HTTP.Personality = getEnv("HTTP_HONEYPOT_PERSONALITY", "admin")
+	config.HTTP.MaxBodySize = getEnvInt("HTTP_HONEYPOT_MAX_BODY", 64*1024)
+	config.HTTP.ContainerName = getEnv("HTTP_HONEYPOT_NAME", "native-http")
+
+	// 内置Redis蜜罐配置
+	config.Redis.Addr = getEnv("REDIS_HONEYPOT_ADDR", ":6379")
+	config.Redis.Password = os.Getenv("REDIS_HONEYPOT_PASSWORD")
+	config.Redis.Version = getEnv("REDIS_HONEYPOT_VERSION", "5.0.7")
+	config.Redis.MaxSessions = getEnvInt("REDIS_HONEYPOT_MAX_SESSIONS", 256)
+	config.Redis.ContainerName = getEnv("REDIS_HONEYPOT_NAME", "native-redis")
+
 	return config
 }
 
@@ -67,6 +181,15 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
+// getEnvInt 获取整数环境变量，不存在或格式错误时返回默认值
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
 // InitDockerClient 初始化 Docker 客户端
 func InitDockerClient() error {
 	cli, err := client.NewClientWithOpts(
@@ -107,10 +230,52 @@ func InitMySQL() error {
 	}
 
 	MySQLDB = db
+	DB = db
 	fmt.Println("MySQL 数据库连接成功")
 	return nil
 }
 
+// InitSQLite 初始化 SQLite 数据库，数据库文件不存在时自动创建
+func InitSQLite() error {
+	config := LoadConfig()
+	if dir := filepath.Dir(config.SQLite.Path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("创建SQLite数据目录失败: %v", err)
+		}
+	}
+
+	// WAL模式允许读写并发，busy_timeout避免后台任务同时写入时直接返回SQLITE_BUSY
+	dsn := config.SQLite.Path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		fmt.Println("SQLite 数据库打开失败: " + err.Error())
+		return err
+	}
+
+	DB = db
+	fmt.Println("SQLite 数据库打开成功: " + config.SQLite.Path)
+	return nil
+}
+
+// InitDatabase 根据DB_BACKEND初始化主数据库
+func InitDatabase() error {
+	switch backend := LoadConfig().DBBackend; backend {
+	case DBBackendMySQL:
+		return InitMySQL()
+	case DBBackendSQLite:
+		return InitSQLite()
+	case DBBackendDameng:
+		if err := InitDameng(); err != nil {
+			return err
+		}
+		DB = DamengDB
+		fmt.Println("达梦数据库连接成功")
+		return nil
+	default:
+		return fmt.Errorf("不支持的数据库类型: %s", backend)
+	}
+}
+
 // InitDameng 初始化达梦数据库
 func InitDameng() error {
 	config := LoadConfig()
@@ -136,45 +301,73 @@ func InitDameng() error {
 		options,
 	)
 
-	// 使用 GORM 打开达梦数据库连接
-	db, err := gorm.Open(dameng.Open(dsn), &gorm.Config{})
+	// 使用 GORM 打开达梦数据库连接，列名使用大写以兼容仓库中手写的SQL
+	db, err := gorm.Open(dameng.Open(dsn), &gorm.Config{
+		NamingStrategy: repositories.DamengNamingStrategy{},
+	})
 	if err != nil {
 		fmt.Println("达梦数据库连接失败: " + err.Error())
 		return err
 	}
+	if err := repositories.RegisterDamengCallbacks(db); err != nil {
+		return fmt.Errorf("注册达梦回调失败: %v", err)
+	}
 
 	DamengDB = db
 	// 不在这里打印连接成功消息，只在main.go中打印
 	return nil
 }
 
-// InitTables 初始化数据库表
+// InitTables 执行未执行的数据库迁移，表结构由 internal/migrations 中的版本化脚本管理
+// DB_AUTO_MIGRATE=false 时只检查并提示未执行的迁移，由运维通过 migrate 命令或接口执行
 func InitTables() error {
-	if MySQLDB == nil {
-		return fmt.Errorf("MySQL数据库未初始化")
-	}
-
-	// 自动迁移数据库表结构
-	err := MySQLDB.AutoMigrate(
-		&repositories.HoneypotTemplate{},
-		&repositories.HoneypotInstance{},
-		&repositories.HoneypotLog{},
-		&repositories.Bait{},
-		&repositories.SecurityRule{},
-		&repositories.RuleLog{},
-		&repositories.DockerImage{},
-		&repositories.DockerImageLog{},
-		&repositories.ContainerLogSegment{},
-		&repositories.DockerContainer{},
-		&repositories.HeadlingAuthLog{},
-		&repositories.CowrieLog{},
-	)
+	if DB == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	runner, err := migrations.NewRunner(DB)
+	if err != nil {
+		fmt.Println("数据库迁移初始化失败: " + err.Error())
+		return err
+	}
 
+	if !LoadConfig().AutoMigrate {
+		pending, err := runner.Pending()
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			fmt.Printf("警告: 有%d个数据库迁移未执行，请执行 migrate up\n", len(pending))
+		}
+		return nil
+	}
+
+	applied, err := runner.Up(0)
 	if err != nil {
-		fmt.Println("MySQL数据库表初始化失败: " + err.Error())
+		fmt.Println("数据库表初始化失败: " + err.Error())
 		return err
 	}
+	for _, m := range applied {
+		fmt.Printf("已执行数据库迁移: %d_%s\n", m.Version, m.Name)
+	}
 
-	fmt.Println("MySQL数据库表初始化成功")
+	fmt.Println("数据库表初始化成功")
+	return nil
+}
+
+// InitDamengTables 初始化达梦数据库中的蜜签表和记录默认蜜签是否已写入的系统设置表，主数据库不是达梦时蜜签可以单独保存在达梦中
+func InitDamengTables() error {
+	if DamengDB == nil {
+		return fmt.Errorf("达梦数据库未初始化")
+	}
+
+	if err := DamengDB.AutoMigrate(
+		&repositories.HoneyToken{},
+		&repositories.HoneyTokenTrigger{},
+		&repositories.SystemSetting{},
+	); err != nil {
+		fmt.Println("达梦数据库表初始化失败: " + err.Error())
+		return err
+	}
 	return nil
 }
@@ -30,12 +30,12 @@ func (h *BaitHandler) CreateBait(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLBaitRepo(config.MySQLDB)
+	repo := repositories.NewBaitRepo(config.DB)
 	if err := repo.Create(&bait); err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "创建诱饵失败: "+err.Error())
 		return
@@ -53,12 +53,12 @@ func (h *BaitHandler) GetBait(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLBaitRepo(config.MySQLDB)
+	repo := repositories.NewBaitRepo(config.DB)
 	bait, err := repo.GetByID(uint(id))
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取诱饵失败: "+err.Error())
@@ -70,12 +70,12 @@ func (h *BaitHandler) GetBait(c *gin.Context) {
 
 // ListBaits 列出所有诱饵
 func (h *BaitHandler) ListBaits(c *gin.Context) {
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLBaitRepo(config.MySQLDB)
+	repo := repositories.NewBaitRepo(config.DB)
 	baits, err := repo.List()
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取诱饵失败: "+err.Error())
@@ -94,12 +94,12 @@ func (h *BaitHandler) DeleteBait(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLBaitRepo(config.MySQLDB)
+	repo := repositories.NewBaitRepo(config.DB)
 	if err := repo.Delete(uint(id)); err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "删除诱饵失败: "+err.Error())
 		return
@@ -117,12 +117,12 @@ func (h *BaitHandler) MonitorBait(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLBaitRepo(config.MySQLDB)
+	repo := repositories.NewBaitRepo(config.DB)
 	bait, err := repo.GetByID(uint(id))
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取诱饵失败: "+err.Error())
@@ -143,22 +143,28 @@ func (h *BaitHandler) MonitorBait(c *gin.Context) {
 // @Description 获取所有诱饵信息
 // @Tags 诱饵管理
 // @Produce json
+// @Param limit query int false "每页数量(默认100，最大1000)"
+// @Param page query int false "页码，从1开始"
+// @Param cursor query string false "上一页返回的next_cursor"
+// @Param sort query string false "排序字段，前加-表示倒序，默认-create_time"
+// @Param start_time query string false "开始时间(RFC3339格式)"
+// @Param end_time query string false "结束时间(RFC3339格式)"
 // @Success 200 {object} utils.Response
 // @Router /baits [get]
 func GetAllBaits(c *gin.Context) {
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	spec, ok := bindQuerySpec(c, "create_time")
+	if !ok {
 		return
 	}
 
-	repo := repositories.NewMySQLBaitRepo(config.MySQLDB)
-	baits, err := repo.List()
-	if err != nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "获取诱饵失败: "+err.Error())
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	utils.ResponseSuccess(c, baits)
+	repo := repositories.NewBaitRepo(config.DB)
+	page, err := repo.Query(spec)
+	respondPage(c, page, err, "获取诱饵失败")
 }
 
 // GetBaitByID 根据ID获取诱饵
@@ -177,12 +183,12 @@ func GetBaitByID(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLBaitRepo(config.MySQLDB)
+	repo := repositories.NewBaitRepo(config.DB)
 	bait, err := repo.GetByID(uint(id))
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取诱饵失败: "+err.Error())
@@ -208,12 +214,12 @@ func CreateBait(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLBaitRepo(config.MySQLDB)
+	repo := repositories.NewBaitRepo(config.DB)
 	if err := repo.Create(&bait); err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "创建诱饵失败: "+err.Error())
 		return
@@ -248,12 +254,12 @@ func UpdateBait(c *gin.Context) {
 
 	bait.ID = uint(id)
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLBaitRepo(config.MySQLDB)
+	repo := repositories.NewBaitRepo(config.DB)
 	if err := repo.Update(&bait); err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "更新诱饵失败: "+err.Error())
 		return
@@ -278,12 +284,12 @@ func DeleteBait(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLBaitRepo(config.MySQLDB)
+	repo := repositories.NewBaitRepo(config.DB)
 	if err := repo.Delete(uint(id)); err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "删除诱饵失败: "+err.Error())
 		return
@@ -317,13 +323,13 @@ func DeployBait(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
 	// 获取诱饵信息
-	baitRepo := repositories.NewMySQLBaitRepo(config.MySQLDB)
+	baitRepo := repositories.NewBaitRepo(config.DB)
 	bait, err := baitRepo.GetByID(uint(id))
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取诱饵失败: "+err.Error())
@@ -331,7 +337,7 @@ func DeployBait(c *gin.Context) {
 	}
 
 	// 获取实例信息
-	instanceRepo := repositories.NewMySQLHoneypotInstanceRepo(config.MySQLDB)
+	instanceRepo := repositories.NewHoneypotInstanceRepo(config.DB)
 	instance, err := instanceRepo.GetByID(uint(instanceID))
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取实例失败: "+err.Error())
@@ -28,6 +28,7 @@ type CreateContainerInstanceRequest struct {
 	HoneypotName  string            `json:"honeypot_name" binding:"required"` // 蜜罐名称
 	ImageName     string            `json:"image_name" binding:"required"`    // Docker镜像名称
 	Protocol      string            `json:"protocol" binding:"required"`      // 协议类型
+	LogParser     string            `json:"log_parser"`                       // 日志解析器，为空时根据镜像自动选择
 	InterfaceType string            `json:"interface_type"`                   // 接口类型
 	PortMappings  map[string]string `json:"port_mappings"`                    // 端口映射
 	Environment   map[string]string `json:"environment"`                      // 环境变量
@@ -42,6 +43,10 @@ func CreateContainerInstance(c *gin.Context) {
 		utils.ResponseError(c, http.StatusBadRequest, "参数错误: "+err.Error())
 		return
 	}
+	if err := services.ValidateLogParser(req.LogParser); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	// 检查Docker是否可用
 	dockerAvailable := config.DockerCli != nil
@@ -116,6 +121,7 @@ func CreateContainerInstance(c *gin.Context) {
 			Image:        req.ImageName,
 			ExposedPorts: exposedPorts,
 			Env:          envVars,
+			Labels:       services.ManagedContainerLabels(req.HoneypotName, req.Protocol, services.ResolveLogParser(req.LogParser, req.ImageName)),
 		}
 
 		hostConfig := &container.HostConfig{
@@ -209,6 +215,7 @@ func CreateContainerInstance(c *gin.Context) {
 		Status:        containerStatus,
 		ImageName:     req.ImageName,
 		ImageID:       imageID,
+		LogParser:     services.ResolveLogParser(req.LogParser, req.ImageName),
 		PortMappings:  string(portMappingsJSON),
 		Environment:   string(environmentJSON),
 		CreateTime:    time.Now(),
@@ -240,11 +247,13 @@ func CreateContainerInstance(c *gin.Context) {
 		"status":           instance.Status,
 		"image_name":       instance.ImageName,
 		"image_id":         instance.ImageID,
+		"log_parser":       instance.LogParser,
 		"port_mappings":    req.PortMappings,
 		"environment":      req.Environment,
 		"create_time":      instance.CreateTime,
 		"description":      instance.Description,
 		"docker_available": dockerAvailable,
+		"storage_type":     services.InstanceStorageBackend(),
 	}
 
 	utils.ResponseSuccess(c, result)
@@ -252,19 +261,19 @@ func CreateContainerInstance(c *gin.Context) {
 
 // GetAllContainerInstances 获取所有容器实例
 func GetAllContainerInstances(c *gin.Context) {
-	service, err := services.NewHoneypotInstanceService()
-	if err != nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+	spec, ok := bindQuerySpec(c, "create_time")
+	if !ok {
 		return
 	}
 
-	instances, err := service.GetAllInstances()
+	service, err := services.NewHoneypotInstanceService()
 	if err != nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "获取容器实例失败: "+err.Error())
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
 		return
 	}
 
-	utils.ResponseSuccess(c, instances)
+	page, err := service.GetInstancesPage(spec)
+	respondPage(c, page, err, "获取容器实例失败")
 }
 
 // GetContainerInstanceByID 根据ID获取容器实例
@@ -603,6 +612,7 @@ func SyncAllContainerInstancesStatus(c *gin.Context) {
 type DeployImageToContainerRequest struct {
 	ImageName     string            `json:"image_name" binding:"required"`     // Docker镜像名称
 	ContainerName string            `json:"container_name" binding:"required"` // 容器名称
+	LogParser     string            `json:"log_parser"`                        // 日志解析器，为空时根据镜像自动选择
 	PortMappings  map[string]string `json:"port_mappings"`                     // 端口映射
 	Environment   map[string]string `json:"environment"`                       // 环境变量
 	AutoStart     bool              `json:"auto_start"`                        // 是否自动启动
@@ -615,6 +625,10 @@ func DeployImageToContainer(c *gin.Context) {
 		utils.ResponseError(c, http.StatusBadRequest, "参数错误: "+err.Error())
 		return
 	}
+	if err := services.ValidateLogParser(req.LogParser); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	// 检查Docker是否可用
 	if config.DockerCli == nil {
@@ -667,6 +681,7 @@ func DeployImageToContainer(c *gin.Context) {
 		Image:        req.ImageName,
 		ExposedPorts: exposedPorts,
 		Env:          envVars,
+		Labels:       services.ManagedContainerLabels(req.ContainerName, "", services.ResolveLogParser(req.LogParser, req.ImageName)),
 	}
 
 	hostConfig := &container.HostConfig{
@@ -745,6 +760,7 @@ func DeployImageToContainer(c *gin.Context) {
 		Status:        containerStatus,
 		ImageName:     req.ImageName,
 		ImageID:       imageInfo.ID,
+		LogParser:     services.ResolveLogParser(req.LogParser, req.ImageName),
 		PortMappings:  string(portMappingsJSON),
 		Environment:   string(environmentJSON),
 		CreateTime:    time.Now(),
@@ -771,6 +787,7 @@ func DeployImageToContainer(c *gin.Context) {
 		"status":         instance.Status,
 		"image_name":     instance.ImageName,
 		"image_id":       instance.ImageID,
+		"log_parser":     instance.LogParser,
 		"port_mappings":  req.PortMappings,
 		"environment":    req.Environment,
 		"create_time":    instance.CreateTime,
@@ -779,3 +796,25 @@ func DeployImageToContainer(c *gin.Context) {
 
 	utils.ResponseSuccess(c, result)
 }
+
+// ReconcileContainerInstances 立即对账Docker容器与实例记录，接管孤立容器并标记已消失的实例
+func ReconcileContainerInstances(c *gin.Context) {
+	result, err := services.ReconcileInstances(c.Request.Context())
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "实例对账失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, result)
+}
+
+// GetLastReconcileResult 获取最近一次对账结果
+func GetLastReconcileResult(c *gin.Context) {
+	result := services.LastReconcileResult()
+	if result == nil {
+		utils.ResponseError(c, http.StatusNotFound, "还没有执行过实例对账")
+		return
+	}
+
+	utils.ResponseSuccess(c, result)
+}
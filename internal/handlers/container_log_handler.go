@@ -15,22 +15,28 @@ import (
 // @Description 获取所有容器日志语义分析结果
 // @Tags 容器日志分析
 // @Produce json
+// @Param limit query int false "每页数量(默认100，最大1000)"
+// @Param page query int false "页码，从1开始"
+// @Param cursor query string false "上一页返回的next_cursor"
+// @Param sort query string false "排序字段，前加-表示倒序，默认-created_at"
+// @Param start_time query string false "开始时间(RFC3339格式)"
+// @Param end_time query string false "结束时间(RFC3339格式)"
 // @Success 200 {object} utils.Response
 // @Router /container-logs/segments [get]
 func GetAllContainerLogSegments(c *gin.Context) {
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	spec, ok := bindQuerySpec(c, "created_at")
+	if !ok {
 		return
 	}
 
-	repo := repositories.NewMySQLContainerLogSegmentRepo(config.MySQLDB)
-	segments, err := repo.List()
-	if err != nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "获取日志分析结果失败: "+err.Error())
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	utils.ResponseSuccess(c, segments)
+	repo := repositories.NewContainerLogSegmentRepo(config.DB)
+	page, err := repo.Query(spec)
+	respondPage(c, page, err, "获取日志分析结果失败")
 }
 
 // GetContainerLogSegmentByID 根据ID获取容器日志分析结果
@@ -49,12 +55,12 @@ func GetContainerLogSegmentByID(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLContainerLogSegmentRepo(config.MySQLDB)
+	repo := repositories.NewContainerLogSegmentRepo(config.DB)
 	segment, err := repo.GetByID(uint(id))
 	if err != nil {
 		utils.ResponseError(c, http.StatusNotFound, "日志分析结果不存在: "+err.Error())
@@ -79,12 +85,12 @@ func GetLogSegmentsByContainerID(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLContainerLogSegmentRepo(config.MySQLDB)
+	repo := repositories.NewContainerLogSegmentRepo(config.DB)
 	segments, err := repo.GetByContainerID(containerID)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取日志分析结果失败: "+err.Error())
@@ -109,12 +115,12 @@ func GetLogSegmentsByType(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLContainerLogSegmentRepo(config.MySQLDB)
+	repo := repositories.NewContainerLogSegmentRepo(config.DB)
 	segments, err := repo.GetBySegmentType(segmentType)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取日志分析结果失败: "+err.Error())
@@ -140,12 +146,12 @@ func DeleteContainerLogSegment(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLContainerLogSegmentRepo(config.MySQLDB)
+	repo := repositories.NewContainerLogSegmentRepo(config.DB)
 	if err := repo.Delete(uint(id)); err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "删除日志分析结果失败: "+err.Error())
 		return
@@ -169,12 +175,12 @@ func DeleteLogSegmentsByContainerID(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLContainerLogSegmentRepo(config.MySQLDB)
+	repo := repositories.NewContainerLogSegmentRepo(config.DB)
 	if err := repo.DeleteByContainerID(containerID); err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "删除日志分析结果失败: "+err.Error())
 		return
@@ -13,6 +13,7 @@ import (
 // PullCowrieLogsRequest 拉取Cowrie日志请求参数
 type PullCowrieLogsRequest struct {
 	ContainerID string `json:"container_id" binding:"required"` // 容器ID
+	LogPath     string `json:"log_path"`                        // 容器内cowrie.json路径，可选
 }
 
 // CowrieLogQueryRequest 查询Cowrie日志请求参数
@@ -50,35 +51,47 @@ func PullCowrieLogs(c *gin.Context) {
 		return
 	}
 
-	if err := service.PullCowrieLogs(req.ContainerID); err != nil {
+	result, err := service.PullCowrieLogs(req.ContainerID, req.LogPath)
+	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "拉取日志失败: "+err.Error())
 		return
 	}
 
-	utils.ResponseSuccess(c, "Cowrie蜜罐日志拉取成功")
+	utils.ResponseSuccess(c, map[string]interface{}{
+		"message":  "Cowrie蜜罐日志拉取成功",
+		"inserted": result.Inserted,
+		"skipped":  result.Skipped,
+		"rejected": result.Rejected,
+	})
 }
 
 // GetAllCowrieLogs 获取所有Cowrie蜜罐日志
 // @Summary 获取所有Cowrie蜜罐日志
-// @Description 获取所有Cowrie蜜罐日志记录
+// @Description 分页获取Cowrie蜜罐日志，其他查询参数按字段过滤，如 protocol=ssh&source_ip=1.2.3.4、username[like]=root
 // @Tags Cowrie蜜罐日志
 // @Produce json
+// @Param limit query int false "每页数量(默认100，最大1000)"
+// @Param page query int false "页码，从1开始"
+// @Param cursor query string false "上一页返回的next_cursor"
+// @Param sort query string false "排序字段，前加-表示倒序，默认-event_time"
+// @Param start_time query string false "开始时间(RFC3339格式)"
+// @Param end_time query string false "结束时间(RFC3339格式)"
 // @Success 200 {object} utils.Response
 // @Router /cowrie/logs [get]
 func GetAllCowrieLogs(c *gin.Context) {
-	service, err := services.NewCowrieService()
-	if err != nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+	spec, ok := bindQuerySpec(c, "event_time")
+	if !ok {
 		return
 	}
 
-	logs, err := service.GetAllLogs()
+	service, err := services.NewCowrieService()
 	if err != nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "获取日志失败: "+err.Error())
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
 		return
 	}
 
-	utils.ResponseSuccess(c, logs)
+	page, err := service.GetLogsPage(spec)
+	respondPage(c, page, err, "获取日志失败")
 }
 
 // GetCowrieLogByID 根据ID获取Cowrie蜜罐日志
@@ -338,21 +351,239 @@ func GetCowrieLogsByTimeRange(c *gin.Context) {
 	utils.ResponseSuccess(c, logs)
 }
 
+// GetCowrieLogsByEventID 根据事件类型获取Cowrie日志
+// @Summary 根据事件类型获取Cowrie日志
+// @Description 获取指定事件类型的Cowrie蜜罐日志，事件类型可省略"cowrie."前缀，如login.failed
+// @Tags Cowrie蜜罐日志
+// @Produce json
+// @Param event_id path string true "事件类型"
+// @Success 200 {object} utils.Response
+// @Router /cowrie/logs/event/{event_id} [get]
+func GetCowrieLogsByEventID(c *gin.Context) {
+	eventID, ok := services.NormalizeCowrieEventID(c.Param("event_id"))
+	if !ok {
+		utils.ResponseError(c, http.StatusBadRequest, "不支持的事件类型: "+eventID)
+		return
+	}
+
+	service, err := services.NewCowrieService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	logs, err := service.GetLogsByEventID(eventID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取日志失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, logs)
+}
+
+// GetCowrieLoginEvents 获取Cowrie登录事件
+// @Summary 获取Cowrie登录事件
+// @Description 获取登录成功或失败的事件，不指定success时返回全部登录事件
+// @Tags Cowrie蜜罐日志
+// @Produce json
+// @Param success query bool false "是否登录成功"
+// @Success 200 {object} utils.Response
+// @Router /cowrie/logins [get]
+func GetCowrieLoginEvents(c *gin.Context) {
+	service, err := services.NewCowrieService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	successStr := c.Query("success")
+	if successStr == "" {
+		succeeded, err := service.GetLoginEvents(true)
+		if err != nil {
+			utils.ResponseError(c, http.StatusInternalServerError, "获取登录事件失败: "+err.Error())
+			return
+		}
+		failed, err := service.GetLoginEvents(false)
+		if err != nil {
+			utils.ResponseError(c, http.StatusInternalServerError, "获取登录事件失败: "+err.Error())
+			return
+		}
+		utils.ResponseSuccess(c, append(succeeded, failed...))
+		return
+	}
+
+	success, err := strconv.ParseBool(successStr)
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的布尔值: "+err.Error())
+		return
+	}
+
+	logs, err := service.GetLoginEvents(success)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取登录事件失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, logs)
+}
+
+// GetCowrieCommandEvents 获取Cowrie命令事件
+// @Summary 获取Cowrie命令事件
+// @Description 获取攻击者输入的命令事件(command.input/command.failed)
+// @Tags Cowrie蜜罐日志
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /cowrie/commands [get]
+func GetCowrieCommandEvents(c *gin.Context) {
+	service, err := services.NewCowrieService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	logs, err := service.GetCommandEvents()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取命令事件失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, logs)
+}
+
+// GetCowrieFileTransfers 获取Cowrie文件传输事件
+// @Summary 获取Cowrie文件传输事件
+// @Description 获取文件下载和上传事件(session.file_download/session.file_upload)
+// @Tags Cowrie蜜罐日志
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /cowrie/file-transfers [get]
+func GetCowrieFileTransfers(c *gin.Context) {
+	service, err := services.NewCowrieService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	logs, err := service.GetFileTransferEvents()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取文件传输事件失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, logs)
+}
+
+// GetCowrieDirectTCPIPEvents 获取Cowrie端口转发事件
+// @Summary 获取Cowrie端口转发事件
+// @Description 获取攻击者通过SSH发起的direct-tcpip端口转发事件
+// @Tags Cowrie蜜罐日志
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /cowrie/tunnels [get]
+func GetCowrieDirectTCPIPEvents(c *gin.Context) {
+	service, err := services.NewCowrieService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	logs, err := service.GetDirectTCPIPEvents()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取端口转发事件失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, logs)
+}
+
+// GetCowrieEventStatistics 获取Cowrie事件类型统计
+// @Summary 获取Cowrie事件类型统计
+// @Description 按事件类型统计事件数量、独立IP数和会话数
+// @Tags Cowrie蜜罐日志
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /cowrie/event-statistics [get]
+func GetCowrieEventStatistics(c *gin.Context) {
+	service, err := services.NewCowrieService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	stats, err := service.GetEventStatistics()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取事件统计失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, stats)
+}
+
+// GetCowrieSessionReplay 获取Cowrie会话的TTY回放
+// @Summary 获取Cowrie会话的TTY回放
+// @Description 解析会话的TTY日志，返回带时间偏移的输入输出帧；format=cast时导出为asciinema v2格式
+// @Tags Cowrie蜜罐日志
+// @Produce json
+// @Param session_id path string true "会话ID"
+// @Param format query string false "导出格式(json/cast)"
+// @Success 200 {object} utils.Response
+// @Router /cowrie/sessions/{session_id}/replay [get]
+func GetCowrieSessionReplay(c *gin.Context) {
+	sessionID := c.Param("session_id")
+
+	service, err := services.NewCowrieService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	replay, err := service.GetSessionReplay(sessionID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取会话回放失败: "+err.Error())
+		return
+	}
+	if replay == nil {
+		utils.ResponseError(c, http.StatusNotFound, "会话不存在")
+		return
+	}
+
+	if c.Query("format") == "cast" {
+		cast, err := replay.ToAsciicast()
+		if err != nil {
+			utils.ResponseError(c, http.StatusInternalServerError, "导出回放失败: "+err.Error())
+			return
+		}
+		c.Header("Content-Disposition", "attachment; filename="+sessionID+".cast")
+		c.Data(http.StatusOK, "application/x-asciicast", cast)
+		return
+	}
+
+	utils.ResponseSuccess(c, replay)
+}
+
 // GetCowrieStatistics 获取Cowrie统计信息
 // @Summary 获取Cowrie统计信息
-// @Description 获取Cowrie蜜罐日志的统计信息
+// @Description 按日期和协议统计Cowrie蜜罐日志，可按容器和时间范围过滤
 // @Tags Cowrie蜜罐日志
 // @Produce json
+// @Param container_id query string false "容器ID"
+// @Param start_time query string false "开始时间(RFC3339格式)"
+// @Param end_time query string false "结束时间(RFC3339格式)"
 // @Success 200 {object} utils.Response
 // @Router /cowrie/statistics [get]
 func GetCowrieStatistics(c *gin.Context) {
+	filter, ok := bindStatisticsFilter(c)
+	if !ok {
+		return
+	}
+
 	service, err := services.NewCowrieService()
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
 		return
 	}
 
-	stats, err := service.GetStatistics()
+	stats, err := service.GetStatistics(filter)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取统计信息失败: "+err.Error())
 		return
@@ -363,19 +594,27 @@ func GetCowrieStatistics(c *gin.Context) {
 
 // GetCowrieAttackerBehavior 获取攻击者行为统计信息
 // @Summary 获取攻击者行为统计信息
-// @Description 获取攻击者行为的详细统计信息
+// @Description 获取攻击者行为的详细统计信息，可按容器和时间范围过滤
 // @Tags Cowrie蜜罐日志
 // @Produce json
+// @Param container_id query string false "容器ID"
+// @Param start_time query string false "开始时间(RFC3339格式)"
+// @Param end_time query string false "结束时间(RFC3339格式)"
 // @Success 200 {object} utils.Response
 // @Router /cowrie/attacker-behavior [get]
 func GetCowrieAttackerBehavior(c *gin.Context) {
+	filter, ok := bindStatisticsFilter(c)
+	if !ok {
+		return
+	}
+
 	service, err := services.NewCowrieService()
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
 		return
 	}
 
-	behavior, err := service.GetAttackerBehavior()
+	behavior, err := service.GetAttackerBehavior(filter)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取攻击者行为统计失败: "+err.Error())
 		return
@@ -386,10 +625,13 @@ func GetCowrieAttackerBehavior(c *gin.Context) {
 
 // GetCowrieTopAttackers 获取前N个攻击者
 // @Summary 获取前N个攻击者
-// @Description 获取攻击活动最频繁的前N个攻击者
+// @Description 获取攻击活动最频繁的前N个攻击者，可按容器和时间范围过滤
 // @Tags Cowrie蜜罐日志
 // @Produce json
 // @Param limit query int false "限制数量" default(10)
+// @Param container_id query string false "容器ID"
+// @Param start_time query string false "开始时间(RFC3339格式)"
+// @Param end_time query string false "结束时间(RFC3339格式)"
 // @Success 200 {object} utils.Response
 // @Router /cowrie/top-attackers [get]
 func GetCowrieTopAttackers(c *gin.Context) {
@@ -398,6 +640,11 @@ func GetCowrieTopAttackers(c *gin.Context) {
 	if err != nil || limit <= 0 {
 		limit = 10
 	}
+	filter, ok := bindStatisticsFilter(c)
+	if !ok {
+		return
+	}
+	filter.Limit = limit
 
 	service, err := services.NewCowrieService()
 	if err != nil {
@@ -405,7 +652,7 @@ func GetCowrieTopAttackers(c *gin.Context) {
 		return
 	}
 
-	attackers, err := service.GetTopAttackers(limit)
+	attackers, err := service.GetAttackerBehavior(filter)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取顶级攻击者失败: "+err.Error())
 		return
@@ -416,10 +663,13 @@ func GetCowrieTopAttackers(c *gin.Context) {
 
 // GetCowrieTopCommands 获取最常用的命令
 // @Summary 获取最常用的命令
-// @Description 获取使用频率最高的前N个命令
+// @Description 获取使用频率最高的前N个命令，可按容器和时间范围过滤
 // @Tags Cowrie蜜罐日志
 // @Produce json
 // @Param limit query int false "限制数量" default(10)
+// @Param container_id query string false "容器ID"
+// @Param start_time query string false "开始时间(RFC3339格式)"
+// @Param end_time query string false "结束时间(RFC3339格式)"
 // @Success 200 {object} utils.Response
 // @Router /cowrie/top-commands [get]
 func GetCowrieTopCommands(c *gin.Context) {
@@ -428,6 +678,11 @@ func GetCowrieTopCommands(c *gin.Context) {
 	if err != nil || limit <= 0 {
 		limit = 10
 	}
+	filter, ok := bindStatisticsFilter(c)
+	if !ok {
+		return
+	}
+	filter.Limit = limit
 
 	service, err := services.NewCowrieService()
 	if err != nil {
@@ -435,7 +690,7 @@ func GetCowrieTopCommands(c *gin.Context) {
 		return
 	}
 
-	commands, err := service.GetTopCommands(limit)
+	commands, err := service.GetCommandStatistics(filter)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取常用命令失败: "+err.Error())
 		return
@@ -0,0 +1,493 @@
+package handlers
+
+import (
+	"andorralee/internal/repositories"
+	"andorralee/internal/services"
+	"andorralee/pkg/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PullDionaeaLogsRequest 拉取Dionaea日志请求参数
+type PullDionaeaLogsRequest struct {
+	ContainerID string `json:"container_id" binding:"required"` // 容器ID
+	LogPath     string `json:"log_path"`                        // 容器内dionaea.json路径，可选
+}
+
+// PullDionaeaLogs 拉取Dionaea蜜罐日志
+// @Summary 拉取Dionaea蜜罐日志
+// @Description 从Dionaea容器中读取log_json输出的日志文件，解析连接、登录和下载事件并保存到数据库
+// @Tags Dionaea蜜罐日志
+// @Accept json
+// @Produce json
+// @Param payload body PullDionaeaLogsRequest true "拉取参数"
+// @Success 200 {object} utils.Response
+// @Router /dionaea/pull-logs [post]
+func PullDionaeaLogs(c *gin.Context) {
+	var req PullDionaeaLogsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	service, err := services.NewDionaeaService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	result, err := service.PullDionaeaLogs(req.ContainerID, req.LogPath)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "拉取日志失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, map[string]interface{}{
+		"message":  "Dionaea蜜罐日志拉取成功",
+		"inserted": result.Inserted,
+		"skipped":  result.Skipped,
+		"rejected": result.Rejected,
+	})
+}
+
+// GetAllDionaeaLogs 获取所有Dionaea蜜罐日志
+// @Summary 获取所有Dionaea蜜罐日志
+// @Description 获取所有Dionaea蜜罐的连接、登录和下载事件
+// @Tags Dionaea蜜罐日志
+// @Produce json
+// @Param limit query int false "每页数量(默认100，最大1000)"
+// @Param page query int false "页码，从1开始"
+// @Param cursor query string false "上一页返回的next_cursor"
+// @Param sort query string false "排序字段，前加-表示倒序，默认-event_time"
+// @Param start_time query string false "开始时间(RFC3339格式)"
+// @Param end_time query string false "结束时间(RFC3339格式)"
+// @Success 200 {object} utils.Response
+// @Router /dionaea/logs [get]
+func GetAllDionaeaLogs(c *gin.Context) {
+	spec, ok := bindQuerySpec(c, "event_time")
+	if !ok {
+		return
+	}
+
+	service, err := services.NewDionaeaService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	page, err := service.GetLogsPage(spec)
+	respondPage(c, page, err, "获取日志失败")
+}
+
+// GetDionaeaLogByID 根据ID获取Dionaea日志
+// @Summary 根据ID获取Dionaea日志
+// @Description 根据日志ID获取Dionaea蜜罐日志详情
+// @Tags Dionaea蜜罐日志
+// @Produce json
+// @Param id path int true "日志ID"
+// @Success 200 {object} utils.Response
+// @Router /dionaea/logs/{id} [get]
+func GetDionaeaLogByID(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的ID: "+err.Error())
+		return
+	}
+
+	service, err := services.NewDionaeaService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	log, err := service.GetLogByID(uint(id))
+	if err != nil {
+		utils.ResponseError(c, http.StatusNotFound, "日志不存在: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, log)
+}
+
+// GetDionaeaLogsBySession 根据连接ID获取Dionaea日志
+// @Summary 根据连接ID获取Dionaea日志
+// @Description 获取同一连接的所有事件
+// @Tags Dionaea蜜罐日志
+// @Produce json
+// @Param session_id path string true "连接ID"
+// @Success 200 {object} utils.Response
+// @Router /dionaea/logs/session/{session_id} [get]
+func GetDionaeaLogsBySession(c *gin.Context) {
+	sessionID := c.Param("session_id")
+
+	service, err := services.NewDionaeaService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	logs, err := service.GetLogsBySessionID(sessionID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取日志失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, logs)
+}
+
+// GetDionaeaLogsByContainer 根据容器ID获取Dionaea日志
+// @Summary 根据容器ID获取Dionaea日志
+// @Description 获取指定容器的所有Dionaea蜜罐日志
+// @Tags Dionaea蜜罐日志
+// @Produce json
+// @Param container_id path string true "容器ID"
+// @Success 200 {object} utils.Response
+// @Router /dionaea/logs/container/{container_id} [get]
+func GetDionaeaLogsByContainer(c *gin.Context) {
+	containerID := c.Param("container_id")
+
+	service, err := services.NewDionaeaService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	logs, err := service.GetLogsByContainerID(containerID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取日志失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, logs)
+}
+
+// GetDionaeaLogsBySourceIP 根据源IP获取Dionaea日志
+// @Summary 根据源IP获取Dionaea日志
+// @Description 获取指定源IP的所有Dionaea蜜罐日志
+// @Tags Dionaea蜜罐日志
+// @Produce json
+// @Param source_ip path string true "源IP地址"
+// @Success 200 {object} utils.Response
+// @Router /dionaea/logs/source-ip/{source_ip} [get]
+func GetDionaeaLogsBySourceIP(c *gin.Context) {
+	sourceIP := c.Param("source_ip")
+
+	service, err := services.NewDionaeaService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	logs, err := service.GetLogsBySourceIP(sourceIP)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取日志失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, logs)
+}
+
+// GetDionaeaLogsByProtocol 根据服务模块获取Dionaea日志
+// @Summary 根据服务模块获取Dionaea日志
+// @Description 获取指定服务模块(httpd/ftpd/smbd等)的Dionaea蜜罐日志
+// @Tags Dionaea蜜罐日志
+// @Produce json
+// @Param protocol path string true "服务模块"
+// @Success 200 {object} utils.Response
+// @Router /dionaea/logs/protocol/{protocol} [get]
+func GetDionaeaLogsByProtocol(c *gin.Context) {
+	protocol := c.Param("protocol")
+
+	service, err := services.NewDionaeaService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	logs, err := service.GetLogsByProtocol(protocol)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取日志失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, logs)
+}
+
+// GetDionaeaLogsByUsername 根据用户名获取Dionaea日志
+// @Summary 根据用户名获取Dionaea日志
+// @Description 获取使用指定用户名的登录事件
+// @Tags Dionaea蜜罐日志
+// @Produce json
+// @Param username path string true "用户名"
+// @Success 200 {object} utils.Response
+// @Router /dionaea/logs/username/{username} [get]
+func GetDionaeaLogsByUsername(c *gin.Context) {
+	username := c.Param("username")
+
+	service, err := services.NewDionaeaService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	logs, err := service.GetLogsByUsername(username)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取日志失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, logs)
+}
+
+// GetDionaeaLogsByEventType 根据事件类型获取Dionaea日志
+// @Summary 根据事件类型获取Dionaea日志
+// @Description 获取指定类型(connection/login/download)的Dionaea事件
+// @Tags Dionaea蜜罐日志
+// @Produce json
+// @Param event_type path string true "事件类型"
+// @Success 200 {object} utils.Response
+// @Router /dionaea/logs/event/{event_type} [get]
+func GetDionaeaLogsByEventType(c *gin.Context) {
+	eventType := c.Param("event_type")
+	switch eventType {
+	case repositories.DionaeaEventConnection, repositories.DionaeaEventLogin, repositories.DionaeaEventDownload:
+	default:
+		utils.ResponseError(c, http.StatusBadRequest, "不支持的事件类型: "+eventType)
+		return
+	}
+
+	service, err := services.NewDionaeaService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	logs, err := service.GetLogsByEventType(eventType)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取日志失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, logs)
+}
+
+// GetDionaeaLogsByTimeRange 根据时间范围获取Dionaea日志
+// @Summary 根据时间范围获取Dionaea日志
+// @Description 获取指定时间范围内的Dionaea蜜罐日志
+// @Tags Dionaea蜜罐日志
+// @Produce json
+// @Param start_time query string true "开始时间(RFC3339格式)"
+// @Param end_time query string true "结束时间(RFC3339格式)"
+// @Success 200 {object} utils.Response
+// @Router /dionaea/logs/time-range [get]
+func GetDionaeaLogsByTimeRange(c *gin.Context) {
+	startTimeStr := c.Query("start_time")
+	endTimeStr := c.Query("end_time")
+
+	if startTimeStr == "" || endTimeStr == "" {
+		utils.ResponseError(c, http.StatusBadRequest, "开始时间和结束时间不能为空")
+		return
+	}
+
+	startTime, err := time.Parse(time.RFC3339, startTimeStr)
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "开始时间格式错误: "+err.Error())
+		return
+	}
+
+	endTime, err := time.Parse(time.RFC3339, endTimeStr)
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "结束时间格式错误: "+err.Error())
+		return
+	}
+
+	service, err := services.NewDionaeaService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	logs, err := service.GetLogsByTimeRange(startTime, endTime)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取日志失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, logs)
+}
+
+// GetDionaeaStatistics 获取Dionaea统计信息
+// @Summary 获取Dionaea统计信息
+// @Description 按日期和服务模块统计连接数、独立IP数、登录尝试和下载次数
+// @Tags Dionaea蜜罐日志
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /dionaea/statistics [get]
+func GetDionaeaStatistics(c *gin.Context) {
+	service, err := services.NewDionaeaService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	stats, err := service.GetStatistics()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取统计信息失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, stats)
+}
+
+// GetDionaeaTopAttackers 获取Dionaea顶级攻击者
+// @Summary 获取Dionaea顶级攻击者
+// @Description 获取连接次数最多的前N个攻击者IP
+// @Tags Dionaea蜜罐日志
+// @Produce json
+// @Param limit query int false "限制数量" default(10)
+// @Success 200 {object} utils.Response
+// @Router /dionaea/top-attackers [get]
+func GetDionaeaTopAttackers(c *gin.Context) {
+	limitStr := c.DefaultQuery("limit", "10")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	service, err := services.NewDionaeaService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	attackers, err := service.GetTopAttackers(limit)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取顶级攻击者失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, attackers)
+}
+
+// GetDionaeaTopUsernames 获取Dionaea常用用户名
+// @Summary 获取Dionaea常用用户名
+// @Description 获取使用频率最高的前N个用户名
+// @Tags Dionaea蜜罐日志
+// @Produce json
+// @Param limit query int false "限制数量" default(10)
+// @Success 200 {object} utils.Response
+// @Router /dionaea/top-usernames [get]
+func GetDionaeaTopUsernames(c *gin.Context) {
+	limitStr := c.DefaultQuery("limit", "10")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	service, err := services.NewDionaeaService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	usernames, err := service.GetTopUsernames(limit)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取常用用户名失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, usernames)
+}
+
+// GetDionaeaTopPasswords 获取Dionaea常用密码
+// @Summary 获取Dionaea常用密码
+// @Description 获取使用频率最高的前N个密码
+// @Tags Dionaea蜜罐日志
+// @Produce json
+// @Param limit query int false "限制数量" default(10)
+// @Success 200 {object} utils.Response
+// @Router /dionaea/top-passwords [get]
+func GetDionaeaTopPasswords(c *gin.Context) {
+	limitStr := c.DefaultQuery("limit", "10")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	service, err := services.NewDionaeaService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	passwords, err := service.GetTopPasswords(limit)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取常用密码失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, passwords)
+}
+
+// GetDionaeaTopDownloads 获取Dionaea常见下载文件
+// @Summary 获取Dionaea常见下载文件
+// @Description 获取被下载次数最多的前N个文件(按MD5)
+// @Tags Dionaea蜜罐日志
+// @Produce json
+// @Param limit query int false "限制数量" default(10)
+// @Success 200 {object} utils.Response
+// @Router /dionaea/top-downloads [get]
+func GetDionaeaTopDownloads(c *gin.Context) {
+	limitStr := c.DefaultQuery("limit", "10")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	service, err := services.NewDionaeaService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	downloads, err := service.GetTopDownloads(limit)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取常见下载文件失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, downloads)
+}
+
+// DeleteDionaeaLogsByContainer 删除容器相关的Dionaea日志
+// @Summary 删除容器相关的Dionaea日志
+// @Description 删除指定容器的所有Dionaea蜜罐日志
+// @Tags Dionaea蜜罐日志
+// @Produce json
+// @Param container_id path string true "容器ID"
+// @Success 200 {object} utils.Response
+// @Router /dionaea/logs/container/{container_id} [delete]
+func DeleteDionaeaLogsByContainer(c *gin.Context) {
+	containerID := c.Param("container_id")
+	if containerID == "" {
+		utils.ResponseError(c, http.StatusBadRequest, "容器ID不能为空")
+		return
+	}
+
+	service, err := services.NewDionaeaService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	if err := service.DeleteLogsByContainerID(containerID); err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "删除日志失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, "容器Dionaea日志删除成功")
+}
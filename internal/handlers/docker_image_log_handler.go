@@ -15,22 +15,28 @@ import (
 // @Description 获取所有Docker镜像的操作日志记录
 // @Tags Docker镜像日志
 // @Produce json
+// @Param limit query int false "每页数量(默认100，最大1000)"
+// @Param page query int false "页码，从1开始"
+// @Param cursor query string false "上一页返回的next_cursor"
+// @Param sort query string false "排序字段，前加-表示倒序，默认-created_at"
+// @Param start_time query string false "开始时间(RFC3339格式)"
+// @Param end_time query string false "结束时间(RFC3339格式)"
 // @Success 200 {object} utils.Response
 // @Router /docker/image-logs [get]
 func GetAllDockerImageLogs(c *gin.Context) {
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	spec, ok := bindQuerySpec(c, "created_at")
+	if !ok {
 		return
 	}
 
-	repo := repositories.NewMySQLDockerImageLogRepo(config.MySQLDB)
-	logs, err := repo.List()
-	if err != nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "获取镜像操作日志失败: "+err.Error())
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	utils.ResponseSuccess(c, logs)
+	repo := repositories.NewDockerImageLogRepo(config.DB)
+	page, err := repo.Query(spec)
+	respondPage(c, page, err, "获取镜像操作日志失败")
 }
 
 // GetDockerImageLogByID 根据ID获取Docker镜像操作日志
@@ -49,12 +55,12 @@ func GetDockerImageLogByID(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLDockerImageLogRepo(config.MySQLDB)
+	repo := repositories.NewDockerImageLogRepo(config.DB)
 	log, err := repo.GetByID(uint(id))
 	if err != nil {
 		utils.ResponseError(c, http.StatusNotFound, "镜像操作日志不存在: "+err.Error())
@@ -79,12 +85,12 @@ func GetDockerImageLogsByImageID(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLDockerImageLogRepo(config.MySQLDB)
+	repo := repositories.NewDockerImageLogRepo(config.DB)
 	logs, err := repo.GetByImageID(imageID)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取镜像操作日志失败: "+err.Error())
@@ -110,12 +116,12 @@ func DeleteDockerImageLog(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLDockerImageLogRepo(config.MySQLDB)
+	repo := repositories.NewDockerImageLogRepo(config.DB)
 	if err := repo.Delete(uint(id)); err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "删除镜像操作日志失败: "+err.Error())
 		return
@@ -132,12 +138,12 @@ func DeleteDockerImageLog(c *gin.Context) {
 // @Success 200 {object} utils.Response
 // @Router /docker/images/db [get]
 func GetDockerImages(c *gin.Context) {
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLDockerImageRepo(config.MySQLDB)
+	repo := repositories.NewDockerImageRepo(config.DB)
 	images, err := repo.List()
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取镜像记录失败: "+err.Error())
@@ -163,12 +169,12 @@ func GetDockerImageByDBID(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLDockerImageRepo(config.MySQLDB)
+	repo := repositories.NewDockerImageRepo(config.DB)
 	image, err := repo.GetByID(uint(id))
 	if err != nil {
 		utils.ResponseError(c, http.StatusNotFound, "镜像记录不存在: "+err.Error())
@@ -194,12 +200,12 @@ func DeleteDockerImageRecord(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLDockerImageRepo(config.MySQLDB)
+	repo := repositories.NewDockerImageRepo(config.DB)
 	if err := repo.Delete(uint(id)); err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "删除镜像记录失败: "+err.Error())
 		return
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"andorralee/internal/repositories"
+	"andorralee/internal/services"
+	"andorralee/pkg/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAttackTimeline 查询攻击事件时间线
+// @Summary 查询攻击事件时间线
+// @Description 合并Headling、Cowrie、Dionaea、qeeqbox日志、攻击捕获和蜜签触发的统一时间线，按事件时间倒序，使用游标翻页
+// @Tags 攻击事件时间线
+// @Produce json
+// @Param source query string false "事件来源，多个用逗号分隔(cowrie/headling/dionaea/qeeqbox/attack_capture/honeytoken)"
+// @Param source_ip query string false "攻击者IP"
+// @Param dest_ip query string false "目标IP"
+// @Param protocol query string false "协议"
+// @Param action query string false "攻击动作"
+// @Param severity query string false "严重程度(low/medium/high/critical)"
+// @Param username query string false "用户名"
+// @Param session_id query string false "会话ID"
+// @Param container_id query string false "容器ID"
+// @Param start_time query string false "开始时间(RFC3339格式)"
+// @Param end_time query string false "结束时间(RFC3339格式)"
+// @Param cursor query string false "上一页返回的next_cursor"
+// @Param limit query int false "每页数量" default(100)
+// @Success 200 {object} utils.Response
+// @Router /events [get]
+func GetAttackTimeline(c *gin.Context) {
+	filter := repositories.AttackTimelineFilter{
+		SourceIP:      c.Query("source_ip"),
+		DestinationIP: c.Query("dest_ip"),
+		Protocol:      c.Query("protocol"),
+		Action:        c.Query("action"),
+		Severity:      c.Query("severity"),
+		Username:      c.Query("username"),
+		SessionID:     c.Query("session_id"),
+		ContainerID:   c.Query("container_id"),
+	}
+
+	if sources := c.Query("source"); sources != "" {
+		for _, source := range strings.Split(sources, ",") {
+			if source = strings.TrimSpace(source); source != "" {
+				filter.Sources = append(filter.Sources, source)
+			}
+		}
+	}
+
+	if startTimeStr := c.Query("start_time"); startTimeStr != "" {
+		startTime, err := time.Parse(time.RFC3339, startTimeStr)
+		if err != nil {
+			utils.ResponseError(c, http.StatusBadRequest, "开始时间格式错误: "+err.Error())
+			return
+		}
+		filter.StartTime = &startTime
+	}
+
+	if endTimeStr := c.Query("end_time"); endTimeStr != "" {
+		endTime, err := time.Parse(time.RFC3339, endTimeStr)
+		if err != nil {
+			utils.ResponseError(c, http.StatusBadRequest, "结束时间格式错误: "+err.Error())
+			return
+		}
+		filter.EndTime = &endTime
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 0 {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的每页数量")
+		return
+	}
+	filter.Limit = limit
+
+	service, err := services.NewEventTimelineService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	page, err := service.Query(filter, c.Query("cursor"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "查询攻击事件时间线失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, page)
+}
+
+// RebuildAttackTimeline 从蜜罐日志表补录时间线
+// @Summary 补录攻击事件时间线
+// @Description 扫描Headling、Cowrie、Dionaea和qeeqbox日志表，把尚未进入时间线的记录补录进去，可重复执行
+// @Tags 攻击事件时间线
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /events/rebuild [post]
+func RebuildAttackTimeline(c *gin.Context) {
+	service, err := services.NewEventTimelineService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	result, err := service.Rebuild()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "补录攻击事件时间线失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, result)
+}
@@ -13,6 +13,7 @@ import (
 // PullHeadlingLogsRequest 拉取headling日志请求参数
 type PullHeadlingLogsRequest struct {
 	ContainerID string `json:"container_id" binding:"required"` // 容器ID
+	LogPath     string `json:"log_path"`                        // 容器内认证日志CSV路径，可选
 }
 
 // HeadlingLogQueryRequest 查询headling日志请求参数
@@ -47,35 +48,47 @@ func PullHeadlingLogs(c *gin.Context) {
 		return
 	}
 
-	if err := service.PullHeadlingLogs(req.ContainerID); err != nil {
+	result, err := service.PullHeadlingLogs(req.ContainerID, req.LogPath)
+	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "拉取日志失败: "+err.Error())
 		return
 	}
 
-	utils.ResponseSuccess(c, "headling认证日志拉取成功")
+	utils.ResponseSuccess(c, map[string]interface{}{
+		"message":  "headling认证日志拉取成功",
+		"inserted": result.Inserted,
+		"skipped":  result.Skipped,
+		"rejected": result.Rejected,
+	})
 }
 
 // GetAllHeadlingLogs 获取所有headling认证日志
 // @Summary 获取所有headling认证日志
-// @Description 获取所有headling认证日志记录
+// @Description 分页获取headling认证日志，其他查询参数按字段过滤，如 protocol=ssh&source_ip=1.2.3.4
 // @Tags Headling认证日志
 // @Produce json
+// @Param limit query int false "每页数量(默认100，最大1000)"
+// @Param page query int false "页码，从1开始"
+// @Param cursor query string false "上一页返回的next_cursor"
+// @Param sort query string false "排序字段，前加-表示倒序，默认-timestamp"
+// @Param start_time query string false "开始时间(RFC3339格式)"
+// @Param end_time query string false "结束时间(RFC3339格式)"
 // @Success 200 {object} utils.Response
 // @Router /headling/logs [get]
 func GetAllHeadlingLogs(c *gin.Context) {
-	service, err := services.NewHeadlingService()
-	if err != nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+	spec, ok := bindQuerySpec(c, "timestamp")
+	if !ok {
 		return
 	}
 
-	logs, err := service.GetAllLogs()
+	service, err := services.NewHeadlingService()
 	if err != nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "获取日志失败: "+err.Error())
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
 		return
 	}
 
-	utils.ResponseSuccess(c, logs)
+	page, err := service.GetLogsPage(spec)
+	respondPage(c, page, err, "获取日志失败")
 }
 
 // GetHeadlingLogByID 根据ID获取headling认证日志
@@ -246,19 +259,27 @@ func GetHeadlingLogsByTimeRange(c *gin.Context) {
 
 // GetHeadlingStatistics 获取headling认证统计信息
 // @Summary 获取headling认证统计信息
-// @Description 获取headling认证日志的统计信息
+// @Description 按日期和协议统计headling认证日志，可按容器和时间范围过滤
 // @Tags Headling认证日志
 // @Produce json
+// @Param container_id query string false "容器ID"
+// @Param start_time query string false "开始时间(RFC3339格式)"
+// @Param end_time query string false "结束时间(RFC3339格式)"
 // @Success 200 {object} utils.Response
 // @Router /headling/statistics [get]
 func GetHeadlingStatistics(c *gin.Context) {
+	filter, ok := bindStatisticsFilter(c)
+	if !ok {
+		return
+	}
+
 	service, err := services.NewHeadlingService()
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
 		return
 	}
 
-	stats, err := service.GetStatistics()
+	stats, err := service.GetStatistics(filter)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取统计信息失败: "+err.Error())
 		return
@@ -269,19 +290,27 @@ func GetHeadlingStatistics(c *gin.Context) {
 
 // GetAttackerIPStatistics 获取攻击者IP统计信息
 // @Summary 获取攻击者IP统计信息
-// @Description 获取攻击者IP的详细统计信息
+// @Description 获取攻击者IP的详细统计信息，可按容器和时间范围过滤
 // @Tags Headling认证日志
 // @Produce json
+// @Param container_id query string false "容器ID"
+// @Param start_time query string false "开始时间(RFC3339格式)"
+// @Param end_time query string false "结束时间(RFC3339格式)"
 // @Success 200 {object} utils.Response
 // @Router /headling/attacker-statistics [get]
 func GetAttackerIPStatistics(c *gin.Context) {
+	filter, ok := bindStatisticsFilter(c)
+	if !ok {
+		return
+	}
+
 	service, err := services.NewHeadlingService()
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
 		return
 	}
 
-	stats, err := service.GetAttackerIPStatistics()
+	stats, err := service.GetAttackerIPStatistics(filter)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取攻击者统计信息失败: "+err.Error())
 		return
@@ -292,10 +321,13 @@ func GetAttackerIPStatistics(c *gin.Context) {
 
 // GetTopAttackers 获取前N个攻击者
 // @Summary 获取前N个攻击者
-// @Description 获取攻击次数最多的前N个攻击者
+// @Description 获取攻击次数最多的前N个攻击者，可按容器和时间范围过滤
 // @Tags Headling认证日志
 // @Produce json
 // @Param limit query int false "限制数量" default(10)
+// @Param container_id query string false "容器ID"
+// @Param start_time query string false "开始时间(RFC3339格式)"
+// @Param end_time query string false "结束时间(RFC3339格式)"
 // @Success 200 {object} utils.Response
 // @Router /headling/top-attackers [get]
 func GetTopAttackers(c *gin.Context) {
@@ -304,6 +336,11 @@ func GetTopAttackers(c *gin.Context) {
 	if err != nil || limit <= 0 {
 		limit = 10
 	}
+	filter, ok := bindStatisticsFilter(c)
+	if !ok {
+		return
+	}
+	filter.Limit = limit
 
 	service, err := services.NewHeadlingService()
 	if err != nil {
@@ -311,7 +348,7 @@ func GetTopAttackers(c *gin.Context) {
 		return
 	}
 
-	attackers, err := service.GetTopAttackers(limit)
+	attackers, err := service.GetAttackerIPStatistics(filter)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取顶级攻击者失败: "+err.Error())
 		return
@@ -109,7 +109,7 @@ func (h *HoneypotHandler) GetHoneypotLogs(c *gin.Context) {
 	}
 
 	// 获取日志
-	repo := repositories.NewMySQLHoneypotLogRepo(config.MySQLDB)
+	repo := repositories.NewHoneypotLogRepo(config.DB)
 	logs, err := repo.GetByInstanceID(uint(id))
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取日志失败: "+err.Error())
@@ -15,22 +15,28 @@ import (
 // @Description 获取所有蜜罐实例信息
 // @Tags 蜜罐管理
 // @Produce json
+// @Param limit query int false "每页数量(默认100，最大1000)"
+// @Param page query int false "页码，从1开始"
+// @Param cursor query string false "上一页返回的next_cursor"
+// @Param sort query string false "排序字段，前加-表示倒序，默认-create_time"
+// @Param start_time query string false "开始时间(RFC3339格式)"
+// @Param end_time query string false "结束时间(RFC3339格式)"
 // @Success 200 {object} utils.Response
 // @Router /honeypot/instances [get]
 func GetAllInstances(c *gin.Context) {
-	service, err := services.NewHoneypotInstanceService()
-	if err != nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "服务初始化失败: "+err.Error())
+	spec, ok := bindQuerySpec(c, "create_time")
+	if !ok {
 		return
 	}
 
-	instances, err := service.GetAllInstances()
+	service, err := services.NewHoneypotInstanceService()
 	if err != nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "获取实例失败: "+err.Error())
+		utils.ResponseError(c, http.StatusInternalServerError, "服务初始化失败: "+err.Error())
 		return
 	}
 
-	utils.ResponseSuccess(c, instances)
+	page, err := service.GetInstancesPage(spec)
+	respondPage(c, page, err, "获取实例失败")
 }
 
 // GetInstanceByID 根据ID获取蜜罐实例
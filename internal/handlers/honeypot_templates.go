@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"andorralee/internal/repositories"
+	"andorralee/internal/services"
 	"andorralee/pkg/utils"
+	"encoding/json"
 	"net/http"
 	"time"
 
@@ -14,6 +17,7 @@ type HoneypotTemplate struct {
 	Name         string            `json:"name"`
 	Protocol     string            `json:"protocol"`
 	ImageName    string            `json:"image_name"`
+	LogParser    string            `json:"log_parser"`
 	DefaultPort  int               `json:"default_port"`
 	Description  string            `json:"description"`
 	Environment  map[string]string `json:"environment"`
@@ -27,6 +31,7 @@ var honeypotTemplates = []HoneypotTemplate{
 		Name:        "SSH蜜罐 (Cowrie)",
 		Protocol:    "ssh",
 		ImageName:   "andorralee/cowrie:v0.1",
+		LogParser:   services.LogParserCowrie,
 		DefaultPort: 22,
 		Description: "基于Cowrie的SSH蜜罐，模拟SSH服务器",
 		Environment: map[string]string{
@@ -43,6 +48,7 @@ var honeypotTemplates = []HoneypotTemplate{
 		Name:        "HTTP蜜罐 (Dionaea)",
 		Protocol:    "http",
 		ImageName:   "dinotools/dionaea:latest",
+		LogParser:   services.LogParserDionaea,
 		DefaultPort: 80,
 		Description: "基于Dionaea的HTTP蜜罐，模拟Web服务器",
 		Environment: map[string]string{
@@ -58,6 +64,7 @@ var honeypotTemplates = []HoneypotTemplate{
 		Name:        "FTP蜜罐 (Dionaea)",
 		Protocol:    "ftp",
 		ImageName:   "dinotools/dionaea:latest",
+		LogParser:   services.LogParserDionaea,
 		DefaultPort: 21,
 		Description: "基于Dionaea的FTP蜜罐，模拟FTP服务器",
 		Environment: map[string]string{
@@ -72,6 +79,7 @@ var honeypotTemplates = []HoneypotTemplate{
 		Name:        "Telnet蜜罐 (Cowrie)",
 		Protocol:    "telnet",
 		ImageName:   "andorralee/cowrie:v0.1",
+		LogParser:   services.LogParserCowrie,
 		DefaultPort: 23,
 		Description: "基于Cowrie的Telnet蜜罐，模拟Telnet服务器",
 		Environment: map[string]string{
@@ -89,6 +97,7 @@ var honeypotTemplates = []HoneypotTemplate{
 		Name:        "MySQL蜜罐",
 		Protocol:    "mysql",
 		ImageName:   "qeeqbox/honeypots:latest",
+		LogParser:   services.LogParserQeeqbox,
 		DefaultPort: 3306,
 		Description: "MySQL数据库蜜罐，模拟MySQL服务器",
 		Environment: map[string]string{
@@ -169,6 +178,7 @@ func DeployHoneypotFromTemplate(c *gin.Context) {
 		Name:          deployReq.Name,
 		HoneypotName:  template.ID + "-" + deployReq.Name,
 		ImageName:     template.ImageName,
+		LogParser:     template.LogParser,
 		Protocol:      template.Protocol,
 		InterfaceType: "network",
 		PortMappings:  portMappings,
@@ -177,31 +187,34 @@ func DeployHoneypotFromTemplate(c *gin.Context) {
 		AutoStart:     deployReq.AutoStart,
 	}
 
-	// 手动创建内存容器实例
-	instanceMutex.Lock()
-	instance := &MemoryContainerInstance{
-		ID:            nextID,
+	// 创建实例记录，实际容器在部署实例时创建
+	service, err := services.NewHoneypotInstanceService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	portMappingsJSON, _ := json.Marshal(instanceReq.PortMappings)
+	environmentJSON, _ := json.Marshal(instanceReq.Environment)
+	instance := &repositories.HoneypotInstance{
 		Name:          instanceReq.Name,
 		HoneypotName:  instanceReq.HoneypotName,
 		ContainerName: instanceReq.HoneypotName,
-		ContainerID:   "",
 		IP:            "0.0.0.0",
-		HoneypotIP:    "",
 		Port:          template.DefaultPort,
 		Protocol:      instanceReq.Protocol,
 		InterfaceType: instanceReq.InterfaceType,
-		Status:        "created",
 		ImageName:     instanceReq.ImageName,
-		ImageID:       "",
-		PortMappings:  instanceReq.PortMappings,
-		Environment:   instanceReq.Environment,
-		CreateTime:    time.Now(),
+		LogParser:     instanceReq.LogParser,
+		PortMappings:  string(portMappingsJSON),
+		Environment:   string(environmentJSON),
 		UpdateTime:    time.Now(),
 		Description:   instanceReq.Description,
 	}
-	memoryInstances[nextID] = instance
-	nextID++
-	instanceMutex.Unlock()
+	if err := service.CreateInstance(instance); err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "保存实例记录失败: "+err.Error())
+		return
+	}
 
 	utils.ResponseSuccess(c, map[string]interface{}{
 		"message":       "从模板部署蜜罐成功",
@@ -211,8 +224,9 @@ func DeployHoneypotFromTemplate(c *gin.Context) {
 		"instance_name": instance.Name,
 		"protocol":      instance.Protocol,
 		"image_name":    instance.ImageName,
-		"port_mappings": instance.PortMappings,
-		"environment":   instance.Environment,
+		"log_parser":    instance.LogParser,
+		"port_mappings": instanceReq.PortMappings,
+		"environment":   instanceReq.Environment,
 		"create_time":   instance.CreateTime,
 	})
 }
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"andorralee/internal/services"
+	"andorralee/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StartHTTPHoneypot 启动内置HTTP蜜罐
+// @Summary 启动内置HTTP蜜罐
+// @Description 按HTTP_HONEYPOT_ADDR、HTTP_HONEYPOT_TLS_ADDR和HTTP_HONEYPOT_PERSONALITY等配置打开监听，不需要Docker和蜜罐镜像
+// @Tags 内置HTTP蜜罐
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /http-honeypot/start [post]
+func StartHTTPHoneypot(c *gin.Context) {
+	honeypot := services.GetHTTPHoneypot()
+	if err := honeypot.Start(); err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "启动内置HTTP蜜罐失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, honeypot.Status())
+}
+
+// StopHTTPHoneypot 停止内置HTTP蜜罐
+// @Summary 停止内置HTTP蜜罐
+// @Description 关闭HTTP和HTTPS监听
+// @Tags 内置HTTP蜜罐
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /http-honeypot/stop [post]
+func StopHTTPHoneypot(c *gin.Context) {
+	honeypot := services.GetHTTPHoneypot()
+	honeypot.Stop()
+	utils.ResponseSuccess(c, honeypot.Status())
+}
+
+// GetHTTPHoneypotStatus 获取内置HTTP蜜罐状态
+// @Summary 获取内置HTTP蜜罐状态
+// @Description 获取监听地址、伪装的应用、请求数和捕获的口令数，请求记录在攻击事件中(container_id为native:http)
+// @Tags 内置HTTP蜜罐
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /http-honeypot/status [get]
+func GetHTTPHoneypotStatus(c *gin.Context) {
+	utils.ResponseSuccess(c, services.GetHTTPHoneypot().Status())
+}
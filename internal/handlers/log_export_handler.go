@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"andorralee/internal/repositories"
+	"andorralee/internal/services"
 	"andorralee/pkg/utils"
 	"encoding/csv"
 	"fmt"
@@ -77,29 +79,29 @@ func ExportLogs(c *gin.Context) {
 
 // exportAttackLogs 导出攻击日志
 func exportAttackLogs(req LogExportRequest) ([]map[string]interface{}, error) {
-	attackMutex.RLock()
-	defer attackMutex.RUnlock()
-
-	var logs []map[string]interface{}
-	for _, event := range attackEvents {
-		// 时间过滤
-		if !req.StartTime.IsZero() && event.Timestamp.Before(req.StartTime) {
-			continue
-		}
-		if !req.EndTime.IsZero() && event.Timestamp.After(req.EndTime) {
-			continue
-		}
+	service, err := services.NewAttackCaptureService()
+	if err != nil {
+		return nil, err
+	}
 
-		// IP过滤
-		if req.SourceIP != "" && event.SourceIP != req.SourceIP {
-			continue
-		}
+	filter := repositories.AttackEventFilter{
+		SourceIP: req.SourceIP,
+		Protocol: req.Protocol,
+	}
+	if !req.StartTime.IsZero() {
+		filter.StartTime = &req.StartTime
+	}
+	if !req.EndTime.IsZero() {
+		filter.EndTime = &req.EndTime
+	}
 
-		// 协议过滤
-		if req.Protocol != "" && event.Protocol != req.Protocol {
-			continue
-		}
+	events, _, err := service.SearchEvents(filter)
+	if err != nil {
+		return nil, err
+	}
 
+	var logs []map[string]interface{}
+	for _, event := range events {
 		log := map[string]interface{}{
 			"id":             event.ID,
 			"timestamp":      event.Timestamp.Format(time.RFC3339),
@@ -125,24 +127,26 @@ func exportAttackLogs(req LogExportRequest) ([]map[string]interface{}, error) {
 
 // exportHoneyTokenLogs 导出蜜签日志
 func exportHoneyTokenLogs(req LogExportRequest) ([]map[string]interface{}, error) {
-	triggerMutex.RLock()
-	defer triggerMutex.RUnlock()
+	service, err := services.NewHoneyTokenService()
+	if err != nil {
+		return nil, err
+	}
 
-	var logs []map[string]interface{}
-	for _, trigger := range tokenTriggers {
-		// 时间过滤
-		if !req.StartTime.IsZero() && trigger.TriggerTime.Before(req.StartTime) {
-			continue
-		}
-		if !req.EndTime.IsZero() && trigger.TriggerTime.After(req.EndTime) {
-			continue
-		}
+	filter := repositories.HoneyTokenTriggerFilter{SourceIP: req.SourceIP}
+	if !req.StartTime.IsZero() {
+		filter.StartTime = &req.StartTime
+	}
+	if !req.EndTime.IsZero() {
+		filter.EndTime = &req.EndTime
+	}
 
-		// IP过滤
-		if req.SourceIP != "" && trigger.SourceIP != req.SourceIP {
-			continue
-		}
+	triggers, err := service.SearchTriggers(filter)
+	if err != nil {
+		return nil, err
+	}
 
+	var logs []map[string]interface{}
+	for _, trigger := range triggers {
 		log := map[string]interface{}{
 			"id":         trigger.ID,
 			"timestamp":  trigger.TriggerTime.Format(time.RFC3339),
@@ -162,11 +166,17 @@ func exportHoneyTokenLogs(req LogExportRequest) ([]map[string]interface{}, error
 
 // exportContainerLogs 导出容器日志
 func exportContainerLogs(req LogExportRequest) ([]map[string]interface{}, error) {
-	instanceMutex.RLock()
-	defer instanceMutex.RUnlock()
+	service, err := services.NewHoneypotInstanceService()
+	if err != nil {
+		return nil, err
+	}
+	instances, err := service.GetAllInstances()
+	if err != nil {
+		return nil, err
+	}
 
 	var logs []map[string]interface{}
-	for _, instance := range memoryInstances {
+	for _, instance := range instances {
 		// 时间过滤
 		if !req.StartTime.IsZero() && instance.CreateTime.Before(req.StartTime) {
 			continue
@@ -350,11 +360,45 @@ func exportCSV(c *gin.Context, logs []map[string]interface{}, logType string) {
 
 // GetLogStatistics 获取日志统计信息
 func GetLogStatistics(c *gin.Context) {
+	service, err := services.NewAttackCaptureService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+	attackLogs, err := service.EventRepo.Count(repositories.AttackEventFilter{})
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "统计攻击日志失败: "+err.Error())
+		return
+	}
+
+	tokenService, err := services.NewHoneyTokenService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+	honeytokenLogs, err := tokenService.TriggerRepo.Count()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "统计蜜签日志失败: "+err.Error())
+		return
+	}
+
+	instanceService, err := services.NewHoneypotInstanceService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+	instances, err := instanceService.GetAllInstances()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "统计容器日志失败: "+err.Error())
+		return
+	}
+	containerLogs := len(instances)
+
 	stats := map[string]interface{}{
-		"attack_logs":     len(attackEvents),
-		"honeytoken_logs": len(tokenTriggers),
-		"container_logs":  len(memoryInstances),
-		"total_logs":      len(attackEvents) + len(tokenTriggers) + len(memoryInstances),
+		"attack_logs":     attackLogs,
+		"honeytoken_logs": honeytokenLogs,
+		"container_logs":  containerLogs,
+		"total_logs":      attackLogs + honeytokenLogs + int64(containerLogs),
 		"last_updated":    time.Now().Format(time.RFC3339),
 	}
 
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"andorralee/internal/services"
+	"andorralee/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LogIngestionRequest 日志采集控制请求参数
+type LogIngestionRequest struct {
+	ContainerID string `json:"container_id"` // 容器ID，为空时作用于整个采集子系统
+	Parser      string `json:"parser"`       // 解析器名称(见/ingestion/parsers)，为空时根据镜像自动选择
+}
+
+// StartLogIngestion 启动容器日志采集
+// @Summary 启动容器日志采集
+// @Description 不指定容器时为所有运行中的蜜罐实例启动日志跟随采集，指定容器时只为该容器启动
+// @Tags 日志采集
+// @Accept json
+// @Produce json
+// @Param payload body LogIngestionRequest false "采集参数"
+// @Success 200 {object} utils.Response
+// @Router /ingestion/start [post]
+func StartLogIngestion(c *gin.Context) {
+	var req LogIngestionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ResponseError(c, http.StatusBadRequest, "参数错误: "+err.Error())
+			return
+		}
+	}
+
+	manager := services.GetLogIngestionManager()
+	if req.ContainerID != "" {
+		if err := manager.StartContainer(req.ContainerID, req.Parser); err != nil {
+			utils.ResponseError(c, http.StatusInternalServerError, "启动日志采集失败: "+err.Error())
+			return
+		}
+		utils.ResponseSuccess(c, manager.Status())
+		return
+	}
+
+	if err := manager.Start(); err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "启动日志采集失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, manager.Status())
+}
+
+// StopLogIngestion 停止容器日志采集
+// @Summary 停止容器日志采集
+// @Description 不指定容器时停止整个采集子系统，指定容器时只停止该容器的采集任务
+// @Tags 日志采集
+// @Accept json
+// @Produce json
+// @Param payload body LogIngestionRequest false "采集参数"
+// @Success 200 {object} utils.Response
+// @Router /ingestion/stop [post]
+func StopLogIngestion(c *gin.Context) {
+	var req LogIngestionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ResponseError(c, http.StatusBadRequest, "参数错误: "+err.Error())
+			return
+		}
+	}
+
+	manager := services.GetLogIngestionManager()
+	if req.ContainerID != "" {
+		if err := manager.StopContainer(req.ContainerID); err != nil {
+			utils.ResponseError(c, http.StatusNotFound, "停止日志采集失败: "+err.Error())
+			return
+		}
+		utils.ResponseSuccess(c, manager.Status())
+		return
+	}
+
+	manager.Stop()
+	utils.ResponseSuccess(c, manager.Status())
+}
+
+// GetLogIngestionStatus 获取容器日志采集状态
+// @Summary 获取容器日志采集状态
+// @Description 获取日志采集子系统及每个容器采集任务的状态
+// @Tags 日志采集
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /ingestion/status [get]
+func GetLogIngestionStatus(c *gin.Context) {
+	utils.ResponseSuccess(c, services.GetLogIngestionManager().Status())
+}
+
+// GetLogParsers 获取已注册的日志解析器
+// @Summary 获取已注册的日志解析器
+// @Description 列出可在蜜罐模板、实例和采集任务中声明的日志解析器及其自动匹配的镜像关键字
+// @Tags 日志采集
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /ingestion/parsers [get]
+func GetLogParsers(c *gin.Context) {
+	utils.ResponseSuccess(c, services.ListLogParsers())
+}
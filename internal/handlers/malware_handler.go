@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"andorralee/internal/services"
+	"andorralee/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CaptureMalwareRequest 捕获恶意样本请求参数
+type CaptureMalwareRequest struct {
+	ContainerID string `json:"container_id" binding:"required"` // Cowrie容器ID
+}
+
+// CaptureMalware 捕获Cowrie容器中攻击者下载的样本
+// @Summary 捕获恶意样本
+// @Description 从Cowrie容器的下载目录中取出尚未捕获的样本，计算哈希后存入隔离区
+// @Tags 恶意样本
+// @Accept json
+// @Produce json
+// @Param request body CaptureMalwareRequest true "捕获参数"
+// @Success 200 {object} utils.Response
+// @Router /malware/capture [post]
+func CaptureMalware(c *gin.Context) {
+	var req CaptureMalwareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	service, err := services.NewMalwareService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	result, err := service.CaptureFromContainer(req.ContainerID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "捕获样本失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, result)
+}
+
+// GetMalwareSamples 获取所有恶意样本
+// @Summary 获取所有恶意样本
+// @Description 获取隔离区中所有样本的元数据
+// @Tags 恶意样本
+// @Produce json
+// @Param limit query int false "每页数量(默认100，最大1000)"
+// @Param page query int false "页码，从1开始"
+// @Param cursor query string false "上一页返回的next_cursor"
+// @Param sort query string false "排序字段，前加-表示倒序，默认-last_seen"
+// @Param start_time query string false "开始时间(RFC3339格式)"
+// @Param end_time query string false "结束时间(RFC3339格式)"
+// @Success 200 {object} utils.Response
+// @Router /malware/samples [get]
+func GetMalwareSamples(c *gin.Context) {
+	spec, ok := bindQuerySpec(c, "last_seen")
+	if !ok {
+		return
+	}
+
+	service, err := services.NewMalwareService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	page, err := service.ListSamplesPage(spec)
+	respondPage(c, page, err, "获取样本失败")
+}
+
+// GetMalwareSample 获取恶意样本详情
+// @Summary 获取恶意样本详情
+// @Description 根据SHA256获取样本元数据及其关联的会话和攻击者IP
+// @Tags 恶意样本
+// @Produce json
+// @Param sha256 path string true "样本SHA256"
+// @Success 200 {object} utils.Response
+// @Router /malware/samples/{sha256} [get]
+func GetMalwareSample(c *gin.Context) {
+	sha256 := c.Param("sha256")
+	if len(sha256) != 64 {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的SHA256")
+		return
+	}
+
+	service, err := services.NewMalwareService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	detail, err := service.GetSample(sha256)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取样本失败: "+err.Error())
+		return
+	}
+	if detail == nil {
+		utils.ResponseError(c, http.StatusNotFound, "样本不存在")
+		return
+	}
+
+	utils.ResponseSuccess(c, detail)
+}
+
+// GetMalwareSightingsBySourceIP 获取攻击者IP投放的样本
+// @Summary 获取攻击者IP投放的样本
+// @Description 获取指定攻击者IP下载或上传的所有样本记录
+// @Tags 恶意样本
+// @Produce json
+// @Param source_ip path string true "攻击者IP"
+// @Success 200 {object} utils.Response
+// @Router /malware/sightings/source-ip/{source_ip} [get]
+func GetMalwareSightingsBySourceIP(c *gin.Context) {
+	service, err := services.NewMalwareService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	sightings, err := service.GetSightingsBySourceIP(c.Param("source_ip"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取样本记录失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, sightings)
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"andorralee/internal/services"
+	"andorralee/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMigrationStatus 获取数据库迁移状态
+// @Summary 获取数据库迁移状态
+// @Description 列出主数据库的所有迁移版本及执行状态、当前版本和未执行的迁移数
+// @Tags 数据库迁移
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /migrations [get]
+func GetMigrationStatus(c *gin.Context) {
+	service, err := services.NewMigrationService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	result, err := service.Status()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取迁移状态失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, result)
+}
+
+// ApplyMigrations 执行数据库迁移
+// @Summary 执行数据库迁移
+// @Description 按版本号顺序执行未执行的迁移，target为0或不传时执行到最新版本
+// @Tags 数据库迁移
+// @Accept json
+// @Produce json
+// @Param request body object false "目标版本，如 {\"target\": 2}"
+// @Success 200 {object} utils.Response
+// @Router /migrations/up [post]
+func ApplyMigrations(c *gin.Context) {
+	var req struct {
+		Target int `json:"target" binding:"min=0"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ResponseError(c, http.StatusBadRequest, "参数错误: "+err.Error())
+			return
+		}
+	}
+
+	service, err := services.NewMigrationService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	applied, err := service.Up(req.Target)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "执行迁移失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, gin.H{"applied": applied})
+}
+
+// RollbackMigrations 回滚数据库迁移
+// @Summary 回滚数据库迁移
+// @Description 按版本号倒序回滚最近执行的steps个迁移，回滚基线版本会删除所有表和数据
+// @Tags 数据库迁移
+// @Accept json
+// @Produce json
+// @Param request body object true "回滚的版本数，如 {\"steps\": 1}"
+// @Success 200 {object} utils.Response
+// @Router /migrations/down [post]
+func RollbackMigrations(c *gin.Context) {
+	var req struct {
+		Steps int `json:"steps" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	service, err := services.NewMigrationService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	rolledBack, err := service.Down(req.Steps)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "回滚迁移失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, gin.H{"rolled_back": rolledBack})
+}
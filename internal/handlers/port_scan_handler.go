@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"andorralee/internal/services"
 	"andorralee/pkg/utils"
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
@@ -267,18 +269,24 @@ func ScanContainerPorts(c *gin.Context) {
 	}
 
 	// 获取容器实例
-	instanceMutex.RLock()
-	instance, exists := memoryInstances[uint(id)]
-	instanceMutex.RUnlock()
-
-	if !exists {
+	service, err := services.NewHoneypotInstanceService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+	instance, err := service.GetInstanceByID(uint(id))
+	if err != nil {
 		utils.ResponseError(c, http.StatusNotFound, "容器实例不存在")
 		return
 	}
 
 	// 构建端口列表
+	portMappings := make(map[string]string)
+	if instance.PortMappings != "" {
+		json.Unmarshal([]byte(instance.PortMappings), &portMappings)
+	}
 	var ports []string
-	for _, hostPort := range instance.PortMappings {
+	for _, hostPort := range portMappings {
 		ports = append(ports, hostPort)
 	}
 
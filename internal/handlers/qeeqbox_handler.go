@@ -0,0 +1,363 @@
+package handlers
+
+import (
+	"andorralee/internal/services"
+	"andorralee/pkg/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PullQeeqboxLogsRequest 拉取qeeqbox日志请求参数
+type PullQeeqboxLogsRequest struct {
+	ContainerID string `json:"container_id" binding:"required"` // 容器ID
+}
+
+// PullQeeqboxLogs 拉取qeeqbox蜜罐日志
+// @Summary 拉取qeeqbox蜜罐日志
+// @Description 从qeeqbox/honeypots容器的标准输出中读取JSON日志，解析各协议的连接、登录和命令事件并保存到数据库
+// @Tags qeeqbox蜜罐日志
+// @Accept json
+// @Produce json
+// @Param payload body PullQeeqboxLogsRequest true "拉取参数"
+// @Success 200 {object} utils.Response
+// @Router /qeeqbox/pull-logs [post]
+func PullQeeqboxLogs(c *gin.Context) {
+	var req PullQeeqboxLogsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	service, err := services.NewQeeqboxService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	result, err := service.PullQeeqboxLogs(req.ContainerID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "拉取日志失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, map[string]interface{}{
+		"message":  "qeeqbox蜜罐日志拉取成功",
+		"inserted": result.Inserted,
+		"skipped":  result.Skipped,
+		"rejected": result.Rejected,
+	})
+}
+
+// GetQeeqboxLogs 按条件查询qeeqbox蜜罐日志
+// @Summary 按条件查询qeeqbox蜜罐日志
+// @Description 按容器、协议、动作、状态、源IP、用户名和时间范围组合过滤qeeqbox蜜罐日志，条件均为可选
+// @Tags qeeqbox蜜罐日志
+// @Produce json
+// @Param container_id query string false "容器ID"
+// @Param protocol query string false "协议(如mysql、redis、postgres)"
+// @Param action query string false "动作(如connection、login)"
+// @Param status query string false "状态(如success、failed)"
+// @Param source_ip query string false "源IP"
+// @Param username query string false "用户名"
+// @Param start_time query string false "开始时间(RFC3339格式)"
+// @Param end_time query string false "结束时间(RFC3339格式)"
+// @Param limit query int false "每页数量(默认100，最大1000)"
+// @Param page query int false "页码，从1开始"
+// @Param cursor query string false "上一页返回的next_cursor"
+// @Param sort query string false "排序字段，前加-表示倒序，默认-event_time"
+// @Success 200 {object} utils.Response
+// @Router /qeeqbox/logs [get]
+func GetQeeqboxLogs(c *gin.Context) {
+	spec, ok := bindQuerySpec(c, "event_time")
+	if !ok {
+		return
+	}
+
+	service, err := services.NewQeeqboxService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	page, err := service.GetLogsPage(spec)
+	respondPage(c, page, err, "获取日志失败")
+}
+
+// GetQeeqboxLogByID 根据ID获取qeeqbox日志
+// @Summary 根据ID获取qeeqbox日志
+// @Description 根据日志ID获取qeeqbox蜜罐日志详情
+// @Tags qeeqbox蜜罐日志
+// @Produce json
+// @Param id path int true "日志ID"
+// @Success 200 {object} utils.Response
+// @Router /qeeqbox/logs/{id} [get]
+func GetQeeqboxLogByID(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的ID: "+err.Error())
+		return
+	}
+
+	service, err := services.NewQeeqboxService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	log, err := service.GetLogByID(uint(id))
+	if err != nil {
+		utils.ResponseError(c, http.StatusNotFound, "日志不存在: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, log)
+}
+
+// GetQeeqboxLogsByContainer 根据容器ID获取qeeqbox日志
+// @Summary 根据容器ID获取qeeqbox日志
+// @Description 获取指定容器的所有qeeqbox蜜罐日志
+// @Tags qeeqbox蜜罐日志
+// @Produce json
+// @Param container_id path string true "容器ID"
+// @Success 200 {object} utils.Response
+// @Router /qeeqbox/logs/container/{container_id} [get]
+func GetQeeqboxLogsByContainer(c *gin.Context) {
+	containerID := c.Param("container_id")
+
+	service, err := services.NewQeeqboxService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	logs, err := service.GetLogsByContainerID(containerID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取日志失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, logs)
+}
+
+// GetQeeqboxLogsBySourceIP 根据源IP获取qeeqbox日志
+// @Summary 根据源IP获取qeeqbox日志
+// @Description 获取指定攻击者IP的所有qeeqbox蜜罐日志
+// @Tags qeeqbox蜜罐日志
+// @Produce json
+// @Param source_ip path string true "源IP"
+// @Success 200 {object} utils.Response
+// @Router /qeeqbox/logs/source-ip/{source_ip} [get]
+func GetQeeqboxLogsBySourceIP(c *gin.Context) {
+	sourceIP := c.Param("source_ip")
+
+	service, err := services.NewQeeqboxService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	logs, err := service.GetLogsBySourceIP(sourceIP)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取日志失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, logs)
+}
+
+// GetQeeqboxLogsByProtocol 根据协议获取qeeqbox日志
+// @Summary 根据协议获取qeeqbox日志
+// @Description 获取指定协议(如mysql、redis、postgres、smtp)的qeeqbox蜜罐日志
+// @Tags qeeqbox蜜罐日志
+// @Produce json
+// @Param protocol path string true "协议"
+// @Success 200 {object} utils.Response
+// @Router /qeeqbox/logs/protocol/{protocol} [get]
+func GetQeeqboxLogsByProtocol(c *gin.Context) {
+	protocol := c.Param("protocol")
+
+	service, err := services.NewQeeqboxService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	logs, err := service.GetLogsByProtocol(protocol)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取日志失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, logs)
+}
+
+// GetQeeqboxLogsByAction 根据动作获取qeeqbox日志
+// @Summary 根据动作获取qeeqbox日志
+// @Description 获取指定动作(如connection、login、command)的qeeqbox蜜罐日志
+// @Tags qeeqbox蜜罐日志
+// @Produce json
+// @Param action path string true "动作"
+// @Success 200 {object} utils.Response
+// @Router /qeeqbox/logs/action/{action} [get]
+func GetQeeqboxLogsByAction(c *gin.Context) {
+	action := c.Param("action")
+
+	service, err := services.NewQeeqboxService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	logs, err := service.GetLogsByAction(action)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取日志失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, logs)
+}
+
+// GetQeeqboxStatistics 获取qeeqbox统计信息
+// @Summary 获取qeeqbox统计信息
+// @Description 按日期和协议统计事件数、独立IP数和登录尝试次数
+// @Tags qeeqbox蜜罐日志
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /qeeqbox/statistics [get]
+func GetQeeqboxStatistics(c *gin.Context) {
+	service, err := services.NewQeeqboxService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	stats, err := service.GetStatistics()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取统计信息失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, stats)
+}
+
+// GetQeeqboxTopAttackers 获取qeeqbox顶级攻击者
+// @Summary 获取qeeqbox顶级攻击者
+// @Description 获取事件数最多的前N个攻击者IP
+// @Tags qeeqbox蜜罐日志
+// @Produce json
+// @Param limit query int false "限制数量" default(10)
+// @Success 200 {object} utils.Response
+// @Router /qeeqbox/top-attackers [get]
+func GetQeeqboxTopAttackers(c *gin.Context) {
+	limitStr := c.DefaultQuery("limit", "10")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	service, err := services.NewQeeqboxService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	attackers, err := service.GetTopAttackers(limit)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取顶级攻击者失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, attackers)
+}
+
+// GetQeeqboxTopUsernames 获取qeeqbox常用用户名
+// @Summary 获取qeeqbox常用用户名
+// @Description 获取使用频率最高的前N个用户名
+// @Tags qeeqbox蜜罐日志
+// @Produce json
+// @Param limit query int false "限制数量" default(10)
+// @Success 200 {object} utils.Response
+// @Router /qeeqbox/top-usernames [get]
+func GetQeeqboxTopUsernames(c *gin.Context) {
+	limitStr := c.DefaultQuery("limit", "10")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	service, err := services.NewQeeqboxService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	usernames, err := service.GetTopUsernames(limit)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取常用用户名失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, usernames)
+}
+
+// GetQeeqboxTopPasswords 获取qeeqbox常用密码
+// @Summary 获取qeeqbox常用密码
+// @Description 获取使用频率最高的前N个密码
+// @Tags qeeqbox蜜罐日志
+// @Produce json
+// @Param limit query int false "限制数量" default(10)
+// @Success 200 {object} utils.Response
+// @Router /qeeqbox/top-passwords [get]
+func GetQeeqboxTopPasswords(c *gin.Context) {
+	limitStr := c.DefaultQuery("limit", "10")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	service, err := services.NewQeeqboxService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	passwords, err := service.GetTopPasswords(limit)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取常用密码失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, passwords)
+}
+
+// DeleteQeeqboxLogsByContainer 删除容器相关的qeeqbox日志
+// @Summary 删除容器相关的qeeqbox日志
+// @Description 删除指定容器的所有qeeqbox蜜罐日志
+// @Tags qeeqbox蜜罐日志
+// @Produce json
+// @Param container_id path string true "容器ID"
+// @Success 200 {object} utils.Response
+// @Router /qeeqbox/logs/container/{container_id} [delete]
+func DeleteQeeqboxLogsByContainer(c *gin.Context) {
+	containerID := c.Param("container_id")
+	if containerID == "" {
+		utils.ResponseError(c, http.StatusBadRequest, "容器ID不能为空")
+		return
+	}
+
+	service, err := services.NewQeeqboxService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	if err := service.DeleteLogsByContainerID(containerID); err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "删除日志失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, "容器qeeqbox日志删除成功")
+}
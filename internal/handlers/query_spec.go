@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"andorralee/internal/repositories"
+	"andorralee/pkg/utils"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listQueryParams 分页和排序参数，不作为过滤条件
+var listQueryParams = map[string]bool{
+	"limit": true, "page": true, "offset": true, "cursor": true,
+	"sort": true, "order": true, "start_time": true, "end_time": true,
+}
+
+// bindQuerySpec 从查询参数读取列表查询条件，timeField为start_time/end_time过滤和默认倒序排序使用的时间字段，为空时按ID排序
+//   - 分页: limit(默认100，最大1000)；page或offset为偏移量分页，cursor为上一页返回的next_cursor
+//   - 排序: sort=字段名，字段名前加-表示倒序，也可以用order=asc|desc
+//   - 时间范围: start_time、end_time(RFC3339格式)
+//   - 过滤: 字段名=值，多个值用逗号分隔表示任一匹配；字段名[操作符]=值，操作符为eq、ne、gt、gte、lt、lte、in、like
+//
+// 参数错误时已写入响应，返回false
+func bindQuerySpec(c *gin.Context, timeField string) (repositories.QuerySpec, bool) {
+	spec := repositories.QuerySpec{Cursor: c.Query("cursor")}
+
+	for _, name := range []string{"limit", "page", "offset"} {
+		raw := c.Query(name)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 || (name == "page" && value == 0) {
+			utils.ResponseError(c, http.StatusBadRequest, "无效的分页参数"+name+": "+raw)
+			return spec, false
+		}
+		switch name {
+		case "limit":
+			spec.Limit = value
+		case "page":
+			limit := spec.Limit
+			if limit <= 0 {
+				limit = repositories.DefaultPageSize
+			}
+			spec.Offset = (value - 1) * limit
+		case "offset":
+			spec.Offset = value
+		}
+	}
+
+	if timeField != "" {
+		spec.SortField, spec.SortDesc = timeField, true
+	}
+	if sortParam := c.Query("sort"); sortParam != "" {
+		spec.SortField = strings.TrimPrefix(sortParam, "-")
+		spec.SortDesc = strings.HasPrefix(sortParam, "-")
+	}
+	switch order := strings.ToLower(c.Query("order")); order {
+	case "":
+	case "asc":
+		spec.SortDesc = false
+	case "desc":
+		spec.SortDesc = true
+	default:
+		utils.ResponseError(c, http.StatusBadRequest, "无效的排序方向: "+order)
+		return spec, false
+	}
+
+	if filter, ok := bindStatisticsFilter(c); !ok {
+		return spec, false
+	} else if timeField != "" {
+		if filter.StartTime != nil {
+			spec.Filters = append(spec.Filters, repositories.QueryFilter{Field: timeField, Op: repositories.FilterGte, Values: []string{filter.StartTime.Format(time.RFC3339Nano)}})
+		}
+		if filter.EndTime != nil {
+			spec.Filters = append(spec.Filters, repositories.QueryFilter{Field: timeField, Op: repositories.FilterLte, Values: []string{filter.EndTime.Format(time.RFC3339Nano)}})
+		}
+	}
+
+	// 按参数名排序，使生成的SQL稳定
+	params := c.Request.URL.Query()
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if listQueryParams[name] {
+			continue
+		}
+		field, op := name, ""
+		if i := strings.Index(name, "["); i > 0 && strings.HasSuffix(name, "]") {
+			field, op = name[:i], name[i+1:len(name)-1]
+		}
+		for _, raw := range params[name] {
+			filter := repositories.QueryFilter{Field: field, Op: op, Values: []string{raw}}
+			if op == repositories.FilterIn || (op == "" && strings.Contains(raw, ",")) {
+				filter.Op, filter.Values = repositories.FilterIn, strings.Split(raw, ",")
+			}
+			spec.Filters = append(spec.Filters, filter)
+		}
+	}
+	return spec, true
+}
+
+// respondPage 返回一页查询结果，查询条件不合法时返回400
+func respondPage[T any](c *gin.Context, page *repositories.Page[T], err error, message string) {
+	if err != nil {
+		if errors.Is(err, repositories.ErrInvalidQuerySpec) {
+			utils.ResponseError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.ResponseError(c, http.StatusInternalServerError, message+": "+err.Error())
+		return
+	}
+	utils.ResponsePage(c, page.Items, page.Total, page.NextCursor)
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"andorralee/internal/services"
+	"andorralee/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StartRedisHoneypot 启动内置Redis蜜罐
+// @Summary 启动内置Redis蜜罐
+// @Description 按REDIS_HONEYPOT_ADDR、REDIS_HONEYPOT_PASSWORD和REDIS_HONEYPOT_VERSION等配置打开监听，替代无法拉取的redispot镜像
+// @Tags 内置Redis蜜罐
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /redis-honeypot/start [post]
+func StartRedisHoneypot(c *gin.Context) {
+	honeypot := services.GetRedisHoneypot()
+	if err := honeypot.Start(); err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "启动内置Redis蜜罐失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, honeypot.Status())
+}
+
+// StopRedisHoneypot 停止内置Redis蜜罐
+// @Summary 停止内置Redis蜜罐
+// @Description 关闭监听和所有连接，模拟的键空间在下次启动时清空
+// @Tags 内置Redis蜜罐
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /redis-honeypot/stop [post]
+func StopRedisHoneypot(c *gin.Context) {
+	honeypot := services.GetRedisHoneypot()
+	honeypot.Stop()
+	utils.ResponseSuccess(c, honeypot.Status())
+}
+
+// GetRedisHoneypotStatus 获取内置Redis蜜罐状态
+// @Summary 获取内置Redis蜜罐状态
+// @Description 获取监听地址、连接数、命令数、认证次数和模拟键空间大小，命令记录在攻击事件中(container_id为native:redis)
+// @Tags 内置Redis蜜罐
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /redis-honeypot/status [get]
+func GetRedisHoneypotStatus(c *gin.Context) {
+	utils.ResponseSuccess(c, services.GetRedisHoneypot().Status())
+}
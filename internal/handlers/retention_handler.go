@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"andorralee/internal/services"
+	"andorralee/pkg/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondRetentionError 按错误类型返回保留策略接口的错误响应
+func respondRetentionError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrRetentionTableUnsupported):
+		utils.ResponseError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrRetentionArchiveNotFound):
+		utils.ResponseError(c, http.StatusNotFound, err.Error())
+	default:
+		utils.ResponseError(c, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
+
+// GetRetentionPolicies 获取日志保留策略
+// @Summary 获取日志保留策略
+// @Description 列出每张支持保留策略的日志表(cowrie_log、headling_auth_log、container_log_segment、docker_image_log)的策略，未配置的表返回未启用的空策略
+// @Tags 日志保留
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /retention/policies [get]
+func GetRetentionPolicies(c *gin.Context) {
+	service, err := services.NewRetentionService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	policies, err := service.ListPolicies()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取保留策略失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, policies)
+}
+
+// SetRetentionPolicy 设置日志表的保留策略
+// @Summary 设置日志保留策略
+// @Description 设置日志表的最长保留天数和最多保留行数，0表示不按该条件清理；超出的记录由后台任务归档为压缩NDJSON文件后删除
+// @Tags 日志保留
+// @Accept json
+// @Produce json
+// @Param table path string true "日志表名"
+// @Param request body object true "保留策略，如 {\"max_age_days\": 30, \"max_rows\": 1000000, \"enabled\": true}"
+// @Success 200 {object} utils.Response
+// @Router /retention/policies/{table} [put]
+func SetRetentionPolicy(c *gin.Context) {
+	var req struct {
+		MaxAgeDays int   `json:"max_age_days" binding:"min=0"`
+		MaxRows    int64 `json:"max_rows" binding:"min=0"`
+		Enabled    *bool `json:"enabled"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	service, err := services.NewRetentionService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	policy, err := service.SetPolicy(c.Param("table"), req.MaxAgeDays, req.MaxRows, enabled)
+	if err != nil {
+		respondRetentionError(c, "设置保留策略失败", err)
+		return
+	}
+
+	utils.ResponseSuccess(c, policy)
+}
+
+// RunRetention 立即执行日志保留策略
+// @Summary 立即执行日志保留策略
+// @Description 立即对启用的保留策略执行归档和删除，不必等待后台任务
+// @Tags 日志保留
+// @Produce json
+// @Param table query string false "只处理该日志表"
+// @Success 200 {object} utils.Response
+// @Router /retention/run [post]
+func RunRetention(c *gin.Context) {
+	service, err := services.NewRetentionService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	results, err := service.Enforce(c.Query("table"))
+	if err != nil {
+		respondRetentionError(c, "执行保留策略失败", err)
+		return
+	}
+
+	utils.ResponseSuccess(c, results)
+}
+
+// GetRetentionArchives 获取日志归档列表
+// @Summary 获取日志归档列表
+// @Description 按归档时间倒序列出归档文件及其行数、时间范围和恢复情况
+// @Tags 日志保留
+// @Produce json
+// @Param table query string false "日志表名"
+// @Success 200 {object} utils.Response
+// @Router /retention/archives [get]
+func GetRetentionArchives(c *gin.Context) {
+	service, err := services.NewRetentionService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	archives, err := service.ListArchives(c.Query("table"))
+	if err != nil {
+		respondRetentionError(c, "获取归档列表失败", err)
+		return
+	}
+
+	utils.ResponseSuccess(c, archives)
+}
+
+// RestoreRetentionArchive 恢复日志归档
+// @Summary 恢复日志归档
+// @Description 把归档文件中的记录按原ID写回原表，已存在的记录被跳过，可以重复恢复
+// @Tags 日志保留
+// @Produce json
+// @Param id path int true "归档ID"
+// @Success 200 {object} utils.Response
+// @Router /retention/archives/{id}/restore [post]
+func RestoreRetentionArchive(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的ID: "+err.Error())
+		return
+	}
+
+	service, err := services.NewRetentionService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	archive, err := service.RestoreArchive(uint(id))
+	if err != nil {
+		respondRetentionError(c, "恢复归档失败", err)
+		return
+	}
+
+	utils.ResponseSuccess(c, archive)
+}
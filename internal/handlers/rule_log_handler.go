@@ -15,22 +15,28 @@ import (
 // @Description 获取所有安全规则执行日志
 // @Tags 安全规则管理
 // @Produce json
+// @Param limit query int false "每页数量(默认100，最大1000)"
+// @Param page query int false "页码，从1开始"
+// @Param cursor query string false "上一页返回的next_cursor"
+// @Param sort query string false "排序字段，前加-表示倒序，默认-log_time"
+// @Param start_time query string false "开始时间(RFC3339格式)"
+// @Param end_time query string false "结束时间(RFC3339格式)"
 // @Success 200 {object} utils.Response
 // @Router /rules/logs [get]
 func GetAllRuleLogs(c *gin.Context) {
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	spec, ok := bindQuerySpec(c, "log_time")
+	if !ok {
 		return
 	}
 
-	repo := repositories.NewMySQLRuleLogRepo(config.MySQLDB)
-	logs, err := repo.List()
-	if err != nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "获取日志失败: "+err.Error())
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	utils.ResponseSuccess(c, logs)
+	repo := repositories.NewRuleLogRepo(config.DB)
+	page, err := repo.Query(spec)
+	respondPage(c, page, err, "获取日志失败")
 }
 
 // GetRuleLogByID 根据ID获取规则日志
@@ -49,12 +55,12 @@ func GetRuleLogByID(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLRuleLogRepo(config.MySQLDB)
+	repo := repositories.NewRuleLogRepo(config.DB)
 	log, err := repo.GetByID(uint(id))
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取日志失败: "+err.Error())
@@ -80,12 +86,12 @@ func GetLogsByRuleID(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLRuleLogRepo(config.MySQLDB)
+	repo := repositories.NewRuleLogRepo(config.DB)
 	logs, err := repo.GetByRuleID(uint(id))
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取日志失败: "+err.Error())
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"andorralee/internal/services"
+	"andorralee/pkg/utils"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchLogs 全文检索日志
+// @Summary 全文检索日志
+// @Description 检索Cowrie日志的command、raw_log、client_info字段和攻击事件的payload字段，返回命中片段及高亮位置；结果在各日志表内按ID倒序，通过next_cursor翻页
+// @Tags 日志检索
+// @Produce json
+// @Param q query string true "检索内容，text模式下空白分隔的词都必须出现，双引号括起的内容作为短语"
+// @Param mode query string false "检索模式(text/regex)，默认text；regex使用RE2语法，可用(?i)忽略大小写"
+// @Param fields query string false "检索字段，逗号分隔(command,raw_log,client_info,payload)，默认全部"
+// @Param source_ip query string false "攻击者IP，逗号分隔多个"
+// @Param container_id query string false "容器ID"
+// @Param start_time query string false "开始时间(RFC3339)"
+// @Param end_time query string false "结束时间(RFC3339)"
+// @Param limit query int false "每页命中数，默认50，最大500"
+// @Param cursor query string false "上一页返回的next_cursor"
+// @Success 200 {object} utils.Response
+// @Router /search [get]
+func SearchLogs(c *gin.Context) {
+	filter, ok := bindStatisticsFilter(c)
+	if !ok {
+		return
+	}
+	query := services.SearchQuery{
+		Query:       c.Query("q"),
+		Mode:        c.Query("mode"),
+		ContainerID: filter.ContainerID,
+		StartTime:   filter.StartTime,
+		EndTime:     filter.EndTime,
+		Cursor:      c.Query("cursor"),
+	}
+	if fields := c.Query("fields"); fields != "" {
+		query.Fields = strings.Split(fields, ",")
+	}
+	if ips := c.Query("source_ip"); ips != "" {
+		for _, ip := range strings.Split(ips, ",") {
+			if ip = strings.TrimSpace(ip); ip != "" {
+				query.SourceIPs = append(query.SourceIPs, ip)
+			}
+		}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			utils.ResponseError(c, http.StatusBadRequest, "无效的limit: "+limitStr)
+			return
+		}
+		query.Limit = limit
+	}
+
+	service, err := services.NewSearchService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	result, err := service.Search(query)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSearchQuery) {
+			utils.ResponseError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.ResponseError(c, http.StatusInternalServerError, "检索日志失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, result)
+}
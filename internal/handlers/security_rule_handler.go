@@ -15,22 +15,26 @@ import (
 // @Description 获取所有安全规则信息
 // @Tags 安全规则管理
 // @Produce json
+// @Param limit query int false "每页数量(默认100，最大1000)"
+// @Param page query int false "页码，从1开始"
+// @Param cursor query string false "上一页返回的next_cursor"
+// @Param sort query string false "排序字段，前加-表示倒序，默认按ID升序"
 // @Success 200 {object} utils.Response
 // @Router /rules [get]
 func GetAllRules(c *gin.Context) {
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	spec, ok := bindQuerySpec(c, "")
+	if !ok {
 		return
 	}
 
-	repo := repositories.NewMySQLSecurityRuleRepo(config.MySQLDB)
-	rules, err := repo.List()
-	if err != nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "获取规则失败: "+err.Error())
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	utils.ResponseSuccess(c, rules)
+	repo := repositories.NewSecurityRuleRepo(config.DB)
+	page, err := repo.Query(spec)
+	respondPage(c, page, err, "获取规则失败")
 }
 
 // GetRuleByID 根据ID获取安全规则
@@ -49,12 +53,12 @@ func GetRuleByID(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLSecurityRuleRepo(config.MySQLDB)
+	repo := repositories.NewSecurityRuleRepo(config.DB)
 	rule, err := repo.GetByID(uint(id))
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取规则失败: "+err.Error())
@@ -80,12 +84,12 @@ func CreateRule(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLSecurityRuleRepo(config.MySQLDB)
+	repo := repositories.NewSecurityRuleRepo(config.DB)
 	if err := repo.Create(&rule); err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "创建规则失败: "+err.Error())
 		return
@@ -120,12 +124,12 @@ func UpdateRule(c *gin.Context) {
 
 	rule.ID = uint(id)
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLSecurityRuleRepo(config.MySQLDB)
+	repo := repositories.NewSecurityRuleRepo(config.DB)
 	if err := repo.Update(&rule); err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "更新规则失败: "+err.Error())
 		return
@@ -150,12 +154,12 @@ func DeleteRule(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLSecurityRuleRepo(config.MySQLDB)
+	repo := repositories.NewSecurityRuleRepo(config.DB)
 	if err := repo.Delete(uint(id)); err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "删除规则失败: "+err.Error())
 		return
@@ -180,12 +184,12 @@ func EnableRule(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLSecurityRuleRepo(config.MySQLDB)
+	repo := repositories.NewSecurityRuleRepo(config.DB)
 	if err := repo.UpdateStatus(uint(id), true); err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "启用规则失败: "+err.Error())
 		return
@@ -216,12 +220,12 @@ func DisableRule(c *gin.Context) {
 		return
 	}
 
-	if config.MySQLDB == nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "MySQL数据库未初始化")
+	if config.DB == nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "数据库未初始化")
 		return
 	}
 
-	repo := repositories.NewMySQLSecurityRuleRepo(config.MySQLDB)
+	repo := repositories.NewSecurityRuleRepo(config.DB)
 	if err := repo.UpdateStatus(uint(id), false); err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "禁用规则失败: "+err.Error())
 		return
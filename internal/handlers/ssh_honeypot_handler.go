@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"andorralee/internal/services"
+	"andorralee/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StartSSHHoneypot 启动内置SSH蜜罐
+// @Summary 启动内置SSH蜜罐
+// @Description 按SSH_HONEYPOT_ADDR、SSH_HONEYPOT_BANNER、SSH_HONEYPOT_HOST_KEYS和SSH_HONEYPOT_AUTH_POLICY等配置打开监听，不需要Docker和蜜罐镜像
+// @Tags 内置SSH蜜罐
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /ssh-honeypot/start [post]
+func StartSSHHoneypot(c *gin.Context) {
+	honeypot := services.GetSSHHoneypot()
+	if err := honeypot.Start(); err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "启动内置SSH蜜罐失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, honeypot.Status())
+}
+
+// StopSSHHoneypot 停止内置SSH蜜罐
+// @Summary 停止内置SSH蜜罐
+// @Description 关闭监听和所有连接
+// @Tags 内置SSH蜜罐
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /ssh-honeypot/stop [post]
+func StopSSHHoneypot(c *gin.Context) {
+	honeypot := services.GetSSHHoneypot()
+	honeypot.Stop()
+	utils.ResponseSuccess(c, honeypot.Status())
+}
+
+// GetSSHHoneypotStatus 获取内置SSH蜜罐状态
+// @Summary 获取内置SSH蜜罐状态
+// @Description 获取监听地址、主机密钥指纹、认证策略、连接数和认证计数
+// @Tags 内置SSH蜜罐
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /ssh-honeypot/status [get]
+func GetSSHHoneypotStatus(c *gin.Context) {
+	utils.ResponseSuccess(c, services.GetSSHHoneypot().Status())
+}
+
+// GetSSHAuthAttempts 获取内置SSH蜜罐的认证尝试
+// @Summary 获取内置SSH蜜罐的认证尝试
+// @Description 分页获取认证方式、公钥指纹、客户端版本、KEX算法和HASSH指纹，用户名和密码同时记录在Headling认证日志中
+// @Tags 内置SSH蜜罐
+// @Produce json
+// @Param limit query int false "每页数量，默认100，最大1000"
+// @Param cursor query string false "上一页返回的next_cursor"
+// @Param sort query string false "排序字段，前缀-表示倒序，默认-timestamp"
+// @Param source_ip query string false "攻击者IP，逗号分隔多个"
+// @Param method query string false "认证方式(password/publickey/keyboard-interactive/none)"
+// @Param hassh query string false "客户端HASSH指纹"
+// @Param start_time query string false "开始时间(RFC3339)"
+// @Param end_time query string false "结束时间(RFC3339)"
+// @Success 200 {object} utils.Response
+// @Router /ssh-honeypot/attempts [get]
+func GetSSHAuthAttempts(c *gin.Context) {
+	spec, ok := bindQuerySpec(c, "timestamp")
+	if !ok {
+		return
+	}
+
+	service, err := services.NewSSHHoneypotService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	page, err := service.GetAttemptsPage(spec)
+	respondPage(c, page, err, "获取认证尝试失败")
+}
+
+// GetSSHSessionAttempts 获取一个连接的认证尝试
+// @Summary 获取一个连接的认证尝试
+// @Description 按时间顺序获取同一SSH连接中的所有认证尝试
+// @Tags 内置SSH蜜罐
+// @Produce json
+// @Param session_id path string true "会话ID"
+// @Success 200 {object} utils.Response
+// @Router /ssh-honeypot/attempts/session/{session_id} [get]
+func GetSSHSessionAttempts(c *gin.Context) {
+	service, err := services.NewSSHHoneypotService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	attempts, err := service.GetSessionAttempts(c.Param("session_id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取认证尝试失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, attempts)
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"andorralee/internal/repositories"
+	"andorralee/pkg/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bindStatisticsFilter 从查询参数读取统计条件: container_id、start_time、end_time(RFC3339格式)
+// 参数错误时已写入响应，返回false
+func bindStatisticsFilter(c *gin.Context) (repositories.StatisticsFilter, bool) {
+	filter := repositories.StatisticsFilter{ContainerID: c.Query("container_id")}
+
+	if startTimeStr := c.Query("start_time"); startTimeStr != "" {
+		startTime, err := time.Parse(time.RFC3339, startTimeStr)
+		if err != nil {
+			utils.ResponseError(c, http.StatusBadRequest, "开始时间格式错误: "+err.Error())
+			return filter, false
+		}
+		filter.StartTime = &startTime
+	}
+	if endTimeStr := c.Query("end_time"); endTimeStr != "" {
+		endTime, err := time.Parse(time.RFC3339, endTimeStr)
+		if err != nil {
+			utils.ResponseError(c, http.StatusBadRequest, "结束时间格式错误: "+err.Error())
+			return filter, false
+		}
+		filter.EndTime = &endTime
+	}
+	if filter.StartTime != nil && filter.EndTime != nil && filter.EndTime.Before(*filter.StartTime) {
+		utils.ResponseError(c, http.StatusBadRequest, "结束时间不能早于开始时间")
+		return filter, false
+	}
+	return filter, true
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"andorralee/internal/repositories"
+	"andorralee/internal/services"
+	"andorralee/pkg/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StartSyslogReceiver 启动syslog接收器
+// @Summary 启动syslog接收器
+// @Description 按SYSLOG_UDP_ADDR、SYSLOG_TCP_ADDR和SYSLOG_TLS_ADDR配置打开监听，接收外部蜜罐和传感器转发的日志
+// @Tags syslog接收
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /syslog/start [post]
+func StartSyslogReceiver(c *gin.Context) {
+	receiver := services.GetSyslogReceiver()
+	if err := receiver.Start(); err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "启动syslog接收器失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, receiver.Status())
+}
+
+// StopSyslogReceiver 停止syslog接收器
+// @Summary 停止syslog接收器
+// @Description 关闭所有syslog监听和连接，队列中的消息写入数据库后返回
+// @Tags syslog接收
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /syslog/stop [post]
+func StopSyslogReceiver(c *gin.Context) {
+	receiver := services.GetSyslogReceiver()
+	receiver.Stop()
+	utils.ResponseSuccess(c, receiver.Status())
+}
+
+// GetSyslogReceiverStatus 获取syslog接收器状态
+// @Summary 获取syslog接收器状态
+// @Description 获取监听地址、连接数和消息计数
+// @Tags syslog接收
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /syslog/status [get]
+func GetSyslogReceiverStatus(c *gin.Context) {
+	utils.ResponseSuccess(c, services.GetSyslogReceiver().Status())
+}
+
+// GetSyslogMessages 按条件查询syslog消息
+// @Summary 按条件查询syslog消息
+// @Description 按传感器、发送方IP、应用名、解析器和时间范围组合过滤收到的syslog消息，条件均为可选
+// @Tags syslog接收
+// @Produce json
+// @Param sensor_id query string false "传感器标识"
+// @Param source_ip query string false "发送方IP"
+// @Param app_name query string false "应用名"
+// @Param parser query string false "解析器名称"
+// @Param start_time query string false "开始时间(RFC3339格式)"
+// @Param end_time query string false "结束时间(RFC3339格式)"
+// @Param limit query int false "限制数量" default(100)
+// @Success 200 {object} utils.Response
+// @Router /syslog/messages [get]
+func GetSyslogMessages(c *gin.Context) {
+	filter := repositories.SyslogMessageFilter{
+		SensorID: c.Query("sensor_id"),
+		SourceIP: c.Query("source_ip"),
+		AppName:  c.Query("app_name"),
+		Parser:   c.Query("parser"),
+	}
+
+	if startTimeStr := c.Query("start_time"); startTimeStr != "" {
+		startTime, err := time.Parse(time.RFC3339, startTimeStr)
+		if err != nil {
+			utils.ResponseError(c, http.StatusBadRequest, "开始时间格式错误: "+err.Error())
+			return
+		}
+		filter.StartTime = &startTime
+	}
+
+	if endTimeStr := c.Query("end_time"); endTimeStr != "" {
+		endTime, err := time.Parse(time.RFC3339, endTimeStr)
+		if err != nil {
+			utils.ResponseError(c, http.StatusBadRequest, "结束时间格式错误: "+err.Error())
+			return
+		}
+		filter.EndTime = &endTime
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 0 {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的限制数量")
+		return
+	}
+	filter.Limit = limit
+
+	service, err := services.NewSyslogMessageService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	messages, err := service.SearchMessages(filter)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取syslog消息失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, messages)
+}
+
+// GetSyslogSensors 获取发送过syslog消息的传感器
+// @Summary 获取syslog传感器统计
+// @Description 按传感器统计消息数量、发送方IP数和首末消息时间
+// @Tags syslog接收
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /syslog/sensors [get]
+func GetSyslogSensors(c *gin.Context) {
+	service, err := services.NewSyslogMessageService()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "创建服务失败: "+err.Error())
+		return
+	}
+
+	stats, err := service.GetSensorStatistics()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取传感器统计失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccess(c, stats)
+}
@@ -0,0 +1,25 @@
+-- 删除基线版本创建的所有表，引用其他表的表先删除
+DROP TABLE IF EXISTS "honey_token_trigger";
+DROP TABLE IF EXISTS "honey_token";
+DROP TABLE IF EXISTS "malware_sighting";
+DROP TABLE IF EXISTS "malware_sample";
+DROP TABLE IF EXISTS "attack_session";
+DROP TABLE IF EXISTS "attack_event";
+DROP TABLE IF EXISTS "attack_timeline_event";
+DROP TABLE IF EXISTS "syslog_message";
+DROP TABLE IF EXISTS "qeeqbox_log";
+DROP TABLE IF EXISTS "dionaea_log";
+DROP TABLE IF EXISTS "cowrie_ttylog";
+DROP TABLE IF EXISTS "container_log_cursor";
+DROP TABLE IF EXISTS "cowrie_log";
+DROP TABLE IF EXISTS "headling_auth_log";
+DROP TABLE IF EXISTS "docker_container";
+DROP TABLE IF EXISTS "container_log_segment";
+DROP TABLE IF EXISTS "docker_image_log";
+DROP TABLE IF EXISTS "docker_image";
+DROP TABLE IF EXISTS "rule_log";
+DROP TABLE IF EXISTS "security_rule";
+DROP TABLE IF EXISTS "bait";
+DROP TABLE IF EXISTS "honeypot_log";
+DROP TABLE IF EXISTS "honeypot_instance";
+DROP TABLE IF EXISTS "honeypot_template";
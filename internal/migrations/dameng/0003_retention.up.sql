@@ -0,0 +1,47 @@
+-- 创建日志保留策略表和归档记录表，超过保留期限的日志归档为压缩NDJSON文件后删除
+
+-- 创建保留策略表
+CREATE TABLE "retention_policy" (
+    "ID" BIGINT IDENTITY(1,1),
+    "LOG_TABLE" VARCHAR(64) NOT NULL,
+    "MAX_AGE_DAYS" BIGINT NOT NULL DEFAULT 0,
+    "MAX_ROWS" BIGINT NOT NULL DEFAULT 0,
+    "ENABLED" BIT NOT NULL,
+    "UPDATE_TIME" TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY ("ID")
+);
+CREATE UNIQUE INDEX "idx_retention_policy_log_table" ON "retention_policy"("LOG_TABLE");
+COMMENT ON COLUMN "retention_policy"."LOG_TABLE" IS '日志表名';
+COMMENT ON COLUMN "retention_policy"."MAX_AGE_DAYS" IS '最长保留天数';
+COMMENT ON COLUMN "retention_policy"."MAX_ROWS" IS '最多保留行数';
+COMMENT ON COLUMN "retention_policy"."ENABLED" IS '是否启用';
+COMMENT ON COLUMN "retention_policy"."UPDATE_TIME" IS '更新时间';
+
+-- 创建归档记录表
+CREATE TABLE "retention_archive" (
+    "ID" BIGINT IDENTITY(1,1),
+    "LOG_TABLE" VARCHAR(64) NOT NULL,
+    "REASON" VARCHAR(20) NOT NULL,
+    "FILE_NAME" VARCHAR(255) NOT NULL,
+    "ROW_COUNT" BIGINT NOT NULL,
+    "SIZE_BYTES" BIGINT NOT NULL,
+    "FROM_TIME" TIMESTAMP WITH TIME ZONE,
+    "TO_TIME" TIMESTAMP WITH TIME ZONE,
+    "CREATED_AT" TIMESTAMP WITH TIME ZONE NOT NULL,
+    "RESTORED_AT" TIMESTAMP WITH TIME ZONE,
+    "RESTORED_ROWS" BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY ("ID")
+);
+CREATE INDEX "idx_retention_archive_created_at" ON "retention_archive"("CREATED_AT");
+CREATE UNIQUE INDEX "idx_retention_archive_file_name" ON "retention_archive"("FILE_NAME");
+CREATE INDEX "idx_retention_archive_log_table" ON "retention_archive"("LOG_TABLE");
+COMMENT ON COLUMN "retention_archive"."LOG_TABLE" IS '日志表名';
+COMMENT ON COLUMN "retention_archive"."REASON" IS '归档原因(max_age/max_rows)';
+COMMENT ON COLUMN "retention_archive"."FILE_NAME" IS '归档文件名';
+COMMENT ON COLUMN "retention_archive"."ROW_COUNT" IS '归档行数';
+COMMENT ON COLUMN "retention_archive"."SIZE_BYTES" IS '文件大小';
+COMMENT ON COLUMN "retention_archive"."FROM_TIME" IS '最早一条记录的时间';
+COMMENT ON COLUMN "retention_archive"."TO_TIME" IS '最晚一条记录的时间';
+COMMENT ON COLUMN "retention_archive"."CREATED_AT" IS '归档时间';
+COMMENT ON COLUMN "retention_archive"."RESTORED_AT" IS '最近一次恢复时间';
+COMMENT ON COLUMN "retention_archive"."RESTORED_ROWS" IS '最近一次恢复写回的行数';
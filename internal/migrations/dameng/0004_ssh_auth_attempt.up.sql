@@ -0,0 +1,42 @@
+-- 创建内置SSH蜜罐的认证尝试表，记录认证方式、公钥指纹、客户端版本和KEX算法，用户名和密码同时写入headling_auth_log
+
+CREATE TABLE "ssh_auth_attempt" (
+    "ID" BIGINT IDENTITY(1,1),
+    "AUTH_ID" VARCHAR(36) NOT NULL,
+    "SESSION_ID" VARCHAR(36) NOT NULL,
+    "TIMESTAMP" TIMESTAMP WITH TIME ZONE NOT NULL,
+    "SOURCE_IP" VARCHAR(45) NOT NULL,
+    "SOURCE_PORT" BIGINT NOT NULL,
+    "METHOD" VARCHAR(32) NOT NULL,
+    "USERNAME" VARCHAR(255) NOT NULL,
+    "KEY_TYPE" VARCHAR(64),
+    "KEY_FINGERPRINT" VARCHAR(128),
+    "ACCEPTED" BIT NOT NULL,
+    "CLIENT_VERSION" VARCHAR(255),
+    "KEX_ALGORITHMS" text,
+    "HASSH" VARCHAR(64),
+    "CONTAINER_ID" VARCHAR(64),
+    PRIMARY KEY ("ID")
+);
+CREATE UNIQUE INDEX "idx_ssh_auth_attempt_auth_id" ON "ssh_auth_attempt"("AUTH_ID");
+CREATE INDEX "idx_ssh_auth_attempt_session_id" ON "ssh_auth_attempt"("SESSION_ID");
+CREATE INDEX "idx_ssh_auth_attempt_timestamp" ON "ssh_auth_attempt"("TIMESTAMP");
+CREATE INDEX "idx_ssh_auth_attempt_source_ip" ON "ssh_auth_attempt"("SOURCE_IP");
+CREATE INDEX "idx_ssh_auth_attempt_method" ON "ssh_auth_attempt"("METHOD");
+CREATE INDEX "idx_ssh_auth_attempt_key_fingerprint" ON "ssh_auth_attempt"("KEY_FINGERPRINT");
+CREATE INDEX "idx_ssh_auth_attempt_hassh" ON "ssh_auth_attempt"("HASSH");
+CREATE INDEX "idx_ssh_auth_attempt_container_id" ON "ssh_auth_attempt"("CONTAINER_ID");
+COMMENT ON COLUMN "ssh_auth_attempt"."AUTH_ID" IS '认证行为的唯一ID';
+COMMENT ON COLUMN "ssh_auth_attempt"."SESSION_ID" IS '所属连接的会话ID';
+COMMENT ON COLUMN "ssh_auth_attempt"."TIMESTAMP" IS '认证尝试时间';
+COMMENT ON COLUMN "ssh_auth_attempt"."SOURCE_IP" IS '攻击者IP';
+COMMENT ON COLUMN "ssh_auth_attempt"."SOURCE_PORT" IS '攻击者使用的端口';
+COMMENT ON COLUMN "ssh_auth_attempt"."METHOD" IS '认证方式(password/publickey/keyboard-interactive/none)';
+COMMENT ON COLUMN "ssh_auth_attempt"."USERNAME" IS '攻击者输入的用户名';
+COMMENT ON COLUMN "ssh_auth_attempt"."KEY_TYPE" IS '公钥类型,publickey认证';
+COMMENT ON COLUMN "ssh_auth_attempt"."KEY_FINGERPRINT" IS '公钥SHA256指纹,publickey认证';
+COMMENT ON COLUMN "ssh_auth_attempt"."ACCEPTED" IS '是否认证成功';
+COMMENT ON COLUMN "ssh_auth_attempt"."CLIENT_VERSION" IS '客户端版本字符串';
+COMMENT ON COLUMN "ssh_auth_attempt"."KEX_ALGORITHMS" IS '客户端KEXINIT中的算法列表(JSON)';
+COMMENT ON COLUMN "ssh_auth_attempt"."HASSH" IS '客户端HASSH指纹';
+COMMENT ON COLUMN "ssh_auth_attempt"."CONTAINER_ID" IS '蜜罐标识';
@@ -0,0 +1,2 @@
+-- 删除系统设置表，下次启动时已有蜜签的数据库不会重新写入默认蜜签
+DROP TABLE IF EXISTS "system_setting";
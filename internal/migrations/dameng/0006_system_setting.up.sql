@@ -0,0 +1,11 @@
+-- 创建系统设置表，记录默认蜜签写入等只需执行一次的初始化
+
+CREATE TABLE "system_setting" (
+    "NAME" VARCHAR(64) NOT NULL,
+    "VALUE" VARCHAR(255),
+    "UPDATED_AT" TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY ("NAME")
+);
+COMMENT ON COLUMN "system_setting"."NAME" IS '设置名称';
+COMMENT ON COLUMN "system_setting"."VALUE" IS '设置值';
+COMMENT ON COLUMN "system_setting"."UPDATED_AT" IS '更新时间';
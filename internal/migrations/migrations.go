@@ -0,0 +1,326 @@
+// Package migrations 管理数据库表结构的版本。
+// 每种数据库在各自目录下按版本号保存迁移脚本，文件名格式为 <版本号>_<名称>.up.sql 和 <版本号>_<名称>.down.sql，
+// 脚本编译进程序，已执行的版本记录在schema_migrations表中，升级传感器时不需要再手动执行SQL
+package migrations
+
+import (
+	"andorralee/internal/repositories"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// BackendMySQL MySQL迁移脚本目录
+	BackendMySQL = "mysql"
+	// BackendSQLite SQLite迁移脚本目录
+	BackendSQLite = "sqlite"
+	// BackendDameng 达梦迁移脚本目录
+	BackendDameng = "dameng"
+
+	// baselineVersion 创建全部表的基线版本
+	baselineVersion = 1
+)
+
+//go:embed mysql/*.sql sqlite/*.sql dameng/*.sql
+var scripts embed.FS
+
+// scriptNamePattern 迁移脚本文件名，如 0002_statistics_views.up.sql
+var scriptNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// runMu 同一进程中同时只执行一组迁移，避免启动时的自动迁移和接口调用并发执行
+var runMu sync.Mutex
+
+// Migration 一个版本的迁移脚本
+type Migration struct {
+	Version int
+	Name    string
+	Up      string // 升级脚本
+	Down    string // 回滚脚本
+}
+
+// SchemaMigration 已执行的迁移版本记录
+type SchemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false;comment:迁移版本号"`
+	Name      string    `gorm:"size:100;not null;comment:迁移名称"`
+	AppliedAt time.Time `gorm:"not null;comment:执行时间"`
+}
+
+// TableName 指定表名
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Status 迁移版本的执行状态
+type Status struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// Load 读取指定数据库的迁移脚本，按版本号升序返回
+func Load(backend string) ([]Migration, error) {
+	entries, err := fs.ReadDir(scripts, backend)
+	if err != nil {
+		return nil, fmt.Errorf("没有%s的迁移脚本: %v", backend, err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := scriptNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("迁移脚本文件名格式错误: %s/%s", backend, entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := scripts.ReadFile(path.Join(backend, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("迁移版本%d的名称不一致: %s 和 %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("迁移版本%d缺少升级或回滚脚本: %s", m.Version, backend)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// backendOf 根据连接的GORM方言确定迁移脚本目录
+func backendOf(db *gorm.DB) (string, error) {
+	switch name := db.Dialector.Name(); name {
+	case repositories.DialectMySQL:
+		return BackendMySQL, nil
+	case repositories.DialectSQLite:
+		return BackendSQLite, nil
+	case repositories.DialectDameng:
+		return BackendDameng, nil
+	default:
+		return "", fmt.Errorf("不支持的数据库类型: %s", name)
+	}
+}
+
+// Runner 在一个数据库连接上执行迁移
+type Runner struct {
+	db         *gorm.DB
+	backend    string
+	migrations []Migration
+}
+
+// NewRunner 创建迁移执行器，并确保schema_migrations表存在
+func NewRunner(db *gorm.DB) (*Runner, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+	backend, err := backendOf(db)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := Load(backend)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("创建schema_migrations表失败: %v", err)
+	}
+	return &Runner{db: db, backend: backend, migrations: migrations}, nil
+}
+
+// Backend 返回使用的迁移脚本目录
+func (r *Runner) Backend() string {
+	return r.backend
+}
+
+// applied 读取已执行的迁移版本
+func (r *Runner) applied() (map[int]SchemaMigration, error) {
+	var records []SchemaMigration
+	if err := r.db.Order("version").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[int]SchemaMigration, len(records))
+	for _, record := range records {
+		result[record.Version] = record
+	}
+	return result, nil
+}
+
+// Status 返回所有迁移版本的执行状态
+func (r *Runner) Status() ([]Status, error) {
+	applied, err := r.applied()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(r.migrations))
+	for _, m := range r.migrations {
+		status := Status{Version: m.Version, Name: m.Name}
+		if record, ok := applied[m.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Pending 返回未执行的迁移版本
+func (r *Runner) Pending() ([]Status, error) {
+	statuses, err := r.Status()
+	if err != nil {
+		return nil, err
+	}
+	var pending []Status
+	for _, status := range statuses {
+		if !status.Applied {
+			pending = append(pending, status)
+		}
+	}
+	return pending, nil
+}
+
+// Up 按版本号顺序执行未执行的迁移，直到target版本(含)，target为0时执行到最新版本，返回本次执行的版本
+func (r *Runner) Up(target int) ([]Status, error) {
+	runMu.Lock()
+	defer runMu.Unlock()
+
+	applied, err := r.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Status
+	for _, m := range r.migrations {
+		if target > 0 && m.Version > target {
+			break
+		}
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+
+		if m.Version == baselineVersion && len(applied) == 0 && r.hasLegacyTables() {
+			// 引入版本管理之前由AutoMigrate或手工脚本创建的数据库，按模型补齐表结构后记为已执行
+			err = r.adoptLegacySchema()
+		} else {
+			err = r.exec(m.Up)
+		}
+		if err != nil {
+			return done, fmt.Errorf("执行迁移%d_%s失败: %v", m.Version, m.Name, err)
+		}
+
+		record := SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}
+		if err := r.db.Create(&record).Error; err != nil {
+			return done, fmt.Errorf("记录迁移%d_%s失败: %v", m.Version, m.Name, err)
+		}
+		done = append(done, Status{Version: m.Version, Name: m.Name, Applied: true, AppliedAt: &record.AppliedAt})
+	}
+	return done, nil
+}
+
+// Down 按版本号倒序回滚最近执行的steps个迁移，返回本次回滚的版本
+func (r *Runner) Down(steps int) ([]Status, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("回滚的版本数必须大于0")
+	}
+
+	runMu.Lock()
+	defer runMu.Unlock()
+
+	applied, err := r.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Status
+	for i := len(r.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		m := r.migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if err := r.exec(m.Down); err != nil {
+			return done, fmt.Errorf("回滚迁移%d_%s失败: %v", m.Version, m.Name, err)
+		}
+		if err := r.db.Delete(&SchemaMigration{}, m.Version).Error; err != nil {
+			return done, fmt.Errorf("删除迁移记录%d_%s失败: %v", m.Version, m.Name, err)
+		}
+		done = append(done, Status{Version: m.Version, Name: m.Name})
+	}
+	return done, nil
+}
+
+// exec 依次执行脚本中的语句
+// MySQL和达梦的DDL会隐式提交，脚本中途失败时已执行的语句不会回滚，脚本应尽量使用IF EXISTS等可重复执行的写法
+func (r *Runner) exec(script string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range SplitStatements(script) {
+			if err := tx.Exec(statement).Error; err != nil {
+				return fmt.Errorf("%v\n%s", err, statement)
+			}
+		}
+		return nil
+	})
+}
+
+// hasLegacyTables 判断数据库中是否已经有基线版本创建的表
+func (r *Runner) hasLegacyTables() bool {
+	return r.db.Migrator().HasTable(&repositories.HoneypotTemplate{})
+}
+
+// adoptLegacySchema 用AutoMigrate把已有的表补齐到与基线版本一致，基线之后新增的表仍由后续迁移创建
+func (r *Runner) adoptLegacySchema() error {
+	fmt.Println("检测到未记录版本的已有数据表，按模型补齐表结构后记为基线版本")
+	return r.db.AutoMigrate(repositories.BaselineModels()...)
+}
+
+// SplitStatements 把脚本拆分为单条语句
+// 以分号结尾的行表示一条语句结束，整行的 -- 注释会被忽略，语句末尾的分号会被去掉
+func SplitStatements(script string) []string {
+	var statements []string
+	var current []string
+	flush := func() {
+		if statement := strings.TrimSpace(strings.Join(current, "\n")); statement != "" {
+			statements = append(statements, statement)
+		}
+		current = current[:0]
+	}
+
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		if strings.HasSuffix(trimmed, ";") {
+			current = append(current, strings.TrimSuffix(strings.TrimRight(line, " \t\r"), ";"))
+			flush()
+			continue
+		}
+		current = append(current, strings.TrimRight(line, "\r"))
+	}
+	flush()
+	return statements
+}
@@ -0,0 +1,498 @@
+-- 基线版本：创建所有表，与模型定义一致
+
+-- 创建蜜罐模板表
+CREATE TABLE `honeypot_template` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `name` varchar(50) NOT NULL COMMENT '蜜罐名称',
+    `protocol` varchar(20) NOT NULL COMMENT '协议类型',
+    `log_parser` varchar(50) COMMENT '日志解析器',
+    `import_time` datetime(3) NOT NULL COMMENT '导入时间',
+    `deploy_count` bigint DEFAULT 0 COMMENT '已部署数量',
+    PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建蜜罐实例表
+CREATE TABLE `honeypot_instance` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `name` varchar(50) NOT NULL COMMENT '实例名称',
+    `honeypot_name` varchar(100) NOT NULL COMMENT '蜜罐名称',
+    `container_name` varchar(50) NOT NULL COMMENT '容器名称',
+    `container_id` varchar(64) COMMENT 'Docker容器ID',
+    `ip` varchar(45) NOT NULL COMMENT 'IP地址',
+    `honeypot_ip` varchar(45) COMMENT '蜜罐IP地址',
+    `port` bigint NOT NULL COMMENT '端口号',
+    `protocol` varchar(20) NOT NULL COMMENT '协议类型',
+    `interface_type` varchar(50) COMMENT '蜜罐接口类型',
+    `status` varchar(20) NOT NULL DEFAULT 'created' COMMENT '部署状态',
+    `image_name` varchar(200) COMMENT 'Docker镜像名称',
+    `image_id` varchar(100) COMMENT 'Docker镜像ID',
+    `log_parser` varchar(50) COMMENT '日志解析器',
+    `port_mappings` text COMMENT '端口映射配置',
+    `environment` text COMMENT '环境变量配置',
+    `create_time` datetime(3) NOT NULL COMMENT '创建时间',
+    `update_time` datetime(3) NULL COMMENT '更新时间',
+    `description` text COMMENT '描述',
+    PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建蜜罐日志表
+CREATE TABLE `honeypot_log` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `instance_id` bigint unsigned NOT NULL COMMENT '蜜罐实例ID',
+    `log_type` varchar(20) NOT NULL COMMENT '日志类型',
+    `content` text NOT NULL COMMENT '日志内容',
+    `log_time` datetime(3) NOT NULL COMMENT '记录时间',
+    PRIMARY KEY (`id`),
+    CONSTRAINT `fk_honeypot_log_instance` FOREIGN KEY (`instance_id`) REFERENCES `honeypot_instance`(`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建诱饵表
+CREATE TABLE `bait` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `name` varchar(50) NOT NULL COMMENT '诱饵名称',
+    `file_type` varchar(10) NOT NULL COMMENT '文件类型',
+    `is_deployed` boolean DEFAULT false COMMENT '投放状态(1已投放,0未投放)',
+    `create_time` datetime(3) NOT NULL COMMENT '创建时间',
+    `instance_id` bigint unsigned COMMENT '关联蜜罐实例',
+    PRIMARY KEY (`id`),
+    CONSTRAINT `fk_bait_instance` FOREIGN KEY (`instance_id`) REFERENCES `honeypot_instance`(`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建安全规则表
+CREATE TABLE `security_rule` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `rule_name` varchar(50) NOT NULL COMMENT '规则名称',
+    `trigger_conditions` text NOT NULL COMMENT '触发条件',
+    `actions` text NOT NULL COMMENT '执行动作',
+    `is_enabled` boolean DEFAULT true COMMENT '启用状态(1启用,0禁用)',
+    PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建规则日志表
+CREATE TABLE `rule_log` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `rule_id` bigint unsigned NOT NULL COMMENT '规则ID',
+    `rule_name` varchar(50) NOT NULL COMMENT '规则名称',
+    `content` text NOT NULL COMMENT '日志内容',
+    `log_time` datetime(3) NOT NULL COMMENT '记录时间',
+    PRIMARY KEY (`id`),
+    CONSTRAINT `fk_rule_log_rule` FOREIGN KEY (`rule_id`) REFERENCES `security_rule`(`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建Docker镜像表
+CREATE TABLE `docker_image` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `image_id` varchar(100) NOT NULL COMMENT '镜像ID',
+    `repository` varchar(100) COMMENT '仓库名称',
+    `tag` varchar(50) COMMENT '标签',
+    `digest` varchar(100) COMMENT '摘要',
+    `size` bigint COMMENT '镜像大小(字节)',
+    `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+    `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+    PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建Docker镜像操作日志表
+CREATE TABLE `docker_image_log` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `image_id` varchar(100) COMMENT '镜像ID',
+    `image_name` varchar(200) COMMENT '镜像名称(包含仓库和标签)',
+    `operation` varchar(20) NOT NULL COMMENT '操作类型(pull/delete/tag/inspect)',
+    `details` text COMMENT '操作详情',
+    `status` varchar(10) NOT NULL COMMENT '操作状态(success/failed)',
+    `message` text COMMENT '状态消息',
+    `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+    PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建容器日志分析结果表
+CREATE TABLE `container_log_segment` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `container_id` varchar(64) NOT NULL COMMENT '容器ID',
+    `container_name` varchar(100) COMMENT '容器名称',
+    `segment_type` varchar(20) NOT NULL COMMENT '日志段类型(error/warning/info/debug)',
+    `content` text NOT NULL COMMENT '日志内容',
+    `timestamp` datetime(3) NULL COMMENT '日志时间戳',
+    `line_number` bigint COMMENT '行号',
+    `component` varchar(50) COMMENT '组件名称',
+    `severity_level` varchar(10) COMMENT '严重程度',
+    `created_at` datetime(3) NOT NULL COMMENT '分析时间',
+    PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建Docker容器管理表
+CREATE TABLE `docker_container` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `container_id` varchar(64) NOT NULL COMMENT 'Docker容器ID',
+    `container_name` varchar(100) NOT NULL COMMENT '容器名称',
+    `image_id` varchar(100) COMMENT '关联的镜像ID',
+    `image_name` varchar(200) COMMENT '镜像名称',
+    `status` varchar(20) COMMENT '容器状态(running/stopped/exited等)',
+    `ports` text COMMENT '端口映射信息',
+    `environment` text COMMENT '环境变量',
+    `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+    `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+    PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建Headling认证日志表
+CREATE TABLE `headling_auth_log` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `timestamp` datetime(6) NOT NULL COMMENT '捕获到认证行为的时间戳',
+    `auth_id` varchar(36) NOT NULL COMMENT '此次认证行为的唯一ID',
+    `session_id` varchar(36) NOT NULL COMMENT '所属会话ID',
+    `source_ip` varchar(45) NOT NULL COMMENT '攻击者IP',
+    `source_port` bigint unsigned NOT NULL COMMENT '攻击者使用的端口',
+    `destination_ip` varchar(45) NOT NULL COMMENT '被攻击的蜜罐容器IP',
+    `destination_port` bigint unsigned NOT NULL COMMENT '目标端口',
+    `protocol` varchar(20) NOT NULL COMMENT '使用的协议',
+    `username` varchar(255) NOT NULL COMMENT '攻击者输入的用户名',
+    `password` varchar(255) NOT NULL COMMENT '攻击者输入的密码',
+    `password_hash` varchar(255) COMMENT '密码hash值',
+    `container_id` varchar(64) COMMENT '关联的容器ID',
+    `container_name` varchar(100) COMMENT '容器名称',
+    `created_at` datetime(3) NOT NULL COMMENT '记录创建时间',
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_headling_auth_log_auth_id` (`auth_id`),
+    INDEX `idx_headling_auth_log_session_id` (`session_id`),
+    INDEX `idx_headling_auth_log_source_ip` (`source_ip`),
+    INDEX `idx_headling_auth_log_destination_ip` (`destination_ip`),
+    INDEX `idx_headling_auth_log_protocol` (`protocol`),
+    INDEX `idx_headling_auth_log_username` (`username`),
+    INDEX `idx_headling_auth_log_container_id` (`container_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建Cowrie蜜罐日志表
+CREATE TABLE `cowrie_log` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `event_id` varchar(64) COMMENT 'Cowrie事件类型(eventid)',
+    `event_time` datetime(6) NOT NULL COMMENT '事件发生的精确时间戳',
+    `auth_id` varchar(36) NOT NULL COMMENT '认证行为的唯一ID',
+    `session_id` varchar(36) NOT NULL COMMENT '会话ID',
+    `source_ip` varchar(15) NOT NULL COMMENT '攻击者IP',
+    `source_port` int unsigned NOT NULL COMMENT '攻击者使用的端口',
+    `destination_ip` varchar(15) NOT NULL COMMENT '蜜罐容器IP',
+    `destination_port` int unsigned NOT NULL COMMENT '目标端口',
+    `protocol` varchar(10) NOT NULL COMMENT '使用的协议类型(http/ssh/telnet/ftp/smb/other)',
+    `client_info` varchar(255) COMMENT '客户端信息',
+    `fingerprint` varchar(64) COMMENT '客户端指纹',
+    `username` varchar(255) COMMENT '攻击者输入的用户名',
+    `password` varchar(255) COMMENT '攻击者输入的密码',
+    `password_hash` varchar(255) COMMENT '密码哈希值',
+    `command` text COMMENT '攻击者执行的命令内容',
+    `command_found` boolean COMMENT '命令是否被系统识别',
+    `message` text COMMENT 'Cowrie事件描述',
+    `duration` double COMMENT '会话持续时间(秒),session.closed事件',
+    `hassh` varchar(64) COMMENT '客户端HASSH指纹,client.kex事件',
+    `kex_algorithms` text COMMENT '客户端密钥交换算法列表,client.kex事件',
+    `url` varchar(1024) COMMENT '下载地址,file_download事件',
+    `shasum` varchar(64) COMMENT '文件SHA256,文件传输事件',
+    `outfile` varchar(255) COMMENT '文件保存路径,文件传输事件',
+    `filename` varchar(255) COMMENT '上传文件名,file_upload事件',
+    `tunnel_dest_ip` varchar(255) COMMENT '端口转发目标地址,direct-tcpip事件',
+    `tunnel_dest_port` int unsigned COMMENT '端口转发目标端口,direct-tcpip事件',
+    `tunnel_data` text COMMENT '端口转发数据,direct-tcpip.data事件',
+    `tty_log` varchar(255) COMMENT 'TTY日志路径,log.closed事件',
+    `raw_log` text NOT NULL COMMENT '原始日志内容',
+    `container_id` varchar(64) COMMENT '关联的容器ID',
+    `container_name` varchar(100) COMMENT '容器名称',
+    `created_at` datetime(3) NOT NULL COMMENT '记录创建时间',
+    PRIMARY KEY (`id`),
+    INDEX `idx_cowrie_log_event_id` (`event_id`),
+    UNIQUE INDEX `idx_cowrie_log_auth_id` (`auth_id`),
+    INDEX `idx_cowrie_log_session_id` (`session_id`),
+    INDEX `idx_cowrie_log_source_ip` (`source_ip`),
+    INDEX `idx_cowrie_log_destination_ip` (`destination_ip`),
+    INDEX `idx_cowrie_log_protocol` (`protocol`),
+    INDEX `idx_cowrie_log_username` (`username`),
+    INDEX `idx_cowrie_log_command_found` (`command_found`),
+    INDEX `idx_cowrie_log_shasum` (`shasum`),
+    INDEX `idx_cowrie_log_container_id` (`container_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建容器日志文件读取游标表
+CREATE TABLE `container_log_cursor` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `container_id` varchar(64) NOT NULL COMMENT '容器ID',
+    `log_source` varchar(20) NOT NULL COMMENT '日志来源(cowrie/headling等)',
+    `file_path` varchar(255) NOT NULL COMMENT '容器内日志文件路径',
+    `offset` bigint NOT NULL DEFAULT 0 COMMENT '已读取的字节偏移',
+    `head_hash` varchar(64) COMMENT '文件头部哈希,用于识别日志轮转',
+    `file_size` bigint COMMENT '上次读取时的文件大小',
+    `mod_time` datetime(3) NULL COMMENT '上次读取时的文件修改时间',
+    `line_count` bigint DEFAULT 0 COMMENT '已读取的行数',
+    `last_record_id` varchar(64) COMMENT '最后一条记录的ID',
+    `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_container_log_cursor` (`container_id`,`log_source`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建Cowrie会话TTY日志表
+CREATE TABLE `cowrie_ttylog` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `session_id` varchar(36) NOT NULL COMMENT '会话ID',
+    `cowrie_log_id` bigint unsigned NOT NULL COMMENT '关联的log.closed事件ID',
+    `container_id` varchar(64) COMMENT '容器ID',
+    `tty_log_path` varchar(255) NOT NULL COMMENT '容器内TTY日志路径',
+    `local_path` varchar(255) NOT NULL COMMENT '本地保存路径',
+    `shasum` varchar(64) COMMENT 'TTY日志SHA256',
+    `size` bigint COMMENT 'TTY日志大小(字节)',
+    `duration` double COMMENT '会话持续时间(秒)',
+    `created_at` datetime(3) NOT NULL COMMENT '记录创建时间',
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_cowrie_ttylog_session_id` (`session_id`),
+    INDEX `idx_cowrie_ttylog_container_id` (`container_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建Dionaea蜜罐日志表
+CREATE TABLE `dionaea_log` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `event_type` varchar(20) NOT NULL COMMENT '事件类型(connection/login/download)',
+    `event_time` datetime(6) NOT NULL COMMENT '事件发生时间',
+    `auth_id` varchar(36) NOT NULL COMMENT '事件的唯一ID',
+    `session_id` varchar(36) NOT NULL COMMENT '连接ID，同一连接的事件相同',
+    `source_ip` varchar(45) NOT NULL COMMENT '攻击者IP',
+    `source_port` int unsigned COMMENT '攻击者端口',
+    `destination_ip` varchar(45) COMMENT '蜜罐IP',
+    `destination_port` int unsigned COMMENT '蜜罐端口',
+    `protocol` varchar(32) COMMENT 'Dionaea服务模块(httpd/ftpd/smbd等)',
+    `transport` varchar(10) COMMENT '传输层协议(tcp/udp/tls)',
+    `connection_type` varchar(20) COMMENT '连接类型(accept/connect/listen)',
+    `username` varchar(255) COMMENT '登录用户名',
+    `password` varchar(255) COMMENT '登录密码',
+    `url` varchar(1024) COMMENT '下载地址',
+    `md5_hash` varchar(32) COMMENT '下载文件MD5',
+    `ftp_commands` text COMMENT 'FTP命令,以换行分隔',
+    `raw_log` text NOT NULL COMMENT '原始日志内容',
+    `container_id` varchar(64) COMMENT '关联的容器ID',
+    `container_name` varchar(100) COMMENT '容器名称',
+    `created_at` datetime(3) NOT NULL COMMENT '记录创建时间',
+    PRIMARY KEY (`id`),
+    INDEX `idx_dionaea_log_event_type` (`event_type`),
+    INDEX `idx_dionaea_log_event_time` (`event_time`),
+    UNIQUE INDEX `idx_dionaea_log_auth_id` (`auth_id`),
+    INDEX `idx_dionaea_log_session_id` (`session_id`),
+    INDEX `idx_dionaea_log_source_ip` (`source_ip`),
+    INDEX `idx_dionaea_log_destination_port` (`destination_port`),
+    INDEX `idx_dionaea_log_protocol` (`protocol`),
+    INDEX `idx_dionaea_log_username` (`username`),
+    INDEX `idx_dionaea_log_md5_hash` (`md5_hash`),
+    INDEX `idx_dionaea_log_container_id` (`container_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建qeeqbox蜜罐日志表
+CREATE TABLE `qeeqbox_log` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `auth_id` varchar(36) NOT NULL COMMENT '事件的唯一ID',
+    `event_time` datetime(6) NOT NULL COMMENT '事件发生时间',
+    `server` varchar(32) NOT NULL COMMENT 'qeeqbox服务名(mysql_server等)',
+    `protocol` varchar(20) NOT NULL COMMENT '协议(mysql/redis/postgres/smtp等)',
+    `action` varchar(32) NOT NULL COMMENT '动作(connection/login/query/command等)',
+    `status` varchar(20) COMMENT '动作结果(success/failed)',
+    `source_ip` varchar(45) NOT NULL COMMENT '攻击者IP',
+    `source_port` int unsigned COMMENT '攻击者端口',
+    `destination_ip` varchar(45) COMMENT '蜜罐IP',
+    `destination_port` int unsigned COMMENT '蜜罐端口',
+    `username` varchar(255) COMMENT '登录用户名',
+    `password` varchar(255) COMMENT '登录密码',
+    `detail` text COMMENT '攻击者执行的命令或查询',
+    `data` text COMMENT '事件附加数据(JSON)',
+    `raw_log` text NOT NULL COMMENT '原始日志内容',
+    `container_id` varchar(64) COMMENT '关联的容器ID',
+    `container_name` varchar(100) COMMENT '容器名称',
+    `created_at` datetime(3) NOT NULL COMMENT '记录创建时间',
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_qeeqbox_log_auth_id` (`auth_id`),
+    INDEX `idx_qeeqbox_log_event_time` (`event_time`),
+    INDEX `idx_qeeqbox_log_protocol` (`protocol`),
+    INDEX `idx_qeeqbox_log_action` (`action`),
+    INDEX `idx_qeeqbox_log_source_ip` (`source_ip`),
+    INDEX `idx_qeeqbox_log_username` (`username`),
+    INDEX `idx_qeeqbox_log_container_id` (`container_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建syslog消息表
+CREATE TABLE `syslog_message` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `sensor_id` varchar(64) NOT NULL COMMENT '发送方传感器标识',
+    `source_ip` varchar(45) NOT NULL COMMENT '发送方IP',
+    `transport` varchar(10) NOT NULL COMMENT '传输方式(udp/tcp/tls)',
+    `format` varchar(10) NOT NULL COMMENT '消息格式(rfc3164/rfc5424)',
+    `facility` bigint COMMENT 'syslog facility',
+    `severity` bigint COMMENT 'syslog severity',
+    `hostname` varchar(255) COMMENT '消息头中的主机名',
+    `app_name` varchar(48) COMMENT '应用名(TAG/APP-NAME)',
+    `proc_id` varchar(128) COMMENT '进程ID',
+    `msg_id` varchar(32) COMMENT '消息类型(RFC 5424 MSGID)',
+    `structured_data` text COMMENT '结构化数据(RFC 5424)',
+    `message` text NOT NULL COMMENT '消息正文',
+    `parser` varchar(20) COMMENT '处理消息正文的解析器',
+    `event_time` datetime(6) NOT NULL COMMENT '消息时间戳',
+    `received_at` datetime(6) NOT NULL COMMENT '接收时间',
+    `created_at` datetime(3) NOT NULL COMMENT '记录创建时间',
+    PRIMARY KEY (`id`),
+    INDEX `idx_syslog_message_sensor_id` (`sensor_id`),
+    INDEX `idx_syslog_message_source_ip` (`source_ip`),
+    INDEX `idx_syslog_message_app_name` (`app_name`),
+    INDEX `idx_syslog_message_parser` (`parser`),
+    INDEX `idx_syslog_message_event_time` (`event_time`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建攻击时间线事件表
+CREATE TABLE `attack_timeline_event` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `event_time` datetime(6) NOT NULL COMMENT '事件发生时间',
+    `auth_id` varchar(36) NOT NULL COMMENT '由来源和来源记录生成的唯一ID',
+    `source` varchar(20) NOT NULL COMMENT '事件来源(cowrie/headling/dionaea/qeeqbox/attack_capture/honeytoken)',
+    `source_ref` varchar(64) COMMENT '来源记录的标识',
+    `session_id` varchar(64) COMMENT '会话ID',
+    `source_ip` varchar(45) COMMENT '攻击者IP',
+    `source_port` bigint unsigned COMMENT '攻击者端口',
+    `destination_ip` varchar(45) COMMENT '目标IP',
+    `destination_port` bigint unsigned COMMENT '目标端口',
+    `protocol` varchar(32) COMMENT '协议',
+    `action` varchar(64) COMMENT '攻击动作',
+    `username` varchar(255) COMMENT '攻击者使用的用户名',
+    `password` varchar(255) COMMENT '攻击者使用的密码',
+    `payload` text COMMENT '命令、请求或下载地址等攻击载荷',
+    `severity` varchar(10) NOT NULL COMMENT '严重程度(low/medium/high/critical)',
+    `container_id` varchar(64) COMMENT '关联的容器ID',
+    `container_name` varchar(100) COMMENT '容器名称',
+    `created_at` datetime(3) NOT NULL COMMENT '记录创建时间',
+    PRIMARY KEY (`id`),
+    INDEX `idx_timeline_time_id` (`event_time`,`id`),
+    UNIQUE INDEX `idx_attack_timeline_event_auth_id` (`auth_id`),
+    INDEX `idx_attack_timeline_event_source` (`source`),
+    INDEX `idx_attack_timeline_event_session_id` (`session_id`),
+    INDEX `idx_attack_timeline_event_source_ip` (`source_ip`),
+    INDEX `idx_attack_timeline_event_destination_ip` (`destination_ip`),
+    INDEX `idx_attack_timeline_event_protocol` (`protocol`),
+    INDEX `idx_attack_timeline_event_action` (`action`),
+    INDEX `idx_attack_timeline_event_username` (`username`),
+    INDEX `idx_attack_timeline_event_severity` (`severity`),
+    INDEX `idx_attack_timeline_event_container_id` (`container_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建攻击事件表
+CREATE TABLE `attack_event` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `source_ip` varchar(45) NOT NULL COMMENT '攻击者IP',
+    `source_port` bigint COMMENT '攻击者端口',
+    `dest_ip` varchar(45) NOT NULL COMMENT '目标IP',
+    `dest_port` bigint COMMENT '目标端口',
+    `protocol` varchar(32) NOT NULL COMMENT '协议',
+    `attack_type` varchar(100) NOT NULL COMMENT '攻击类型',
+    `payload` text COMMENT '攻击载荷',
+    `timestamp` datetime(6) NOT NULL COMMENT '事件发生时间',
+    `severity` varchar(10) NOT NULL COMMENT '严重程度(low/medium/high/critical)',
+    `container_id` varchar(64) COMMENT '关联的容器ID',
+    `container_name` varchar(100) COMMENT '容器名称',
+    `user_agent` varchar(512) COMMENT '客户端User-Agent',
+    `session_id` varchar(64) COMMENT '会话标识，为空时按攻击者IP归并',
+    `attack_session_id` bigint unsigned COMMENT '所属攻击会话ID',
+    `created_at` datetime(3) NOT NULL COMMENT '记录创建时间',
+    PRIMARY KEY (`id`),
+    INDEX `idx_attack_event_source_ip` (`source_ip`),
+    INDEX `idx_attack_event_protocol` (`protocol`),
+    INDEX `idx_attack_event_attack_type` (`attack_type`),
+    INDEX `idx_attack_event_timestamp` (`timestamp`),
+    INDEX `idx_attack_event_severity` (`severity`),
+    INDEX `idx_attack_event_container_id` (`container_id`),
+    INDEX `idx_attack_event_session_id` (`session_id`),
+    INDEX `idx_attack_event_attack_session_id` (`attack_session_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建攻击会话表
+CREATE TABLE `attack_session` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `session_id` varchar(64) NOT NULL COMMENT '会话标识(上报的会话ID或攻击者IP)',
+    `source_ip` varchar(45) NOT NULL COMMENT '攻击者IP',
+    `start_time` datetime(6) NOT NULL COMMENT '会话开始时间',
+    `last_event_time` datetime(6) NOT NULL COMMENT '最后一个事件的时间',
+    `end_time` datetime(6) NULL COMMENT '会话结束时间，为空表示会话未结束',
+    `event_count` bigint NOT NULL DEFAULT 0 COMMENT '事件数量',
+    `attack_types` text COMMENT '出现过的攻击类型',
+    `created_at` datetime(3) NOT NULL COMMENT '记录创建时间',
+    `updated_at` datetime(3) NULL COMMENT '记录更新时间',
+    PRIMARY KEY (`id`),
+    INDEX `idx_attack_session_session_id` (`session_id`),
+    INDEX `idx_attack_session_source_ip` (`source_ip`),
+    INDEX `idx_attack_session_start_time` (`start_time`),
+    INDEX `idx_attack_session_end_time` (`end_time`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建恶意样本表
+CREATE TABLE `malware_sample` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `sha256` varchar(64) NOT NULL COMMENT '样本SHA256',
+    `sha1` varchar(40) NOT NULL COMMENT '样本SHA1',
+    `md5` varchar(32) NOT NULL COMMENT '样本MD5',
+    `ss_deep` varchar(255) COMMENT '样本ssdeep模糊哈希',
+    `file_size` bigint NOT NULL COMMENT '文件大小(字节)',
+    `file_type` varchar(100) COMMENT '文件类型',
+    `quarantine_path` varchar(255) NOT NULL COMMENT '隔离区中的存储路径',
+    `sighting_count` bigint NOT NULL DEFAULT 0 COMMENT '被捕获的次数',
+    `first_seen` datetime(3) NOT NULL COMMENT '首次捕获时间',
+    `last_seen` datetime(3) NOT NULL COMMENT '最近捕获时间',
+    `created_at` datetime(3) NOT NULL COMMENT '记录创建时间',
+    `updated_at` datetime(3) NOT NULL COMMENT '记录更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_malware_sample_sha256` (`sha256`),
+    INDEX `idx_malware_sample_sha1` (`sha1`),
+    INDEX `idx_malware_sample_md5` (`md5`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建恶意样本捕获记录表
+CREATE TABLE `malware_sighting` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `sample_id` bigint unsigned NOT NULL COMMENT '关联的样本ID',
+    `sha256` varchar(64) NOT NULL COMMENT '样本SHA256',
+    `cowrie_log_id` bigint unsigned NOT NULL COMMENT '关联的Cowrie文件传输事件ID',
+    `session_id` varchar(36) COMMENT '会话ID',
+    `source_ip` varchar(45) COMMENT '攻击者IP',
+    `event_id` varchar(64) COMMENT '文件传输事件类型',
+    `url` varchar(1024) COMMENT '下载地址',
+    `filename` varchar(255) COMMENT '上传文件名',
+    `container_id` varchar(64) COMMENT '容器ID',
+    `event_time` datetime(6) NOT NULL COMMENT '事件发生时间',
+    `created_at` datetime(3) NOT NULL COMMENT '记录创建时间',
+    PRIMARY KEY (`id`),
+    INDEX `idx_malware_sighting_sample_id` (`sample_id`),
+    INDEX `idx_malware_sighting_sha256` (`sha256`),
+    UNIQUE INDEX `idx_malware_sighting_cowrie_log_id` (`cowrie_log_id`),
+    INDEX `idx_malware_sighting_session_id` (`session_id`),
+    INDEX `idx_malware_sighting_source_ip` (`source_ip`),
+    INDEX `idx_malware_sighting_container_id` (`container_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建蜜签表
+CREATE TABLE `honey_token` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `name` varchar(100) NOT NULL COMMENT '蜜签名称',
+    `type` varchar(20) NOT NULL COMMENT '蜜签类型(credential/file/url/email)',
+    `content` text COMMENT '蜜签内容',
+    `description` varchar(255) COMMENT '描述',
+    `is_active` boolean NOT NULL DEFAULT true COMMENT '是否启用',
+    `trigger_count` bigint NOT NULL DEFAULT 0 COMMENT '触发次数',
+    `create_time` datetime(3) NOT NULL COMMENT '创建时间',
+    `update_time` datetime(3) NOT NULL COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    INDEX `idx_honey_token_name` (`name`),
+    INDEX `idx_honey_token_type` (`type`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建蜜签触发记录表
+CREATE TABLE `honey_token_trigger` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `token_id` bigint unsigned NOT NULL COMMENT '触发的蜜签ID',
+    `token_name` varchar(100) COMMENT '触发时的蜜签名称',
+    `source_ip` varchar(45) COMMENT '触发者IP',
+    `user_agent` varchar(512) COMMENT '客户端User-Agent',
+    `trigger_time` datetime(3) NOT NULL COMMENT '触发时间',
+    `action` varchar(100) COMMENT '触发动作',
+    `details` text COMMENT '详细信息',
+    PRIMARY KEY (`id`),
+    INDEX `idx_honey_token_trigger_token_id` (`token_id`),
+    INDEX `idx_honey_token_trigger_source_ip` (`source_ip`),
+    INDEX `idx_honey_token_trigger_trigger_time` (`trigger_time`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,7 @@
+-- 删除统计视图
+DROP VIEW IF EXISTS `v_cowrie_command_statistics`;
+DROP VIEW IF EXISTS `v_cowrie_attacker_behavior`;
+DROP VIEW IF EXISTS `v_cowrie_statistics`;
+DROP VIEW IF EXISTS `v_attacker_ip_statistics`;
+DROP VIEW IF EXISTS `v_headling_auth_statistics`;
+DROP VIEW IF EXISTS `v_log_statistics`;
@@ -0,0 +1,32 @@
+-- 创建日志保留策略表和归档记录表，超过保留期限的日志归档为压缩NDJSON文件后删除
+
+-- 创建保留策略表
+CREATE TABLE `retention_policy` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `log_table` varchar(64) NOT NULL COMMENT '日志表名',
+    `max_age_days` bigint NOT NULL DEFAULT 0 COMMENT '最长保留天数',
+    `max_rows` bigint NOT NULL DEFAULT 0 COMMENT '最多保留行数',
+    `enabled` boolean NOT NULL COMMENT '是否启用',
+    `update_time` datetime(3) NOT NULL COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_retention_policy_log_table` (`log_table`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建归档记录表
+CREATE TABLE `retention_archive` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `log_table` varchar(64) NOT NULL COMMENT '日志表名',
+    `reason` varchar(20) NOT NULL COMMENT '归档原因(max_age/max_rows)',
+    `file_name` varchar(255) NOT NULL COMMENT '归档文件名',
+    `row_count` bigint NOT NULL COMMENT '归档行数',
+    `size_bytes` bigint NOT NULL COMMENT '文件大小',
+    `from_time` datetime(3) NULL COMMENT '最早一条记录的时间',
+    `to_time` datetime(3) NULL COMMENT '最晚一条记录的时间',
+    `created_at` datetime(3) NOT NULL COMMENT '归档时间',
+    `restored_at` datetime(3) NULL COMMENT '最近一次恢复时间',
+    `restored_rows` bigint NOT NULL DEFAULT 0 COMMENT '最近一次恢复写回的行数',
+    PRIMARY KEY (`id`),
+    INDEX `idx_retention_archive_log_table` (`log_table`),
+    UNIQUE INDEX `idx_retention_archive_file_name` (`file_name`),
+    INDEX `idx_retention_archive_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
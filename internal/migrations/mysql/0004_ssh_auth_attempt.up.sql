@@ -0,0 +1,28 @@
+-- 创建内置SSH蜜罐的认证尝试表，记录认证方式、公钥指纹、客户端版本和KEX算法，用户名和密码同时写入headling_auth_log
+
+CREATE TABLE `ssh_auth_attempt` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `auth_id` varchar(36) NOT NULL COMMENT '认证行为的唯一ID',
+    `session_id` varchar(36) NOT NULL COMMENT '所属连接的会话ID',
+    `timestamp` datetime(6) NOT NULL COMMENT '认证尝试时间',
+    `source_ip` varchar(45) NOT NULL COMMENT '攻击者IP',
+    `source_port` bigint unsigned NOT NULL COMMENT '攻击者使用的端口',
+    `method` varchar(32) NOT NULL COMMENT '认证方式(password/publickey/keyboard-interactive/none)',
+    `username` varchar(255) NOT NULL COMMENT '攻击者输入的用户名',
+    `key_type` varchar(64) COMMENT '公钥类型,publickey认证',
+    `key_fingerprint` varchar(128) COMMENT '公钥SHA256指纹,publickey认证',
+    `accepted` boolean NOT NULL COMMENT '是否认证成功',
+    `client_version` varchar(255) COMMENT '客户端版本字符串',
+    `kex_algorithms` text COMMENT '客户端KEXINIT中的算法列表(JSON)',
+    `hassh` varchar(64) COMMENT '客户端HASSH指纹',
+    `container_id` varchar(64) COMMENT '蜜罐标识',
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_ssh_auth_attempt_auth_id` (`auth_id`),
+    INDEX `idx_ssh_auth_attempt_session_id` (`session_id`),
+    INDEX `idx_ssh_auth_attempt_timestamp` (`timestamp`),
+    INDEX `idx_ssh_auth_attempt_source_ip` (`source_ip`),
+    INDEX `idx_ssh_auth_attempt_method` (`method`),
+    INDEX `idx_ssh_auth_attempt_key_fingerprint` (`key_fingerprint`),
+    INDEX `idx_ssh_auth_attempt_hassh` (`hassh`),
+    INDEX `idx_ssh_auth_attempt_container_id` (`container_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,93 @@
+-- 恢复0002版本的统计视图
+
+-- 创建日志统计视图
+CREATE OR REPLACE VIEW `v_log_statistics` AS
+SELECT
+    container_id,
+    container_name,
+    segment_type,
+    COUNT(*) as log_count,
+    MIN(timestamp) as first_log_time,
+    MAX(timestamp) as last_log_time,
+    MAX(created_at) as last_analysis_time
+FROM container_log_segment
+GROUP BY container_id, container_name, segment_type;
+
+-- 创建headling认证统计视图
+CREATE OR REPLACE VIEW `v_headling_auth_statistics` AS
+SELECT
+    DATE(timestamp) as log_date,
+    protocol,
+    COUNT(*) as total_attempts,
+    COUNT(DISTINCT source_ip) as unique_ips,
+    COUNT(DISTINCT username) as unique_usernames,
+    COUNT(DISTINCT session_id) as unique_sessions,
+    MIN(timestamp) as first_attempt,
+    MAX(timestamp) as last_attempt
+FROM headling_auth_log
+GROUP BY DATE(timestamp), protocol;
+
+-- 创建攻击者IP统计视图
+CREATE OR REPLACE VIEW `v_attacker_ip_statistics` AS
+SELECT
+    source_ip,
+    COUNT(*) as total_attempts,
+    COUNT(DISTINCT protocol) as protocols_used,
+    COUNT(DISTINCT username) as usernames_tried,
+    COUNT(DISTINCT destination_port) as ports_targeted,
+    MIN(timestamp) as first_seen,
+    MAX(timestamp) as last_seen,
+    TIMESTAMPDIFF(MINUTE, MIN(timestamp), MAX(timestamp)) as attack_duration_minutes
+FROM headling_auth_log
+GROUP BY source_ip
+ORDER BY total_attempts DESC;
+
+-- 创建Cowrie日志统计视图
+CREATE OR REPLACE VIEW `v_cowrie_statistics` AS
+SELECT
+    DATE(event_time) as log_date,
+    protocol,
+    COUNT(*) as total_events,
+    COUNT(DISTINCT source_ip) as unique_ips,
+    COUNT(DISTINCT session_id) as unique_sessions,
+    COUNT(CASE WHEN username IS NOT NULL THEN 1 END) as auth_attempts,
+    COUNT(CASE WHEN command IS NOT NULL THEN 1 END) as command_attempts,
+    COUNT(CASE WHEN command_found = TRUE THEN 1 END) as valid_commands,
+    MIN(event_time) as first_event,
+    MAX(event_time) as last_event
+FROM cowrie_log
+GROUP BY DATE(event_time), protocol;
+
+-- 创建Cowrie攻击者行为统计视图
+CREATE OR REPLACE VIEW `v_cowrie_attacker_behavior` AS
+SELECT
+    source_ip,
+    COUNT(*) as total_events,
+    COUNT(DISTINCT protocol) as protocols_used,
+    COUNT(DISTINCT session_id) as sessions_created,
+    COUNT(CASE WHEN username IS NOT NULL THEN 1 END) as auth_attempts,
+    COUNT(CASE WHEN command IS NOT NULL THEN 1 END) as commands_executed,
+    COUNT(CASE WHEN command_found = TRUE THEN 1 END) as valid_commands,
+    COUNT(DISTINCT username) as usernames_tried,
+    COUNT(DISTINCT fingerprint) as unique_fingerprints,
+    MIN(event_time) as first_seen,
+    MAX(event_time) as last_seen,
+    TIMESTAMPDIFF(MINUTE, MIN(event_time), MAX(event_time)) as activity_duration_minutes
+FROM cowrie_log
+GROUP BY source_ip
+ORDER BY total_events DESC;
+
+-- 创建Cowrie命令统计视图
+CREATE OR REPLACE VIEW `v_cowrie_command_statistics` AS
+SELECT
+    command,
+    COUNT(*) as usage_count,
+    COUNT(DISTINCT source_ip) as unique_ips,
+    COUNT(DISTINCT session_id) as unique_sessions,
+    command_found,
+    MIN(event_time) as first_used,
+    MAX(event_time) as last_used
+FROM cowrie_log
+WHERE command IS NOT NULL AND command != ''
+GROUP BY command, command_found
+ORDER BY usage_count DESC;
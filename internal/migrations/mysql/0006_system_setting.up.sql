@@ -0,0 +1,8 @@
+-- 创建系统设置表，记录默认蜜签写入等只需执行一次的初始化
+
+CREATE TABLE `system_setting` (
+    `name` varchar(64) NOT NULL COMMENT '设置名称',
+    `value` varchar(255) COMMENT '设置值',
+    `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+    PRIMARY KEY (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,474 @@
+-- 基线版本：创建所有表，与模型定义一致
+
+-- 创建蜜罐模板表
+CREATE TABLE `honeypot_template` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `name` text NOT NULL,
+    `protocol` text NOT NULL,
+    `log_parser` text,
+    `import_time` datetime NOT NULL,
+    `deploy_count` integer DEFAULT 0
+);
+
+-- 创建蜜罐实例表
+CREATE TABLE `honeypot_instance` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `name` text NOT NULL,
+    `honeypot_name` text NOT NULL,
+    `container_name` text NOT NULL,
+    `container_id` text,
+    `ip` text NOT NULL,
+    `honeypot_ip` text,
+    `port` integer NOT NULL,
+    `protocol` text NOT NULL,
+    `interface_type` text,
+    `status` text NOT NULL DEFAULT "created",
+    `image_name` text,
+    `image_id` text,
+    `log_parser` text,
+    `port_mappings` text,
+    `environment` text,
+    `create_time` datetime NOT NULL,
+    `update_time` datetime,
+    `description` text
+);
+
+-- 创建蜜罐日志表
+CREATE TABLE `honeypot_log` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `instance_id` integer NOT NULL,
+    `log_type` text NOT NULL,
+    `content` text NOT NULL,
+    `log_time` datetime NOT NULL,
+    CONSTRAINT `fk_honeypot_log_instance` FOREIGN KEY (`instance_id`) REFERENCES `honeypot_instance`(`id`)
+);
+
+-- 创建诱饵表
+CREATE TABLE `bait` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `name` text NOT NULL,
+    `file_type` text NOT NULL,
+    `is_deployed` numeric DEFAULT false,
+    `create_time` datetime NOT NULL,
+    `instance_id` integer,
+    CONSTRAINT `fk_bait_instance` FOREIGN KEY (`instance_id`) REFERENCES `honeypot_instance`(`id`)
+);
+
+-- 创建安全规则表
+CREATE TABLE `security_rule` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `rule_name` text NOT NULL,
+    `trigger_conditions` text NOT NULL,
+    `actions` text NOT NULL,
+    `is_enabled` numeric DEFAULT true
+);
+
+-- 创建规则日志表
+CREATE TABLE `rule_log` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `rule_id` integer NOT NULL,
+    `rule_name` text NOT NULL,
+    `content` text NOT NULL,
+    `log_time` datetime NOT NULL,
+    CONSTRAINT `fk_rule_log_rule` FOREIGN KEY (`rule_id`) REFERENCES `security_rule`(`id`)
+);
+
+-- 创建Docker镜像表
+CREATE TABLE `docker_image` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `image_id` text NOT NULL,
+    `repository` text,
+    `tag` text,
+    `digest` text,
+    `size` integer,
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL
+);
+
+-- 创建Docker镜像操作日志表
+CREATE TABLE `docker_image_log` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `image_id` text,
+    `image_name` text,
+    `operation` text NOT NULL,
+    `details` text,
+    `status` text NOT NULL,
+    `message` text,
+    `created_at` datetime NOT NULL
+);
+
+-- 创建容器日志分析结果表
+CREATE TABLE `container_log_segment` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `container_id` text NOT NULL,
+    `container_name` text,
+    `segment_type` text NOT NULL,
+    `content` text NOT NULL,
+    `timestamp` datetime,
+    `line_number` integer,
+    `component` text,
+    `severity_level` text,
+    `created_at` datetime NOT NULL
+);
+
+-- 创建Docker容器管理表
+CREATE TABLE `docker_container` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `container_id` text NOT NULL,
+    `container_name` text NOT NULL,
+    `image_id` text,
+    `image_name` text,
+    `status` text,
+    `ports` text,
+    `environment` text,
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL
+);
+
+-- 创建Headling认证日志表
+CREATE TABLE `headling_auth_log` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `timestamp` datetime NOT NULL,
+    `auth_id` text NOT NULL,
+    `session_id` text NOT NULL,
+    `source_ip` text NOT NULL,
+    `source_port` integer NOT NULL,
+    `destination_ip` text NOT NULL,
+    `destination_port` integer NOT NULL,
+    `protocol` text NOT NULL,
+    `username` text NOT NULL,
+    `password` text NOT NULL,
+    `password_hash` text,
+    `container_id` text,
+    `container_name` text,
+    `created_at` datetime NOT NULL
+);
+CREATE INDEX `idx_headling_auth_log_container_id` ON `headling_auth_log`(`container_id`);
+CREATE INDEX `idx_headling_auth_log_username` ON `headling_auth_log`(`username`);
+CREATE INDEX `idx_headling_auth_log_protocol` ON `headling_auth_log`(`protocol`);
+CREATE INDEX `idx_headling_auth_log_destination_ip` ON `headling_auth_log`(`destination_ip`);
+CREATE INDEX `idx_headling_auth_log_source_ip` ON `headling_auth_log`(`source_ip`);
+CREATE INDEX `idx_headling_auth_log_session_id` ON `headling_auth_log`(`session_id`);
+CREATE UNIQUE INDEX `idx_headling_auth_log_auth_id` ON `headling_auth_log`(`auth_id`);
+
+-- 创建Cowrie蜜罐日志表
+CREATE TABLE `cowrie_log` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `event_id` text,
+    `event_time` datetime NOT NULL,
+    `auth_id` text NOT NULL,
+    `session_id` text NOT NULL,
+    `source_ip` text NOT NULL,
+    `source_port` integer NOT NULL,
+    `destination_ip` text NOT NULL,
+    `destination_port` integer NOT NULL,
+    `protocol` text NOT NULL,
+    `client_info` text,
+    `fingerprint` text,
+    `username` text,
+    `password` text,
+    `password_hash` text,
+    `command` text,
+    `command_found` numeric,
+    `message` text,
+    `duration` real,
+    `hassh` text,
+    `kex_algorithms` text,
+    `url` text,
+    `shasum` text,
+    `outfile` text,
+    `filename` text,
+    `tunnel_dest_ip` text,
+    `tunnel_dest_port` integer,
+    `tunnel_data` text,
+    `tty_log` text,
+    `raw_log` text NOT NULL,
+    `container_id` text,
+    `container_name` text,
+    `created_at` datetime NOT NULL
+);
+CREATE INDEX `idx_cowrie_log_container_id` ON `cowrie_log`(`container_id`);
+CREATE INDEX `idx_cowrie_log_shasum` ON `cowrie_log`(`shasum`);
+CREATE INDEX `idx_cowrie_log_command_found` ON `cowrie_log`(`command_found`);
+CREATE INDEX `idx_cowrie_log_username` ON `cowrie_log`(`username`);
+CREATE INDEX `idx_cowrie_log_protocol` ON `cowrie_log`(`protocol`);
+CREATE INDEX `idx_cowrie_log_destination_ip` ON `cowrie_log`(`destination_ip`);
+CREATE INDEX `idx_cowrie_log_source_ip` ON `cowrie_log`(`source_ip`);
+CREATE INDEX `idx_cowrie_log_session_id` ON `cowrie_log`(`session_id`);
+CREATE UNIQUE INDEX `idx_cowrie_log_auth_id` ON `cowrie_log`(`auth_id`);
+CREATE INDEX `idx_cowrie_log_event_id` ON `cowrie_log`(`event_id`);
+
+-- 创建容器日志文件读取游标表
+CREATE TABLE `container_log_cursor` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `container_id` text NOT NULL,
+    `log_source` text NOT NULL,
+    `file_path` text NOT NULL,
+    `offset` integer NOT NULL DEFAULT 0,
+    `head_hash` text,
+    `file_size` integer,
+    `mod_time` datetime,
+    `line_count` integer DEFAULT 0,
+    `last_record_id` text,
+    `updated_at` datetime NOT NULL
+);
+CREATE UNIQUE INDEX `idx_container_log_cursor` ON `container_log_cursor`(`container_id`,`log_source`);
+
+-- 创建Cowrie会话TTY日志表
+CREATE TABLE `cowrie_ttylog` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `session_id` text NOT NULL,
+    `cowrie_log_id` integer NOT NULL,
+    `container_id` text,
+    `tty_log_path` text NOT NULL,
+    `local_path` text NOT NULL,
+    `shasum` text,
+    `size` integer,
+    `duration` real,
+    `created_at` datetime NOT NULL
+);
+CREATE INDEX `idx_cowrie_ttylog_container_id` ON `cowrie_ttylog`(`container_id`);
+CREATE UNIQUE INDEX `idx_cowrie_ttylog_session_id` ON `cowrie_ttylog`(`session_id`);
+
+-- 创建Dionaea蜜罐日志表
+CREATE TABLE `dionaea_log` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `event_type` text NOT NULL,
+    `event_time` datetime NOT NULL,
+    `auth_id` text NOT NULL,
+    `session_id` text NOT NULL,
+    `source_ip` text NOT NULL,
+    `source_port` integer,
+    `destination_ip` text,
+    `destination_port` integer,
+    `protocol` text,
+    `transport` text,
+    `connection_type` text,
+    `username` text,
+    `password` text,
+    `url` text,
+    `md5_hash` text,
+    `ftp_commands` text,
+    `raw_log` text NOT NULL,
+    `container_id` text,
+    `container_name` text,
+    `created_at` datetime NOT NULL
+);
+CREATE INDEX `idx_dionaea_log_container_id` ON `dionaea_log`(`container_id`);
+CREATE INDEX `idx_dionaea_log_md5_hash` ON `dionaea_log`(`md5_hash`);
+CREATE INDEX `idx_dionaea_log_username` ON `dionaea_log`(`username`);
+CREATE INDEX `idx_dionaea_log_protocol` ON `dionaea_log`(`protocol`);
+CREATE INDEX `idx_dionaea_log_destination_port` ON `dionaea_log`(`destination_port`);
+CREATE INDEX `idx_dionaea_log_source_ip` ON `dionaea_log`(`source_ip`);
+CREATE INDEX `idx_dionaea_log_session_id` ON `dionaea_log`(`session_id`);
+CREATE UNIQUE INDEX `idx_dionaea_log_auth_id` ON `dionaea_log`(`auth_id`);
+CREATE INDEX `idx_dionaea_log_event_time` ON `dionaea_log`(`event_time`);
+CREATE INDEX `idx_dionaea_log_event_type` ON `dionaea_log`(`event_type`);
+
+-- 创建qeeqbox蜜罐日志表
+CREATE TABLE `qeeqbox_log` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `auth_id` text NOT NULL,
+    `event_time` datetime NOT NULL,
+    `server` text NOT NULL,
+    `protocol` text NOT NULL,
+    `action` text NOT NULL,
+    `status` text,
+    `source_ip` text NOT NULL,
+    `source_port` integer,
+    `destination_ip` text,
+    `destination_port` integer,
+    `username` text,
+    `password` text,
+    `detail` text,
+    `data` text,
+    `raw_log` text NOT NULL,
+    `container_id` text,
+    `container_name` text,
+    `created_at` datetime NOT NULL
+);
+CREATE INDEX `idx_qeeqbox_log_container_id` ON `qeeqbox_log`(`container_id`);
+CREATE INDEX `idx_qeeqbox_log_username` ON `qeeqbox_log`(`username`);
+CREATE INDEX `idx_qeeqbox_log_source_ip` ON `qeeqbox_log`(`source_ip`);
+CREATE INDEX `idx_qeeqbox_log_action` ON `qeeqbox_log`(`action`);
+CREATE INDEX `idx_qeeqbox_log_protocol` ON `qeeqbox_log`(`protocol`);
+CREATE INDEX `idx_qeeqbox_log_event_time` ON `qeeqbox_log`(`event_time`);
+CREATE UNIQUE INDEX `idx_qeeqbox_log_auth_id` ON `qeeqbox_log`(`auth_id`);
+
+-- 创建syslog消息表
+CREATE TABLE `syslog_message` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `sensor_id` text NOT NULL,
+    `source_ip` text NOT NULL,
+    `transport` text NOT NULL,
+    `format` text NOT NULL,
+    `facility` integer,
+    `severity` integer,
+    `hostname` text,
+    `app_name` text,
+    `proc_id` text,
+    `msg_id` text,
+    `structured_data` text,
+    `message` text NOT NULL,
+    `parser` text,
+    `event_time` datetime NOT NULL,
+    `received_at` datetime NOT NULL,
+    `created_at` datetime NOT NULL
+);
+CREATE INDEX `idx_syslog_message_event_time` ON `syslog_message`(`event_time`);
+CREATE INDEX `idx_syslog_message_parser` ON `syslog_message`(`parser`);
+CREATE INDEX `idx_syslog_message_app_name` ON `syslog_message`(`app_name`);
+CREATE INDEX `idx_syslog_message_source_ip` ON `syslog_message`(`source_ip`);
+CREATE INDEX `idx_syslog_message_sensor_id` ON `syslog_message`(`sensor_id`);
+
+-- 创建攻击时间线事件表
+CREATE TABLE `attack_timeline_event` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `event_time` datetime NOT NULL,
+    `auth_id` text NOT NULL,
+    `source` text NOT NULL,
+    `source_ref` text,
+    `session_id` text,
+    `source_ip` text,
+    `source_port` integer,
+    `destination_ip` text,
+    `destination_port` integer,
+    `protocol` text,
+    `action` text,
+    `username` text,
+    `password` text,
+    `payload` text,
+    `severity` text NOT NULL,
+    `container_id` text,
+    `container_name` text,
+    `created_at` datetime NOT NULL
+);
+CREATE INDEX `idx_attack_timeline_event_container_id` ON `attack_timeline_event`(`container_id`);
+CREATE INDEX `idx_attack_timeline_event_severity` ON `attack_timeline_event`(`severity`);
+CREATE INDEX `idx_attack_timeline_event_username` ON `attack_timeline_event`(`username`);
+CREATE INDEX `idx_attack_timeline_event_action` ON `attack_timeline_event`(`action`);
+CREATE INDEX `idx_attack_timeline_event_protocol` ON `attack_timeline_event`(`protocol`);
+CREATE INDEX `idx_attack_timeline_event_destination_ip` ON `attack_timeline_event`(`destination_ip`);
+CREATE INDEX `idx_attack_timeline_event_source_ip` ON `attack_timeline_event`(`source_ip`);
+CREATE INDEX `idx_attack_timeline_event_session_id` ON `attack_timeline_event`(`session_id`);
+CREATE INDEX `idx_attack_timeline_event_source` ON `attack_timeline_event`(`source`);
+CREATE UNIQUE INDEX `idx_attack_timeline_event_auth_id` ON `attack_timeline_event`(`auth_id`);
+CREATE INDEX `idx_timeline_time_id` ON `attack_timeline_event`(`event_time`,`id`);
+
+-- 创建攻击事件表
+CREATE TABLE `attack_event` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `source_ip` text NOT NULL,
+    `source_port` integer,
+    `dest_ip` text NOT NULL,
+    `dest_port` integer,
+    `protocol` text NOT NULL,
+    `attack_type` text NOT NULL,
+    `payload` text,
+    `timestamp` datetime NOT NULL,
+    `severity` text NOT NULL,
+    `container_id` text,
+    `container_name` text,
+    `user_agent` text,
+    `session_id` text,
+    `attack_session_id` integer,
+    `created_at` datetime NOT NULL
+);
+CREATE INDEX `idx_attack_event_attack_session_id` ON `attack_event`(`attack_session_id`);
+CREATE INDEX `idx_attack_event_session_id` ON `attack_event`(`session_id`);
+CREATE INDEX `idx_attack_event_container_id` ON `attack_event`(`container_id`);
+CREATE INDEX `idx_attack_event_severity` ON `attack_event`(`severity`);
+CREATE INDEX `idx_attack_event_timestamp` ON `attack_event`(`timestamp`);
+CREATE INDEX `idx_attack_event_attack_type` ON `attack_event`(`attack_type`);
+CREATE INDEX `idx_attack_event_protocol` ON `attack_event`(`protocol`);
+CREATE INDEX `idx_attack_event_source_ip` ON `attack_event`(`source_ip`);
+
+-- 创建攻击会话表
+CREATE TABLE `attack_session` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `session_id` text NOT NULL,
+    `source_ip` text NOT NULL,
+    `start_time` datetime NOT NULL,
+    `last_event_time` datetime NOT NULL,
+    `end_time` datetime,
+    `event_count` integer NOT NULL DEFAULT 0,
+    `attack_types` text,
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime
+);
+CREATE INDEX `idx_attack_session_end_time` ON `attack_session`(`end_time`);
+CREATE INDEX `idx_attack_session_start_time` ON `attack_session`(`start_time`);
+CREATE INDEX `idx_attack_session_source_ip` ON `attack_session`(`source_ip`);
+CREATE INDEX `idx_attack_session_session_id` ON `attack_session`(`session_id`);
+
+-- 创建恶意样本表
+CREATE TABLE `malware_sample` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `sha256` text NOT NULL,
+    `sha1` text NOT NULL,
+    `md5` text NOT NULL,
+    `ss_deep` text,
+    `file_size` integer NOT NULL,
+    `file_type` text,
+    `quarantine_path` text NOT NULL,
+    `sighting_count` integer NOT NULL DEFAULT 0,
+    `first_seen` datetime NOT NULL,
+    `last_seen` datetime NOT NULL,
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL
+);
+CREATE INDEX `idx_malware_sample_md5` ON `malware_sample`(`md5`);
+CREATE INDEX `idx_malware_sample_sha1` ON `malware_sample`(`sha1`);
+CREATE UNIQUE INDEX `idx_malware_sample_sha256` ON `malware_sample`(`sha256`);
+
+-- 创建恶意样本捕获记录表
+CREATE TABLE `malware_sighting` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `sample_id` integer NOT NULL,
+    `sha256` text NOT NULL,
+    `cowrie_log_id` integer NOT NULL,
+    `session_id` text,
+    `source_ip` text,
+    `event_id` text,
+    `url` text,
+    `filename` text,
+    `container_id` text,
+    `event_time` datetime NOT NULL,
+    `created_at` datetime NOT NULL
+);
+CREATE INDEX `idx_malware_sighting_container_id` ON `malware_sighting`(`container_id`);
+CREATE INDEX `idx_malware_sighting_source_ip` ON `malware_sighting`(`source_ip`);
+CREATE INDEX `idx_malware_sighting_session_id` ON `malware_sighting`(`session_id`);
+CREATE UNIQUE INDEX `idx_malware_sighting_cowrie_log_id` ON `malware_sighting`(`cowrie_log_id`);
+CREATE INDEX `idx_malware_sighting_sha256` ON `malware_sighting`(`sha256`);
+CREATE INDEX `idx_malware_sighting_sample_id` ON `malware_sighting`(`sample_id`);
+
+-- 创建蜜签表
+CREATE TABLE `honey_token` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `name` text NOT NULL,
+    `type` text NOT NULL,
+    `content` text,
+    `description` text,
+    `is_active` numeric NOT NULL DEFAULT true,
+    `trigger_count` integer NOT NULL DEFAULT 0,
+    `create_time` datetime NOT NULL,
+    `update_time` datetime NOT NULL
+);
+CREATE INDEX `idx_honey_token_type` ON `honey_token`(`type`);
+CREATE INDEX `idx_honey_token_name` ON `honey_token`(`name`);
+
+-- 创建蜜签触发记录表
+CREATE TABLE `honey_token_trigger` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `token_id` integer NOT NULL,
+    `token_name` text,
+    `source_ip` text,
+    `user_agent` text,
+    `trigger_time` datetime NOT NULL,
+    `action` text,
+    `details` text
+);
+CREATE INDEX `idx_honey_token_trigger_trigger_time` ON `honey_token_trigger`(`trigger_time`);
+CREATE INDEX `idx_honey_token_trigger_source_ip` ON `honey_token_trigger`(`source_ip`);
+CREATE INDEX `idx_honey_token_trigger_token_id` ON `honey_token_trigger`(`token_id`);
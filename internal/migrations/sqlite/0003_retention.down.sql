@@ -0,0 +1,3 @@
+-- 删除日志保留策略表和归档记录表，已生成的归档文件不会被删除
+DROP TABLE IF EXISTS `retention_archive`;
+DROP TABLE IF EXISTS `retention_policy`;
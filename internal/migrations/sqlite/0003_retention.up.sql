@@ -0,0 +1,30 @@
+-- 创建日志保留策略表和归档记录表，超过保留期限的日志归档为压缩NDJSON文件后删除
+
+-- 创建保留策略表
+CREATE TABLE `retention_policy` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `log_table` text NOT NULL,
+    `max_age_days` integer NOT NULL DEFAULT 0,
+    `max_rows` integer NOT NULL DEFAULT 0,
+    `enabled` numeric NOT NULL,
+    `update_time` datetime NOT NULL
+);
+CREATE UNIQUE INDEX `idx_retention_policy_log_table` ON `retention_policy`(`log_table`);
+
+-- 创建归档记录表
+CREATE TABLE `retention_archive` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `log_table` text NOT NULL,
+    `reason` text NOT NULL,
+    `file_name` text NOT NULL,
+    `row_count` integer NOT NULL,
+    `size_bytes` integer NOT NULL,
+    `from_time` datetime,
+    `to_time` datetime,
+    `created_at` datetime NOT NULL,
+    `restored_at` datetime,
+    `restored_rows` integer NOT NULL DEFAULT 0
+);
+CREATE INDEX `idx_retention_archive_created_at` ON `retention_archive`(`created_at`);
+CREATE UNIQUE INDEX `idx_retention_archive_file_name` ON `retention_archive`(`file_name`);
+CREATE INDEX `idx_retention_archive_log_table` ON `retention_archive`(`log_table`);
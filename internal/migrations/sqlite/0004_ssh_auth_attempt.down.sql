@@ -0,0 +1,2 @@
+-- 删除内置SSH蜜罐的认证尝试表，headling_auth_log中的记录保留
+DROP TABLE IF EXISTS `ssh_auth_attempt`;
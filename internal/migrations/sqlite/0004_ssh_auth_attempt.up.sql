@@ -0,0 +1,27 @@
+-- 创建内置SSH蜜罐的认证尝试表，记录认证方式、公钥指纹、客户端版本和KEX算法，用户名和密码同时写入headling_auth_log
+
+CREATE TABLE `ssh_auth_attempt` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `auth_id` text NOT NULL,
+    `session_id` text NOT NULL,
+    `timestamp` datetime NOT NULL,
+    `source_ip` text NOT NULL,
+    `source_port` integer NOT NULL,
+    `method` text NOT NULL,
+    `username` text NOT NULL,
+    `key_type` text,
+    `key_fingerprint` text,
+    `accepted` numeric NOT NULL,
+    `client_version` text,
+    `kex_algorithms` text,
+    `hassh` text,
+    `container_id` text
+);
+CREATE UNIQUE INDEX `idx_ssh_auth_attempt_auth_id` ON `ssh_auth_attempt`(`auth_id`);
+CREATE INDEX `idx_ssh_auth_attempt_session_id` ON `ssh_auth_attempt`(`session_id`);
+CREATE INDEX `idx_ssh_auth_attempt_timestamp` ON `ssh_auth_attempt`(`timestamp`);
+CREATE INDEX `idx_ssh_auth_attempt_source_ip` ON `ssh_auth_attempt`(`source_ip`);
+CREATE INDEX `idx_ssh_auth_attempt_method` ON `ssh_auth_attempt`(`method`);
+CREATE INDEX `idx_ssh_auth_attempt_key_fingerprint` ON `ssh_auth_attempt`(`key_fingerprint`);
+CREATE INDEX `idx_ssh_auth_attempt_hassh` ON `ssh_auth_attempt`(`hassh`);
+CREATE INDEX `idx_ssh_auth_attempt_container_id` ON `ssh_auth_attempt`(`container_id`);
@@ -0,0 +1,8 @@
+-- 创建系统设置表，记录默认蜜签写入等只需执行一次的初始化
+
+CREATE TABLE `system_setting` (
+    `name` text,
+    `value` text,
+    `updated_at` datetime NOT NULL,
+    PRIMARY KEY (`name`)
+);
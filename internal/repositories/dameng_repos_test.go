@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	dameng "github.com/godoes/gorm-dameng"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sqlRecorder 记录GORM生成的SQL，用于在没有达梦服务的情况下检查生成的语句
+type sqlRecorder struct {
+	logger.Interface
+	statements []string
+}
+
+func (r *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	r.statements = append(r.statements, sql)
+}
+
+// sql 返回记录的所有SQL
+func (r *sqlRecorder) sql() string {
+	return strings.Join(r.statements, "\n")
+}
+
+// openDamengDryRun 以DryRun模式打开达梦连接，只生成SQL不执行
+func openDamengDryRun(t *testing.T) (*gorm.DB, *sqlRecorder) {
+	t.Helper()
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	db, err := gorm.Open(dameng.Open("dm://SYSDBA:SYSDBA@127.0.0.1:5236"), &gorm.Config{
+		NamingStrategy:         DamengNamingStrategy{},
+		Logger:                 recorder,
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+	})
+	if err != nil {
+		t.Fatalf("打开达梦连接失败: %v", err)
+	}
+	if err := RegisterDamengCallbacks(db); err != nil {
+		t.Fatalf("注册达梦回调失败: %v", err)
+	}
+	return db, recorder
+}
+
+// assertSQLContains 检查生成的SQL包含所有期望的片段
+func assertSQLContains(t *testing.T, recorder *sqlRecorder, wants ...string) {
+	t.Helper()
+	sql := recorder.sql()
+	for _, want := range wants {
+		if !strings.Contains(sql, want) {
+			t.Errorf("生成的SQL缺少 %s:\n%s", want, sql)
+		}
+	}
+}
+
+// TestDamengRepositories 测试达梦后端的查询使用达梦的函数并为保留字列名加引号
+func TestDamengRepositories(t *testing.T) {
+	db, recorder := openDamengDryRun(t)
+
+	if _, err := NewCowrieLogRepo(db).GetTopAttackers(10); err != nil {
+		t.Fatalf("生成攻击者统计SQL失败: %v", err)
+	}
+	if err := NewHoneypotInstanceRepo(db).UpdateStatus(1, "running"); err != nil {
+		t.Fatalf("生成更新SQL失败: %v", err)
+	}
+
+	assertSQLContains(t, recorder,
+		"DATEDIFF(MI, MIN(event_time), MAX(event_time))",
+		`SET "STATUS"=`,
+	)
+	if sql := recorder.sql(); strings.Contains(sql, "v_cowrie") {
+		t.Errorf("达梦后端不应查询MySQL视图:\n%s", sql)
+	}
+}
+
+// TestDamengHoneyTokenCountByType 测试达梦后端按类型统计蜜签时为保留字列名TYPE和别名COUNT加引号
+func TestDamengHoneyTokenCountByType(t *testing.T) {
+	db, recorder := openDamengDryRun(t)
+
+	if _, err := NewHoneyTokenRepo(db).CountByType(); err != nil {
+		t.Fatalf("生成按类型统计SQL失败: %v", err)
+	}
+
+	assertSQLContains(t, recorder, `SELECT "TYPE", COUNT(*) as "COUNT" FROM "honey_token"`, `GROUP BY "TYPE"`)
+}
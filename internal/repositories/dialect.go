@@ -0,0 +1,187 @@
+package repositories
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	// DialectMySQL MySQL的GORM方言名
+	DialectMySQL = "mysql"
+	// DialectSQLite SQLite的GORM方言名
+	DialectSQLite = "sqlite"
+	// DialectDameng 达梦的GORM方言名
+	DialectDameng = "dm"
+)
+
+// dialectOf 获取连接使用的GORM方言名
+func dialectOf(db *gorm.DB) string {
+	return db.Dialector.Name()
+}
+
+// columnOf 返回在手写SQL中引用列名的写法
+// timestamp、type等列名在达梦中是保留字，需要加引号，达梦的列名按DamengNamingStrategy使用大写
+func columnOf(db *gorm.DB, column string) string {
+	if dialectOf(db) == DialectDameng {
+		return `"` + strings.ToUpper(column) + `"`
+	}
+	return column
+}
+
+// dateOf 返回取时间列日期部分的SQL表达式，结果为YYYY-MM-DD
+// MySQL的DATE()在parseTime=True时会被解析成时间，这里直接格式化为字符串；
+// SQLite的date()会把带时区的时间换算成UTC日期，这里直接截取保存的本地日期
+func dateOf(db *gorm.DB, column string) string {
+	switch dialectOf(db) {
+	case DialectSQLite:
+		return "substr(" + column + ", 1, 10)"
+	case DialectDameng:
+		return "TO_CHAR(" + column + ", 'YYYY-MM-DD')"
+	default:
+		return "DATE_FORMAT(" + column + ", '%Y-%m-%d')"
+	}
+}
+
+// textKeyOf 返回把TEXT列用作分组或比较条件时的写法
+// 达梦的TEXT是大字段，不能直接GROUP BY，先转换为VARCHAR，超过4000字节的部分不参与分组
+func textKeyOf(db *gorm.DB, column string) string {
+	if dialectOf(db) == DialectDameng {
+		return "CAST(" + column + " AS VARCHAR(4000))"
+	}
+	return column
+}
+
+// durationMinutesOf 返回计算两个时间相差整分钟数的SQL表达式，对应MySQL的TIMESTAMPDIFF(MINUTE, start, end)
+func durationMinutesOf(db *gorm.DB, start, end string) string {
+	switch dialectOf(db) {
+	case DialectSQLite:
+		// julianday的差值是浮点数，先四舍五入到秒再整除，避免5分钟被算成4.9999分钟
+		return "(CAST(ROUND((julianday(" + end + ") - julianday(" + start + ")) * 86400) AS INTEGER) / 60)"
+	case DialectDameng:
+		return "DATEDIFF(MI, " + start + ", " + end + ")"
+	default:
+		return "TIMESTAMPDIFF(MINUTE, " + start + ", " + end + ")"
+	}
+}
+
+// sqliteTimeLayouts SQLite驱动写入时间使用的格式
+var sqliteTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseSQLiteTime 解析SQLite以文本保存的时间
+func parseSQLiteTime(s string) (time.Time, bool) {
+	for _, layout := range sqliteTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+var statisticsSchemaCache sync.Map
+
+// findStatistics 执行聚合查询并写入dest
+// SQLite的MIN/MAX等聚合结果没有列类型，时间以文本返回、布尔值以整数返回，无法直接扫描到结构体，
+// 这里先读成map再按字段类型转换
+func findStatistics[T any](query *gorm.DB, dest *[]T) error {
+	if dialectOf(query) != DialectSQLite {
+		return query.Find(dest).Error
+	}
+
+	var rows []map[string]interface{}
+	if err := query.Find(&rows).Error; err != nil {
+		return err
+	}
+	s, err := schema.Parse(new(T), &statisticsSchemaCache, query.NamingStrategy)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	results := make([]T, len(rows))
+	for i, row := range rows {
+		rv := reflect.ValueOf(&results[i]).Elem()
+		for _, field := range s.Fields {
+			v, ok := row[field.DBName]
+			if !ok || v == nil {
+				continue
+			}
+			switch data := v.(type) {
+			case string:
+				if field.IndirectFieldType == reflect.TypeOf(time.Time{}) {
+					t, ok := parseSQLiteTime(data)
+					if !ok {
+						continue
+					}
+					v = t
+				}
+			case int64:
+				if field.IndirectFieldType.Kind() == reflect.Bool {
+					v = data != 0
+				}
+			}
+			if err := field.Set(ctx, rv, v); err != nil {
+				return err
+			}
+		}
+	}
+	*dest = results
+	return nil
+}
+
+// DamengNamingStrategy 达梦数据库的命名策略，列名使用大写
+// 达梦默认大小写敏感，未加引号的标识符按大写处理。列名统一大写后，
+// 仓库中手写的小写列名和查询结果的别名都能与GORM生成的列名对应。表名由各模型的TableName决定，GORM始终加引号引用。
+// GORM会给Update/Updates中的列名加引号，这些地方要使用字段名(如Update("Status", ...))，由GORM换算成列名
+type DamengNamingStrategy struct {
+	schema.NamingStrategy
+}
+
+// ColumnName 返回大写的列名
+func (ns DamengNamingStrategy) ColumnName(table, column string) string {
+	return strings.ToUpper(ns.NamingStrategy.ColumnName(table, column))
+}
+
+// RegisterDamengCallbacks 注册达梦连接需要的回调
+// 查询到map的结果列名是大写的，这里转换为小写，保持与MySQL/SQLite返回的键一致
+func RegisterDamengCallbacks(db *gorm.DB) error {
+	return db.Callback().Query().After("gorm:query").Register("andorralee:lower_map_keys", lowerMapKeys)
+}
+
+// lowerMapKeys 把查询结果map的键转换为小写
+func lowerMapKeys(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	switch dest := db.Statement.Dest.(type) {
+	case *[]map[string]interface{}:
+		for _, row := range *dest {
+			lowerKeys(row)
+		}
+	case *map[string]interface{}:
+		lowerKeys(*dest)
+	case map[string]interface{}:
+		lowerKeys(dest)
+	}
+}
+
+func lowerKeys(row map[string]interface{}) {
+	for key, value := range row {
+		if lower := strings.ToLower(key); lower != key {
+			delete(row, key)
+			row[lower] = value
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// -------------------- 蜜罐实例仓库 --------------------
+
+// MemoryHoneypotInstanceRepo 蜜罐实例内存仓库，未配置数据库时使用，进程重启后数据丢失
+type MemoryHoneypotInstanceRepo struct {
+	mu        sync.RWMutex
+	instances map[uint]HoneypotInstance
+	nextID    uint
+}
+
+// NewMemoryHoneypotInstanceRepo 创建蜜罐实例内存仓库
+func NewMemoryHoneypotInstanceRepo() *MemoryHoneypotInstanceRepo {
+	return &MemoryHoneypotInstanceRepo{instances: make(map[uint]HoneypotInstance), nextID: 1}
+}
+
+// List 按ID顺序获取所有蜜罐实例
+func (r *MemoryHoneypotInstanceRepo) List() ([]HoneypotInstance, error) {
+	return r.filter(func(*HoneypotInstance) bool { return true }), nil
+}
+
+// Query 按查询条件分页获取蜜罐实例
+func (r *MemoryHoneypotInstanceRepo) Query(spec QuerySpec) (*Page[HoneypotInstance], error) {
+	return queryPage(r.filter(func(*HoneypotInstance) bool { return true }), spec)
+}
+
+// GetByID 根据ID获取蜜罐实例
+func (r *MemoryHoneypotInstanceRepo) GetByID(id uint) (*HoneypotInstance, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	instance, ok := r.instances[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &instance, nil
+}
+
+// Create 创建蜜罐实例
+func (r *MemoryHoneypotInstanceRepo) Create(instance *HoneypotInstance) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	instance.ID = r.nextID
+	instance.CreateTime = time.Now()
+	if instance.Status == "" {
+		instance.Status = "已部署"
+	}
+	r.instances[instance.ID] = *instance
+	r.nextID++
+	return nil
+}
+
+// Update 更新蜜罐实例
+func (r *MemoryHoneypotInstanceRepo) Update(instance *HoneypotInstance) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.instances[instance.ID]; !ok {
+		return fmt.Errorf("蜜罐实例 %d 不存在", instance.ID)
+	}
+	r.instances[instance.ID] = *instance
+	return nil
+}
+
+// Delete 删除蜜罐实例
+func (r *MemoryHoneypotInstanceRepo) Delete(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.instances, id)
+	return nil
+}
+
+// UpdateStatus 更新蜜罐实例状态
+func (r *MemoryHoneypotInstanceRepo) UpdateStatus(id uint, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	instance, ok := r.instances[id]
+	if !ok {
+		return fmt.Errorf("蜜罐实例 %d 不存在", id)
+	}
+	instance.Status = status
+	r.instances[id] = instance
+	return nil
+}
+
+// GetByTemplateID 内存实例不关联模板，始终返回空列表
+func (r *MemoryHoneypotInstanceRepo) GetByTemplateID(templateID uint) ([]HoneypotInstance, error) {
+	return nil, nil
+}
+
+// GetByStatus 根据状态获取蜜罐实例
+func (r *MemoryHoneypotInstanceRepo) GetByStatus(status string) ([]HoneypotInstance, error) {
+	return r.filter(func(instance *HoneypotInstance) bool { return instance.Status == status }), nil
+}
+
+// GetByContainerID 根据容器ID获取蜜罐实例
+func (r *MemoryHoneypotInstanceRepo) GetByContainerID(containerID string) (*HoneypotInstance, error) {
+	instances := r.filter(func(instance *HoneypotInstance) bool { return instance.ContainerID == containerID })
+	if len(instances) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &instances[0], nil
+}
+
+// filter 按ID顺序返回满足条件的实例副本
+func (r *MemoryHoneypotInstanceRepo) filter(match func(*HoneypotInstance) bool) []HoneypotInstance {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	instances := make([]HoneypotInstance, 0, len(r.instances))
+	for _, instance := range r.instances {
+		if match(&instance) {
+			instances = append(instances, instance)
+		}
+	}
+	sort.Slice(instances, func(i, j int) bool { return instances[i].ID < instances[j].ID })
+	return instances
+}
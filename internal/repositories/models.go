@@ -4,11 +4,52 @@ import (
 	"time"
 )
 
+// Models 返回所有需要建表的模型，顺序与建表顺序一致，被引用的表在前
+func Models() []interface{} {
+	return append(BaselineModels(),
+		&RetentionPolicy{},
+		&RetentionArchive{},
+		&SSHAuthAttempt{},
+		&SystemSetting{},
+	)
+}
+
+// BaselineModels 返回基线版本迁移创建的模型，之后新增的表由各自的迁移脚本创建
+func BaselineModels() []interface{} {
+	return []interface{}{
+		&HoneypotTemplate{},
+		&HoneypotInstance{},
+		&HoneypotLog{},
+		&Bait{},
+		&SecurityRule{},
+		&RuleLog{},
+		&DockerImage{},
+		&DockerImageLog{},
+		&ContainerLogSegment{},
+		&DockerContainer{},
+		&HeadlingAuthLog{},
+		&CowrieLog{},
+		&ContainerLogCursor{},
+		&CowrieTTYLog{},
+		&DionaeaLog{},
+		&QeeqboxLog{},
+		&SyslogMessage{},
+		&AttackTimelineEvent{},
+		&AttackEvent{},
+		&AttackSession{},
+		&MalwareSample{},
+		&MalwareSighting{},
+		&HoneyToken{},
+		&HoneyTokenTrigger{},
+	}
+}
+
 // HoneypotTemplate 蜜罐模板模型
 type HoneypotTemplate struct {
 	ID          uint      `json:"id" gorm:"primaryKey"`
 	Name        string    `json:"name" gorm:"size:50;not null;comment:蜜罐名称"`
 	Protocol    string    `json:"protocol" gorm:"size:20;not null;comment:协议类型"`
+	LogParser   string    `json:"log_parser" gorm:"size:50;comment:日志解析器"`
 	ImportTime  time.Time `json:"import_time" gorm:"not null;comment:导入时间"`
 	DeployCount int       `json:"deploy_count" gorm:"default:0;comment:已部署数量"`
 }
@@ -28,8 +69,9 @@ type HoneypotInstance struct {
 	Status        string    `json:"status" gorm:"size:20;not null;default:created;comment:部署状态"`
 	ImageName     string    `json:"image_name" gorm:"size:200;comment:Docker镜像名称"`
 	ImageID       string    `json:"image_id" gorm:"size:100;comment:Docker镜像ID"`
-	PortMappings  string    `json:"port_mappings" gorm:"type:json;comment:端口映射配置"`
-	Environment   string    `json:"environment" gorm:"type:json;comment:环境变量配置"`
+	LogParser     string    `json:"log_parser" gorm:"size:50;comment:日志解析器"`
+	PortMappings  string    `json:"port_mappings" gorm:"type:text;comment:端口映射配置"`
+	Environment   string    `json:"environment" gorm:"type:text;comment:环境变量配置"`
 	CreateTime    time.Time `json:"create_time" gorm:"not null;comment:创建时间"`
 	UpdateTime    time.Time `json:"update_time" gorm:"comment:更新时间"`
 	Description   string    `json:"description" gorm:"type:text;comment:描述"`
@@ -146,8 +188,8 @@ type DockerContainer struct {
 	ImageID       string    `json:"image_id" gorm:"size:100;comment:关联的镜像ID"`
 	ImageName     string    `json:"image_name" gorm:"size:200;comment:镜像名称"`
 	Status        string    `json:"status" gorm:"size:20;comment:容器状态(running/stopped/exited等)"`
-	Ports         string    `json:"ports" gorm:"type:json;comment:端口映射信息"`
-	Environment   string    `json:"environment" gorm:"type:json;comment:环境变量"`
+	Ports         string    `json:"ports" gorm:"type:text;comment:端口映射信息"`
+	Environment   string    `json:"environment" gorm:"type:text;comment:环境变量"`
 	CreatedAt     time.Time `json:"created_at" gorm:"not null;comment:创建时间"`
 	UpdatedAt     time.Time `json:"updated_at" gorm:"not null;comment:更新时间"`
 }
@@ -172,7 +214,7 @@ func (DockerContainer) TableName() string {
 // HeadlingAuthLog Headling认证日志模型
 type HeadlingAuthLog struct {
 	ID              uint      `json:"id" gorm:"primaryKey"`
-	Timestamp       time.Time `json:"timestamp" gorm:"type:datetime(6);not null;comment:捕获到认证行为的时间戳"`
+	Timestamp       time.Time `json:"timestamp" gorm:"precision:6;not null;comment:捕获到认证行为的时间戳"`
 	AuthID          string    `json:"auth_id" gorm:"size:36;not null;uniqueIndex;comment:此次认证行为的唯一ID"`
 	SessionID       string    `json:"session_id" gorm:"size:36;not null;index;comment:所属会话ID"`
 	SourceIP        string    `json:"source_ip" gorm:"size:45;not null;index;comment:攻击者IP"`
@@ -216,17 +258,60 @@ func (HeadlingAuthLog) TableName() string {
 	return "headling_auth_log"
 }
 
+// StatisticsFilter 认证日志和Cowrie日志统计的查询条件，空值表示不过滤
+type StatisticsFilter struct {
+	ContainerID string
+	StartTime   *time.Time
+	EndTime     *time.Time
+	Limit       int // 攻击者和命令统计返回的最大条数，0表示不限制
+}
+
+// Cowrie事件类型(eventid)
+const (
+	CowrieEventSessionConnect  = "cowrie.session.connect"
+	CowrieEventSessionClosed   = "cowrie.session.closed"
+	CowrieEventLoginSuccess    = "cowrie.login.success"
+	CowrieEventLoginFailed     = "cowrie.login.failed"
+	CowrieEventCommandInput    = "cowrie.command.input"
+	CowrieEventCommandFailed   = "cowrie.command.failed"
+	CowrieEventClientVersion   = "cowrie.client.version"
+	CowrieEventClientKex       = "cowrie.client.kex"
+	CowrieEventFileDownload    = "cowrie.session.file_download"
+	CowrieEventFileUpload      = "cowrie.session.file_upload"
+	CowrieEventDirectTCPIP     = "cowrie.direct-tcpip.request"
+	CowrieEventDirectTCPIPData = "cowrie.direct-tcpip.data"
+	CowrieEventLogClosed       = "cowrie.log.closed"
+)
+
+// CowrieEventTypes 支持的Cowrie事件类型
+var CowrieEventTypes = []string{
+	CowrieEventSessionConnect,
+	CowrieEventSessionClosed,
+	CowrieEventLoginSuccess,
+	CowrieEventLoginFailed,
+	CowrieEventCommandInput,
+	CowrieEventCommandFailed,
+	CowrieEventClientVersion,
+	CowrieEventClientKex,
+	CowrieEventFileDownload,
+	CowrieEventFileUpload,
+	CowrieEventDirectTCPIP,
+	CowrieEventDirectTCPIPData,
+	CowrieEventLogClosed,
+}
+
 // CowrieLog Cowrie蜜罐日志模型
 type CowrieLog struct {
 	ID              uint      `json:"id" gorm:"primaryKey"`
-	EventTime       time.Time `json:"event_time" gorm:"type:datetime(6);not null;comment:事件发生的精确时间戳"`
+	EventID         string    `json:"event_id" gorm:"size:64;index;comment:Cowrie事件类型(eventid)"`
+	EventTime       time.Time `json:"event_time" gorm:"precision:6;not null;comment:事件发生的精确时间戳"`
 	AuthID          string    `json:"auth_id" gorm:"size:36;not null;uniqueIndex;comment:认证行为的唯一ID"`
 	SessionID       string    `json:"session_id" gorm:"size:36;not null;index;comment:会话ID"`
 	SourceIP        string    `json:"source_ip" gorm:"size:15;not null;index;comment:攻击者IP"`
-	SourcePort      uint16    `json:"source_port" gorm:"not null;comment:攻击者使用的端口"`
+	SourcePort      uint16    `json:"source_port" gorm:"size:32;not null;comment:攻击者使用的端口"`
 	DestinationIP   string    `json:"destination_ip" gorm:"size:15;not null;index;comment:蜜罐容器IP"`
-	DestinationPort uint16    `json:"destination_port" gorm:"not null;comment:目标端口"`
-	Protocol        string    `json:"protocol" gorm:"type:enum('http','ssh','telnet','ftp','smb','other');not null;index;comment:使用的协议类型"`
+	DestinationPort uint16    `json:"destination_port" gorm:"size:32;not null;comment:目标端口"`
+	Protocol        string    `json:"protocol" gorm:"size:10;not null;index;comment:使用的协议类型(http/ssh/telnet/ftp/smb/other)"`
 	ClientInfo      string    `json:"client_info" gorm:"size:255;comment:客户端信息"`
 	Fingerprint     string    `json:"fingerprint" gorm:"size:64;comment:客户端指纹"`
 	Username        string    `json:"username" gorm:"size:255;index;comment:攻击者输入的用户名"`
@@ -234,6 +319,18 @@ type CowrieLog struct {
 	PasswordHash    string    `json:"password_hash" gorm:"size:255;comment:密码哈希值"`
 	Command         string    `json:"command" gorm:"type:text;comment:攻击者执行的命令内容"`
 	CommandFound    *bool     `json:"command_found" gorm:"index;comment:命令是否被系统识别"`
+	Message         string    `json:"message" gorm:"type:text;comment:Cowrie事件描述"`
+	Duration        float64   `json:"duration" gorm:"comment:会话持续时间(秒),session.closed事件"`
+	Hassh           string    `json:"hassh" gorm:"size:64;comment:客户端HASSH指纹,client.kex事件"`
+	KexAlgorithms   string    `json:"kex_algorithms" gorm:"type:text;comment:客户端密钥交换算法列表,client.kex事件"`
+	URL             string    `json:"url" gorm:"size:1024;comment:下载地址,file_download事件"`
+	Shasum          string    `json:"shasum" gorm:"size:64;index;comment:文件SHA256,文件传输事件"`
+	Outfile         string    `json:"outfile" gorm:"size:255;comment:文件保存路径,文件传输事件"`
+	Filename        string    `json:"filename" gorm:"size:255;comment:上传文件名,file_upload事件"`
+	TunnelDestIP    string    `json:"tunnel_dest_ip" gorm:"size:255;comment:端口转发目标地址,direct-tcpip事件"`
+	TunnelDestPort  uint16    `json:"tunnel_dest_port" gorm:"size:32;comment:端口转发目标端口,direct-tcpip事件"`
+	TunnelData      string    `json:"tunnel_data" gorm:"type:text;comment:端口转发数据,direct-tcpip.data事件"`
+	TTYLog          string    `json:"ttylog" gorm:"size:255;comment:TTY日志路径,log.closed事件"`
 	RawLog          string    `json:"raw_log" gorm:"type:text;not null;comment:原始日志内容"`
 	ContainerID     string    `json:"container_id" gorm:"size:64;index;comment:关联的容器ID"`
 	ContainerName   string    `json:"container_name" gorm:"size:100;comment:容器名称"`
@@ -281,6 +378,487 @@ type CowrieCommandStatistics struct {
 	LastUsed       time.Time `json:"last_used"`
 }
 
+// CowrieEventStatistics Cowrie事件类型统计模型
+type CowrieEventStatistics struct {
+	EventID        string    `json:"event_id"`
+	TotalEvents    int       `json:"total_events"`
+	UniqueIPs      int       `json:"unique_ips"`
+	UniqueSessions int       `json:"unique_sessions"`
+	FirstEvent     time.Time `json:"first_event"`
+	LastEvent      time.Time `json:"last_event"`
+}
+
 func (CowrieLog) TableName() string {
 	return "cowrie_log"
 }
+
+// ContainerLogCursor 容器日志文件读取游标
+type ContainerLogCursor struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	ContainerID  string    `json:"container_id" gorm:"size:64;not null;uniqueIndex:idx_container_log_cursor;comment:容器ID"`
+	LogSource    string    `json:"log_source" gorm:"size:20;not null;uniqueIndex:idx_container_log_cursor;comment:日志来源(cowrie/headling等)"`
+	FilePath     string    `json:"file_path" gorm:"size:255;not null;comment:容器内日志文件路径"`
+	Offset       int64     `json:"offset" gorm:"not null;default:0;comment:已读取的字节偏移"`
+	HeadHash     string    `json:"head_hash" gorm:"size:64;comment:文件头部哈希,用于识别日志轮转"`
+	FileSize     int64     `json:"file_size" gorm:"comment:上次读取时的文件大小"`
+	ModTime      time.Time `json:"mod_time" gorm:"comment:上次读取时的文件修改时间"`
+	LineCount    int64     `json:"line_count" gorm:"default:0;comment:已读取的行数"`
+	LastRecordID string    `json:"last_record_id" gorm:"size:64;comment:最后一条记录的ID"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"not null;comment:更新时间"`
+}
+
+func (ContainerLogCursor) TableName() string {
+	return "container_log_cursor"
+}
+
+// MalwareSample 攻击者下载或上传到蜜罐中的恶意样本，按SHA256去重
+type MalwareSample struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	SHA256         string    `json:"sha256" gorm:"size:64;not null;uniqueIndex;comment:样本SHA256"`
+	SHA1           string    `json:"sha1" gorm:"size:40;not null;index;comment:样本SHA1"`
+	MD5            string    `json:"md5" gorm:"size:32;not null;index;comment:样本MD5"`
+	SSDeep         string    `json:"ssdeep" gorm:"size:255;comment:样本ssdeep模糊哈希"`
+	FileSize       int64     `json:"file_size" gorm:"not null;comment:文件大小(字节)"`
+	FileType       string    `json:"file_type" gorm:"size:100;comment:文件类型"`
+	QuarantinePath string    `json:"quarantine_path" gorm:"size:255;not null;comment:隔离区中的存储路径"`
+	SightingCount  int       `json:"sighting_count" gorm:"not null;default:0;comment:被捕获的次数"`
+	FirstSeen      time.Time `json:"first_seen" gorm:"not null;comment:首次捕获时间"`
+	LastSeen       time.Time `json:"last_seen" gorm:"not null;comment:最近捕获时间"`
+	CreatedAt      time.Time `json:"created_at" gorm:"not null;comment:记录创建时间"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"not null;comment:记录更新时间"`
+}
+
+func (MalwareSample) TableName() string {
+	return "malware_sample"
+}
+
+// MalwareSighting 样本的一次捕获记录，关联到产生它的Cowrie会话
+type MalwareSighting struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	SampleID    uint      `json:"sample_id" gorm:"not null;index;comment:关联的样本ID"`
+	SHA256      string    `json:"sha256" gorm:"size:64;not null;index;comment:样本SHA256"`
+	CowrieLogID uint      `json:"cowrie_log_id" gorm:"not null;uniqueIndex;comment:关联的Cowrie文件传输事件ID"`
+	SessionID   string    `json:"session_id" gorm:"size:36;index;comment:会话ID"`
+	SourceIP    string    `json:"source_ip" gorm:"size:45;index;comment:攻击者IP"`
+	EventID     string    `json:"event_id" gorm:"size:64;comment:文件传输事件类型"`
+	URL         string    `json:"url" gorm:"size:1024;comment:下载地址"`
+	Filename    string    `json:"filename" gorm:"size:255;comment:上传文件名"`
+	ContainerID string    `json:"container_id" gorm:"size:64;index;comment:容器ID"`
+	EventTime   time.Time `json:"event_time" gorm:"precision:6;not null;comment:事件发生时间"`
+	CreatedAt   time.Time `json:"created_at" gorm:"not null;comment:记录创建时间"`
+}
+
+func (MalwareSighting) TableName() string {
+	return "malware_sighting"
+}
+
+// CowrieTTYLog 从Cowrie容器中收集的会话TTY日志
+type CowrieTTYLog struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	SessionID   string    `json:"session_id" gorm:"size:36;not null;uniqueIndex;comment:会话ID"`
+	CowrieLogID uint      `json:"cowrie_log_id" gorm:"not null;comment:关联的log.closed事件ID"`
+	ContainerID string    `json:"container_id" gorm:"size:64;index;comment:容器ID"`
+	TTYLogPath  string    `json:"ttylog_path" gorm:"size:255;not null;comment:容器内TTY日志路径"`
+	LocalPath   string    `json:"local_path" gorm:"size:255;not null;comment:本地保存路径"`
+	Shasum      string    `json:"shasum" gorm:"size:64;comment:TTY日志SHA256"`
+	Size        int64     `json:"size" gorm:"comment:TTY日志大小(字节)"`
+	Duration    float64   `json:"duration" gorm:"comment:会话持续时间(秒)"`
+	CreatedAt   time.Time `json:"created_at" gorm:"not null;comment:记录创建时间"`
+}
+
+func (CowrieTTYLog) TableName() string {
+	return "cowrie_ttylog"
+}
+
+// Dionaea事件类型，一条Dionaea连接记录会拆分为连接、登录和下载事件
+const (
+	DionaeaEventConnection = "connection"
+	DionaeaEventLogin      = "login"
+	DionaeaEventDownload   = "download"
+)
+
+// DionaeaLog Dionaea蜜罐日志模型
+type DionaeaLog struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	EventType       string    `json:"event_type" gorm:"size:20;not null;index;comment:事件类型(connection/login/download)"`
+	EventTime       time.Time `json:"event_time" gorm:"precision:6;not null;index;comment:事件发生时间"`
+	AuthID          string    `json:"auth_id" gorm:"size:36;not null;uniqueIndex;comment:事件的唯一ID"`
+	SessionID       string    `json:"session_id" gorm:"size:36;not null;index;comment:连接ID，同一连接的事件相同"`
+	SourceIP        string    `json:"source_ip" gorm:"size:45;not null;index;comment:攻击者IP"`
+	SourcePort      uint16    `json:"source_port" gorm:"size:32;comment:攻击者端口"`
+	DestinationIP   string    `json:"destination_ip" gorm:"size:45;comment:蜜罐IP"`
+	DestinationPort uint16    `json:"destination_port" gorm:"size:32;index;comment:蜜罐端口"`
+	Protocol        string    `json:"protocol" gorm:"size:32;index;comment:Dionaea服务模块(httpd/ftpd/smbd等)"`
+	Transport       string    `json:"transport" gorm:"size:10;comment:传输层协议(tcp/udp/tls)"`
+	ConnectionType  string    `json:"connection_type" gorm:"size:20;comment:连接类型(accept/connect/listen)"`
+	Username        string    `json:"username" gorm:"size:255;index;comment:登录用户名"`
+	Password        string    `json:"password" gorm:"size:255;comment:登录密码"`
+	URL             string    `json:"url" gorm:"size:1024;comment:下载地址"`
+	MD5Hash         string    `json:"md5_hash" gorm:"size:32;index;comment:下载文件MD5"`
+	FTPCommands     string    `json:"ftp_commands" gorm:"type:text;comment:FTP命令,以换行分隔"`
+	RawLog          string    `json:"raw_log" gorm:"type:text;not null;comment:原始日志内容"`
+	ContainerID     string    `json:"container_id" gorm:"size:64;index;comment:关联的容器ID"`
+	ContainerName   string    `json:"container_name" gorm:"size:100;comment:容器名称"`
+	CreatedAt       time.Time `json:"created_at" gorm:"not null;comment:记录创建时间"`
+}
+
+func (DionaeaLog) TableName() string {
+	return "dionaea_log"
+}
+
+// DionaeaStatistics Dionaea日志统计模型
+type DionaeaStatistics struct {
+	LogDate       string    `json:"log_date"`
+	Protocol      string    `json:"protocol"`
+	Connections   int       `json:"connections"`
+	UniqueIPs     int       `json:"unique_ips"`
+	LoginAttempts int       `json:"login_attempts"`
+	Downloads     int       `json:"downloads"`
+	FirstEvent    time.Time `json:"first_event"`
+	LastEvent     time.Time `json:"last_event"`
+}
+
+// DionaeaAttackerStatistics Dionaea攻击者统计模型
+type DionaeaAttackerStatistics struct {
+	SourceIP      string    `json:"source_ip"`
+	Connections   int       `json:"connections"`
+	ProtocolsUsed int       `json:"protocols_used"`
+	LoginAttempts int       `json:"login_attempts"`
+	Downloads     int       `json:"downloads"`
+	FirstSeen     time.Time `json:"first_seen"`
+	LastSeen      time.Time `json:"last_seen"`
+}
+
+// QeeqboxLog qeeqbox/honeypots蜜罐日志模型，覆盖其模拟的各类协议
+type QeeqboxLog struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	AuthID          string    `json:"auth_id" gorm:"size:36;not null;uniqueIndex;comment:事件的唯一ID"`
+	EventTime       time.Time `json:"event_time" gorm:"precision:6;not null;index;comment:事件发生时间"`
+	Server          string    `json:"server" gorm:"size:32;not null;comment:qeeqbox服务名(mysql_server等)"`
+	Protocol        string    `json:"protocol" gorm:"size:20;not null;index;comment:协议(mysql/redis/postgres/smtp等)"`
+	Action          string    `json:"action" gorm:"size:32;not null;index;comment:动作(connection/login/query/command等)"`
+	Status          string    `json:"status" gorm:"size:20;comment:动作结果(success/failed)"`
+	SourceIP        string    `json:"source_ip" gorm:"size:45;not null;index;comment:攻击者IP"`
+	SourcePort      uint16    `json:"source_port" gorm:"size:32;comment:攻击者端口"`
+	DestinationIP   string    `json:"destination_ip" gorm:"size:45;comment:蜜罐IP"`
+	DestinationPort uint16    `json:"destination_port" gorm:"size:32;comment:蜜罐端口"`
+	Username        string    `json:"username" gorm:"size:255;index;comment:登录用户名"`
+	Password        string    `json:"password" gorm:"size:255;comment:登录密码"`
+	Detail          string    `json:"detail" gorm:"type:text;comment:攻击者执行的命令或查询"`
+	Data            string    `json:"data" gorm:"type:text;comment:事件附加数据(JSON)"`
+	RawLog          string    `json:"raw_log" gorm:"type:text;not null;comment:原始日志内容"`
+	ContainerID     string    `json:"container_id" gorm:"size:64;index;comment:关联的容器ID"`
+	ContainerName   string    `json:"container_name" gorm:"size:100;comment:容器名称"`
+	CreatedAt       time.Time `json:"created_at" gorm:"not null;comment:记录创建时间"`
+}
+
+func (QeeqboxLog) TableName() string {
+	return "qeeqbox_log"
+}
+
+// QeeqboxLogFilter qeeqbox日志查询条件，空值表示不过滤
+type QeeqboxLogFilter struct {
+	ContainerID string
+	Protocol    string
+	Action      string
+	Status      string
+	SourceIP    string
+	Username    string
+	StartTime   *time.Time
+	EndTime     *time.Time
+	Limit       int
+}
+
+// QeeqboxStatistics qeeqbox日志统计模型
+type QeeqboxStatistics struct {
+	Protocol      string    `json:"protocol"`
+	TotalEvents   int       `json:"total_events"`
+	UniqueIPs     int       `json:"unique_ips"`
+	LoginAttempts int       `json:"login_attempts"`
+	LoginSuccess  int       `json:"login_success"`
+	FirstEvent    time.Time `json:"first_event"`
+	LastEvent     time.Time `json:"last_event"`
+}
+
+// QeeqboxAttackerStatistics qeeqbox攻击者统计模型
+type QeeqboxAttackerStatistics struct {
+	SourceIP      string    `json:"source_ip"`
+	TotalEvents   int       `json:"total_events"`
+	ProtocolsUsed int       `json:"protocols_used"`
+	LoginAttempts int       `json:"login_attempts"`
+	FirstSeen     time.Time `json:"first_seen"`
+	LastSeen      time.Time `json:"last_seen"`
+}
+
+// SyslogMessage 通过内置syslog接收器收到的消息，按发送方的传感器标识归类
+type SyslogMessage struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	SensorID       string    `json:"sensor_id" gorm:"size:64;not null;index;comment:发送方传感器标识"`
+	SourceIP       string    `json:"source_ip" gorm:"size:45;not null;index;comment:发送方IP"`
+	Transport      string    `json:"transport" gorm:"size:10;not null;comment:传输方式(udp/tcp/tls)"`
+	Format         string    `json:"format" gorm:"size:10;not null;comment:消息格式(rfc3164/rfc5424)"`
+	Facility       int       `json:"facility" gorm:"comment:syslog facility"`
+	Severity       int       `json:"severity" gorm:"comment:syslog severity"`
+	Hostname       string    `json:"hostname" gorm:"size:255;comment:消息头中的主机名"`
+	AppName        string    `json:"app_name" gorm:"size:48;index;comment:应用名(TAG/APP-NAME)"`
+	ProcID         string    `json:"proc_id" gorm:"size:128;comment:进程ID"`
+	MsgID          string    `json:"msg_id" gorm:"size:32;comment:消息类型(RFC 5424 MSGID)"`
+	StructuredData string    `json:"structured_data" gorm:"type:text;comment:结构化数据(RFC 5424)"`
+	Message        string    `json:"message" gorm:"type:text;not null;comment:消息正文"`
+	Parser         string    `json:"parser" gorm:"size:20;index;comment:处理消息正文的解析器"`
+	EventTime      time.Time `json:"event_time" gorm:"precision:6;not null;index;comment:消息时间戳"`
+	ReceivedAt     time.Time `json:"received_at" gorm:"precision:6;not null;comment:接收时间"`
+	CreatedAt      time.Time `json:"created_at" gorm:"not null;comment:记录创建时间"`
+}
+
+func (SyslogMessage) TableName() string {
+	return "syslog_message"
+}
+
+// SyslogMessageFilter syslog消息查询条件，空值表示不过滤
+type SyslogMessageFilter struct {
+	SensorID  string
+	SourceIP  string
+	AppName   string
+	Parser    string
+	StartTime *time.Time
+	EndTime   *time.Time
+	Limit     int
+}
+
+// SyslogSensorStatistics 按传感器统计的syslog消息
+type SyslogSensorStatistics struct {
+	SensorID      string    `json:"sensor_id"`
+	TotalMessages int       `json:"total_messages"`
+	SourceIPs     int       `json:"source_ips"`
+	FirstSeen     time.Time `json:"first_seen"`
+	LastSeen      time.Time `json:"last_seen"`
+}
+
+// AttackTimelineEvent 攻击事件时间线中的一条事件，由各蜜罐日志和攻击捕获记录归一化而来
+type AttackTimelineEvent struct {
+	ID              uint      `json:"id" gorm:"primaryKey;index:idx_timeline_time_id,priority:2"`
+	EventTime       time.Time `json:"event_time" gorm:"precision:6;not null;index:idx_timeline_time_id,priority:1;comment:事件发生时间"`
+	AuthID          string    `json:"auth_id" gorm:"size:36;not null;uniqueIndex;comment:由来源和来源记录生成的唯一ID"`
+	Source          string    `json:"source" gorm:"size:20;not null;index;comment:事件来源(cowrie/headling/dionaea/qeeqbox/attack_capture/honeytoken)"`
+	SourceRef       string    `json:"source_ref" gorm:"size:64;comment:来源记录的标识"`
+	SessionID       string    `json:"session_id" gorm:"size:64;index;comment:会话ID"`
+	SourceIP        string    `json:"source_ip" gorm:"size:45;index;comment:攻击者IP"`
+	SourcePort      uint      `json:"source_port" gorm:"comment:攻击者端口"`
+	DestinationIP   string    `json:"destination_ip" gorm:"size:45;index;comment:目标IP"`
+	DestinationPort uint      `json:"destination_port" gorm:"comment:目标端口"`
+	Protocol        string    `json:"protocol" gorm:"size:32;index;comment:协议"`
+	Action          string    `json:"action" gorm:"size:64;index;comment:攻击动作"`
+	Username        string    `json:"username" gorm:"size:255;index;comment:攻击者使用的用户名"`
+	Password        string    `json:"password" gorm:"size:255;comment:攻击者使用的密码"`
+	Payload         string    `json:"payload" gorm:"type:text;comment:命令、请求或下载地址等攻击载荷"`
+	Severity        string    `json:"severity" gorm:"size:10;not null;index;comment:严重程度(low/medium/high/critical)"`
+	ContainerID     string    `json:"container_id" gorm:"size:64;index;comment:关联的容器ID"`
+	ContainerName   string    `json:"container_name" gorm:"size:100;comment:容器名称"`
+	CreatedAt       time.Time `json:"created_at" gorm:"not null;comment:记录创建时间"`
+}
+
+func (AttackTimelineEvent) TableName() string {
+	return "attack_timeline_event"
+}
+
+// TimelineCursor 时间线游标，指向上一页最后一条事件，按(event_time, id)倒序翻页
+type TimelineCursor struct {
+	EventTime time.Time
+	ID        uint
+}
+
+// AttackTimelineFilter 攻击事件时间线查询条件，空值表示不过滤
+type AttackTimelineFilter struct {
+	Sources       []string
+	SourceIP      string
+	DestinationIP string
+	Protocol      string
+	Action        string
+	Severity      string
+	Username      string
+	SessionID     string
+	ContainerID   string
+	StartTime     *time.Time
+	EndTime       *time.Time
+	After         *TimelineCursor
+	Limit         int
+}
+
+// AttackEvent 通过攻击捕获接口上报的攻击事件
+type AttackEvent struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	SourceIP        string    `json:"source_ip" gorm:"size:45;not null;index;comment:攻击者IP"`
+	SourcePort      int       `json:"source_port" gorm:"comment:攻击者端口"`
+	DestIP          string    `json:"dest_ip" gorm:"size:45;not null;comment:目标IP"`
+	DestPort        int       `json:"dest_port" gorm:"comment:目标端口"`
+	Protocol        string    `json:"protocol" gorm:"size:32;not null;index;comment:协议"`
+	AttackType      string    `json:"attack_type" gorm:"size:100;not null;index;comment:攻击类型"`
+	Payload         string    `json:"payload" gorm:"type:text;comment:攻击载荷"`
+	Timestamp       time.Time `json:"timestamp" gorm:"precision:6;not null;index;comment:事件发生时间"`
+	Severity        string    `json:"severity" gorm:"size:10;not null;index;comment:严重程度(low/medium/high/critical)"`
+	ContainerID     string    `json:"container_id" gorm:"size:64;index;comment:关联的容器ID"`
+	ContainerName   string    `json:"container_name" gorm:"size:100;comment:容器名称"`
+	UserAgent       string    `json:"user_agent" gorm:"size:512;comment:客户端User-Agent"`
+	SessionID       string    `json:"session_id" gorm:"size:64;index;comment:会话标识，为空时按攻击者IP归并"`
+	AttackSessionID uint      `json:"attack_session_id" gorm:"index;comment:所属攻击会话ID"`
+	CreatedAt       time.Time `json:"created_at" gorm:"not null;comment:记录创建时间"`
+}
+
+func (AttackEvent) TableName() string {
+	return "attack_event"
+}
+
+// AttackSession 攻击会话，同一会话标识的事件间隔超过空闲超时后开启新的会话
+type AttackSession struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	SessionID     string     `json:"session_id" gorm:"size:64;not null;index;comment:会话标识(上报的会话ID或攻击者IP)"`
+	SourceIP      string     `json:"source_ip" gorm:"size:45;not null;index;comment:攻击者IP"`
+	StartTime     time.Time  `json:"start_time" gorm:"precision:6;not null;index;comment:会话开始时间"`
+	LastEventTime time.Time  `json:"last_event_time" gorm:"precision:6;not null;comment:最后一个事件的时间"`
+	EndTime       *time.Time `json:"end_time" gorm:"precision:6;index;comment:会话结束时间，为空表示会话未结束"`
+	EventCount    int        `json:"event_count" gorm:"not null;default:0;comment:事件数量"`
+	AttackTypes   []string   `json:"attack_types" gorm:"type:text;serializer:json;comment:出现过的攻击类型"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"not null;comment:记录创建时间"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"comment:记录更新时间"`
+}
+
+func (AttackSession) TableName() string {
+	return "attack_session"
+}
+
+// AttackEventFilter 攻击事件查询条件，空值表示不过滤
+type AttackEventFilter struct {
+	SourceIP        string
+	Protocol        string
+	AttackType      string
+	Severity        string
+	SessionID       string
+	AttackSessionID uint
+	StartTime       *time.Time
+	EndTime         *time.Time
+	Offset          int
+	Limit           int
+}
+
+// AttackEventCount 攻击事件按某个字段分组的数量
+type AttackEventCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// HoneyToken 蜜签，诱使攻击者使用的虚假凭证、文件路径或链接
+type HoneyToken struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Name         string    `json:"name" gorm:"size:100;not null;index;comment:蜜签名称"`
+	Type         string    `json:"type" gorm:"size:20;not null;index;comment:蜜签类型(credential/file/url/email)"`
+	Content      string    `json:"content" gorm:"type:text;comment:蜜签内容"`
+	Description  string    `json:"description" gorm:"size:255;comment:描述"`
+	IsActive     bool      `json:"is_active" gorm:"not null;default:true;comment:是否启用"`
+	TriggerCount int       `json:"trigger_count" gorm:"not null;default:0;comment:触发次数"`
+	CreateTime   time.Time `json:"create_time" gorm:"not null;comment:创建时间"`
+	UpdateTime   time.Time `json:"update_time" gorm:"not null;comment:更新时间"`
+}
+
+func (HoneyToken) TableName() string {
+	return "honey_token"
+}
+
+// HoneyTokenTrigger 蜜签触发记录
+type HoneyTokenTrigger struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	TokenID     uint      `json:"token_id" gorm:"not null;index;comment:触发的蜜签ID"`
+	TokenName   string    `json:"token_name" gorm:"size:100;comment:触发时的蜜签名称"`
+	SourceIP    string    `json:"source_ip" gorm:"size:45;index;comment:触发者IP"`
+	UserAgent   string    `json:"user_agent" gorm:"size:512;comment:客户端User-Agent"`
+	TriggerTime time.Time `json:"trigger_time" gorm:"not null;index;comment:触发时间"`
+	Action      string    `json:"action" gorm:"size:100;comment:触发动作"`
+	Details     string    `json:"details" gorm:"type:text;comment:详细信息"`
+}
+
+func (HoneyTokenTrigger) TableName() string {
+	return "honey_token_trigger"
+}
+
+// HoneyTokenTriggerFilter 蜜签触发记录查询条件，空值表示不过滤
+type HoneyTokenTriggerFilter struct {
+	TokenID   uint
+	SourceIP  string
+	StartTime *time.Time
+	EndTime   *time.Time
+	Limit     int
+}
+
+// HoneyTokenTypeCount 按类型分组的蜜签数量
+type HoneyTokenTypeCount struct {
+	Type  string `json:"type"`
+	Count int64  `json:"count"`
+}
+
+// RetentionPolicy 日志表的保留策略，超过保留时长或保留行数的记录先归档再删除，0表示不限制
+type RetentionPolicy struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	LogTable   string    `json:"table" gorm:"size:64;not null;uniqueIndex;comment:日志表名"`
+	MaxAgeDays int       `json:"max_age_days" gorm:"not null;default:0;comment:最长保留天数"`
+	MaxRows    int64     `json:"max_rows" gorm:"not null;default:0;comment:最多保留行数"`
+	Enabled    bool      `json:"enabled" gorm:"not null;comment:是否启用"`
+	UpdateTime time.Time `json:"update_time" gorm:"not null;comment:更新时间"`
+}
+
+func (RetentionPolicy) TableName() string {
+	return "retention_policy"
+}
+
+// RetentionArchive 一次归档生成的压缩NDJSON文件，每行为一条被删除的记录
+type RetentionArchive struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	LogTable     string     `json:"table" gorm:"size:64;not null;index;comment:日志表名"`
+	Reason       string     `json:"reason" gorm:"size:20;not null;comment:归档原因(max_age/max_rows)"`
+	FileName     string     `json:"file_name" gorm:"size:255;not null;uniqueIndex;comment:归档文件名"`
+	RowCount     int64      `json:"row_count" gorm:"not null;comment:归档行数"`
+	SizeBytes    int64      `json:"size_bytes" gorm:"not null;comment:文件大小"`
+	FromTime     *time.Time `json:"from_time" gorm:"comment:最早一条记录的时间"`
+	ToTime       *time.Time `json:"to_time" gorm:"comment:最晚一条记录的时间"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"not null;index;comment:归档时间"`
+	RestoredAt   *time.Time `json:"restored_at" gorm:"comment:最近一次恢复时间"`
+	RestoredRows int64      `json:"restored_rows" gorm:"not null;default:0;comment:最近一次恢复写回的行数"`
+}
+
+func (RetentionArchive) TableName() string {
+	return "retention_archive"
+}
+
+// SSHAuthAttempt 内置SSH蜜罐记录的一次认证尝试，auth_id与同时写入headling_auth_log的记录一致
+// 没有任何认证尝试就断开的连接记录一条method为none的记录
+type SSHAuthAttempt struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	AuthID         string    `json:"auth_id" gorm:"size:36;not null;uniqueIndex;comment:认证行为的唯一ID"`
+	SessionID      string    `json:"session_id" gorm:"size:36;not null;index;comment:所属连接的会话ID"`
+	Timestamp      time.Time `json:"timestamp" gorm:"precision:6;not null;index;comment:认证尝试时间"`
+	SourceIP       string    `json:"source_ip" gorm:"size:45;not null;index;comment:攻击者IP"`
+	SourcePort     uint      `json:"source_port" gorm:"not null;comment:攻击者使用的端口"`
+	Method         string    `json:"method" gorm:"size:32;not null;index;comment:认证方式(password/publickey/keyboard-interactive/none)"`
+	Username       string    `json:"username" gorm:"size:255;not null;comment:攻击者输入的用户名"`
+	KeyType        string    `json:"key_type" gorm:"size:64;comment:公钥类型,publickey认证"`
+	KeyFingerprint string    `json:"key_fingerprint" gorm:"size:128;index;comment:公钥SHA256指纹,publickey认证"`
+	Accepted       bool      `json:"accepted" gorm:"not null;comment:是否认证成功"`
+	ClientVersion  string    `json:"client_version" gorm:"size:255;comment:客户端版本字符串"`
+	KexAlgorithms  string    `json:"kex_algorithms" gorm:"type:text;comment:客户端KEXINIT中的算法列表(JSON)"`
+	Hassh          string    `json:"hassh" gorm:"size:64;index;comment:客户端HASSH指纹"`
+	ContainerID    string    `json:"container_id" gorm:"size:64;index;comment:蜜罐标识"`
+}
+
+func (SSHAuthAttempt) TableName() string {
+	return "ssh_auth_attempt"
+}
+
+// SystemSetting 系统设置，按名称保存只需执行一次的初始化等状态
+type SystemSetting struct {
+	Name      string    `json:"name" gorm:"primaryKey;size:64;comment:设置名称"`
+	Value     string    `json:"value" gorm:"size:255;comment:设置值"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null;comment:更新时间"`
+}
+
+func (SystemSetting) TableName() string {
+	return "system_setting"
+}
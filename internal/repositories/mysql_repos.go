@@ -1,11 +1,106 @@
 package repositories
 
 import (
+	"errors"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// 仓库实现在MySQL、SQLite和达梦上共用，每个仓库只有一个构造函数：
+// 达梦的列名大写由DamengNamingStrategy处理，手写SQL中方言不同的部分(保留字列名、日期函数、时间差等)
+// 由dialect.go中的columnOf、dateOf等函数按连接的方言生成
+
+// skipOnAuthIDConflict auth_id冲突时跳过插入，由GORM按方言生成
+// (MySQL: ON DUPLICATE KEY UPDATE id=id, SQLite: ON CONFLICT DO NOTHING)，重复拉取同一批日志不会导致整批失败；
+// 达梦不使用该子句，由createSkippingDuplicates在插入前排除已存在的auth_id
+var skipOnAuthIDConflict = clause.OnConflict{
+	Columns:   []clause.Column{{Name: "auth_id"}},
+	DoNothing: true,
+}
+
+// authIDRecord 按auth_id回查ID时使用的记录
+type authIDRecord struct {
+	ID     uint
+	AuthID string
+}
+
+// findByAuthIDs 查询已存在的auth_id及其ID
+func findByAuthIDs[T any](db *gorm.DB, authIDs []string) (map[string]uint, error) {
+	var existing []authIDRecord
+	if err := db.Model(new(T)).Select("id", "auth_id").Where("auth_id IN ?", authIDs).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	ids := make(map[string]uint, len(existing))
+	for _, e := range existing {
+		ids[e.AuthID] = e.ID
+	}
+	return ids, nil
+}
+
+// createSkippingDuplicates 批量插入日志，auth_id已存在的记录被跳过，返回实际插入的条数
+// 有记录被跳过时自增ID无法按顺序回填，插入后按auth_id重新读取ID
+func createSkippingDuplicates[T any](db *gorm.DB, rows []T, authID func(*T) string, setID func(*T, uint)) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	authIDs := make([]string, len(rows))
+	for i := range rows {
+		setID(&rows[i], 0)
+		authIDs[i] = authID(&rows[i])
+	}
+
+	var inserted int64
+	if dialectOf(db) == DialectDameng {
+		// 达梦方言只在主键冲突时把ON CONFLICT转换为MERGE，这里先排除已存在和批内重复的auth_id
+		existing, err := findByAuthIDs[T](db, authIDs)
+		if err != nil {
+			return 0, err
+		}
+		fresh := make([]T, 0, len(rows))
+		for i := range rows {
+			if _, ok := existing[authIDs[i]]; !ok {
+				existing[authIDs[i]] = 0
+				fresh = append(fresh, rows[i])
+			}
+		}
+		if len(fresh) > 0 {
+			result := db.CreateInBatches(fresh, 100)
+			if result.Error != nil {
+				return 0, result.Error
+			}
+			inserted = result.RowsAffected
+		}
+	} else {
+		result := db.Clauses(skipOnAuthIDConflict).CreateInBatches(rows, 100)
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		if result.RowsAffected == int64(len(rows)) {
+			return result.RowsAffected, nil
+		}
+		inserted = result.RowsAffected
+	}
+
+	ids, err := findByAuthIDs[T](db, authIDs)
+	if err != nil {
+		return inserted, err
+	}
+	for i := range rows {
+		setID(&rows[i], ids[authID(&rows[i])])
+	}
+	return inserted, nil
+}
+
+// listAfterID 按ID升序读取afterID之后的最多limit条记录
+func listAfterID[T any](db *gorm.DB, afterID uint, limit int) ([]T, error) {
+	var rows []T
+	result := db.Where("id > ?", afterID).Order("id").Limit(limit).Find(&rows)
+	return rows, result.Error
+}
+
 // -------------------- 蜜罐模板仓库 --------------------
 
 // MySQLHoneypotTemplateRepo 蜜罐模板MySQL仓库
@@ -13,8 +108,8 @@ type MySQLHoneypotTemplateRepo struct {
 	DB *gorm.DB
 }
 
-// NewMySQLHoneypotTemplateRepo 创建蜜罐模板MySQL仓库
-func NewMySQLHoneypotTemplateRepo(db *gorm.DB) HoneypotTemplateRepository {
+// NewHoneypotTemplateRepo 创建蜜罐模板仓库
+func NewHoneypotTemplateRepo(db *gorm.DB) HoneypotTemplateRepository {
 	return &MySQLHoneypotTemplateRepo{DB: db}
 }
 
@@ -25,6 +120,11 @@ func (r *MySQLHoneypotTemplateRepo) List() ([]HoneypotTemplate, error) {
 	return templates, result.Error
 }
 
+// Query 按查询条件分页获取蜜罐模板
+func (r *MySQLHoneypotTemplateRepo) Query(spec QuerySpec) (*Page[HoneypotTemplate], error) {
+	return findPage[HoneypotTemplate](r.DB, spec)
+}
+
 // GetByID 根据ID获取蜜罐模板
 func (r *MySQLHoneypotTemplateRepo) GetByID(id uint) (*HoneypotTemplate, error) {
 	var template HoneypotTemplate
@@ -54,13 +154,13 @@ func (r *MySQLHoneypotTemplateRepo) Delete(id uint) error {
 // IncrementDeployCount 增加部署数量
 func (r *MySQLHoneypotTemplateRepo) IncrementDeployCount(id uint) error {
 	return r.DB.Model(&HoneypotTemplate{}).Where("id = ?", id).
-		UpdateColumn("deploy_count", gorm.Expr("deploy_count + ?", 1)).Error
+		UpdateColumn("DeployCount", gorm.Expr("deploy_count + ?", 1)).Error
 }
 
 // DecrementDeployCount 减少部署数量
 func (r *MySQLHoneypotTemplateRepo) DecrementDeployCount(id uint) error {
 	return r.DB.Model(&HoneypotTemplate{}).Where("id = ? AND deploy_count > 0", id).
-		UpdateColumn("deploy_count", gorm.Expr("deploy_count - ?", 1)).Error
+		UpdateColumn("DeployCount", gorm.Expr("deploy_count - ?", 1)).Error
 }
 
 // -------------------- 蜜罐实例仓库 --------------------
@@ -70,22 +170,27 @@ type MySQLHoneypotInstanceRepo struct {
 	DB *gorm.DB
 }
 
-// NewMySQLHoneypotInstanceRepo 创建蜜罐实例MySQL仓库
-func NewMySQLHoneypotInstanceRepo(db *gorm.DB) HoneypotInstanceRepository {
+// NewHoneypotInstanceRepo 创建蜜罐实例仓库
+func NewHoneypotInstanceRepo(db *gorm.DB) HoneypotInstanceRepository {
 	return &MySQLHoneypotInstanceRepo{DB: db}
 }
 
 // List 获取所有蜜罐实例
 func (r *MySQLHoneypotInstanceRepo) List() ([]HoneypotInstance, error) {
 	var instances []HoneypotInstance
-	result := r.DB.Preload("Template").Find(&instances)
+	result := r.DB.Find(&instances)
 	return instances, result.Error
 }
 
+// Query 按查询条件分页获取蜜罐实例
+func (r *MySQLHoneypotInstanceRepo) Query(spec QuerySpec) (*Page[HoneypotInstance], error) {
+	return findPage[HoneypotInstance](r.DB, spec)
+}
+
 // GetByID 根据ID获取蜜罐实例
 func (r *MySQLHoneypotInstanceRepo) GetByID(id uint) (*HoneypotInstance, error) {
 	var instance HoneypotInstance
-	result := r.DB.Preload("Template").First(&instance, id)
+	result := r.DB.First(&instance, id)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -102,7 +207,9 @@ func (r *MySQLHoneypotInstanceRepo) GetByTemplateID(templateID uint) ([]Honeypot
 // Create 创建蜜罐实例
 func (r *MySQLHoneypotInstanceRepo) Create(instance *HoneypotInstance) error {
 	instance.CreateTime = time.Now()
-	instance.Status = "已部署"
+	if instance.Status == "" {
+		instance.Status = "已部署"
+	}
 	return r.DB.Create(instance).Error
 }
 
@@ -119,20 +226,20 @@ func (r *MySQLHoneypotInstanceRepo) Delete(id uint) error {
 // UpdateStatus 更新蜜罐实例状态
 func (r *MySQLHoneypotInstanceRepo) UpdateStatus(id uint, status string) error {
 	return r.DB.Model(&HoneypotInstance{}).Where("id = ?", id).
-		Update("status", status).Error
+		Update("Status", status).Error
 }
 
 // GetByStatus 根据状态获取蜜罐实例
 func (r *MySQLHoneypotInstanceRepo) GetByStatus(status string) ([]HoneypotInstance, error) {
 	var instances []HoneypotInstance
-	result := r.DB.Where("status = ?", status).Preload("Template").Find(&instances)
+	result := r.DB.Where("status = ?", status).Find(&instances)
 	return instances, result.Error
 }
 
 // GetByContainerID 根据容器ID获取蜜罐实例
 func (r *MySQLHoneypotInstanceRepo) GetByContainerID(containerID string) (*HoneypotInstance, error) {
 	var instance HoneypotInstance
-	result := r.DB.Where("container_id = ?", containerID).Preload("Template").First(&instance)
+	result := r.DB.Where("container_id = ?", containerID).First(&instance)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -146,8 +253,8 @@ type MySQLHoneypotLogRepo struct {
 	DB *gorm.DB
 }
 
-// NewMySQLHoneypotLogRepo 创建蜜罐日志MySQL仓库
-func NewMySQLHoneypotLogRepo(db *gorm.DB) HoneypotLogRepository {
+// NewHoneypotLogRepo 创建蜜罐日志仓库
+func NewHoneypotLogRepo(db *gorm.DB) HoneypotLogRepository {
 	return &MySQLHoneypotLogRepo{DB: db}
 }
 
@@ -163,6 +270,11 @@ func (r *MySQLHoneypotLogRepo) List() ([]HoneypotLog, error) {
 	return r.GetAll()
 }
 
+// Query 按查询条件分页获取蜜罐日志
+func (r *MySQLHoneypotLogRepo) Query(spec QuerySpec) (*Page[HoneypotLog], error) {
+	return findPage[HoneypotLog](r.DB, spec)
+}
+
 // GetByID 根据ID获取蜜罐日志
 func (r *MySQLHoneypotLogRepo) GetByID(id uint) (*HoneypotLog, error) {
 	var log HoneypotLog
@@ -197,8 +309,8 @@ type MySQLBaitRepo struct {
 	DB *gorm.DB
 }
 
-// NewMySQLBaitRepo 创建诱饵MySQL仓库
-func NewMySQLBaitRepo(db *gorm.DB) BaitRepository {
+// NewBaitRepo 创建诱饵仓库
+func NewBaitRepo(db *gorm.DB) BaitRepository {
 	return &MySQLBaitRepo{DB: db}
 }
 
@@ -209,6 +321,11 @@ func (r *MySQLBaitRepo) List() ([]Bait, error) {
 	return baits, result.Error
 }
 
+// Query 按查询条件分页获取诱饵
+func (r *MySQLBaitRepo) Query(spec QuerySpec) (*Page[Bait], error) {
+	return findPage[Bait](r.DB, spec)
+}
+
 // GetByID 根据ID获取诱饵
 func (r *MySQLBaitRepo) GetByID(id uint) (*Bait, error) {
 	var bait Bait
@@ -238,7 +355,7 @@ func (r *MySQLBaitRepo) Delete(id uint) error {
 // UpdateDeployStatus 更新诱饵部署状态
 func (r *MySQLBaitRepo) UpdateDeployStatus(id uint, isDeployed bool) error {
 	return r.DB.Model(&Bait{}).Where("id = ?", id).
-		Update("is_deployed", isDeployed).Error
+		Update("IsDeployed", isDeployed).Error
 }
 
 // -------------------- 安全规则仓库 --------------------
@@ -248,8 +365,8 @@ type MySQLSecurityRuleRepo struct {
 	DB *gorm.DB
 }
 
-// NewMySQLSecurityRuleRepo 创建安全规则MySQL仓库
-func NewMySQLSecurityRuleRepo(db *gorm.DB) SecurityRuleRepository {
+// NewSecurityRuleRepo 创建安全规则仓库
+func NewSecurityRuleRepo(db *gorm.DB) SecurityRuleRepository {
 	return &MySQLSecurityRuleRepo{DB: db}
 }
 
@@ -260,6 +377,11 @@ func (r *MySQLSecurityRuleRepo) List() ([]SecurityRule, error) {
 	return rules, result.Error
 }
 
+// Query 按查询条件分页获取安全规则
+func (r *MySQLSecurityRuleRepo) Query(spec QuerySpec) (*Page[SecurityRule], error) {
+	return findPage[SecurityRule](r.DB, spec)
+}
+
 // GetByID 根据ID获取安全规则
 func (r *MySQLSecurityRuleRepo) GetByID(id uint) (*SecurityRule, error) {
 	var rule SecurityRule
@@ -288,7 +410,7 @@ func (r *MySQLSecurityRuleRepo) Delete(id uint) error {
 // UpdateStatus 更新安全规则状态
 func (r *MySQLSecurityRuleRepo) UpdateStatus(id uint, isEnabled bool) error {
 	return r.DB.Model(&SecurityRule{}).Where("id = ?", id).
-		Update("is_enabled", isEnabled).Error
+		Update("IsEnabled", isEnabled).Error
 }
 
 // -------------------- 规则日志仓库 --------------------
@@ -298,8 +420,8 @@ type MySQLRuleLogRepo struct {
 	DB *gorm.DB
 }
 
-// NewMySQLRuleLogRepo 创建规则日志MySQL仓库
-func NewMySQLRuleLogRepo(db *gorm.DB) RuleLogRepository {
+// NewRuleLogRepo 创建规则日志仓库
+func NewRuleLogRepo(db *gorm.DB) RuleLogRepository {
 	return &MySQLRuleLogRepo{DB: db}
 }
 
@@ -315,6 +437,11 @@ func (r *MySQLRuleLogRepo) List() ([]RuleLog, error) {
 	return r.GetAll()
 }
 
+// Query 按查询条件分页获取规则日志
+func (r *MySQLRuleLogRepo) Query(spec QuerySpec) (*Page[RuleLog], error) {
+	return findPage[RuleLog](r.DB, spec)
+}
+
 // GetByID 根据ID获取规则日志
 func (r *MySQLRuleLogRepo) GetByID(id uint) (*RuleLog, error) {
 	var log RuleLog
@@ -349,8 +476,8 @@ type MySQLDockerImageRepo struct {
 	DB *gorm.DB
 }
 
-// NewMySQLDockerImageRepo 创建Docker镜像MySQL仓库
-func NewMySQLDockerImageRepo(db *gorm.DB) DockerImageRepository {
+// NewDockerImageRepo 创建Docker镜像仓库
+func NewDockerImageRepo(db *gorm.DB) DockerImageRepository {
 	return &MySQLDockerImageRepo{DB: db}
 }
 
@@ -361,6 +488,11 @@ func (r *MySQLDockerImageRepo) List() ([]DockerImage, error) {
 	return images, result.Error
 }
 
+// Query 按查询条件分页获取Docker镜像
+func (r *MySQLDockerImageRepo) Query(spec QuerySpec) (*Page[DockerImage], error) {
+	return findPage[DockerImage](r.DB, spec)
+}
+
 // GetByID 根据ID获取Docker镜像
 func (r *MySQLDockerImageRepo) GetByID(id uint) (*DockerImage, error) {
 	var image DockerImage
@@ -411,8 +543,8 @@ type MySQLDockerImageLogRepo struct {
 	DB *gorm.DB
 }
 
-// NewMySQLDockerImageLogRepo 创建Docker镜像日志MySQL仓库
-func NewMySQLDockerImageLogRepo(db *gorm.DB) DockerImageLogRepository {
+// NewDockerImageLogRepo 创建Docker镜像日志仓库
+func NewDockerImageLogRepo(db *gorm.DB) DockerImageLogRepository {
 	return &MySQLDockerImageLogRepo{DB: db}
 }
 
@@ -423,6 +555,11 @@ func (r *MySQLDockerImageLogRepo) List() ([]DockerImageLog, error) {
 	return logs, result.Error
 }
 
+// Query 按查询条件分页获取Docker镜像日志
+func (r *MySQLDockerImageLogRepo) Query(spec QuerySpec) (*Page[DockerImageLog], error) {
+	return findPage[DockerImageLog](r.DB, spec)
+}
+
 // GetByID 根据ID获取Docker镜像日志
 func (r *MySQLDockerImageLogRepo) GetByID(id uint) (*DockerImageLog, error) {
 	var log DockerImageLog
@@ -458,8 +595,8 @@ type MySQLContainerLogSegmentRepo struct {
 	DB *gorm.DB
 }
 
-// NewMySQLContainerLogSegmentRepo 创建容器日志分析MySQL仓库
-func NewMySQLContainerLogSegmentRepo(db *gorm.DB) ContainerLogSegmentRepository {
+// NewContainerLogSegmentRepo 创建容器日志分析仓库
+func NewContainerLogSegmentRepo(db *gorm.DB) ContainerLogSegmentRepository {
 	return &MySQLContainerLogSegmentRepo{DB: db}
 }
 
@@ -470,6 +607,11 @@ func (r *MySQLContainerLogSegmentRepo) List() ([]ContainerLogSegment, error) {
 	return segments, result.Error
 }
 
+// Query 按查询条件分页获取容器日志分析结果
+func (r *MySQLContainerLogSegmentRepo) Query(spec QuerySpec) (*Page[ContainerLogSegment], error) {
+	return findPage[ContainerLogSegment](r.DB, spec)
+}
+
 // GetByID 根据ID获取容器日志分析结果
 func (r *MySQLContainerLogSegmentRepo) GetByID(id uint) (*ContainerLogSegment, error) {
 	var segment ContainerLogSegment
@@ -483,7 +625,7 @@ func (r *MySQLContainerLogSegmentRepo) GetByID(id uint) (*ContainerLogSegment, e
 // GetByContainerID 根据容器ID获取日志分析结果
 func (r *MySQLContainerLogSegmentRepo) GetByContainerID(containerID string) ([]ContainerLogSegment, error) {
 	var segments []ContainerLogSegment
-	result := r.DB.Where("container_id = ?", containerID).Order("timestamp DESC, line_number ASC").Find(&segments)
+	result := r.DB.Where("container_id = ?", containerID).Order(columnOf(r.DB, "timestamp") + " DESC, line_number ASC").Find(&segments)
 	return segments, result.Error
 }
 
@@ -526,8 +668,8 @@ type MySQLDockerContainerRepo struct {
 	DB *gorm.DB
 }
 
-// NewMySQLDockerContainerRepo 创建Docker容器MySQL仓库
-func NewMySQLDockerContainerRepo(db *gorm.DB) DockerContainerRepository {
+// NewDockerContainerRepo 创建Docker容器仓库
+func NewDockerContainerRepo(db *gorm.DB) DockerContainerRepository {
 	return &MySQLDockerContainerRepo{DB: db}
 }
 
@@ -538,6 +680,11 @@ func (r *MySQLDockerContainerRepo) List() ([]DockerContainer, error) {
 	return containers, result.Error
 }
 
+// Query 按查询条件分页获取Docker容器
+func (r *MySQLDockerContainerRepo) Query(spec QuerySpec) (*Page[DockerContainer], error) {
+	return findPage[DockerContainer](r.DB, spec)
+}
+
 // GetByID 根据ID获取Docker容器
 func (r *MySQLDockerContainerRepo) GetByID(id uint) (*DockerContainer, error) {
 	var container DockerContainer
@@ -592,8 +739,8 @@ func (r *MySQLDockerContainerRepo) DeleteByContainerID(containerID string) error
 func (r *MySQLDockerContainerRepo) UpdateStatus(containerID string, status string) error {
 	return r.DB.Model(&DockerContainer{}).Where("container_id = ?", containerID).
 		Updates(map[string]interface{}{
-			"status":     status,
-			"updated_at": time.Now(),
+			"Status":    status,
+			"UpdatedAt": time.Now(),
 		}).Error
 }
 
@@ -604,18 +751,28 @@ type MySQLHeadlingAuthLogRepo struct {
 	DB *gorm.DB
 }
 
-// NewMySQLHeadlingAuthLogRepo 创建Headling认证日志MySQL仓库
-func NewMySQLHeadlingAuthLogRepo(db *gorm.DB) HeadlingAuthLogRepository {
+// NewHeadlingAuthLogRepo 创建Headling认证日志仓库
+func NewHeadlingAuthLogRepo(db *gorm.DB) HeadlingAuthLogRepository {
 	return &MySQLHeadlingAuthLogRepo{DB: db}
 }
 
 // List 获取所有Headling认证日志
 func (r *MySQLHeadlingAuthLogRepo) List() ([]HeadlingAuthLog, error) {
 	var logs []HeadlingAuthLog
-	result := r.DB.Order("timestamp DESC").Find(&logs)
+	result := r.DB.Order(columnOf(r.DB, "timestamp") + " DESC").Find(&logs)
 	return logs, result.Error
 }
 
+// Query 按查询条件分页获取Headling认证日志
+func (r *MySQLHeadlingAuthLogRepo) Query(spec QuerySpec) (*Page[HeadlingAuthLog], error) {
+	return findPage[HeadlingAuthLog](r.DB, spec)
+}
+
+// ListAfterID 按ID顺序获取指定ID之后的Headling认证日志，用于分批遍历全表
+func (r *MySQLHeadlingAuthLogRepo) ListAfterID(afterID uint, limit int) ([]HeadlingAuthLog, error) {
+	return listAfterID[HeadlingAuthLog](r.DB, afterID, limit)
+}
+
 // GetByID 根据ID获取Headling认证日志
 func (r *MySQLHeadlingAuthLogRepo) GetByID(id uint) (*HeadlingAuthLog, error) {
 	var log HeadlingAuthLog
@@ -639,35 +796,35 @@ func (r *MySQLHeadlingAuthLogRepo) GetByAuthID(authID string) (*HeadlingAuthLog,
 // GetBySessionID 根据会话ID获取Headling认证日志
 func (r *MySQLHeadlingAuthLogRepo) GetBySessionID(sessionID string) ([]HeadlingAuthLog, error) {
 	var logs []HeadlingAuthLog
-	result := r.DB.Where("session_id = ?", sessionID).Order("timestamp ASC").Find(&logs)
+	result := r.DB.Where("session_id = ?", sessionID).Order(columnOf(r.DB, "timestamp") + " ASC").Find(&logs)
 	return logs, result.Error
 }
 
 // GetBySourceIP 根据源IP获取Headling认证日志
 func (r *MySQLHeadlingAuthLogRepo) GetBySourceIP(sourceIP string) ([]HeadlingAuthLog, error) {
 	var logs []HeadlingAuthLog
-	result := r.DB.Where("source_ip = ?", sourceIP).Order("timestamp DESC").Find(&logs)
+	result := r.DB.Where("source_ip = ?", sourceIP).Order(columnOf(r.DB, "timestamp") + " DESC").Find(&logs)
 	return logs, result.Error
 }
 
 // GetByContainerID 根据容器ID获取Headling认证日志
 func (r *MySQLHeadlingAuthLogRepo) GetByContainerID(containerID string) ([]HeadlingAuthLog, error) {
 	var logs []HeadlingAuthLog
-	result := r.DB.Where("container_id = ?", containerID).Order("timestamp DESC").Find(&logs)
+	result := r.DB.Where("container_id = ?", containerID).Order(columnOf(r.DB, "timestamp") + " DESC").Find(&logs)
 	return logs, result.Error
 }
 
 // GetByProtocol 根据协议获取Headling认证日志
 func (r *MySQLHeadlingAuthLogRepo) GetByProtocol(protocol string) ([]HeadlingAuthLog, error) {
 	var logs []HeadlingAuthLog
-	result := r.DB.Where("protocol = ?", protocol).Order("timestamp DESC").Find(&logs)
+	result := r.DB.Where("protocol = ?", protocol).Order(columnOf(r.DB, "timestamp") + " DESC").Find(&logs)
 	return logs, result.Error
 }
 
 // GetByTimeRange 根据时间范围获取Headling认证日志
 func (r *MySQLHeadlingAuthLogRepo) GetByTimeRange(startTime, endTime time.Time) ([]HeadlingAuthLog, error) {
 	var logs []HeadlingAuthLog
-	result := r.DB.Where("timestamp BETWEEN ? AND ?", startTime, endTime).Order("timestamp DESC").Find(&logs)
+	result := r.DB.Where(columnOf(r.DB, "timestamp")+" BETWEEN ? AND ?", startTime, endTime).Order(columnOf(r.DB, "timestamp") + " DESC").Find(&logs)
 	return logs, result.Error
 }
 
@@ -677,13 +834,15 @@ func (r *MySQLHeadlingAuthLogRepo) Create(log *HeadlingAuthLog) error {
 	return r.DB.Create(log).Error
 }
 
-// CreateBatch 批量创建Headling认证日志
-func (r *MySQLHeadlingAuthLogRepo) CreateBatch(logs []HeadlingAuthLog) error {
+// CreateBatch 批量创建Headling认证日志，auth_id已存在的记录会被跳过，返回实际插入的条数
+func (r *MySQLHeadlingAuthLogRepo) CreateBatch(logs []HeadlingAuthLog) (int64, error) {
 	now := time.Now()
 	for i := range logs {
 		logs[i].CreatedAt = now
 	}
-	return r.DB.CreateInBatches(logs, 100).Error
+	return createSkippingDuplicates(r.DB, logs,
+		func(log *HeadlingAuthLog) string { return log.AuthID },
+		func(log *HeadlingAuthLog, id uint) { log.ID = id })
 }
 
 // Update 更新Headling认证日志
@@ -701,25 +860,19 @@ func (r *MySQLHeadlingAuthLogRepo) DeleteByContainerID(containerID string) error
 	return r.DB.Where("container_id = ?", containerID).Delete(&HeadlingAuthLog{}).Error
 }
 
-// GetStatistics 获取认证统计信息
-func (r *MySQLHeadlingAuthLogRepo) GetStatistics() ([]HeadlingAuthStatistics, error) {
-	var stats []HeadlingAuthStatistics
-	result := r.DB.Table("v_headling_auth_statistics").Find(&stats)
-	return stats, result.Error
+// GetStatistics 按日期和协议获取认证统计信息
+func (r *MySQLHeadlingAuthLogRepo) GetStatistics(filter StatisticsFilter) ([]HeadlingAuthStatistics, error) {
+	return headlingAuthStatistics(r.DB, filter)
 }
 
 // GetAttackerIPStatistics 获取攻击者IP统计信息
-func (r *MySQLHeadlingAuthLogRepo) GetAttackerIPStatistics() ([]AttackerIPStatistics, error) {
-	var stats []AttackerIPStatistics
-	result := r.DB.Table("v_attacker_ip_statistics").Find(&stats)
-	return stats, result.Error
+func (r *MySQLHeadlingAuthLogRepo) GetAttackerIPStatistics(filter StatisticsFilter) ([]AttackerIPStatistics, error) {
+	return headlingAttackerIPStatistics(r.DB, filter)
 }
 
 // GetTopAttackers 获取前N个攻击者
 func (r *MySQLHeadlingAuthLogRepo) GetTopAttackers(limit int) ([]AttackerIPStatistics, error) {
-	var stats []AttackerIPStatistics
-	result := r.DB.Table("v_attacker_ip_statistics").Limit(limit).Find(&stats)
-	return stats, result.Error
+	return headlingAttackerIPStatistics(r.DB, StatisticsFilter{Limit: limit})
 }
 
 // GetTopUsernames 获取最常用的用户名
@@ -753,8 +906,8 @@ type MySQLCowrieLogRepo struct {
 	DB *gorm.DB
 }
 
-// NewMySQLCowrieLogRepo 创建Cowrie日志MySQL仓库
-func NewMySQLCowrieLogRepo(db *gorm.DB) CowrieLogRepository {
+// NewCowrieLogRepo 创建Cowrie日志仓库
+func NewCowrieLogRepo(db *gorm.DB) CowrieLogRepository {
 	return &MySQLCowrieLogRepo{DB: db}
 }
 
@@ -765,6 +918,16 @@ func (r *MySQLCowrieLogRepo) List() ([]CowrieLog, error) {
 	return logs, result.Error
 }
 
+// Query 按查询条件分页获取Cowrie日志
+func (r *MySQLCowrieLogRepo) Query(spec QuerySpec) (*Page[CowrieLog], error) {
+	return findPage[CowrieLog](r.DB, spec)
+}
+
+// ListAfterID 按ID顺序获取指定ID之后的Cowrie日志，用于分批遍历全表
+func (r *MySQLCowrieLogRepo) ListAfterID(afterID uint, limit int) ([]CowrieLog, error) {
+	return listAfterID[CowrieLog](r.DB, afterID, limit)
+}
+
 // GetByID 根据ID获取Cowrie日志
 func (r *MySQLCowrieLogRepo) GetByID(id uint) (*CowrieLog, error) {
 	var log CowrieLog
@@ -792,6 +955,29 @@ func (r *MySQLCowrieLogRepo) GetBySessionID(sessionID string) ([]CowrieLog, erro
 	return logs, result.Error
 }
 
+// GetSessionConnects 获取容器中指定会话已入库的session.connect事件
+func (r *MySQLCowrieLogRepo) GetSessionConnects(containerID string, sessionIDs []string) ([]CowrieLog, error) {
+	var logs []CowrieLog
+	if len(sessionIDs) == 0 {
+		return logs, nil
+	}
+	result := r.DB.Where("container_id = ? AND event_id = ? AND session_id IN ?",
+		containerID, CowrieEventSessionConnect, sessionIDs).Find(&logs)
+	return logs, result.Error
+}
+
+// MarkLastCommandNotFound 将会话中最近一条已入库的命令输入事件标记为未识别，会话中没有命令输入时不做修改
+func (r *MySQLCowrieLogRepo) MarkLastCommandNotFound(containerID, sessionID string) error {
+	var last CowrieLog
+	result := r.DB.Select("id").
+		Where("container_id = ? AND session_id = ? AND event_id = ?", containerID, sessionID, CowrieEventCommandInput).
+		Order("event_time DESC, id DESC").Limit(1).Find(&last)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	return r.DB.Model(&CowrieLog{}).Where("id = ?", last.ID).Update("CommandFound", false).Error
+}
+
 // GetBySourceIP 根据源IP获取Cowrie日志
 func (r *MySQLCowrieLogRepo) GetBySourceIP(sourceIP string) ([]CowrieLog, error) {
 	var logs []CowrieLog
@@ -841,19 +1027,69 @@ func (r *MySQLCowrieLogRepo) GetByUsername(username string) ([]CowrieLog, error)
 	return logs, result.Error
 }
 
+// GetByEventID 根据事件类型获取Cowrie日志
+func (r *MySQLCowrieLogRepo) GetByEventID(eventID string) ([]CowrieLog, error) {
+	var logs []CowrieLog
+	result := r.DB.Where("event_id = ?", eventID).Order("event_time DESC").Find(&logs)
+	return logs, result.Error
+}
+
+// GetLoginEvents 获取登录成功或失败的事件
+func (r *MySQLCowrieLogRepo) GetLoginEvents(success bool) ([]CowrieLog, error) {
+	eventID := CowrieEventLoginFailed
+	if success {
+		eventID = CowrieEventLoginSuccess
+	}
+	return r.GetByEventID(eventID)
+}
+
+// GetCommandEvents 获取命令输入和命令失败事件
+func (r *MySQLCowrieLogRepo) GetCommandEvents() ([]CowrieLog, error) {
+	var logs []CowrieLog
+	result := r.DB.Where("event_id IN ?", []string{CowrieEventCommandInput, CowrieEventCommandFailed}).
+		Order("event_time DESC").Find(&logs)
+	return logs, result.Error
+}
+
+// GetFileTransferEvents 获取文件下载和上传事件
+func (r *MySQLCowrieLogRepo) GetFileTransferEvents() ([]CowrieLog, error) {
+	var logs []CowrieLog
+	result := r.DB.Where("event_id IN ?", []string{CowrieEventFileDownload, CowrieEventFileUpload}).
+		Order("event_time DESC").Find(&logs)
+	return logs, result.Error
+}
+
+// GetFileTransferEventsByContainerID 获取指定容器的文件下载和上传事件
+func (r *MySQLCowrieLogRepo) GetFileTransferEventsByContainerID(containerID string) ([]CowrieLog, error) {
+	var logs []CowrieLog
+	result := r.DB.Where("container_id = ? AND event_id IN ?", containerID, []string{CowrieEventFileDownload, CowrieEventFileUpload}).
+		Order("event_time DESC").Find(&logs)
+	return logs, result.Error
+}
+
+// GetDirectTCPIPEvents 获取端口转发(direct-tcpip)事件
+func (r *MySQLCowrieLogRepo) GetDirectTCPIPEvents() ([]CowrieLog, error) {
+	var logs []CowrieLog
+	result := r.DB.Where("event_id IN ?", []string{CowrieEventDirectTCPIP, CowrieEventDirectTCPIPData}).
+		Order("event_time DESC").Find(&logs)
+	return logs, result.Error
+}
+
 // Create 创建Cowrie日志
 func (r *MySQLCowrieLogRepo) Create(log *CowrieLog) error {
 	log.CreatedAt = time.Now()
 	return r.DB.Create(log).Error
 }
 
-// CreateBatch 批量创建Cowrie日志
-func (r *MySQLCowrieLogRepo) CreateBatch(logs []CowrieLog) error {
+// CreateBatch 批量创建Cowrie日志，auth_id已存在的记录会被跳过，返回实际插入的条数
+func (r *MySQLCowrieLogRepo) CreateBatch(logs []CowrieLog) (int64, error) {
 	now := time.Now()
 	for i := range logs {
 		logs[i].CreatedAt = now
 	}
-	return r.DB.CreateInBatches(logs, 100).Error
+	return createSkippingDuplicates(r.DB, logs,
+		func(log *CowrieLog) string { return log.AuthID },
+		func(log *CowrieLog, id uint) { log.ID = id })
 }
 
 // Update 更新Cowrie日志
@@ -871,39 +1107,29 @@ func (r *MySQLCowrieLogRepo) DeleteByContainerID(containerID string) error {
 	return r.DB.Where("container_id = ?", containerID).Delete(&CowrieLog{}).Error
 }
 
-// GetStatistics 获取Cowrie统计信息
-func (r *MySQLCowrieLogRepo) GetStatistics() ([]CowrieStatistics, error) {
-	var stats []CowrieStatistics
-	result := r.DB.Table("v_cowrie_statistics").Find(&stats)
-	return stats, result.Error
+// GetStatistics 按日期和协议获取Cowrie统计信息
+func (r *MySQLCowrieLogRepo) GetStatistics(filter StatisticsFilter) ([]CowrieStatistics, error) {
+	return cowrieStatistics(r.DB, filter)
 }
 
 // GetAttackerBehavior 获取攻击者行为统计信息
-func (r *MySQLCowrieLogRepo) GetAttackerBehavior() ([]CowrieAttackerBehavior, error) {
-	var behavior []CowrieAttackerBehavior
-	result := r.DB.Table("v_cowrie_attacker_behavior").Find(&behavior)
-	return behavior, result.Error
+func (r *MySQLCowrieLogRepo) GetAttackerBehavior(filter StatisticsFilter) ([]CowrieAttackerBehavior, error) {
+	return cowrieAttackerBehavior(r.DB, filter)
 }
 
 // GetTopAttackers 获取前N个攻击者
 func (r *MySQLCowrieLogRepo) GetTopAttackers(limit int) ([]CowrieAttackerBehavior, error) {
-	var attackers []CowrieAttackerBehavior
-	result := r.DB.Table("v_cowrie_attacker_behavior").Limit(limit).Find(&attackers)
-	return attackers, result.Error
+	return cowrieAttackerBehavior(r.DB, StatisticsFilter{Limit: limit})
 }
 
 // GetCommandStatistics 获取命令统计信息
-func (r *MySQLCowrieLogRepo) GetCommandStatistics() ([]CowrieCommandStatistics, error) {
-	var stats []CowrieCommandStatistics
-	result := r.DB.Table("v_cowrie_command_statistics").Find(&stats)
-	return stats, result.Error
+func (r *MySQLCowrieLogRepo) GetCommandStatistics(filter StatisticsFilter) ([]CowrieCommandStatistics, error) {
+	return cowrieCommandStatistics(r.DB, filter)
 }
 
 // GetTopCommands 获取最常用的命令
 func (r *MySQLCowrieLogRepo) GetTopCommands(limit int) ([]CowrieCommandStatistics, error) {
-	var commands []CowrieCommandStatistics
-	result := r.DB.Table("v_cowrie_command_statistics").Limit(limit).Find(&commands)
-	return commands, result.Error
+	return cowrieCommandStatistics(r.DB, StatisticsFilter{Limit: limit})
 }
 
 // GetTopUsernames 获取最常用的用户名
@@ -944,3 +1170,1206 @@ func (r *MySQLCowrieLogRepo) GetTopFingerprints(limit int) ([]map[string]interfa
 		Find(&results)
 	return results, result.Error
 }
+
+// GetEventStatistics 按事件类型统计Cowrie日志
+func (r *MySQLCowrieLogRepo) GetEventStatistics() ([]CowrieEventStatistics, error) {
+	var stats []CowrieEventStatistics
+	query := r.DB.Table("cowrie_log").
+		Select("event_id, COUNT(*) as total_events, COUNT(DISTINCT source_ip) as unique_ips, " +
+			"COUNT(DISTINCT session_id) as unique_sessions, MIN(event_time) as first_event, MAX(event_time) as last_event").
+		Where("event_id IS NOT NULL AND event_id != ''").
+		Group("event_id").
+		Order("total_events DESC")
+	err := findStatistics(query, &stats)
+	return stats, err
+}
+
+// -------------------- 容器日志游标仓库 --------------------
+
+// MySQLContainerLogCursorRepo 容器日志游标MySQL仓库
+type MySQLContainerLogCursorRepo struct {
+	DB *gorm.DB
+}
+
+// NewContainerLogCursorRepo 创建容器日志游标仓库
+func NewContainerLogCursorRepo(db *gorm.DB) ContainerLogCursorRepository {
+	return &MySQLContainerLogCursorRepo{DB: db}
+}
+
+// Get 获取指定容器和日志来源的游标，不存在时返回nil
+func (r *MySQLContainerLogCursorRepo) Get(containerID, logSource string) (*ContainerLogCursor, error) {
+	var cursor ContainerLogCursor
+	result := r.DB.Where("container_id = ? AND log_source = ?", containerID, logSource).Limit(1).Find(&cursor)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &cursor, nil
+}
+
+// GetByContainerID 获取容器的所有日志游标
+func (r *MySQLContainerLogCursorRepo) GetByContainerID(containerID string) ([]ContainerLogCursor, error) {
+	var cursors []ContainerLogCursor
+	result := r.DB.Where("container_id = ?", containerID).Find(&cursors)
+	return cursors, result.Error
+}
+
+// Save 保存游标
+func (r *MySQLContainerLogCursorRepo) Save(cursor *ContainerLogCursor) error {
+	cursor.UpdatedAt = time.Now()
+	return r.DB.Save(cursor).Error
+}
+
+// Delete 删除游标
+func (r *MySQLContainerLogCursorRepo) Delete(containerID, logSource string) error {
+	return r.DB.Where("container_id = ? AND log_source = ?", containerID, logSource).
+		Delete(&ContainerLogCursor{}).Error
+}
+
+// -------------------- Cowrie TTY日志仓库 --------------------
+
+// MySQLCowrieTTYLogRepo Cowrie TTY日志MySQL仓库
+type MySQLCowrieTTYLogRepo struct {
+	DB *gorm.DB
+}
+
+// NewCowrieTTYLogRepo 创建Cowrie TTY日志仓库
+func NewCowrieTTYLogRepo(db *gorm.DB) CowrieTTYLogRepository {
+	return &MySQLCowrieTTYLogRepo{DB: db}
+}
+
+// GetBySessionID 获取会话的TTY日志，不存在时返回nil
+func (r *MySQLCowrieTTYLogRepo) GetBySessionID(sessionID string) (*CowrieTTYLog, error) {
+	var ttyLog CowrieTTYLog
+	result := r.DB.Where("session_id = ?", sessionID).Limit(1).Find(&ttyLog)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &ttyLog, nil
+}
+
+// Create 创建TTY日志记录
+func (r *MySQLCowrieTTYLogRepo) Create(ttyLog *CowrieTTYLog) error {
+	ttyLog.CreatedAt = time.Now()
+	return r.DB.Create(ttyLog).Error
+}
+
+// -------------------- 恶意样本仓库 --------------------
+
+// MySQLMalwareSampleRepo 恶意样本MySQL仓库
+type MySQLMalwareSampleRepo struct {
+	DB *gorm.DB
+}
+
+// NewMalwareSampleRepo 创建恶意样本仓库
+func NewMalwareSampleRepo(db *gorm.DB) MalwareSampleRepository {
+	return &MySQLMalwareSampleRepo{DB: db}
+}
+
+// List 获取所有样本，按最近捕获时间倒序
+func (r *MySQLMalwareSampleRepo) List() ([]MalwareSample, error) {
+	var samples []MalwareSample
+	result := r.DB.Order("last_seen DESC").Find(&samples)
+	return samples, result.Error
+}
+
+// Query 按查询条件分页获取样本
+func (r *MySQLMalwareSampleRepo) Query(spec QuerySpec) (*Page[MalwareSample], error) {
+	return findPage[MalwareSample](r.DB, spec)
+}
+
+// GetByID 根据ID获取样本
+func (r *MySQLMalwareSampleRepo) GetByID(id uint) (*MalwareSample, error) {
+	var sample MalwareSample
+	result := r.DB.First(&sample, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &sample, nil
+}
+
+// GetBySHA256 根据SHA256获取样本，不存在时返回nil
+func (r *MySQLMalwareSampleRepo) GetBySHA256(sha256 string) (*MalwareSample, error) {
+	var sample MalwareSample
+	result := r.DB.Where("sha256 = ?", sha256).Limit(1).Find(&sample)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &sample, nil
+}
+
+// Create 创建样本
+func (r *MySQLMalwareSampleRepo) Create(sample *MalwareSample) error {
+	now := time.Now()
+	sample.CreatedAt = now
+	sample.UpdatedAt = now
+	return r.DB.Create(sample).Error
+}
+
+// Update 更新样本
+func (r *MySQLMalwareSampleRepo) Update(sample *MalwareSample) error {
+	sample.UpdatedAt = time.Now()
+	return r.DB.Save(sample).Error
+}
+
+// CreateSighting 创建样本捕获记录
+func (r *MySQLMalwareSampleRepo) CreateSighting(sighting *MalwareSighting) error {
+	sighting.CreatedAt = time.Now()
+	return r.DB.Create(sighting).Error
+}
+
+// RecordSighting 在一个事务中保存样本(ID为0时创建，否则更新)并创建捕获记录，任一步失败时都不会留下记录
+func (r *MySQLMalwareSampleRepo) RecordSighting(sample *MalwareSample, sighting *MalwareSighting) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		sample.UpdatedAt = now
+		if sample.ID == 0 {
+			sample.CreatedAt = now
+			if err := tx.Create(sample).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Save(sample).Error; err != nil {
+			return err
+		}
+
+		sighting.SampleID = sample.ID
+		sighting.CreatedAt = now
+		return tx.Create(sighting).Error
+	})
+}
+
+// HasSighting 判断Cowrie文件传输事件是否已经处理过
+func (r *MySQLMalwareSampleRepo) HasSighting(cowrieLogID uint) (bool, error) {
+	var count int64
+	result := r.DB.Model(&MalwareSighting{}).Where("cowrie_log_id = ?", cowrieLogID).Count(&count)
+	return count > 0, result.Error
+}
+
+// GetSightingsBySampleID 获取样本的所有捕获记录
+func (r *MySQLMalwareSampleRepo) GetSightingsBySampleID(sampleID uint) ([]MalwareSighting, error) {
+	var sightings []MalwareSighting
+	result := r.DB.Where("sample_id = ?", sampleID).Order("event_time DESC").Find(&sightings)
+	return sightings, result.Error
+}
+
+// GetSightingsBySourceIP 获取攻击者IP相关的捕获记录
+func (r *MySQLMalwareSampleRepo) GetSightingsBySourceIP(sourceIP string) ([]MalwareSighting, error) {
+	var sightings []MalwareSighting
+	result := r.DB.Where("source_ip = ?", sourceIP).Order("event_time DESC").Find(&sightings)
+	return sightings, result.Error
+}
+
+// -------------------- Dionaea日志仓库 --------------------
+
+// MySQLDionaeaLogRepo Dionaea日志MySQL仓库
+type MySQLDionaeaLogRepo struct {
+	DB *gorm.DB
+}
+
+// NewDionaeaLogRepo 创建Dionaea日志仓库
+func NewDionaeaLogRepo(db *gorm.DB) DionaeaLogRepository {
+	return &MySQLDionaeaLogRepo{DB: db}
+}
+
+// List 获取所有Dionaea日志
+func (r *MySQLDionaeaLogRepo) List() ([]DionaeaLog, error) {
+	var logs []DionaeaLog
+	result := r.DB.Order("event_time DESC").Find(&logs)
+	return logs, result.Error
+}
+
+// Query 按查询条件分页获取Dionaea日志
+func (r *MySQLDionaeaLogRepo) Query(spec QuerySpec) (*Page[DionaeaLog], error) {
+	return findPage[DionaeaLog](r.DB, spec)
+}
+
+// ListAfterID 按ID顺序获取指定ID之后的Dionaea日志，用于分批遍历全表
+func (r *MySQLDionaeaLogRepo) ListAfterID(afterID uint, limit int) ([]DionaeaLog, error) {
+	return listAfterID[DionaeaLog](r.DB, afterID, limit)
+}
+
+// GetByID 根据ID获取Dionaea日志
+func (r *MySQLDionaeaLogRepo) GetByID(id uint) (*DionaeaLog, error) {
+	var log DionaeaLog
+	result := r.DB.First(&log, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &log, nil
+}
+
+// GetBySessionID 获取同一连接的所有事件
+func (r *MySQLDionaeaLogRepo) GetBySessionID(sessionID string) ([]DionaeaLog, error) {
+	var logs []DionaeaLog
+	result := r.DB.Where("session_id = ?", sessionID).Order("event_time ASC").Find(&logs)
+	return logs, result.Error
+}
+
+// GetBySourceIP 根据源IP获取Dionaea日志
+func (r *MySQLDionaeaLogRepo) GetBySourceIP(sourceIP string) ([]DionaeaLog, error) {
+	var logs []DionaeaLog
+	result := r.DB.Where("source_ip = ?", sourceIP).Order("event_time DESC").Find(&logs)
+	return logs, result.Error
+}
+
+// GetByContainerID 根据容器ID获取Dionaea日志
+func (r *MySQLDionaeaLogRepo) GetByContainerID(containerID string) ([]DionaeaLog, error) {
+	var logs []DionaeaLog
+	result := r.DB.Where("container_id = ?", containerID).Order("event_time DESC").Find(&logs)
+	return logs, result.Error
+}
+
+// GetByProtocol 根据服务模块获取Dionaea日志
+func (r *MySQLDionaeaLogRepo) GetByProtocol(protocol string) ([]DionaeaLog, error) {
+	var logs []DionaeaLog
+	result := r.DB.Where("protocol = ?", protocol).Order("event_time DESC").Find(&logs)
+	return logs, result.Error
+}
+
+// GetByEventType 根据事件类型获取Dionaea日志
+func (r *MySQLDionaeaLogRepo) GetByEventType(eventType string) ([]DionaeaLog, error) {
+	var logs []DionaeaLog
+	result := r.DB.Where("event_type = ?", eventType).Order("event_time DESC").Find(&logs)
+	return logs, result.Error
+}
+
+// GetByUsername 根据用户名获取登录事件
+func (r *MySQLDionaeaLogRepo) GetByUsername(username string) ([]DionaeaLog, error) {
+	var logs []DionaeaLog
+	result := r.DB.Where("username = ?", username).Order("event_time DESC").Find(&logs)
+	return logs, result.Error
+}
+
+// GetByTimeRange 根据时间范围获取Dionaea日志
+func (r *MySQLDionaeaLogRepo) GetByTimeRange(startTime, endTime time.Time) ([]DionaeaLog, error) {
+	var logs []DionaeaLog
+	result := r.DB.Where("event_time BETWEEN ? AND ?", startTime, endTime).Order("event_time DESC").Find(&logs)
+	return logs, result.Error
+}
+
+// CreateBatch 批量创建Dionaea日志，auth_id已存在的记录会被跳过，返回实际插入的条数
+func (r *MySQLDionaeaLogRepo) CreateBatch(logs []DionaeaLog) (int64, error) {
+	now := time.Now()
+	for i := range logs {
+		logs[i].CreatedAt = now
+	}
+	return createSkippingDuplicates(r.DB, logs,
+		func(log *DionaeaLog) string { return log.AuthID },
+		func(log *DionaeaLog, id uint) { log.ID = id })
+}
+
+// DeleteByContainerID 删除容器相关的Dionaea日志
+func (r *MySQLDionaeaLogRepo) DeleteByContainerID(containerID string) error {
+	return r.DB.Where("container_id = ?", containerID).Delete(&DionaeaLog{}).Error
+}
+
+// GetStatistics 按日期和服务模块统计Dionaea日志
+func (r *MySQLDionaeaLogRepo) GetStatistics() ([]DionaeaStatistics, error) {
+	var stats []DionaeaStatistics
+	logDate := dateOf(r.DB, "event_time")
+	query := r.DB.Table("dionaea_log").
+		Select(logDate + " as log_date, protocol, " +
+			"SUM(CASE WHEN event_type = 'connection' THEN 1 ELSE 0 END) as connections, " +
+			"COUNT(DISTINCT source_ip) as unique_ips, " +
+			"SUM(CASE WHEN event_type = 'login' THEN 1 ELSE 0 END) as login_attempts, " +
+			"SUM(CASE WHEN event_type = 'download' THEN 1 ELSE 0 END) as downloads, " +
+			"MIN(event_time) as first_event, MAX(event_time) as last_event").
+		Group(logDate + ", protocol").
+		Order("log_date DESC, connections DESC")
+	err := findStatistics(query, &stats)
+	return stats, err
+}
+
+// GetTopAttackers 获取连接次数最多的前N个攻击者
+func (r *MySQLDionaeaLogRepo) GetTopAttackers(limit int) ([]DionaeaAttackerStatistics, error) {
+	var attackers []DionaeaAttackerStatistics
+	query := r.DB.Table("dionaea_log").
+		Select("source_ip, " +
+			"SUM(CASE WHEN event_type = 'connection' THEN 1 ELSE 0 END) as connections, " +
+			"COUNT(DISTINCT protocol) as protocols_used, " +
+			"SUM(CASE WHEN event_type = 'login' THEN 1 ELSE 0 END) as login_attempts, " +
+			"SUM(CASE WHEN event_type = 'download' THEN 1 ELSE 0 END) as downloads, " +
+			"MIN(event_time) as first_seen, MAX(event_time) as last_seen").
+		Group("source_ip").
+		Order("connections DESC").
+		Limit(limit)
+	err := findStatistics(query, &attackers)
+	return attackers, err
+}
+
+// GetTopUsernames 获取最常用的用户名
+func (r *MySQLDionaeaLogRepo) GetTopUsernames(limit int) ([]map[string]interface{}, error) {
+	var results []map[string]interface{}
+	result := r.DB.Table("dionaea_log").
+		Select("username, COUNT(*) as count, COUNT(DISTINCT source_ip) as unique_ips").
+		Where("event_type = ? AND username != ''", DionaeaEventLogin).
+		Group("username").
+		Order("count DESC").
+		Limit(limit).
+		Find(&results)
+	return results, result.Error
+}
+
+// GetTopPasswords 获取最常用的密码
+func (r *MySQLDionaeaLogRepo) GetTopPasswords(limit int) ([]map[string]interface{}, error) {
+	var results []map[string]interface{}
+	result := r.DB.Table("dionaea_log").
+		Select("password, COUNT(*) as count, COUNT(DISTINCT source_ip) as unique_ips").
+		Where("event_type = ? AND password != ''", DionaeaEventLogin).
+		Group("password").
+		Order("count DESC").
+		Limit(limit).
+		Find(&results)
+	return results, result.Error
+}
+
+// GetTopDownloads 获取被下载最多的文件
+func (r *MySQLDionaeaLogRepo) GetTopDownloads(limit int) ([]map[string]interface{}, error) {
+	var results []map[string]interface{}
+	result := r.DB.Table("dionaea_log").
+		Select("md5_hash, MAX(url) as url, COUNT(*) as count, COUNT(DISTINCT source_ip) as unique_ips").
+		Where("event_type = ? AND md5_hash != ''", DionaeaEventDownload).
+		Group("md5_hash").
+		Order("count DESC").
+		Limit(limit).
+		Find(&results)
+	return results, result.Error
+}
+
+// -------------------- qeeqbox日志仓库 --------------------
+
+// MySQLQeeqboxLogRepo qeeqbox日志MySQL仓库
+type MySQLQeeqboxLogRepo struct {
+	DB *gorm.DB
+}
+
+// NewQeeqboxLogRepo 创建qeeqbox日志仓库
+func NewQeeqboxLogRepo(db *gorm.DB) QeeqboxLogRepository {
+	return &MySQLQeeqboxLogRepo{DB: db}
+}
+
+// List 获取所有qeeqbox日志
+func (r *MySQLQeeqboxLogRepo) List() ([]QeeqboxLog, error) {
+	var logs []QeeqboxLog
+	result := r.DB.Order("event_time DESC").Find(&logs)
+	return logs, result.Error
+}
+
+// Query 按查询条件分页获取qeeqbox日志
+func (r *MySQLQeeqboxLogRepo) Query(spec QuerySpec) (*Page[QeeqboxLog], error) {
+	return findPage[QeeqboxLog](r.DB, spec)
+}
+
+// ListAfterID 按ID顺序获取指定ID之后的qeeqbox日志，用于分批遍历全表
+func (r *MySQLQeeqboxLogRepo) ListAfterID(afterID uint, limit int) ([]QeeqboxLog, error) {
+	return listAfterID[QeeqboxLog](r.DB, afterID, limit)
+}
+
+// GetByID 根据ID获取qeeqbox日志
+func (r *MySQLQeeqboxLogRepo) GetByID(id uint) (*QeeqboxLog, error) {
+	var log QeeqboxLog
+	result := r.DB.First(&log, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &log, nil
+}
+
+// GetByContainerID 根据容器ID获取qeeqbox日志
+func (r *MySQLQeeqboxLogRepo) GetByContainerID(containerID string) ([]QeeqboxLog, error) {
+	var logs []QeeqboxLog
+	result := r.DB.Where("container_id = ?", containerID).Order("event_time DESC").Find(&logs)
+	return logs, result.Error
+}
+
+// GetBySourceIP 根据源IP获取qeeqbox日志
+func (r *MySQLQeeqboxLogRepo) GetBySourceIP(sourceIP string) ([]QeeqboxLog, error) {
+	var logs []QeeqboxLog
+	result := r.DB.Where("source_ip = ?", sourceIP).Order("event_time DESC").Find(&logs)
+	return logs, result.Error
+}
+
+// GetByProtocol 根据协议获取qeeqbox日志
+func (r *MySQLQeeqboxLogRepo) GetByProtocol(protocol string) ([]QeeqboxLog, error) {
+	var logs []QeeqboxLog
+	result := r.DB.Where("protocol = ?", protocol).Order("event_time DESC").Find(&logs)
+	return logs, result.Error
+}
+
+// GetByAction 根据动作获取qeeqbox日志
+func (r *MySQLQeeqboxLogRepo) GetByAction(action string) ([]QeeqboxLog, error) {
+	var logs []QeeqboxLog
+	result := r.DB.Where(columnOf(r.DB, "action")+" = ?", action).Order("event_time DESC").Find(&logs)
+	return logs, result.Error
+}
+
+// GetByTimeRange 根据时间范围获取qeeqbox日志
+func (r *MySQLQeeqboxLogRepo) GetByTimeRange(startTime, endTime time.Time) ([]QeeqboxLog, error) {
+	var logs []QeeqboxLog
+	result := r.DB.Where("event_time BETWEEN ? AND ?", startTime, endTime).Order("event_time DESC").Find(&logs)
+	return logs, result.Error
+}
+
+// Search 按组合条件查询qeeqbox日志
+func (r *MySQLQeeqboxLogRepo) Search(filter QeeqboxLogFilter) ([]QeeqboxLog, error) {
+	query := r.DB.Model(&QeeqboxLog{})
+	if filter.ContainerID != "" {
+		query = query.Where("container_id = ?", filter.ContainerID)
+	}
+	if filter.Protocol != "" {
+		query = query.Where("protocol = ?", filter.Protocol)
+	}
+	if filter.Action != "" {
+		query = query.Where(columnOf(r.DB, "action")+" = ?", filter.Action)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.SourceIP != "" {
+		query = query.Where("source_ip = ?", filter.SourceIP)
+	}
+	if filter.Username != "" {
+		query = query.Where("username = ?", filter.Username)
+	}
+	if filter.StartTime != nil {
+		query = query.Where("event_time >= ?", *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		query = query.Where("event_time <= ?", *filter.EndTime)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var logs []QeeqboxLog
+	result := query.Order("event_time DESC").Find(&logs)
+	return logs, result.Error
+}
+
+// CreateBatch 批量创建qeeqbox日志，auth_id已存在的记录会被跳过，返回实际插入的条数
+func (r *MySQLQeeqboxLogRepo) CreateBatch(logs []QeeqboxLog) (int64, error) {
+	now := time.Now()
+	for i := range logs {
+		logs[i].CreatedAt = now
+	}
+	return createSkippingDuplicates(r.DB, logs,
+		func(log *QeeqboxLog) string { return log.AuthID },
+		func(log *QeeqboxLog, id uint) { log.ID = id })
+}
+
+// DeleteByContainerID 删除容器相关的qeeqbox日志
+func (r *MySQLQeeqboxLogRepo) DeleteByContainerID(containerID string) error {
+	return r.DB.Where("container_id = ?", containerID).Delete(&QeeqboxLog{}).Error
+}
+
+// GetStatistics 按协议统计qeeqbox日志
+func (r *MySQLQeeqboxLogRepo) GetStatistics() ([]QeeqboxStatistics, error) {
+	var stats []QeeqboxStatistics
+	action := columnOf(r.DB, "action")
+	query := r.DB.Table("qeeqbox_log").
+		Select("protocol, COUNT(*) as total_events, COUNT(DISTINCT source_ip) as unique_ips, " +
+			"SUM(CASE WHEN " + action + " = 'login' THEN 1 ELSE 0 END) as login_attempts, " +
+			"SUM(CASE WHEN " + action + " = 'login' AND status = 'success' THEN 1 ELSE 0 END) as login_success, " +
+			"MIN(event_time) as first_event, MAX(event_time) as last_event").
+		Group("protocol").
+		Order("total_events DESC")
+	err := findStatistics(query, &stats)
+	return stats, err
+}
+
+// GetTopAttackers 获取事件最多的前N个攻击者
+func (r *MySQLQeeqboxLogRepo) GetTopAttackers(limit int) ([]QeeqboxAttackerStatistics, error) {
+	var attackers []QeeqboxAttackerStatistics
+	action := columnOf(r.DB, "action")
+	query := r.DB.Table("qeeqbox_log").
+		Select("source_ip, COUNT(*) as total_events, COUNT(DISTINCT protocol) as protocols_used, " +
+			"SUM(CASE WHEN " + action + " = 'login' THEN 1 ELSE 0 END) as login_attempts, " +
+			"MIN(event_time) as first_seen, MAX(event_time) as last_seen").
+		Group("source_ip").
+		Order("total_events DESC").
+		Limit(limit)
+	err := findStatistics(query, &attackers)
+	return attackers, err
+}
+
+// GetTopUsernames 获取最常用的用户名
+func (r *MySQLQeeqboxLogRepo) GetTopUsernames(limit int) ([]map[string]interface{}, error) {
+	var results []map[string]interface{}
+	result := r.DB.Table("qeeqbox_log").
+		Select("username, COUNT(*) as count, COUNT(DISTINCT source_ip) as unique_ips").
+		Where("username IS NOT NULL AND username != ''").
+		Group("username").
+		Order("count DESC").
+		Limit(limit).
+		Find(&results)
+	return results, result.Error
+}
+
+// GetTopPasswords 获取最常用的密码
+func (r *MySQLQeeqboxLogRepo) GetTopPasswords(limit int) ([]map[string]interface{}, error) {
+	var results []map[string]interface{}
+	result := r.DB.Table("qeeqbox_log").
+		Select("password, COUNT(*) as count, COUNT(DISTINCT source_ip) as unique_ips").
+		Where("password IS NOT NULL AND password != ''").
+		Group("password").
+		Order("count DESC").
+		Limit(limit).
+		Find(&results)
+	return results, result.Error
+}
+
+// -------------------- syslog消息仓库 --------------------
+
+// MySQLSyslogMessageRepo syslog消息MySQL仓库
+type MySQLSyslogMessageRepo struct {
+	DB *gorm.DB
+}
+
+// NewSyslogMessageRepo 创建syslog消息仓库
+func NewSyslogMessageRepo(db *gorm.DB) SyslogMessageRepository {
+	return &MySQLSyslogMessageRepo{DB: db}
+}
+
+// Search 按组合条件查询syslog消息
+func (r *MySQLSyslogMessageRepo) Search(filter SyslogMessageFilter) ([]SyslogMessage, error) {
+	query := r.DB.Model(&SyslogMessage{})
+	if filter.SensorID != "" {
+		query = query.Where("sensor_id = ?", filter.SensorID)
+	}
+	if filter.SourceIP != "" {
+		query = query.Where("source_ip = ?", filter.SourceIP)
+	}
+	if filter.AppName != "" {
+		query = query.Where("app_name = ?", filter.AppName)
+	}
+	if filter.Parser != "" {
+		query = query.Where("parser = ?", filter.Parser)
+	}
+	if filter.StartTime != nil {
+		query = query.Where("event_time >= ?", *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		query = query.Where("event_time <= ?", *filter.EndTime)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var messages []SyslogMessage
+	result := query.Order("event_time DESC").Find(&messages)
+	return messages, result.Error
+}
+
+// CreateBatch 批量保存syslog消息
+func (r *MySQLSyslogMessageRepo) CreateBatch(messages []SyslogMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	now := time.Now()
+	for i := range messages {
+		messages[i].CreatedAt = now
+	}
+	return r.DB.CreateInBatches(messages, 100).Error
+}
+
+// GetSensorStatistics 按传感器统计syslog消息
+func (r *MySQLSyslogMessageRepo) GetSensorStatistics() ([]SyslogSensorStatistics, error) {
+	var stats []SyslogSensorStatistics
+	query := r.DB.Table("syslog_message").
+		Select("sensor_id, COUNT(*) as total_messages, COUNT(DISTINCT source_ip) as source_ips, " +
+			"MIN(event_time) as first_seen, MAX(event_time) as last_seen").
+		Group("sensor_id").
+		Order("last_seen DESC")
+	err := findStatistics(query, &stats)
+	return stats, err
+}
+
+// -------------------- 攻击事件仓库 --------------------
+
+// MySQLAttackEventRepo 攻击事件MySQL仓库
+type MySQLAttackEventRepo struct {
+	DB *gorm.DB
+}
+
+// NewAttackEventRepo 创建攻击事件仓库
+func NewAttackEventRepo(db *gorm.DB) AttackEventRepository {
+	return &MySQLAttackEventRepo{DB: db}
+}
+
+// Create 保存攻击事件
+func (r *MySQLAttackEventRepo) Create(event *AttackEvent) error {
+	event.CreatedAt = time.Now()
+	return r.DB.Create(event).Error
+}
+
+// CreateBatch 批量创建攻击事件，创建后回填事件ID
+func (r *MySQLAttackEventRepo) CreateBatch(events []AttackEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	now := time.Now()
+	for i := range events {
+		events[i].CreatedAt = now
+	}
+	return r.DB.CreateInBatches(events, 100).Error
+}
+
+// GetByID 根据ID获取攻击事件
+func (r *MySQLAttackEventRepo) GetByID(id uint) (*AttackEvent, error) {
+	var event AttackEvent
+	result := r.DB.First(&event, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &event, nil
+}
+
+// filtered 按查询条件构造攻击事件查询，不包含分页
+func (r *MySQLAttackEventRepo) filtered(filter AttackEventFilter) *gorm.DB {
+	query := r.DB.Model(&AttackEvent{})
+	if filter.SourceIP != "" {
+		query = query.Where("source_ip = ?", filter.SourceIP)
+	}
+	if filter.Protocol != "" {
+		query = query.Where("protocol = ?", filter.Protocol)
+	}
+	if filter.AttackType != "" {
+		query = query.Where("attack_type = ?", filter.AttackType)
+	}
+	if filter.Severity != "" {
+		query = query.Where("severity = ?", filter.Severity)
+	}
+	if filter.SessionID != "" {
+		query = query.Where("session_id = ?", filter.SessionID)
+	}
+	if filter.AttackSessionID != 0 {
+		query = query.Where("attack_session_id = ?", filter.AttackSessionID)
+	}
+	if filter.StartTime != nil {
+		query = query.Where(columnOf(r.DB, "timestamp")+" >= ?", *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		query = query.Where(columnOf(r.DB, "timestamp")+" <= ?", *filter.EndTime)
+	}
+	return query
+}
+
+// Search 按组合条件查询攻击事件，结果按时间倒序
+func (r *MySQLAttackEventRepo) Search(filter AttackEventFilter) ([]AttackEvent, error) {
+	query := r.filtered(filter)
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var events []AttackEvent
+	result := query.Order(columnOf(r.DB, "timestamp") + " DESC").Order("id DESC").Find(&events)
+	return events, result.Error
+}
+
+// Query 按查询条件分页获取攻击事件
+func (r *MySQLAttackEventRepo) Query(spec QuerySpec) (*Page[AttackEvent], error) {
+	return findPage[AttackEvent](r.DB, spec)
+}
+
+// Count 统计符合条件的攻击事件数量
+func (r *MySQLAttackEventRepo) Count(filter AttackEventFilter) (int64, error) {
+	var count int64
+	result := r.filtered(filter).Count(&count)
+	return count, result.Error
+}
+
+// CountByAttackType 按攻击类型统计事件数量
+func (r *MySQLAttackEventRepo) CountByAttackType() ([]AttackEventCount, error) {
+	return r.groupCount("attack_type", 0)
+}
+
+// CountBySeverity 按严重程度统计事件数量
+func (r *MySQLAttackEventRepo) CountBySeverity() ([]AttackEventCount, error) {
+	return r.groupCount("severity", 0)
+}
+
+// GetTopAttackers 获取事件数量最多的前N个攻击者IP
+func (r *MySQLAttackEventRepo) GetTopAttackers(limit int) ([]AttackEventCount, error) {
+	return r.groupCount("source_ip", limit)
+}
+
+// groupCount 按指定列分组统计事件数量，limit为0时不限制
+func (r *MySQLAttackEventRepo) groupCount(column string, limit int) ([]AttackEventCount, error) {
+	column = columnOf(r.DB, column)
+	query := r.DB.Model(&AttackEvent{}).
+		Select(column + " as " + columnOf(r.DB, "value") + ", COUNT(*) as count").
+		Group(column).
+		Order("count DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var counts []AttackEventCount
+	result := query.Scan(&counts)
+	return counts, result.Error
+}
+
+// -------------------- 攻击会话仓库 --------------------
+
+// MySQLAttackSessionRepo 攻击会话MySQL仓库
+type MySQLAttackSessionRepo struct {
+	DB *gorm.DB
+}
+
+// NewAttackSessionRepo 创建攻击会话仓库
+func NewAttackSessionRepo(db *gorm.DB) AttackSessionRepository {
+	return &MySQLAttackSessionRepo{DB: db}
+}
+
+// Create 创建攻击会话
+func (r *MySQLAttackSessionRepo) Create(session *AttackSession) error {
+	now := time.Now()
+	session.CreatedAt = now
+	session.UpdatedAt = now
+	return r.DB.Create(session).Error
+}
+
+// Update 更新攻击会话
+func (r *MySQLAttackSessionRepo) Update(session *AttackSession) error {
+	session.UpdatedAt = time.Now()
+	return r.DB.Save(session).Error
+}
+
+// GetByID 根据ID获取攻击会话
+func (r *MySQLAttackSessionRepo) GetByID(id uint) (*AttackSession, error) {
+	var session AttackSession
+	result := r.DB.First(&session, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &session, nil
+}
+
+// GetOpenBySessionID 获取会话标识对应的未结束会话，不存在时返回nil
+func (r *MySQLAttackSessionRepo) GetOpenBySessionID(sessionID string) (*AttackSession, error) {
+	return r.findOne(r.DB.Where("session_id = ? AND end_time IS NULL", sessionID))
+}
+
+// GetLatestBySessionID 获取会话标识对应的最近一个会话，不存在时返回nil
+func (r *MySQLAttackSessionRepo) GetLatestBySessionID(sessionID string) (*AttackSession, error) {
+	return r.findOne(r.DB.Where("session_id = ?", sessionID))
+}
+
+// findOne 获取查询条件下开始时间最晚的会话，不存在时返回nil
+func (r *MySQLAttackSessionRepo) findOne(query *gorm.DB) (*AttackSession, error) {
+	var session AttackSession
+	result := query.Order("start_time DESC").Limit(1).Find(&session)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+// List 按开始时间倒序获取攻击会话，limit为0时不限制
+func (r *MySQLAttackSessionRepo) List(limit int) ([]AttackSession, error) {
+	query := r.DB.Order("start_time DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var sessions []AttackSession
+	result := query.Find(&sessions)
+	return sessions, result.Error
+}
+
+// Count 统计攻击会话数量
+func (r *MySQLAttackSessionRepo) Count() (int64, error) {
+	var count int64
+	result := r.DB.Model(&AttackSession{}).Count(&count)
+	return count, result.Error
+}
+
+// CloseIdle 结束最后一个事件早于idleBefore的会话，结束时间取最后一个事件的时间
+func (r *MySQLAttackSessionRepo) CloseIdle(idleBefore time.Time) (int64, error) {
+	result := r.DB.Model(&AttackSession{}).
+		Where("end_time IS NULL AND last_event_time < ?", idleBefore).
+		Updates(map[string]interface{}{
+			"EndTime":   gorm.Expr("last_event_time"),
+			"UpdatedAt": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// -------------------- 攻击事件时间线仓库 --------------------
+
+// MySQLAttackTimelineRepo 攻击事件时间线MySQL仓库
+type MySQLAttackTimelineRepo struct {
+	DB *gorm.DB
+}
+
+// NewAttackTimelineRepo 创建攻击事件时间线仓库
+func NewAttackTimelineRepo(db *gorm.DB) AttackTimelineRepository {
+	return &MySQLAttackTimelineRepo{DB: db}
+}
+
+// Search 按组合条件查询时间线，结果按事件时间倒序，After不为空时从游标之后继续
+func (r *MySQLAttackTimelineRepo) Search(filter AttackTimelineFilter) ([]AttackTimelineEvent, error) {
+	query := r.DB.Model(&AttackTimelineEvent{})
+	if len(filter.Sources) > 0 {
+		query = query.Where("source IN ?", filter.Sources)
+	}
+	if filter.SourceIP != "" {
+		query = query.Where("source_ip = ?", filter.SourceIP)
+	}
+	if filter.DestinationIP != "" {
+		query = query.Where("destination_ip = ?", filter.DestinationIP)
+	}
+	if filter.Protocol != "" {
+		query = query.Where("protocol = ?", filter.Protocol)
+	}
+	if filter.Action != "" {
+		query = query.Where(columnOf(r.DB, "action")+" = ?", filter.Action)
+	}
+	if filter.Severity != "" {
+		query = query.Where("severity = ?", filter.Severity)
+	}
+	if filter.Username != "" {
+		query = query.Where("username = ?", filter.Username)
+	}
+	if filter.SessionID != "" {
+		query = query.Where("session_id = ?", filter.SessionID)
+	}
+	if filter.ContainerID != "" {
+		query = query.Where("container_id = ?", filter.ContainerID)
+	}
+	if filter.StartTime != nil {
+		query = query.Where("event_time >= ?", *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		query = query.Where("event_time <= ?", *filter.EndTime)
+	}
+	if filter.After != nil {
+		query = query.Where("event_time < ? OR (event_time = ? AND id < ?)",
+			filter.After.EventTime, filter.After.EventTime, filter.After.ID)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var events []AttackTimelineEvent
+	result := query.Order("event_time DESC").Order("id DESC").Find(&events)
+	return events, result.Error
+}
+
+// CreateBatch 批量保存时间线事件，auth_id已存在的事件会被跳过，返回实际插入的条数
+func (r *MySQLAttackTimelineRepo) CreateBatch(events []AttackTimelineEvent) (int64, error) {
+	now := time.Now()
+	for i := range events {
+		events[i].CreatedAt = now
+	}
+	return createSkippingDuplicates(r.DB, events,
+		func(event *AttackTimelineEvent) string { return event.AuthID },
+		func(event *AttackTimelineEvent, id uint) { event.ID = id })
+}
+
+// -------------------- 蜜签仓库 --------------------
+
+// MySQLHoneyTokenRepo 蜜签MySQL仓库
+type MySQLHoneyTokenRepo struct {
+	DB *gorm.DB
+}
+
+// NewHoneyTokenRepo 创建蜜签仓库
+func NewHoneyTokenRepo(db *gorm.DB) HoneyTokenRepository {
+	return &MySQLHoneyTokenRepo{DB: db}
+}
+
+// Create 创建蜜签
+func (r *MySQLHoneyTokenRepo) Create(token *HoneyToken) error {
+	now := time.Now()
+	token.CreateTime = now
+	token.UpdateTime = now
+	return r.DB.Create(token).Error
+}
+
+// Update 更新蜜签
+func (r *MySQLHoneyTokenRepo) Update(token *HoneyToken) error {
+	token.UpdateTime = time.Now()
+	return r.DB.Save(token).Error
+}
+
+// Delete 删除蜜签
+func (r *MySQLHoneyTokenRepo) Delete(id uint) error {
+	return r.DB.Delete(&HoneyToken{}, id).Error
+}
+
+// GetByID 根据ID获取蜜签，不存在时返回nil
+func (r *MySQLHoneyTokenRepo) GetByID(id uint) (*HoneyToken, error) {
+	var token HoneyToken
+	result := r.DB.Where("id = ?", id).Limit(1).Find(&token)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &token, nil
+}
+
+// List 按ID顺序获取所有蜜签
+func (r *MySQLHoneyTokenRepo) List() ([]HoneyToken, error) {
+	var tokens []HoneyToken
+	result := r.DB.Order("id").Find(&tokens)
+	return tokens, result.Error
+}
+
+// Query 按查询条件分页获取蜜签
+func (r *MySQLHoneyTokenRepo) Query(spec QuerySpec) (*Page[HoneyToken], error) {
+	return findPage[HoneyToken](r.DB, spec)
+}
+
+// IncrementTriggerCount 原子地增加蜜签触发次数
+func (r *MySQLHoneyTokenRepo) IncrementTriggerCount(id uint) error {
+	return r.DB.Model(&HoneyToken{}).Where("id = ?", id).
+		UpdateColumn("TriggerCount", gorm.Expr("trigger_count + 1")).Error
+}
+
+// Count 统计蜜签总数
+func (r *MySQLHoneyTokenRepo) Count() (int64, error) {
+	var count int64
+	result := r.DB.Model(&HoneyToken{}).Count(&count)
+	return count, result.Error
+}
+
+// CountActive 统计启用的蜜签数量
+func (r *MySQLHoneyTokenRepo) CountActive() (int64, error) {
+	var count int64
+	result := r.DB.Model(&HoneyToken{}).Where("is_active = ?", true).Count(&count)
+	return count, result.Error
+}
+
+// CountByType 按类型统计蜜签数量
+func (r *MySQLHoneyTokenRepo) CountByType() ([]HoneyTokenTypeCount, error) {
+	var counts []HoneyTokenTypeCount
+	// TYPE和COUNT在达梦中是保留字，列名和别名都要加引号
+	tokenType := columnOf(r.DB, "type")
+	result := r.DB.Model(&HoneyToken{}).
+		Select(tokenType + ", COUNT(*) as " + columnOf(r.DB, "count")).
+		Group(tokenType).
+		Find(&counts)
+	return counts, result.Error
+}
+
+// -------------------- 蜜签触发记录仓库 --------------------
+
+// MySQLHoneyTokenTriggerRepo 蜜签触发记录MySQL仓库
+type MySQLHoneyTokenTriggerRepo struct {
+	DB *gorm.DB
+}
+
+// NewHoneyTokenTriggerRepo 创建蜜签触发记录仓库
+func NewHoneyTokenTriggerRepo(db *gorm.DB) HoneyTokenTriggerRepository {
+	return &MySQLHoneyTokenTriggerRepo{DB: db}
+}
+
+// Create 保存蜜签触发记录
+func (r *MySQLHoneyTokenTriggerRepo) Create(trigger *HoneyTokenTrigger) error {
+	return r.DB.Create(trigger).Error
+}
+
+// Record 在同一事务中累加蜜签的触发次数并保存触发记录
+func (r *MySQLHoneyTokenTriggerRepo) Record(trigger *HoneyTokenTrigger) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&HoneyToken{}).Where("id = ?", trigger.TokenID).
+			UpdateColumn("TriggerCount", gorm.Expr("trigger_count + 1")).Error; err != nil {
+			return err
+		}
+		return tx.Create(trigger).Error
+	})
+}
+
+// Search 按条件查询触发记录，结果按触发时间倒序
+func (r *MySQLHoneyTokenTriggerRepo) Search(filter HoneyTokenTriggerFilter) ([]HoneyTokenTrigger, error) {
+	query := r.DB.Model(&HoneyTokenTrigger{})
+	if filter.TokenID != 0 {
+		query = query.Where("token_id = ?", filter.TokenID)
+	}
+	if filter.SourceIP != "" {
+		query = query.Where("source_ip = ?", filter.SourceIP)
+	}
+	if filter.StartTime != nil {
+		query = query.Where("trigger_time >= ?", *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		query = query.Where("trigger_time <= ?", *filter.EndTime)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var triggers []HoneyTokenTrigger
+	result := query.Order("trigger_time DESC").Order("id DESC").Find(&triggers)
+	return triggers, result.Error
+}
+
+// Count 统计触发记录总数
+func (r *MySQLHoneyTokenTriggerRepo) Count() (int64, error) {
+	var count int64
+	result := r.DB.Model(&HoneyTokenTrigger{}).Count(&count)
+	return count, result.Error
+}
+
+// -------------------- 日志保留策略仓库 --------------------
+
+// MySQLRetentionRepo 日志保留策略和归档记录MySQL仓库
+type MySQLRetentionRepo struct {
+	DB *gorm.DB
+}
+
+// NewRetentionRepo 创建日志保留策略仓库
+func NewRetentionRepo(db *gorm.DB) RetentionRepository {
+	return &MySQLRetentionRepo{DB: db}
+}
+
+// ListPolicies 获取所有保留策略
+func (r *MySQLRetentionRepo) ListPolicies() ([]RetentionPolicy, error) {
+	var policies []RetentionPolicy
+	result := r.DB.Order("log_table").Find(&policies)
+	return policies, result.Error
+}
+
+// GetPolicy 获取指定日志表的保留策略
+func (r *MySQLRetentionRepo) GetPolicy(table string) (*RetentionPolicy, error) {
+	var policy RetentionPolicy
+	result := r.DB.Where("log_table = ?", table).First(&policy)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &policy, nil
+}
+
+// SavePolicy 保存保留策略，同一日志表已有策略时覆盖
+func (r *MySQLRetentionRepo) SavePolicy(policy *RetentionPolicy) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var existing RetentionPolicy
+		err := tx.Where("log_table = ?", policy.LogTable).First(&existing).Error
+		switch {
+		case err == nil:
+			policy.ID = existing.ID
+			return tx.Save(policy).Error
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			policy.ID = 0
+			return tx.Create(policy).Error
+		default:
+			return err
+		}
+	})
+}
+
+// CreateArchive 保存归档记录
+func (r *MySQLRetentionRepo) CreateArchive(archive *RetentionArchive) error {
+	return r.DB.Create(archive).Error
+}
+
+// GetArchive 根据ID获取归档记录
+func (r *MySQLRetentionRepo) GetArchive(id uint) (*RetentionArchive, error) {
+	var archive RetentionArchive
+	result := r.DB.First(&archive, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &archive, nil
+}
+
+// ListArchives 按归档时间倒序获取归档记录，table为空时返回所有日志表的归档
+func (r *MySQLRetentionRepo) ListArchives(table string) ([]RetentionArchive, error) {
+	query := r.DB.Model(&RetentionArchive{})
+	if table != "" {
+		query = query.Where("log_table = ?", table)
+	}
+	var archives []RetentionArchive
+	result := query.Order("created_at DESC").Order("id DESC").Find(&archives)
+	return archives, result.Error
+}
+
+// MarkArchiveRestored 记录归档的恢复时间和写回的行数
+func (r *MySQLRetentionRepo) MarkArchiveRestored(id uint, rows int64, restoredAt time.Time) error {
+	return r.DB.Model(&RetentionArchive{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"RestoredAt":   restoredAt,
+		"RestoredRows": rows,
+	}).Error
+}
+
+// -------------------- 内置SSH蜜罐认证尝试仓库 --------------------
+
+// MySQLSSHAuthAttemptRepo 内置SSH蜜罐认证尝试MySQL仓库
+type MySQLSSHAuthAttemptRepo struct {
+	DB *gorm.DB
+}
+
+// NewSSHAuthAttemptRepo 创建内置SSH蜜罐认证尝试仓库
+func NewSSHAuthAttemptRepo(db *gorm.DB) SSHAuthAttemptRepository {
+	return &MySQLSSHAuthAttemptRepo{DB: db}
+}
+
+// Query 按查询条件分页获取认证尝试
+func (r *MySQLSSHAuthAttemptRepo) Query(spec QuerySpec) (*Page[SSHAuthAttempt], error) {
+	return findPage[SSHAuthAttempt](r.DB, spec)
+}
+
+// GetBySessionID 按时间顺序获取一个连接的认证尝试
+func (r *MySQLSSHAuthAttemptRepo) GetBySessionID(sessionID string) ([]SSHAuthAttempt, error) {
+	var attempts []SSHAuthAttempt
+	result := r.DB.Where("session_id = ?", sessionID).Order("id").Find(&attempts)
+	return attempts, result.Error
+}
+
+// Create 保存认证尝试
+func (r *MySQLSSHAuthAttemptRepo) Create(attempt *SSHAuthAttempt) error {
+	return r.DB.Create(attempt).Error
+}
+
+// -------------------- 系统设置仓库 --------------------
+
+// MySQLSystemSettingRepo 系统设置MySQL仓库
+type MySQLSystemSettingRepo struct {
+	DB *gorm.DB
+}
+
+// NewSystemSettingRepo 创建系统设置仓库
+func NewSystemSettingRepo(db *gorm.DB) SystemSettingRepository {
+	return &MySQLSystemSettingRepo{DB: db}
+}
+
+// Get 根据名称获取设置，不存在时返回nil
+func (r *MySQLSystemSettingRepo) Get(name string) (*SystemSetting, error) {
+	var setting SystemSetting
+	result := r.DB.Where(&SystemSetting{Name: name}).Limit(1).Find(&setting)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &setting, nil
+}
+
+// Set 保存设置，已存在时覆盖
+func (r *MySQLSystemSettingRepo) Set(name, value string) error {
+	setting := SystemSetting{Name: name, Value: value, UpdatedAt: time.Now()}
+	result := r.DB.Model(&SystemSetting{}).Where(&SystemSetting{Name: name}).
+		Updates(map[string]interface{}{"Value": value, "UpdatedAt": setting.UpdatedAt})
+	if result.Error != nil || result.RowsAffected > 0 {
+		return result.Error
+	}
+	return r.DB.Create(&setting).Error
+}
@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openSQLiteMemory 打开内存SQLite并创建指定模型的表
+func openSQLiteMemory(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("打开SQLite失败: %v", err)
+	}
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("建表失败: %v", err)
+	}
+	return db
+}
+
+// TestRecordSightingTransaction 测试样本和捕获记录在同一事务中保存，捕获记录保存失败时样本也不会写入
+func TestRecordSightingTransaction(t *testing.T) {
+	db := openSQLiteMemory(t, &MalwareSample{}, &MalwareSighting{})
+	repo := NewMalwareSampleRepo(db)
+	now := time.Now()
+
+	sample := &MalwareSample{SHA256: "a1", SHA1: "b1", MD5: "c1", QuarantinePath: "q/a1", SightingCount: 1, FirstSeen: now, LastSeen: now}
+	if err := repo.RecordSighting(sample, &MalwareSighting{SHA256: "a1", CowrieLogID: 1, EventTime: now}); err != nil {
+		t.Fatalf("保存样本失败: %v", err)
+	}
+	sightings, err := repo.GetSightingsBySampleID(sample.ID)
+	if err != nil || len(sightings) != 1 {
+		t.Fatalf("捕获记录应关联到新样本: %+v err=%v", sightings, err)
+	}
+
+	// 同一个Cowrie事件的捕获记录违反唯一索引，样本的更新和新样本都应回滚
+	sample.SightingCount++
+	if err := repo.RecordSighting(sample, &MalwareSighting{SHA256: "a1", CowrieLogID: 1, EventTime: now}); err == nil {
+		t.Fatal("重复的捕获记录应保存失败")
+	}
+	other := &MalwareSample{SHA256: "a2", SHA1: "b2", MD5: "c2", QuarantinePath: "q/a2", SightingCount: 1, FirstSeen: now, LastSeen: now}
+	if err := repo.RecordSighting(other, &MalwareSighting{SHA256: "a2", CowrieLogID: 1, EventTime: now}); err == nil {
+		t.Fatal("重复的捕获记录应保存失败")
+	}
+
+	if stored, err := repo.GetBySHA256("a1"); err != nil || stored.SightingCount != 1 {
+		t.Errorf("失败的事务不应更新样本: %+v err=%v", stored, err)
+	}
+	if stored, err := repo.GetBySHA256("a2"); err != nil || stored != nil {
+		t.Errorf("失败的事务不应创建样本: %+v err=%v", stored, err)
+	}
+}
+
+// TestRecordHoneyTokenTriggerTransaction 测试触发次数和触发记录在同一事务中保存，记录保存失败时次数回滚
+func TestRecordHoneyTokenTriggerTransaction(t *testing.T) {
+	db := openSQLiteMemory(t, &HoneyToken{}, &HoneyTokenTrigger{})
+	tokens := NewHoneyTokenRepo(db)
+	triggers := NewHoneyTokenTriggerRepo(db)
+
+	token := &HoneyToken{Name: "管理员凭证", Type: "credential", Content: "admin:123456", IsActive: true}
+	if err := tokens.Create(token); err != nil {
+		t.Fatalf("创建蜜签失败: %v", err)
+	}
+	trigger := &HoneyTokenTrigger{TokenID: token.ID, TokenName: token.Name, SourceIP: "1.2.3.4", TriggerTime: time.Now(), Action: "login"}
+	if err := triggers.Record(trigger); err != nil {
+		t.Fatalf("保存触发记录失败: %v", err)
+	}
+
+	// 主键重复的触发记录保存失败，触发次数的累加也应回滚
+	duplicate := *trigger
+	if err := triggers.Record(&duplicate); err == nil {
+		t.Fatal("主键重复的触发记录应保存失败")
+	}
+	if stored, err := tokens.GetByID(token.ID); err != nil || stored.TriggerCount != 1 {
+		t.Errorf("失败的事务不应累加触发次数: %+v err=%v", stored, err)
+	}
+	if count, err := triggers.Count(); err != nil || count != 1 {
+		t.Errorf("触发记录数量错误: %d err=%v", count, err)
+	}
+}
+
+// TestSystemSettingSet 测试设置不存在时新增，已存在时覆盖
+func TestSystemSettingSet(t *testing.T) {
+	repo := NewSystemSettingRepo(openSQLiteMemory(t, &SystemSetting{}))
+
+	if setting, err := repo.Get("honey_tokens_seeded"); err != nil || setting != nil {
+		t.Fatalf("不存在的设置应返回nil: %+v %v", setting, err)
+	}
+	for _, value := range []string{"first", "second"} {
+		if err := repo.Set("honey_tokens_seeded", value); err != nil {
+			t.Fatalf("保存设置失败: %v", err)
+		}
+	}
+	setting, err := repo.Get("honey_tokens_seeded")
+	if err != nil || setting == nil || setting.Value != "second" {
+		t.Errorf("设置应被覆盖: %+v %v", setting, err)
+	}
+}
+
+// TestHoneyTokenCountByType 测试按类型统计蜜签数量
+func TestHoneyTokenCountByType(t *testing.T) {
+	repo := NewHoneyTokenRepo(openSQLiteMemory(t, &HoneyToken{}))
+	for _, tokenType := range []string{"credential", "credential", "file"} {
+		if err := repo.Create(&HoneyToken{Name: tokenType, Type: tokenType, Content: tokenType}); err != nil {
+			t.Fatalf("创建蜜签失败: %v", err)
+		}
+	}
+
+	counts, err := repo.CountByType()
+	if err != nil {
+		t.Fatalf("按类型统计失败: %v", err)
+	}
+	byType := make(map[string]int64)
+	for _, count := range counts {
+		byType[count.Type] = count.Count
+	}
+	if len(byType) != 2 || byType["credential"] != 2 || byType["file"] != 1 {
+		t.Errorf("按类型统计错误: %+v", counts)
+	}
+}
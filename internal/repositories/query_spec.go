@@ -0,0 +1,522 @@
+package repositories
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	// DefaultPageSize 未指定每页数量时的默认值
+	DefaultPageSize = 100
+	// MaxPageSize 每页数量上限
+	MaxPageSize = 1000
+)
+
+// 过滤操作符
+const (
+	FilterEq   = "eq"
+	FilterNe   = "ne"
+	FilterGt   = "gt"
+	FilterGte  = "gte"
+	FilterLt   = "lt"
+	FilterLte  = "lte"
+	FilterIn   = "in"
+	FilterLike = "like"
+)
+
+// ErrInvalidQuerySpec 查询条件中的字段、操作符、值或游标不合法
+var ErrInvalidQuerySpec = errors.New("无效的查询条件")
+
+// QueryFilter 单个字段的过滤条件
+type QueryFilter struct {
+	Field  string   // 字段名，与JSON字段名一致，如 source_ip
+	Op     string   // 操作符，为空时等同于eq
+	Values []string // 值按字段类型转换，时间使用RFC3339格式；in可以有多个值，其他操作符只使用第一个
+}
+
+// QuerySpec 列表查询条件，所有列表接口共用
+// 分页支持偏移量和游标两种方式，设置Cursor时忽略Offset；游标分页按(排序字段, 主键)定位，
+// 排序字段应选择不为空的列，如时间列
+type QuerySpec struct {
+	Filters   []QueryFilter // 多个条件按AND组合
+	SortField string        // 排序字段，为空时按主键排序
+	SortDesc  bool          // 是否倒序
+	Offset    int
+	Limit     int    // 每页数量，0使用DefaultPageSize，超过MaxPageSize时按MaxPageSize
+	Cursor    string // 上一页返回的NextCursor
+}
+
+// Page 一页查询结果，Total为满足过滤条件的总数，NextCursor为空表示没有下一页
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int64  `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// pageCursor 游标内容，记录上一页最后一条记录的排序字段值和主键
+type pageCursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+var querySpecSchemaCache sync.Map
+
+// pageLimit 返回实际使用的每页数量
+func (spec QuerySpec) pageLimit() int {
+	switch {
+	case spec.Limit <= 0:
+		return DefaultPageSize
+	case spec.Limit > MaxPageSize:
+		return MaxPageSize
+	default:
+		return spec.Limit
+	}
+}
+
+// querySchema 解析模型结构，namer为nil时使用默认命名策略
+func querySchema(model interface{}, namer schema.Namer) (*schema.Schema, error) {
+	if namer == nil {
+		namer = schema.NamingStrategy{}
+	}
+	return schema.Parse(model, &querySpecSchemaCache, namer)
+}
+
+// lookupQueryField 按字段名查找模型字段，不区分大小写，达梦的列名为大写
+func lookupQueryField(s *schema.Schema, name string) (*schema.Field, error) {
+	for _, field := range s.Fields {
+		if field.DBName != "" && strings.EqualFold(field.DBName, name) {
+			return field, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: 不支持的字段%s", ErrInvalidQuerySpec, name)
+}
+
+// sortFields 返回排序字段和主键字段
+func sortFields(s *schema.Schema, spec QuerySpec) (sortField, pk *schema.Field, err error) {
+	pk = s.PrioritizedPrimaryField
+	if pk == nil {
+		return nil, nil, fmt.Errorf("%w: %s没有主键", ErrInvalidQuerySpec, s.Table)
+	}
+	sortField = pk
+	if spec.SortField != "" {
+		if sortField, err = lookupQueryField(s, spec.SortField); err != nil {
+			return nil, nil, err
+		}
+	}
+	return sortField, pk, nil
+}
+
+// parseQueryValue 把字符串形式的值转换为字段类型
+func parseQueryValue(field *schema.Field, value string) (interface{}, error) {
+	fieldType := field.IndirectFieldType
+	if fieldType == reflect.TypeOf(time.Time{}) {
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"} {
+			if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s的时间格式错误: %s", ErrInvalidQuerySpec, field.DBName, value)
+	}
+
+	var (
+		parsed interface{}
+		err    error
+	)
+	switch fieldType.Kind() {
+	case reflect.Bool:
+		parsed, err = strconv.ParseBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err = strconv.ParseInt(value, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err = strconv.ParseUint(value, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		parsed, err = strconv.ParseFloat(value, 64)
+	default:
+		parsed = value
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s的值格式错误: %s", ErrInvalidQuerySpec, field.DBName, value)
+	}
+	return parsed, nil
+}
+
+// formatQueryValue 把字段值转换为字符串，与parseQueryValue对应
+func formatQueryValue(value interface{}) string {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return ""
+		}
+		rv = rv.Elem()
+	}
+	if t, ok := rv.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(rv.Interface())
+}
+
+// filterExpression 把过滤条件转换为GORM表达式，列名由GORM按方言加引号
+func filterExpression(s *schema.Schema, filter QueryFilter) (clause.Expression, error) {
+	field, err := lookupQueryField(s, filter.Field)
+	if err != nil {
+		return nil, err
+	}
+	if len(filter.Values) == 0 {
+		return nil, fmt.Errorf("%w: %s缺少过滤值", ErrInvalidQuerySpec, filter.Field)
+	}
+
+	values := make([]interface{}, 0, len(filter.Values))
+	for _, raw := range filter.Values {
+		if filter.Op == FilterLike {
+			values = append(values, raw)
+			continue
+		}
+		value, err := parseQueryValue(field, raw)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	column := clause.Column{Name: field.DBName}
+	switch filter.Op {
+	case "", FilterEq:
+		return clause.Eq{Column: column, Value: values[0]}, nil
+	case FilterNe:
+		return clause.Neq{Column: column, Value: values[0]}, nil
+	case FilterGt:
+		return clause.Gt{Column: column, Value: values[0]}, nil
+	case FilterGte:
+		return clause.Gte{Column: column, Value: values[0]}, nil
+	case FilterLt:
+		return clause.Lt{Column: column, Value: values[0]}, nil
+	case FilterLte:
+		return clause.Lte{Column: column, Value: values[0]}, nil
+	case FilterIn:
+		return clause.IN{Column: column, Values: values}, nil
+	case FilterLike:
+		// 值中的%和_按字面匹配，与全文搜索使用相同的转义方式
+		pattern := "%" + likeEscaper.Replace(filter.Values[0]) + "%"
+		return clause.Expr{SQL: "? LIKE ? ESCAPE '!'", Vars: []interface{}{column, pattern}}, nil
+	default:
+		return nil, fmt.Errorf("%w: 不支持的操作符%s", ErrInvalidQuerySpec, filter.Op)
+	}
+}
+
+// encodePageCursor 根据最后一条记录生成游标
+func encodePageCursor(ctx context.Context, sortField, pk *schema.Field, desc bool, last reflect.Value) string {
+	sortValue, _ := sortField.ValueOf(ctx, last)
+	id, _ := pk.ValueOf(ctx, last)
+	raw, _ := json.Marshal(pageCursor{
+		Sort:  sortField.DBName,
+		Desc:  desc,
+		Value: formatQueryValue(sortValue),
+		ID:    formatQueryValue(id),
+	})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodePageCursor 解析游标，排序方式必须与生成游标时一致
+func decodePageCursor(cursor string, sortField, pk *schema.Field, desc bool) (sortValue, id interface{}, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: 无效的游标", ErrInvalidQuerySpec)
+	}
+	var c pageCursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, nil, fmt.Errorf("%w: 无效的游标", ErrInvalidQuerySpec)
+	}
+	if c.Sort != sortField.DBName || c.Desc != desc {
+		return nil, nil, fmt.Errorf("%w: 游标与排序方式不一致", ErrInvalidQuerySpec)
+	}
+	if sortValue, err = parseQueryValue(sortField, c.Value); err != nil {
+		return nil, nil, err
+	}
+	if id, err = parseQueryValue(pk, c.ID); err != nil {
+		return nil, nil, err
+	}
+	return sortValue, id, nil
+}
+
+// findPage 按查询条件在数据库中查询一页模型记录
+func findPage[T any](db *gorm.DB, spec QuerySpec) (*Page[T], error) {
+	s, err := querySchema(new(T), db.NamingStrategy)
+	if err != nil {
+		return nil, err
+	}
+	sortField, pk, err := sortFields(s, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Model(new(T))
+	for _, filter := range spec.Filters {
+		expr, err := filterExpression(s, filter)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(expr)
+	}
+
+	page := &Page[T]{}
+	if err := query.Session(&gorm.Session{}).Count(&page.Total).Error; err != nil {
+		return nil, err
+	}
+
+	sortColumn, pkColumn := clause.Column{Name: sortField.DBName}, clause.Column{Name: pk.DBName}
+	if spec.Cursor != "" {
+		sortValue, id, err := decodePageCursor(spec.Cursor, sortField, pk, spec.SortDesc)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(keysetExpression(sortColumn, pkColumn, sortField == pk, spec.SortDesc, sortValue, id))
+	} else if spec.Offset > 0 {
+		query = query.Offset(spec.Offset)
+	}
+
+	limit := spec.pageLimit()
+	order := clause.OrderBy{Columns: []clause.OrderByColumn{{Column: sortColumn, Desc: spec.SortDesc}}}
+	if sortField != pk {
+		order.Columns = append(order.Columns, clause.OrderByColumn{Column: pkColumn, Desc: spec.SortDesc})
+	}
+	var items []T
+	if err := query.Clauses(order).Limit(limit + 1).Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	if len(items) > limit {
+		items = items[:limit]
+		page.NextCursor = encodePageCursor(db.Statement.Context, sortField, pk, spec.SortDesc, reflect.ValueOf(&items[limit-1]).Elem())
+	}
+	if items == nil {
+		items = []T{}
+	}
+	page.Items = items
+	return page, nil
+}
+
+// keysetExpression 游标分页条件: 排序字段在游标之后，或排序字段相同且主键在游标之后
+func keysetExpression(sortColumn, pkColumn clause.Column, sortByPK, desc bool, sortValue, id interface{}) clause.Expression {
+	after := func(column clause.Column, value interface{}) clause.Expression {
+		if desc {
+			return clause.Lt{Column: column, Value: value}
+		}
+		return clause.Gt{Column: column, Value: value}
+	}
+	if sortByPK {
+		return after(pkColumn, id)
+	}
+	return clause.Or(
+		after(sortColumn, sortValue),
+		clause.And(clause.Eq{Column: sortColumn, Value: sortValue}, after(pkColumn, id)),
+	)
+}
+
+// queryPage 按查询条件对内存中的记录分页，过滤、排序和游标的语义与findPage一致
+func queryPage[T any](items []T, spec QuerySpec) (*Page[T], error) {
+	s, err := querySchema(new(T), nil)
+	if err != nil {
+		return nil, err
+	}
+	sortField, pk, err := sortFields(s, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	type condition struct {
+		field  *schema.Field
+		op     string
+		values []interface{}
+		like   string
+	}
+	conditions := make([]condition, 0, len(spec.Filters))
+	for _, filter := range spec.Filters {
+		if _, err := filterExpression(s, filter); err != nil {
+			return nil, err
+		}
+		field, _ := lookupQueryField(s, filter.Field)
+		cond := condition{field: field, op: filter.Op}
+		if filter.Op == FilterLike {
+			cond.like = strings.ToLower(filter.Values[0])
+		} else {
+			for _, raw := range filter.Values {
+				value, _ := parseQueryValue(field, raw)
+				cond.values = append(cond.values, value)
+			}
+		}
+		conditions = append(conditions, cond)
+	}
+
+	ctx := context.Background()
+	valueOf := func(field *schema.Field, item *T) interface{} {
+		value, _ := field.ValueOf(ctx, reflect.ValueOf(item).Elem())
+		return value
+	}
+	match := func(item *T) bool {
+		for _, cond := range conditions {
+			value := valueOf(cond.field, item)
+			if cond.op == FilterLike {
+				if !strings.Contains(strings.ToLower(formatQueryValue(value)), cond.like) {
+					return false
+				}
+				continue
+			}
+			if cond.op == FilterIn {
+				found := false
+				for _, v := range cond.values {
+					if compareQueryValues(value, v) == 0 {
+						found = true
+						break
+					}
+				}
+				if !found {
+					return false
+				}
+				continue
+			}
+			cmp := compareQueryValues(value, cond.values[0])
+			ok := map[string]bool{
+				"": cmp == 0, FilterEq: cmp == 0, FilterNe: cmp != 0,
+				FilterGt: cmp > 0, FilterGte: cmp >= 0, FilterLt: cmp < 0, FilterLte: cmp <= 0,
+			}[cond.op]
+			if !ok {
+				return false
+			}
+		}
+		return true
+	}
+
+	matched := make([]T, 0, len(items))
+	for i := range items {
+		if match(&items[i]) {
+			matched = append(matched, items[i])
+		}
+	}
+	// before 判断a是否排在b之前
+	before := func(a, b *T) bool {
+		cmp := compareQueryValues(valueOf(sortField, a), valueOf(sortField, b))
+		if cmp == 0 {
+			cmp = compareQueryValues(valueOf(pk, a), valueOf(pk, b))
+		}
+		if spec.SortDesc {
+			return cmp > 0
+		}
+		return cmp < 0
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return before(&matched[i], &matched[j]) })
+
+	page := &Page[T]{Total: int64(len(matched))}
+	start := 0
+	if spec.Cursor != "" {
+		sortValue, id, err := decodePageCursor(spec.Cursor, sortField, pk, spec.SortDesc)
+		if err != nil {
+			return nil, err
+		}
+		start = sort.Search(len(matched), func(i int) bool {
+			cmp := compareQueryValues(valueOf(sortField, &matched[i]), sortValue)
+			if cmp == 0 {
+				cmp = compareQueryValues(valueOf(pk, &matched[i]), id)
+			}
+			if spec.SortDesc {
+				return cmp < 0
+			}
+			return cmp > 0
+		})
+	} else if spec.Offset > 0 {
+		start = spec.Offset
+	}
+	if start > len(matched) {
+		start = len(matched)
+	}
+
+	end := start + spec.pageLimit()
+	if end < len(matched) {
+		page.NextCursor = encodePageCursor(ctx, sortField, pk, spec.SortDesc, reflect.ValueOf(&matched[end-1]).Elem())
+	} else {
+		end = len(matched)
+	}
+	page.Items = matched[start:end]
+	return page, nil
+}
+
+// compareQueryValues 比较两个同类字段值，返回-1、0或1
+func compareQueryValues(a, b interface{}) int {
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	for av.Kind() == reflect.Ptr {
+		if av.IsNil() {
+			return -1
+		}
+		av = av.Elem()
+	}
+	for bv.Kind() == reflect.Ptr {
+		if bv.IsNil() {
+			return 1
+		}
+		bv = bv.Elem()
+	}
+
+	if at, ok := av.Interface().(time.Time); ok {
+		if bt, ok := bv.Interface().(time.Time); ok {
+			return at.Compare(bt)
+		}
+	}
+	switch av.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareOrdered(float64(av.Int()), toFloat(bv))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return compareOrdered(float64(av.Uint()), toFloat(bv))
+	case reflect.Float32, reflect.Float64:
+		return compareOrdered(av.Float(), toFloat(bv))
+	case reflect.Bool:
+		return compareOrdered(boolToFloat(av.Bool()), toFloat(bv))
+	default:
+		return strings.Compare(fmt.Sprint(av.Interface()), fmt.Sprint(bv.Interface()))
+	}
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Bool:
+		return boolToFloat(v.Bool())
+	}
+	return 0
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func compareOrdered(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+)
+
+// TestDamengQuerySpec 测试达梦后端的分页查询为保留字排序列加引号，并以主键作为第二排序列
+func TestDamengQuerySpec(t *testing.T) {
+	db, recorder := openDamengDryRun(t)
+
+	if _, err := NewAttackEventRepo(db).Query(QuerySpec{
+		Filters:   []QueryFilter{{Field: "source_ip", Values: []string{"1.2.3.4"}}},
+		SortField: "timestamp",
+		SortDesc:  true,
+	}); err != nil {
+		t.Fatalf("生成分页查询SQL失败: %v", err)
+	}
+
+	assertSQLContains(t, recorder, `ORDER BY "TIMESTAMP" DESC,"ID" DESC`)
+}
+
+// TestQuerySpecLikeEscape 测试模糊匹配的值中的%和_按字面匹配
+func TestQuerySpecLikeEscape(t *testing.T) {
+	repo := NewCowrieLogRepo(openSQLiteMemory(t, &CowrieLog{}))
+	now := time.Now()
+	if _, err := repo.CreateBatch([]CowrieLog{
+		{AuthID: "a1", SessionID: "s1", EventTime: now, Command: "echo 100%"},
+		{AuthID: "a2", SessionID: "s2", EventTime: now, Command: "echo 1000"},
+		{AuthID: "a3", SessionID: "s3", EventTime: now, Command: "cat a_b"},
+		{AuthID: "a4", SessionID: "s4", EventTime: now, Command: "cat axb"},
+	}); err != nil {
+		t.Fatalf("写入日志失败: %v", err)
+	}
+
+	for value, want := range map[string]string{"0%": "a1", "a_b": "a3"} {
+		page, err := repo.Query(QuerySpec{Filters: []QueryFilter{{Field: "command", Op: FilterLike, Values: []string{value}}}})
+		if err != nil || page.Total != 1 || page.Items[0].AuthID != want {
+			t.Errorf("模糊匹配 %q 应只匹配 %s: %+v err=%v", value, want, page, err)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import "time"
 // HoneypotTemplateRepository 蜜罐模板仓库接口
 type HoneypotTemplateRepository interface {
 	List() ([]HoneypotTemplate, error)
+	Query(spec QuerySpec) (*Page[HoneypotTemplate], error)
 	GetByID(id uint) (*HoneypotTemplate, error)
 	Create(template *HoneypotTemplate) error
 	Update(template *HoneypotTemplate) error
@@ -16,6 +17,7 @@ type HoneypotTemplateRepository interface {
 // HoneypotInstanceRepository 蜜罐实例仓库接口
 type HoneypotInstanceRepository interface {
 	List() ([]HoneypotInstance, error)
+	Query(spec QuerySpec) (*Page[HoneypotInstance], error)
 	GetByID(id uint) (*HoneypotInstance, error)
 	Create(instance *HoneypotInstance) error
 	Update(instance *HoneypotInstance) error
@@ -29,6 +31,7 @@ type HoneypotInstanceRepository interface {
 // HoneypotLogRepository 蜜罐日志仓库接口
 type HoneypotLogRepository interface {
 	List() ([]HoneypotLog, error)
+	Query(spec QuerySpec) (*Page[HoneypotLog], error)
 	GetByID(id uint) (*HoneypotLog, error)
 	GetByInstanceID(instanceID uint) ([]HoneypotLog, error)
 	Create(log *HoneypotLog) error
@@ -38,6 +41,7 @@ type HoneypotLogRepository interface {
 // BaitRepository 诱饵仓库接口
 type BaitRepository interface {
 	List() ([]Bait, error)
+	Query(spec QuerySpec) (*Page[Bait], error)
 	GetByID(id uint) (*Bait, error)
 	Create(bait *Bait) error
 	Update(bait *Bait) error
@@ -48,6 +52,7 @@ type BaitRepository interface {
 // SecurityRuleRepository 安全规则仓库接口
 type SecurityRuleRepository interface {
 	List() ([]SecurityRule, error)
+	Query(spec QuerySpec) (*Page[SecurityRule], error)
 	GetByID(id uint) (*SecurityRule, error)
 	Create(rule *SecurityRule) error
 	Update(rule *SecurityRule) error
@@ -58,6 +63,7 @@ type SecurityRuleRepository interface {
 // RuleLogRepository 规则日志仓库接口
 type RuleLogRepository interface {
 	List() ([]RuleLog, error)
+	Query(spec QuerySpec) (*Page[RuleLog], error)
 	GetByID(id uint) (*RuleLog, error)
 	GetByRuleID(ruleID uint) ([]RuleLog, error)
 	Create(log *RuleLog) error
@@ -67,6 +73,7 @@ type RuleLogRepository interface {
 // DockerImageRepository Docker镜像仓库接口
 type DockerImageRepository interface {
 	List() ([]DockerImage, error)
+	Query(spec QuerySpec) (*Page[DockerImage], error)
 	GetByID(id uint) (*DockerImage, error)
 	GetByImageID(imageID string) (*DockerImage, error)
 	Create(image *DockerImage) error
@@ -78,6 +85,7 @@ type DockerImageRepository interface {
 // DockerImageLogRepository Docker镜像日志仓库接口
 type DockerImageLogRepository interface {
 	List() ([]DockerImageLog, error)
+	Query(spec QuerySpec) (*Page[DockerImageLog], error)
 	GetByID(id uint) (*DockerImageLog, error)
 	GetByImageID(imageID string) ([]DockerImageLog, error)
 	Create(log *DockerImageLog) error
@@ -87,6 +95,7 @@ type DockerImageLogRepository interface {
 // ContainerLogSegmentRepository 容器日志分析仓库接口
 type ContainerLogSegmentRepository interface {
 	List() ([]ContainerLogSegment, error)
+	Query(spec QuerySpec) (*Page[ContainerLogSegment], error)
 	GetByID(id uint) (*ContainerLogSegment, error)
 	GetByContainerID(containerID string) ([]ContainerLogSegment, error)
 	GetBySegmentType(segmentType string) ([]ContainerLogSegment, error)
@@ -99,6 +108,7 @@ type ContainerLogSegmentRepository interface {
 // DockerContainerRepository Docker容器仓库接口
 type DockerContainerRepository interface {
 	List() ([]DockerContainer, error)
+	Query(spec QuerySpec) (*Page[DockerContainer], error)
 	GetByID(id uint) (*DockerContainer, error)
 	GetByContainerID(containerID string) (*DockerContainer, error)
 	GetByImageID(imageID string) ([]DockerContainer, error)
@@ -112,6 +122,8 @@ type DockerContainerRepository interface {
 // HeadlingAuthLogRepository Headling认证日志仓库接口
 type HeadlingAuthLogRepository interface {
 	List() ([]HeadlingAuthLog, error)
+	Query(spec QuerySpec) (*Page[HeadlingAuthLog], error)
+	ListAfterID(afterID uint, limit int) ([]HeadlingAuthLog, error)
 	GetByID(id uint) (*HeadlingAuthLog, error)
 	GetByAuthID(authID string) (*HeadlingAuthLog, error)
 	GetBySessionID(sessionID string) ([]HeadlingAuthLog, error)
@@ -120,12 +132,12 @@ type HeadlingAuthLogRepository interface {
 	GetByProtocol(protocol string) ([]HeadlingAuthLog, error)
 	GetByTimeRange(startTime, endTime time.Time) ([]HeadlingAuthLog, error)
 	Create(log *HeadlingAuthLog) error
-	CreateBatch(logs []HeadlingAuthLog) error
+	CreateBatch(logs []HeadlingAuthLog) (int64, error)
 	Update(log *HeadlingAuthLog) error
 	Delete(id uint) error
 	DeleteByContainerID(containerID string) error
-	GetStatistics() ([]HeadlingAuthStatistics, error)
-	GetAttackerIPStatistics() ([]AttackerIPStatistics, error)
+	GetStatistics(filter StatisticsFilter) ([]HeadlingAuthStatistics, error)
+	GetAttackerIPStatistics(filter StatisticsFilter) ([]AttackerIPStatistics, error)
 	GetTopAttackers(limit int) ([]AttackerIPStatistics, error)
 	GetTopUsernames(limit int) ([]map[string]interface{}, error)
 	GetTopPasswords(limit int) ([]map[string]interface{}, error)
@@ -134,9 +146,13 @@ type HeadlingAuthLogRepository interface {
 // CowrieLogRepository Cowrie蜜罐日志仓库接口
 type CowrieLogRepository interface {
 	List() ([]CowrieLog, error)
+	Query(spec QuerySpec) (*Page[CowrieLog], error)
+	ListAfterID(afterID uint, limit int) ([]CowrieLog, error)
 	GetByID(id uint) (*CowrieLog, error)
 	GetByAuthID(authID string) (*CowrieLog, error)
 	GetBySessionID(sessionID string) ([]CowrieLog, error)
+	GetSessionConnects(containerID string, sessionIDs []string) ([]CowrieLog, error)
+	MarkLastCommandNotFound(containerID, sessionID string) error
 	GetBySourceIP(sourceIP string) ([]CowrieLog, error)
 	GetByContainerID(containerID string) ([]CowrieLog, error)
 	GetByProtocol(protocol string) ([]CowrieLog, error)
@@ -144,17 +160,179 @@ type CowrieLogRepository interface {
 	GetByCommand(command string) ([]CowrieLog, error)
 	GetByCommandFound(found bool) ([]CowrieLog, error)
 	GetByUsername(username string) ([]CowrieLog, error)
+	GetByEventID(eventID string) ([]CowrieLog, error)
+	GetLoginEvents(success bool) ([]CowrieLog, error)
+	GetCommandEvents() ([]CowrieLog, error)
+	GetFileTransferEvents() ([]CowrieLog, error)
+	GetFileTransferEventsByContainerID(containerID string) ([]CowrieLog, error)
+	GetDirectTCPIPEvents() ([]CowrieLog, error)
 	Create(log *CowrieLog) error
-	CreateBatch(logs []CowrieLog) error
+	CreateBatch(logs []CowrieLog) (int64, error)
 	Update(log *CowrieLog) error
 	Delete(id uint) error
 	DeleteByContainerID(containerID string) error
-	GetStatistics() ([]CowrieStatistics, error)
-	GetAttackerBehavior() ([]CowrieAttackerBehavior, error)
+	GetStatistics(filter StatisticsFilter) ([]CowrieStatistics, error)
+	GetAttackerBehavior(filter StatisticsFilter) ([]CowrieAttackerBehavior, error)
 	GetTopAttackers(limit int) ([]CowrieAttackerBehavior, error)
-	GetCommandStatistics() ([]CowrieCommandStatistics, error)
+	GetCommandStatistics(filter StatisticsFilter) ([]CowrieCommandStatistics, error)
 	GetTopCommands(limit int) ([]CowrieCommandStatistics, error)
 	GetTopUsernames(limit int) ([]map[string]interface{}, error)
 	GetTopPasswords(limit int) ([]map[string]interface{}, error)
 	GetTopFingerprints(limit int) ([]map[string]interface{}, error)
+	GetEventStatistics() ([]CowrieEventStatistics, error)
+}
+
+// DionaeaLogRepository Dionaea日志仓库接口
+type DionaeaLogRepository interface {
+	List() ([]DionaeaLog, error)
+	Query(spec QuerySpec) (*Page[DionaeaLog], error)
+	ListAfterID(afterID uint, limit int) ([]DionaeaLog, error)
+	GetByID(id uint) (*DionaeaLog, error)
+	GetBySessionID(sessionID string) ([]DionaeaLog, error)
+	GetBySourceIP(sourceIP string) ([]DionaeaLog, error)
+	GetByContainerID(containerID string) ([]DionaeaLog, error)
+	GetByProtocol(protocol string) ([]DionaeaLog, error)
+	GetByEventType(eventType string) ([]DionaeaLog, error)
+	GetByUsername(username string) ([]DionaeaLog, error)
+	GetByTimeRange(startTime, endTime time.Time) ([]DionaeaLog, error)
+	CreateBatch(logs []DionaeaLog) (int64, error)
+	DeleteByContainerID(containerID string) error
+	GetStatistics() ([]DionaeaStatistics, error)
+	GetTopAttackers(limit int) ([]DionaeaAttackerStatistics, error)
+	GetTopUsernames(limit int) ([]map[string]interface{}, error)
+	GetTopPasswords(limit int) ([]map[string]interface{}, error)
+	GetTopDownloads(limit int) ([]map[string]interface{}, error)
+}
+
+// QeeqboxLogRepository qeeqbox/honeypots日志仓库接口
+type QeeqboxLogRepository interface {
+	List() ([]QeeqboxLog, error)
+	Query(spec QuerySpec) (*Page[QeeqboxLog], error)
+	ListAfterID(afterID uint, limit int) ([]QeeqboxLog, error)
+	GetByID(id uint) (*QeeqboxLog, error)
+	GetByContainerID(containerID string) ([]QeeqboxLog, error)
+	GetBySourceIP(sourceIP string) ([]QeeqboxLog, error)
+	GetByProtocol(protocol string) ([]QeeqboxLog, error)
+	GetByAction(action string) ([]QeeqboxLog, error)
+	GetByTimeRange(startTime, endTime time.Time) ([]QeeqboxLog, error)
+	Search(filter QeeqboxLogFilter) ([]QeeqboxLog, error)
+	CreateBatch(logs []QeeqboxLog) (int64, error)
+	DeleteByContainerID(containerID string) error
+	GetStatistics() ([]QeeqboxStatistics, error)
+	GetTopAttackers(limit int) ([]QeeqboxAttackerStatistics, error)
+	GetTopUsernames(limit int) ([]map[string]interface{}, error)
+	GetTopPasswords(limit int) ([]map[string]interface{}, error)
+}
+
+// CowrieTTYLogRepository Cowrie会话TTY日志仓库接口
+type CowrieTTYLogRepository interface {
+	GetBySessionID(sessionID string) (*CowrieTTYLog, error)
+	Create(ttyLog *CowrieTTYLog) error
+}
+
+// MalwareSampleRepository 恶意样本仓库接口
+type MalwareSampleRepository interface {
+	List() ([]MalwareSample, error)
+	Query(spec QuerySpec) (*Page[MalwareSample], error)
+	GetByID(id uint) (*MalwareSample, error)
+	GetBySHA256(sha256 string) (*MalwareSample, error)
+	Create(sample *MalwareSample) error
+	Update(sample *MalwareSample) error
+	CreateSighting(sighting *MalwareSighting) error
+	RecordSighting(sample *MalwareSample, sighting *MalwareSighting) error
+	HasSighting(cowrieLogID uint) (bool, error)
+	GetSightingsBySampleID(sampleID uint) ([]MalwareSighting, error)
+	GetSightingsBySourceIP(sourceIP string) ([]MalwareSighting, error)
+}
+
+// ContainerLogCursorRepository 容器日志读取游标仓库接口
+type ContainerLogCursorRepository interface {
+	Get(containerID, logSource string) (*ContainerLogCursor, error)
+	GetByContainerID(containerID string) ([]ContainerLogCursor, error)
+	Save(cursor *ContainerLogCursor) error
+	Delete(containerID, logSource string) error
+}
+
+// AttackEventRepository 攻击事件仓库接口
+type AttackEventRepository interface {
+	Create(event *AttackEvent) error
+	CreateBatch(events []AttackEvent) error
+	GetByID(id uint) (*AttackEvent, error)
+	Search(filter AttackEventFilter) ([]AttackEvent, error)
+	Query(spec QuerySpec) (*Page[AttackEvent], error)
+	Count(filter AttackEventFilter) (int64, error)
+	CountByAttackType() ([]AttackEventCount, error)
+	CountBySeverity() ([]AttackEventCount, error)
+	GetTopAttackers(limit int) ([]AttackEventCount, error)
+}
+
+// AttackSessionRepository 攻击会话仓库接口
+type AttackSessionRepository interface {
+	Create(session *AttackSession) error
+	Update(session *AttackSession) error
+	GetByID(id uint) (*AttackSession, error)
+	GetOpenBySessionID(sessionID string) (*AttackSession, error)
+	GetLatestBySessionID(sessionID string) (*AttackSession, error)
+	List(limit int) ([]AttackSession, error)
+	Count() (int64, error)
+	CloseIdle(idleBefore time.Time) (int64, error)
+}
+
+// HoneyTokenRepository 蜜签仓库接口
+type HoneyTokenRepository interface {
+	Create(token *HoneyToken) error
+	Update(token *HoneyToken) error
+	Delete(id uint) error
+	GetByID(id uint) (*HoneyToken, error)
+	List() ([]HoneyToken, error)
+	Query(spec QuerySpec) (*Page[HoneyToken], error)
+	IncrementTriggerCount(id uint) error
+	Count() (int64, error)
+	CountActive() (int64, error)
+	CountByType() ([]HoneyTokenTypeCount, error)
+}
+
+// HoneyTokenTriggerRepository 蜜签触发记录仓库接口
+type HoneyTokenTriggerRepository interface {
+	Create(trigger *HoneyTokenTrigger) error
+	Record(trigger *HoneyTokenTrigger) error
+	Search(filter HoneyTokenTriggerFilter) ([]HoneyTokenTrigger, error)
+	Count() (int64, error)
+}
+
+// AttackTimelineRepository 攻击事件时间线仓库接口
+type AttackTimelineRepository interface {
+	Search(filter AttackTimelineFilter) ([]AttackTimelineEvent, error)
+	CreateBatch(events []AttackTimelineEvent) (int64, error)
+}
+
+// SyslogMessageRepository syslog消息仓库接口
+type SyslogMessageRepository interface {
+	Search(filter SyslogMessageFilter) ([]SyslogMessage, error)
+	CreateBatch(messages []SyslogMessage) error
+	GetSensorStatistics() ([]SyslogSensorStatistics, error)
+}
+
+// RetentionRepository 日志保留策略和归档记录仓库接口
+type RetentionRepository interface {
+	ListPolicies() ([]RetentionPolicy, error)
+	GetPolicy(table string) (*RetentionPolicy, error)
+	SavePolicy(policy *RetentionPolicy) error
+	CreateArchive(archive *RetentionArchive) error
+	GetArchive(id uint) (*RetentionArchive, error)
+	ListArchives(table string) ([]RetentionArchive, error)
+	MarkArchiveRestored(id uint, rows int64, restoredAt time.Time) error
+}
+
+// SSHAuthAttemptRepository 内置SSH蜜罐认证尝试仓库接口
+type SSHAuthAttemptRepository interface {
+	Query(spec QuerySpec) (*Page[SSHAuthAttempt], error)
+	GetBySessionID(sessionID string) ([]SSHAuthAttempt, error)
+	Create(attempt *SSHAuthAttempt) error
+}
+
+// SystemSettingRepository 系统设置仓库接口
+type SystemSettingRepository interface {
+	Get(name string) (*SystemSetting, error)
+	Set(name, value string) error
 }
@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 日志保留策略使用的通用读写函数，按模型类型操作，不依赖具体的日志仓库
+
+// retentionDeleteChunk 按主键删除时每条语句的ID数量，避免IN列表过长
+const retentionDeleteChunk = 500
+
+// OldestRows 按时间列升序(时间相同时按ID)读取最早的limit条记录，before不为nil时只读取时间早于before的记录
+// 列名按连接的命名策略换算，达梦中为大写
+func OldestRows[T any](db *gorm.DB, timeColumn string, before *time.Time, limit int) ([]T, error) {
+	timeCol := clause.Column{Name: db.NamingStrategy.ColumnName("", timeColumn)}
+	idCol := clause.Column{Name: db.NamingStrategy.ColumnName("", "id")}
+
+	query := db.Model(new(T))
+	if before != nil {
+		query = query.Where(clause.Lt{Column: timeCol, Value: *before})
+	}
+
+	var rows []T
+	result := query.Clauses(clause.OrderBy{Columns: []clause.OrderByColumn{
+		{Column: timeCol},
+		{Column: idCol},
+	}}).Limit(limit).Find(&rows)
+	return rows, result.Error
+}
+
+// DeleteRowsByID 按主键删除记录，返回删除的条数
+func DeleteRowsByID[T any](db *gorm.DB, ids []uint) (int64, error) {
+	var deleted int64
+	for start := 0; start < len(ids); start += retentionDeleteChunk {
+		end := start + retentionDeleteChunk
+		if end > len(ids) {
+			end = len(ids)
+		}
+		result := db.Where("id IN ?", ids[start:end]).Delete(new(T))
+		if result.Error != nil {
+			return deleted, result.Error
+		}
+		deleted += result.RowsAffected
+	}
+	return deleted, nil
+}
+
+// RestoreRows 按原主键写回归档的记录，主键已存在的记录被跳过；authID不为nil时auth_id已存在的记录也被跳过
+// 返回实际写回的条数
+func RestoreRows[T any](db *gorm.DB, rows []T, id func(*T) uint, authID func(*T) string) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]uint, len(rows))
+	for i := range rows {
+		ids[i] = id(&rows[i])
+	}
+	var existingIDs []uint
+	if err := db.Model(new(T)).Where("id IN ?", ids).Pluck("id", &existingIDs).Error; err != nil {
+		return 0, err
+	}
+	skip := make(map[uint]bool, len(existingIDs))
+	for _, existing := range existingIDs {
+		skip[existing] = true
+	}
+
+	var existingAuthIDs map[string]uint
+	if authID != nil {
+		authIDs := make([]string, len(rows))
+		for i := range rows {
+			authIDs[i] = authID(&rows[i])
+		}
+		var err error
+		if existingAuthIDs, err = findByAuthIDs[T](db, authIDs); err != nil {
+			return 0, err
+		}
+	}
+
+	fresh := make([]T, 0, len(rows))
+	for i := range rows {
+		if skip[ids[i]] {
+			continue
+		}
+		if authID != nil {
+			if _, ok := existingAuthIDs[authID(&rows[i])]; ok {
+				continue
+			}
+		}
+		skip[ids[i]] = true
+		fresh = append(fresh, rows[i])
+	}
+	if len(fresh) == 0 {
+		return 0, nil
+	}
+
+	var restored int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if dialectOf(tx) == DialectDameng {
+			// 达梦的自增列默认不允许写入指定值，需要在当前会话中临时打开
+			stmt := &gorm.Statement{DB: tx}
+			if err := stmt.Parse(new(T)); err != nil {
+				return err
+			}
+			table := stmt.Schema.Table
+			if err := tx.Exec(fmt.Sprintf(`SET IDENTITY_INSERT "%s" ON`, table)).Error; err != nil {
+				return err
+			}
+			defer tx.Exec(fmt.Sprintf(`SET IDENTITY_INSERT "%s" OFF`, table))
+		}
+		result := tx.CreateInBatches(fresh, 100)
+		restored = result.RowsAffected
+		return result.Error
+	})
+	return restored, err
+}
@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+)
+
+// TestDamengOldestRows 测试达梦后端的归档查询按时间列和主键顺序读取早于截止时间的记录
+func TestDamengOldestRows(t *testing.T) {
+	db, recorder := openDamengDryRun(t)
+
+	before := time.Date(2025, 3, 1, 0, 0, 0, 0, time.Local)
+	if _, err := OldestRows[HeadlingAuthLog](db, "timestamp", &before, 100); err != nil {
+		t.Fatalf("生成归档查询SQL失败: %v", err)
+	}
+
+	assertSQLContains(t, recorder, `WHERE "TIMESTAMP" < `, `ORDER BY "TIMESTAMP","ID" LIMIT 100`)
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TextSearchFilter 全文检索的候选记录条件
+// 数据库只做不区分大小写的子串预筛选，是否真正匹配以及高亮由服务层判断，因此三种数据库的行为一致
+type TextSearchFilter struct {
+	Columns     []string // 检索的文本列
+	Terms       []string // 每个词都必须出现在某个检索列中，为空时不按内容过滤
+	TimeColumn  string   // 时间范围过滤使用的列
+	StartTime   *time.Time
+	EndTime     *time.Time
+	SourceIPs   []string
+	ContainerID string
+	BeforeID    uint // 只返回ID小于BeforeID的记录，0表示从最新的记录开始
+	Limit       int
+}
+
+// likeEscaper 转义LIKE中的通配符，使用!作为转义字符，避免不同数据库对反斜杠的处理差异
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// SearchCandidates 按ID倒序读取满足条件的候选记录
+func SearchCandidates[T any](db *gorm.DB, filter TextSearchFilter) ([]T, error) {
+	query := db.Model(new(T))
+
+	for _, term := range filter.Terms {
+		pattern := "%" + likeEscaper.Replace(strings.ToLower(term)) + "%"
+		conditions := make([]string, len(filter.Columns))
+		args := make([]interface{}, len(filter.Columns))
+		for i, column := range filter.Columns {
+			conditions[i] = "LOWER(" + column + ") LIKE ? ESCAPE '!'"
+			args[i] = pattern
+		}
+		query = query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+
+	if filter.TimeColumn != "" {
+		timeCol := clause.Column{Name: db.NamingStrategy.ColumnName("", filter.TimeColumn)}
+		if filter.StartTime != nil {
+			query = query.Where(clause.Gte{Column: timeCol, Value: *filter.StartTime})
+		}
+		if filter.EndTime != nil {
+			query = query.Where(clause.Lte{Column: timeCol, Value: *filter.EndTime})
+		}
+	}
+	if len(filter.SourceIPs) > 0 {
+		query = query.Where("source_ip IN ?", filter.SourceIPs)
+	}
+	if filter.ContainerID != "" {
+		query = query.Where("container_id = ?", filter.ContainerID)
+	}
+	if filter.BeforeID > 0 {
+		query = query.Where("id < ?", filter.BeforeID)
+	}
+
+	var rows []T
+	result := query.Order("id DESC").Limit(filter.Limit).Find(&rows)
+	return rows, result.Error
+}
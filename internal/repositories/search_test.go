@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+)
+
+// TestDamengSearchCandidates 测试达梦后端的检索按小写匹配关键词并限制时间范围
+func TestDamengSearchCandidates(t *testing.T) {
+	db, recorder := openDamengDryRun(t)
+
+	end := time.Date(2025, 3, 1, 0, 0, 0, 0, time.Local)
+	if _, err := SearchCandidates[AttackEvent](db, TextSearchFilter{
+		Columns: []string{"payload"}, Terms: []string{"wget"}, TimeColumn: "timestamp", EndTime: &end, Limit: 500,
+	}); err != nil {
+		t.Fatalf("生成检索SQL失败: %v", err)
+	}
+
+	assertSQLContains(t, recorder, "(LOWER(payload) LIKE '%wget%' ESCAPE '!')", `"TIMESTAMP" <= `)
+}
@@ -0,0 +1,121 @@
+package repositories
+
+import "gorm.io/gorm"
+
+// 认证日志和Cowrie日志的统计直接聚合日志表，所有数据库使用同一套查询，不依赖MySQL脚本中的统计视图(v_*)，
+// 时间列和container_id列都有索引，按时间范围和容器过滤时不需要全表扫描
+
+// Cowrie日志的username和command是普通字符串，非认证、非命令事件保存的是空串而不是NULL，
+// 认证和命令次数按事件类型统计，用户名和指纹去重时忽略空串
+var (
+	cowrieAuthAttempts  = "COUNT(CASE WHEN event_id IN ('" + CowrieEventLoginSuccess + "', '" + CowrieEventLoginFailed + "') THEN 1 END)"
+	cowrieCommandInputs = "COUNT(CASE WHEN event_id = '" + CowrieEventCommandInput + "' THEN 1 END)"
+)
+
+// applyStatisticsFilter 按时间范围和容器过滤统计查询，timeColumn为日志的时间列
+func applyStatisticsFilter(query *gorm.DB, filter StatisticsFilter, timeColumn string) *gorm.DB {
+	if filter.ContainerID != "" {
+		query = query.Where("container_id = ?", filter.ContainerID)
+	}
+	if filter.StartTime != nil {
+		query = query.Where(timeColumn+" >= ?", *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		query = query.Where(timeColumn+" <= ?", *filter.EndTime)
+	}
+	return query
+}
+
+// headlingAuthStatistics 按日期和协议统计认证日志
+func headlingAuthStatistics(db *gorm.DB, filter StatisticsFilter) ([]HeadlingAuthStatistics, error) {
+	var stats []HeadlingAuthStatistics
+	timestamp := columnOf(db, "timestamp")
+	logDate := dateOf(db, timestamp)
+	query := db.Table("headling_auth_log").
+		Select(logDate + " as log_date, protocol, COUNT(*) as total_attempts, " +
+			"COUNT(DISTINCT source_ip) as unique_ips, COUNT(DISTINCT username) as unique_usernames, " +
+			"COUNT(DISTINCT session_id) as unique_sessions, " +
+			"MIN(" + timestamp + ") as first_attempt, MAX(" + timestamp + ") as last_attempt").
+		Group(logDate + ", protocol").
+		Order("log_date, protocol")
+	err := findStatistics(applyStatisticsFilter(query, filter, timestamp), &stats)
+	return stats, err
+}
+
+// headlingAttackerIPStatistics 按攻击者IP统计认证日志，按尝试次数降序
+func headlingAttackerIPStatistics(db *gorm.DB, filter StatisticsFilter) ([]AttackerIPStatistics, error) {
+	var stats []AttackerIPStatistics
+	timestamp := columnOf(db, "timestamp")
+	query := db.Table("headling_auth_log").
+		Select("source_ip, COUNT(*) as total_attempts, COUNT(DISTINCT protocol) as protocols_used, " +
+			"COUNT(DISTINCT username) as usernames_tried, COUNT(DISTINCT destination_port) as ports_targeted, " +
+			"MIN(" + timestamp + ") as first_seen, MAX(" + timestamp + ") as last_seen, " +
+			durationMinutesOf(db, "MIN("+timestamp+")", "MAX("+timestamp+")") + " as attack_duration_minutes").
+		Group("source_ip").
+		Order("total_attempts DESC, source_ip")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	err := findStatistics(applyStatisticsFilter(query, filter, timestamp), &stats)
+	return stats, err
+}
+
+// cowrieStatistics 按日期和协议统计Cowrie日志
+func cowrieStatistics(db *gorm.DB, filter StatisticsFilter) ([]CowrieStatistics, error) {
+	var stats []CowrieStatistics
+	logDate := dateOf(db, "event_time")
+	query := db.Table("cowrie_log").
+		Select(logDate + " as log_date, protocol, COUNT(*) as total_events, " +
+			"COUNT(DISTINCT source_ip) as unique_ips, COUNT(DISTINCT session_id) as unique_sessions, " +
+			cowrieAuthAttempts + " as auth_attempts, " +
+			cowrieCommandInputs + " as command_attempts, " +
+			"COUNT(CASE WHEN command_found = 1 THEN 1 END) as valid_commands, " +
+			"MIN(event_time) as first_event, MAX(event_time) as last_event").
+		Group(logDate + ", protocol").
+		Order("log_date, protocol")
+	err := findStatistics(applyStatisticsFilter(query, filter, "event_time"), &stats)
+	return stats, err
+}
+
+// cowrieAttackerBehavior 按攻击者IP统计行为，按事件数降序
+func cowrieAttackerBehavior(db *gorm.DB, filter StatisticsFilter) ([]CowrieAttackerBehavior, error) {
+	var behavior []CowrieAttackerBehavior
+	query := db.Table("cowrie_log").
+		Select("source_ip, COUNT(*) as total_events, COUNT(DISTINCT protocol) as protocols_used, " +
+			"COUNT(DISTINCT session_id) as sessions_created, " +
+			cowrieAuthAttempts + " as auth_attempts, " +
+			cowrieCommandInputs + " as commands_executed, " +
+			"COUNT(CASE WHEN command_found = 1 THEN 1 END) as valid_commands, " +
+			"COUNT(DISTINCT NULLIF(username, '')) as usernames_tried, " +
+			"COUNT(DISTINCT NULLIF(fingerprint, '')) as unique_fingerprints, " +
+			"MIN(event_time) as first_seen, MAX(event_time) as last_seen, " +
+			durationMinutesOf(db, "MIN(event_time)", "MAX(event_time)") + " as activity_duration_minutes").
+		Group("source_ip").
+		Order("total_events DESC, source_ip")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	err := findStatistics(applyStatisticsFilter(query, filter, "event_time"), &behavior)
+	return behavior, err
+}
+
+// cowrieCommandStatistics 按命令统计使用情况，按使用次数降序
+// 只统计command.input事件，command.failed事件只用来标记命令未识别，不计入使用次数
+func cowrieCommandStatistics(db *gorm.DB, filter StatisticsFilter) ([]CowrieCommandStatistics, error) {
+	var stats []CowrieCommandStatistics
+	command := textKeyOf(db, "command")
+	query := db.Table("cowrie_log").
+		Where("event_id = ?", CowrieEventCommandInput).
+		Select(command + " as command, COUNT(*) as usage_count, COUNT(DISTINCT source_ip) as unique_ips, " +
+			"COUNT(DISTINCT session_id) as unique_sessions, " +
+			"MAX(CASE WHEN command_found = 1 THEN 1 ELSE 0 END) as command_found, " +
+			"MIN(event_time) as first_used, MAX(event_time) as last_used").
+		Where(command + " != ''").
+		Group(command).
+		Order("usage_count DESC, command")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	err := findStatistics(applyStatisticsFilter(query, filter, "event_time"), &stats)
+	return stats, err
+}
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestDamengStatistics 测试达梦后端的Cowrie和Headling统计不依赖MySQL视图，并按过滤条件生成查询
+func TestDamengStatistics(t *testing.T) {
+	db, recorder := openDamengDryRun(t)
+
+	if _, err := NewCowrieLogRepo(db).GetTopCommands(10); err != nil {
+		t.Fatalf("生成命令统计SQL失败: %v", err)
+	}
+	since := time.Date(2025, 3, 1, 0, 0, 0, 0, time.Local)
+	if _, err := NewHeadlingAuthLogRepo(db).GetStatistics(StatisticsFilter{ContainerID: "c1", StartTime: &since}); err != nil {
+		t.Fatalf("生成认证统计SQL失败: %v", err)
+	}
+
+	assertSQLContains(t, recorder,
+		`TO_CHAR("TIMESTAMP", 'YYYY-MM-DD')`,
+		`"TIMESTAMP" >= `,
+		"GROUP BY CAST(command AS VARCHAR(4000))",
+	)
+	if sql := recorder.sql(); strings.Contains(sql, "v_cowrie") || strings.Contains(sql, "v_headling") {
+		t.Errorf("达梦后端不应查询MySQL视图:\n%s", sql)
+	}
+}
+
+// TestCowrieStatisticsCounts 测试认证和命令次数按事件类型统计，不把连接等其他事件的空用户名和空命令计入
+func TestCowrieStatisticsCounts(t *testing.T) {
+	repo := NewCowrieLogRepo(openSQLiteMemory(t, &CowrieLog{}))
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.Local)
+	events := []CowrieLog{
+		{EventID: CowrieEventSessionConnect},
+		{EventID: CowrieEventClientVersion},
+		{EventID: CowrieEventLoginFailed, Username: "root", Password: "123456"},
+		{EventID: CowrieEventLoginSuccess, Username: "admin", Password: "admin"},
+		{EventID: CowrieEventCommandInput, Command: "uname -a"},
+		{EventID: CowrieEventCommandInput, Command: "foo"},
+		{EventID: CowrieEventCommandFailed, Command: "foo"},
+		{EventID: CowrieEventSessionClosed},
+	}
+	for i := range events {
+		events[i].AuthID = fmt.Sprintf("a%d", i)
+		events[i].SessionID = "s1"
+		events[i].SourceIP = "1.2.3.4"
+		events[i].Protocol = "ssh"
+		events[i].EventTime = now.Add(time.Duration(i) * time.Second)
+	}
+	if _, err := repo.CreateBatch(events); err != nil {
+		t.Fatalf("写入日志失败: %v", err)
+	}
+
+	stats, err := repo.GetStatistics(StatisticsFilter{})
+	if err != nil || len(stats) != 1 {
+		t.Fatalf("获取统计失败: %+v err=%v", stats, err)
+	}
+	if stats[0].TotalEvents != 8 || stats[0].AuthAttempts != 2 || stats[0].CommandAttempts != 2 {
+		t.Errorf("按日期统计的次数错误: %+v", stats[0])
+	}
+
+	behavior, err := repo.GetAttackerBehavior(StatisticsFilter{})
+	if err != nil || len(behavior) != 1 {
+		t.Fatalf("获取攻击者行为失败: %+v err=%v", behavior, err)
+	}
+	if b := behavior[0]; b.TotalEvents != 8 || b.AuthAttempts != 2 || b.CommandsExecuted != 2 || b.UsernamesTried != 2 || b.UniqueFingerprints != 0 {
+		t.Errorf("攻击者行为统计错误: %+v", b)
+	}
+}
@@ -52,6 +52,18 @@ func SemanticSegment(containerID string) (map[string]interface{}, error) {
 	return result, nil
 }
 
+// IngestGenericLines 对没有专用解析器的日志行做语义分割并保存分析结果
+func IngestGenericLines(containerID string, lines []string) (int, error) {
+	segments, _ := AnalyzeContainerLogs(strings.Join(lines, "\n"))
+	if len(segments) == 0 {
+		return 0, nil
+	}
+	if err := saveLogSegmentsToDatabase(containerID, segments); err != nil {
+		return 0, err
+	}
+	return len(segments), nil
+}
+
 // AnalyzeContainerLogs 分析容器日志内容
 func AnalyzeContainerLogs(logContent string) ([]LogSegmentInfo, map[string]int) {
 	// 按行分割日志
@@ -133,14 +145,14 @@ func AnalyzeContainerLogs(logContent string) ([]LogSegmentInfo, map[string]int)
 
 // saveLogSegmentsToDatabase 将日志分析结果保存到数据库
 func saveLogSegmentsToDatabase(containerID string, segments []LogSegmentInfo) error {
-	if config.MySQLDB == nil {
-		fmt.Printf("MySQL数据库未初始化，无法保存日志分析结果\n")
+	if config.DB == nil {
+		fmt.Printf("数据库未初始化，无法保存日志分析结果\n")
 		return nil
 	}
 
 	// 创建仓库实例
-	segmentRepo := repositories.NewMySQLContainerLogSegmentRepo(config.MySQLDB)
-	containerRepo := repositories.NewMySQLDockerContainerRepo(config.MySQLDB)
+	segmentRepo := repositories.NewContainerLogSegmentRepo(config.DB)
+	containerRepo := repositories.NewDockerContainerRepo(config.DB)
 
 	// 获取容器信息
 	var containerName string
@@ -0,0 +1,367 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/repositories"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	// DefaultAttackSessionIdleTimeout 攻击会话默认空闲超时，超过该时间没有新事件的会话会被结束
+	DefaultAttackSessionIdleTimeout = 30 * time.Minute
+	// attackSessionReapInterval 检查空闲会话的间隔
+	attackSessionReapInterval = time.Minute
+)
+
+// attackSessionMutex 串行化会话的查找和更新，避免同一会话标识的并发事件各自开启会话
+var attackSessionMutex sync.Mutex
+
+// AttackSessionIdleTimeout 获取攻击会话空闲超时，可通过ATTACK_SESSION_IDLE_TIMEOUT环境变量覆盖(如 15m)
+func AttackSessionIdleTimeout() time.Duration {
+	if v := os.Getenv("ATTACK_SESSION_IDLE_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return DefaultAttackSessionIdleTimeout
+}
+
+// AttackSessionDetail 攻击会话及其包含的事件
+type AttackSessionDetail struct {
+	repositories.AttackSession
+	Events []repositories.AttackEvent `json:"events"`
+}
+
+// AttackStatistics 攻击捕获统计
+type AttackStatistics struct {
+	TotalEvents      int64                      `json:"total_events"`
+	TotalSessions    int64                      `json:"total_sessions"`
+	EventsByType     map[string]int64           `json:"events_by_type"`
+	EventsBySeverity map[string]int64           `json:"events_by_severity"`
+	TopAttackers     map[string]int64           `json:"top_attackers"`
+	RecentEvents     []repositories.AttackEvent `json:"recent_events"`
+}
+
+// AttackCaptureService 攻击捕获服务
+type AttackCaptureService struct {
+	EventRepo   repositories.AttackEventRepository
+	SessionRepo repositories.AttackSessionRepository
+	IdleTimeout time.Duration
+}
+
+// NewAttackCaptureService 创建攻击捕获服务
+func NewAttackCaptureService() (*AttackCaptureService, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	return &AttackCaptureService{
+		EventRepo:   repositories.NewAttackEventRepo(config.DB),
+		SessionRepo: repositories.NewAttackSessionRepo(config.DB),
+		IdleTimeout: AttackSessionIdleTimeout(),
+	}, nil
+}
+
+// RecordEvent 保存攻击事件并归入会话，会话标识为空时按攻击者IP归并
+// 同一会话标识距上一个事件超过空闲超时时结束旧会话并开启新会话
+func (s *AttackCaptureService) RecordEvent(event *repositories.AttackEvent) error {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	session, err := s.attachSession([]*repositories.AttackEvent{event})
+	if err != nil {
+		return fmt.Errorf("更新攻击会话失败: %v", err)
+	}
+	event.AttackSessionID = session.ID
+
+	if err := s.EventRepo.Create(event); err != nil {
+		return fmt.Errorf("保存攻击事件失败: %v", err)
+	}
+
+	RecordTimelineEvent(attackTimelineEvent(event, session.SessionID))
+	return nil
+}
+
+// RecordEvents 批量保存按时间排列的攻击事件，同一会话的事件只更新一次会话，事件和时间线各批量插入一次
+// 用于蜜罐按连接缓冲的事件，一批事件的时间跨度应小于会话空闲超时
+func (s *AttackCaptureService) RecordEvents(events []repositories.AttackEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	var keys []string
+	groups := make(map[string][]*repositories.AttackEvent)
+	for i := range events {
+		event := &events[i]
+		if event.Timestamp.IsZero() {
+			event.Timestamp = time.Now()
+		}
+		key := attackSessionKey(event)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], event)
+	}
+
+	sessionIDs := make(map[uint]string)
+	for _, key := range keys {
+		session, err := s.attachSession(groups[key])
+		if err != nil {
+			return fmt.Errorf("更新攻击会话失败: %v", err)
+		}
+		for _, event := range groups[key] {
+			event.AttackSessionID = session.ID
+		}
+		sessionIDs[session.ID] = session.SessionID
+	}
+
+	if err := s.EventRepo.CreateBatch(events); err != nil {
+		return fmt.Errorf("保存攻击事件失败: %v", err)
+	}
+
+	timeline := make([]repositories.AttackTimelineEvent, len(events))
+	for i := range events {
+		timeline[i] = attackTimelineEvent(&events[i], sessionIDs[events[i].AttackSessionID])
+	}
+	RecordTimelineEvents(timeline)
+	return nil
+}
+
+// attackTimelineEvent 把已保存的攻击事件转换为时间线事件
+func attackTimelineEvent(event *repositories.AttackEvent, sessionID string) repositories.AttackTimelineEvent {
+	return repositories.AttackTimelineEvent{
+		EventTime:       event.Timestamp,
+		Source:          TimelineSourceAttackCapture,
+		SourceRef:       strconv.FormatUint(uint64(event.ID), 10),
+		SessionID:       sessionID,
+		SourceIP:        event.SourceIP,
+		SourcePort:      uint(max(event.SourcePort, 0)),
+		DestinationIP:   event.DestIP,
+		DestinationPort: uint(max(event.DestPort, 0)),
+		Protocol:        event.Protocol,
+		Action:          event.AttackType,
+		Payload:         event.Payload,
+		Severity:        event.Severity,
+		ContainerID:     event.ContainerID,
+		ContainerName:   event.ContainerName,
+	}
+}
+
+// attackSessionKey 事件所属会话的标识，上报的会话ID为空时使用攻击者IP
+func attackSessionKey(event *repositories.AttackEvent) string {
+	if event.SessionID == "" {
+		return event.SourceIP
+	}
+	return event.SessionID
+}
+
+// AnalyzeSeverity 按攻击类型和载荷分析攻击严重程度(low/medium/high/critical)
+func AnalyzeSeverity(attackType, payload string) string {
+	attackType = strings.ToLower(attackType)
+	payload = strings.ToLower(payload)
+
+	// 高危攻击类型
+	if strings.Contains(attackType, "sql injection") ||
+		strings.Contains(attackType, "command injection") ||
+		hasWord(attackType, "rce") ||
+		strings.Contains(payload, "union select") ||
+		strings.Contains(payload, "exec") ||
+		strings.Contains(payload, "system") {
+		return "critical"
+	}
+
+	// 中高危攻击类型
+	if strings.Contains(attackType, "xss") ||
+		strings.Contains(attackType, "csrf") ||
+		strings.Contains(attackType, "brute force") ||
+		strings.Contains(payload, "script") ||
+		strings.Contains(payload, "alert") {
+		return "high"
+	}
+
+	// 中危攻击类型
+	if strings.Contains(attackType, "scan") ||
+		strings.Contains(attackType, "probe") ||
+		strings.Contains(attackType, "enumeration") {
+		return "medium"
+	}
+
+	return "low"
+}
+
+// hasWord 判断text中是否有以非字母数字字符分隔的单词word，避免"brute force"被当作"rce"
+func hasWord(text, word string) bool {
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		if field == word {
+			return true
+		}
+	}
+	return false
+}
+
+// attachSession 查找或开启一组同一会话标识、按时间排列的事件所属的会话并更新会话信息
+func (s *AttackCaptureService) attachSession(events []*repositories.AttackEvent) (*repositories.AttackSession, error) {
+	first := events[0]
+	sessionKey := attackSessionKey(first)
+
+	attackSessionMutex.Lock()
+	defer attackSessionMutex.Unlock()
+
+	session, err := s.SessionRepo.GetOpenBySessionID(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// 空闲超时的会话以最后一个事件的时间结束
+	if session != nil && first.Timestamp.Sub(session.LastEventTime) > s.IdleTimeout {
+		endTime := session.LastEventTime
+		session.EndTime = &endTime
+		if err := s.SessionRepo.Update(session); err != nil {
+			return nil, err
+		}
+		session = nil
+	}
+
+	create := session == nil
+	if create {
+		session = &repositories.AttackSession{
+			SessionID:     sessionKey,
+			SourceIP:      first.SourceIP,
+			StartTime:     first.Timestamp,
+			LastEventTime: first.Timestamp,
+		}
+	}
+	for _, event := range events {
+		session.EventCount++
+		if event.Timestamp.After(session.LastEventTime) {
+			session.LastEventTime = event.Timestamp
+		}
+		if !slices.Contains(session.AttackTypes, event.AttackType) {
+			session.AttackTypes = append(session.AttackTypes, event.AttackType)
+		}
+	}
+	if create {
+		return session, s.SessionRepo.Create(session)
+	}
+	return session, s.SessionRepo.Update(session)
+}
+
+// CloseIdleSessions 结束超过空闲超时没有新事件的会话，返回结束的会话数
+func (s *AttackCaptureService) CloseIdleSessions() (int64, error) {
+	attackSessionMutex.Lock()
+	defer attackSessionMutex.Unlock()
+
+	return s.SessionRepo.CloseIdle(time.Now().Add(-s.IdleTimeout))
+}
+
+// GetEventsPage 按查询条件分页获取攻击事件，同时返回符合条件的总数
+func (s *AttackCaptureService) GetEventsPage(spec repositories.QuerySpec) (*repositories.Page[repositories.AttackEvent], error) {
+	return s.EventRepo.Query(spec)
+}
+
+// SearchEvents 按条件分页查询攻击事件，同时返回符合条件的总数
+func (s *AttackCaptureService) SearchEvents(filter repositories.AttackEventFilter) ([]repositories.AttackEvent, int64, error) {
+	total, err := s.EventRepo.Count(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	events, err := s.EventRepo.Search(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+// ListSessions 按开始时间倒序获取攻击会话
+func (s *AttackCaptureService) ListSessions(limit int) ([]repositories.AttackSession, error) {
+	return s.SessionRepo.List(limit)
+}
+
+// GetSessionDetail 获取会话标识对应的最近一个会话及其事件，不存在时返回nil
+func (s *AttackCaptureService) GetSessionDetail(sessionID string) (*AttackSessionDetail, error) {
+	session, err := s.SessionRepo.GetLatestBySessionID(sessionID)
+	if err != nil || session == nil {
+		return nil, err
+	}
+
+	events, err := s.EventRepo.Search(repositories.AttackEventFilter{AttackSessionID: session.ID})
+	if err != nil {
+		return nil, err
+	}
+	return &AttackSessionDetail{AttackSession: *session, Events: events}, nil
+}
+
+// GetStatistics 统计攻击事件和会话
+func (s *AttackCaptureService) GetStatistics() (*AttackStatistics, error) {
+	stats := &AttackStatistics{}
+
+	var err error
+	if stats.TotalEvents, err = s.EventRepo.Count(repositories.AttackEventFilter{}); err != nil {
+		return nil, err
+	}
+	if stats.TotalSessions, err = s.SessionRepo.Count(); err != nil {
+		return nil, err
+	}
+
+	byType, err := s.EventRepo.CountByAttackType()
+	if err != nil {
+		return nil, err
+	}
+	bySeverity, err := s.EventRepo.CountBySeverity()
+	if err != nil {
+		return nil, err
+	}
+	topAttackers, err := s.EventRepo.GetTopAttackers(10)
+	if err != nil {
+		return nil, err
+	}
+	stats.EventsByType = countsToMap(byType)
+	stats.EventsBySeverity = countsToMap(bySeverity)
+	stats.TopAttackers = countsToMap(topAttackers)
+
+	if stats.RecentEvents, err = s.EventRepo.Search(repositories.AttackEventFilter{Limit: 20}); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// countsToMap 把分组统计结果转换为map
+func countsToMap(counts []repositories.AttackEventCount) map[string]int64 {
+	m := make(map[string]int64, len(counts))
+	for _, c := range counts {
+		m[c.Value] = c.Count
+	}
+	return m
+}
+
+var attackSessionReaperOnce sync.Once
+
+// StartAttackSessionReaper 启动后台任务，定期结束空闲超时的攻击会话，重复调用只启动一次
+func StartAttackSessionReaper() {
+	attackSessionReaperOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(attackSessionReapInterval)
+			defer ticker.Stop()
+
+			for range ticker.C {
+				service, err := NewAttackCaptureService()
+				if err != nil {
+					continue
+				}
+				if closed, err := service.CloseIdleSessions(); err != nil {
+					fmt.Printf("结束空闲攻击会话失败: %v\n", err)
+				} else if closed > 0 {
+					fmt.Printf("已结束 %d 个空闲攻击会话\n", closed)
+				}
+			}
+		}()
+	})
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"andorralee/internal/repositories"
+	"testing"
+	"time"
+)
+
+// memoryAttackSessionRepo 测试用的内存攻击会话仓库
+type memoryAttackSessionRepo struct {
+	sessions []*repositories.AttackSession
+}
+
+func (r *memoryAttackSessionRepo) Create(session *repositories.AttackSession) error {
+	session.ID = uint(len(r.sessions) + 1)
+	copied := *session
+	r.sessions = append(r.sessions, &copied)
+	return nil
+}
+
+func (r *memoryAttackSessionRepo) Update(session *repositories.AttackSession) error {
+	copied := *session
+	r.sessions[session.ID-1] = &copied
+	return nil
+}
+
+func (r *memoryAttackSessionRepo) GetByID(id uint) (*repositories.AttackSession, error) {
+	copied := *r.sessions[id-1]
+	return &copied, nil
+}
+
+func (r *memoryAttackSessionRepo) GetOpenBySessionID(sessionID string) (*repositories.AttackSession, error) {
+	for i := len(r.sessions) - 1; i >= 0; i-- {
+		if r.sessions[i].SessionID == sessionID && r.sessions[i].EndTime == nil {
+			copied := *r.sessions[i]
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryAttackSessionRepo) GetLatestBySessionID(sessionID string) (*repositories.AttackSession, error) {
+	return r.GetOpenBySessionID(sessionID)
+}
+
+func (r *memoryAttackSessionRepo) List(limit int) ([]repositories.AttackSession, error) {
+	return nil, nil
+}
+
+func (r *memoryAttackSessionRepo) Count() (int64, error) {
+	return int64(len(r.sessions)), nil
+}
+
+func (r *memoryAttackSessionRepo) CloseIdle(idleBefore time.Time) (int64, error) {
+	var closed int64
+	for _, session := range r.sessions {
+		if session.EndTime == nil && session.LastEventTime.Before(idleBefore) {
+			endTime := session.LastEventTime
+			session.EndTime = &endTime
+			closed++
+		}
+	}
+	return closed, nil
+}
+
+// TestAttachSessionIdleTimeout 测试同一会话标识的事件在空闲超时内归入同一会话，超时后开启新会话
+func TestAttachSessionIdleTimeout(t *testing.T) {
+	repo := &memoryAttackSessionRepo{}
+	service := &AttackCaptureService{SessionRepo: repo, IdleTimeout: 10 * time.Minute}
+
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.Local)
+	events := []repositories.AttackEvent{
+		{SourceIP: "1.2.3.4", AttackType: "scan", Timestamp: start},
+		{SourceIP: "1.2.3.4", AttackType: "brute force", Timestamp: start.Add(5 * time.Minute)},
+		{SourceIP: "1.2.3.4", AttackType: "scan", Timestamp: start.Add(8 * time.Minute)},
+		{SourceIP: "1.2.3.4", AttackType: "rce", Timestamp: start.Add(30 * time.Minute)},
+		{SourceIP: "5.6.7.8", SessionID: "s-1", AttackType: "xss", Timestamp: start},
+	}
+	for i := range events {
+		if _, err := service.attachSession([]*repositories.AttackEvent{&events[i]}); err != nil {
+			t.Fatalf("更新会话失败: %v", err)
+		}
+	}
+
+	if len(repo.sessions) != 3 {
+		t.Fatalf("期望3个会话，实际为 %d", len(repo.sessions))
+	}
+
+	first := repo.sessions[0]
+	if first.EventCount != 3 || len(first.AttackTypes) != 2 {
+		t.Errorf("第一个会话统计错误: %+v", first)
+	}
+	if first.EndTime == nil || !first.EndTime.Equal(start.Add(8*time.Minute)) {
+		t.Errorf("超时的会话应以最后一个事件的时间结束: %v", first.EndTime)
+	}
+	if repo.sessions[1].EndTime != nil || repo.sessions[1].EventCount != 1 {
+		t.Errorf("超时后应开启新会话: %+v", repo.sessions[1])
+	}
+	if repo.sessions[2].SessionID != "s-1" {
+		t.Errorf("上报了会话ID时应按会话ID归并: %+v", repo.sessions[2])
+	}
+
+	// 一组事件只更新一次会话
+	batch := []*repositories.AttackEvent{
+		{SourceIP: "5.6.7.8", SessionID: "s-1", AttackType: "xss", Timestamp: start.Add(time.Minute)},
+		{SourceIP: "5.6.7.8", SessionID: "s-1", AttackType: "sql injection", Timestamp: start.Add(2 * time.Minute)},
+	}
+	if session, err := service.attachSession(batch); err != nil || session.EventCount != 3 || len(session.AttackTypes) != 2 || !session.LastEventTime.Equal(start.Add(2*time.Minute)) {
+		t.Errorf("批量归入会话错误: %+v err=%v", session, err)
+	}
+}
+
+// TestAnalyzeSeverity 测试攻击类型按完整单词匹配，"brute force"中的"rce"不会被当作远程代码执行
+func TestAnalyzeSeverity(t *testing.T) {
+	for _, tc := range []struct {
+		attackType, payload, want string
+	}{
+		{"rce", "", "critical"},
+		{"redis rce", "SLAVEOF 1.2.3.4 6379", "critical"},
+		{"brute force", "user=admin&pass=admin", "high"},
+		{"redis brute force", "AUTH foobared", "high"},
+		{"force", "", "low"},
+		{"port scan", "", "medium"},
+	} {
+		if got := AnalyzeSeverity(tc.attackType, tc.payload); got != tc.want {
+			t.Errorf("AnalyzeSeverity(%q, %q) = %s, want %s", tc.attackType, tc.payload, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/repositories"
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// headHashSize 用于识别日志轮转的文件头部字节数
+const headHashSize = 1024
+
+// ContainerFile 从容器中读取到的文件
+type ContainerFile struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+	Reader  io.Reader
+	closer  io.Closer
+}
+
+// Close 关闭底层的tar流
+func (f *ContainerFile) Close() error {
+	if f.closer == nil {
+		return nil
+	}
+	return f.closer.Close()
+}
+
+// OpenContainerFile 通过Docker API(CopyFromContainer)打开容器中的单个文件
+func OpenContainerFile(containerID, filePath string) (*ContainerFile, error) {
+	if !IsDockerAvailable() {
+		return nil, fmt.Errorf("Docker服务不可用")
+	}
+
+	rc, _, err := config.DockerCli.CopyFromContainer(context.Background(), containerID, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("从容器读取文件 %s 失败: %v", filePath, err)
+	}
+
+	tr := tar.NewReader(rc)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			rc.Close()
+			return nil, fmt.Errorf("容器中不存在文件 %s", filePath)
+		}
+		if err != nil {
+			rc.Close()
+			return nil, fmt.Errorf("解析容器文件流失败: %v", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		return &ContainerFile{
+			Path:    filePath,
+			Size:    header.Size,
+			ModTime: header.ModTime,
+			Reader:  tr,
+			closer:  rc,
+		}, nil
+	}
+}
+
+// ContainerLogReader 基于游标增量读取容器内的日志文件
+type ContainerLogReader struct {
+	CursorRepo repositories.ContainerLogCursorRepository
+}
+
+// NewContainerLogReader 创建容器日志文件读取器
+func NewContainerLogReader(cursorRepo repositories.ContainerLogCursorRepository) *ContainerLogReader {
+	return &ContainerLogReader{CursorRepo: cursorRepo}
+}
+
+// ReadNewLines 读取日志文件中自上次游标以来新增的完整行
+// 返回的游标尚未保存，调用方应在日志成功入库后调用 Commit
+func (r *ContainerLogReader) ReadNewLines(containerID, logSource, filePath string) ([]string, *repositories.ContainerLogCursor, error) {
+	cursor, err := r.CursorRepo.Get(containerID, logSource)
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取日志游标失败: %v", err)
+	}
+	if cursor == nil || cursor.FilePath != filePath {
+		cursor = &repositories.ContainerLogCursor{
+			ContainerID: containerID,
+			LogSource:   logSource,
+			FilePath:    filePath,
+		}
+	}
+
+	file, err := OpenContainerFile(containerID, filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	head, err := readHead(file.Reader, file.Size)
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取日志文件头部失败: %v", err)
+	}
+	var lines []string
+
+	// 文件头部变化或文件变小，说明日志已轮转
+	rotated := cursor.Offset > 0 && (!sameHead(head, cursor) || file.Size < cursor.Offset)
+	if rotated {
+		fmt.Printf("检测到容器 %s 的日志文件 %s 已轮转\n", containerID, filePath)
+		lines = append(lines, r.readRotatedRemainder(containerID, cursor)...)
+		cursor.Offset = 0
+	}
+
+	// 跳过已读取的部分
+	var content []byte
+	if cursor.Offset <= int64(len(head)) {
+		content = append(content, head[cursor.Offset:]...)
+	} else if _, err := io.CopyN(io.Discard, file.Reader, cursor.Offset-int64(len(head))); err != nil {
+		return nil, nil, fmt.Errorf("跳过已读取的日志失败: %v", err)
+	}
+
+	rest, err := io.ReadAll(file.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取日志文件失败: %v", err)
+	}
+	content = append(content, rest...)
+
+	newLines, consumed := splitCompleteLines(content)
+	lines = append(lines, newLines...)
+
+	cursor.Offset += consumed
+	cursor.HeadHash = hashBytes(head)
+	cursor.FileSize = file.Size
+	cursor.ModTime = file.ModTime
+	cursor.LineCount += int64(len(newLines))
+
+	return lines, cursor, nil
+}
+
+// Commit 保存游标
+func (r *ContainerLogReader) Commit(cursor *repositories.ContainerLogCursor) error {
+	return r.CursorRepo.Save(cursor)
+}
+
+// readRotatedRemainder 从轮转后的旧文件中读取上次游标之后未处理的行
+func (r *ContainerLogReader) readRotatedRemainder(containerID string, cursor *repositories.ContainerLogCursor) []string {
+	for _, candidate := range rotatedFileCandidates(cursor.FilePath, cursor.ModTime) {
+		file, err := OpenContainerFile(containerID, candidate)
+		if err != nil {
+			continue
+		}
+
+		head, err := readHead(file.Reader, file.Size)
+		if err != nil || !sameHead(head, cursor) || file.Size < cursor.Offset {
+			file.Close()
+			continue
+		}
+
+		var content []byte
+		if cursor.Offset <= int64(len(head)) {
+			content = append(content, head[cursor.Offset:]...)
+		} else if _, err := io.CopyN(io.Discard, file.Reader, cursor.Offset-int64(len(head))); err != nil {
+			file.Close()
+			continue
+		}
+		rest, err := io.ReadAll(file.Reader)
+		file.Close()
+		if err != nil {
+			continue
+		}
+		content = append(content, rest...)
+
+		// 轮转后的文件不会再写入，最后一行即使没有换行符也是完整的
+		lines, consumed := splitCompleteLines(content)
+		if tail := strings.TrimSpace(string(content[consumed:])); tail != "" {
+			lines = append(lines, tail)
+		}
+		fmt.Printf("从轮转文件 %s 中补读了 %d 行日志\n", candidate, len(lines))
+		return lines
+	}
+	return nil
+}
+
+// rotatedFileCandidates 返回日志轮转后旧文件可能的路径
+func rotatedFileCandidates(filePath string, modTime time.Time) []string {
+	candidates := []string{filePath + ".1"}
+	if !modTime.IsZero() {
+		candidates = append(candidates, filePath+"."+modTime.Format("2006-01-02"))
+	}
+	ext := path.Ext(filePath)
+	if ext != "" && !modTime.IsZero() {
+		base := strings.TrimSuffix(filePath, ext)
+		candidates = append(candidates, base+"-"+modTime.Format("2006-01-02")+ext)
+	}
+	return candidates
+}
+
+// readHead 读取文件头部用于计算轮转指纹
+func readHead(reader io.Reader, size int64) ([]byte, error) {
+	n := int64(headHashSize)
+	if size < n {
+		n = size
+	}
+	head := make([]byte, n)
+	if _, err := io.ReadFull(reader, head); err != nil {
+		return nil, err
+	}
+	return head, nil
+}
+
+// sameHead 判断文件头部是否与游标记录的一致
+// 游标记录的是上次读取时的头部哈希，文件增长后只比较当时覆盖的长度
+func sameHead(head []byte, cursor *repositories.ContainerLogCursor) bool {
+	prevLen := int64(headHashSize)
+	if cursor.FileSize < prevLen {
+		prevLen = cursor.FileSize
+	}
+	if int64(len(head)) < prevLen {
+		return false
+	}
+	return hashBytes(head[:prevLen]) == cursor.HeadHash
+}
+
+// hashBytes 计算字节内容的SHA256
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// splitCompleteLines 按行切分内容，只返回以换行符结尾的完整行及其占用的字节数
+func splitCompleteLines(content []byte) ([]string, int64) {
+	last := bytes.LastIndexByte(content, '\n')
+	if last < 0 {
+		return nil, 0
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(content[:last]), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, int64(last + 1)
+}
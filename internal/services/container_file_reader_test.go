@@ -0,0 +1,60 @@
+package services
+
+import (
+	"andorralee/internal/repositories"
+	"strings"
+	"testing"
+)
+
+// TestSplitCompleteLines 测试只消费以换行符结尾的完整行
+func TestSplitCompleteLines(t *testing.T) {
+	content := []byte("line1\r\nline2\n\nline3-partial")
+
+	lines, consumed := splitCompleteLines(content)
+
+	if len(lines) != 2 || lines[0] != "line1" || lines[1] != "line2" {
+		t.Errorf("期望得到 [line1 line2]，实际得到 %v", lines)
+	}
+	if consumed != int64(len("line1\r\nline2\n\n")) {
+		t.Errorf("期望消费 %d 字节，实际消费 %d 字节", len("line1\r\nline2\n\n"), consumed)
+	}
+
+	if lines, consumed := splitCompleteLines([]byte("no-newline")); lines != nil || consumed != 0 {
+		t.Errorf("没有换行符时不应消费任何内容，实际得到 %v, %d", lines, consumed)
+	}
+}
+
+// TestSameHead 测试日志轮转识别
+func TestSameHead(t *testing.T) {
+	original := []byte("first line\n")
+	cursor := &repositories.ContainerLogCursor{
+		HeadHash: hashBytes(original),
+		FileSize: int64(len(original)),
+	}
+
+	// 文件追加内容后头部不变
+	grown := []byte("first line\nsecond line\n")
+	if !sameHead(grown, cursor) {
+		t.Error("文件追加内容后不应被识别为轮转")
+	}
+
+	// 文件被替换为新内容
+	if sameHead([]byte("another file\n"), cursor) {
+		t.Error("文件内容被替换后应被识别为轮转")
+	}
+
+	// 文件被截断
+	if sameHead([]byte("first"), cursor) {
+		t.Error("文件被截断后应被识别为轮转")
+	}
+
+	// 超过头部长度的大文件只比较头部
+	large := []byte(strings.Repeat("a", headHashSize*2))
+	cursor = &repositories.ContainerLogCursor{
+		HeadHash: hashBytes(large[:headHashSize]),
+		FileSize: int64(len(large)),
+	}
+	if !sameHead(large[:headHashSize], cursor) {
+		t.Error("大文件应只比较头部内容")
+	}
+}
@@ -0,0 +1,336 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/repositories"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// DefaultCowrieTTYDir Cowrie官方镜像中保存TTY日志的目录
+const DefaultCowrieTTYDir = "/cowrie/cowrie-git/var/lib/cowrie/tty"
+
+// DefaultTTYLogDir 本地保存TTY日志的默认目录
+const DefaultTTYLogDir = "data/ttylog"
+
+// Cowrie ttylog记录类型和方向，对应cowrie/core/ttylog.py
+const (
+	TTYLogOpOpen  = 1
+	TTYLogOpClose = 2
+	TTYLogOpWrite = 3
+	TTYLogOpExec  = 4
+
+	TTYLogDirectionInput    = 1
+	TTYLogDirectionOutput   = 2
+	TTYLogDirectionInteract = 3
+)
+
+// maxTTYLogSize 单个TTY日志(包括gzip解压后)的最大大小，超过的日志不会被收集或解析
+const maxTTYLogSize = 32 << 20
+
+// ttyLogHeaderSize ttylog记录头大小，结构为 <iLiiLL (op, tty, length, direction, sec, usec)
+const ttyLogHeaderSize = 24
+
+// 回放的默认终端尺寸，Cowrie的ttylog中不记录窗口大小
+const (
+	defaultReplayWidth  = 80
+	defaultReplayHeight = 24
+)
+
+// TTYLogRecord ttylog中的一条记录
+type TTYLogRecord struct {
+	Op        int
+	TTY       uint32
+	Direction int
+	Time      time.Time
+	Data      []byte
+}
+
+// ReplayFrame 回放中的一帧
+type ReplayFrame struct {
+	Offset float64 `json:"offset"` // 相对会话开始的秒数
+	Type   string  `json:"type"`   // o: 输出, i: 输入
+	Data   string  `json:"data"`
+}
+
+// SessionReplay 会话回放数据
+type SessionReplay struct {
+	SessionID   string        `json:"session_id"`
+	SourceIP    string        `json:"source_ip"`
+	ContainerID string        `json:"container_id"`
+	StartTime   time.Time     `json:"start_time"`
+	Duration    float64       `json:"duration"`
+	Width       int           `json:"width"`
+	Height      int           `json:"height"`
+	Frames      []ReplayFrame `json:"frames"`
+}
+
+// TTYLogDir 获取本地TTY日志目录，可通过COWRIE_TTYLOG_DIR环境变量覆盖
+func TTYLogDir() string {
+	if dir := os.Getenv("COWRIE_TTYLOG_DIR"); dir != "" {
+		return dir
+	}
+	return DefaultTTYLogDir
+}
+
+// GetSessionReplay 获取会话的TTY回放，本地尚未收集时从容器中拉取
+func (s *CowrieService) GetSessionReplay(sessionID string) (*SessionReplay, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+	ttyRepo := repositories.NewCowrieTTYLogRepo(config.DB)
+
+	events, err := s.Repo.GetBySessionID(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("获取会话日志失败: %v", err)
+	}
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	ttyLog, err := ttyRepo.GetBySessionID(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("获取TTY日志记录失败: %v", err)
+	}
+	if ttyLog == nil {
+		for _, event := range events {
+			if event.EventID == repositories.CowrieEventLogClosed && event.TTYLog != "" {
+				if ttyLog, err = collectTTYLog(ttyRepo, event); err != nil {
+					return nil, err
+				}
+				break
+			}
+		}
+	}
+	if ttyLog == nil {
+		return nil, fmt.Errorf("会话 %s 没有TTY日志", sessionID)
+	}
+
+	data, err := os.ReadFile(ttyLog.LocalPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取TTY日志失败: %v", err)
+	}
+	records, err := ParseTTYLog(data)
+	if err != nil {
+		return nil, err
+	}
+
+	replay := BuildSessionReplay(records)
+	replay.SessionID = sessionID
+	replay.SourceIP = events[0].SourceIP
+	replay.ContainerID = ttyLog.ContainerID
+	if replay.StartTime.IsZero() {
+		replay.StartTime = events[0].EventTime
+	}
+	return replay, nil
+}
+
+// collectCowrieTTYLogs 收集一批日志中log.closed事件引用的TTY日志
+func collectCowrieTTYLogs(containerID string, logs []repositories.CowrieLog) {
+	if config.DB == nil {
+		return
+	}
+	ttyRepo := repositories.NewCowrieTTYLogRepo(config.DB)
+
+	for _, log := range logs {
+		if log.EventID != repositories.CowrieEventLogClosed || log.TTYLog == "" || log.ID == 0 {
+			continue
+		}
+		if existing, err := ttyRepo.GetBySessionID(log.SessionID); err != nil || existing != nil {
+			continue
+		}
+		log.ContainerID = containerID
+		if _, err := collectTTYLog(ttyRepo, log); err != nil {
+			fmt.Printf("收集会话 %s 的TTY日志失败: %v\n", log.SessionID, err)
+		}
+	}
+}
+
+// collectTTYLog 从容器中拉取log.closed事件引用的TTY日志并保存到本地
+func collectTTYLog(ttyRepo repositories.CowrieTTYLogRepository, event repositories.CowrieLog) (*repositories.CowrieTTYLog, error) {
+	// Cowrie记录的ttylog是相对于安装目录的路径，文件名即内容的SHA256
+	containerPath := event.TTYLog
+	if !path.IsAbs(containerPath) {
+		containerPath = path.Join(DefaultCowrieTTYDir, path.Base(containerPath))
+	}
+
+	file, err := OpenContainerFile(event.ContainerID, containerPath)
+	if err != nil {
+		return nil, err
+	}
+	if file.Size > maxTTYLogSize {
+		file.Close()
+		return nil, fmt.Errorf("TTY日志 %s 过大(%d字节)", containerPath, file.Size)
+	}
+	data, err := readTTYLog(file.Reader)
+	file.Close()
+	if err != nil {
+		return nil, fmt.Errorf("读取TTY日志 %s 失败: %v", containerPath, err)
+	}
+
+	sum := sha256.Sum256(data)
+	shasum := hex.EncodeToString(sum[:])
+
+	dir := TTYLogDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("创建TTY日志目录失败: %v", err)
+	}
+	localPath := filepath.Join(dir, shasum)
+	if err := os.WriteFile(localPath, data, 0600); err != nil {
+		return nil, fmt.Errorf("保存TTY日志失败: %v", err)
+	}
+
+	ttyLog := &repositories.CowrieTTYLog{
+		SessionID:   event.SessionID,
+		CowrieLogID: event.ID,
+		ContainerID: event.ContainerID,
+		TTYLogPath:  containerPath,
+		LocalPath:   localPath,
+		Shasum:      shasum,
+		Size:        int64(len(data)),
+		Duration:    event.Duration,
+	}
+	if err := ttyRepo.Create(ttyLog); err != nil {
+		return nil, fmt.Errorf("保存TTY日志记录失败: %v", err)
+	}
+	return ttyLog, nil
+}
+
+// readTTYLog 读取TTY日志内容，超过maxTTYLogSize时返回错误，避免过大的日志或gzip炸弹占满内存
+func readTTYLog(reader io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, maxTTYLogSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxTTYLogSize {
+		return nil, fmt.Errorf("超过%d字节的大小上限", maxTTYLogSize)
+	}
+	return data, nil
+}
+
+// ParseTTYLog 解析Cowrie的二进制ttylog，支持gzip压缩的日志
+func ParseTTYLog(data []byte) ([]TTYLogRecord, error) {
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("解压TTY日志失败: %v", err)
+		}
+		defer gz.Close()
+		if data, err = readTTYLog(gz); err != nil {
+			return nil, fmt.Errorf("解压TTY日志失败: %v", err)
+		}
+	}
+
+	var records []TTYLogRecord
+	for offset := 0; offset < len(data); {
+		if len(data)-offset < ttyLogHeaderSize {
+			return records, fmt.Errorf("TTY日志在偏移 %d 处被截断", offset)
+		}
+		header := data[offset : offset+ttyLogHeaderSize]
+		length := int(int32(binary.LittleEndian.Uint32(header[8:12])))
+		if length < 0 {
+			return records, fmt.Errorf("TTY日志在偏移 %d 处的记录长度无效", offset)
+		}
+		offset += ttyLogHeaderSize
+		if len(data)-offset < length {
+			return records, fmt.Errorf("TTY日志在偏移 %d 处被截断", offset)
+		}
+
+		sec := binary.LittleEndian.Uint32(header[16:20])
+		usec := binary.LittleEndian.Uint32(header[20:24])
+		records = append(records, TTYLogRecord{
+			Op:        int(int32(binary.LittleEndian.Uint32(header[0:4]))),
+			TTY:       binary.LittleEndian.Uint32(header[4:8]),
+			Direction: int(int32(binary.LittleEndian.Uint32(header[12:16]))),
+			Time:      time.Unix(int64(sec), int64(usec)*int64(time.Microsecond)),
+			Data:      data[offset : offset+length],
+		})
+		offset += length
+	}
+	return records, nil
+}
+
+// BuildSessionReplay 将ttylog记录转换为按时间排列的回放帧
+func BuildSessionReplay(records []TTYLogRecord) *SessionReplay {
+	replay := &SessionReplay{
+		Width:  defaultReplayWidth,
+		Height: defaultReplayHeight,
+		Frames: []ReplayFrame{},
+	}
+	if len(records) == 0 {
+		return replay
+	}
+
+	replay.StartTime = records[0].Time
+	for _, record := range records {
+		offset := record.Time.Sub(replay.StartTime).Seconds()
+		if offset > replay.Duration {
+			replay.Duration = offset
+		}
+		if record.Op != TTYLogOpWrite || len(record.Data) == 0 {
+			continue
+		}
+
+		frameType := "o"
+		if record.Direction == TTYLogDirectionInput {
+			frameType = "i"
+		}
+		replay.Frames = append(replay.Frames, ReplayFrame{
+			Offset: offset,
+			Type:   frameType,
+			Data:   string(record.Data),
+		})
+	}
+	return replay
+}
+
+// asciicastHeader asciinema v2格式的文件头
+type asciicastHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Duration  float64           `json:"duration,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// ToAsciicast 导出为asciinema v2(.cast)格式，第一行为文件头，之后每行一个事件
+func (r *SessionReplay) ToAsciicast() ([]byte, error) {
+	var buf bytes.Buffer
+
+	header, err := json.Marshal(asciicastHeader{
+		Version:   2,
+		Width:     r.Width,
+		Height:    r.Height,
+		Timestamp: r.StartTime.Unix(),
+		Duration:  r.Duration,
+		Title:     fmt.Sprintf("cowrie session %s from %s", r.SessionID, r.SourceIP),
+		Env:       map[string]string{"TERM": "xterm", "SHELL": "/bin/bash"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	buf.Write(header)
+	buf.WriteByte('\n')
+
+	for _, frame := range r.Frames {
+		event, err := json.Marshal([]interface{}{frame.Offset, frame.Type, frame.Data})
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(event)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
@@ -5,6 +5,7 @@ import (
 	"andorralee/internal/repositories"
 	"encoding/json"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -14,128 +15,366 @@ import (
 
 // CowrieService Cowrie蜜罐日志服务
 type CowrieService struct {
-	Repo repositories.CowrieLogRepository
+	Repo   repositories.CowrieLogRepository
+	Reader *ContainerLogReader
 }
 
 // NewCowrieService 创建Cowrie服务
 func NewCowrieService() (*CowrieService, error) {
-	if config.MySQLDB == nil {
-		return nil, fmt.Errorf("MySQL数据库未初始化")
+	if config.DB == nil {
+		return nil, fmt.Errorf("数据库未初始化")
 	}
 
 	return &CowrieService{
-		Repo: repositories.NewMySQLCowrieLogRepo(config.MySQLDB),
+		Repo:   repositories.NewCowrieLogRepo(config.DB),
+		Reader: NewContainerLogReader(repositories.NewContainerLogCursorRepo(config.DB)),
 	}, nil
 }
 
+// init 注册Cowrie日志解析器
+func init() {
+	RegisterLogParser(NewLogParser(LogParserCowrie, isJSONLine, func(containerID string, lines []string) (int, error) {
+		service, err := NewCowrieService()
+		if err != nil {
+			return 0, err
+		}
+		return service.IngestLines(containerID, lines)
+	}), "cowrie")
+}
+
+// CowrieLogSource Cowrie日志在游标表中的来源标识
+const CowrieLogSource = "cowrie"
+
+// DefaultCowrieLogPath Cowrie官方镜像中JSON日志的默认路径
+const DefaultCowrieLogPath = "/cowrie/cowrie-git/var/log/cowrie/cowrie.json"
+
+// cowrieNamespace 用于根据原始日志行生成确定性AuthID的命名空间
+var cowrieNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("andorralee/cowrie"))
+
 // PullCowrieLogs 从容器中拉取Cowrie日志
-func (s *CowrieService) PullCowrieLogs(containerID string) error {
+// 通过Docker API读取容器内的cowrie.json，只处理上次拉取之后新增的行，已入库的记录会被跳过
+func (s *CowrieService) PullCowrieLogs(containerID, logPath string) (*IngestResult, error) {
 	if !IsDockerAvailable() {
-		return fmt.Errorf("Docker服务不可用")
+		return nil, fmt.Errorf("Docker服务不可用")
 	}
 
-	// 模拟JSON格式的Cowrie日志数据
-	// 实际项目中应该从容器中读取真实的日志文件
-	jsonLogs := []string{
-		`{"event_time":"2025-01-15T10:30:45.123456","auth_id":"550e8400-e29b-41d4-a716-446655440001","session_id":"550e8400-e29b-41d4-a716-446655440002","source_ip":"192.168.1.100","source_port":45678,"destination_ip":"172.17.0.2","destination_port":22,"protocol":"ssh","client_info":"SSH-2.0-OpenSSH_8.0","fingerprint":"92:65:ee:de:36:63:d9:f2:24:de:c4:84:ba:14:c3:42","username":"admin","password":"123456","command":"ls -la","command_found":true,"raw_log":"2025-01-15T10:30:45.123456Z [SSHChannel session (0) on SSHService b'ssh-connection' on SSHTransport,1,192.168.1.100] CMD: ls -la"}`,
-		`{"event_time":"2025-01-15T10:31:20.654321","auth_id":"550e8400-e29b-41d4-a716-446655440003","session_id":"550e8400-e29b-41d4-a716-446655440002","source_ip":"192.168.1.100","source_port":45678,"destination_ip":"172.17.0.2","destination_port":22,"protocol":"ssh","client_info":"SSH-2.0-OpenSSH_8.0","fingerprint":"92:65:ee:de:36:63:d9:f2:24:de:c4:84:ba:14:c3:42","username":"admin","password":"123456","command":"cat /etc/passwd","command_found":true,"raw_log":"2025-01-15T10:31:20.654321Z [SSHChannel session (0) on SSHService b'ssh-connection' on SSHTransport,1,192.168.1.100] CMD: cat /etc/passwd"}`,
-		`{"event_time":"2025-01-15T10:32:15.789012","auth_id":"550e8400-e29b-41d4-a716-446655440004","session_id":"550e8400-e29b-41d4-a716-446655440005","source_ip":"192.168.1.101","source_port":54321,"destination_ip":"172.17.0.2","destination_port":22,"protocol":"ssh","client_info":"SSH-2.0-libssh_0.8.9","fingerprint":"a1:b2:c3:d4:e5:f6:07:08:09:0a:0b:0c:0d:0e:0f:10","username":"root","password":"password","command":"whoami","command_found":true,"raw_log":"2025-01-15T10:32:15.789012Z [SSHChannel session (0) on SSHService b'ssh-connection' on SSHTransport,2,192.168.1.101] CMD: whoami"}`,
+	if logPath == "" {
+		logPath = CowrieLogPath()
 	}
 
-	// 解析JSON日志
-	logs, err := s.parseJSONLogs(jsonLogs, containerID)
+	lines, cursor, err := s.Reader.ReadNewLines(containerID, CowrieLogSource, logPath)
 	if err != nil {
-		return fmt.Errorf("解析JSON日志失败: %v", err)
+		return nil, fmt.Errorf("读取Cowrie日志失败: %v", err)
 	}
 
-	// 批量保存到数据库
+	logs, result, err := s.saveLogs(lines, containerID)
+	if err != nil {
+		return nil, err
+	}
 	if len(logs) > 0 {
-		if err := s.Repo.CreateBatch(logs); err != nil {
-			return fmt.Errorf("保存日志到数据库失败: %v", err)
+		collectCowrieArtifacts(containerID, logs)
+		cursor.LastRecordID = logs[len(logs)-1].AuthID
+	}
+	fmt.Printf("容器 %s 的Cowrie日志拉取完成: 新增 %d 条, 跳过 %d 条, 无法解析 %d 条\n",
+		containerID, result.Inserted, result.Skipped, result.Rejected)
+
+	// 日志入库后再推进游标，保证失败时下次可以重新读取
+	if err := s.Reader.Commit(cursor); err != nil {
+		return result, fmt.Errorf("保存日志游标失败: %v", err)
+	}
+
+	return result, nil
+}
+
+// IngestLines 解析并保存一批Cowrie JSON日志行，供日志跟随采集等场景复用，返回新插入的条数
+func (s *CowrieService) IngestLines(containerID string, lines []string) (int, error) {
+	logs, result, err := s.saveLogs(lines, containerID)
+	if err != nil {
+		return 0, err
+	}
+	collectCowrieArtifacts(containerID, logs)
+	return result.Inserted, nil
+}
+
+// collectCowrieArtifacts 日志入库后收集事件引用的容器内文件(下载样本、TTY日志)，失败不影响日志入库
+func collectCowrieArtifacts(containerID string, logs []repositories.CowrieLog) {
+	captureCowrieMalware(containerID, logs)
+	collectCowrieTTYLogs(containerID, logs)
+}
+
+// saveLogs 解析JSON日志行并批量保存到数据库，auth_id已存在的记录计为跳过
+func (s *CowrieService) saveLogs(lines []string, containerID string) ([]repositories.CowrieLog, *IngestResult, error) {
+	logs, rejected, err := s.parseJSONLogs(lines, containerID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("解析JSON日志失败: %v", err)
+	}
+	unmatched := markFailedCommands(logs)
+
+	inserted, err := s.Repo.CreateBatch(logs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("保存日志到数据库失败: %v", err)
+	}
+	// command.input在之前批次中入库的命令，修改已入库的记录
+	for _, sessionID := range unmatched {
+		if err := s.Repo.MarkLastCommandNotFound(containerID, sessionID); err != nil {
+			return nil, nil, fmt.Errorf("更新命令识别状态失败: %v", err)
 		}
-		fmt.Printf("成功从容器 %s 拉取并保存了 %d 条Cowrie日志\n", containerID, len(logs))
-	} else {
-		fmt.Printf("容器 %s 没有新的Cowrie日志\n", containerID)
 	}
+	if inserted > 0 {
+		recordTimeline(TimelineEventsFromCowrie(logs))
+	}
+	return logs, &IngestResult{
+		Inserted: int(inserted),
+		Skipped:  len(logs) - int(inserted),
+		Rejected: rejected,
+	}, nil
+}
 
-	return nil
+// CowrieLogPath 获取容器内Cowrie JSON日志路径，可通过COWRIE_LOG_PATH环境变量覆盖
+func CowrieLogPath() string {
+	if p := os.Getenv("COWRIE_LOG_PATH"); p != "" {
+		return p
+	}
+	return DefaultCowrieLogPath
 }
 
-// parseJSONLogs 解析JSON格式的日志
-func (s *CowrieService) parseJSONLogs(jsonLogs []string, containerID string) ([]repositories.CowrieLog, error) {
+// parseJSONLogs 解析Cowrie输出的JSON格式日志(每行一个事件)，返回解析出的日志和无法解析的行数
+func (s *CowrieService) parseJSONLogs(jsonLogs []string, containerID string) ([]repositories.CowrieLog, int, error) {
 	var logs []repositories.CowrieLog
+	rejected := 0
+
+	// 获取容器名称
+	containerName := s.getContainerName(containerID)
+
+	// 会话的协议和目标地址只出现在session.connect事件中，同一批次内直接传递，
+	// 日志跟随和增量拉取都是分批入库的，session.connect在之前批次中的会话从已入库的记录中补充
+	sessions := s.loadCowrieSessions(containerID, jsonLogs)
 
 	for i, jsonLog := range jsonLogs {
-		var logData map[string]interface{}
-		if err := json.Unmarshal([]byte(jsonLog), &logData); err != nil {
-			fmt.Printf("跳过JSON解析失败的记录 %d: %v\n", i+1, err)
+		log, err := parseCowrieEvent(jsonLog, sessions)
+		if err != nil {
+			fmt.Printf("跳过解析失败的记录 %d: %v\n", i+1, err)
+			rejected++
 			continue
 		}
 
-		// 解析时间戳
-		eventTimeStr, ok := logData["event_time"].(string)
-		if !ok {
-			fmt.Printf("跳过时间戳格式错误的记录 %d\n", i+1)
-			continue
+		// 原始日志行决定AuthID，同一行重复拉取时ID保持不变，入库时按auth_id去重
+		log.AuthID = uuid.NewSHA1(cowrieNamespace, []byte(containerID+"|"+jsonLog)).String()
+
+		log.ContainerID = containerID
+		log.ContainerName = containerName
+		logs = append(logs, *log)
+	}
+
+	return logs, rejected, nil
+}
+
+// markFailedCommands 将command.failed事件之前同一会话中最近的command.input标记为未识别，
+// Cowrie在命令输入后立即记录command.failed，最近的命令输入就是未识别的那一条。
+// 返回本批次中找不到对应命令输入的会话ID
+func markFailedCommands(logs []repositories.CowrieLog) []string {
+	lastInput := make(map[string]int)
+	var unmatched []string
+	for i := range logs {
+		switch logs[i].EventID {
+		case repositories.CowrieEventCommandInput:
+			lastInput[logs[i].SessionID] = i
+		case repositories.CowrieEventCommandFailed:
+			if j, ok := lastInput[logs[i].SessionID]; ok {
+				found := false
+				logs[j].CommandFound = &found
+			} else {
+				unmatched = append(unmatched, logs[i].SessionID)
+			}
 		}
+	}
+	return unmatched
+}
 
-		eventTime, err := time.Parse("2006-01-02T15:04:05.999999", eventTimeStr)
-		if err != nil {
-			fmt.Printf("跳过时间戳解析失败的记录 %d: %v\n", i+1, err)
+// cowrieSessionInfo 从session.connect事件中获得的会话信息
+type cowrieSessionInfo struct {
+	protocol        string
+	destinationIP   string
+	destinationPort uint16
+}
+
+// loadCowrieSessions 查询本批次中没有session.connect事件的会话已入库的连接信息，查询失败时按事件自身的字段推断
+func (s *CowrieService) loadCowrieSessions(containerID string, jsonLogs []string) map[string]*cowrieSessionInfo {
+	sessions := make(map[string]*cowrieSessionInfo)
+
+	seen := make(map[string]bool)
+	var missing []string
+	for _, jsonLog := range jsonLogs {
+		var event struct {
+			EventID string `json:"eventid"`
+			Session string `json:"session"`
+		}
+		if json.Unmarshal([]byte(jsonLog), &event) != nil || event.Session == "" || seen[event.Session] {
 			continue
 		}
-
-		// 检查是否已存在（根据auth_id）
-		authID := getString(logData, "auth_id")
-		if authID == "" {
-			authID = uuid.New().String()
+		seen[event.Session] = true
+		if event.EventID != repositories.CowrieEventSessionConnect {
+			missing = append(missing, event.Session)
 		}
+	}
+	if len(missing) == 0 {
+		return sessions
+	}
 
-		existing, _ := s.Repo.GetByAuthID(authID)
-		if existing != nil {
-			continue // 跳过已存在的记录
+	connects, err := s.Repo.GetSessionConnects(containerID, missing)
+	if err != nil {
+		fmt.Printf("查询Cowrie会话连接信息失败: %v\n", err)
+		return sessions
+	}
+	for _, connect := range connects {
+		sessions[connect.SessionID] = &cowrieSessionInfo{
+			protocol:        connect.Protocol,
+			destinationIP:   connect.DestinationIP,
+			destinationPort: connect.DestinationPort,
 		}
+	}
+	return sessions
+}
 
-		// 获取容器名称
-		containerName := s.getContainerName(containerID)
+// parseCowrieEvent 将一行Cowrie JSON事件解析为日志记录，按eventid填充各事件特有的字段
+func parseCowrieEvent(jsonLog string, sessions map[string]*cowrieSessionInfo) (*repositories.CowrieLog, error) {
+	var logData map[string]interface{}
+	if err := json.Unmarshal([]byte(jsonLog), &logData); err != nil {
+		return nil, fmt.Errorf("JSON解析失败: %v", err)
+	}
 
-		// 解析端口号
-		sourcePort := uint16(getInt(logData, "source_port"))
-		destinationPort := uint16(getInt(logData, "destination_port"))
+	eventTime, err := parseCowrieTime(getString(logData, "timestamp"))
+	if err != nil {
+		return nil, fmt.Errorf("时间戳解析失败: %v", err)
+	}
 
-		// 解析command_found
-		var commandFound *bool
-		if val, exists := logData["command_found"]; exists {
-			if boolVal, ok := val.(bool); ok {
-				commandFound = &boolVal
-			}
-		}
+	eventID := getString(logData, "eventid")
+	sessionID := getString(logData, "session")
+
+	log := &repositories.CowrieLog{
+		EventID:    eventID,
+		EventTime:  eventTime,
+		SessionID:  sessionID,
+		SourceIP:   getString(logData, "src_ip"),
+		SourcePort: uint16(getInt(logData, "src_port")),
+		Message:    getString(logData, "message"),
+		RawLog:     jsonLog,
+	}
 
-		log := repositories.CowrieLog{
-			EventTime:       eventTime,
-			AuthID:          authID,
-			SessionID:       getString(logData, "session_id"),
-			SourceIP:        getString(logData, "source_ip"),
-			SourcePort:      sourcePort,
-			DestinationIP:   getString(logData, "destination_ip"),
-			DestinationPort: destinationPort,
-			Protocol:        getString(logData, "protocol"),
-			ClientInfo:      getString(logData, "client_info"),
-			Fingerprint:     getString(logData, "fingerprint"),
-			Username:        getString(logData, "username"),
-			Password:        getString(logData, "password"),
-			PasswordHash:    getString(logData, "password_hash"),
-			Command:         getString(logData, "command"),
-			CommandFound:    commandFound,
-			RawLog:          getString(logData, "raw_log"),
-			ContainerID:     containerID,
-			ContainerName:   containerName,
-		}
+	session := sessions[sessionID]
+	if session == nil {
+		session = &cowrieSessionInfo{}
+		sessions[sessionID] = session
+	}
+
+	switch eventID {
+	case repositories.CowrieEventSessionConnect:
+		session.protocol = normalizeCowrieProtocol(getString(logData, "protocol"))
+		session.destinationIP = getString(logData, "dst_ip")
+		session.destinationPort = uint16(getInt(logData, "dst_port"))
+	case repositories.CowrieEventSessionClosed:
+		log.Duration = getFloat(logData, "duration")
+	case repositories.CowrieEventLoginSuccess, repositories.CowrieEventLoginFailed:
+		log.Username = getString(logData, "username")
+		log.Password = getString(logData, "password")
+	case repositories.CowrieEventCommandInput:
+		// 未识别的命令随后会有一条command.failed事件，收到时再改为false
+		log.Command = getString(logData, "input")
+		found := true
+		log.CommandFound = &found
+	case repositories.CowrieEventCommandFailed:
+		log.Command = getString(logData, "input")
+		found := false
+		log.CommandFound = &found
+	case repositories.CowrieEventClientVersion:
+		log.ClientInfo = getString(logData, "version")
+	case repositories.CowrieEventClientKex:
+		log.Hassh = getString(logData, "hassh")
+		log.KexAlgorithms = strings.Join(getStringSlice(logData, "kexAlgs"), ",")
+	case "cowrie.client.fingerprint":
+		log.Username = getString(logData, "username")
+		log.Fingerprint = getString(logData, "fingerprint")
+	case repositories.CowrieEventFileDownload:
+		log.URL = getString(logData, "url")
+		log.Shasum = getString(logData, "shasum")
+		log.Outfile = getString(logData, "outfile")
+	case repositories.CowrieEventFileUpload:
+		log.Filename = getString(logData, "filename")
+		log.Shasum = getString(logData, "shasum")
+		log.Outfile = getString(logData, "outfile")
+	case repositories.CowrieEventLogClosed:
+		log.TTYLog = getString(logData, "ttylog")
+		log.Shasum = getString(logData, "shasum")
+		log.Duration = getFloat(logData, "duration")
+	case repositories.CowrieEventDirectTCPIP, repositories.CowrieEventDirectTCPIPData:
+		// direct-tcpip事件中的dst_ip/dst_port是端口转发的目标，而不是蜜罐地址
+		log.TunnelDestIP = getString(logData, "dst_ip")
+		log.TunnelDestPort = uint16(getInt(logData, "dst_port"))
+		log.TunnelData = getString(logData, "data")
+	}
+
+	// 目标地址和协议以会话建立时的信息为准
+	log.DestinationIP = session.destinationIP
+	log.DestinationPort = session.destinationPort
+	if log.DestinationIP == "" && !strings.HasPrefix(eventID, "cowrie.direct-tcpip") {
+		log.DestinationIP = getString(logData, "dst_ip")
+		log.DestinationPort = uint16(getInt(logData, "dst_port"))
+	}
+
+	log.Protocol = session.protocol
+	if log.Protocol == "" {
+		log.Protocol = normalizeCowrieProtocol(getString(logData, "protocol"))
+	}
+	if log.Protocol == "" {
+		log.Protocol = protocolByPort(log.DestinationPort)
+	}
+
+	return log, nil
+}
+
+// parseCowrieTime 解析Cowrie事件时间戳(RFC3339，带微秒)
+func parseCowrieTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("缺少timestamp字段")
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02T15:04:05.999999", value)
+}
 
-		logs = append(logs, log)
+// normalizeCowrieProtocol 将Cowrie的协议名称映射到cowrie_log表支持的取值
+func normalizeCowrieProtocol(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "":
+		return ""
+	case "ssh", "telnet", "http", "ftp", "smb":
+		return strings.ToLower(protocol)
+	default:
+		return "other"
 	}
+}
 
-	return logs, nil
+// protocolByPort 根据目标端口推断协议
+func protocolByPort(port uint16) string {
+	switch port {
+	case 22, 2222:
+		return "ssh"
+	case 23, 2223, 2323:
+		return "telnet"
+	default:
+		return "other"
+	}
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
 }
 
 // 辅助函数：从map中获取字符串值
@@ -165,6 +404,36 @@ func getInt(data map[string]interface{}, key string) int {
 	return 0
 }
 
+// 辅助函数：从map中获取浮点数值
+func getFloat(data map[string]interface{}, key string) float64 {
+	if val, exists := data[key]; exists {
+		switch v := val.(type) {
+		case float64:
+			return v
+		case string:
+			if floatVal, err := strconv.ParseFloat(v, 64); err == nil {
+				return floatVal
+			}
+		}
+	}
+	return 0
+}
+
+// 辅助函数：从map中获取字符串数组
+func getStringSlice(data map[string]interface{}, key string) []string {
+	var result []string
+	if val, exists := data[key]; exists {
+		if items, ok := val.([]interface{}); ok {
+			for _, item := range items {
+				if strVal, ok := item.(string); ok {
+					result = append(result, strVal)
+				}
+			}
+		}
+	}
+	return result
+}
+
 // getContainerName 获取容器名称
 func (s *CowrieService) getContainerName(containerID string) string {
 	if !IsDockerAvailable() {
@@ -214,14 +483,57 @@ func (s *CowrieService) GetLogsByUsername(username string) ([]repositories.Cowri
 	return s.Repo.GetByUsername(username)
 }
 
-// GetStatistics 获取Cowrie统计信息
-func (s *CowrieService) GetStatistics() ([]repositories.CowrieStatistics, error) {
-	return s.Repo.GetStatistics()
+// GetLogsByEventID 获取指定事件类型的Cowrie日志
+func (s *CowrieService) GetLogsByEventID(eventID string) ([]repositories.CowrieLog, error) {
+	return s.Repo.GetByEventID(eventID)
+}
+
+// GetLoginEvents 获取登录成功或失败的事件
+func (s *CowrieService) GetLoginEvents(success bool) ([]repositories.CowrieLog, error) {
+	return s.Repo.GetLoginEvents(success)
+}
+
+// GetCommandEvents 获取命令事件
+func (s *CowrieService) GetCommandEvents() ([]repositories.CowrieLog, error) {
+	return s.Repo.GetCommandEvents()
 }
 
-// GetAttackerBehavior 获取攻击者行为统计信息
-func (s *CowrieService) GetAttackerBehavior() ([]repositories.CowrieAttackerBehavior, error) {
-	return s.Repo.GetAttackerBehavior()
+// GetFileTransferEvents 获取文件传输事件
+func (s *CowrieService) GetFileTransferEvents() ([]repositories.CowrieLog, error) {
+	return s.Repo.GetFileTransferEvents()
+}
+
+// GetDirectTCPIPEvents 获取端口转发事件
+func (s *CowrieService) GetDirectTCPIPEvents() ([]repositories.CowrieLog, error) {
+	return s.Repo.GetDirectTCPIPEvents()
+}
+
+// GetEventStatistics 获取按事件类型的统计信息
+func (s *CowrieService) GetEventStatistics() ([]repositories.CowrieEventStatistics, error) {
+	return s.Repo.GetEventStatistics()
+}
+
+// NormalizeCowrieEventID 规范化事件类型，允许省略"cowrie."前缀
+func NormalizeCowrieEventID(eventID string) (string, bool) {
+	if !strings.HasPrefix(eventID, "cowrie.") {
+		eventID = "cowrie." + eventID
+	}
+	for _, t := range repositories.CowrieEventTypes {
+		if t == eventID {
+			return eventID, true
+		}
+	}
+	return eventID, false
+}
+
+// GetStatistics 按日期和协议获取Cowrie统计信息
+func (s *CowrieService) GetStatistics(filter repositories.StatisticsFilter) ([]repositories.CowrieStatistics, error) {
+	return s.Repo.GetStatistics(filter)
+}
+
+// GetAttackerBehavior 获取攻击者行为统计信息，filter.Limit大于0时只返回前N个攻击者
+func (s *CowrieService) GetAttackerBehavior(filter repositories.StatisticsFilter) ([]repositories.CowrieAttackerBehavior, error) {
+	return s.Repo.GetAttackerBehavior(filter)
 }
 
 // GetTopAttackers 获取前N个攻击者
@@ -229,9 +541,9 @@ func (s *CowrieService) GetTopAttackers(limit int) ([]repositories.CowrieAttacke
 	return s.Repo.GetTopAttackers(limit)
 }
 
-// GetCommandStatistics 获取命令统计信息
-func (s *CowrieService) GetCommandStatistics() ([]repositories.CowrieCommandStatistics, error) {
-	return s.Repo.GetCommandStatistics()
+// GetCommandStatistics 获取命令统计信息，filter.Limit大于0时只返回前N个命令
+func (s *CowrieService) GetCommandStatistics(filter repositories.StatisticsFilter) ([]repositories.CowrieCommandStatistics, error) {
+	return s.Repo.GetCommandStatistics(filter)
 }
 
 // GetTopCommands 获取最常用的命令
@@ -279,6 +591,11 @@ func (s *CowrieService) GetAllLogs() ([]repositories.CowrieLog, error) {
 	return s.Repo.List()
 }
 
+// GetLogsPage 按查询条件分页获取Cowrie日志，同时返回符合条件的总数
+func (s *CowrieService) GetLogsPage(spec repositories.QuerySpec) (*repositories.Page[repositories.CowrieLog], error) {
+	return s.Repo.Query(spec)
+}
+
 // GetLogByID 根据ID获取Cowrie日志
 func (s *CowrieService) GetLogByID(id uint) (*repositories.CowrieLog, error) {
 	return s.Repo.GetByID(id)
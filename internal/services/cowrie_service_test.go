@@ -0,0 +1,170 @@
+package services
+
+import (
+	"andorralee/internal/repositories"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+// TestParseCowrieEvent 测试按事件类型解析Cowrie日志
+func TestParseCowrieEvent(t *testing.T) {
+	sessions := make(map[string]*cowrieSessionInfo)
+	lines := []string{
+		`{"eventid":"cowrie.session.connect","timestamp":"2024-05-01T10:00:00.000000Z","session":"a1","src_ip":"1.2.3.4","src_port":50000,"dst_ip":"10.0.0.2","dst_port":2222,"protocol":"ssh"}`,
+		`{"eventid":"cowrie.login.failed","timestamp":"2024-05-01T10:00:01.000000Z","session":"a1","src_ip":"1.2.3.4","username":"root","password":"123456"}`,
+		`{"eventid":"cowrie.login.success","timestamp":"2024-05-01T10:00:02.000000Z","session":"a1","src_ip":"1.2.3.4","username":"root","password":"admin"}`,
+		`{"eventid":"cowrie.direct-tcpip.request","timestamp":"2024-05-01T10:00:03.000000Z","session":"a1","src_ip":"1.2.3.4","dst_ip":"8.8.8.8","dst_port":53}`,
+	}
+
+	var logs []*repositories.CowrieLog
+	for _, line := range lines {
+		log, err := parseCowrieEvent(line, sessions)
+		if err != nil {
+			t.Fatalf("解析失败: %v", err)
+		}
+		logs = append(logs, log)
+	}
+
+	if logs[1].EventID != repositories.CowrieEventLoginFailed || logs[2].EventID != repositories.CowrieEventLoginSuccess {
+		t.Errorf("登录成功和失败事件应能区分，实际得到 %s, %s", logs[1].EventID, logs[2].EventID)
+	}
+	if logs[1].Protocol != "ssh" || logs[1].DestinationPort != 2222 {
+		t.Errorf("后续事件应继承会话的协议和目标端口，实际得到 %s:%d", logs[1].Protocol, logs[1].DestinationPort)
+	}
+	if logs[3].TunnelDestIP != "8.8.8.8" || logs[3].TunnelDestPort != 53 || logs[3].DestinationIP != "10.0.0.2" {
+		t.Errorf("端口转发目标不应覆盖蜜罐地址，实际得到 %+v", logs[3])
+	}
+}
+
+// TestCowrieSessionAcrossBatches 测试session.connect在之前批次入库时，后续批次的事件从已入库的连接记录中获得协议和目标地址
+func TestCowrieSessionAcrossBatches(t *testing.T) {
+	useSQLiteDatabase(t)
+
+	service, err := NewCowrieService()
+	if err != nil {
+		t.Fatalf("创建Cowrie服务失败: %v", err)
+	}
+	batches := [][]string{
+		{`{"eventid":"cowrie.session.connect","timestamp":"2024-05-01T10:00:00.000000Z","session":"b1","src_ip":"1.2.3.4","src_port":50000,"dst_ip":"10.0.0.2","dst_port":2223,"protocol":"telnet"}`},
+		{`{"eventid":"cowrie.login.failed","timestamp":"2024-05-01T10:00:01.000000Z","session":"b1","src_ip":"1.2.3.4","username":"root","password":"123456"}`},
+	}
+	for _, batch := range batches {
+		if _, _, err := service.saveLogs(batch, "c1"); err != nil {
+			t.Fatalf("保存日志失败: %v", err)
+		}
+	}
+
+	logs, err := service.Repo.GetLoginEvents(false)
+	if err != nil || len(logs) != 1 {
+		t.Fatalf("获取登录事件失败: %+v err=%v", logs, err)
+	}
+	if logs[0].Protocol != "telnet" || logs[0].DestinationIP != "10.0.0.2" || logs[0].DestinationPort != 2223 {
+		t.Errorf("后续批次的事件应继承已入库会话的协议和目标地址，实际得到 %s %s:%d", logs[0].Protocol, logs[0].DestinationIP, logs[0].DestinationPort)
+	}
+}
+
+// TestCowrieCommandFound 测试命令输入默认为已识别，随后有command.failed时改为未识别(包括command.failed在下一批次中的情况)，
+// 命令统计按命令分组，不把command.failed拆成单独的一组
+func TestCowrieCommandFound(t *testing.T) {
+	useSQLiteDatabase(t)
+
+	service, err := NewCowrieService()
+	if err != nil {
+		t.Fatalf("创建Cowrie服务失败: %v", err)
+	}
+	batches := [][]string{
+		{
+			`{"eventid":"cowrie.command.input","timestamp":"2024-05-01T10:00:01.000000Z","session":"c1","src_ip":"1.2.3.4","input":"uname -a"}`,
+			`{"eventid":"cowrie.command.input","timestamp":"2024-05-01T10:00:02.000000Z","session":"c1","src_ip":"1.2.3.4","input":"foo"}`,
+			`{"eventid":"cowrie.command.failed","timestamp":"2024-05-01T10:00:02.000100Z","session":"c1","src_ip":"1.2.3.4","input":"foo"}`,
+			`{"eventid":"cowrie.command.input","timestamp":"2024-05-01T10:00:03.000000Z","session":"c1","src_ip":"1.2.3.4","input":"bar"}`,
+		},
+		{`{"eventid":"cowrie.command.failed","timestamp":"2024-05-01T10:00:03.000100Z","session":"c1","src_ip":"1.2.3.4","input":"bar"}`},
+	}
+	for _, batch := range batches {
+		if _, _, err := service.saveLogs(batch, "c1"); err != nil {
+			t.Fatalf("保存日志失败: %v", err)
+		}
+	}
+
+	found, err := service.GetLogsByCommandFound(true)
+	if err != nil || len(found) != 1 || found[0].Command != "uname -a" {
+		t.Fatalf("已识别的命令错误: %+v err=%v", found, err)
+	}
+	notFound, err := service.GetLogsByCommandFound(false)
+	if err != nil || len(notFound) != 4 {
+		t.Fatalf("未识别的命令应包括两条命令输入和两条command.failed: %+v err=%v", notFound, err)
+	}
+
+	commands, err := service.Repo.GetTopCommands(10)
+	if err != nil || len(commands) != 3 {
+		t.Fatalf("命令统计应按命令分为3组: %+v err=%v", commands, err)
+	}
+	for _, command := range commands {
+		if command.UsageCount != 1 || command.CommandFound != (command.Command == "uname -a") {
+			t.Errorf("命令统计错误: %+v", command)
+		}
+	}
+}
+
+// TestParseTTYLog 测试解析Cowrie的ttylog并导出asciicast
+func TestParseTTYLog(t *testing.T) {
+	var buf bytes.Buffer
+	writeRecord := func(op, direction int32, sec, usec uint32, data string) {
+		binary.Write(&buf, binary.LittleEndian, op)
+		binary.Write(&buf, binary.LittleEndian, uint32(1))
+		binary.Write(&buf, binary.LittleEndian, int32(len(data)))
+		binary.Write(&buf, binary.LittleEndian, direction)
+		binary.Write(&buf, binary.LittleEndian, sec)
+		binary.Write(&buf, binary.LittleEndian, usec)
+		buf.WriteString(data)
+	}
+	writeRecord(TTYLogOpOpen, 0, 1714557600, 0, "")
+	writeRecord(TTYLogOpWrite, TTYLogDirectionOutput, 1714557600, 500000, "root@svr04:~# ")
+	writeRecord(TTYLogOpWrite, TTYLogDirectionInput, 1714557602, 0, "uname -a\r")
+	writeRecord(TTYLogOpClose, 0, 1714557603, 0, "")
+
+	records, err := ParseTTYLog(buf.Bytes())
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("期望4条记录，实际得到%d条", len(records))
+	}
+
+	replay := BuildSessionReplay(records)
+	if len(replay.Frames) != 2 || replay.Frames[0].Type != "o" || replay.Frames[1].Type != "i" {
+		t.Errorf("回放帧错误: %+v", replay.Frames)
+	}
+	if replay.Frames[0].Offset != 0.5 || replay.Duration != 3 {
+		t.Errorf("时间偏移错误: offset=%v duration=%v", replay.Frames[0].Offset, replay.Duration)
+	}
+
+	cast, err := replay.ToAsciicast()
+	if err != nil {
+		t.Fatalf("导出失败: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(cast)), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], `"version":2`) || lines[1] != `[0.5,"o","root@svr04:~# "]` {
+		t.Errorf("asciicast格式错误: %s", cast)
+	}
+
+	if _, err := ParseTTYLog(buf.Bytes()[:30]); err == nil {
+		t.Error("截断的ttylog应返回错误")
+	}
+}
+
+// TestParseTTYLogSizeLimit 测试解压后超过大小上限的TTY日志返回错误，不会全部读入内存
+func TestParseTTYLogSizeLimit(t *testing.T) {
+	var buf bytes.Buffer
+	gz, _ := gzip.NewWriterLevel(&buf, gzip.BestSpeed)
+	gz.Write(make([]byte, maxTTYLogSize+1))
+	gz.Close()
+
+	if _, err := ParseTTYLog(buf.Bytes()); err == nil || !strings.Contains(err.Error(), "大小上限") {
+		t.Errorf("超过大小上限的TTY日志应返回错误: %v", err)
+	}
+}
@@ -0,0 +1,363 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/repositories"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DionaeaLogSource Dionaea日志在游标表中的来源标识
+const DionaeaLogSource = "dionaea"
+
+// DefaultDionaeaLogPath dinotools/dionaea镜像中log_json处理器输出的默认路径
+// Dionaea的log_sqlite输出需要SQLite驱动，目前只支持log_json格式
+const DefaultDionaeaLogPath = "/opt/dionaea/var/lib/dionaea/dionaea.json"
+
+// dionaeaNamespace 用于根据原始日志行生成确定性ID的命名空间
+var dionaeaNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("andorralee/dionaea"))
+
+// DionaeaService Dionaea蜜罐日志服务
+type DionaeaService struct {
+	Repo   repositories.DionaeaLogRepository
+	Reader *ContainerLogReader
+}
+
+// NewDionaeaService 创建Dionaea服务
+func NewDionaeaService() (*DionaeaService, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	return &DionaeaService{
+		Repo:   repositories.NewDionaeaLogRepo(config.DB),
+		Reader: NewContainerLogReader(repositories.NewContainerLogCursorRepo(config.DB)),
+	}, nil
+}
+
+// init 注册Dionaea日志解析器
+func init() {
+	RegisterLogParser(dionaeaLogParser{NewLogParser(LogParserDionaea, isJSONLine, func(containerID string, lines []string) (int, error) {
+		service, err := NewDionaeaService()
+		if err != nil {
+			return 0, err
+		}
+		return service.IngestLines(containerID, lines)
+	})}, "dionaea")
+}
+
+// dionaeaLogParser Dionaea只把结构化日志写入文件，标准输出中只有运行日志，需要轮询日志文件
+type dionaeaLogParser struct {
+	LogParser
+}
+
+// Poll 按游标拉取容器内的Dionaea日志文件
+func (p dionaeaLogParser) Poll(containerID string) (int, error) {
+	service, err := NewDionaeaService()
+	if err != nil {
+		return 0, err
+	}
+	result, err := service.PullDionaeaLogs(containerID, "")
+	if err != nil {
+		return 0, err
+	}
+	return result.Inserted, nil
+}
+
+// DionaeaLogPath 获取容器内Dionaea JSON日志路径，可通过DIONAEA_LOG_PATH环境变量覆盖
+func DionaeaLogPath() string {
+	if p := os.Getenv("DIONAEA_LOG_PATH"); p != "" {
+		return p
+	}
+	return DefaultDionaeaLogPath
+}
+
+// PullDionaeaLogs 从容器中拉取Dionaea日志
+// 通过Docker API读取容器内log_json输出的文件，只处理上次拉取之后新增的行，已入库的记录会被跳过
+func (s *DionaeaService) PullDionaeaLogs(containerID, logPath string) (*IngestResult, error) {
+	if !IsDockerAvailable() {
+		return nil, fmt.Errorf("Docker服务不可用")
+	}
+
+	if logPath == "" {
+		logPath = DionaeaLogPath()
+	}
+
+	lines, cursor, err := s.Reader.ReadNewLines(containerID, DionaeaLogSource, logPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取Dionaea日志失败: %v", err)
+	}
+
+	logs, result, err := s.saveLogs(lines, containerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(logs) > 0 {
+		cursor.LastRecordID = logs[len(logs)-1].AuthID
+	}
+	if result.Inserted > 0 || result.Rejected > 0 {
+		fmt.Printf("容器 %s 的Dionaea日志拉取完成: 新增 %d 条, 跳过 %d 条, 无法解析 %d 条\n",
+			containerID, result.Inserted, result.Skipped, result.Rejected)
+	}
+
+	// 日志入库后再推进游标，保证失败时下次可以重新读取
+	if err := s.Reader.Commit(cursor); err != nil {
+		return result, fmt.Errorf("保存日志游标失败: %v", err)
+	}
+
+	return result, nil
+}
+
+// IngestLines 解析并保存一批Dionaea JSON日志行，返回新插入的条数
+func (s *DionaeaService) IngestLines(containerID string, lines []string) (int, error) {
+	_, result, err := s.saveLogs(lines, containerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.Inserted, nil
+}
+
+// saveLogs 解析JSON日志行并批量保存到数据库，auth_id已存在的记录计为跳过
+func (s *DionaeaService) saveLogs(lines []string, containerID string) ([]repositories.DionaeaLog, *IngestResult, error) {
+	containerName := s.getContainerName(containerID)
+
+	var logs []repositories.DionaeaLog
+	rejected := 0
+	for i, line := range lines {
+		events, err := parseDionaeaIncident(line, containerID)
+		if err != nil {
+			fmt.Printf("跳过解析失败的记录 %d: %v\n", i+1, err)
+			rejected++
+			continue
+		}
+		for _, event := range events {
+			event.ContainerName = containerName
+			logs = append(logs, event)
+		}
+	}
+
+	inserted, err := s.Repo.CreateBatch(logs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("保存日志到数据库失败: %v", err)
+	}
+	if inserted > 0 {
+		recordTimeline(TimelineEventsFromDionaea(logs))
+	}
+	return logs, &IngestResult{
+		Inserted: int(inserted),
+		Skipped:  len(logs) - int(inserted),
+		Rejected: rejected,
+	}, nil
+}
+
+// dionaeaIncident Dionaea log_json处理器输出的一条连接记录
+type dionaeaIncident struct {
+	Timestamp  string `json:"timestamp"`
+	SrcIP      string `json:"src_ip"`
+	SrcPort    int    `json:"src_port"`
+	DstIP      string `json:"dst_ip"`
+	DstPort    int    `json:"dst_port"`
+	Connection struct {
+		Protocol  string `json:"protocol"`
+		Transport string `json:"transport"`
+		Type      string `json:"type"`
+	} `json:"connection"`
+	Credentials struct {
+		Username []string `json:"username"`
+		Password []string `json:"password"`
+	} `json:"credentials"`
+	Downloads []struct {
+		URL     string `json:"url"`
+		MD5Hash string `json:"md5_hash"`
+	} `json:"downloads"`
+	FTP struct {
+		Commands []struct {
+			Command   string   `json:"command"`
+			Arguments []string `json:"arguments"`
+		} `json:"commands"`
+	} `json:"ftp"`
+}
+
+// parseDionaeaIncident 将一条Dionaea连接记录拆分为连接、登录和下载事件
+func parseDionaeaIncident(line, containerID string) ([]repositories.DionaeaLog, error) {
+	var incident dionaeaIncident
+	if err := json.Unmarshal([]byte(line), &incident); err != nil {
+		return nil, fmt.Errorf("JSON解析失败: %v", err)
+	}
+	if incident.SrcIP == "" {
+		return nil, fmt.Errorf("缺少src_ip字段")
+	}
+
+	eventTime, err := parseISOTime(incident.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("时间戳解析失败: %v", err)
+	}
+
+	// 同一行日志的所有事件共享会话ID，事件ID由会话ID和事件序号决定，重复拉取时保持不变
+	sessionID := uuid.NewSHA1(dionaeaNamespace, []byte(containerID+"|"+line))
+	base := repositories.DionaeaLog{
+		EventTime:       eventTime,
+		SessionID:       sessionID.String(),
+		SourceIP:        normalizeDionaeaIP(incident.SrcIP),
+		SourcePort:      uint16(incident.SrcPort),
+		DestinationIP:   normalizeDionaeaIP(incident.DstIP),
+		DestinationPort: uint16(incident.DstPort),
+		Protocol:        incident.Connection.Protocol,
+		Transport:       incident.Connection.Transport,
+		ConnectionType:  incident.Connection.Type,
+		RawLog:          line,
+		ContainerID:     containerID,
+	}
+
+	var ftpCommands []string
+	for _, cmd := range incident.FTP.Commands {
+		ftpCommands = append(ftpCommands, strings.TrimSpace(cmd.Command+" "+strings.Join(cmd.Arguments, " ")))
+	}
+
+	connection := base
+	connection.EventType = repositories.DionaeaEventConnection
+	connection.FTPCommands = strings.Join(ftpCommands, "\n")
+	events := []repositories.DionaeaLog{connection}
+
+	for i, username := range incident.Credentials.Username {
+		login := base
+		login.EventType = repositories.DionaeaEventLogin
+		login.Username = username
+		if i < len(incident.Credentials.Password) {
+			login.Password = incident.Credentials.Password[i]
+		}
+		events = append(events, login)
+	}
+
+	for _, download := range incident.Downloads {
+		event := base
+		event.EventType = repositories.DionaeaEventDownload
+		event.URL = download.URL
+		event.MD5Hash = download.MD5Hash
+		events = append(events, event)
+	}
+
+	for i := range events {
+		events[i].AuthID = uuid.NewSHA1(sessionID, []byte(fmt.Sprintf("%d", i))).String()
+	}
+	return events, nil
+}
+
+// parseISOTime 解析Python isoformat()输出的时间戳，Dionaea和qeeqbox都输出不带时区的UTC时间
+func parseISOTime(value string) (time.Time, error) {
+	layouts := []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05.999999",
+		"2006-01-02 15:04:05.999999",
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无法识别的时间格式: %s", value)
+}
+
+// normalizeDionaeaIP 去掉Dionaea在双栈监听时输出的IPv4映射前缀
+func normalizeDionaeaIP(ip string) string {
+	return strings.TrimPrefix(ip, "::ffff:")
+}
+
+// getContainerName 获取容器名称
+func (s *DionaeaService) getContainerName(containerID string) string {
+	if !IsDockerAvailable() {
+		return ""
+	}
+
+	containerInfo, err := GetContainerInfo(containerID)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(containerInfo.Name, "/")
+}
+
+// GetAllLogs 获取所有日志
+func (s *DionaeaService) GetAllLogs() ([]repositories.DionaeaLog, error) {
+	return s.Repo.List()
+}
+
+// GetLogsPage 按查询条件分页获取Dionaea日志，同时返回符合条件的总数
+func (s *DionaeaService) GetLogsPage(spec repositories.QuerySpec) (*repositories.Page[repositories.DionaeaLog], error) {
+	return s.Repo.Query(spec)
+}
+
+// GetLogByID 根据ID获取日志
+func (s *DionaeaService) GetLogByID(id uint) (*repositories.DionaeaLog, error) {
+	return s.Repo.GetByID(id)
+}
+
+// GetLogsBySessionID 获取同一连接的所有事件
+func (s *DionaeaService) GetLogsBySessionID(sessionID string) ([]repositories.DionaeaLog, error) {
+	return s.Repo.GetBySessionID(sessionID)
+}
+
+// GetLogsBySourceIP 根据源IP获取日志
+func (s *DionaeaService) GetLogsBySourceIP(sourceIP string) ([]repositories.DionaeaLog, error) {
+	return s.Repo.GetBySourceIP(sourceIP)
+}
+
+// GetLogsByContainerID 根据容器ID获取日志
+func (s *DionaeaService) GetLogsByContainerID(containerID string) ([]repositories.DionaeaLog, error) {
+	return s.Repo.GetByContainerID(containerID)
+}
+
+// GetLogsByProtocol 根据服务模块获取日志
+func (s *DionaeaService) GetLogsByProtocol(protocol string) ([]repositories.DionaeaLog, error) {
+	return s.Repo.GetByProtocol(protocol)
+}
+
+// GetLogsByEventType 根据事件类型获取日志
+func (s *DionaeaService) GetLogsByEventType(eventType string) ([]repositories.DionaeaLog, error) {
+	return s.Repo.GetByEventType(eventType)
+}
+
+// GetLogsByUsername 根据用户名获取日志
+func (s *DionaeaService) GetLogsByUsername(username string) ([]repositories.DionaeaLog, error) {
+	return s.Repo.GetByUsername(username)
+}
+
+// GetLogsByTimeRange 根据时间范围获取日志
+func (s *DionaeaService) GetLogsByTimeRange(startTime, endTime time.Time) ([]repositories.DionaeaLog, error) {
+	return s.Repo.GetByTimeRange(startTime, endTime)
+}
+
+// DeleteLogsByContainerID 删除容器相关日志
+func (s *DionaeaService) DeleteLogsByContainerID(containerID string) error {
+	return s.Repo.DeleteByContainerID(containerID)
+}
+
+// GetStatistics 获取统计信息
+func (s *DionaeaService) GetStatistics() ([]repositories.DionaeaStatistics, error) {
+	return s.Repo.GetStatistics()
+}
+
+// GetTopAttackers 获取顶级攻击者
+func (s *DionaeaService) GetTopAttackers(limit int) ([]repositories.DionaeaAttackerStatistics, error) {
+	return s.Repo.GetTopAttackers(limit)
+}
+
+// GetTopUsernames 获取常用用户名
+func (s *DionaeaService) GetTopUsernames(limit int) ([]map[string]interface{}, error) {
+	return s.Repo.GetTopUsernames(limit)
+}
+
+// GetTopPasswords 获取常用密码
+func (s *DionaeaService) GetTopPasswords(limit int) ([]map[string]interface{}, error) {
+	return s.Repo.GetTopPasswords(limit)
+}
+
+// GetTopDownloads 获取下载最多的文件
+func (s *DionaeaService) GetTopDownloads(limit int) ([]map[string]interface{}, error) {
+	return s.Repo.GetTopDownloads(limit)
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"andorralee/internal/repositories"
+	"testing"
+)
+
+// TestParseDionaeaIncident 测试将Dionaea连接记录拆分为连接、登录和下载事件
+func TestParseDionaeaIncident(t *testing.T) {
+	line := `{"timestamp":"2024-05-01T10:00:00.123456","src_ip":"::ffff:1.2.3.4","src_port":40000,"dst_ip":"::ffff:172.17.0.2","dst_port":21,` +
+		`"connection":{"protocol":"ftpd","transport":"tcp","type":"accept"},` +
+		`"credentials":{"username":["root","admin"],"password":["123456","admin"]},` +
+		`"downloads":[{"url":"ftp://1.2.3.4/x.exe","md5_hash":"d41d8cd98f00b204e9800998ecf8427e"}],` +
+		`"ftp":{"commands":[{"command":"USER","arguments":["root"]},{"command":"RETR","arguments":["x.exe"]}]}}`
+
+	events, err := parseDionaeaIncident(line, "container")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("期望1个连接、2个登录和1个下载事件，实际得到%d个", len(events))
+	}
+
+	if events[0].EventType != repositories.DionaeaEventConnection || events[0].FTPCommands != "USER root\nRETR x.exe" {
+		t.Errorf("连接事件错误: %+v", events[0])
+	}
+	if events[2].EventType != repositories.DionaeaEventLogin || events[2].Username != "admin" || events[2].Password != "admin" {
+		t.Errorf("登录事件错误: %+v", events[2])
+	}
+	if events[3].EventType != repositories.DionaeaEventDownload || events[3].MD5Hash == "" {
+		t.Errorf("下载事件错误: %+v", events[3])
+	}
+	if events[0].SourceIP != "1.2.3.4" || events[0].Protocol != "ftpd" {
+		t.Errorf("连接信息错误: %s %s", events[0].SourceIP, events[0].Protocol)
+	}
+
+	// 重复解析同一行得到相同的ID，事件之间ID不同
+	again, _ := parseDionaeaIncident(line, "container")
+	if again[1].AuthID != events[1].AuthID || events[1].AuthID == events[2].AuthID {
+		t.Error("事件ID应由日志内容确定且互不相同")
+	}
+}
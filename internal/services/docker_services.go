@@ -21,13 +21,13 @@ type DockerImageService struct {
 
 // NewDockerImageService 创建Docker镜像服务
 func NewDockerImageService() (*DockerImageService, error) {
-	if config.MySQLDB == nil {
-		return nil, fmt.Errorf("MySQL数据库未初始化")
+	if config.DB == nil {
+		return nil, fmt.Errorf("数据库未初始化")
 	}
 
 	return &DockerImageService{
-		Repo:    repositories.NewMySQLDockerImageRepo(config.MySQLDB),
-		LogRepo: repositories.NewMySQLDockerImageLogRepo(config.MySQLDB),
+		Repo:    repositories.NewDockerImageRepo(config.DB),
+		LogRepo: repositories.NewDockerImageLogRepo(config.DB),
 	}, nil
 }
 
@@ -172,7 +172,7 @@ func TagDockerImage(imageID, newRepo, newTag string) error {
 
 // SyncDockerImagesToDB 同步Docker镜像到数据库
 func SyncDockerImagesToDB(images []image.Summary) {
-	if config.MySQLDB == nil {
+	if config.DB == nil {
 		return
 	}
 
@@ -236,8 +236,8 @@ func logPullImageOperation(imageName, imageID, status, message string) {
 		time.Now().Format("2006-01-02 15:04:05"), imageName, imageID, status, message)
 
 	// 记录到数据库
-	if config.MySQLDB != nil {
-		logRepo := repositories.NewMySQLDockerImageLogRepo(config.MySQLDB)
+	if config.DB != nil {
+		logRepo := repositories.NewDockerImageLogRepo(config.DB)
 		log := &repositories.DockerImageLog{
 			ImageID:   imageID,
 			ImageName: imageName,
@@ -259,8 +259,8 @@ func logImageOperation(imageID, operation, status, message string) {
 		time.Now().Format("2006-01-02 15:04:05"), operation, imageID, status, message)
 
 	// 记录到数据库
-	if config.MySQLDB != nil {
-		logRepo := repositories.NewMySQLDockerImageLogRepo(config.MySQLDB)
+	if config.DB != nil {
+		logRepo := repositories.NewDockerImageLogRepo(config.DB)
 		log := &repositories.DockerImageLog{
 			ImageID:   imageID,
 			Operation: operation,
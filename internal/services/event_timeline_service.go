@@ -0,0 +1,392 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/repositories"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 时间线事件来源，蜜罐日志的来源名称与对应的日志解析器一致
+const (
+	TimelineSourceCowrie        = LogParserCowrie
+	TimelineSourceHeadling      = LogParserHeadling
+	TimelineSourceDionaea       = LogParserDionaea
+	TimelineSourceQeeqbox       = LogParserQeeqbox
+	TimelineSourceAttackCapture = "attack_capture"
+	TimelineSourceHoneyToken    = "honeytoken"
+)
+
+// 攻击严重程度
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+const (
+	// timelineDefaultLimit 时间线每页默认条数
+	timelineDefaultLimit = 100
+	// timelineMaxLimit 时间线每页最大条数
+	timelineMaxLimit = 1000
+	// timelineRebuildBatch 重建时间线时每批读取的来源记录数
+	timelineRebuildBatch = 500
+)
+
+// timelineNamespace 用于根据来源和来源记录生成确定性AuthID的命名空间
+var timelineNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("andorralee/timeline"))
+
+// TimelinePage 一页时间线事件，NextCursor为空表示没有更多事件
+type TimelinePage struct {
+	Events     []repositories.AttackTimelineEvent `json:"events"`
+	NextCursor string                             `json:"next_cursor"`
+	HasMore    bool                               `json:"has_more"`
+}
+
+// TimelineRebuildResult 重建时间线的结果，按来源统计新插入的事件数
+type TimelineRebuildResult struct {
+	Scanned  int            `json:"scanned"`
+	Inserted int            `json:"inserted"`
+	BySource map[string]int `json:"by_source"`
+}
+
+// EventTimelineService 攻击事件时间线服务
+type EventTimelineService struct {
+	Repo         repositories.AttackTimelineRepository
+	HeadlingRepo repositories.HeadlingAuthLogRepository
+	CowrieRepo   repositories.CowrieLogRepository
+	DionaeaRepo  repositories.DionaeaLogRepository
+	QeeqboxRepo  repositories.QeeqboxLogRepository
+}
+
+// NewEventTimelineService 创建攻击事件时间线服务
+func NewEventTimelineService() (*EventTimelineService, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	return &EventTimelineService{
+		Repo:         repositories.NewAttackTimelineRepo(config.DB),
+		HeadlingRepo: repositories.NewHeadlingAuthLogRepo(config.DB),
+		CowrieRepo:   repositories.NewCowrieLogRepo(config.DB),
+		DionaeaRepo:  repositories.NewDionaeaLogRepo(config.DB),
+		QeeqboxRepo:  repositories.NewQeeqboxLogRepo(config.DB),
+	}, nil
+}
+
+// Query 按条件查询一页时间线，cursor为上一页返回的NextCursor
+func (s *EventTimelineService) Query(filter repositories.AttackTimelineFilter, cursor string) (*TimelinePage, error) {
+	if cursor != "" {
+		after, err := DecodeTimelineCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = timelineDefaultLimit
+	}
+	if limit > timelineMaxLimit {
+		limit = timelineMaxLimit
+	}
+	// 多取一条用于判断是否还有下一页
+	filter.Limit = limit + 1
+
+	events, err := s.Repo.Search(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &TimelinePage{Events: events}
+	if len(events) > limit {
+		page.Events = events[:limit]
+		page.HasMore = true
+		last := page.Events[limit-1]
+		page.NextCursor = EncodeTimelineCursor(repositories.TimelineCursor{EventTime: last.EventTime, ID: last.ID})
+	}
+	if page.Events == nil {
+		page.Events = []repositories.AttackTimelineEvent{}
+	}
+	return page, nil
+}
+
+// RecordEvents 保存一批时间线事件，已存在的事件会被跳过，返回新插入的条数
+func (s *EventTimelineService) RecordEvents(events []repositories.AttackTimelineEvent) (int, error) {
+	if len(events) == 0 {
+		return 0, nil
+	}
+	inserted, err := s.Repo.CreateBatch(events)
+	return int(inserted), err
+}
+
+// Rebuild 从各蜜罐日志表补录时间线事件，用于启用时间线之前已入库的日志，可重复执行
+func (s *EventTimelineService) Rebuild() (*TimelineRebuildResult, error) {
+	result := &TimelineRebuildResult{BySource: make(map[string]int)}
+
+	sources := []struct {
+		name string
+		next func(afterID uint) ([]repositories.AttackTimelineEvent, uint, error)
+	}{
+		{TimelineSourceHeadling, func(afterID uint) ([]repositories.AttackTimelineEvent, uint, error) {
+			logs, err := s.HeadlingRepo.ListAfterID(afterID, timelineRebuildBatch)
+			if err != nil || len(logs) == 0 {
+				return nil, 0, err
+			}
+			return TimelineEventsFromHeadling(logs), logs[len(logs)-1].ID, nil
+		}},
+		{TimelineSourceCowrie, func(afterID uint) ([]repositories.AttackTimelineEvent, uint, error) {
+			logs, err := s.CowrieRepo.ListAfterID(afterID, timelineRebuildBatch)
+			if err != nil || len(logs) == 0 {
+				return nil, 0, err
+			}
+			return TimelineEventsFromCowrie(logs), logs[len(logs)-1].ID, nil
+		}},
+		{TimelineSourceDionaea, func(afterID uint) ([]repositories.AttackTimelineEvent, uint, error) {
+			logs, err := s.DionaeaRepo.ListAfterID(afterID, timelineRebuildBatch)
+			if err != nil || len(logs) == 0 {
+				return nil, 0, err
+			}
+			return TimelineEventsFromDionaea(logs), logs[len(logs)-1].ID, nil
+		}},
+		{TimelineSourceQeeqbox, func(afterID uint) ([]repositories.AttackTimelineEvent, uint, error) {
+			logs, err := s.QeeqboxRepo.ListAfterID(afterID, timelineRebuildBatch)
+			if err != nil || len(logs) == 0 {
+				return nil, 0, err
+			}
+			return TimelineEventsFromQeeqbox(logs), logs[len(logs)-1].ID, nil
+		}},
+	}
+
+	for _, source := range sources {
+		var afterID uint
+		for {
+			events, lastID, err := source.next(afterID)
+			if err != nil {
+				return result, fmt.Errorf("读取%s日志失败: %v", source.name, err)
+			}
+			if len(events) == 0 {
+				break
+			}
+			inserted, err := s.RecordEvents(events)
+			if err != nil {
+				return result, fmt.Errorf("保存%s时间线事件失败: %v", source.name, err)
+			}
+			result.Scanned += len(events)
+			result.Inserted += inserted
+			result.BySource[source.name] += inserted
+			afterID = lastID
+		}
+	}
+
+	return result, nil
+}
+
+// RecordTimelineEvent 把一条攻击事件写入时间线，AuthID为空时生成随机ID
+// 时间线是辅助视图，数据库不可用或写入失败时只打印错误，不影响调用方
+func RecordTimelineEvent(event repositories.AttackTimelineEvent) {
+	RecordTimelineEvents([]repositories.AttackTimelineEvent{event})
+}
+
+// RecordTimelineEvents 批量写入时间线事件，未指定ID和严重程度时使用随机ID和low
+func RecordTimelineEvents(events []repositories.AttackTimelineEvent) {
+	for i := range events {
+		if events[i].AuthID == "" {
+			events[i].AuthID = uuid.New().String()
+		}
+		if events[i].Severity == "" {
+			events[i].Severity = SeverityLow
+		}
+	}
+	recordTimeline(events)
+}
+
+// recordTimeline 日志入库后同步写入时间线，失败时只打印错误
+func recordTimeline(events []repositories.AttackTimelineEvent) {
+	if len(events) == 0 || config.DB == nil {
+		return
+	}
+	if _, err := repositories.NewAttackTimelineRepo(config.DB).CreateBatch(events); err != nil {
+		fmt.Printf("写入攻击事件时间线失败: %v\n", err)
+	}
+}
+
+// timelineAuthID 根据来源和来源记录的唯一ID生成时间线事件ID，重复写入同一记录会被跳过
+func timelineAuthID(source, ref string) string {
+	return uuid.NewSHA1(timelineNamespace, []byte(source+":"+ref)).String()
+}
+
+// TimelineEventsFromHeadling 把Headling认证日志转换为时间线事件
+func TimelineEventsFromHeadling(logs []repositories.HeadlingAuthLog) []repositories.AttackTimelineEvent {
+	events := make([]repositories.AttackTimelineEvent, 0, len(logs))
+	for _, log := range logs {
+		events = append(events, repositories.AttackTimelineEvent{
+			EventTime:       log.Timestamp,
+			AuthID:          timelineAuthID(TimelineSourceHeadling, log.AuthID),
+			Source:          TimelineSourceHeadling,
+			SourceRef:       log.AuthID,
+			SessionID:       log.SessionID,
+			SourceIP:        log.SourceIP,
+			SourcePort:      log.SourcePort,
+			DestinationIP:   log.DestinationIP,
+			DestinationPort: log.DestinationPort,
+			Protocol:        log.Protocol,
+			Action:          "login.attempt",
+			Username:        log.Username,
+			Password:        log.Password,
+			Severity:        SeverityMedium,
+			ContainerID:     log.ContainerID,
+			ContainerName:   log.ContainerName,
+		})
+	}
+	return events
+}
+
+// TimelineEventsFromCowrie 把Cowrie日志转换为时间线事件，动作取去掉cowrie.前缀的事件类型
+func TimelineEventsFromCowrie(logs []repositories.CowrieLog) []repositories.AttackTimelineEvent {
+	events := make([]repositories.AttackTimelineEvent, 0, len(logs))
+	for _, log := range logs {
+		action := strings.TrimPrefix(log.EventID, "cowrie.")
+		payload := firstNonEmpty(log.Command, log.URL, log.Filename)
+		if payload == "" && log.TunnelDestIP != "" {
+			payload = fmt.Sprintf("%s:%d", log.TunnelDestIP, log.TunnelDestPort)
+		}
+		events = append(events, repositories.AttackTimelineEvent{
+			EventTime:       log.EventTime,
+			AuthID:          timelineAuthID(TimelineSourceCowrie, log.AuthID),
+			Source:          TimelineSourceCowrie,
+			SourceRef:       log.AuthID,
+			SessionID:       log.SessionID,
+			SourceIP:        log.SourceIP,
+			SourcePort:      uint(log.SourcePort),
+			DestinationIP:   log.DestinationIP,
+			DestinationPort: uint(log.DestinationPort),
+			Protocol:        log.Protocol,
+			Action:          action,
+			Username:        log.Username,
+			Password:        log.Password,
+			Payload:         payload,
+			Severity:        cowrieSeverity(action),
+			ContainerID:     log.ContainerID,
+			ContainerName:   log.ContainerName,
+		})
+	}
+	return events
+}
+
+// cowrieSeverity 根据Cowrie事件类型评估严重程度
+func cowrieSeverity(action string) string {
+	switch {
+	case strings.HasPrefix(action, "session.file_"):
+		return SeverityCritical
+	case action == "login.success", action == "command.input", strings.HasPrefix(action, "direct-tcpip"):
+		return SeverityHigh
+	case strings.HasPrefix(action, "login."), action == "command.failed":
+		return SeverityMedium
+	default:
+		return SeverityLow
+	}
+}
+
+// TimelineEventsFromDionaea 把Dionaea日志转换为时间线事件
+func TimelineEventsFromDionaea(logs []repositories.DionaeaLog) []repositories.AttackTimelineEvent {
+	events := make([]repositories.AttackTimelineEvent, 0, len(logs))
+	for _, log := range logs {
+		severity := SeverityLow
+		switch log.EventType {
+		case "download":
+			severity = SeverityCritical
+		case "login":
+			severity = SeverityMedium
+		}
+		events = append(events, repositories.AttackTimelineEvent{
+			EventTime:       log.EventTime,
+			AuthID:          timelineAuthID(TimelineSourceDionaea, log.AuthID),
+			Source:          TimelineSourceDionaea,
+			SourceRef:       log.AuthID,
+			SessionID:       log.SessionID,
+			SourceIP:        log.SourceIP,
+			SourcePort:      uint(log.SourcePort),
+			DestinationIP:   log.DestinationIP,
+			DestinationPort: uint(log.DestinationPort),
+			Protocol:        log.Protocol,
+			Action:          log.EventType,
+			Username:        log.Username,
+			Password:        log.Password,
+			Payload:         firstNonEmpty(log.URL, log.FTPCommands),
+			Severity:        severity,
+			ContainerID:     log.ContainerID,
+			ContainerName:   log.ContainerName,
+		})
+	}
+	return events
+}
+
+// TimelineEventsFromQeeqbox 把qeeqbox日志转换为时间线事件
+func TimelineEventsFromQeeqbox(logs []repositories.QeeqboxLog) []repositories.AttackTimelineEvent {
+	events := make([]repositories.AttackTimelineEvent, 0, len(logs))
+	for _, log := range logs {
+		severity := SeverityLow
+		switch {
+		case log.Action == "login" && log.Status == "success":
+			severity = SeverityHigh
+		case log.Action == "login":
+			severity = SeverityMedium
+		case log.Detail != "":
+			severity = SeverityHigh
+		}
+		events = append(events, repositories.AttackTimelineEvent{
+			EventTime:       log.EventTime,
+			AuthID:          timelineAuthID(TimelineSourceQeeqbox, log.AuthID),
+			Source:          TimelineSourceQeeqbox,
+			SourceRef:       log.AuthID,
+			SourceIP:        log.SourceIP,
+			SourcePort:      uint(log.SourcePort),
+			DestinationIP:   log.DestinationIP,
+			DestinationPort: uint(log.DestinationPort),
+			Protocol:        log.Protocol,
+			Action:          log.Action,
+			Username:        log.Username,
+			Password:        log.Password,
+			Payload:         log.Detail,
+			Severity:        severity,
+			ContainerID:     log.ContainerID,
+			ContainerName:   log.ContainerName,
+		})
+	}
+	return events
+}
+
+// EncodeTimelineCursor 把游标编码为URL安全的字符串
+func EncodeTimelineCursor(cursor repositories.TimelineCursor) string {
+	raw := fmt.Sprintf("%d:%d", cursor.EventTime.UnixNano(), cursor.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeTimelineCursor 解析EncodeTimelineCursor生成的游标
+func DecodeTimelineCursor(cursor string) (*repositories.TimelineCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("无效的游标")
+	}
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, fmt.Errorf("无效的游标")
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("无效的游标")
+	}
+	i, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("无效的游标")
+	}
+	return &repositories.TimelineCursor{EventTime: time.Unix(0, n), ID: uint(i)}, nil
+}
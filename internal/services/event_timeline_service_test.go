@@ -0,0 +1,61 @@
+package services
+
+import (
+	"andorralee/internal/repositories"
+	"testing"
+	"time"
+)
+
+// TestTimelineCursorRoundTrip 测试游标编码后能还原时间和ID
+func TestTimelineCursorRoundTrip(t *testing.T) {
+	cursor := repositories.TimelineCursor{EventTime: time.Date(2024, 5, 1, 10, 0, 0, 123456000, time.Local), ID: 42}
+
+	decoded, err := DecodeTimelineCursor(EncodeTimelineCursor(cursor))
+	if err != nil {
+		t.Fatalf("解析游标失败: %v", err)
+	}
+	if !decoded.EventTime.Equal(cursor.EventTime) || decoded.ID != cursor.ID {
+		t.Errorf("游标还原错误: %+v", decoded)
+	}
+
+	if _, err := DecodeTimelineCursor("not-a-cursor"); err == nil {
+		t.Error("无效的游标应当解析失败")
+	}
+}
+
+// TestTimelineEventsFromCowrie 测试Cowrie日志归一化后的动作、载荷和严重程度
+func TestTimelineEventsFromCowrie(t *testing.T) {
+	logs := []repositories.CowrieLog{
+		{EventID: "cowrie.login.success", AuthID: "a1", SourceIP: "1.2.3.4", Username: "root", Password: "123456"},
+		{EventID: "cowrie.command.input", AuthID: "a2", SourceIP: "1.2.3.4", Command: "uname -a"},
+		{EventID: "cowrie.session.file_download", AuthID: "a3", SourceIP: "1.2.3.4", URL: "http://evil/x.sh"},
+		{EventID: "cowrie.session.connect", AuthID: "a4", SourceIP: "1.2.3.4"},
+	}
+
+	events := TimelineEventsFromCowrie(logs)
+	want := []struct{ action, payload, severity string }{
+		{"login.success", "", SeverityHigh},
+		{"command.input", "uname -a", SeverityHigh},
+		{"session.file_download", "http://evil/x.sh", SeverityCritical},
+		{"session.connect", "", SeverityLow},
+	}
+	for i, w := range want {
+		e := events[i]
+		if e.Source != TimelineSourceCowrie || e.Action != w.action || e.Payload != w.payload || e.Severity != w.severity {
+			t.Errorf("第%d条事件归一化错误: %+v", i, e)
+		}
+	}
+	if events[0].Username != "root" || events[0].Password != "123456" {
+		t.Errorf("凭据未保留: %+v", events[0])
+	}
+
+	// 同一条来源记录重复写入时生成相同的AuthID，不同来源即使ID相同也不会冲突
+	again := TimelineEventsFromCowrie(logs[:1])
+	if again[0].AuthID != events[0].AuthID {
+		t.Error("同一记录的AuthID应当稳定")
+	}
+	headling := TimelineEventsFromHeadling([]repositories.HeadlingAuthLog{{AuthID: "a1"}})
+	if headling[0].AuthID == events[0].AuthID {
+		t.Error("不同来源的AuthID不应冲突")
+	}
+}
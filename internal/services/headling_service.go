@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/csv"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -15,77 +16,147 @@ import (
 
 // HeadlingService Headling认证日志服务
 type HeadlingService struct {
-	Repo repositories.HeadlingAuthLogRepository
+	Repo   repositories.HeadlingAuthLogRepository
+	Reader *ContainerLogReader
 }
 
 // NewHeadlingService 创建Headling服务
 func NewHeadlingService() (*HeadlingService, error) {
-	if config.MySQLDB == nil {
-		return nil, fmt.Errorf("MySQL数据库未初始化")
+	if config.DB == nil {
+		return nil, fmt.Errorf("数据库未初始化")
 	}
 
 	return &HeadlingService{
-		Repo: repositories.NewMySQLHeadlingAuthLogRepo(config.MySQLDB),
+		Repo:   repositories.NewHeadlingAuthLogRepo(config.DB),
+		Reader: NewContainerLogReader(repositories.NewContainerLogCursorRepo(config.DB)),
 	}, nil
 }
 
+// init 注册Headling日志解析器
+func init() {
+	RegisterLogParser(NewLogParser(LogParserHeadling, isHeadlingLine, func(containerID string, lines []string) (int, error) {
+		service, err := NewHeadlingService()
+		if err != nil {
+			return 0, err
+		}
+		return service.IngestLines(containerID, lines)
+	}), "headling")
+}
+
+// isHeadlingLine 判断日志行是否为Headling认证日志CSV记录，跳过表头
+func isHeadlingLine(line string) bool {
+	return strings.Count(line, ",") >= 10 && !strings.HasPrefix(line, "timestamp,")
+}
+
+// HeadlingLogSource Headling日志在游标表中的来源标识
+const HeadlingLogSource = "headling"
+
+// DefaultHeadlingLogPath Headling认证日志CSV文件在容器内的默认路径
+const DefaultHeadlingLogPath = "/var/log/headling/auth.csv"
+
 // PullHeadlingLogs 从容器中拉取headling认证日志
-func (s *HeadlingService) PullHeadlingLogs(containerID string) error {
+// 通过Docker API读取容器内的CSV文件，按游标只处理新增的行，日志轮转后会先补读旧文件剩余部分，已入库的记录会被跳过
+func (s *HeadlingService) PullHeadlingLogs(containerID, logPath string) (*IngestResult, error) {
 	if !IsDockerAvailable() {
-		return fmt.Errorf("Docker服务不可用")
+		return nil, fmt.Errorf("Docker服务不可用")
 	}
 
-	// 暂时使用模拟数据，实际项目中需要实现从容器中读取CSV文件的逻辑
-	// 这里可以通过以下方式实现：
-	// 1. 使用 docker cp 命令复制文件
-	// 2. 使用 docker exec 执行命令读取文件内容
-	// 3. 通过挂载卷的方式直接读取文件
-
-	// 模拟CSV内容（实际应该从容器中读取）
-	csvContent := `timestamp,auth_id,session_id,source_ip,source_port,destination_ip,destination_port,protocol,username,password,password_hash
-2025-06-03 11:45:51.239525,e69fc485-66f8-440b-8a79-27f764ef83b9,b344a630-a024-41fa-9aaa-91b7653ad49c,172.17.0.1,44390,172.17.0.3,80,http,123,123,
-2025-06-03 11:45:59.990922,ea9cae68-b2f5-4d9a-a8d7-08f69e8238d7,0853267f-01b0-4b0c-b66a-1bd2686a9393,172.17.0.1,38324,172.17.0.3,80,http,hhh,hhh,`
+	if logPath == "" {
+		logPath = HeadlingLogPath()
+	}
 
-	// 解析CSV内容
-	logs, err := s.parseCSVLogs(csvContent, containerID)
+	lines, cursor, err := s.Reader.ReadNewLines(containerID, HeadlingLogSource, logPath)
 	if err != nil {
-		return fmt.Errorf("解析CSV日志失败: %v", err)
+		return nil, fmt.Errorf("读取认证日志失败: %v", err)
 	}
 
-	// 批量保存到数据库
+	logs, result, err := s.saveLogs(lines, containerID)
+	if err != nil {
+		return nil, err
+	}
 	if len(logs) > 0 {
-		if err := s.Repo.CreateBatch(logs); err != nil {
-			return fmt.Errorf("保存日志到数据库失败: %v", err)
-		}
-		fmt.Printf("成功从容器 %s 拉取并保存了 %d 条认证日志\n", containerID, len(logs))
-	} else {
-		fmt.Printf("容器 %s 没有新的认证日志\n", containerID)
+		cursor.LastRecordID = logs[len(logs)-1].AuthID
+	}
+	fmt.Printf("容器 %s 的认证日志拉取完成: 新增 %d 条, 跳过 %d 条, 无法解析 %d 条\n",
+		containerID, result.Inserted, result.Skipped, result.Rejected)
+
+	// 日志入库后再推进游标，保证失败时下次可以重新读取
+	if err := s.Reader.Commit(cursor); err != nil {
+		return result, fmt.Errorf("保存日志游标失败: %v", err)
 	}
 
-	return nil
+	return result, nil
 }
 
-// parseCSVLogs 解析CSV格式的日志
-func (s *HeadlingService) parseCSVLogs(csvContent, containerID string) ([]repositories.HeadlingAuthLog, error) {
-	reader := csv.NewReader(strings.NewReader(csvContent))
-	records, err := reader.ReadAll()
+// IngestLines 解析并保存一批Headling CSV日志行，供日志跟随采集等场景复用，返回新插入的条数
+func (s *HeadlingService) IngestLines(containerID string, lines []string) (int, error) {
+	_, result, err := s.saveLogs(lines, containerID)
 	if err != nil {
-		return nil, fmt.Errorf("解析CSV失败: %v", err)
+		return 0, err
 	}
+	return result.Inserted, nil
+}
 
-	if len(records) == 0 {
-		return nil, nil
+// saveLogs 解析CSV日志行并批量保存到数据库，auth_id已存在的记录计为跳过
+func (s *HeadlingService) saveLogs(lines []string, containerID string) ([]repositories.HeadlingAuthLog, *IngestResult, error) {
+	logs, rejected, err := s.parseCSVLogs(lines, containerID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("解析CSV日志失败: %v", err)
 	}
 
-	// 跳过标题行
-	if len(records) > 0 && records[0][0] == "timestamp" {
-		records = records[1:]
+	inserted, err := s.Repo.CreateBatch(logs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("保存日志到数据库失败: %v", err)
+	}
+	if inserted > 0 {
+		recordTimeline(TimelineEventsFromHeadling(logs))
 	}
+	return logs, &IngestResult{
+		Inserted: int(inserted),
+		Skipped:  len(logs) - int(inserted),
+		Rejected: rejected,
+	}, nil
+}
+
+// HeadlingLogPath 获取容器内Headling认证日志路径，可通过HEADLING_LOG_PATH环境变量覆盖
+func HeadlingLogPath() string {
+	if p := os.Getenv("HEADLING_LOG_PATH"); p != "" {
+		return p
+	}
+	return DefaultHeadlingLogPath
+}
+
+// parseCSVLogs 逐行解析CSV格式的日志，返回解析出的日志和无法解析的行数
+// 用户名和口令来自攻击者，可能包含不成对的引号，每行单独解析，格式错误的行计为无法解析并跳过，不影响同一批次的其他行
+func (s *HeadlingService) parseCSVLogs(csvLines []string, containerID string) ([]repositories.HeadlingAuthLog, int, error) {
+	if len(csvLines) == 0 {
+		return nil, 0, nil
+	}
+	rejected := 0
+
+	// 获取容器名称
+	containerName := s.getContainerName(containerID)
 
 	var logs []repositories.HeadlingAuthLog
-	for i, record := range records {
+	for i, line := range csvLines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		record, err := parseCSVLine(line)
+		if err != nil {
+			fmt.Printf("跳过CSV解析失败的记录 %d: %v\n", i+1, err)
+			rejected++
+			continue
+		}
+
+		// 跳过标题行，日志轮转后新文件的标题行可能出现在中间
+		if len(record) > 0 && record[0] == "timestamp" {
+			continue
+		}
+
 		if len(record) < 11 {
 			fmt.Printf("跳过格式不正确的记录 %d: %v\n", i+1, record)
+			rejected++
 			continue
 		}
 
@@ -93,6 +164,7 @@ func (s *HeadlingService) parseCSVLogs(csvContent, containerID string) ([]reposi
 		timestamp, err := time.Parse("2006-01-02 15:04:05.999999", record[0])
 		if err != nil {
 			fmt.Printf("跳过时间戳解析失败的记录 %d: %v\n", i+1, err)
+			rejected++
 			continue
 		}
 
@@ -100,24 +172,17 @@ func (s *HeadlingService) parseCSVLogs(csvContent, containerID string) ([]reposi
 		sourcePort, err := strconv.ParseUint(record[4], 10, 32)
 		if err != nil {
 			fmt.Printf("跳过源端口解析失败的记录 %d: %v\n", i+1, err)
+			rejected++
 			continue
 		}
 
 		destinationPort, err := strconv.ParseUint(record[6], 10, 32)
 		if err != nil {
 			fmt.Printf("跳过目标端口解析失败的记录 %d: %v\n", i+1, err)
+			rejected++
 			continue
 		}
 
-		// 检查是否已存在（根据auth_id）
-		existing, _ := s.Repo.GetByAuthID(record[1])
-		if existing != nil {
-			continue // 跳过已存在的记录
-		}
-
-		// 获取容器名称
-		containerName := s.getContainerName(containerID)
-
 		log := repositories.HeadlingAuthLog{
 			Timestamp:       timestamp,
 			AuthID:          record[1],
@@ -137,7 +202,15 @@ func (s *HeadlingService) parseCSVLogs(csvContent, containerID string) ([]reposi
 		logs = append(logs, log)
 	}
 
-	return logs, nil
+	return logs, rejected, nil
+}
+
+// parseCSVLine 解析一行CSV，字段中间不成对的引号按普通字符处理
+func parseCSVLine(line string) ([]string, error) {
+	reader := csv.NewReader(strings.NewReader(line))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	return reader.Read()
 }
 
 // getContainerName 获取容器名称
@@ -174,14 +247,14 @@ func (s *HeadlingService) GetLogsByTimeRange(startTime, endTime time.Time) ([]re
 	return s.Repo.GetByTimeRange(startTime, endTime)
 }
 
-// GetStatistics 获取认证统计信息
-func (s *HeadlingService) GetStatistics() ([]repositories.HeadlingAuthStatistics, error) {
-	return s.Repo.GetStatistics()
+// GetStatistics 按日期和协议获取认证统计信息
+func (s *HeadlingService) GetStatistics(filter repositories.StatisticsFilter) ([]repositories.HeadlingAuthStatistics, error) {
+	return s.Repo.GetStatistics(filter)
 }
 
-// GetAttackerIPStatistics 获取攻击者IP统计信息
-func (s *HeadlingService) GetAttackerIPStatistics() ([]repositories.AttackerIPStatistics, error) {
-	return s.Repo.GetAttackerIPStatistics()
+// GetAttackerIPStatistics 获取攻击者IP统计信息，filter.Limit大于0时只返回前N个攻击者
+func (s *HeadlingService) GetAttackerIPStatistics(filter repositories.StatisticsFilter) ([]repositories.AttackerIPStatistics, error) {
+	return s.Repo.GetAttackerIPStatistics(filter)
 }
 
 // GetTopAttackers 获取前N个攻击者
@@ -224,6 +297,11 @@ func (s *HeadlingService) GetAllLogs() ([]repositories.HeadlingAuthLog, error) {
 	return s.Repo.List()
 }
 
+// GetLogsPage 按查询条件分页获取认证日志，同时返回符合条件的总数
+func (s *HeadlingService) GetLogsPage(spec repositories.QuerySpec) (*repositories.Page[repositories.HeadlingAuthLog], error) {
+	return s.Repo.Query(spec)
+}
+
 // GetLogByID 根据ID获取认证日志
 func (s *HeadlingService) GetLogByID(id uint) (*repositories.HeadlingAuthLog, error) {
 	return s.Repo.GetByID(id)
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"testing"
+)
+
+// TestHeadlingMalformedLine 测试批次中间格式错误的行计为无法解析并跳过，前后的行正常入库，字段中的不成对引号按普通字符处理
+func TestHeadlingMalformedLine(t *testing.T) {
+	useSQLiteDatabase(t)
+
+	service, err := NewHeadlingService()
+	if err != nil {
+		t.Fatalf("创建Headling服务失败: %v", err)
+	}
+	lines := []string{
+		"timestamp,auth_id,session_id,source_ip,source_port,destination_ip,destination_port,protocol,username,password,password_hash",
+		"2025-03-01 10:00:00.000001,h1,s1,1.2.3.4,50000,10.0.0.2,22,ssh,root,123456,",
+		`2025-03-01 10:00:01.000001,h2,s1,1.2.3.4,50000,10.0.0.2,22,ssh,"root,pass,`,
+		`2025-03-01 10:00:02.000001,h3,s1,1.2.3.4,50000,10.0.0.2,22,ssh,admin,pa"ss,`,
+		"2025-03-01 10:00:03.000001,h4,s1,1.2.3.4,50000,10.0.0.2,22,ssh,root,toor,",
+	}
+
+	logs, result, err := service.saveLogs(lines, "c1")
+	if err != nil {
+		t.Fatalf("一行格式错误不应导致整批失败: %v", err)
+	}
+	if result.Inserted != 3 || result.Rejected != 1 {
+		t.Fatalf("应入库3条并拒绝1条: %+v", result)
+	}
+	if logs[1].AuthID != "h3" || logs[1].Password != `pa"ss` {
+		t.Errorf("字段中的引号应按普通字符保存: %+v", logs[1])
+	}
+	if logs[2].AuthID != "h4" {
+		t.Errorf("格式错误的行之后的记录应正常解析: %+v", logs[2])
+	}
+}
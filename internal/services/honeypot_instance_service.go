@@ -6,26 +6,64 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/go-connections/nat"
 )
 
+const (
+	// InstanceStorageDatabase 蜜罐实例保存在主数据库(MySQL/SQLite)中
+	InstanceStorageDatabase = "database"
+	// InstanceStorageMemory 蜜罐实例只保存在内存中，进程重启后由对账从Docker重新接管
+	InstanceStorageMemory = "memory"
+)
+
+// memoryInstanceRepo 内存存储后端共用的实例仓库
+var memoryInstanceRepo = repositories.NewMemoryHoneypotInstanceRepo()
+
+// InstanceStorageBackend 获取蜜罐实例存储后端，可通过INSTANCE_STORAGE环境变量指定(database/memory，mysql等同于database)
+// 未指定时主数据库可用则使用数据库，否则使用内存
+func InstanceStorageBackend() string {
+	switch backend := strings.ToLower(os.Getenv("INSTANCE_STORAGE")); backend {
+	case InstanceStorageDatabase, InstanceStorageMemory:
+		return backend
+	case "mysql":
+		return InstanceStorageDatabase
+	}
+	if config.DB != nil {
+		return InstanceStorageDatabase
+	}
+	return InstanceStorageMemory
+}
+
+// newHoneypotInstanceRepo 按存储后端创建蜜罐实例仓库
+func newHoneypotInstanceRepo() (repositories.HoneypotInstanceRepository, error) {
+	if InstanceStorageBackend() == InstanceStorageMemory {
+		return memoryInstanceRepo, nil
+	}
+	if config.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	return repositories.NewHoneypotInstanceRepo(config.DB), nil
+}
+
 // HoneypotInstanceService 蜜罐实例服务
 type HoneypotInstanceService struct {
 	repo repositories.HoneypotInstanceRepository
 }
 
-// NewHoneypotInstanceService 创建蜜罐实例服务
+// NewHoneypotInstanceService 创建蜜罐实例服务，实例存储后端由InstanceStorageBackend决定
 func NewHoneypotInstanceService() (*HoneypotInstanceService, error) {
-	if config.MySQLDB == nil {
-		return nil, errors.New("MySQL数据库未初始化")
+	repo, err := newHoneypotInstanceRepo()
+	if err != nil {
+		return nil, err
 	}
-
-	repo := repositories.NewMySQLHoneypotInstanceRepo(config.MySQLDB)
 	return &HoneypotInstanceService{repo: repo}, nil
 }
 
@@ -34,6 +72,11 @@ func (s *HoneypotInstanceService) GetAllInstances() ([]repositories.HoneypotInst
 	return s.repo.List()
 }
 
+// GetInstancesPage 按查询条件分页获取蜜罐实例，同时返回符合条件的总数
+func (s *HoneypotInstanceService) GetInstancesPage(spec repositories.QuerySpec) (*repositories.Page[repositories.HoneypotInstance], error) {
+	return s.repo.Query(spec)
+}
+
 // GetInstanceByID 根据ID获取蜜罐实例
 func (s *HoneypotInstanceService) GetInstanceByID(id uint) (*repositories.HoneypotInstance, error) {
 	return s.repo.GetByID(id)
@@ -71,9 +114,9 @@ func (s *HoneypotInstanceService) DeleteInstance(id uint) error {
 		return err
 	}
 
-	// 如果实例已经部署，先停止容器
-	if instance.Status == "running" {
-		if err := s.StopInstance(id); err != nil {
+	// 如果实例已经部署，先停止容器，容器已经不存在时直接删除记录
+	if instance.Status == "running" && config.DockerCli != nil {
+		if err := s.StopInstance(id); err != nil && !errdefs.IsNotFound(err) {
 			return err
 		}
 	}
@@ -121,6 +164,7 @@ func (s *HoneypotInstanceService) DeployInstance(id uint) error {
 	containerConfig := &container.Config{
 		Image:        instance.ImageName,
 		ExposedPorts: exposedPorts,
+		Labels:       ManagedContainerLabels(instance.HoneypotName, instance.Protocol, instance.LogParser),
 	}
 
 	hostConfig := &container.HostConfig{
@@ -145,6 +189,7 @@ func (s *HoneypotInstanceService) DeployInstance(id uint) error {
 
 	// 更新实例信息
 	instance.ContainerName = instance.Name
+	instance.ContainerID = resp.ID
 	instance.Status = "created"
 	if err := s.repo.Update(instance); err != nil {
 		return err
@@ -194,6 +239,9 @@ func (s *HoneypotInstanceService) GetInstanceLogs(id uint) ([]repositories.Honey
 	}
 
 	// 获取日志
-	logRepo := repositories.NewMySQLHoneypotLogRepo(config.MySQLDB)
+	if config.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	logRepo := repositories.NewHoneypotLogRepo(config.DB)
 	return logRepo.GetByInstanceID(instance.ID)
 }
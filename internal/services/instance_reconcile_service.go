@@ -0,0 +1,292 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/repositories"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+)
+
+const (
+	// ManagedContainerLabel 标记由andorralee创建的容器，对账只处理带有该标签的容器
+	ManagedContainerLabel = "andorralee.managed"
+	// ContainerHoneypotLabel 容器所属的蜜罐名称
+	ContainerHoneypotLabel = "andorralee.honeypot"
+	// ContainerProtocolLabel 蜜罐协议类型
+	ContainerProtocolLabel = "andorralee.protocol"
+	// ContainerLogParserLabel 容器日志使用的解析器
+	ContainerLogParserLabel = "andorralee.log_parser"
+
+	// InstanceStatusMissing 实例记录存在但Docker中已找不到对应的容器
+	InstanceStatusMissing = "missing"
+
+	// DefaultInstanceReconcileInterval 默认的实例对账间隔
+	DefaultInstanceReconcileInterval = 5 * time.Minute
+)
+
+// ManagedContainerLabels 生成创建容器时附加的标签，对账接管孤立容器时据此还原实例信息
+func ManagedContainerLabels(honeypotName, protocol, logParser string) map[string]string {
+	labels := map[string]string{ManagedContainerLabel: "true"}
+	if honeypotName != "" {
+		labels[ContainerHoneypotLabel] = honeypotName
+	}
+	if protocol != "" {
+		labels[ContainerProtocolLabel] = protocol
+	}
+	if logParser != "" {
+		labels[ContainerLogParserLabel] = logParser
+	}
+	return labels
+}
+
+// InstanceReconcileInterval 获取实例对账间隔，可通过INSTANCE_RECONCILE_INTERVAL环境变量覆盖(如 1m)
+func InstanceReconcileInterval() time.Duration {
+	if v := os.Getenv("INSTANCE_RECONCILE_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return DefaultInstanceReconcileInterval
+}
+
+// ReconcileResult 一次对账的结果
+type ReconcileResult struct {
+	Backend    string    `json:"backend"`    // 实例存储后端
+	Containers int       `json:"containers"` // Docker中带有管理标签的容器数量
+	Instances  int       `json:"instances"`  // 对账前的实例记录数量
+	Adopted    []string  `json:"adopted"`    // 新接管的孤立容器
+	Missing    []string  `json:"missing"`    // 新标记为missing的实例
+	Updated    []string  `json:"updated"`    // 状态或容器ID发生变化的实例
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// InstanceReconciler 蜜罐实例对账服务，比较Docker中的容器与存储的实例记录
+type InstanceReconciler struct {
+	Repo       repositories.HoneypotInstanceRepository
+	Backend    string
+	Containers func(ctx context.Context) ([]container.Summary, error)
+}
+
+// NewInstanceReconciler 创建实例对账服务，实例存储后端由InstanceStorageBackend决定
+func NewInstanceReconciler() (*InstanceReconciler, error) {
+	if config.DockerCli == nil {
+		return nil, errors.New("Docker客户端未初始化")
+	}
+	repo, err := newHoneypotInstanceRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	return &InstanceReconciler{
+		Repo:       repo,
+		Backend:    InstanceStorageBackend(),
+		Containers: listManagedContainers,
+	}, nil
+}
+
+// listManagedContainers 列出带有管理标签的所有容器，包括已停止的容器
+func listManagedContainers(ctx context.Context) ([]container.Summary, error) {
+	return config.DockerCli.ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", ManagedContainerLabel+"=true")),
+	})
+}
+
+// Reconcile 执行一次对账：同步已知容器的状态，接管没有实例记录的孤立容器，
+// 把容器已消失的实例标记为missing。没有容器ID或使用模拟容器ID的实例不参与比较
+func (r *InstanceReconciler) Reconcile(ctx context.Context) (*ReconcileResult, error) {
+	result := &ReconcileResult{Backend: r.Backend, StartedAt: time.Now()}
+
+	containers, err := r.Containers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取容器列表失败: %v", err)
+	}
+	instances, err := r.Repo.List()
+	if err != nil {
+		return nil, fmt.Errorf("获取实例记录失败: %v", err)
+	}
+	result.Containers = len(containers)
+	result.Instances = len(instances)
+
+	byContainerID := make(map[string]*repositories.HoneypotInstance, len(instances))
+	byContainerName := make(map[string]*repositories.HoneypotInstance, len(instances))
+	for i := range instances {
+		instance := &instances[i]
+		if instance.ContainerID != "" {
+			byContainerID[instance.ContainerID] = instance
+		}
+		if instance.ContainerName != "" {
+			byContainerName[instance.ContainerName] = instance
+		}
+	}
+
+	seen := make(map[uint]bool, len(instances))
+	for _, c := range containers {
+		name := containerName(c)
+		instance, ok := byContainerID[c.ID]
+		if !ok {
+			// 部署时只记录了容器名称的实例按名称匹配
+			if byName, found := byContainerName[name]; found && !seen[byName.ID] && !isTrackedContainerID(byName.ContainerID) {
+				instance, ok = byName, true
+			}
+		}
+
+		if !ok {
+			adopted := adoptedInstance(c)
+			if err := r.Repo.Create(adopted); err != nil {
+				return nil, fmt.Errorf("接管容器 %s 失败: %v", name, err)
+			}
+			result.Adopted = append(result.Adopted, name)
+			continue
+		}
+
+		seen[instance.ID] = true
+		if instance.ContainerID == c.ID && instance.Status == c.State {
+			continue
+		}
+		instance.ContainerID = c.ID
+		instance.Status = c.State
+		instance.UpdateTime = time.Now()
+		if err := r.Repo.Update(instance); err != nil {
+			return nil, fmt.Errorf("更新实例 %s 失败: %v", instance.Name, err)
+		}
+		result.Updated = append(result.Updated, instance.Name)
+	}
+
+	for i := range instances {
+		instance := &instances[i]
+		if seen[instance.ID] || !isTrackedContainerID(instance.ContainerID) || instance.Status == InstanceStatusMissing {
+			continue
+		}
+		instance.Status = InstanceStatusMissing
+		instance.UpdateTime = time.Now()
+		if err := r.Repo.Update(instance); err != nil {
+			return nil, fmt.Errorf("标记实例 %s 失败: %v", instance.Name, err)
+		}
+		result.Missing = append(result.Missing, instance.Name)
+	}
+
+	result.FinishedAt = time.Now()
+	return result, nil
+}
+
+// isTrackedContainerID 判断实例是否关联了真实的Docker容器
+func isTrackedContainerID(containerID string) bool {
+	return containerID != "" && !strings.HasPrefix(containerID, "mock")
+}
+
+// containerName 获取容器名称，去掉Docker返回的前导斜杠
+func containerName(c container.Summary) string {
+	if len(c.Names) == 0 {
+		return c.ID[:min(len(c.ID), 12)]
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+// adoptedInstance 根据容器标签和端口还原孤立容器的实例记录
+func adoptedInstance(c container.Summary) *repositories.HoneypotInstance {
+	name := containerName(c)
+	honeypotName := c.Labels[ContainerHoneypotLabel]
+	if honeypotName == "" {
+		honeypotName = name
+	}
+	protocol := c.Labels[ContainerProtocolLabel]
+	if protocol == "" {
+		protocol = "auto-detected"
+	}
+
+	portMappings := make(map[string]string)
+	mainPort := 0
+	for _, p := range c.Ports {
+		if p.PublicPort == 0 {
+			continue
+		}
+		portMappings[strconv.Itoa(int(p.PrivatePort))] = strconv.Itoa(int(p.PublicPort))
+		if mainPort == 0 {
+			mainPort = int(p.PublicPort)
+		}
+	}
+	portMappingsJSON, _ := json.Marshal(portMappings)
+
+	now := time.Now()
+	return &repositories.HoneypotInstance{
+		Name:          name,
+		HoneypotName:  honeypotName,
+		ContainerName: name,
+		ContainerID:   c.ID,
+		IP:            "0.0.0.0",
+		Port:          mainPort,
+		Protocol:      protocol,
+		InterfaceType: "docker",
+		Status:        c.State,
+		ImageName:     c.Image,
+		ImageID:       c.ImageID,
+		LogParser:     ResolveLogParser(c.Labels[ContainerLogParserLabel], c.Image),
+		PortMappings:  string(portMappingsJSON),
+		Environment:   "{}",
+		CreateTime:    now,
+		UpdateTime:    now,
+		Description:   "对账时接管的孤立容器",
+	}
+}
+
+var (
+	instanceReconcilerOnce sync.Once
+	lastReconcileMu        sync.RWMutex
+	lastReconcileResult    *ReconcileResult
+)
+
+// ReconcileInstances 立即执行一次实例对账并记录结果
+func ReconcileInstances(ctx context.Context) (*ReconcileResult, error) {
+	reconciler, err := NewInstanceReconciler()
+	if err != nil {
+		return nil, err
+	}
+	result, err := reconciler.Reconcile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lastReconcileMu.Lock()
+	lastReconcileResult = result
+	lastReconcileMu.Unlock()
+	return result, nil
+}
+
+// LastReconcileResult 获取最近一次对账的结果，还没有执行过对账时返回nil
+func LastReconcileResult() *ReconcileResult {
+	lastReconcileMu.RLock()
+	defer lastReconcileMu.RUnlock()
+	return lastReconcileResult
+}
+
+// StartInstanceReconciler 启动时立即对账一次，之后按InstanceReconcileInterval定期对账，重复调用只启动一次
+func StartInstanceReconciler() {
+	instanceReconcilerOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(InstanceReconcileInterval())
+			defer ticker.Stop()
+
+			for {
+				if result, err := ReconcileInstances(context.Background()); err != nil {
+					fmt.Printf("蜜罐实例对账失败: %v\n", err)
+				} else if len(result.Adopted)+len(result.Missing) > 0 {
+					fmt.Printf("蜜罐实例对账完成: 接管 %d 个孤立容器，%d 个实例的容器已消失\n",
+						len(result.Adopted), len(result.Missing))
+				}
+				<-ticker.C
+			}
+		}()
+	})
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"andorralee/internal/repositories"
+	"context"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// TestReconcileInstances 测试对账同步状态、接管孤立容器并标记已消失的实例
+func TestReconcileInstances(t *testing.T) {
+	repo := repositories.NewMemoryHoneypotInstanceRepo()
+	known := &repositories.HoneypotInstance{Name: "ssh", ContainerName: "ssh-1", ContainerID: "c-known", Status: "created"}
+	vanished := &repositories.HoneypotInstance{Name: "web", ContainerName: "web-1", ContainerID: "c-gone", Status: "running"}
+	mock := &repositories.HoneypotInstance{Name: "mock", ContainerName: "mock-1", ContainerID: "mock-123", Status: "mock-created"}
+	pending := &repositories.HoneypotInstance{Name: "ftp", ContainerName: "ftp-1", Status: "created"}
+	for _, instance := range []*repositories.HoneypotInstance{known, vanished, mock, pending} {
+		if err := repo.Create(instance); err != nil {
+			t.Fatalf("创建实例失败: %v", err)
+		}
+	}
+
+	containers := []container.Summary{
+		{ID: "c-known", Names: []string{"/ssh-1"}, State: "running"},
+		{ID: "c-ftp", Names: []string{"/ftp-1"}, State: "exited"},
+		{
+			ID:     "c-orphan",
+			Names:  []string{"/redis-ab12"},
+			Image:  "cowrie/cowrie:latest",
+			State:  "running",
+			Labels: ManagedContainerLabels("redis", "Redis", ""),
+			Ports:  []container.Port{{PrivatePort: 6379, PublicPort: 16379, Type: "tcp"}},
+		},
+	}
+	reconciler := &InstanceReconciler{
+		Repo:       repo,
+		Backend:    InstanceStorageMemory,
+		Containers: func(context.Context) ([]container.Summary, error) { return containers, nil },
+	}
+
+	result, err := reconciler.Reconcile(context.Background())
+	if err != nil {
+		t.Fatalf("对账失败: %v", err)
+	}
+	if len(result.Adopted) != 1 || result.Adopted[0] != "redis-ab12" {
+		t.Errorf("应接管孤立容器: %+v", result.Adopted)
+	}
+	if len(result.Missing) != 1 || result.Missing[0] != "web" {
+		t.Errorf("只有关联真实容器的实例会被标记为missing: %+v", result.Missing)
+	}
+	if len(result.Updated) != 2 {
+		t.Errorf("应更新2个实例: %+v", result.Updated)
+	}
+
+	if got, _ := repo.GetByID(known.ID); got.Status != "running" {
+		t.Errorf("已知容器的状态未同步: %+v", got)
+	}
+	if got, _ := repo.GetByID(pending.ID); got.ContainerID != "c-ftp" || got.Status != "exited" {
+		t.Errorf("按容器名称匹配的实例应补全容器ID: %+v", got)
+	}
+	adopted, err := repo.GetByContainerID("c-orphan")
+	if err != nil {
+		t.Fatalf("接管的实例未保存: %v", err)
+	}
+	if adopted.HoneypotName != "redis" || adopted.Protocol != "Redis" || adopted.Port != 16379 || adopted.LogParser != LogParserCowrie {
+		t.Errorf("接管的实例信息错误: %+v", adopted)
+	}
+
+	// 再次对账没有变化
+	result, err = reconciler.Reconcile(context.Background())
+	if err != nil {
+		t.Fatalf("对账失败: %v", err)
+	}
+	if len(result.Adopted)+len(result.Missing)+len(result.Updated) != 0 {
+		t.Errorf("重复对账不应产生变化: %+v", result)
+	}
+}
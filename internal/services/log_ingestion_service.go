@@ -0,0 +1,679 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/repositories"
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+const (
+	// ingestionScanInterval 扫描运行中蜜罐实例的间隔
+	ingestionScanInterval = 30 * time.Second
+	// ingestionFlushInterval 批量写入数据库的最长等待时间
+	ingestionFlushInterval = 2 * time.Second
+	// ingestionBatchSize 单次批量写入的最大行数
+	ingestionBatchSize = 100
+	// ingestionMaxBackoff 重连的最大等待时间
+	ingestionMaxBackoff = 30 * time.Second
+	// ingestionPollInterval 轮询容器内日志文件的间隔
+	ingestionPollInterval = 10 * time.Second
+	// ingestionMaxLineSize 单行日志的最大字节数，超出的部分被丢弃
+	ingestionMaxLineSize = 1024 * 1024
+)
+
+// 内置日志解析器名称
+const (
+	LogParserCowrie   = "cowrie"
+	LogParserHeadling = "headling"
+	LogParserDionaea  = "dionaea"
+	LogParserQeeqbox  = "qeeqbox"
+	LogParserGeneric  = "generic"
+)
+
+// 采集任务状态
+const (
+	IngestionStateRunning      = "running"
+	IngestionStateReconnecting = "reconnecting"
+	IngestionStateStopped      = "stopped"
+)
+
+// IngestionWorkerStatus 单个容器日志采集任务的状态
+type IngestionWorkerStatus struct {
+	ContainerID    string     `json:"container_id"`
+	ContainerName  string     `json:"container_name"`
+	InstanceID     uint       `json:"instance_id"`
+	Parser         string     `json:"parser"`
+	State          string     `json:"state"`
+	StartedAt      time.Time  `json:"started_at"`
+	LastLineAt     *time.Time `json:"last_line_at"`
+	StdoutLines    int64      `json:"stdout_lines"`
+	StderrLines    int64      `json:"stderr_lines"`
+	RecordsSaved   int64      `json:"records_saved"`
+	Reconnects     int        `json:"reconnects"`
+	LastError      string     `json:"last_error"`
+	LastErrorAt    *time.Time `json:"last_error_at"`
+	AutoDiscovered bool       `json:"auto_discovered"`
+}
+
+// IngestionStatus 日志采集子系统整体状态
+type IngestionStatus struct {
+	Running   bool                    `json:"running"`
+	StartedAt *time.Time              `json:"started_at"`
+	Workers   []IngestionWorkerStatus `json:"workers"`
+}
+
+// IngestResult 一批日志入库的结果
+type IngestResult struct {
+	Inserted int `json:"inserted"` // 新插入的记录数
+	Skipped  int `json:"skipped"`  // 已存在而被跳过的记录数
+	Rejected int `json:"rejected"` // 无法解析的记录数
+}
+
+// logLine 带来源流的日志行
+type logLine struct {
+	stream string
+	text   string
+}
+
+// LogIngestionManager 管理所有容器的日志跟随采集任务
+type LogIngestionManager struct {
+	mu        sync.Mutex
+	workers   map[string]*ingestionWorker
+	running   bool
+	startedAt *time.Time
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+var (
+	ingestionManager     *LogIngestionManager
+	ingestionManagerOnce sync.Once
+)
+
+// GetLogIngestionManager 获取全局日志采集管理器
+func GetLogIngestionManager() *LogIngestionManager {
+	ingestionManagerOnce.Do(func() {
+		ingestionManager = &LogIngestionManager{
+			workers: make(map[string]*ingestionWorker),
+		}
+	})
+	return ingestionManager
+}
+
+// Start 启动日志采集，自动为所有运行中的蜜罐实例创建跟随任务
+func (m *LogIngestionManager) Start() error {
+	if !IsDockerAvailable() {
+		return fmt.Errorf("Docker服务不可用")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	now := time.Now()
+	m.running = true
+	m.startedAt = &now
+	m.cancel = cancel
+	m.done = make(chan struct{})
+
+	go m.supervise(ctx, m.done)
+
+	fmt.Println("容器日志采集已启动")
+	return nil
+}
+
+// Stop 停止日志采集及所有跟随任务
+func (m *LogIngestionManager) Stop() {
+	m.mu.Lock()
+	if !m.running {
+		m.mu.Unlock()
+		return
+	}
+	m.running = false
+	m.startedAt = nil
+	m.cancel()
+	done := m.done
+	workers := m.workers
+	m.workers = make(map[string]*ingestionWorker)
+	m.mu.Unlock()
+
+	<-done
+	for _, w := range workers {
+		w.stop()
+	}
+
+	fmt.Println("容器日志采集已停止")
+}
+
+// StartContainer 手动为指定容器启动日志跟随任务，parser为空时根据镜像自动选择
+func (m *LogIngestionManager) StartContainer(containerID, parser string) error {
+	if !IsDockerAvailable() {
+		return fmt.Errorf("Docker服务不可用")
+	}
+
+	info, err := config.DockerCli.ContainerInspect(context.Background(), containerID)
+	if err != nil {
+		return fmt.Errorf("获取容器信息失败: %v", err)
+	}
+	if err := ValidateLogParser(parser); err != nil {
+		return err
+	}
+	parser = ResolveLogParser(parser, info.Config.Image)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.workers[info.ID]; exists {
+		return fmt.Errorf("容器 %s 的日志采集任务已存在", containerID)
+	}
+	m.startWorkerLocked(info.ID, strings.TrimPrefix(info.Name, "/"), 0, parser, false)
+	return nil
+}
+
+// StopContainer 停止指定容器的日志跟随任务
+func (m *LogIngestionManager) StopContainer(containerID string) error {
+	m.mu.Lock()
+	w := m.findWorkerLocked(containerID)
+	if w == nil {
+		m.mu.Unlock()
+		return fmt.Errorf("容器 %s 没有运行中的日志采集任务", containerID)
+	}
+	delete(m.workers, w.containerID)
+	m.mu.Unlock()
+
+	w.stop()
+	return nil
+}
+
+// Status 获取日志采集状态
+func (m *LogIngestionManager) Status() IngestionStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status := IngestionStatus{
+		Running:   m.running,
+		StartedAt: m.startedAt,
+		Workers:   make([]IngestionWorkerStatus, 0, len(m.workers)),
+	}
+	for _, w := range m.workers {
+		status.Workers = append(status.Workers, w.snapshot())
+	}
+	return status
+}
+
+// findWorkerLocked 根据完整或短容器ID查找采集任务
+func (m *LogIngestionManager) findWorkerLocked(containerID string) *ingestionWorker {
+	if w, ok := m.workers[containerID]; ok {
+		return w
+	}
+	for id, w := range m.workers {
+		if strings.HasPrefix(id, containerID) {
+			return w
+		}
+	}
+	return nil
+}
+
+// startWorkerLocked 创建并启动采集任务，调用方需持有锁
+// 解析器未注册时按通用日志处理
+func (m *LogIngestionManager) startWorkerLocked(containerID, containerName string, instanceID uint, parser string, auto bool) {
+	logParser, ok := GetLogParser(parser)
+	if !ok {
+		fmt.Printf("容器 %s 声明的日志解析器 %s 未注册，使用通用解析器\n", containerName, parser)
+		parser = logParser.Name()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &ingestionWorker{
+		containerID: containerID,
+		parser:      logParser,
+		cancel:      cancel,
+		done:        make(chan struct{}),
+		status: IngestionWorkerStatus{
+			ContainerID:    containerID,
+			ContainerName:  containerName,
+			InstanceID:     instanceID,
+			Parser:         parser,
+			State:          IngestionStateRunning,
+			StartedAt:      time.Now(),
+			AutoDiscovered: auto,
+		},
+	}
+	m.workers[containerID] = w
+	go w.run(ctx)
+	fmt.Printf("已启动容器 %s 的日志采集任务，解析器: %s\n", containerName, parser)
+}
+
+// supervise 定期扫描运行中的蜜罐实例，为新实例启动采集任务
+func (m *LogIngestionManager) supervise(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(ingestionScanInterval)
+	defer ticker.Stop()
+
+	for {
+		m.discoverInstances()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// discoverInstances 按数据库中蜜罐实例的运行状态同步采集任务
+func (m *LogIngestionManager) discoverInstances() {
+	service, err := NewHoneypotInstanceService()
+	if err != nil {
+		return
+	}
+
+	instances, err := service.GetAllInstances()
+	if err != nil {
+		fmt.Printf("获取蜜罐实例失败，跳过本轮日志采集扫描: %v\n", err)
+		return
+	}
+
+	m.syncWorkers(instances)
+}
+
+// syncWorkers 为运行中的实例启动日志采集，并停止实例已不在运行中的自动采集任务；
+// 手动启动的采集任务不受影响
+func (m *LogIngestionManager) syncWorkers(instances []repositories.HoneypotInstance) {
+	m.mu.Lock()
+	if !m.running {
+		m.mu.Unlock()
+		return
+	}
+
+	running := make(map[string]bool)
+	for _, instance := range instances {
+		if instance.ContainerID == "" || strings.HasPrefix(instance.ContainerID, "mock-") {
+			continue
+		}
+		if instance.Status != "running" {
+			continue
+		}
+		running[instance.ContainerID] = true
+		if _, exists := m.workers[instance.ContainerID]; exists {
+			continue
+		}
+		m.startWorkerLocked(instance.ContainerID, instance.ContainerName, instance.ID,
+			ResolveLogParser(instance.LogParser, instance.ImageName), true)
+	}
+
+	var stale []*ingestionWorker
+	for containerID, w := range m.workers {
+		if w.snapshot().AutoDiscovered && !running[containerID] {
+			delete(m.workers, containerID)
+			stale = append(stale, w)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, w := range stale {
+		w.stop()
+	}
+}
+
+// ingestionWorker 单个容器的日志跟随采集任务
+type ingestionWorker struct {
+	containerID string
+	parser      LogParser
+	cancel      context.CancelFunc
+	done        chan struct{}
+
+	mu       sync.Mutex
+	status   IngestionWorkerStatus
+	lastSeen time.Time
+}
+
+// stop 停止采集任务并等待退出
+func (w *ingestionWorker) stop() {
+	w.cancel()
+	<-w.done
+}
+
+// snapshot 获取任务状态副本
+func (w *ingestionWorker) snapshot() IngestionWorkerStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status
+}
+
+// run 跟随容器日志，断开后按指数退避重连(容器重启后会自动恢复)
+func (w *ingestionWorker) run(ctx context.Context) {
+	var polling sync.WaitGroup
+	defer func() {
+		polling.Wait()
+		close(w.done)
+	}()
+
+	// 结构化日志只写入文件的蜜罐(如Dionaea)需要定期拉取日志文件
+	if poller, ok := w.parser.(LogFilePoller); ok {
+		polling.Add(1)
+		go func() {
+			defer polling.Done()
+			w.pollLogFile(ctx, poller)
+		}()
+	}
+
+	backoff := time.Second
+	for {
+		linesBefore := w.lineCount()
+		err := w.follow(ctx)
+		if ctx.Err() != nil {
+			w.setState(IngestionStateStopped)
+			return
+		}
+
+		// 本次连接读到过日志，说明连接正常，重置退避时间
+		if w.lineCount() > linesBefore {
+			backoff = time.Second
+		}
+
+		w.mu.Lock()
+		now := time.Now()
+		w.status.State = IngestionStateReconnecting
+		w.status.Reconnects++
+		if err != nil {
+			w.status.LastError = err.Error()
+			w.status.LastErrorAt = &now
+		}
+		w.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			w.setState(IngestionStateStopped)
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > ingestionMaxBackoff {
+			backoff = ingestionMaxBackoff
+		}
+	}
+}
+
+// follow 建立一次日志跟随连接，直到日志流结束或出错
+func (w *ingestionWorker) follow(ctx context.Context) error {
+	info, err := config.DockerCli.ContainerInspect(ctx, w.containerID)
+	if err != nil {
+		return fmt.Errorf("获取容器信息失败: %v", err)
+	}
+	if info.State == nil || !info.State.Running {
+		return fmt.Errorf("容器未运行")
+	}
+
+	// 首次连接只采集之后产生的日志，重连时从上次读到的时间点继续
+	w.mu.Lock()
+	since := w.lastSeen
+	if since.IsZero() {
+		since = w.status.StartedAt
+	}
+	w.mu.Unlock()
+
+	reader, err := config.DockerCli.ContainerLogs(ctx, w.containerID, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+		Timestamps: true,
+		Since:      fmt.Sprintf("%d.%09d", since.Unix(), since.Nanosecond()),
+	})
+	if err != nil {
+		return fmt.Errorf("打开日志流失败: %v", err)
+	}
+	defer reader.Close()
+
+	w.setState(IngestionStateRunning)
+
+	lines := make(chan logLine, 256)
+	dispatched := make(chan struct{})
+	go func() {
+		defer close(dispatched)
+		w.dispatch(lines)
+	}()
+
+	var scanners sync.WaitGroup
+	scan := func(r io.Reader, stream string) {
+		defer scanners.Done()
+		err := readLogLines(r, ingestionMaxLineSize, func(line string) {
+			text := w.stripTimestamp(line)
+			if strings.TrimSpace(text) == "" {
+				return
+			}
+			lines <- logLine{stream: stream, text: text}
+		})
+		// 读取管道出错时关闭管道，避免StdCopy阻塞在写入上
+		if pipe, ok := r.(*io.PipeReader); ok && err != nil {
+			pipe.CloseWithError(err)
+		}
+	}
+
+	if info.Config != nil && info.Config.Tty {
+		// TTY模式下日志没有多路复用头部
+		scanners.Add(1)
+		scan(reader, "stdout")
+		err = nil
+	} else {
+		stdoutReader, stdoutWriter := io.Pipe()
+		stderrReader, stderrWriter := io.Pipe()
+		scanners.Add(2)
+		go scan(stdoutReader, "stdout")
+		go scan(stderrReader, "stderr")
+
+		_, err = stdcopy.StdCopy(stdoutWriter, stderrWriter, reader)
+		stdoutWriter.CloseWithError(err)
+		stderrWriter.CloseWithError(err)
+	}
+
+	scanners.Wait()
+	close(lines)
+	<-dispatched
+
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("读取日志流失败: %v", err)
+	}
+	return fmt.Errorf("日志流已结束，容器可能已停止或重启")
+}
+
+// readLogLines 逐行读取日志直到流结束，超过maxSize的行截断后继续读取，流正常结束时返回nil
+func readLogLines(r io.Reader, maxSize int, emit func(line string)) error {
+	reader := bufio.NewReaderSize(r, 64*1024)
+	var line []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if room := maxSize - len(line); room > 0 {
+			line = append(line, chunk[:min(len(chunk), room)]...)
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if len(line) > 0 {
+			emit(strings.TrimRight(string(line), "\r\n"))
+			line = line[:0]
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// stripTimestamp 去掉Docker添加的时间戳前缀并记录最后读到的时间
+func (w *ingestionWorker) stripTimestamp(line string) string {
+	idx := strings.IndexByte(line, ' ')
+	if idx <= 0 {
+		return line
+	}
+	ts, err := time.Parse(time.RFC3339Nano, line[:idx])
+	if err != nil {
+		return line
+	}
+
+	w.mu.Lock()
+	if ts.After(w.lastSeen) {
+		// Since参数包含边界，向后偏移1纳秒避免重复读取
+		w.lastSeen = ts.Add(time.Nanosecond)
+	}
+	w.mu.Unlock()
+
+	return line[idx+1:]
+}
+
+// dispatch 按批次把日志行交给对应的解析器
+func (w *ingestionWorker) dispatch(lines <-chan logLine) {
+	ticker := time.NewTicker(ingestionFlushInterval)
+	defer ticker.Stop()
+
+	var batch []logLine
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			batch = append(batch, line)
+			w.countLine(line.stream)
+			if len(batch) >= ingestionBatchSize {
+				w.flush(batch)
+				batch = nil
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				w.flush(batch)
+				batch = nil
+			}
+		}
+	}
+}
+
+// flush 把一批日志行按格式分发给对应蜜罐的解析器或通用解析器
+func (w *ingestionWorker) flush(batch []logLine) {
+	if len(batch) == 0 {
+		return
+	}
+
+	var structured, generic []string
+	for _, line := range batch {
+		if w.isStructured(line.text) {
+			structured = append(structured, line.text)
+		} else {
+			generic = append(generic, line.text)
+		}
+	}
+
+	var saved int
+	if len(structured) > 0 {
+		n, err := w.ingestStructured(structured)
+		saved += n
+		w.recordError(err)
+	}
+	if len(generic) > 0 {
+		n, err := IngestGenericLines(w.containerID, generic)
+		saved += n
+		w.recordError(err)
+	}
+
+	w.mu.Lock()
+	w.status.RecordsSaved += int64(saved)
+	w.mu.Unlock()
+}
+
+// isStructured 判断日志行是否为当前解析器能识别的结构化格式
+func (w *ingestionWorker) isStructured(line string) bool {
+	// 通过日志文件采集结构化日志的蜜罐，标准输出中只有运行日志
+	if _, ok := w.parser.(LogFilePoller); ok {
+		return false
+	}
+	return w.parser.Match(line)
+}
+
+// pollLogFile 定期按游标拉取容器内的日志文件，用于不向标准输出写结构化日志的蜜罐
+func (w *ingestionWorker) pollLogFile(ctx context.Context, poller LogFilePoller) {
+	ticker := time.NewTicker(ingestionPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		inserted, err := poller.Poll(w.containerID)
+		if err != nil {
+			w.recordError(err)
+			continue
+		}
+
+		w.mu.Lock()
+		w.status.RecordsSaved += int64(inserted)
+		w.mu.Unlock()
+	}
+}
+
+// ingestStructured 调用专用解析器保存结构化日志
+func (w *ingestionWorker) ingestStructured(lines []string) (int, error) {
+	return w.parser.Ingest(w.containerID, lines)
+}
+
+// countLine 更新读取行数统计
+func (w *ingestionWorker) countLine(stream string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	w.status.LastLineAt = &now
+	if stream == "stderr" {
+		w.status.StderrLines++
+	} else {
+		w.status.StdoutLines++
+	}
+}
+
+// lineCount 获取已读取的总行数
+func (w *ingestionWorker) lineCount() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status.StdoutLines + w.status.StderrLines
+}
+
+// setState 更新任务状态
+func (w *ingestionWorker) setState(state string) {
+	w.mu.Lock()
+	w.status.State = state
+	w.mu.Unlock()
+}
+
+// recordError 记录最近一次错误
+func (w *ingestionWorker) recordError(err error) {
+	if err == nil {
+		return
+	}
+	fmt.Printf("容器 %s 日志采集出错: %v\n", w.containerID, err)
+
+	w.mu.Lock()
+	now := time.Now()
+	w.status.LastError = err.Error()
+	w.status.LastErrorAt = &now
+	w.mu.Unlock()
+}
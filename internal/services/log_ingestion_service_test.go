@@ -0,0 +1,139 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/repositories"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// recordingLogParser 记录收到的日志行的解析器
+type recordingLogParser struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (p *recordingLogParser) Name() string           { return "recording" }
+func (p *recordingLogParser) Match(line string) bool { return true }
+func (p *recordingLogParser) Ingest(containerID string, lines []string) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lines = append(p.lines, lines...)
+	return len(lines), nil
+}
+
+// TestFollowOversizedLine 测试超过1MiB的日志行被截断，日志流读完后follow正常返回
+func TestFollowOversizedLine(t *testing.T) {
+	const ts = "2026-01-02T03:04:05.000000000Z "
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/containers/c-big/json"):
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"Id":     "c-big",
+				"State":  map[string]interface{}{"Running": true},
+				"Config": map[string]interface{}{"Tty": false},
+			})
+		case strings.HasSuffix(r.URL.Path, "/containers/c-big/logs"):
+			w.Header().Set("Content-Type", "application/vnd.docker.multiplexed-stream")
+			stdout := stdcopy.NewStdWriter(w, stdcopy.Stdout)
+			stderr := stdcopy.NewStdWriter(w, stdcopy.Stderr)
+			stdout.Write([]byte(ts + "before\n"))
+			stdout.Write([]byte(ts + strings.Repeat("x", 2*ingestionMaxLineSize) + "\n"))
+			stdout.Write([]byte(ts + "after\n"))
+			stderr.Write([]byte(ts + "stderr line\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cli, err := client.NewClientWithOpts(client.WithHost("tcp://"+server.Listener.Addr().String()), client.WithVersion("1.41"), client.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("创建Docker客户端失败: %v", err)
+	}
+	previous := config.DockerCli
+	config.DockerCli = cli
+	t.Cleanup(func() { config.DockerCli = previous })
+
+	parser := &recordingLogParser{}
+	worker := &ingestionWorker{containerID: "c-big", parser: parser, done: make(chan struct{})}
+	worker.status.StartedAt = time.Now()
+
+	result := make(chan error, 1)
+	go func() { result <- worker.follow(context.Background()) }()
+	select {
+	case err := <-result:
+		if err == nil || !strings.Contains(err.Error(), "日志流已结束") {
+			t.Fatalf("日志流结束后应返回结束错误: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("超长日志行导致follow无法返回")
+	}
+
+	parser.mu.Lock()
+	defer parser.mu.Unlock()
+	if len(parser.lines) != 4 {
+		t.Fatalf("应收到4行日志: %d", len(parser.lines))
+	}
+	var stdoutLines []string
+	for _, line := range parser.lines {
+		if line == "stderr line" {
+			continue
+		}
+		stdoutLines = append(stdoutLines, line)
+	}
+	if len(stdoutLines) != 3 || stdoutLines[0] != "before" || stdoutLines[2] != "after" {
+		t.Fatalf("标准输出日志顺序错误: %d行", len(stdoutLines))
+	}
+	if big := stdoutLines[1]; len(big) != ingestionMaxLineSize-len(ts) || strings.Trim(big, "x") != "" {
+		t.Errorf("超长日志行应截断为%d字节: %d", ingestionMaxLineSize-len(ts), len(big))
+	}
+}
+
+// TestSyncWorkersStopsStale 测试实例停止或被删除后自动发现的采集任务被停止，手动启动的任务保留
+func TestSyncWorkersStopsStale(t *testing.T) {
+	newWorker := func(containerID string, auto bool) (*ingestionWorker, *bool) {
+		cancelled := false
+		done := make(chan struct{})
+		w := &ingestionWorker{containerID: containerID, done: done, cancel: func() {
+			if !cancelled {
+				cancelled = true
+				close(done)
+			}
+		}}
+		w.status.AutoDiscovered = auto
+		return w, &cancelled
+	}
+	stopped, stoppedCancelled := newWorker("c-stopped", true)
+	deleted, deletedCancelled := newWorker("c-deleted", true)
+	manual, manualCancelled := newWorker("c-manual", false)
+	m := &LogIngestionManager{running: true, workers: map[string]*ingestionWorker{
+		"c-stopped": stopped,
+		"c-deleted": deleted,
+		"c-manual":  manual,
+	}}
+
+	m.syncWorkers([]repositories.HoneypotInstance{
+		{ContainerID: "c-stopped", Status: "stopped"},
+		{ContainerID: "c-missing", Status: "missing"},
+	})
+
+	if !*stoppedCancelled || !*deletedCancelled {
+		t.Errorf("不在运行中的实例的采集任务应被停止")
+	}
+	if *manualCancelled {
+		t.Errorf("手动启动的采集任务不应被停止")
+	}
+	if len(m.workers) != 1 || m.workers["c-manual"] != manual {
+		t.Errorf("剩余采集任务错误: %v", m.workers)
+	}
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LogParser 蜜罐日志解析器，把一种蜜罐输出的日志行解析并保存到对应的日志表
+// 新的蜜罐镜像只需要实现该接口并调用RegisterLogParser注册，日志跟随采集和syslog接收会自动使用
+type LogParser interface {
+	// Name 解析器名称，蜜罐模板和实例通过名称声明使用的解析器
+	Name() string
+	// Match 判断日志行是否为该解析器能识别的结构化格式，不能识别的行按通用日志处理
+	Match(line string) bool
+	// Ingest 解析并保存一批日志行，返回新插入的条数
+	Ingest(containerID string, lines []string) (int, error)
+}
+
+// LogFilePoller 结构化日志只写入容器内文件的蜜罐实现该接口，采集任务会定期调用Poll拉取
+// 实现了该接口的解析器在日志跟随采集中不再从标准输出识别结构化日志
+type LogFilePoller interface {
+	Poll(containerID string) (int, error)
+}
+
+// LogParserInfo 已注册解析器的描述
+type LogParserInfo struct {
+	Name          string   `json:"name"`
+	ImageKeywords []string `json:"image_keywords"`
+	PollsLogFile  bool     `json:"polls_log_file"`
+}
+
+// registeredParser 注册表中的解析器及其匹配的镜像关键字
+type registeredParser struct {
+	parser        LogParser
+	imageKeywords []string
+}
+
+var (
+	logParsersMu sync.RWMutex
+	logParsers   = make(map[string]*registeredParser)
+	// logParserOrder 按注册顺序匹配镜像关键字，保证检测结果稳定
+	logParserOrder []string
+)
+
+// RegisterLogParser 注册解析器，imageKeywords用于在未声明解析器时根据镜像名称自动选择
+// 同名解析器重复注册时后注册的覆盖先注册的
+func RegisterLogParser(parser LogParser, imageKeywords ...string) {
+	logParsersMu.Lock()
+	defer logParsersMu.Unlock()
+
+	name := parser.Name()
+	keywords := make([]string, 0, len(imageKeywords))
+	for _, keyword := range imageKeywords {
+		keywords = append(keywords, strings.ToLower(keyword))
+	}
+	if _, exists := logParsers[name]; !exists {
+		logParserOrder = append(logParserOrder, name)
+	}
+	logParsers[name] = &registeredParser{parser: parser, imageKeywords: keywords}
+}
+
+// GetLogParser 根据名称获取解析器，未注册的名称返回通用解析器和false
+func GetLogParser(name string) (LogParser, bool) {
+	logParsersMu.RLock()
+	defer logParsersMu.RUnlock()
+
+	if entry, ok := logParsers[name]; ok {
+		return entry.parser, true
+	}
+	return genericLogParser, false
+}
+
+// ValidateLogParser 检查解析器名称是否已注册，空名称表示自动选择
+func ValidateLogParser(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, ok := GetLogParser(name); !ok {
+		return fmt.Errorf("未知的日志解析器: %s", name)
+	}
+	return nil
+}
+
+// ListLogParsers 列出所有已注册的解析器
+func ListLogParsers() []LogParserInfo {
+	logParsersMu.RLock()
+	defer logParsersMu.RUnlock()
+
+	infos := make([]LogParserInfo, 0, len(logParsers))
+	for _, name := range logParserOrder {
+		entry := logParsers[name]
+		_, polls := entry.parser.(LogFilePoller)
+		infos = append(infos, LogParserInfo{
+			Name:          name,
+			ImageKeywords: entry.imageKeywords,
+			PollsLogFile:  polls,
+		})
+	}
+	sort.SliceStable(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// DetectLogParser 根据镜像名称选择日志解析器，没有匹配的关键字时使用通用解析器
+func DetectLogParser(imageName string) string {
+	image := strings.ToLower(imageName)
+
+	logParsersMu.RLock()
+	defer logParsersMu.RUnlock()
+
+	for _, name := range logParserOrder {
+		for _, keyword := range logParsers[name].imageKeywords {
+			if strings.Contains(image, keyword) {
+				return name
+			}
+		}
+	}
+	return LogParserGeneric
+}
+
+// ResolveLogParser 优先使用蜜罐实例或模板声明的解析器，未声明时根据镜像名称选择
+func ResolveLogParser(declared, imageName string) string {
+	if declared != "" {
+		return declared
+	}
+	return DetectLogParser(imageName)
+}
+
+// IngestParsedLines 用指定解析器保存一批结构化日志行，返回新插入的条数
+// containerID对于远程传感器是传感器标识，未注册的解析器按通用日志处理
+func IngestParsedLines(parser, containerID string, lines []string) (int, error) {
+	p, _ := GetLogParser(parser)
+	return p.Ingest(containerID, lines)
+}
+
+// funcLogParser 由函数组成的解析器
+type funcLogParser struct {
+	name   string
+	match  func(line string) bool
+	ingest func(containerID string, lines []string) (int, error)
+}
+
+// NewLogParser 用匹配和入库函数构造解析器，match为nil时不识别任何结构化日志
+func NewLogParser(name string, match func(line string) bool, ingest func(containerID string, lines []string) (int, error)) LogParser {
+	return &funcLogParser{name: name, match: match, ingest: ingest}
+}
+
+// Name 解析器名称
+func (p *funcLogParser) Name() string {
+	return p.name
+}
+
+// Match 判断日志行是否为结构化格式
+func (p *funcLogParser) Match(line string) bool {
+	return p.match != nil && p.match(line)
+}
+
+// Ingest 解析并保存日志行
+func (p *funcLogParser) Ingest(containerID string, lines []string) (int, error) {
+	return p.ingest(containerID, lines)
+}
+
+// isJSONLine 判断日志行是否为JSON对象，Cowrie、Dionaea和qeeqbox都是每行一个JSON事件
+func isJSONLine(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "{")
+}
+
+// genericLogParser 没有专用解析器的日志按语义分割保存为容器日志分析结果
+var genericLogParser = NewLogParser(LogParserGeneric, nil, IngestGenericLines)
+
+func init() {
+	RegisterLogParser(genericLogParser)
+}
@@ -0,0 +1,60 @@
+package services
+
+import "testing"
+
+// TestDetectLogParser 测试根据镜像名称选择已注册的解析器
+func TestDetectLogParser(t *testing.T) {
+	cases := map[string]string{
+		"andorralee/cowrie:v0.1":   LogParserCowrie,
+		"dinotools/dionaea:latest": LogParserDionaea,
+		"qeeqbox/honeypots:latest": LogParserQeeqbox,
+		"Headling:1.0":             LogParserHeadling,
+		"nginx:latest":             LogParserGeneric,
+	}
+	for image, want := range cases {
+		if got := DetectLogParser(image); got != want {
+			t.Errorf("镜像 %s 期望解析器 %s，实际为 %s", image, want, got)
+		}
+	}
+
+	if got := ResolveLogParser(LogParserQeeqbox, "andorralee/cowrie:v0.1"); got != LogParserQeeqbox {
+		t.Errorf("声明的解析器应优先于镜像匹配，实际为 %s", got)
+	}
+}
+
+// TestRegisterLogParser 测试注册新解析器后可按名称和镜像关键字使用
+func TestRegisterLogParser(t *testing.T) {
+	var ingested []string
+	RegisterLogParser(NewLogParser("test-parser", isJSONLine, func(containerID string, lines []string) (int, error) {
+		ingested = append(ingested, lines...)
+		return len(lines), nil
+	}), "example/test-honeypot")
+	defer func() {
+		logParsersMu.Lock()
+		delete(logParsers, "test-parser")
+		logParserOrder = logParserOrder[:len(logParserOrder)-1]
+		logParsersMu.Unlock()
+	}()
+
+	if got := DetectLogParser("Example/Test-Honeypot:2.0"); got != "test-parser" {
+		t.Fatalf("期望匹配新注册的解析器，实际为 %s", got)
+	}
+	parser, ok := GetLogParser("test-parser")
+	if !ok || !parser.Match(`{"event":"login"}`) || parser.Match("plain text") {
+		t.Fatal("新注册的解析器匹配规则错误")
+	}
+	if n, err := IngestParsedLines("test-parser", "c1", []string{`{"a":1}`}); err != nil || n != 1 || len(ingested) != 1 {
+		t.Errorf("入库结果错误: n=%d err=%v", n, err)
+	}
+
+	if err := ValidateLogParser("no-such-parser"); err == nil {
+		t.Error("未注册的解析器应当校验失败")
+	}
+	dionaea, ok := GetLogParser(LogParserDionaea)
+	if !ok {
+		t.Fatal("Dionaea解析器未注册")
+	}
+	if _, polls := dionaea.(LogFilePoller); !polls {
+		t.Error("Dionaea解析器应当轮询日志文件")
+	}
+}
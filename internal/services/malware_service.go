@@ -0,0 +1,322 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/repositories"
+	"andorralee/internal/utils"
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultCowrieDownloadDir Cowrie官方镜像中保存下载文件的目录
+const DefaultCowrieDownloadDir = "/cowrie/cowrie-git/var/lib/cowrie/downloads"
+
+// DefaultQuarantineDir 本地隔离区默认目录
+const DefaultQuarantineDir = "data/quarantine"
+
+// maxSampleSize 单个样本的最大大小，超过的文件不会被捕获
+const maxSampleSize = 64 << 20
+
+// MalwareService 恶意样本捕获服务
+type MalwareService struct {
+	Repo          repositories.MalwareSampleRepository
+	CowrieRepo    repositories.CowrieLogRepository
+	QuarantineDir string
+}
+
+// MalwareCaptureResult 样本捕获结果
+type MalwareCaptureResult struct {
+	Captured   int      `json:"captured"`    // 新增的捕获记录数
+	NewSamples int      `json:"new_samples"` // 新增的样本数
+	Failed     int      `json:"failed"`      // 捕获失败的事件数
+	Errors     []string `json:"errors,omitempty"`
+}
+
+// MalwareSampleDetail 样本详情，包含所有捕获记录
+type MalwareSampleDetail struct {
+	Sample    *repositories.MalwareSample    `json:"sample"`
+	Sightings []repositories.MalwareSighting `json:"sightings"`
+}
+
+// NewMalwareService 创建恶意样本捕获服务
+func NewMalwareService() (*MalwareService, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	return &MalwareService{
+		Repo:          repositories.NewMalwareSampleRepo(config.DB),
+		CowrieRepo:    repositories.NewCowrieLogRepo(config.DB),
+		QuarantineDir: QuarantineDir(),
+	}, nil
+}
+
+// QuarantineDir 获取隔离区目录，可通过MALWARE_QUARANTINE_DIR环境变量覆盖
+func QuarantineDir() string {
+	if dir := os.Getenv("MALWARE_QUARANTINE_DIR"); dir != "" {
+		return dir
+	}
+	return DefaultQuarantineDir
+}
+
+// CowrieDownloadDir 获取容器内Cowrie下载目录，可通过COWRIE_DOWNLOAD_DIR环境变量覆盖
+func CowrieDownloadDir() string {
+	if dir := os.Getenv("COWRIE_DOWNLOAD_DIR"); dir != "" {
+		return dir
+	}
+	return DefaultCowrieDownloadDir
+}
+
+// CaptureFromContainer 捕获容器中所有尚未处理的文件传输事件对应的样本
+func (s *MalwareService) CaptureFromContainer(containerID string) (*MalwareCaptureResult, error) {
+	logs, err := s.CowrieRepo.GetFileTransferEventsByContainerID(containerID)
+	if err != nil {
+		return nil, fmt.Errorf("获取文件传输事件失败: %v", err)
+	}
+	return s.CaptureFromLogs(containerID, logs), nil
+}
+
+// CaptureFromLogs 从一批Cowrie日志中找出文件传输事件并捕获对应样本
+func (s *MalwareService) CaptureFromLogs(containerID string, logs []repositories.CowrieLog) *MalwareCaptureResult {
+	result := &MalwareCaptureResult{}
+
+	for _, log := range logs {
+		if log.EventID != repositories.CowrieEventFileDownload && log.EventID != repositories.CowrieEventFileUpload {
+			continue
+		}
+		// 下载失败的事件没有shasum，容器中也没有对应文件
+		if log.Shasum == "" || log.ID == 0 {
+			continue
+		}
+
+		exists, err := s.Repo.HasSighting(log.ID)
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("事件 %d: %v", log.ID, err))
+			continue
+		}
+		if exists {
+			continue
+		}
+
+		newSample, err := s.captureEvent(containerID, log)
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("事件 %d: %v", log.ID, err))
+			continue
+		}
+		result.Captured++
+		if newSample {
+			result.NewSamples++
+		}
+	}
+
+	if result.Captured > 0 {
+		fmt.Printf("从容器 %s 捕获了 %d 个样本文件，其中新样本 %d 个\n", containerID, result.Captured, result.NewSamples)
+	}
+	return result
+}
+
+// captureEvent 从容器中取出文件传输事件对应的文件，计算哈希后存入隔离区并记录捕获
+func (s *MalwareService) captureEvent(containerID string, log repositories.CowrieLog) (bool, error) {
+	data, err := readContainerSample(containerID, cowrieSamplePath(log))
+	if err != nil {
+		return false, err
+	}
+
+	// Cowrie按内容的SHA256命名下载文件，不一致说明容器中的文件已被替换或不完整，不作为该事件的样本
+	sample := hashSample(data)
+	if !strings.EqualFold(sample.SHA256, log.Shasum) {
+		return false, fmt.Errorf("样本哈希与Cowrie记录不一致: 记录 %s, 实际 %s", log.Shasum, sample.SHA256)
+	}
+
+	existing, err := s.Repo.GetBySHA256(sample.SHA256)
+	if err != nil {
+		return false, fmt.Errorf("查询样本失败: %v", err)
+	}
+
+	newSample := existing == nil
+	if newSample {
+		quarantinePath, err := s.quarantine(sample.SHA256, data)
+		if err != nil {
+			return false, err
+		}
+		sample.QuarantinePath = quarantinePath
+		sample.FirstSeen = log.EventTime
+		sample.LastSeen = log.EventTime
+		sample.SightingCount = 1
+	} else {
+		sample = existing
+		sample.SightingCount++
+		if log.EventTime.After(sample.LastSeen) {
+			sample.LastSeen = log.EventTime
+		}
+		if log.EventTime.Before(sample.FirstSeen) {
+			sample.FirstSeen = log.EventTime
+		}
+	}
+
+	sighting := &repositories.MalwareSighting{
+		SHA256:      sample.SHA256,
+		CowrieLogID: log.ID,
+		SessionID:   log.SessionID,
+		SourceIP:    log.SourceIP,
+		EventID:     log.EventID,
+		URL:         log.URL,
+		Filename:    log.Filename,
+		ContainerID: containerID,
+		EventTime:   log.EventTime,
+	}
+	if err := s.Repo.RecordSighting(sample, sighting); err != nil {
+		return false, fmt.Errorf("保存样本和捕获记录失败: %v", err)
+	}
+	return newSample, nil
+}
+
+// quarantine 将样本以内容寻址的方式写入隔离区，已存在时直接复用
+func (s *MalwareService) quarantine(sha256Sum string, data []byte) (string, error) {
+	dir := filepath.Join(s.QuarantineDir, sha256Sum[:2])
+	target := filepath.Join(dir, sha256Sum)
+
+	if _, err := os.Stat(target); err == nil {
+		return target, nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("创建隔离区目录失败: %v", err)
+	}
+
+	// 先写临时文件再重命名，避免留下不完整的样本
+	tmp, err := os.CreateTemp(dir, sha256Sum+".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("创建隔离文件失败: %v", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("写入隔离文件失败: %v", err)
+	}
+	tmp.Close()
+
+	// 样本只读且不可执行
+	if err := os.Chmod(tmp.Name(), 0400); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("设置隔离文件权限失败: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("保存隔离文件失败: %v", err)
+	}
+	return target, nil
+}
+
+// ListSamples 获取所有样本
+func (s *MalwareService) ListSamples() ([]repositories.MalwareSample, error) {
+	return s.Repo.List()
+}
+
+// ListSamplesPage 按查询条件分页获取样本，同时返回符合条件的总数
+func (s *MalwareService) ListSamplesPage(spec repositories.QuerySpec) (*repositories.Page[repositories.MalwareSample], error) {
+	return s.Repo.Query(spec)
+}
+
+// GetSample 根据SHA256获取样本及其捕获记录
+func (s *MalwareService) GetSample(sha256Sum string) (*MalwareSampleDetail, error) {
+	sample, err := s.Repo.GetBySHA256(strings.ToLower(sha256Sum))
+	if err != nil {
+		return nil, err
+	}
+	if sample == nil {
+		return nil, nil
+	}
+
+	sightings, err := s.Repo.GetSightingsBySampleID(sample.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &MalwareSampleDetail{Sample: sample, Sightings: sightings}, nil
+}
+
+// GetSightingsBySourceIP 获取攻击者IP投放的所有样本记录
+func (s *MalwareService) GetSightingsBySourceIP(sourceIP string) ([]repositories.MalwareSighting, error) {
+	return s.Repo.GetSightingsBySourceIP(sourceIP)
+}
+
+// captureCowrieMalware 在Cowrie日志入库后捕获其中的样本，捕获失败不影响日志入库
+func captureCowrieMalware(containerID string, logs []repositories.CowrieLog) {
+	service, err := NewMalwareService()
+	if err != nil {
+		return
+	}
+	result := service.CaptureFromLogs(containerID, logs)
+	for _, msg := range result.Errors {
+		fmt.Printf("捕获样本失败: %s\n", msg)
+	}
+}
+
+// cowrieSamplePath 根据文件传输事件推断样本在容器中的路径
+// Cowrie的outfile是相对于安装目录的路径，文件名即SHA256
+func cowrieSamplePath(log repositories.CowrieLog) string {
+	if path.IsAbs(log.Outfile) {
+		return log.Outfile
+	}
+	name := log.Shasum
+	if log.Outfile != "" {
+		name = path.Base(log.Outfile)
+	}
+	return path.Join(CowrieDownloadDir(), name)
+}
+
+// readContainerSample 从容器中读取样本文件
+func readContainerSample(containerID, filePath string) ([]byte, error) {
+	file, err := OpenContainerFile(containerID, filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if file.Size > maxSampleSize {
+		return nil, fmt.Errorf("样本文件 %s 过大(%d字节)", filePath, file.Size)
+	}
+	data, err := io.ReadAll(file.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("读取样本文件 %s 失败: %v", filePath, err)
+	}
+	return data, nil
+}
+
+// hashSample 计算样本的各类哈希
+func hashSample(data []byte) *repositories.MalwareSample {
+	md5Sum := md5.Sum(data)
+	sha1Sum := sha1.Sum(data)
+	sha256Sum := sha256.Sum256(data)
+
+	return &repositories.MalwareSample{
+		SHA256:   hex.EncodeToString(sha256Sum[:]),
+		SHA1:     hex.EncodeToString(sha1Sum[:]),
+		MD5:      hex.EncodeToString(md5Sum[:]),
+		SSDeep:   utils.SSDeep(data),
+		FileSize: int64(len(data)),
+		FileType: detectSampleType(data),
+	}
+}
+
+// detectSampleType 识别样本类型，蜜罐中常见的ELF和脚本单独识别
+func detectSampleType(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x7fELF")):
+		return "application/x-executable"
+	case bytes.HasPrefix(data, []byte("#!")):
+		return "text/x-shellscript"
+	}
+	return http.DetectContentType(data)
+}
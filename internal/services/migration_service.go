@@ -0,0 +1,58 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/migrations"
+)
+
+// MigrationStatusResult 数据库迁移状态
+type MigrationStatusResult struct {
+	Backend        string              `json:"backend"`         // 迁移脚本对应的数据库类型
+	CurrentVersion int                 `json:"current_version"` // 已执行的最高版本，0表示尚未执行任何迁移
+	LatestVersion  int                 `json:"latest_version"`  // 程序内置的最新版本
+	Pending        int                 `json:"pending"`         // 未执行的迁移数
+	Migrations     []migrations.Status `json:"migrations"`
+}
+
+// MigrationService 数据库迁移服务
+type MigrationService struct {
+	Runner *migrations.Runner
+}
+
+// NewMigrationService 创建数据库迁移服务
+func NewMigrationService() (*MigrationService, error) {
+	runner, err := migrations.NewRunner(config.DB)
+	if err != nil {
+		return nil, err
+	}
+	return &MigrationService{Runner: runner}, nil
+}
+
+// Status 获取主数据库的迁移状态
+func (s *MigrationService) Status() (*MigrationStatusResult, error) {
+	statuses, err := s.Runner.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MigrationStatusResult{Backend: s.Runner.Backend(), Migrations: statuses}
+	for _, status := range statuses {
+		result.LatestVersion = status.Version
+		if status.Applied {
+			result.CurrentVersion = status.Version
+		} else {
+			result.Pending++
+		}
+	}
+	return result, nil
+}
+
+// Up 执行未执行的迁移，target为0时执行到最新版本
+func (s *MigrationService) Up(target int) ([]migrations.Status, error) {
+	return s.Runner.Up(target)
+}
+
+// Down 回滚最近执行的steps个迁移
+func (s *MigrationService) Down(steps int) ([]migrations.Status, error) {
+	return s.Runner.Down(steps)
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/migrations"
+	"andorralee/internal/repositories"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// TestMigrationScriptsLoad 测试每种数据库的迁移脚本都能加载，且从基线版本开始
+func TestMigrationScriptsLoad(t *testing.T) {
+	for _, backend := range []string{migrations.BackendMySQL, migrations.BackendSQLite, migrations.BackendDameng} {
+		loaded, err := migrations.Load(backend)
+		if err != nil {
+			t.Fatalf("加载%s迁移脚本失败: %v", backend, err)
+		}
+		if len(loaded) == 0 || loaded[0].Version != 1 || loaded[0].Name != "baseline" {
+			t.Fatalf("%s的迁移应从基线版本开始: %+v", backend, loaded)
+		}
+		// 基线版本为基线模型建表，之后新增的模型由后续版本建表
+		for _, model := range repositories.BaselineModels() {
+			table := model.(interface{ TableName() string }).TableName()
+			if !strings.Contains(loaded[0].Up, "CREATE TABLE `"+table+"`") && !strings.Contains(loaded[0].Up, `CREATE TABLE "`+table+`"`) {
+				t.Errorf("%s基线版本缺少表%s", backend, table)
+			}
+		}
+		var all strings.Builder
+		for _, m := range loaded {
+			all.WriteString(m.Up)
+		}
+		for _, model := range repositories.Models() {
+			table := model.(interface{ TableName() string }).TableName()
+			if !strings.Contains(all.String(), "CREATE TABLE `"+table+"`") && !strings.Contains(all.String(), `CREATE TABLE "`+table+`"`) {
+				t.Errorf("%s的迁移缺少表%s", backend, table)
+			}
+		}
+	}
+}
+
+// TestSplitStatements 测试迁移脚本按行尾分号拆分语句并忽略注释
+func TestSplitStatements(t *testing.T) {
+	script := "-- 注释\nCREATE TABLE a (\n    id INT\n);\n\n-- 第二条\nDROP VIEW IF EXISTS b;\nSELECT 1"
+	got := migrations.SplitStatements(script)
+	want := []string{"CREATE TABLE a (\n    id INT\n)", "DROP VIEW IF EXISTS b", "SELECT 1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("拆分结果错误: %q", got)
+	}
+}
+
+// TestSQLiteMigrationsMatchModels 测试SQLite基线版本建出的表与模型一致，AutoMigrate不需要再做修改
+func TestSQLiteMigrationsMatchModels(t *testing.T) {
+	useSQLiteDatabase(t)
+
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	db := config.DB.Session(&gorm.Session{Logger: recorder})
+	if err := db.AutoMigrate(repositories.Models()...); err != nil {
+		t.Fatalf("AutoMigrate失败: %v", err)
+	}
+	for _, statement := range recorder.statements {
+		if strings.HasPrefix(statement, "CREATE") || strings.HasPrefix(statement, "ALTER") || strings.HasPrefix(statement, "DROP") {
+			t.Errorf("基线版本与模型不一致，AutoMigrate执行了: %s", statement)
+		}
+	}
+}
+
+// TestMigrationDownAndUp 测试回滚和重新执行迁移
+func TestMigrationDownAndUp(t *testing.T) {
+	useSQLiteDatabase(t)
+
+	service, err := NewMigrationService()
+	if err != nil {
+		t.Fatalf("创建迁移服务失败: %v", err)
+	}
+	status, err := service.Status()
+	if err != nil {
+		t.Fatalf("获取迁移状态失败: %v", err)
+	}
+	if status.Backend != migrations.BackendSQLite || status.Pending != 0 || status.CurrentVersion != status.LatestVersion {
+		t.Fatalf("启动后迁移应全部执行: %+v", status)
+	}
+
+	if _, err := service.Down(len(status.Migrations)); err != nil {
+		t.Fatalf("回滚失败: %v", err)
+	}
+	if config.DB.Migrator().HasTable(&repositories.CowrieLog{}) {
+		t.Fatalf("回滚基线版本后表应被删除")
+	}
+	if status, _ := service.Status(); status.CurrentVersion != 0 || status.Pending != len(status.Migrations) {
+		t.Fatalf("回滚后迁移应全部未执行: %+v", status)
+	}
+
+	applied, err := service.Up(0)
+	if err != nil || len(applied) != len(status.Migrations) {
+		t.Fatalf("重新执行迁移失败: %+v err=%v", applied, err)
+	}
+	if !config.DB.Migrator().HasTable(&repositories.CowrieLog{}) {
+		t.Fatalf("重新执行后表应存在")
+	}
+}
+
+// TestMigrationAdoptsLegacySchema 测试引入版本管理之前由AutoMigrate创建的数据库可以直接升级
+func TestMigrationAdoptsLegacySchema(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "legacy.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("打开SQLite失败: %v", err)
+	}
+	// 旧版本只建了部分表
+	if err := db.AutoMigrate(&repositories.HoneypotTemplate{}, &repositories.CowrieLog{}); err != nil {
+		t.Fatalf("建表失败: %v", err)
+	}
+	if err := db.Create(&repositories.CowrieLog{AuthID: "a1", SessionID: "s1", SourceIP: "1.2.3.4", Protocol: "ssh", RawLog: "{}"}).Error; err != nil {
+		t.Fatalf("写入旧数据失败: %v", err)
+	}
+
+	runner, err := migrations.NewRunner(db)
+	if err != nil {
+		t.Fatalf("创建迁移执行器失败: %v", err)
+	}
+	if _, err := runner.Up(0); err != nil {
+		t.Fatalf("升级旧数据库失败: %v", err)
+	}
+
+	pending, err := runner.Pending()
+	if err != nil || len(pending) != 0 {
+		t.Fatalf("升级后不应有未执行的迁移: %+v err=%v", pending, err)
+	}
+	if !db.Migrator().HasTable(&repositories.AttackEvent{}) {
+		t.Errorf("缺少的表应被补齐")
+	}
+	if !db.Migrator().HasTable(&repositories.RetentionArchive{}) {
+		t.Errorf("基线之后的迁移应在补齐基线版本后执行")
+	}
+	var count int64
+	if db.Model(&repositories.CowrieLog{}).Count(&count); count != 1 {
+		t.Errorf("已有数据应保留: %d", count)
+	}
+}
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/repositories"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/google/uuid"
+)
+
+// qeeqboxNamespace 用于根据原始日志行生成确定性ID的命名空间
+var qeeqboxNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("andorralee/qeeqbox"))
+
+// qeeqboxBaseFields qeeqbox日志中的公共字段，其余字段保存到Data中
+var qeeqboxBaseFields = map[string]bool{
+	"action": true, "server": true, "status": true, "timestamp": true,
+	"src_ip": true, "src_port": true, "dest_ip": true, "dest_port": true,
+	"username": true, "password": true,
+}
+
+// QeeqboxService qeeqbox/honeypots蜜罐日志服务
+type QeeqboxService struct {
+	Repo repositories.QeeqboxLogRepository
+}
+
+// NewQeeqboxService 创建qeeqbox服务
+func NewQeeqboxService() (*QeeqboxService, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	return &QeeqboxService{
+		Repo: repositories.NewQeeqboxLogRepo(config.DB),
+	}, nil
+}
+
+// init 注册qeeqbox日志解析器
+func init() {
+	RegisterLogParser(NewLogParser(LogParserQeeqbox, isJSONLine, func(containerID string, lines []string) (int, error) {
+		service, err := NewQeeqboxService()
+		if err != nil {
+			return 0, err
+		}
+		return service.IngestLines(containerID, lines)
+	}), "qeeqbox", "honeypots")
+}
+
+// PullQeeqboxLogs 从容器的标准输出中拉取qeeqbox日志
+// qeeqbox以--termlogs方式运行时把JSON日志写到标准输出，已入库的记录会被跳过
+func (s *QeeqboxService) PullQeeqboxLogs(containerID string) (*IngestResult, error) {
+	if !IsDockerAvailable() {
+		return nil, fmt.Errorf("Docker服务不可用")
+	}
+
+	lines, err := readContainerOutputLines(containerID)
+	if err != nil {
+		return nil, fmt.Errorf("读取容器日志失败: %v", err)
+	}
+
+	// 标准输出中还有程序自身的运行日志，只处理JSON行
+	var jsonLines []string
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "{") {
+			jsonLines = append(jsonLines, line)
+		}
+	}
+
+	result, err := s.saveLogs(jsonLines, containerID)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("容器 %s 的qeeqbox日志拉取完成: 新增 %d 条, 跳过 %d 条, 无法解析 %d 条\n",
+		containerID, result.Inserted, result.Skipped, result.Rejected)
+	return result, nil
+}
+
+// IngestLines 解析并保存一批qeeqbox JSON日志行，返回新插入的条数
+func (s *QeeqboxService) IngestLines(containerID string, lines []string) (int, error) {
+	result, err := s.saveLogs(lines, containerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.Inserted, nil
+}
+
+// saveLogs 解析JSON日志行并批量保存到数据库，auth_id已存在的记录计为跳过
+func (s *QeeqboxService) saveLogs(lines []string, containerID string) (*IngestResult, error) {
+	containerName := s.getContainerName(containerID)
+
+	var logs []repositories.QeeqboxLog
+	rejected := 0
+	for i, line := range lines {
+		log, err := parseQeeqboxEvent(line)
+		if err != nil {
+			fmt.Printf("跳过解析失败的记录 %d: %v\n", i+1, err)
+			rejected++
+			continue
+		}
+		log.AuthID = uuid.NewSHA1(qeeqboxNamespace, []byte(containerID+"|"+line)).String()
+		log.ContainerID = containerID
+		log.ContainerName = containerName
+		logs = append(logs, *log)
+	}
+
+	inserted, err := s.Repo.CreateBatch(logs)
+	if err != nil {
+		return nil, fmt.Errorf("保存日志到数据库失败: %v", err)
+	}
+	if inserted > 0 {
+		recordTimeline(TimelineEventsFromQeeqbox(logs))
+	}
+	return &IngestResult{
+		Inserted: int(inserted),
+		Skipped:  len(logs) - int(inserted),
+		Rejected: rejected,
+	}, nil
+}
+
+// parseQeeqboxEvent 解析一行qeeqbox JSON日志，提取凭据和攻击者的命令
+func parseQeeqboxEvent(line string) (*repositories.QeeqboxLog, error) {
+	var logData map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &logData); err != nil {
+		return nil, fmt.Errorf("JSON解析失败: %v", err)
+	}
+
+	server := getString(logData, "server")
+	action := getString(logData, "action")
+	if server == "" || action == "" {
+		return nil, fmt.Errorf("缺少server或action字段")
+	}
+
+	eventTime, err := parseISOTime(getString(logData, "timestamp"))
+	if err != nil {
+		return nil, fmt.Errorf("时间戳解析失败: %v", err)
+	}
+
+	log := &repositories.QeeqboxLog{
+		EventTime:       eventTime,
+		Server:          server,
+		Protocol:        strings.TrimSuffix(server, "_server"),
+		Action:          action,
+		Status:          getString(logData, "status"),
+		SourceIP:        getString(logData, "src_ip"),
+		SourcePort:      uint16(getInt(logData, "src_port")),
+		DestinationIP:   getString(logData, "dest_ip"),
+		DestinationPort: uint16(getInt(logData, "dest_port")),
+		Username:        getString(logData, "username"),
+		Password:        getString(logData, "password"),
+		RawLog:          line,
+	}
+
+	// 各协议的附加字段不同，统一保存为JSON，并从中提取命令或查询语句
+	extra := make(map[string]interface{})
+	for key, value := range logData {
+		if !qeeqboxBaseFields[key] {
+			extra[key] = value
+		}
+	}
+	if len(extra) > 0 {
+		if data, err := json.Marshal(extra); err == nil {
+			log.Data = string(data)
+		}
+	}
+	log.Detail = qeeqboxDetail(extra)
+
+	return log, nil
+}
+
+// qeeqboxDetail 从附加字段中提取攻击者执行的命令或查询
+func qeeqboxDetail(extra map[string]interface{}) string {
+	if data, ok := extra["data"]; ok {
+		switch v := data.(type) {
+		case string:
+			return v
+		case map[string]interface{}:
+			for _, key := range []string{"command", "query", "cmd", "args"} {
+				if value, exists := v[key]; exists {
+					return fmt.Sprint(value)
+				}
+			}
+		}
+	}
+	for _, key := range []string{"command", "query", "cmd"} {
+		if value := getString(extra, key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// readContainerOutputLines 读取容器标准输出和标准错误的全部日志行
+func readContainerOutputLines(containerID string) ([]string, error) {
+	ctx := context.Background()
+	info, err := config.DockerCli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := config.DockerCli.ContainerLogs(ctx, containerID, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	// 非TTY容器的日志流带有多路复用头部
+	var output bytes.Buffer
+	if info.Config != nil && info.Config.Tty {
+		_, err = io.Copy(&output, reader)
+	} else {
+		_, err = stdcopy.StdCopy(&output, &output, reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(&output)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// getContainerName 获取容器名称
+func (s *QeeqboxService) getContainerName(containerID string) string {
+	if !IsDockerAvailable() {
+		return ""
+	}
+
+	containerInfo, err := GetContainerInfo(containerID)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(containerInfo.Name, "/")
+}
+
+// GetAllLogs 获取所有日志
+func (s *QeeqboxService) GetAllLogs() ([]repositories.QeeqboxLog, error) {
+	return s.Repo.List()
+}
+
+// GetLogsPage 按查询条件分页获取qeeqbox日志，同时返回符合条件的总数
+func (s *QeeqboxService) GetLogsPage(spec repositories.QuerySpec) (*repositories.Page[repositories.QeeqboxLog], error) {
+	return s.Repo.Query(spec)
+}
+
+// GetLogByID 根据ID获取日志
+func (s *QeeqboxService) GetLogByID(id uint) (*repositories.QeeqboxLog, error) {
+	return s.Repo.GetByID(id)
+}
+
+// GetLogsByContainerID 根据容器ID获取日志
+func (s *QeeqboxService) GetLogsByContainerID(containerID string) ([]repositories.QeeqboxLog, error) {
+	return s.Repo.GetByContainerID(containerID)
+}
+
+// GetLogsBySourceIP 根据源IP获取日志
+func (s *QeeqboxService) GetLogsBySourceIP(sourceIP string) ([]repositories.QeeqboxLog, error) {
+	return s.Repo.GetBySourceIP(sourceIP)
+}
+
+// GetLogsByProtocol 根据协议获取日志
+func (s *QeeqboxService) GetLogsByProtocol(protocol string) ([]repositories.QeeqboxLog, error) {
+	return s.Repo.GetByProtocol(protocol)
+}
+
+// GetLogsByAction 根据动作获取日志
+func (s *QeeqboxService) GetLogsByAction(action string) ([]repositories.QeeqboxLog, error) {
+	return s.Repo.GetByAction(action)
+}
+
+// GetLogsByTimeRange 根据时间范围获取日志
+func (s *QeeqboxService) GetLogsByTimeRange(startTime, endTime time.Time) ([]repositories.QeeqboxLog, error) {
+	return s.Repo.GetByTimeRange(startTime, endTime)
+}
+
+// SearchLogs 按组合条件查询日志
+func (s *QeeqboxService) SearchLogs(filter repositories.QeeqboxLogFilter) ([]repositories.QeeqboxLog, error) {
+	return s.Repo.Search(filter)
+}
+
+// DeleteLogsByContainerID 删除容器相关日志
+func (s *QeeqboxService) DeleteLogsByContainerID(containerID string) error {
+	return s.Repo.DeleteByContainerID(containerID)
+}
+
+// GetStatistics 获取按协议的统计信息
+func (s *QeeqboxService) GetStatistics() ([]repositories.QeeqboxStatistics, error) {
+	return s.Repo.GetStatistics()
+}
+
+// GetTopAttackers 获取顶级攻击者
+func (s *QeeqboxService) GetTopAttackers(limit int) ([]repositories.QeeqboxAttackerStatistics, error) {
+	return s.Repo.GetTopAttackers(limit)
+}
+
+// GetTopUsernames 获取常用用户名
+func (s *QeeqboxService) GetTopUsernames(limit int) ([]map[string]interface{}, error) {
+	return s.Repo.GetTopUsernames(limit)
+}
+
+// GetTopPasswords 获取常用密码
+func (s *QeeqboxService) GetTopPasswords(limit int) ([]map[string]interface{}, error) {
+	return s.Repo.GetTopPasswords(limit)
+}
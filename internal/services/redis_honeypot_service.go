@@ -0,0 +1,1147 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/repositories"
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// RedisHoneypotContainerID 内置Redis蜜罐写入日志表container_id字段的标识
+	RedisHoneypotContainerID = "native:redis"
+
+	// redisIdleTimeout 两条命令之间的最长空闲时间
+	redisIdleTimeout = 5 * time.Minute
+	// redisMaxArgs 一条命令的最大参数个数
+	redisMaxArgs = 1024
+	// redisMaxBulk 单个参数的最大字节数
+	redisMaxBulk = 1024 * 1024
+	// redisMaxCommandBytes 一条命令所有参数合计的最大字节数，超过时返回协议错误并断开连接
+	redisMaxCommandBytes = 4 * 1024 * 1024
+	// redisMaxInline 内联命令一行的最大字节数
+	redisMaxInline = 64 * 1024
+	// redisMaxKeys 模拟键空间最多保存的键数，超过时返回OOM
+	redisMaxKeys = 10000
+	// redisMaxKeyspaceBytes 模拟键空间中键和值的总字节数上限，超过时返回OOM
+	redisMaxKeyspaceBytes = 64 * 1024 * 1024
+	// redisMaxPayload 写入攻击事件的命令最大长度
+	redisMaxPayload = 64 * 1024
+	// redisDatabases SELECT允许的数据库个数
+	redisDatabases = 16
+	// redisRecordBatch 每个连接攒够多少条命令后写入一次数据库
+	redisRecordBatch = 100
+	// redisRecordInterval 每个连接未攒满一批时写入数据库的间隔
+	redisRecordInterval = time.Second
+)
+
+// 内置Redis蜜罐记录的攻击类型
+const (
+	RedisAttackRCE        = "redis rce"         // 主从复制、加载模块、Lua脚本和写文件利用链
+	RedisAttackBruteForce = "redis brute force" // AUTH口令猜测
+	RedisAttackProbe      = "redis probe"       // 信息收集
+	RedisAttackCommand    = "redis command"     // 其他命令
+)
+
+// RedisHoneypotStatus 内置Redis蜜罐状态
+type RedisHoneypotStatus struct {
+	Running          bool       `json:"running"`
+	StartedAt        *time.Time `json:"started_at"`
+	Listener         string     `json:"listener"`
+	Version          string     `json:"version"`
+	RequireAuth      bool       `json:"require_auth"`
+	Connections      int        `json:"connections"`
+	TotalConnections int64      `json:"total_connections"`
+	Commands         int64      `json:"commands"`
+	AuthAttempts     int64      `json:"auth_attempts"`
+	Keys             int        `json:"keys"`
+	KeyspaceBytes    int        `json:"keyspace_bytes"`
+	LastError        string     `json:"last_error"`
+	LastErrorAt      *time.Time `json:"last_error_at"`
+}
+
+// RedisHoneypot 内置Redis蜜罐，模拟RESP协议和常见的利用命令，每条命令记录为攻击事件
+type RedisHoneypot struct {
+	mu       sync.Mutex
+	running  bool
+	cancel   context.CancelFunc
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	serving  sync.WaitGroup
+	cfg      config.RedisHoneypotConfig
+	status   RedisHoneypotStatus
+
+	// 以下为所有连接共享的模拟服务端状态，每次启动时重置
+	runID        string
+	processID    int64
+	keyspaces    [redisDatabases]map[string]string
+	keyspaceKeys int // 所有数据库的键数
+	keyspaceSize int // 所有数据库中键和值的总字节数
+	params       map[string]string
+	modules      []string
+	scripts      map[string]struct{}
+	masterHost   string
+	masterPort   string
+	lastSave     time.Time
+}
+
+var (
+	redisHoneypot     *RedisHoneypot
+	redisHoneypotOnce sync.Once
+)
+
+// GetRedisHoneypot 获取全局内置Redis蜜罐
+func GetRedisHoneypot() *RedisHoneypot {
+	redisHoneypotOnce.Do(func() {
+		redisHoneypot = &RedisHoneypot{}
+	})
+	return redisHoneypot
+}
+
+// Start 按配置打开监听并重置模拟的键空间和配置
+func (h *RedisHoneypot) Start() error {
+	if config.DB == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.running {
+		return nil
+	}
+
+	cfg := config.LoadConfig().Redis
+	if cfg.Version == "" || strings.ContainsAny(cfg.Version, "\r\n") {
+		return fmt.Errorf("无效的REDIS_HONEYPOT_VERSION: %q", cfg.Version)
+	}
+
+	listener, err := net.Listen("tcp", cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("监听Redis %s 失败: %v", cfg.Addr, err)
+	}
+	_, port := splitHostPort(listener.Addr())
+
+	runID := make([]byte, 20)
+	rand.Read(runID)
+	pid, _ := rand.Int(rand.Reader, big.NewInt(30000))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	now := time.Now()
+	h.cfg = cfg
+	h.listener = listener
+	h.conns = make(map[net.Conn]struct{})
+	h.cancel = cancel
+	h.running = true
+	h.runID = hex.EncodeToString(runID)
+	h.processID = pid.Int64() + 1000
+	for i := range h.keyspaces {
+		h.keyspaces[i] = make(map[string]string)
+	}
+	h.keyspaceKeys, h.keyspaceSize = 0, 0
+	h.params = map[string]string{
+		"dir":             "/var/lib/redis",
+		"dbfilename":      "dump.rdb",
+		"requirepass":     cfg.Password,
+		"masterauth":      "",
+		"bind":            "0.0.0.0",
+		"port":            strconv.FormatUint(uint64(port), 10),
+		"protected-mode":  "no",
+		"daemonize":       "yes",
+		"databases":       strconv.Itoa(redisDatabases),
+		"maxmemory":       "0",
+		"maxclients":      "10000",
+		"appendonly":      "no",
+		"save":            "900 1 300 10 60 10000",
+		"slave-read-only": "yes",
+		"logfile":         "/var/log/redis/redis-server.log",
+	}
+	h.modules = nil
+	h.scripts = make(map[string]struct{})
+	h.masterHost, h.masterPort = "", ""
+	h.lastSave = now
+	h.status = RedisHoneypotStatus{
+		Running:   true,
+		StartedAt: &now,
+		Listener:  listener.Addr().String(),
+		Version:   cfg.Version,
+	}
+
+	h.serving.Add(1)
+	go h.serve(ctx, listener)
+
+	fmt.Printf("内置Redis蜜罐已启动，监听: %s\n", h.status.Listener)
+	return nil
+}
+
+// Stop 关闭监听和所有连接
+func (h *RedisHoneypot) Stop() {
+	h.mu.Lock()
+	if !h.running {
+		h.mu.Unlock()
+		return
+	}
+	h.running = false
+	h.cancel()
+	h.listener.Close()
+	for conn := range h.conns {
+		conn.Close()
+	}
+	h.mu.Unlock()
+
+	h.serving.Wait()
+
+	h.mu.Lock()
+	h.status.Running = false
+	h.status.StartedAt = nil
+	h.status.Listener = ""
+	h.mu.Unlock()
+
+	fmt.Println("内置Redis蜜罐已停止")
+}
+
+// Status 获取蜜罐状态
+func (h *RedisHoneypot) Status() RedisHoneypotStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	status := h.status
+	status.Connections = len(h.conns)
+	status.Keys = h.keyspaceKeys
+	status.KeyspaceBytes = h.keyspaceSize
+	status.RequireAuth = h.params["requirepass"] != ""
+	return status
+}
+
+// serve 接受连接，超过最大连接数时直接关闭新连接
+func (h *RedisHoneypot) serve(ctx context.Context, listener net.Listener) {
+	defer h.serving.Done()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				h.recordError(fmt.Errorf("接受Redis连接失败: %v", err))
+			}
+			return
+		}
+
+		h.mu.Lock()
+		if ctx.Err() != nil || (h.cfg.MaxSessions > 0 && len(h.conns) >= h.cfg.MaxSessions) {
+			h.mu.Unlock()
+			conn.Close()
+			continue
+		}
+		h.conns[conn] = struct{}{}
+		h.status.TotalConnections++
+		h.serving.Add(1)
+		h.mu.Unlock()
+
+		go h.serveConn(conn)
+	}
+}
+
+// redisConnState 一个连接的会话信息
+type redisConnState struct {
+	sessionID  string
+	authed     bool
+	db         int
+	clientName string
+	createdAt  time.Time
+}
+
+// errRedisQuit 客户端要求关闭连接
+var errRedisQuit = errors.New("quit")
+
+// serveConn 逐条读取命令，交给记录协程批量写入后返回模拟的响应
+func (h *RedisHoneypot) serveConn(conn net.Conn) {
+	records := make(chan redisRecord, redisRecordBatch)
+	recorded := make(chan struct{})
+	go func() {
+		defer close(recorded)
+		h.recordCommands(records)
+	}()
+
+	defer func() {
+		conn.Close()
+		h.mu.Lock()
+		delete(h.conns, conn)
+		h.mu.Unlock()
+		// 等待剩余的记录写入后才算连接处理结束，Stop返回时所有命令都已入库
+		close(records)
+		<-recorded
+		h.serving.Done()
+	}()
+
+	h.mu.Lock()
+	state := &redisConnState{sessionID: uuid.New().String(), authed: h.params["requirepass"] == "", createdAt: time.Now()}
+	h.mu.Unlock()
+
+	reader := bufio.NewReaderSize(conn, redisMaxInline)
+	writer := bufio.NewWriter(conn)
+	for {
+		conn.SetDeadline(time.Now().Add(redisIdleTimeout))
+		args, err := readRedisCommand(reader)
+		if err != nil {
+			var protocolErr redisProtocolError
+			if errors.As(err, &protocolErr) {
+				writeRedisError(writer, "ERR Protocol error: "+protocolErr.Error())
+				writer.Flush()
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		// 记录协程写库跟不上时通道写满，连接随之阻塞
+		records <- h.recordCommand(conn, state, args)
+		err = h.execute(writer, conn, state, args)
+		// 管道中的命令全部处理完再发送响应
+		if reader.Buffered() == 0 || err != nil {
+			writer.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// redisProtocolError 客户端发送的数据不符合RESP协议
+type redisProtocolError string
+
+func (e redisProtocolError) Error() string {
+	return string(e)
+}
+
+// readRedisCommand 读取一条RESP数组命令或内联命令
+func readRedisCommand(reader *bufio.Reader) ([]string, error) {
+	prefix, err := reader.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	if prefix[0] != '*' {
+		line, err := readRedisLine(reader)
+		if err != nil {
+			if errors.Is(err, bufio.ErrBufferFull) {
+				return nil, redisProtocolError("too big inline request")
+			}
+			return nil, err
+		}
+		return splitRedisInline(line)
+	}
+
+	line, err := readRedisLine(reader)
+	if err != nil {
+		if errors.Is(err, bufio.ErrBufferFull) {
+			return nil, redisProtocolError("too big mbulk count string")
+		}
+		return nil, err
+	}
+	count, err := strconv.Atoi(line[1:])
+	if err != nil || count > redisMaxArgs {
+		return nil, redisProtocolError("invalid multibulk length")
+	}
+
+	args := make([]string, 0, max(count, 0))
+	total := 0
+	for i := 0; i < count; i++ {
+		line, err := readRedisLine(reader)
+		if err != nil {
+			if errors.Is(err, bufio.ErrBufferFull) {
+				return nil, redisProtocolError("too big bulk count string")
+			}
+			return nil, err
+		}
+		if line == "" || line[0] != '$' {
+			return nil, redisProtocolError(fmt.Sprintf("expected '$', got '%s'", line[:min(len(line), 1)]))
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 || size > redisMaxBulk {
+			return nil, redisProtocolError("invalid bulk length")
+		}
+		// 在分配缓冲区之前检查，避免一条命令的大量参数占用过多内存
+		total += size
+		if total > redisMaxCommandBytes {
+			return nil, redisProtocolError("too big request")
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		args = append(args, string(buf[:size]))
+	}
+	return args, nil
+}
+
+// readRedisLine 读取一行并去掉行尾的CRLF
+func readRedisLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadSlice('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
+
+// splitRedisInline 按空白拆分内联命令，支持单引号和双引号包含的参数
+func splitRedisInline(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(line[i+1:], c)
+			if end < 0 {
+				return nil, redisProtocolError("unbalanced quotes in request")
+			}
+			value := line[i+1 : i+1+end]
+			if c == '"' {
+				if unquoted, err := strconv.Unquote(`"` + value + `"`); err == nil {
+					value = unquoted
+				}
+			}
+			current.WriteString(value)
+			inArg = true
+			i += end + 1
+		case c == ' ' || c == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteByte(c)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// redisCommandArity 支持的命令及其参数个数(含命令名)，负数表示至少需要的个数
+var redisCommandArity = map[string]int{
+	"ping": -1, "echo": 2, "auth": -2, "select": 2, "quit": 1, "info": -1, "config": -2,
+	"slaveof": 3, "replicaof": 3, "module": -2, "eval": -3, "evalsha": -3, "script": -2,
+	"get": 2, "set": -3, "setnx": 3, "del": -2, "exists": -2, "keys": 2, "scan": -2,
+	"type": 2, "ttl": 2, "expire": 3, "randomkey": 1, "dbsize": 1, "flushall": -1,
+	"flushdb": -1, "save": 1, "bgsave": -1, "lastsave": 1, "client": -2, "command": -1,
+	"time": 1,
+}
+
+// execute 执行一条命令并写入响应，返回错误时关闭连接
+func (h *RedisHoneypot) execute(w *bufio.Writer, conn net.Conn, state *redisConnState, args []string) error {
+	name := strings.ToLower(args[0])
+
+	// 与Redis一样，把发往Redis端口的HTTP请求视为跨协议攻击并关闭连接
+	if name == "post" || name == "host:" {
+		return errRedisQuit
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	keyspace := h.keyspaces[state.db]
+	arity, known := redisCommandArity[name]
+	if !known && strings.Contains(name, ".") && len(h.modules) > 0 {
+		// 已加载模块注册的命令，如恶意模块的system.exec
+		writeRedisBulk(w, "")
+		return nil
+	}
+	if !known {
+		writeRedisError(w, redisUnknownCommand(args))
+		return nil
+	}
+	if (arity > 0 && len(args) != arity) || (arity < 0 && len(args) < -arity) || (name == "auth" && len(args) > 3) {
+		writeRedisError(w, fmt.Sprintf("ERR wrong number of arguments for '%s' command", name))
+		return nil
+	}
+	if !state.authed && name != "auth" && name != "quit" {
+		writeRedisError(w, "NOAUTH Authentication required.")
+		return nil
+	}
+
+	switch name {
+	case "ping":
+		if len(args) > 1 {
+			writeRedisBulk(w, args[1])
+		} else {
+			writeRedisSimple(w, "PONG")
+		}
+	case "echo":
+		writeRedisBulk(w, args[1])
+	case "auth":
+		h.status.AuthAttempts++
+		password := h.params["requirepass"]
+		switch {
+		case len(args) == 3:
+			// AUTH <username> <password>，只有default用户，未设置口令时接受任意口令
+			if args[1] != "default" || (password != "" && args[2] != password) {
+				writeRedisError(w, "WRONGPASS invalid username-password pair or user is disabled.")
+				break
+			}
+			state.authed = true
+			writeRedisSimple(w, "OK")
+		case password == "":
+			writeRedisError(w, "ERR Client sent AUTH, but no password is set")
+		case args[1] != password:
+			writeRedisError(w, "ERR invalid password")
+		default:
+			state.authed = true
+			writeRedisSimple(w, "OK")
+		}
+	case "select":
+		index, err := strconv.Atoi(args[1])
+		if err != nil {
+			writeRedisError(w, "ERR invalid DB index")
+		} else if index < 0 || index >= redisDatabases {
+			writeRedisError(w, "ERR DB index is out of range")
+		} else {
+			state.db = index
+			writeRedisSimple(w, "OK")
+		}
+	case "quit":
+		writeRedisSimple(w, "OK")
+		return errRedisQuit
+	case "info":
+		section := "default"
+		if len(args) > 1 {
+			section = strings.ToLower(args[1])
+		}
+		writeRedisBulk(w, h.info(section))
+	case "config":
+		h.config(w, args)
+	case "slaveof", "replicaof":
+		if strings.EqualFold(args[1], "no") && strings.EqualFold(args[2], "one") {
+			h.masterHost, h.masterPort = "", ""
+			writeRedisSimple(w, "OK")
+		} else if _, err := strconv.ParseUint(args[2], 10, 16); err != nil {
+			writeRedisError(w, "ERR Invalid master port")
+		} else {
+			// 只记录主节点地址，不会真正连接攻击者的主节点
+			h.masterHost, h.masterPort = args[1], args[2]
+			writeRedisSimple(w, "OK Already connected to specified master")
+		}
+	case "module":
+		h.module(w, args)
+	case "eval", "evalsha":
+		numKeys, err := strconv.Atoi(args[2])
+		switch {
+		case err != nil:
+			writeRedisError(w, "ERR value is not an integer or out of range")
+		case numKeys < 0:
+			writeRedisError(w, "ERR Number of keys can't be negative")
+		case numKeys > len(args)-3:
+			writeRedisError(w, "ERR Number of keys can't be greater than number of args")
+		default:
+			if name == "evalsha" {
+				if _, ok := h.scripts[strings.ToLower(args[1])]; !ok {
+					writeRedisError(w, "NOSCRIPT No matching script. Please use EVAL.")
+					break
+				}
+			} else {
+				h.scripts[redisScriptSHA(args[1])] = struct{}{}
+			}
+			writeRedisNil(w)
+		}
+	case "script":
+		h.script(w, args)
+	case "get":
+		if value, ok := keyspace[args[1]]; ok {
+			writeRedisBulk(w, value)
+		} else {
+			writeRedisNil(w)
+		}
+	case "set", "setnx":
+		_, exists := keyspace[args[1]]
+		nx, xx := name == "setnx", false
+		for _, option := range args[3:] {
+			switch strings.ToUpper(option) {
+			case "NX":
+				nx = true
+			case "XX":
+				xx = true
+			}
+		}
+		switch {
+		case (nx && exists) || (xx && !exists):
+			if name == "setnx" {
+				writeRedisInt(w, 0)
+			} else {
+				writeRedisNil(w)
+			}
+		case !h.setKey(state.db, args[1], args[2]):
+			writeRedisError(w, "OOM command not allowed when used memory > 'maxmemory'.")
+		default:
+			if name == "setnx" {
+				writeRedisInt(w, 1)
+			} else {
+				writeRedisSimple(w, "OK")
+			}
+		}
+	case "del", "exists":
+		var count int64
+		for _, key := range args[1:] {
+			if _, ok := keyspace[key]; ok {
+				count++
+				if name == "del" {
+					h.deleteKey(state.db, key)
+				}
+			}
+		}
+		writeRedisInt(w, count)
+	case "keys":
+		writeRedisArray(w, matchRedisKeys(keyspace, args[1]))
+	case "scan":
+		pattern := "*"
+		for i := 2; i+1 < len(args); i += 2 {
+			if strings.EqualFold(args[i], "match") {
+				pattern = args[i+1]
+			}
+		}
+		w.WriteString("*2\r\n")
+		writeRedisBulk(w, "0")
+		writeRedisArray(w, matchRedisKeys(keyspace, pattern))
+	case "type":
+		if _, ok := keyspace[args[1]]; ok {
+			writeRedisSimple(w, "string")
+		} else {
+			writeRedisSimple(w, "none")
+		}
+	case "ttl":
+		if _, ok := keyspace[args[1]]; ok {
+			writeRedisInt(w, -1)
+		} else {
+			writeRedisInt(w, -2)
+		}
+	case "expire":
+		if _, ok := keyspace[args[1]]; ok {
+			writeRedisInt(w, 1)
+		} else {
+			writeRedisInt(w, 0)
+		}
+	case "randomkey":
+		if keys := matchRedisKeys(keyspace, "*"); len(keys) > 0 {
+			writeRedisBulk(w, keys[0])
+		} else {
+			writeRedisNil(w)
+		}
+	case "dbsize":
+		writeRedisInt(w, int64(len(keyspace)))
+	case "flushall":
+		for db := range h.keyspaces {
+			h.flushKeyspace(db)
+		}
+		writeRedisSimple(w, "OK")
+	case "flushdb":
+		h.flushKeyspace(state.db)
+		writeRedisSimple(w, "OK")
+	case "save":
+		h.lastSave = time.Now()
+		writeRedisSimple(w, "OK")
+	case "bgsave":
+		h.lastSave = time.Now()
+		writeRedisSimple(w, "Background saving started")
+	case "lastsave":
+		writeRedisInt(w, h.lastSave.Unix())
+	case "client":
+		h.client(w, conn, state, args)
+	case "command":
+		if len(args) > 1 && strings.EqualFold(args[1], "count") {
+			writeRedisInt(w, int64(len(redisCommandArity)))
+		} else {
+			writeRedisArray(w, nil)
+		}
+	case "time":
+		now := time.Now()
+		writeRedisArray(w, []string{strconv.FormatInt(now.Unix(), 10), strconv.Itoa(now.Nanosecond() / 1000)})
+	}
+	return nil
+}
+
+// config 处理CONFIG GET/SET/RESETSTAT/REWRITE
+func (h *RedisHoneypot) config(w *bufio.Writer, args []string) {
+	subcommand := strings.ToLower(args[1])
+	switch {
+	case subcommand == "get" && len(args) == 3:
+		names := make([]string, 0, len(h.params))
+		for name := range h.params {
+			if ok, _ := path.Match(strings.ToLower(args[2]), name); ok {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		values := make([]string, 0, len(names)*2)
+		for _, name := range names {
+			values = append(values, name, h.params[name])
+		}
+		writeRedisArray(w, values)
+	case subcommand == "set" && len(args) == 4:
+		name, value := strings.ToLower(args[2]), args[3]
+		if _, ok := h.params[name]; !ok {
+			writeRedisError(w, "ERR Unsupported CONFIG parameter: "+args[2])
+			return
+		}
+		switch {
+		case name == "dir" && !strings.HasPrefix(value, "/"):
+			writeRedisError(w, "ERR Changing directory: No such file or directory")
+		case name == "dbfilename" && (value == "" || strings.Contains(value, "/")):
+			writeRedisError(w, fmt.Sprintf("ERR Invalid argument '%s' for CONFIG SET '%s'", value, args[2]))
+		default:
+			h.params[name] = value
+			writeRedisSimple(w, "OK")
+		}
+	case (subcommand == "resetstat" || subcommand == "rewrite") && len(args) == 2:
+		writeRedisSimple(w, "OK")
+	default:
+		writeRedisError(w, fmt.Sprintf("ERR Unknown subcommand or wrong number of arguments for '%s'. Try CONFIG HELP.", args[1]))
+	}
+}
+
+// module 处理MODULE LOAD/LIST/UNLOAD，加载的模块只记录名称
+func (h *RedisHoneypot) module(w *bufio.Writer, args []string) {
+	subcommand := strings.ToLower(args[1])
+	switch {
+	case subcommand == "load" && len(args) >= 3:
+		name := strings.TrimSuffix(path.Base(args[2]), path.Ext(args[2]))
+		for _, loaded := range h.modules {
+			if loaded == name {
+				writeRedisError(w, "ERR Error loading the extension. Please check the server logs.")
+				return
+			}
+		}
+		h.modules = append(h.modules, name)
+		writeRedisSimple(w, "OK")
+	case subcommand == "unload" && len(args) == 3:
+		for i, loaded := range h.modules {
+			if loaded == args[2] {
+				h.modules = append(h.modules[:i], h.modules[i+1:]...)
+				writeRedisSimple(w, "OK")
+				return
+			}
+		}
+		writeRedisError(w, "ERR Error unloading module: no such module with that name")
+	case subcommand == "list" && len(args) == 2:
+		fmt.Fprintf(w, "*%d\r\n", len(h.modules))
+		for _, name := range h.modules {
+			w.WriteString("*4\r\n")
+			writeRedisBulk(w, "name")
+			writeRedisBulk(w, name)
+			writeRedisBulk(w, "ver")
+			writeRedisInt(w, 1)
+		}
+	default:
+		writeRedisError(w, fmt.Sprintf("ERR Unknown subcommand or wrong number of arguments for '%s'. Try MODULE HELP.", args[1]))
+	}
+}
+
+// script 处理SCRIPT LOAD/EXISTS/FLUSH
+func (h *RedisHoneypot) script(w *bufio.Writer, args []string) {
+	subcommand := strings.ToLower(args[1])
+	switch {
+	case subcommand == "load" && len(args) == 3:
+		sha := redisScriptSHA(args[2])
+		h.scripts[sha] = struct{}{}
+		writeRedisBulk(w, sha)
+	case subcommand == "exists" && len(args) >= 3:
+		fmt.Fprintf(w, "*%d\r\n", len(args)-2)
+		for _, sha := range args[2:] {
+			if _, ok := h.scripts[strings.ToLower(sha)]; ok {
+				writeRedisInt(w, 1)
+			} else {
+				writeRedisInt(w, 0)
+			}
+		}
+	case subcommand == "flush":
+		h.scripts = make(map[string]struct{})
+		writeRedisSimple(w, "OK")
+	default:
+		writeRedisError(w, fmt.Sprintf("ERR Unknown subcommand or wrong number of arguments for '%s'. Try SCRIPT HELP.", args[1]))
+	}
+}
+
+// client 处理CLIENT SETNAME/GETNAME/ID/LIST，其他子命令直接返回OK
+func (h *RedisHoneypot) client(w *bufio.Writer, conn net.Conn, state *redisConnState, args []string) {
+	switch strings.ToLower(args[1]) {
+	case "setname":
+		if len(args) != 3 || strings.ContainsAny(args[2], " \r\n") {
+			writeRedisError(w, "ERR Client names cannot contain spaces, newlines or special characters.")
+			return
+		}
+		state.clientName = args[2]
+		writeRedisSimple(w, "OK")
+	case "getname":
+		if state.clientName == "" {
+			writeRedisNil(w)
+		} else {
+			writeRedisBulk(w, state.clientName)
+		}
+	case "id":
+		writeRedisInt(w, h.status.TotalConnections)
+	case "list":
+		writeRedisBulk(w, fmt.Sprintf("id=%d addr=%s fd=8 name=%s age=%d idle=0 flags=N db=%d sub=0 psub=0 multi=-1 qbuf=26 qbuf-free=32742 obl=0 oll=0 omem=0 events=r cmd=client\n",
+			h.status.TotalConnections, conn.RemoteAddr(), state.clientName, int(time.Since(state.createdAt).Seconds()), state.db))
+	default:
+		writeRedisSimple(w, "OK")
+	}
+}
+
+// info 生成INFO命令返回的内容
+func (h *RedisHoneypot) info(section string) string {
+	uptime := int64(0)
+	if h.status.StartedAt != nil {
+		uptime = int64(time.Since(*h.status.StartedAt).Seconds())
+	}
+	memory := 853000 + 96*h.keyspaceKeys + h.keyspaceSize
+	role := "role:master\r\nconnected_slaves:0\r\n"
+	if h.masterHost != "" {
+		role = fmt.Sprintf("role:slave\r\nmaster_host:%s\r\nmaster_port:%s\r\nmaster_link_status:down\r\nmaster_last_io_seconds_ago:-1\r\nmaster_sync_in_progress:0\r\nslave_repl_offset:1\r\nslave_priority:100\r\nslave_read_only:1\r\nconnected_slaves:0\r\n", h.masterHost, h.masterPort)
+	}
+	keyspace := ""
+	for db, keys := range h.keyspaces {
+		if len(keys) > 0 {
+			keyspace += fmt.Sprintf("db%d:keys=%d,expires=0,avg_ttl=0\r\n", db, len(keys))
+		}
+	}
+
+	sections := []struct {
+		name string
+		body string
+	}{
+		{"server", fmt.Sprintf("redis_version:%s\r\nredis_git_sha1:00000000\r\nredis_git_dirty:0\r\nredis_build_id:636cde3b5c7a3923\r\nredis_mode:standalone\r\nos:Linux 5.4.0-100-generic x86_64\r\narch_bits:64\r\nmultiplexing_api:epoll\r\natomicvar_api:atomic-builtin\r\ngcc_version:9.3.0\r\nprocess_id:%d\r\nrun_id:%s\r\ntcp_port:%s\r\nuptime_in_seconds:%d\r\nuptime_in_days:%d\r\nhz:10\r\nconfigured_hz:10\r\nlru_clock:%d\r\nexecutable:/usr/bin/redis-server\r\nconfig_file:/etc/redis/redis.conf\r\n",
+			h.cfg.Version, h.processID, h.runID, h.params["port"], uptime, uptime/86400, time.Now().Unix()%(1<<24))},
+		{"clients", fmt.Sprintf("connected_clients:%d\r\nclient_recent_max_input_buffer:2\r\nclient_recent_max_output_buffer:0\r\nblocked_clients:0\r\n", len(h.conns))},
+		{"memory", fmt.Sprintf("used_memory:%d\r\nused_memory_human:%.2fK\r\nused_memory_rss:4018176\r\nused_memory_rss_human:3.83M\r\nused_memory_peak:%d\r\nmaxmemory:0\r\nmaxmemory_human:0B\r\nmaxmemory_policy:noeviction\r\nmem_fragmentation_ratio:4.71\r\nmem_allocator:jemalloc-5.2.1\r\n",
+			memory, float64(memory)/1024, memory)},
+		{"persistence", fmt.Sprintf("loading:0\r\nrdb_changes_since_last_save:0\r\nrdb_bgsave_in_progress:0\r\nrdb_last_save_time:%d\r\nrdb_last_bgsave_status:ok\r\naof_enabled:0\r\naof_rewrite_in_progress:0\r\n", h.lastSave.Unix())},
+		{"stats", fmt.Sprintf("total_connections_received:%d\r\ntotal_commands_processed:%d\r\ninstantaneous_ops_per_sec:0\r\nrejected_connections:0\r\nexpired_keys:0\r\nevicted_keys:0\r\nkeyspace_hits:0\r\nkeyspace_misses:0\r\n", h.status.TotalConnections, h.status.Commands)},
+		{"replication", role + "master_replid:" + h.runID + "\r\nmaster_repl_offset:0\r\nrepl_backlog_active:0\r\n"},
+		{"cpu", fmt.Sprintf("used_cpu_sys:%.6f\r\nused_cpu_user:%.6f\r\nused_cpu_sys_children:0.000000\r\nused_cpu_user_children:0.000000\r\n", float64(uptime)*0.0012, float64(uptime)*0.0009)},
+		{"modules", ""},
+		{"keyspace", keyspace},
+	}
+
+	var b strings.Builder
+	for _, s := range sections {
+		all := section == "all" || section == "everything" || (section == "default" && s.name != "modules")
+		if !all && section != s.name {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString("# " + strings.ToUpper(s.name[:1]) + s.name[1:] + "\r\n" + s.body)
+	}
+	return b.String()
+}
+
+// setKey 写入键值并更新键空间的键数和总字节数，超过上限时不写入并返回false，调用方持有锁
+func (h *RedisHoneypot) setKey(db int, key, value string) bool {
+	keys, size := h.keyspaceKeys, h.keyspaceSize+len(value)
+	if old, exists := h.keyspaces[db][key]; exists {
+		size -= len(old)
+	} else {
+		keys++
+		size += len(key)
+	}
+	if keys > redisMaxKeys || size > redisMaxKeyspaceBytes {
+		return false
+	}
+	h.keyspaces[db][key] = value
+	h.keyspaceKeys, h.keyspaceSize = keys, size
+	return true
+}
+
+// deleteKey 删除键并更新键空间的键数和总字节数，调用方持有锁
+func (h *RedisHoneypot) deleteKey(db int, key string) {
+	if value, ok := h.keyspaces[db][key]; ok {
+		delete(h.keyspaces[db], key)
+		h.keyspaceKeys--
+		h.keyspaceSize -= len(key) + len(value)
+	}
+}
+
+// flushKeyspace 清空一个数据库，调用方持有锁
+func (h *RedisHoneypot) flushKeyspace(db int) {
+	for key, value := range h.keyspaces[db] {
+		h.keyspaceKeys--
+		h.keyspaceSize -= len(key) + len(value)
+	}
+	h.keyspaces[db] = make(map[string]string)
+}
+
+// matchRedisKeys 返回数据库中匹配模式的键
+func matchRedisKeys(keyspace map[string]string, pattern string) []string {
+	keys := make([]string, 0)
+	for key := range keyspace {
+		if ok, _ := path.Match(pattern, key); ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// redisRecord 等待写入数据库的一条命令，AUTH命令同时带有认证日志
+type redisRecord struct {
+	event   repositories.AttackEvent
+	authLog *repositories.HeadlingAuthLog
+}
+
+// recordCommand 把命令转换为攻击事件，AUTH的用户名和口令同时转换为headling_auth_log记录
+func (h *RedisHoneypot) recordCommand(conn net.Conn, state *redisConnState, args []string) redisRecord {
+	now := time.Now()
+	sourceIP, sourcePort := splitHostPort(conn.RemoteAddr())
+	destIP, destPort := splitHostPort(conn.LocalAddr())
+	payload := truncate(formatRedisCommand(args), redisMaxPayload)
+	attackType := classifyRedisCommand(args)
+
+	h.mu.Lock()
+	containerName := h.cfg.ContainerName
+	h.status.Commands++
+	h.mu.Unlock()
+
+	record := redisRecord{event: repositories.AttackEvent{
+		SourceIP:      sourceIP,
+		SourcePort:    int(sourcePort),
+		DestIP:        destIP,
+		DestPort:      int(destPort),
+		Protocol:      "redis",
+		AttackType:    attackType,
+		Payload:       payload,
+		Timestamp:     now,
+		Severity:      AnalyzeSeverity(attackType, payload),
+		ContainerID:   RedisHoneypotContainerID,
+		ContainerName: containerName,
+		UserAgent:     truncate(state.clientName, 512),
+		SessionID:     state.sessionID,
+	}}
+
+	if strings.EqualFold(args[0], "auth") && (len(args) == 2 || len(args) == 3) {
+		// AUTH <password>不带用户名，AUTH <username> <password>为Redis 6的ACL形式
+		username, password := "", args[1]
+		if len(args) == 3 {
+			username, password = args[1], args[2]
+		}
+		record.authLog = &repositories.HeadlingAuthLog{
+			Timestamp:       now,
+			AuthID:          uuid.New().String(),
+			SessionID:       state.sessionID,
+			SourceIP:        sourceIP,
+			SourcePort:      sourcePort,
+			DestinationIP:   destIP,
+			DestinationPort: destPort,
+			Protocol:        "redis",
+			Username:        truncate(username, 255),
+			Password:        truncate(password, 255),
+			ContainerID:     RedisHoneypotContainerID,
+			ContainerName:   containerName,
+			CreatedAt:       now,
+		}
+	}
+	return record
+}
+
+// recordCommands 批量写入一个连接的命令记录，攒够一批或到达写入间隔时写入一次，通道关闭时写入剩余的记录
+func (h *RedisHoneypot) recordCommands(records <-chan redisRecord) {
+	ticker := time.NewTicker(redisRecordInterval)
+	defer ticker.Stop()
+
+	batch := make([]redisRecord, 0, redisRecordBatch)
+	for {
+		select {
+		case record, ok := <-records:
+			if !ok {
+				h.flushRecords(batch)
+				return
+			}
+			batch = append(batch, record)
+			if len(batch) >= redisRecordBatch {
+				h.flushRecords(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			h.flushRecords(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// flushRecords 把一批命令记录写入攻击事件表和headling_auth_log
+func (h *RedisHoneypot) flushRecords(batch []redisRecord) {
+	if len(batch) == 0 {
+		return
+	}
+
+	events := make([]repositories.AttackEvent, 0, len(batch))
+	var authLogs []repositories.HeadlingAuthLog
+	for _, record := range batch {
+		events = append(events, record.event)
+		if record.authLog != nil {
+			authLogs = append(authLogs, *record.authLog)
+		}
+	}
+
+	if len(authLogs) > 0 {
+		if _, err := repositories.NewHeadlingAuthLogRepo(config.DB).CreateBatch(authLogs); err != nil {
+			h.recordError(fmt.Errorf("保存Redis认证日志失败: %v", err))
+		}
+	}
+
+	service, err := NewAttackCaptureService()
+	if err != nil {
+		h.recordError(err)
+		return
+	}
+	h.recordError(service.RecordEvents(events))
+}
+
+// recordError 记录最近一次错误
+func (h *RedisHoneypot) recordError(err error) {
+	if err == nil {
+		return
+	}
+	fmt.Printf("内置Redis蜜罐出错: %v\n", err)
+
+	h.mu.Lock()
+	now := time.Now()
+	h.status.LastError = err.Error()
+	h.status.LastErrorAt = &now
+	h.mu.Unlock()
+}
+
+// redisPayloadPatterns 写入键值后再保存为cron、authorized_keys或webshell时使用的内容特征
+var redisPayloadPatterns = []string{"* * * *", "ssh-rsa ", "ssh-ed25519 ", "ecdsa-sha2-", "<?php", "/bin/sh", "/bin/bash", "bash -i", "/dev/tcp/"}
+
+// classifyRedisCommand 按命令判断攻击类型，主从复制、加载模块、Lua脚本、修改持久化路径和写入利用载荷都属于RCE利用链
+func classifyRedisCommand(args []string) string {
+	if len(args) == 0 {
+		return RedisAttackCommand
+	}
+	name := strings.ToLower(args[0])
+	switch name {
+	case "auth":
+		return RedisAttackBruteForce
+	case "slaveof", "replicaof":
+		if len(args) == 3 && strings.EqualFold(args[1], "no") && strings.EqualFold(args[2], "one") {
+			return RedisAttackCommand
+		}
+		return RedisAttackRCE
+	case "eval", "evalsha":
+		return RedisAttackRCE
+	case "module", "script":
+		if len(args) > 1 && strings.EqualFold(args[1], "load") {
+			return RedisAttackRCE
+		}
+		return RedisAttackProbe
+	case "config":
+		if len(args) > 2 && strings.EqualFold(args[1], "set") {
+			if param := strings.ToLower(args[2]); param == "dir" || param == "dbfilename" {
+				return RedisAttackRCE
+			}
+			return RedisAttackCommand
+		}
+		return RedisAttackProbe
+	case "set", "setnx":
+		if len(args) > 2 {
+			value := strings.ToLower(args[2])
+			for _, pattern := range redisPayloadPatterns {
+				if strings.Contains(value, pattern) {
+					return RedisAttackRCE
+				}
+			}
+		}
+		return RedisAttackCommand
+	case "ping", "echo", "info", "keys", "scan", "dbsize", "type", "ttl", "randomkey", "client", "command", "time", "select", "post", "host:":
+		return RedisAttackProbe
+	}
+	if strings.Contains(name, ".") {
+		// system.exec等恶意模块注册的命令
+		return RedisAttackRCE
+	}
+	return RedisAttackCommand
+}
+
+// formatRedisCommand 把命令格式化为redis-cli风格的一行，包含空白或不可见字符的参数加引号转义
+func formatRedisCommand(args []string) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\r\n\"'\\") || !strconv.CanBackquote(arg) {
+			parts[i] = strconv.Quote(arg)
+		} else {
+			parts[i] = arg
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// redisUnknownCommand 生成未知命令的错误信息
+func redisUnknownCommand(args []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ERR unknown command `%s`, with args beginning with: ", truncate(args[0], 128))
+	for _, arg := range args[1:] {
+		if b.Len() > 256 {
+			break
+		}
+		fmt.Fprintf(&b, "`%s`, ", truncate(arg, 128))
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(b.String(), "\r", " "), "\n", " ")
+}
+
+// redisScriptSHA 计算Lua脚本的SHA1
+func redisScriptSHA(script string) string {
+	sum := sha1.Sum([]byte(script))
+	return hex.EncodeToString(sum[:])
+}
+
+// writeRedisSimple 写入简单字符串响应
+func writeRedisSimple(w *bufio.Writer, value string) {
+	w.WriteString("+" + value + "\r\n")
+}
+
+// writeRedisError 写入错误响应
+func writeRedisError(w *bufio.Writer, message string) {
+	w.WriteString("-" + message + "\r\n")
+}
+
+// writeRedisInt 写入整数响应
+func writeRedisInt(w *bufio.Writer, value int64) {
+	w.WriteString(":" + strconv.FormatInt(value, 10) + "\r\n")
+}
+
+// writeRedisBulk 写入批量字符串响应
+func writeRedisBulk(w *bufio.Writer, value string) {
+	w.WriteString("$" + strconv.Itoa(len(value)) + "\r\n" + value + "\r\n")
+}
+
+// writeRedisNil 写入空批量字符串
+func writeRedisNil(w *bufio.Writer) {
+	w.WriteString("$-1\r\n")
+}
+
+// writeRedisArray 写入批量字符串数组
+func writeRedisArray(w *bufio.Writer, values []string) {
+	w.WriteString("*" + strconv.Itoa(len(values)) + "\r\n")
+	for _, value := range values {
+		writeRedisBulk(w, value)
+	}
+}
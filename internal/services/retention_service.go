@@ -0,0 +1,480 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/repositories"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultRetentionArchiveDir 默认的归档目录
+	DefaultRetentionArchiveDir = "data/archives"
+	// DefaultRetentionInterval 默认的保留策略执行间隔
+	DefaultRetentionInterval = time.Hour
+
+	// RetentionReasonMaxAge 超过最长保留天数
+	RetentionReasonMaxAge = "max_age"
+	// RetentionReasonMaxRows 超过最多保留行数
+	RetentionReasonMaxRows = "max_rows"
+
+	// retentionArchiveRows 每个归档文件最多包含的行数，超过时分为多个文件
+	retentionArchiveRows = 10000
+	// retentionRestoreBatch 恢复时每批写回的行数
+	retentionRestoreBatch = 500
+)
+
+var (
+	// ErrRetentionTableUnsupported 日志表不支持保留策略
+	ErrRetentionTableUnsupported = errors.New("该日志表不支持保留策略")
+	// ErrRetentionArchiveNotFound 归档记录或归档文件不存在
+	ErrRetentionArchiveNotFound = errors.New("归档不存在")
+)
+
+// retentionMu 同一进程中同时只执行一次保留策略或恢复，避免后台任务和接口调用并发删除或写回同一张表
+var retentionMu sync.Mutex
+
+// RetentionArchiveDir 获取归档目录，可通过RETENTION_ARCHIVE_DIR环境变量覆盖
+func RetentionArchiveDir() string {
+	if dir := os.Getenv("RETENTION_ARCHIVE_DIR"); dir != "" {
+		return dir
+	}
+	return DefaultRetentionArchiveDir
+}
+
+// RetentionInterval 获取保留策略执行间隔，可通过RETENTION_INTERVAL环境变量覆盖(如 30m)
+func RetentionInterval() time.Duration {
+	if v := os.Getenv("RETENTION_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return DefaultRetentionInterval
+}
+
+// retentionTable 可配置保留策略的日志表，按模型类型封装读取、删除和写回
+type retentionTable struct {
+	name string
+	// archive 读取最早的最多limit条记录写入w，返回记录的ID和时间范围
+	archive func(db *gorm.DB, before *time.Time, limit int, w io.Writer) (ids []uint, from, to *time.Time, err error)
+	// delete 按ID删除记录
+	delete func(db *gorm.DB, ids []uint) (int64, error)
+	// restore 从NDJSON中读取记录并写回，返回写回的条数
+	restore func(db *gorm.DB, r io.Reader) (int64, error)
+}
+
+// newRetentionTable 创建模型T对应的日志表，timeColumn为判断记录新旧的时间列
+func newRetentionTable[T any](name, timeColumn string, id func(*T) uint, timeOf func(*T) time.Time, authID func(*T) string) retentionTable {
+	return retentionTable{
+		name: name,
+		archive: func(db *gorm.DB, before *time.Time, limit int, w io.Writer) ([]uint, *time.Time, *time.Time, error) {
+			rows, err := repositories.OldestRows[T](db, timeColumn, before, limit)
+			if err != nil || len(rows) == 0 {
+				return nil, nil, nil, err
+			}
+			encoder := json.NewEncoder(w)
+			ids := make([]uint, len(rows))
+			var from, to time.Time
+			for i := range rows {
+				if err := encoder.Encode(&rows[i]); err != nil {
+					return nil, nil, nil, err
+				}
+				ids[i] = id(&rows[i])
+				t := timeOf(&rows[i])
+				if i == 0 || t.Before(from) {
+					from = t
+				}
+				if i == 0 || t.After(to) {
+					to = t
+				}
+			}
+			return ids, &from, &to, nil
+		},
+		delete: repositories.DeleteRowsByID[T],
+		restore: func(db *gorm.DB, r io.Reader) (int64, error) {
+			decoder := json.NewDecoder(r)
+			var restored int64
+			batch := make([]T, 0, retentionRestoreBatch)
+			flush := func() error {
+				n, err := repositories.RestoreRows(db, batch, id, authID)
+				restored += n
+				batch = batch[:0]
+				return err
+			}
+			for {
+				var row T
+				if err := decoder.Decode(&row); err == io.EOF {
+					break
+				} else if err != nil {
+					return restored, fmt.Errorf("解析归档记录失败: %v", err)
+				}
+				batch = append(batch, row)
+				if len(batch) == retentionRestoreBatch {
+					if err := flush(); err != nil {
+						return restored, err
+					}
+				}
+			}
+			return restored, flush()
+		},
+	}
+}
+
+// retentionTables 支持保留策略的日志表
+var retentionTables = map[string]retentionTable{
+	"cowrie_log": newRetentionTable("cowrie_log", "event_time",
+		func(l *repositories.CowrieLog) uint { return l.ID },
+		func(l *repositories.CowrieLog) time.Time { return l.EventTime },
+		func(l *repositories.CowrieLog) string { return l.AuthID }),
+	"headling_auth_log": newRetentionTable("headling_auth_log", "timestamp",
+		func(l *repositories.HeadlingAuthLog) uint { return l.ID },
+		func(l *repositories.HeadlingAuthLog) time.Time { return l.Timestamp },
+		func(l *repositories.HeadlingAuthLog) string { return l.AuthID }),
+	"container_log_segment": newRetentionTable("container_log_segment", "created_at",
+		func(s *repositories.ContainerLogSegment) uint { return s.ID },
+		func(s *repositories.ContainerLogSegment) time.Time { return s.CreatedAt },
+		nil),
+	"docker_image_log": newRetentionTable("docker_image_log", "created_at",
+		func(l *repositories.DockerImageLog) uint { return l.ID },
+		func(l *repositories.DockerImageLog) time.Time { return l.CreatedAt },
+		nil),
+}
+
+// RetentionTables 返回支持保留策略的日志表名
+func RetentionTables() []string {
+	names := make([]string, 0, len(retentionTables))
+	for name := range retentionTables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RetentionRunResult 一张日志表执行保留策略的结果
+type RetentionRunResult struct {
+	Table    string                          `json:"table"`
+	Archived int64                           `json:"archived"` // 归档的行数
+	Deleted  int64                           `json:"deleted"`  // 删除的行数
+	Archives []repositories.RetentionArchive `json:"archives,omitempty"`
+	Error    string                          `json:"error,omitempty"`
+}
+
+// RetentionService 日志保留策略服务，把超过保留期限的日志归档为压缩NDJSON文件后删除
+type RetentionService struct {
+	DB         *gorm.DB
+	Repo       repositories.RetentionRepository
+	ArchiveDir string
+	Now        func() time.Time
+}
+
+// NewRetentionService 创建日志保留策略服务
+func NewRetentionService() (*RetentionService, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+	return &RetentionService{
+		DB:         config.DB,
+		Repo:       repositories.NewRetentionRepo(config.DB),
+		ArchiveDir: RetentionArchiveDir(),
+		Now:        time.Now,
+	}, nil
+}
+
+// ListPolicies 获取每张支持的日志表的保留策略，没有配置的表返回未启用的空策略
+func (s *RetentionService) ListPolicies() ([]repositories.RetentionPolicy, error) {
+	stored, err := s.Repo.ListPolicies()
+	if err != nil {
+		return nil, err
+	}
+	byTable := make(map[string]repositories.RetentionPolicy, len(stored))
+	for _, policy := range stored {
+		byTable[policy.LogTable] = policy
+	}
+
+	policies := make([]repositories.RetentionPolicy, 0, len(retentionTables))
+	for _, table := range RetentionTables() {
+		policy, ok := byTable[table]
+		if !ok {
+			policy = repositories.RetentionPolicy{LogTable: table}
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// SetPolicy 设置日志表的保留策略，maxAgeDays和maxRows为0表示不按该条件清理
+func (s *RetentionService) SetPolicy(table string, maxAgeDays int, maxRows int64, enabled bool) (*repositories.RetentionPolicy, error) {
+	if _, ok := retentionTables[table]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRetentionTableUnsupported, table)
+	}
+	if maxAgeDays < 0 || maxRows < 0 {
+		return nil, fmt.Errorf("保留天数和保留行数不能为负数")
+	}
+
+	policy := &repositories.RetentionPolicy{
+		LogTable:   table,
+		MaxAgeDays: maxAgeDays,
+		MaxRows:    maxRows,
+		Enabled:    enabled,
+		UpdateTime: s.Now(),
+	}
+	if err := s.Repo.SavePolicy(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Enforce 对所有启用的保留策略执行归档和删除，table不为空时只处理该表
+// 单张表失败时记录在结果中并继续处理其他表
+func (s *RetentionService) Enforce(table string) ([]RetentionRunResult, error) {
+	if table != "" {
+		if _, ok := retentionTables[table]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrRetentionTableUnsupported, table)
+		}
+	}
+	policies, err := s.ListPolicies()
+	if err != nil {
+		return nil, err
+	}
+
+	retentionMu.Lock()
+	defer retentionMu.Unlock()
+
+	var results []RetentionRunResult
+	for _, policy := range policies {
+		if !policy.Enabled || (table != "" && policy.LogTable != table) {
+			continue
+		}
+		result := s.enforcePolicy(policy)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// enforcePolicy 先归档删除超过保留天数的记录，再归档删除超出保留行数的最早记录
+func (s *RetentionService) enforcePolicy(policy repositories.RetentionPolicy) RetentionRunResult {
+	target := retentionTables[policy.LogTable]
+	result := RetentionRunResult{Table: policy.LogTable}
+
+	if policy.MaxAgeDays > 0 {
+		cutoff := s.Now().AddDate(0, 0, -policy.MaxAgeDays)
+		for {
+			archive, deleted, err := s.archiveChunk(target, RetentionReasonMaxAge, &cutoff, retentionArchiveRows)
+			if err != nil {
+				result.Error = err.Error()
+				return result
+			}
+			if archive == nil {
+				break
+			}
+			result.Archived += archive.RowCount
+			result.Deleted += deleted
+			result.Archives = append(result.Archives, *archive)
+			if archive.RowCount < retentionArchiveRows {
+				break
+			}
+		}
+	}
+
+	if policy.MaxRows > 0 {
+		var count int64
+		if err := s.DB.Table(policy.LogTable).Count(&count).Error; err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		for excess := count - policy.MaxRows; excess > 0; {
+			limit := retentionArchiveRows
+			if excess < int64(limit) {
+				limit = int(excess)
+			}
+			archive, deleted, err := s.archiveChunk(target, RetentionReasonMaxRows, nil, limit)
+			if err != nil {
+				result.Error = err.Error()
+				return result
+			}
+			if archive == nil {
+				break
+			}
+			result.Archived += archive.RowCount
+			result.Deleted += deleted
+			result.Archives = append(result.Archives, *archive)
+			excess -= archive.RowCount
+		}
+	}
+	return result
+}
+
+// archiveChunk 把最早的最多limit条记录写入一个归档文件，文件落盘并登记后再删除这些记录
+// 没有需要归档的记录时返回nil
+func (s *RetentionService) archiveChunk(target retentionTable, reason string, before *time.Time, limit int) (*repositories.RetentionArchive, int64, error) {
+	dir := filepath.Join(s.ArchiveDir, target.name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, 0, fmt.Errorf("创建归档目录失败: %v", err)
+	}
+
+	now := s.Now()
+	fileName := fmt.Sprintf("%s/%s-%s-%s.ndjson.gz", target.name, target.name, now.UTC().Format("20060102T150405.000000000Z"), reason)
+	path := filepath.Join(s.ArchiveDir, filepath.FromSlash(fileName))
+	tmpPath := path + ".tmp"
+
+	ids, from, to, size, err := writeArchiveFile(tmpPath, func(w io.Writer) ([]uint, *time.Time, *time.Time, error) {
+		return target.archive(s.DB, before, limit, w)
+	})
+	if err != nil || len(ids) == 0 {
+		os.Remove(tmpPath)
+		return nil, 0, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return nil, 0, fmt.Errorf("保存归档文件失败: %v", err)
+	}
+
+	archive := &repositories.RetentionArchive{
+		LogTable:  target.name,
+		Reason:    reason,
+		FileName:  fileName,
+		RowCount:  int64(len(ids)),
+		SizeBytes: size,
+		FromTime:  from,
+		ToTime:    to,
+		CreatedAt: now,
+	}
+	if err := s.Repo.CreateArchive(archive); err != nil {
+		return nil, 0, fmt.Errorf("登记归档文件失败: %v", err)
+	}
+
+	deleted, err := target.delete(s.DB, ids)
+	if err != nil {
+		return archive, deleted, fmt.Errorf("删除已归档的记录失败: %v", err)
+	}
+	return archive, deleted, nil
+}
+
+// writeArchiveFile 创建gzip压缩的归档文件，由write写入NDJSON内容，返回写入的记录和压缩后的文件大小
+func writeArchiveFile(path string, write func(w io.Writer) ([]uint, *time.Time, *time.Time, error)) ([]uint, *time.Time, *time.Time, int64, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, nil, nil, 0, fmt.Errorf("创建归档文件失败: %v", err)
+	}
+	defer file.Close()
+
+	buffered := bufio.NewWriter(file)
+	gz := gzip.NewWriter(buffered)
+	ids, from, to, err := write(gz)
+	if err != nil {
+		return nil, nil, nil, 0, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, nil, nil, 0, err
+	}
+	if err := buffered.Flush(); err != nil {
+		return nil, nil, nil, 0, err
+	}
+	// 删除数据库记录之前确保归档已经写入磁盘
+	if err := file.Sync(); err != nil {
+		return nil, nil, nil, 0, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return nil, nil, nil, 0, err
+	}
+	return ids, from, to, info.Size(), nil
+}
+
+// ListArchives 获取归档记录，table为空时返回所有日志表的归档
+func (s *RetentionService) ListArchives(table string) ([]repositories.RetentionArchive, error) {
+	if table != "" {
+		if _, ok := retentionTables[table]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrRetentionTableUnsupported, table)
+		}
+	}
+	return s.Repo.ListArchives(table)
+}
+
+// RestoreArchive 把归档文件中的记录写回原表，ID或auth_id已存在的记录被跳过，可以重复恢复
+func (s *RetentionService) RestoreArchive(id uint) (*repositories.RetentionArchive, error) {
+	archive, err := s.Repo.GetArchive(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRetentionArchiveNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	target, ok := retentionTables[archive.LogTable]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRetentionTableUnsupported, archive.LogTable)
+	}
+	if strings.Contains(archive.FileName, "..") {
+		return nil, fmt.Errorf("归档文件名不合法: %s", archive.FileName)
+	}
+
+	file, err := os.Open(filepath.Join(s.ArchiveDir, filepath.FromSlash(archive.FileName)))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: 归档文件%s已不存在", ErrRetentionArchiveNotFound, archive.FileName)
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(bufio.NewReader(file))
+	if err != nil {
+		return nil, fmt.Errorf("读取归档文件失败: %v", err)
+	}
+	defer gz.Close()
+
+	retentionMu.Lock()
+	defer retentionMu.Unlock()
+
+	restored, err := target.restore(s.DB, gz)
+	if err != nil {
+		return nil, fmt.Errorf("恢复归档失败(已写回%d条): %v", restored, err)
+	}
+	now := s.Now()
+	if err := s.Repo.MarkArchiveRestored(archive.ID, restored, now); err != nil {
+		return nil, err
+	}
+	archive.RestoredAt = &now
+	archive.RestoredRows = restored
+	return archive, nil
+}
+
+var retentionJobOnce sync.Once
+
+// StartRetentionJob 启动后台任务，按RetentionInterval定期执行所有启用的保留策略，重复调用只启动一次
+func StartRetentionJob() {
+	retentionJobOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(RetentionInterval())
+			defer ticker.Stop()
+
+			for range ticker.C {
+				service, err := NewRetentionService()
+				if err != nil {
+					continue
+				}
+				results, err := service.Enforce("")
+				if err != nil {
+					fmt.Printf("执行日志保留策略失败: %v\n", err)
+					continue
+				}
+				for _, result := range results {
+					if result.Error != "" {
+						fmt.Printf("日志表%s执行保留策略失败: %s\n", result.Table, result.Error)
+					} else if result.Deleted > 0 {
+						fmt.Printf("日志表%s已归档 %d 条、删除 %d 条过期记录\n", result.Table, result.Archived, result.Deleted)
+					}
+				}
+			}
+		}()
+	})
+}
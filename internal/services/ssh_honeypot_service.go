@@ -0,0 +1,764 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/repositories"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/ssh"
+	"gorm.io/gorm"
+)
+
+const (
+	// SSHHoneypotContainerID 内置SSH蜜罐写入日志表container_id字段的标识
+	SSHHoneypotContainerID = "native:ssh"
+
+	// SSH认证策略
+	SSHAuthPolicyReject      = "reject"
+	SSHAuthPolicyAccept      = "accept"
+	SSHAuthPolicyCredentials = "credentials"
+
+	// sshHandshakeTimeout 完成密钥交换和认证的最长时间
+	sshHandshakeTimeout = 30 * time.Second
+	// sshSessionTimeout 认证成功后连接的最长保持时间
+	sshSessionTimeout = 10 * time.Minute
+	// sshSniffLimit 解析客户端版本和KEXINIT时最多缓存的字节数
+	sshSniffLimit = 64 * 1024
+	// sshMaxLineLength 模拟shell中一行命令的最大长度
+	sshMaxLineLength = 4096
+)
+
+// SSHHoneypotStatus 内置SSH蜜罐状态
+type SSHHoneypotStatus struct {
+	Running          bool       `json:"running"`
+	StartedAt        *time.Time `json:"started_at"`
+	Listener         string     `json:"listener"`
+	Banner           string     `json:"banner"`
+	AuthPolicy       string     `json:"auth_policy"`
+	HostKeys         []string   `json:"host_keys"` // 主机公钥类型和SHA256指纹
+	Connections      int        `json:"connections"`
+	TotalConnections int64      `json:"total_connections"`
+	AuthAttempts     int64      `json:"auth_attempts"`
+	Accepted         int64      `json:"accepted"`
+	LastError        string     `json:"last_error"`
+	LastErrorAt      *time.Time `json:"last_error_at"`
+}
+
+// SSHHoneypot 内置SSH蜜罐，不依赖Docker镜像，记录每次口令和公钥认证尝试、客户端版本和KEX算法
+type SSHHoneypot struct {
+	mu          sync.Mutex
+	running     bool
+	cancel      context.CancelFunc
+	listener    net.Listener
+	conns       map[net.Conn]struct{}
+	serving     sync.WaitGroup
+	cfg         config.SSHHoneypotConfig
+	signers     []ssh.Signer
+	credentials map[string]string
+	status      SSHHoneypotStatus
+}
+
+var (
+	sshHoneypot     *SSHHoneypot
+	sshHoneypotOnce sync.Once
+)
+
+// GetSSHHoneypot 获取全局内置SSH蜜罐
+func GetSSHHoneypot() *SSHHoneypot {
+	sshHoneypotOnce.Do(func() {
+		sshHoneypot = &SSHHoneypot{}
+	})
+	return sshHoneypot
+}
+
+// Start 按配置加载主机密钥并打开监听
+func (h *SSHHoneypot) Start() error {
+	if config.DB == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.running {
+		return nil
+	}
+
+	cfg := config.LoadConfig().SSH
+	if !strings.HasPrefix(cfg.Banner, "SSH-2.0-") {
+		return fmt.Errorf("SSH_HONEYPOT_BANNER必须以SSH-2.0-开头: %s", cfg.Banner)
+	}
+	credentials, err := parseSSHCredentials(cfg.AuthPolicy, cfg.Credentials)
+	if err != nil {
+		return err
+	}
+	signers, err := loadSSHHostKeys(cfg)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("监听SSH %s 失败: %v", cfg.Addr, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	now := time.Now()
+	h.cfg = cfg
+	h.signers = signers
+	h.credentials = credentials
+	h.listener = listener
+	h.conns = make(map[net.Conn]struct{})
+	h.cancel = cancel
+	h.running = true
+	h.status = SSHHoneypotStatus{
+		Running:    true,
+		StartedAt:  &now,
+		Listener:   listener.Addr().String(),
+		Banner:     cfg.Banner,
+		AuthPolicy: cfg.AuthPolicy,
+	}
+	for _, signer := range signers {
+		key := signer.PublicKey()
+		h.status.HostKeys = append(h.status.HostKeys, key.Type()+" "+ssh.FingerprintSHA256(key))
+	}
+
+	h.serving.Add(1)
+	go h.serve(ctx, listener)
+
+	fmt.Printf("内置SSH蜜罐已启动，监听: %s\n", h.status.Listener)
+	return nil
+}
+
+// Stop 关闭监听和所有连接
+func (h *SSHHoneypot) Stop() {
+	h.mu.Lock()
+	if !h.running {
+		h.mu.Unlock()
+		return
+	}
+	h.running = false
+	h.cancel()
+	h.listener.Close()
+	for conn := range h.conns {
+		conn.Close()
+	}
+	h.mu.Unlock()
+
+	h.serving.Wait()
+
+	h.mu.Lock()
+	h.status.Running = false
+	h.status.StartedAt = nil
+	h.status.Listener = ""
+	h.mu.Unlock()
+
+	fmt.Println("内置SSH蜜罐已停止")
+}
+
+// Status 获取蜜罐状态
+func (h *SSHHoneypot) Status() SSHHoneypotStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	status := h.status
+	status.HostKeys = append([]string(nil), h.status.HostKeys...)
+	status.Connections = len(h.conns)
+	return status
+}
+
+// serve 接受连接，超过最大连接数时直接关闭新连接
+func (h *SSHHoneypot) serve(ctx context.Context, listener net.Listener) {
+	defer h.serving.Done()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				h.recordError(fmt.Errorf("接受SSH连接失败: %v", err))
+			}
+			return
+		}
+
+		h.mu.Lock()
+		if ctx.Err() != nil || (h.cfg.MaxSessions > 0 && len(h.conns) >= h.cfg.MaxSessions) {
+			h.mu.Unlock()
+			conn.Close()
+			continue
+		}
+		h.conns[conn] = struct{}{}
+		h.status.TotalConnections++
+		h.serving.Add(1)
+		h.mu.Unlock()
+
+		go h.serveConn(conn)
+	}
+}
+
+// sshConnState 一个连接的会话信息
+type sshConnState struct {
+	sessionID string
+	sniffer   *sshClientSniffer
+	attempts  int
+}
+
+// serveConn 完成握手和认证，认证成功时提供模拟shell
+func (h *SSHHoneypot) serveConn(conn net.Conn) {
+	defer func() {
+		conn.Close()
+		h.mu.Lock()
+		delete(h.conns, conn)
+		h.mu.Unlock()
+		h.serving.Done()
+	}()
+
+	state := &sshConnState{sessionID: uuid.New().String(), sniffer: &sshClientSniffer{Conn: conn}}
+	conn.SetDeadline(time.Now().Add(sshHandshakeTimeout))
+	serverConn, channels, requests, err := ssh.NewServerConn(state.sniffer, h.serverConfig(conn, state))
+	if state.attempts == 0 && state.sniffer.clientVersion() != "" {
+		// 只交换了版本或密钥就断开的扫描器也记录客户端指纹
+		h.recordAttempt(conn, state, "none", "", "", nil, false)
+	}
+	if err != nil {
+		return
+	}
+	defer serverConn.Close()
+
+	conn.SetDeadline(time.Now().Add(sshSessionTimeout))
+	go ssh.DiscardRequests(requests)
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go h.serveSession(channel, channelRequests, serverConn.User())
+	}
+}
+
+// serverConfig 创建连接使用的SSH服务端配置，认证回调按策略决定是否接受并记录每次尝试
+func (h *SSHHoneypot) serverConfig(conn net.Conn, state *sshConnState) *ssh.ServerConfig {
+	h.mu.Lock()
+	cfg := h.cfg
+	signers := h.signers
+	h.mu.Unlock()
+
+	reject := errors.New("permission denied")
+	serverConfig := &ssh.ServerConfig{
+		ServerVersion: cfg.Banner,
+		MaxAuthTries:  cfg.MaxAuthTries,
+		PasswordCallback: func(meta ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			accepted := h.acceptPassword(meta.User(), string(password))
+			h.recordAttempt(conn, state, "password", meta.User(), string(password), nil, accepted)
+			if !accepted {
+				return nil, reject
+			}
+			return &ssh.Permissions{}, nil
+		},
+		PublicKeyCallback: func(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			accepted := cfg.AuthPolicy == SSHAuthPolicyAccept
+			h.recordAttempt(conn, state, "publickey", meta.User(), "", key, accepted)
+			if !accepted {
+				return nil, reject
+			}
+			return &ssh.Permissions{}, nil
+		},
+		KeyboardInteractiveCallback: func(meta ssh.ConnMetadata, challenge ssh.KeyboardInteractiveChallenge) (*ssh.Permissions, error) {
+			answers, err := challenge(meta.User(), "", []string{"Password: "}, []bool{false})
+			if err != nil || len(answers) != 1 {
+				return nil, reject
+			}
+			accepted := h.acceptPassword(meta.User(), answers[0])
+			h.recordAttempt(conn, state, "keyboard-interactive", meta.User(), answers[0], nil, accepted)
+			if !accepted {
+				return nil, reject
+			}
+			return &ssh.Permissions{}, nil
+		},
+	}
+	if cfg.LoginBanner != "" {
+		serverConfig.BannerCallback = func(ssh.ConnMetadata) string { return cfg.LoginBanner }
+	}
+	for _, signer := range signers {
+		serverConfig.AddHostKey(signer)
+	}
+	return serverConfig
+}
+
+// acceptPassword 按认证策略判断是否接受口令
+func (h *SSHHoneypot) acceptPassword(username, password string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch h.cfg.AuthPolicy {
+	case SSHAuthPolicyAccept:
+		return true
+	case SSHAuthPolicyCredentials:
+		for _, key := range []string{username, "*"} {
+			if expected, ok := h.credentials[key]; ok && (expected == "*" || expected == password) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// recordAttempt 把认证尝试写入headling_auth_log和ssh_auth_attempt，method为none时只写入ssh_auth_attempt
+func (h *SSHHoneypot) recordAttempt(conn net.Conn, state *sshConnState, method, username, password string, key ssh.PublicKey, accepted bool) {
+	state.attempts++
+	now := time.Now()
+	sourceIP, sourcePort := splitHostPort(conn.RemoteAddr())
+	destIP, destPort := splitHostPort(conn.LocalAddr())
+	clientVersion, kex, hassh := state.sniffer.fingerprint()
+
+	h.mu.Lock()
+	containerName := h.cfg.ContainerName
+	if method != "none" {
+		h.status.AuthAttempts++
+		if accepted {
+			h.status.Accepted++
+		}
+	}
+	h.mu.Unlock()
+
+	attempt := repositories.SSHAuthAttempt{
+		AuthID:        uuid.New().String(),
+		SessionID:     state.sessionID,
+		Timestamp:     now,
+		SourceIP:      sourceIP,
+		SourcePort:    sourcePort,
+		Method:        method,
+		Username:      truncate(username, 255),
+		Accepted:      accepted,
+		ClientVersion: truncate(clientVersion, 255),
+		KexAlgorithms: kex,
+		Hassh:         hassh,
+		ContainerID:   SSHHoneypotContainerID,
+	}
+	if key != nil {
+		attempt.KeyType = truncate(key.Type(), 64)
+		attempt.KeyFingerprint = ssh.FingerprintSHA256(key)
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if method != "none" {
+			authLog := repositories.HeadlingAuthLog{
+				Timestamp:       now,
+				AuthID:          attempt.AuthID,
+				SessionID:       state.sessionID,
+				SourceIP:        sourceIP,
+				SourcePort:      sourcePort,
+				DestinationIP:   destIP,
+				DestinationPort: destPort,
+				Protocol:        "ssh",
+				Username:        attempt.Username,
+				Password:        truncate(password, 255),
+				PasswordHash:    attempt.KeyFingerprint,
+				ContainerID:     SSHHoneypotContainerID,
+				ContainerName:   containerName,
+				CreatedAt:       now,
+			}
+			if err := repositories.NewHeadlingAuthLogRepo(tx).Create(&authLog); err != nil {
+				return err
+			}
+		}
+		return repositories.NewSSHAuthAttemptRepo(tx).Create(&attempt)
+	})
+	h.recordError(err)
+}
+
+// serveSession 处理会话通道的请求，shell请求提供模拟的交互式shell，exec请求返回命令不存在
+func (h *SSHHoneypot) serveSession(channel ssh.Channel, requests <-chan *ssh.Request, user string) {
+	defer channel.Close()
+
+	h.mu.Lock()
+	hostname := h.cfg.Hostname
+	h.mu.Unlock()
+
+	for req := range requests {
+		switch req.Type {
+		case "pty-req", "env", "window-change":
+			req.Reply(true, nil)
+		case "exec":
+			var payload struct{ Command string }
+			if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			req.Reply(true, nil)
+			fmt.Fprintf(channel.Stderr(), "bash: %s: command not found\n", firstField(payload.Command))
+			sendExitStatus(channel, 127)
+			return
+		case "shell":
+			req.Reply(true, nil)
+			go ssh.DiscardRequests(requests)
+			runFakeShell(channel, user, hostname)
+			return
+		default:
+			req.Reply(false, nil)
+		}
+	}
+}
+
+// runFakeShell 回显输入并对每条命令返回命令不存在，exit或logout结束会话
+func runFakeShell(channel ssh.Channel, user, hostname string) {
+	prompt := fmt.Sprintf("%s@%s:~$ ", user, hostname)
+	if user == "root" {
+		prompt = fmt.Sprintf("root@%s:~# ", hostname)
+	}
+	channel.Write([]byte(prompt))
+
+	var line []byte
+	buf := make([]byte, 256)
+	for {
+		n, err := channel.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, b := range buf[:n] {
+			switch b {
+			case '\r', '\n':
+				channel.Write([]byte("\r\n"))
+				command := strings.TrimSpace(string(line))
+				line = line[:0]
+				switch command {
+				case "":
+				case "exit", "logout":
+					sendExitStatus(channel, 0)
+					return
+				default:
+					fmt.Fprintf(channel, "-bash: %s: command not found\r\n", firstField(command))
+				}
+				channel.Write([]byte(prompt))
+			case 0x7f, 0x08:
+				if len(line) > 0 {
+					line = line[:len(line)-1]
+					channel.Write([]byte("\b \b"))
+				}
+			case 0x03:
+				line = line[:0]
+				channel.Write([]byte("^C\r\n" + prompt))
+			case 0x04:
+				if len(line) == 0 {
+					sendExitStatus(channel, 0)
+					return
+				}
+			default:
+				if b >= 0x20 && len(line) < sshMaxLineLength {
+					line = append(line, b)
+					channel.Write([]byte{b})
+				}
+			}
+		}
+	}
+}
+
+// sendExitStatus 发送命令退出码
+func sendExitStatus(channel ssh.Channel, status uint32) {
+	channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+}
+
+// firstField 获取命令的第一个词
+func firstField(command string) string {
+	if fields := strings.Fields(command); len(fields) > 0 {
+		return fields[0]
+	}
+	return command
+}
+
+// recordError 记录最近一次错误
+func (h *SSHHoneypot) recordError(err error) {
+	if err == nil {
+		return
+	}
+	fmt.Printf("内置SSH蜜罐出错: %v\n", err)
+
+	h.mu.Lock()
+	now := time.Now()
+	h.status.LastError = err.Error()
+	h.status.LastErrorAt = &now
+	h.mu.Unlock()
+}
+
+// parseSSHCredentials 校验认证策略并解析credentials策略接受的口令，用户名或密码为*时匹配任意值
+func parseSSHCredentials(policy, value string) (map[string]string, error) {
+	switch policy {
+	case SSHAuthPolicyReject, SSHAuthPolicyAccept:
+		return nil, nil
+	case SSHAuthPolicyCredentials:
+	default:
+		return nil, fmt.Errorf("SSH_HONEYPOT_AUTH_POLICY配置错误: 不支持的认证策略 %s", policy)
+	}
+
+	credentials := make(map[string]string)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		username, password, ok := strings.Cut(item, ":")
+		if !ok || username == "" {
+			return nil, fmt.Errorf("SSH_HONEYPOT_CREDENTIALS配置错误: 无效的配置项 %s", item)
+		}
+		credentials[username] = password
+	}
+	if len(credentials) == 0 {
+		return nil, fmt.Errorf("credentials认证策略需要配置SSH_HONEYPOT_CREDENTIALS")
+	}
+	return credentials, nil
+}
+
+// loadSSHHostKeys 加载配置的主机私钥，未配置时在主机密钥目录中生成ed25519、ECDSA和RSA密钥并在重启后复用
+func loadSSHHostKeys(cfg config.SSHHoneypotConfig) ([]ssh.Signer, error) {
+	var signers []ssh.Signer
+	if cfg.HostKeys != "" {
+		for _, path := range strings.Split(cfg.HostKeys, ",") {
+			path = strings.TrimSpace(path)
+			if path == "" {
+				continue
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("读取主机私钥失败: %v", err)
+			}
+			signer, err := ssh.ParsePrivateKey(data)
+			if err != nil {
+				return nil, fmt.Errorf("解析主机私钥%s失败: %v", path, err)
+			}
+			signers = append(signers, signer)
+		}
+		if len(signers) == 0 {
+			return nil, fmt.Errorf("SSH_HONEYPOT_HOST_KEYS中没有可用的主机私钥")
+		}
+		return signers, nil
+	}
+
+	if err := os.MkdirAll(cfg.HostKeyDir, 0700); err != nil {
+		return nil, fmt.Errorf("创建主机密钥目录失败: %v", err)
+	}
+	generators := []struct {
+		name     string
+		generate func() (crypto.PrivateKey, error)
+	}{
+		{"ed25519", func() (crypto.PrivateKey, error) {
+			_, key, err := ed25519.GenerateKey(rand.Reader)
+			return key, err
+		}},
+		{"ecdsa", func() (crypto.PrivateKey, error) { return ecdsa.GenerateKey(elliptic.P256(), rand.Reader) }},
+		{"rsa", func() (crypto.PrivateKey, error) { return rsa.GenerateKey(rand.Reader, 3072) }},
+	}
+	for _, g := range generators {
+		path := filepath.Join(cfg.HostKeyDir, "ssh_host_"+g.name+"_key")
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			key, err := g.generate()
+			if err != nil {
+				return nil, fmt.Errorf("生成%s主机密钥失败: %v", g.name, err)
+			}
+			block, err := ssh.MarshalPrivateKey(key, "")
+			if err != nil {
+				return nil, fmt.Errorf("编码%s主机密钥失败: %v", g.name, err)
+			}
+			data = pem.EncodeToMemory(block)
+			if err := os.WriteFile(path, data, 0600); err != nil {
+				return nil, fmt.Errorf("保存主机密钥失败: %v", err)
+			}
+		} else if err != nil {
+			return nil, fmt.Errorf("读取主机私钥失败: %v", err)
+		}
+		signer, err := ssh.ParsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("解析主机私钥%s失败: %v", path, err)
+		}
+		signers = append(signers, signer)
+	}
+	return signers, nil
+}
+
+// splitHostPort 拆分网络地址中的IP和端口
+func splitHostPort(addr net.Addr) (string, uint) {
+	if addr == nil {
+		return "", 0
+	}
+	host, portStr, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String(), 0
+	}
+	port, _ := strconv.ParseUint(portStr, 10, 16)
+	return host, uint(port)
+}
+
+// SSHClientKex 客户端KEXINIT报文中按优先级排列的算法列表
+type SSHClientKex struct {
+	Kex           []string `json:"kex"`
+	HostKey       []string `json:"host_key"`
+	EncryptionCS  []string `json:"encryption_cs"`
+	EncryptionSC  []string `json:"encryption_sc"`
+	MACCS         []string `json:"mac_cs"`
+	MACSC         []string `json:"mac_sc"`
+	CompressionCS []string `json:"compression_cs"`
+	CompressionSC []string `json:"compression_sc"`
+}
+
+// Hassh 按HASSH规则计算客户端指纹: md5(kex;encryption;mac;compression)，各列表取客户端到服务端方向
+func (k *SSHClientKex) Hassh() string {
+	sum := md5.Sum([]byte(strings.Join([]string{
+		strings.Join(k.Kex, ","),
+		strings.Join(k.EncryptionCS, ","),
+		strings.Join(k.MACCS, ","),
+		strings.Join(k.CompressionCS, ","),
+	}, ";")))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseSSHKexInit 解析SSH_MSG_KEXINIT报文的载荷
+func ParseSSHKexInit(payload []byte) (*SSHClientKex, error) {
+	const msgKexInit = 20
+	if len(payload) < 17 || payload[0] != msgKexInit {
+		return nil, fmt.Errorf("不是KEXINIT报文")
+	}
+	rest := payload[17:]
+	lists := make([][]string, 8)
+	for i := range lists {
+		if len(rest) < 4 {
+			return nil, fmt.Errorf("KEXINIT报文不完整")
+		}
+		length := binary.BigEndian.Uint32(rest)
+		if uint32(len(rest)-4) < length {
+			return nil, fmt.Errorf("KEXINIT报文不完整")
+		}
+		if length > 0 {
+			lists[i] = strings.Split(string(rest[4:4+length]), ",")
+		}
+		rest = rest[4+length:]
+	}
+	return &SSHClientKex{
+		Kex: lists[0], HostKey: lists[1],
+		EncryptionCS: lists[2], EncryptionSC: lists[3],
+		MACCS: lists[4], MACSC: lists[5],
+		CompressionCS: lists[6], CompressionSC: lists[7],
+	}, nil
+}
+
+// sshClientSniffer 包装连接，从客户端发来的明文数据中解析版本行和第一个KEXINIT报文
+type sshClientSniffer struct {
+	net.Conn
+	mu      sync.Mutex
+	buf     []byte
+	done    bool
+	version string
+	kex     *SSHClientKex
+}
+
+func (s *sshClientSniffer) Read(p []byte) (int, error) {
+	n, err := s.Conn.Read(p)
+	if n > 0 {
+		s.mu.Lock()
+		if !s.done {
+			s.buf = append(s.buf, p[:n]...)
+			s.parseLocked()
+		}
+		s.mu.Unlock()
+	}
+	return n, err
+}
+
+// parseLocked 依次解析版本行和KEXINIT，解析完成或数据超过上限后不再缓存
+func (s *sshClientSniffer) parseLocked() {
+	for s.version == "" {
+		i := strings.IndexByte(string(s.buf), '\n')
+		if i < 0 {
+			if len(s.buf) > sshSniffLimit {
+				s.done, s.buf = true, nil
+			}
+			return
+		}
+		line := strings.TrimRight(string(s.buf[:i]), "\r")
+		s.buf = s.buf[i+1:]
+		if strings.HasPrefix(line, "SSH-") {
+			s.version = line
+		}
+	}
+
+	if len(s.buf) < 5 {
+		return
+	}
+	length := binary.BigEndian.Uint32(s.buf)
+	if length > sshSniffLimit || length < 1 {
+		s.done, s.buf = true, nil
+		return
+	}
+	if uint32(len(s.buf)-4) < length {
+		return
+	}
+	padding := uint32(s.buf[4])
+	if padding+1 <= length {
+		s.kex, _ = ParseSSHKexInit(s.buf[5 : 4+length-padding])
+	}
+	s.done, s.buf = true, nil
+}
+
+// clientVersion 获取客户端版本字符串
+func (s *sshClientSniffer) clientVersion() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.version
+}
+
+// fingerprint 获取客户端版本、JSON格式的KEX算法列表和HASSH指纹
+func (s *sshClientSniffer) fingerprint() (version, kex, hassh string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.kex != nil {
+		data, _ := json.Marshal(s.kex)
+		kex, hassh = string(data), s.kex.Hassh()
+	}
+	return s.version, kex, hassh
+}
+
+// SSHHoneypotService 内置SSH蜜罐记录查询服务
+type SSHHoneypotService struct {
+	Repo repositories.SSHAuthAttemptRepository
+}
+
+// NewSSHHoneypotService 创建内置SSH蜜罐记录查询服务
+func NewSSHHoneypotService() (*SSHHoneypotService, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+	return &SSHHoneypotService{Repo: repositories.NewSSHAuthAttemptRepo(config.DB)}, nil
+}
+
+// GetAttemptsPage 按查询条件分页获取认证尝试
+func (s *SSHHoneypotService) GetAttemptsPage(spec repositories.QuerySpec) (*repositories.Page[repositories.SSHAuthAttempt], error) {
+	return s.Repo.Query(spec)
+}
+
+// GetSessionAttempts 获取一个连接的所有认证尝试
+func (s *SSHHoneypotService) GetSessionAttempts(sessionID string) ([]repositories.SSHAuthAttempt, error) {
+	return s.Repo.GetBySessionID(sessionID)
+}
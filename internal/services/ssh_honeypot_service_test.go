@@ -0,0 +1,195 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/repositories"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// startTestSSHHoneypot 在随机端口启动使用临时主机密钥目录的SSH蜜罐
+func startTestSSHHoneypot(t *testing.T, keyDir string) *SSHHoneypot {
+	t.Helper()
+	t.Setenv("SSH_HONEYPOT_ADDR", "127.0.0.1:0")
+	t.Setenv("SSH_HONEYPOT_HOST_KEY_DIR", keyDir)
+	t.Setenv("SSH_HONEYPOT_AUTH_POLICY", SSHAuthPolicyCredentials)
+	t.Setenv("SSH_HONEYPOT_CREDENTIALS", "root:toor")
+
+	honeypot := &SSHHoneypot{}
+	if err := honeypot.Start(); err != nil {
+		t.Fatalf("启动SSH蜜罐失败: %v", err)
+	}
+	t.Cleanup(honeypot.Stop)
+	return honeypot
+}
+
+// waitSSHAttempts 等待连接关闭后异步写入的认证尝试
+func waitSSHAttempts(t *testing.T, count int) []repositories.SSHAuthAttempt {
+	t.Helper()
+	var attempts []repositories.SSHAuthAttempt
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		attempts = nil
+		config.DB.Order("id").Find(&attempts)
+		if len(attempts) >= count {
+			return attempts
+		}
+	}
+	t.Fatalf("应记录%d次认证尝试: %+v", count, attempts)
+	return nil
+}
+
+// TestSSHHoneypotRecordsAttempts 测试记录口令和公钥认证、客户端版本和KEX算法，认证成功后提供模拟shell
+func TestSSHHoneypotRecordsAttempts(t *testing.T) {
+	useSQLiteDatabase(t)
+	keyDir := t.TempDir()
+	honeypot := startTestSSHHoneypot(t, keyDir)
+	status := honeypot.Status()
+	if !status.Running || len(status.HostKeys) != 3 {
+		t.Fatalf("蜜罐状态错误: %+v", status)
+	}
+
+	_, clientKey, _ := ed25519.GenerateKey(rand.Reader)
+	signer, err := ssh.NewSignerFromKey(clientKey)
+	if err != nil {
+		t.Fatalf("创建客户端密钥失败: %v", err)
+	}
+	clientConfig := func(auth ...ssh.AuthMethod) *ssh.ClientConfig {
+		return &ssh.ClientConfig{
+			User:            "root",
+			Auth:            auth,
+			ClientVersion:   "SSH-2.0-libssh_0.9.6",
+			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+			Timeout:         5 * time.Second,
+		}
+	}
+
+	// 口令错误时拒绝
+	if _, err := ssh.Dial("tcp", status.Listener, clientConfig(ssh.Password("123456"))); err == nil {
+		t.Fatalf("错误的口令应被拒绝")
+	}
+
+	// 公钥被拒绝后使用正确的口令登录
+	client, err := ssh.Dial("tcp", status.Listener, clientConfig(ssh.PublicKeys(signer), ssh.Password("toor")))
+	if err != nil {
+		t.Fatalf("配置的口令应被接受: %v", err)
+	}
+	session, err := client.NewSession()
+	if err != nil {
+		t.Fatalf("打开会话失败: %v", err)
+	}
+	var stderr strings.Builder
+	session.Stderr = &stderr
+	var exitErr *ssh.ExitError
+	if err := session.Run("uname -a"); !errors.As(err, &exitErr) || exitErr.ExitStatus() != 127 || !strings.Contains(stderr.String(), "uname: command not found") {
+		t.Fatalf("exec应返回命令不存在: err=%v stderr=%q", err, stderr.String())
+	}
+	client.Close()
+
+	// 只发送版本就断开的扫描器
+	conn, err := net.Dial("tcp", status.Listener)
+	if err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	conn.Write([]byte("SSH-2.0-Go-scanner\r\n"))
+	conn.Close()
+
+	attempts := waitSSHAttempts(t, 4)
+	password, publicKey, accepted, scanner := attempts[0], attempts[1], attempts[2], attempts[3]
+	if password.Method != "password" || password.Accepted || password.ClientVersion != "SSH-2.0-libssh_0.9.6" || password.SourceIP != "127.0.0.1" {
+		t.Errorf("口令尝试记录错误: %+v", password)
+	}
+	if publicKey.Method != "publickey" || publicKey.Accepted || publicKey.KeyType != ssh.KeyAlgoED25519 || publicKey.KeyFingerprint != ssh.FingerprintSHA256(signer.PublicKey()) {
+		t.Errorf("公钥尝试记录错误: %+v", publicKey)
+	}
+	if accepted.Method != "password" || !accepted.Accepted || accepted.SessionID != publicKey.SessionID || accepted.SessionID == password.SessionID {
+		t.Errorf("成功的认证记录错误: %+v", accepted)
+	}
+	if scanner.Method != "none" || scanner.ClientVersion != "SSH-2.0-Go-scanner" || scanner.Hassh != "" {
+		t.Errorf("扫描器连接记录错误: %+v", scanner)
+	}
+
+	var kex SSHClientKex
+	if err := json.Unmarshal([]byte(password.KexAlgorithms), &kex); err != nil || len(kex.Kex) == 0 || len(kex.EncryptionCS) == 0 {
+		t.Fatalf("KEX算法记录错误: %q err=%v", password.KexAlgorithms, err)
+	}
+	if password.Hassh != kex.Hassh() || len(password.Hassh) != 32 {
+		t.Errorf("HASSH指纹错误: %s", password.Hassh)
+	}
+
+	// 口令和公钥尝试同时写入Headling认证日志
+	logs, err := repositories.NewHeadlingAuthLogRepo(config.DB).GetByContainerID(SSHHoneypotContainerID)
+	if err != nil || len(logs) != 3 {
+		t.Fatalf("Headling认证日志应有3条: %+v err=%v", logs, err)
+	}
+	for _, log := range logs {
+		if log.AuthID == password.AuthID && (log.Password != "123456" || log.Username != "root" || log.Protocol != "ssh" || log.ContainerName != "native-ssh") {
+			t.Errorf("Headling认证日志错误: %+v", log)
+		}
+		if log.AuthID == publicKey.AuthID && log.PasswordHash != publicKey.KeyFingerprint {
+			t.Errorf("公钥尝试应记录公钥指纹: %+v", log)
+		}
+	}
+	if status := honeypot.Status(); status.AuthAttempts != 3 || status.Accepted != 1 || status.TotalConnections != 3 {
+		t.Errorf("认证计数错误: %+v", status)
+	}
+
+	// 重启后复用已生成的主机密钥
+	honeypot.Stop()
+	restarted := startTestSSHHoneypot(t, keyDir)
+	if got := restarted.Status().HostKeys; strings.Join(got, ",") != strings.Join(status.HostKeys, ",") {
+		t.Errorf("重启后主机密钥应保持不变: %v != %v", got, status.HostKeys)
+	}
+}
+
+// TestSSHHoneypotConfig 测试认证策略和版本字符串的校验
+func TestSSHHoneypotConfig(t *testing.T) {
+	useSQLiteDatabase(t)
+	t.Setenv("SSH_HONEYPOT_ADDR", "127.0.0.1:0")
+	t.Setenv("SSH_HONEYPOT_HOST_KEY_DIR", t.TempDir())
+
+	for _, env := range []map[string]string{
+		{"SSH_HONEYPOT_BANNER": "OpenSSH_8.2"},
+		{"SSH_HONEYPOT_AUTH_POLICY": "random"},
+		{"SSH_HONEYPOT_AUTH_POLICY": SSHAuthPolicyCredentials},
+		{"SSH_HONEYPOT_AUTH_POLICY": SSHAuthPolicyCredentials, "SSH_HONEYPOT_CREDENTIALS": ":x"},
+	} {
+		for key, value := range env {
+			t.Setenv(key, value)
+		}
+		honeypot := &SSHHoneypot{}
+		if err := honeypot.Start(); err == nil {
+			honeypot.Stop()
+			t.Errorf("配置 %v 应启动失败", env)
+		}
+		t.Setenv("SSH_HONEYPOT_BANNER", "")
+		t.Setenv("SSH_HONEYPOT_AUTH_POLICY", "")
+		t.Setenv("SSH_HONEYPOT_CREDENTIALS", "")
+	}
+
+	credentials, err := parseSSHCredentials(SSHAuthPolicyCredentials, "root:toor, *:admin ,oracle:*")
+	if err != nil || credentials["root"] != "toor" || credentials["*"] != "admin" || credentials["oracle"] != "*" {
+		t.Fatalf("解析口令错误: %v err=%v", credentials, err)
+	}
+	honeypot := &SSHHoneypot{cfg: config.SSHHoneypotConfig{AuthPolicy: SSHAuthPolicyCredentials}, credentials: credentials}
+	for _, c := range []struct {
+		username, password string
+		want               bool
+	}{
+		{"root", "toor", true},
+		{"root", "admin", true},
+		{"oracle", "anything", true},
+		{"root", "123456", false},
+	} {
+		if got := honeypot.acceptPassword(c.username, c.password); got != c.want {
+			t.Errorf("acceptPassword(%s, %s) = %v", c.username, c.password, got)
+		}
+	}
+}
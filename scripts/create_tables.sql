@@ -102,4 +102,33 @@ CREATE TABLE IF NOT EXISTS container_log_segment (
     INDEX idx_container_id (container_id),
     INDEX idx_segment_type (segment_type),
     INDEX idx_timestamp (timestamp)
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='容器日志语义分析结果';
\ No newline at end of file
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='容器日志语义分析结果';
+-- 创建蜜签表
+CREATE TABLE IF NOT EXISTS honey_token (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL COMMENT '蜜签名称',
+    type VARCHAR(20) NOT NULL COMMENT '蜜签类型(credential/file/url/email)',
+    content TEXT COMMENT '蜜签内容',
+    description VARCHAR(255) COMMENT '描述',
+    is_active TINYINT(1) NOT NULL DEFAULT 1 COMMENT '是否启用',
+    trigger_count BIGINT NOT NULL DEFAULT 0 COMMENT '触发次数',
+    create_time DATETIME(3) NOT NULL COMMENT '创建时间',
+    update_time DATETIME(3) NOT NULL COMMENT '更新时间',
+    INDEX idx_honey_token_name (name),
+    INDEX idx_honey_token_type (type)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='蜜签管理';
+
+-- 创建蜜签触发记录表
+CREATE TABLE IF NOT EXISTS honey_token_trigger (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    token_id BIGINT UNSIGNED NOT NULL COMMENT '触发的蜜签ID',
+    token_name VARCHAR(100) COMMENT '触发时的蜜签名称',
+    source_ip VARCHAR(45) COMMENT '触发者IP',
+    user_agent VARCHAR(512) COMMENT '客户端User-Agent',
+    trigger_time DATETIME(3) NOT NULL COMMENT '触发时间',
+    action VARCHAR(100) COMMENT '触发动作',
+    details TEXT COMMENT '详细信息',
+    INDEX idx_honey_token_trigger_token_id (token_id),
+    INDEX idx_honey_token_trigger_source_ip (source_ip),
+    INDEX idx_honey_token_trigger_trigger_time (trigger_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='蜜签触发记录';
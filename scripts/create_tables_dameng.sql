@@ -0,0 +1,429 @@
+-- 达梦(DM8)建表脚本，与 GORM AutoMigrate 在达梦上创建的表结构一致(DB_BACKEND=dameng 时启动会自动建表)
+-- 表名为小写并加引号，列名为大写(DamengNamingStrategy)，仓库中未加引号的小写列名按大写解析
+-- 与MySQL脚本的差异:
+--   1. 没有ENUM类型，协议等枚举列使用VARCHAR
+--   2. 没有JSON类型，端口映射、环境变量等JSON内容使用TEXT
+--   3. 不创建 v_cowrie_statistics、v_headling_auth_statistics 等统计视图，统计由仓库直接查询日志表
+--   4. 端口等无符号小整数使用INT，避免超过SMALLINT的范围
+
+-- 创建蜜罐模板表(honeypot_template)
+CREATE TABLE "honeypot_template" ("ID" BIGINT IDENTITY(1,1),"NAME" VARCHAR(50) NOT NULL,"PROTOCOL" VARCHAR(20) NOT NULL,"LOG_PARSER" VARCHAR(50),"IMPORT_TIME" TIMESTAMP WITH TIME ZONE NOT NULL,"DEPLOY_COUNT" BIGINT DEFAULT 0,PRIMARY KEY ("ID"));
+COMMENT ON COLUMN "honeypot_template"."NAME" IS '蜜罐名称';
+COMMENT ON COLUMN "honeypot_template"."PROTOCOL" IS '协议类型';
+COMMENT ON COLUMN "honeypot_template"."LOG_PARSER" IS '日志解析器';
+COMMENT ON COLUMN "honeypot_template"."IMPORT_TIME" IS '导入时间';
+COMMENT ON COLUMN "honeypot_template"."DEPLOY_COUNT" IS '已部署数量';
+
+-- 创建蜜罐实例表(honeypot_instance)
+CREATE TABLE "honeypot_instance" ("ID" BIGINT IDENTITY(1,1),"NAME" VARCHAR(50) NOT NULL,"HONEYPOT_NAME" VARCHAR(100) NOT NULL,"CONTAINER_NAME" VARCHAR(50) NOT NULL,"CONTAINER_ID" VARCHAR(64),"IP" VARCHAR(45) NOT NULL,"HONEYPOT_IP" VARCHAR(45),"PORT" BIGINT NOT NULL,"PROTOCOL" VARCHAR(20) NOT NULL,"INTERFACE_TYPE" VARCHAR(50),"STATUS" VARCHAR(20) NOT NULL DEFAULT 'created',"IMAGE_NAME" VARCHAR(200),"IMAGE_ID" VARCHAR(100),"LOG_PARSER" VARCHAR(50),"PORT_MAPPINGS" text,"ENVIRONMENT" text,"CREATE_TIME" TIMESTAMP WITH TIME ZONE NOT NULL,"UPDATE_TIME" TIMESTAMP WITH TIME ZONE,"DESCRIPTION" text,PRIMARY KEY ("ID"));
+COMMENT ON COLUMN "honeypot_instance"."NAME" IS '实例名称';
+COMMENT ON COLUMN "honeypot_instance"."HONEYPOT_NAME" IS '蜜罐名称';
+COMMENT ON COLUMN "honeypot_instance"."CONTAINER_NAME" IS '容器名称';
+COMMENT ON COLUMN "honeypot_instance"."CONTAINER_ID" IS 'Docker容器ID';
+COMMENT ON COLUMN "honeypot_instance"."IP" IS 'IP地址';
+COMMENT ON COLUMN "honeypot_instance"."HONEYPOT_IP" IS '蜜罐IP地址';
+COMMENT ON COLUMN "honeypot_instance"."PORT" IS '端口号';
+COMMENT ON COLUMN "honeypot_instance"."PROTOCOL" IS '协议类型';
+COMMENT ON COLUMN "honeypot_instance"."INTERFACE_TYPE" IS '蜜罐接口类型';
+COMMENT ON COLUMN "honeypot_instance"."STATUS" IS '部署状态';
+COMMENT ON COLUMN "honeypot_instance"."IMAGE_NAME" IS 'Docker镜像名称';
+COMMENT ON COLUMN "honeypot_instance"."IMAGE_ID" IS 'Docker镜像ID';
+COMMENT ON COLUMN "honeypot_instance"."LOG_PARSER" IS '日志解析器';
+COMMENT ON COLUMN "honeypot_instance"."PORT_MAPPINGS" IS '端口映射配置';
+COMMENT ON COLUMN "honeypot_instance"."ENVIRONMENT" IS '环境变量配置';
+COMMENT ON COLUMN "honeypot_instance"."CREATE_TIME" IS '创建时间';
+COMMENT ON COLUMN "honeypot_instance"."UPDATE_TIME" IS '更新时间';
+COMMENT ON COLUMN "honeypot_instance"."DESCRIPTION" IS '描述';
+
+-- 创建蜜罐日志表(honeypot_log)
+CREATE TABLE "honeypot_log" ("ID" BIGINT IDENTITY(1,1),"INSTANCE_ID" BIGINT NOT NULL,"LOG_TYPE" VARCHAR(20) NOT NULL,"CONTENT" text NOT NULL,"LOG_TIME" TIMESTAMP WITH TIME ZONE NOT NULL,PRIMARY KEY ("ID"),CONSTRAINT "fk_honeypot_log_instance" FOREIGN KEY ("INSTANCE_ID") REFERENCES "honeypot_instance"("ID"));
+COMMENT ON COLUMN "honeypot_log"."INSTANCE_ID" IS '蜜罐实例ID';
+COMMENT ON COLUMN "honeypot_log"."LOG_TYPE" IS '日志类型';
+COMMENT ON COLUMN "honeypot_log"."CONTENT" IS '日志内容';
+COMMENT ON COLUMN "honeypot_log"."LOG_TIME" IS '记录时间';
+
+-- 创建诱饵表(bait)
+CREATE TABLE "bait" ("ID" BIGINT IDENTITY(1,1),"NAME" VARCHAR(50) NOT NULL,"FILE_TYPE" VARCHAR(10) NOT NULL,"IS_DEPLOYED" BIT DEFAULT 0,"CREATE_TIME" TIMESTAMP WITH TIME ZONE NOT NULL,"INSTANCE_ID" BIGINT,PRIMARY KEY ("ID"),CONSTRAINT "fk_bait_instance" FOREIGN KEY ("INSTANCE_ID") REFERENCES "honeypot_instance"("ID"));
+COMMENT ON COLUMN "bait"."NAME" IS '诱饵名称';
+COMMENT ON COLUMN "bait"."FILE_TYPE" IS '文件类型';
+COMMENT ON COLUMN "bait"."IS_DEPLOYED" IS '投放状态(1已投放,0未投放)';
+COMMENT ON COLUMN "bait"."CREATE_TIME" IS '创建时间';
+COMMENT ON COLUMN "bait"."INSTANCE_ID" IS '关联蜜罐实例';
+
+-- 创建安全规则表(security_rule)
+CREATE TABLE "security_rule" ("ID" BIGINT IDENTITY(1,1),"RULE_NAME" VARCHAR(50) NOT NULL,"TRIGGER_CONDITIONS" text NOT NULL,"ACTIONS" text NOT NULL,"IS_ENABLED" BIT DEFAULT 1,PRIMARY KEY ("ID"));
+COMMENT ON COLUMN "security_rule"."RULE_NAME" IS '规则名称';
+COMMENT ON COLUMN "security_rule"."TRIGGER_CONDITIONS" IS '触发条件';
+COMMENT ON COLUMN "security_rule"."ACTIONS" IS '执行动作';
+COMMENT ON COLUMN "security_rule"."IS_ENABLED" IS '启用状态(1启用,0禁用)';
+
+-- 创建规则日志表(rule_log)
+CREATE TABLE "rule_log" ("ID" BIGINT IDENTITY(1,1),"RULE_ID" BIGINT NOT NULL,"RULE_NAME" VARCHAR(50) NOT NULL,"CONTENT" text NOT NULL,"LOG_TIME" TIMESTAMP WITH TIME ZONE NOT NULL,PRIMARY KEY ("ID"),CONSTRAINT "fk_rule_log_rule" FOREIGN KEY ("RULE_ID") REFERENCES "security_rule"("ID"));
+COMMENT ON COLUMN "rule_log"."RULE_ID" IS '规则ID';
+COMMENT ON COLUMN "rule_log"."RULE_NAME" IS '规则名称';
+COMMENT ON COLUMN "rule_log"."CONTENT" IS '日志内容';
+COMMENT ON COLUMN "rule_log"."LOG_TIME" IS '记录时间';
+
+-- 创建Docker镜像表(docker_image)
+CREATE TABLE "docker_image" ("ID" BIGINT IDENTITY(1,1),"IMAGE_ID" VARCHAR(100) NOT NULL,"REPOSITORY" VARCHAR(100),"TAG" VARCHAR(50),"DIGEST" VARCHAR(100),"SIZE" BIGINT,"CREATED_AT" TIMESTAMP WITH TIME ZONE NOT NULL,"UPDATED_AT" TIMESTAMP WITH TIME ZONE NOT NULL,PRIMARY KEY ("ID"));
+COMMENT ON COLUMN "docker_image"."IMAGE_ID" IS '镜像ID';
+COMMENT ON COLUMN "docker_image"."REPOSITORY" IS '仓库名称';
+COMMENT ON COLUMN "docker_image"."TAG" IS '标签';
+COMMENT ON COLUMN "docker_image"."DIGEST" IS '摘要';
+COMMENT ON COLUMN "docker_image"."SIZE" IS '镜像大小(字节)';
+COMMENT ON COLUMN "docker_image"."CREATED_AT" IS '创建时间';
+COMMENT ON COLUMN "docker_image"."UPDATED_AT" IS '更新时间';
+
+-- 创建Docker镜像操作日志表(docker_image_log)
+CREATE TABLE "docker_image_log" ("ID" BIGINT IDENTITY(1,1),"IMAGE_ID" VARCHAR(100),"IMAGE_NAME" VARCHAR(200),"OPERATION" VARCHAR(20) NOT NULL,"DETAILS" text,"STATUS" VARCHAR(10) NOT NULL,"MESSAGE" text,"CREATED_AT" TIMESTAMP WITH TIME ZONE NOT NULL,PRIMARY KEY ("ID"));
+COMMENT ON COLUMN "docker_image_log"."IMAGE_ID" IS '镜像ID';
+COMMENT ON COLUMN "docker_image_log"."IMAGE_NAME" IS '镜像名称(包含仓库和标签)';
+COMMENT ON COLUMN "docker_image_log"."OPERATION" IS '操作类型(pull/delete/tag/inspect)';
+COMMENT ON COLUMN "docker_image_log"."DETAILS" IS '操作详情';
+COMMENT ON COLUMN "docker_image_log"."STATUS" IS '操作状态(success/failed)';
+COMMENT ON COLUMN "docker_image_log"."MESSAGE" IS '状态消息';
+COMMENT ON COLUMN "docker_image_log"."CREATED_AT" IS '创建时间';
+
+-- 创建容器日志分析结果表(container_log_segment)
+CREATE TABLE "container_log_segment" ("ID" BIGINT IDENTITY(1,1),"CONTAINER_ID" VARCHAR(64) NOT NULL,"CONTAINER_NAME" VARCHAR(100),"SEGMENT_TYPE" VARCHAR(20) NOT NULL,"CONTENT" text NOT NULL,"TIMESTAMP" TIMESTAMP WITH TIME ZONE,"LINE_NUMBER" BIGINT,"COMPONENT" VARCHAR(50),"SEVERITY_LEVEL" VARCHAR(10),"CREATED_AT" TIMESTAMP WITH TIME ZONE NOT NULL,PRIMARY KEY ("ID"));
+COMMENT ON COLUMN "container_log_segment"."CONTAINER_ID" IS '容器ID';
+COMMENT ON COLUMN "container_log_segment"."CONTAINER_NAME" IS '容器名称';
+COMMENT ON COLUMN "container_log_segment"."SEGMENT_TYPE" IS '日志段类型(error/warning/info/debug)';
+COMMENT ON COLUMN "container_log_segment"."CONTENT" IS '日志内容';
+COMMENT ON COLUMN "container_log_segment"."TIMESTAMP" IS '日志时间戳';
+COMMENT ON COLUMN "container_log_segment"."LINE_NUMBER" IS '行号';
+COMMENT ON COLUMN "container_log_segment"."COMPONENT" IS '组件名称';
+COMMENT ON COLUMN "container_log_segment"."SEVERITY_LEVEL" IS '严重程度';
+COMMENT ON COLUMN "container_log_segment"."CREATED_AT" IS '分析时间';
+
+-- 创建Docker容器管理表(docker_container)
+CREATE TABLE "docker_container" ("ID" BIGINT IDENTITY(1,1),"CONTAINER_ID" VARCHAR(64) NOT NULL,"CONTAINER_NAME" VARCHAR(100) NOT NULL,"IMAGE_ID" VARCHAR(100),"IMAGE_NAME" VARCHAR(200),"STATUS" VARCHAR(20),"PORTS" text,"ENVIRONMENT" text,"CREATED_AT" TIMESTAMP WITH TIME ZONE NOT NULL,"UPDATED_AT" TIMESTAMP WITH TIME ZONE NOT NULL,PRIMARY KEY ("ID"));
+COMMENT ON COLUMN "docker_container"."CONTAINER_ID" IS 'Docker容器ID';
+COMMENT ON COLUMN "docker_container"."CONTAINER_NAME" IS '容器名称';
+COMMENT ON COLUMN "docker_container"."IMAGE_ID" IS '关联的镜像ID';
+COMMENT ON COLUMN "docker_container"."IMAGE_NAME" IS '镜像名称';
+COMMENT ON COLUMN "docker_container"."STATUS" IS '容器状态(running/stopped/exited等)';
+COMMENT ON COLUMN "docker_container"."PORTS" IS '端口映射信息';
+COMMENT ON COLUMN "docker_container"."ENVIRONMENT" IS '环境变量';
+COMMENT ON COLUMN "docker_container"."CREATED_AT" IS '创建时间';
+COMMENT ON COLUMN "docker_container"."UPDATED_AT" IS '更新时间';
+
+-- 创建Headling认证日志表(headling_auth_log)
+CREATE TABLE "headling_auth_log" ("ID" BIGINT IDENTITY(1,1),"TIMESTAMP" TIMESTAMP WITH TIME ZONE NOT NULL,"AUTH_ID" VARCHAR(36) NOT NULL,"SESSION_ID" VARCHAR(36) NOT NULL,"SOURCE_IP" VARCHAR(45) NOT NULL,"SOURCE_PORT" BIGINT NOT NULL,"DESTINATION_IP" VARCHAR(45) NOT NULL,"DESTINATION_PORT" BIGINT NOT NULL,"PROTOCOL" VARCHAR(20) NOT NULL,"USERNAME" VARCHAR(255) NOT NULL,"PASSWORD" VARCHAR(255) NOT NULL,"PASSWORD_HASH" VARCHAR(255),"CONTAINER_ID" VARCHAR(64),"CONTAINER_NAME" VARCHAR(100),"CREATED_AT" TIMESTAMP WITH TIME ZONE NOT NULL,PRIMARY KEY ("ID"));
+COMMENT ON COLUMN "headling_auth_log"."TIMESTAMP" IS '捕获到认证行为的时间戳';
+COMMENT ON COLUMN "headling_auth_log"."AUTH_ID" IS '此次认证行为的唯一ID';
+COMMENT ON COLUMN "headling_auth_log"."SESSION_ID" IS '所属会话ID';
+COMMENT ON COLUMN "headling_auth_log"."SOURCE_IP" IS '攻击者IP';
+COMMENT ON COLUMN "headling_auth_log"."SOURCE_PORT" IS '攻击者使用的端口';
+COMMENT ON COLUMN "headling_auth_log"."DESTINATION_IP" IS '被攻击的蜜罐容器IP';
+COMMENT ON COLUMN "headling_auth_log"."DESTINATION_PORT" IS '目标端口';
+COMMENT ON COLUMN "headling_auth_log"."PROTOCOL" IS '使用的协议';
+COMMENT ON COLUMN "headling_auth_log"."USERNAME" IS '攻击者输入的用户名';
+COMMENT ON COLUMN "headling_auth_log"."PASSWORD" IS '攻击者输入的密码';
+COMMENT ON COLUMN "headling_auth_log"."PASSWORD_HASH" IS '密码hash值';
+COMMENT ON COLUMN "headling_auth_log"."CONTAINER_ID" IS '关联的容器ID';
+COMMENT ON COLUMN "headling_auth_log"."CONTAINER_NAME" IS '容器名称';
+COMMENT ON COLUMN "headling_auth_log"."CREATED_AT" IS '记录创建时间';
+CREATE INDEX "idx_headling_auth_log_container_id" ON "headling_auth_log"("CONTAINER_ID");
+CREATE INDEX "idx_headling_auth_log_destination_ip" ON "headling_auth_log"("DESTINATION_IP");
+CREATE INDEX "idx_headling_auth_log_protocol" ON "headling_auth_log"("PROTOCOL");
+CREATE INDEX "idx_headling_auth_log_session_id" ON "headling_auth_log"("SESSION_ID");
+CREATE INDEX "idx_headling_auth_log_source_ip" ON "headling_auth_log"("SOURCE_IP");
+CREATE INDEX "idx_headling_auth_log_username" ON "headling_auth_log"("USERNAME");
+CREATE UNIQUE INDEX "idx_headling_auth_log_auth_id" ON "headling_auth_log"("AUTH_ID");
+
+-- 创建Cowrie蜜罐日志表(cowrie_log)
+CREATE TABLE "cowrie_log" ("ID" BIGINT IDENTITY(1,1),"EVENT_ID" VARCHAR(64),"EVENT_TIME" TIMESTAMP WITH TIME ZONE NOT NULL,"AUTH_ID" VARCHAR(36) NOT NULL,"SESSION_ID" VARCHAR(36) NOT NULL,"SOURCE_IP" VARCHAR(15) NOT NULL,"SOURCE_PORT" INT NOT NULL,"DESTINATION_IP" VARCHAR(15) NOT NULL,"DESTINATION_PORT" INT NOT NULL,"PROTOCOL" VARCHAR(10) NOT NULL,"CLIENT_INFO" VARCHAR(255),"FINGERPRINT" VARCHAR(64),"USERNAME" VARCHAR(255),"PASSWORD" VARCHAR(255),"PASSWORD_HASH" VARCHAR(255),"COMMAND" text,"COMMAND_FOUND" BIT,"MESSAGE" text,"DURATION" DOUBLE,"HASSH" VARCHAR(64),"KEX_ALGORITHMS" text,"URL" VARCHAR(1024),"SHASUM" VARCHAR(64),"OUTFILE" VARCHAR(255),"FILENAME" VARCHAR(255),"TUNNEL_DEST_IP" VARCHAR(255),"TUNNEL_DEST_PORT" INT,"TUNNEL_DATA" text,"TTY_LOG" VARCHAR(255),"RAW_LOG" text NOT NULL,"CONTAINER_ID" VARCHAR(64),"CONTAINER_NAME" VARCHAR(100),"CREATED_AT" TIMESTAMP WITH TIME ZONE NOT NULL,PRIMARY KEY ("ID"));
+COMMENT ON COLUMN "cowrie_log"."EVENT_ID" IS 'Cowrie事件类型(eventid)';
+COMMENT ON COLUMN "cowrie_log"."EVENT_TIME" IS '事件发生的精确时间戳';
+COMMENT ON COLUMN "cowrie_log"."AUTH_ID" IS '认证行为的唯一ID';
+COMMENT ON COLUMN "cowrie_log"."SESSION_ID" IS '会话ID';
+COMMENT ON COLUMN "cowrie_log"."SOURCE_IP" IS '攻击者IP';
+COMMENT ON COLUMN "cowrie_log"."SOURCE_PORT" IS '攻击者使用的端口';
+COMMENT ON COLUMN "cowrie_log"."DESTINATION_IP" IS '蜜罐容器IP';
+COMMENT ON COLUMN "cowrie_log"."DESTINATION_PORT" IS '目标端口';
+COMMENT ON COLUMN "cowrie_log"."PROTOCOL" IS '使用的协议类型(http/ssh/telnet/ftp/smb/other)';
+COMMENT ON COLUMN "cowrie_log"."CLIENT_INFO" IS '客户端信息';
+COMMENT ON COLUMN "cowrie_log"."FINGERPRINT" IS '客户端指纹';
+COMMENT ON COLUMN "cowrie_log"."USERNAME" IS '攻击者输入的用户名';
+COMMENT ON COLUMN "cowrie_log"."PASSWORD" IS '攻击者输入的密码';
+COMMENT ON COLUMN "cowrie_log"."PASSWORD_HASH" IS '密码哈希值';
+COMMENT ON COLUMN "cowrie_log"."COMMAND" IS '攻击者执行的命令内容';
+COMMENT ON COLUMN "cowrie_log"."COMMAND_FOUND" IS '命令是否被系统识别';
+COMMENT ON COLUMN "cowrie_log"."MESSAGE" IS 'Cowrie事件描述';
+COMMENT ON COLUMN "cowrie_log"."DURATION" IS '会话持续时间(秒),session.closed事件';
+COMMENT ON COLUMN "cowrie_log"."HASSH" IS '客户端HASSH指纹,client.kex事件';
+COMMENT ON COLUMN "cowrie_log"."KEX_ALGORITHMS" IS '客户端密钥交换算法列表,client.kex事件';
+COMMENT ON COLUMN "cowrie_log"."URL" IS '下载地址,file_download事件';
+COMMENT ON COLUMN "cowrie_log"."SHASUM" IS '文件SHA256,文件传输事件';
+COMMENT ON COLUMN "cowrie_log"."OUTFILE" IS '文件保存路径,文件传输事件';
+COMMENT ON COLUMN "cowrie_log"."FILENAME" IS '上传文件名,file_upload事件';
+COMMENT ON COLUMN "cowrie_log"."TUNNEL_DEST_IP" IS '端口转发目标地址,direct-tcpip事件';
+COMMENT ON COLUMN "cowrie_log"."TUNNEL_DEST_PORT" IS '端口转发目标端口,direct-tcpip事件';
+COMMENT ON COLUMN "cowrie_log"."TUNNEL_DATA" IS '端口转发数据,direct-tcpip.data事件';
+COMMENT ON COLUMN "cowrie_log"."TTY_LOG" IS 'TTY日志路径,log.closed事件';
+COMMENT ON COLUMN "cowrie_log"."RAW_LOG" IS '原始日志内容';
+COMMENT ON COLUMN "cowrie_log"."CONTAINER_ID" IS '关联的容器ID';
+COMMENT ON COLUMN "cowrie_log"."CONTAINER_NAME" IS '容器名称';
+COMMENT ON COLUMN "cowrie_log"."CREATED_AT" IS '记录创建时间';
+CREATE INDEX "idx_cowrie_log_command_found" ON "cowrie_log"("COMMAND_FOUND");
+CREATE INDEX "idx_cowrie_log_container_id" ON "cowrie_log"("CONTAINER_ID");
+CREATE INDEX "idx_cowrie_log_destination_ip" ON "cowrie_log"("DESTINATION_IP");
+CREATE INDEX "idx_cowrie_log_event_id" ON "cowrie_log"("EVENT_ID");
+CREATE INDEX "idx_cowrie_log_protocol" ON "cowrie_log"("PROTOCOL");
+CREATE INDEX "idx_cowrie_log_session_id" ON "cowrie_log"("SESSION_ID");
+CREATE INDEX "idx_cowrie_log_shasum" ON "cowrie_log"("SHASUM");
+CREATE INDEX "idx_cowrie_log_source_ip" ON "cowrie_log"("SOURCE_IP");
+CREATE INDEX "idx_cowrie_log_username" ON "cowrie_log"("USERNAME");
+CREATE UNIQUE INDEX "idx_cowrie_log_auth_id" ON "cowrie_log"("AUTH_ID");
+
+-- 创建容器日志文件读取游标表(container_log_cursor)
+CREATE TABLE "container_log_cursor" ("ID" BIGINT IDENTITY(1,1),"CONTAINER_ID" VARCHAR(64) NOT NULL,"LOG_SOURCE" VARCHAR(20) NOT NULL,"FILE_PATH" VARCHAR(255) NOT NULL,"OFFSET" BIGINT NOT NULL DEFAULT 0,"HEAD_HASH" VARCHAR(64),"FILE_SIZE" BIGINT,"MOD_TIME" TIMESTAMP WITH TIME ZONE,"LINE_COUNT" BIGINT DEFAULT 0,"LAST_RECORD_ID" VARCHAR(64),"UPDATED_AT" TIMESTAMP WITH TIME ZONE NOT NULL,PRIMARY KEY ("ID"));
+COMMENT ON COLUMN "container_log_cursor"."CONTAINER_ID" IS '容器ID';
+COMMENT ON COLUMN "container_log_cursor"."LOG_SOURCE" IS '日志来源(cowrie/headling等)';
+COMMENT ON COLUMN "container_log_cursor"."FILE_PATH" IS '容器内日志文件路径';
+COMMENT ON COLUMN "container_log_cursor"."OFFSET" IS '已读取的字节偏移';
+COMMENT ON COLUMN "container_log_cursor"."HEAD_HASH" IS '文件头部哈希,用于识别日志轮转';
+COMMENT ON COLUMN "container_log_cursor"."FILE_SIZE" IS '上次读取时的文件大小';
+COMMENT ON COLUMN "container_log_cursor"."MOD_TIME" IS '上次读取时的文件修改时间';
+COMMENT ON COLUMN "container_log_cursor"."LINE_COUNT" IS '已读取的行数';
+COMMENT ON COLUMN "container_log_cursor"."LAST_RECORD_ID" IS '最后一条记录的ID';
+COMMENT ON COLUMN "container_log_cursor"."UPDATED_AT" IS '更新时间';
+CREATE UNIQUE INDEX "idx_container_log_cursor" ON "container_log_cursor"("CONTAINER_ID","LOG_SOURCE");
+
+-- 创建Cowrie会话TTY日志表(cowrie_ttylog)
+CREATE TABLE "cowrie_ttylog" ("ID" BIGINT IDENTITY(1,1),"SESSION_ID" VARCHAR(36) NOT NULL,"COWRIE_LOG_ID" BIGINT NOT NULL,"CONTAINER_ID" VARCHAR(64),"TTY_LOG_PATH" VARCHAR(255) NOT NULL,"LOCAL_PATH" VARCHAR(255) NOT NULL,"SHASUM" VARCHAR(64),"SIZE" BIGINT,"DURATION" DOUBLE,"CREATED_AT" TIMESTAMP WITH TIME ZONE NOT NULL,PRIMARY KEY ("ID"));
+COMMENT ON COLUMN "cowrie_ttylog"."SESSION_ID" IS '会话ID';
+COMMENT ON COLUMN "cowrie_ttylog"."COWRIE_LOG_ID" IS '关联的log.closed事件ID';
+COMMENT ON COLUMN "cowrie_ttylog"."CONTAINER_ID" IS '容器ID';
+COMMENT ON COLUMN "cowrie_ttylog"."TTY_LOG_PATH" IS '容器内TTY日志路径';
+COMMENT ON COLUMN "cowrie_ttylog"."LOCAL_PATH" IS '本地保存路径';
+COMMENT ON COLUMN "cowrie_ttylog"."SHASUM" IS 'TTY日志SHA256';
+COMMENT ON COLUMN "cowrie_ttylog"."SIZE" IS 'TTY日志大小(字节)';
+COMMENT ON COLUMN "cowrie_ttylog"."DURATION" IS '会话持续时间(秒)';
+COMMENT ON COLUMN "cowrie_ttylog"."CREATED_AT" IS '记录创建时间';
+CREATE INDEX "idx_cowrie_ttylog_container_id" ON "cowrie_ttylog"("CONTAINER_ID");
+CREATE UNIQUE INDEX "idx_cowrie_ttylog_session_id" ON "cowrie_ttylog"("SESSION_ID");
+
+-- 创建Dionaea蜜罐日志表(dionaea_log)
+CREATE TABLE "dionaea_log" ("ID" BIGINT IDENTITY(1,1),"EVENT_TYPE" VARCHAR(20) NOT NULL,"EVENT_TIME" TIMESTAMP WITH TIME ZONE NOT NULL,"AUTH_ID" VARCHAR(36) NOT NULL,"SESSION_ID" VARCHAR(36) NOT NULL,"SOURCE_IP" VARCHAR(45) NOT NULL,"SOURCE_PORT" INT,"DESTINATION_IP" VARCHAR(45),"DESTINATION_PORT" INT,"PROTOCOL" VARCHAR(32),"TRANSPORT" VARCHAR(10),"CONNECTION_TYPE" VARCHAR(20),"USERNAME" VARCHAR(255),"PASSWORD" VARCHAR(255),"URL" VARCHAR(1024),"MD5_HASH" VARCHAR(32),"FTP_COMMANDS" text,"RAW_LOG" text NOT NULL,"CONTAINER_ID" VARCHAR(64),"CONTAINER_NAME" VARCHAR(100),"CREATED_AT" TIMESTAMP WITH TIME ZONE NOT NULL,PRIMARY KEY ("ID"));
+COMMENT ON COLUMN "dionaea_log"."EVENT_TYPE" IS '事件类型(connection/login/download)';
+COMMENT ON COLUMN "dionaea_log"."EVENT_TIME" IS '事件发生时间';
+COMMENT ON COLUMN "dionaea_log"."AUTH_ID" IS '事件的唯一ID';
+COMMENT ON COLUMN "dionaea_log"."SESSION_ID" IS '连接ID，同一连接的事件相同';
+COMMENT ON COLUMN "dionaea_log"."SOURCE_IP" IS '攻击者IP';
+COMMENT ON COLUMN "dionaea_log"."SOURCE_PORT" IS '攻击者端口';
+COMMENT ON COLUMN "dionaea_log"."DESTINATION_IP" IS '蜜罐IP';
+COMMENT ON COLUMN "dionaea_log"."DESTINATION_PORT" IS '蜜罐端口';
+COMMENT ON COLUMN "dionaea_log"."PROTOCOL" IS 'Dionaea服务模块(httpd/ftpd/smbd等)';
+COMMENT ON COLUMN "dionaea_log"."TRANSPORT" IS '传输层协议(tcp/udp/tls)';
+COMMENT ON COLUMN "dionaea_log"."CONNECTION_TYPE" IS '连接类型(accept/connect/listen)';
+COMMENT ON COLUMN "dionaea_log"."USERNAME" IS '登录用户名';
+COMMENT ON COLUMN "dionaea_log"."PASSWORD" IS '登录密码';
+COMMENT ON COLUMN "dionaea_log"."URL" IS '下载地址';
+COMMENT ON COLUMN "dionaea_log"."MD5_HASH" IS '下载文件MD5';
+COMMENT ON COLUMN "dionaea_log"."FTP_COMMANDS" IS 'FTP命令,以换行分隔';
+COMMENT ON COLUMN "dionaea_log"."RAW_LOG" IS '原始日志内容';
+COMMENT ON COLUMN "dionaea_log"."CONTAINER_ID" IS '关联的容器ID';
+COMMENT ON COLUMN "dionaea_log"."CONTAINER_NAME" IS '容器名称';
+COMMENT ON COLUMN "dionaea_log"."CREATED_AT" IS '记录创建时间';
+CREATE INDEX "idx_dionaea_log_container_id" ON "dionaea_log"("CONTAINER_ID");
+CREATE INDEX "idx_dionaea_log_destination_port" ON "dionaea_log"("DESTINATION_PORT");
+CREATE INDEX "idx_dionaea_log_event_time" ON "dionaea_log"("EVENT_TIME");
+CREATE INDEX "idx_dionaea_log_event_type" ON "dionaea_log"("EVENT_TYPE");
+CREATE INDEX "idx_dionaea_log_md5_hash" ON "dionaea_log"("MD5_HASH");
+CREATE INDEX "idx_dionaea_log_protocol" ON "dionaea_log"("PROTOCOL");
+CREATE INDEX "idx_dionaea_log_session_id" ON "dionaea_log"("SESSION_ID");
+CREATE INDEX "idx_dionaea_log_source_ip" ON "dionaea_log"("SOURCE_IP");
+CREATE INDEX "idx_dionaea_log_username" ON "dionaea_log"("USERNAME");
+CREATE UNIQUE INDEX "idx_dionaea_log_auth_id" ON "dionaea_log"("AUTH_ID");
+
+-- 创建qeeqbox蜜罐日志表(qeeqbox_log)
+CREATE TABLE "qeeqbox_log" ("ID" BIGINT IDENTITY(1,1),"AUTH_ID" VARCHAR(36) NOT NULL,"EVENT_TIME" TIMESTAMP WITH TIME ZONE NOT NULL,"SERVER" VARCHAR(32) NOT NULL,"PROTOCOL" VARCHAR(20) NOT NULL,"ACTION" VARCHAR(32) NOT NULL,"STATUS" VARCHAR(20),"SOURCE_IP" VARCHAR(45) NOT NULL,"SOURCE_PORT" INT,"DESTINATION_IP" VARCHAR(45),"DESTINATION_PORT" INT,"USERNAME" VARCHAR(255),"PASSWORD" VARCHAR(255),"DETAIL" text,"DATA" text,"RAW_LOG" text NOT NULL,"CONTAINER_ID" VARCHAR(64),"CONTAINER_NAME" VARCHAR(100),"CREATED_AT" TIMESTAMP WITH TIME ZONE NOT NULL,PRIMARY KEY ("ID"));
+COMMENT ON COLUMN "qeeqbox_log"."AUTH_ID" IS '事件的唯一ID';
+COMMENT ON COLUMN "qeeqbox_log"."EVENT_TIME" IS '事件发生时间';
+COMMENT ON COLUMN "qeeqbox_log"."SERVER" IS 'qeeqbox服务名(mysql_server等)';
+COMMENT ON COLUMN "qeeqbox_log"."PROTOCOL" IS '协议(mysql/redis/postgres/smtp等)';
+COMMENT ON COLUMN "qeeqbox_log"."ACTION" IS '动作(connection/login/query/command等)';
+COMMENT ON COLUMN "qeeqbox_log"."STATUS" IS '动作结果(success/failed)';
+COMMENT ON COLUMN "qeeqbox_log"."SOURCE_IP" IS '攻击者IP';
+COMMENT ON COLUMN "qeeqbox_log"."SOURCE_PORT" IS '攻击者端口';
+COMMENT ON COLUMN "qeeqbox_log"."DESTINATION_IP" IS '蜜罐IP';
+COMMENT ON COLUMN "qeeqbox_log"."DESTINATION_PORT" IS '蜜罐端口';
+COMMENT ON COLUMN "qeeqbox_log"."USERNAME" IS '登录用户名';
+COMMENT ON COLUMN "qeeqbox_log"."PASSWORD" IS '登录密码';
+COMMENT ON COLUMN "qeeqbox_log"."DETAIL" IS '攻击者执行的命令或查询';
+COMMENT ON COLUMN "qeeqbox_log"."DATA" IS '事件附加数据(JSON)';
+COMMENT ON COLUMN "qeeqbox_log"."RAW_LOG" IS '原始日志内容';
+COMMENT ON COLUMN "qeeqbox_log"."CONTAINER_ID" IS '关联的容器ID';
+COMMENT ON COLUMN "qeeqbox_log"."CONTAINER_NAME" IS '容器名称';
+COMMENT ON COLUMN "qeeqbox_log"."CREATED_AT" IS '记录创建时间';
+CREATE INDEX "idx_qeeqbox_log_action" ON "qeeqbox_log"("ACTION");
+CREATE INDEX "idx_qeeqbox_log_container_id" ON "qeeqbox_log"("CONTAINER_ID");
+CREATE INDEX "idx_qeeqbox_log_event_time" ON "qeeqbox_log"("EVENT_TIME");
+CREATE INDEX "idx_qeeqbox_log_protocol" ON "qeeqbox_log"("PROTOCOL");
+CREATE INDEX "idx_qeeqbox_log_source_ip" ON "qeeqbox_log"("SOURCE_IP");
+CREATE INDEX "idx_qeeqbox_log_username" ON "qeeqbox_log"("USERNAME");
+CREATE UNIQUE INDEX "idx_qeeqbox_log_auth_id" ON "qeeqbox_log"("AUTH_ID");
+
+-- 创建syslog消息表(syslog_message)
+CREATE TABLE "syslog_message" ("ID" BIGINT IDENTITY(1,1),"SENSOR_ID" VARCHAR(64) NOT NULL,"SOURCE_IP" VARCHAR(45) NOT NULL,"TRANSPORT" VARCHAR(10) NOT NULL,"FORMAT" VARCHAR(10) NOT NULL,"FACILITY" BIGINT,"SEVERITY" BIGINT,"HOSTNAME" VARCHAR(255),"APP_NAME" VARCHAR(48),"PROC_ID" VARCHAR(128),"MSG_ID" VARCHAR(32),"STRUCTURED_DATA" text,"MESSAGE" text NOT NULL,"PARSER" VARCHAR(20),"EVENT_TIME" TIMESTAMP WITH TIME ZONE NOT NULL,"RECEIVED_AT" TIMESTAMP WITH TIME ZONE NOT NULL,"CREATED_AT" TIMESTAMP WITH TIME ZONE NOT NULL,PRIMARY KEY ("ID"));
+COMMENT ON COLUMN "syslog_message"."SENSOR_ID" IS '发送方传感器标识';
+COMMENT ON COLUMN "syslog_message"."SOURCE_IP" IS '发送方IP';
+COMMENT ON COLUMN "syslog_message"."TRANSPORT" IS '传输方式(udp/tcp/tls)';
+COMMENT ON COLUMN "syslog_message"."FORMAT" IS '消息格式(rfc3164/rfc5424)';
+COMMENT ON COLUMN "syslog_message"."FACILITY" IS 'syslog facility';
+COMMENT ON COLUMN "syslog_message"."SEVERITY" IS 'syslog severity';
+COMMENT ON COLUMN "syslog_message"."HOSTNAME" IS '消息头中的主机名';
+COMMENT ON COLUMN "syslog_message"."APP_NAME" IS '应用名(TAG/APP-NAME)';
+COMMENT ON COLUMN "syslog_message"."PROC_ID" IS '进程ID';
+COMMENT ON COLUMN "syslog_message"."MSG_ID" IS '消息类型(RFC 5424 MSGID)';
+COMMENT ON COLUMN "syslog_message"."STRUCTURED_DATA" IS '结构化数据(RFC 5424)';
+COMMENT ON COLUMN "syslog_message"."MESSAGE" IS '消息正文';
+COMMENT ON COLUMN "syslog_message"."PARSER" IS '处理消息正文的解析器';
+COMMENT ON COLUMN "syslog_message"."EVENT_TIME" IS '消息时间戳';
+COMMENT ON COLUMN "syslog_message"."RECEIVED_AT" IS '接收时间';
+COMMENT ON COLUMN "syslog_message"."CREATED_AT" IS '记录创建时间';
+CREATE INDEX "idx_syslog_message_app_name" ON "syslog_message"("APP_NAME");
+CREATE INDEX "idx_syslog_message_event_time" ON "syslog_message"("EVENT_TIME");
+CREATE INDEX "idx_syslog_message_parser" ON "syslog_message"("PARSER");
+CREATE INDEX "idx_syslog_message_sensor_id" ON "syslog_message"("SENSOR_ID");
+CREATE INDEX "idx_syslog_message_source_ip" ON "syslog_message"("SOURCE_IP");
+
+-- 创建攻击时间线事件表(attack_timeline_event)
+CREATE TABLE "attack_timeline_event" ("ID" BIGINT IDENTITY(1,1),"EVENT_TIME" TIMESTAMP WITH TIME ZONE NOT NULL,"AUTH_ID" VARCHAR(36) NOT NULL,"SOURCE" VARCHAR(20) NOT NULL,"SOURCE_REF" VARCHAR(64),"SESSION_ID" VARCHAR(64),"SOURCE_IP" VARCHAR(45),"SOURCE_PORT" BIGINT,"DESTINATION_IP" VARCHAR(45),"DESTINATION_PORT" BIGINT,"PROTOCOL" VARCHAR(32),"ACTION" VARCHAR(64),"USERNAME" VARCHAR(255),"PASSWORD" VARCHAR(255),"PAYLOAD" text,"SEVERITY" VARCHAR(10) NOT NULL,"CONTAINER_ID" VARCHAR(64),"CONTAINER_NAME" VARCHAR(100),"CREATED_AT" TIMESTAMP WITH TIME ZONE NOT NULL,PRIMARY KEY ("ID"));
+COMMENT ON COLUMN "attack_timeline_event"."EVENT_TIME" IS '事件发生时间';
+COMMENT ON COLUMN "attack_timeline_event"."AUTH_ID" IS '由来源和来源记录生成的唯一ID';
+COMMENT ON COLUMN "attack_timeline_event"."SOURCE" IS '事件来源(cowrie/headling/dionaea/qeeqbox/attack_capture/honeytoken)';
+COMMENT ON COLUMN "attack_timeline_event"."SOURCE_REF" IS '来源记录的标识';
+COMMENT ON COLUMN "attack_timeline_event"."SESSION_ID" IS '会话ID';
+COMMENT ON COLUMN "attack_timeline_event"."SOURCE_IP" IS '攻击者IP';
+COMMENT ON COLUMN "attack_timeline_event"."SOURCE_PORT" IS '攻击者端口';
+COMMENT ON COLUMN "attack_timeline_event"."DESTINATION_IP" IS '目标IP';
+COMMENT ON COLUMN "attack_timeline_event"."DESTINATION_PORT" IS '目标端口';
+COMMENT ON COLUMN "attack_timeline_event"."PROTOCOL" IS '协议';
+COMMENT ON COLUMN "attack_timeline_event"."ACTION" IS '攻击动作';
+COMMENT ON COLUMN "attack_timeline_event"."USERNAME" IS '攻击者使用的用户名';
+COMMENT ON COLUMN "attack_timeline_event"."PASSWORD" IS '攻击者使用的密码';
+COMMENT ON COLUMN "attack_timeline_event"."PAYLOAD" IS '命令、请求或下载地址等攻击载荷';
+COMMENT ON COLUMN "attack_timeline_event"."SEVERITY" IS '严重程度(low/medium/high/critical)';
+COMMENT ON COLUMN "attack_timeline_event"."CONTAINER_ID" IS '关联的容器ID';
+COMMENT ON COLUMN "attack_timeline_event"."CONTAINER_NAME" IS '容器名称';
+COMMENT ON COLUMN "attack_timeline_event"."CREATED_AT" IS '记录创建时间';
+CREATE INDEX "idx_attack_timeline_event_action" ON "attack_timeline_event"("ACTION");
+CREATE INDEX "idx_attack_timeline_event_container_id" ON "attack_timeline_event"("CONTAINER_ID");
+CREATE INDEX "idx_attack_timeline_event_destination_ip" ON "attack_timeline_event"("DESTINATION_IP");
+CREATE INDEX "idx_attack_timeline_event_protocol" ON "attack_timeline_event"("PROTOCOL");
+CREATE INDEX "idx_attack_timeline_event_session_id" ON "attack_timeline_event"("SESSION_ID");
+CREATE INDEX "idx_attack_timeline_event_severity" ON "attack_timeline_event"("SEVERITY");
+CREATE INDEX "idx_attack_timeline_event_source" ON "attack_timeline_event"("SOURCE");
+CREATE INDEX "idx_attack_timeline_event_source_ip" ON "attack_timeline_event"("SOURCE_IP");
+CREATE INDEX "idx_attack_timeline_event_username" ON "attack_timeline_event"("USERNAME");
+CREATE INDEX "idx_timeline_time_id" ON "attack_timeline_event"("EVENT_TIME","ID");
+CREATE UNIQUE INDEX "idx_attack_timeline_event_auth_id" ON "attack_timeline_event"("AUTH_ID");
+
+-- 创建攻击事件表(attack_event)
+CREATE TABLE "attack_event" ("ID" BIGINT IDENTITY(1,1),"SOURCE_IP" VARCHAR(45) NOT NULL,"SOURCE_PORT" BIGINT,"DEST_IP" VARCHAR(45) NOT NULL,"DEST_PORT" BIGINT,"PROTOCOL" VARCHAR(32) NOT NULL,"ATTACK_TYPE" VARCHAR(100) NOT NULL,"PAYLOAD" text,"TIMESTAMP" TIMESTAMP WITH TIME ZONE NOT NULL,"SEVERITY" VARCHAR(10) NOT NULL,"CONTAINER_ID" VARCHAR(64),"CONTAINER_NAME" VARCHAR(100),"USER_AGENT" VARCHAR(512),"SESSION_ID" VARCHAR(64),"ATTACK_SESSION_ID" BIGINT,"CREATED_AT" TIMESTAMP WITH TIME ZONE NOT NULL,PRIMARY KEY ("ID"));
+COMMENT ON COLUMN "attack_event"."SOURCE_IP" IS '攻击者IP';
+COMMENT ON COLUMN "attack_event"."SOURCE_PORT" IS '攻击者端口';
+COMMENT ON COLUMN "attack_event"."DEST_IP" IS '目标IP';
+COMMENT ON COLUMN "attack_event"."DEST_PORT" IS '目标端口';
+COMMENT ON COLUMN "attack_event"."PROTOCOL" IS '协议';
+COMMENT ON COLUMN "attack_event"."ATTACK_TYPE" IS '攻击类型';
+COMMENT ON COLUMN "attack_event"."PAYLOAD" IS '攻击载荷';
+COMMENT ON COLUMN "attack_event"."TIMESTAMP" IS '事件发生时间';
+COMMENT ON COLUMN "attack_event"."SEVERITY" IS '严重程度(low/medium/high/critical)';
+COMMENT ON COLUMN "attack_event"."CONTAINER_ID" IS '关联的容器ID';
+COMMENT ON COLUMN "attack_event"."CONTAINER_NAME" IS '容器名称';
+COMMENT ON COLUMN "attack_event"."USER_AGENT" IS '客户端User-Agent';
+COMMENT ON COLUMN "attack_event"."SESSION_ID" IS '会话标识，为空时按攻击者IP归并';
+COMMENT ON COLUMN "attack_event"."ATTACK_SESSION_ID" IS '所属攻击会话ID';
+COMMENT ON COLUMN "attack_event"."CREATED_AT" IS '记录创建时间';
+CREATE INDEX "idx_attack_event_attack_session_id" ON "attack_event"("ATTACK_SESSION_ID");
+CREATE INDEX "idx_attack_event_attack_type" ON "attack_event"("ATTACK_TYPE");
+CREATE INDEX "idx_attack_event_container_id" ON "attack_event"("CONTAINER_ID");
+CREATE INDEX "idx_attack_event_protocol" ON "attack_event"("PROTOCOL");
+CREATE INDEX "idx_attack_event_session_id" ON "attack_event"("SESSION_ID");
+CREATE INDEX "idx_attack_event_severity" ON "attack_event"("SEVERITY");
+CREATE INDEX "idx_attack_event_source_ip" ON "attack_event"("SOURCE_IP");
+CREATE INDEX "idx_attack_event_timestamp" ON "attack_event"("TIMESTAMP");
+
+-- 创建攻击会话表(attack_session)
+CREATE TABLE "attack_session" ("ID" BIGINT IDENTITY(1,1),"SESSION_ID" VARCHAR(64) NOT NULL,"SOURCE_IP" VARCHAR(45) NOT NULL,"START_TIME" TIMESTAMP WITH TIME ZONE NOT NULL,"LAST_EVENT_TIME" TIMESTAMP WITH TIME ZONE NOT NULL,"END_TIME" TIMESTAMP WITH TIME ZONE,"EVENT_COUNT" BIGINT NOT NULL DEFAULT 0,"ATTACK_TYPES" text,"CREATED_AT" TIMESTAMP WITH TIME ZONE NOT NULL,"UPDATED_AT" TIMESTAMP WITH TIME ZONE,PRIMARY KEY ("ID"));
+COMMENT ON COLUMN "attack_session"."SESSION_ID" IS '会话标识(上报的会话ID或攻击者IP)';
+COMMENT ON COLUMN "attack_session"."SOURCE_IP" IS '攻击者IP';
+COMMENT ON COLUMN "attack_session"."START_TIME" IS '会话开始时间';
+COMMENT ON COLUMN "attack_session"."LAST_EVENT_TIME" IS '最后一个事件的时间';
+COMMENT ON COLUMN "attack_session"."END_TIME" IS '会话结束时间，为空表示会话未结束';
+COMMENT ON COLUMN "attack_session"."EVENT_COUNT" IS '事件数量';
+COMMENT ON COLUMN "attack_session"."ATTACK_TYPES" IS '出现过的攻击类型';
+COMMENT ON COLUMN "attack_session"."CREATED_AT" IS '记录创建时间';
+COMMENT ON COLUMN "attack_session"."UPDATED_AT" IS '记录更新时间';
+CREATE INDEX "idx_attack_session_end_time" ON "attack_session"("END_TIME");
+CREATE INDEX "idx_attack_session_session_id" ON "attack_session"("SESSION_ID");
+CREATE INDEX "idx_attack_session_source_ip" ON "attack_session"("SOURCE_IP");
+CREATE INDEX "idx_attack_session_start_time" ON "attack_session"("START_TIME");
+
+-- 创建恶意样本表(malware_sample)
+CREATE TABLE "malware_sample" ("ID" BIGINT IDENTITY(1,1),"SHA256" VARCHAR(64) NOT NULL,"SHA1" VARCHAR(40) NOT NULL,"MD5" VARCHAR(32) NOT NULL,"SS_DEEP" VARCHAR(255),"FILE_SIZE" BIGINT NOT NULL,"FILE_TYPE" VARCHAR(100),"QUARANTINE_PATH" VARCHAR(255) NOT NULL,"SIGHTING_COUNT" BIGINT NOT NULL DEFAULT 0,"FIRST_SEEN" TIMESTAMP WITH TIME ZONE NOT NULL,"LAST_SEEN" TIMESTAMP WITH TIME ZONE NOT NULL,"CREATED_AT" TIMESTAMP WITH TIME ZONE NOT NULL,"UPDATED_AT" TIMESTAMP WITH TIME ZONE NOT NULL,PRIMARY KEY ("ID"));
+COMMENT ON COLUMN "malware_sample"."SHA256" IS '样本SHA256';
+COMMENT ON COLUMN "malware_sample"."SHA1" IS '样本SHA1';
+COMMENT ON COLUMN "malware_sample"."MD5" IS '样本MD5';
+COMMENT ON COLUMN "malware_sample"."SS_DEEP" IS '样本ssdeep模糊哈希';
+COMMENT ON COLUMN "malware_sample"."FILE_SIZE" IS '文件大小(字节)';
+COMMENT ON COLUMN "malware_sample"."FILE_TYPE" IS '文件类型';
+COMMENT ON COLUMN "malware_sample"."QUARANTINE_PATH" IS '隔离区中的存储路径';
+COMMENT ON COLUMN "malware_sample"."SIGHTING_COUNT" IS '被捕获的次数';
+COMMENT ON COLUMN "malware_sample"."FIRST_SEEN" IS '首次捕获时间';
+COMMENT ON COLUMN "malware_sample"."LAST_SEEN" IS '最近捕获时间';
+COMMENT ON COLUMN "malware_sample"."CREATED_AT" IS '记录创建时间';
+COMMENT ON COLUMN "malware_sample"."UPDATED_AT" IS '记录更新时间';
+CREATE INDEX "idx_malware_sample_md5" ON "malware_sample"("MD5");
+CREATE INDEX "idx_malware_sample_sha1" ON "malware_sample"("SHA1");
+CREATE UNIQUE INDEX "idx_malware_sample_sha256" ON "malware_sample"("SHA256");
+
+-- 创建恶意样本捕获记录表(malware_sighting)
+CREATE TABLE "malware_sighting" ("ID" BIGINT IDENTITY(1,1),"SAMPLE_ID" BIGINT NOT NULL,"SHA256" VARCHAR(64) NOT NULL,"COWRIE_LOG_ID" BIGINT NOT NULL,"SESSION_ID" VARCHAR(36),"SOURCE_IP" VARCHAR(45),"EVENT_ID" VARCHAR(64),"URL" VARCHAR(1024),"FILENAME" VARCHAR(255),"CONTAINER_ID" VARCHAR(64),"EVENT_TIME" TIMESTAMP WITH TIME ZONE NOT NULL,"CREATED_AT" TIMESTAMP WITH TIME ZONE NOT NULL,PRIMARY KEY ("ID"));
+COMMENT ON COLUMN "malware_sighting"."SAMPLE_ID" IS '关联的样本ID';
+COMMENT ON COLUMN "malware_sighting"."SHA256" IS '样本SHA256';
+COMMENT ON COLUMN "malware_sighting"."COWRIE_LOG_ID" IS '关联的Cowrie文件传输事件ID';
+COMMENT ON COLUMN "malware_sighting"."SESSION_ID" IS '会话ID';
+COMMENT ON COLUMN "malware_sighting"."SOURCE_IP" IS '攻击者IP';
+COMMENT ON COLUMN "malware_sighting"."EVENT_ID" IS '文件传输事件类型';
+COMMENT ON COLUMN "malware_sighting"."URL" IS '下载地址';
+COMMENT ON COLUMN "malware_sighting"."FILENAME" IS '上传文件名';
+COMMENT ON COLUMN "malware_sighting"."CONTAINER_ID" IS '容器ID';
+COMMENT ON COLUMN "malware_sighting"."EVENT_TIME" IS '事件发生时间';
+COMMENT ON COLUMN "malware_sighting"."CREATED_AT" IS '记录创建时间';
+CREATE INDEX "idx_malware_sighting_container_id" ON "malware_sighting"("CONTAINER_ID");
+CREATE INDEX "idx_malware_sighting_sample_id" ON "malware_sighting"("SAMPLE_ID");
+CREATE INDEX "idx_malware_sighting_session_id" ON "malware_sighting"("SESSION_ID");
+CREATE INDEX "idx_malware_sighting_sha256" ON "malware_sighting"("SHA256");
+CREATE INDEX "idx_malware_sighting_source_ip" ON "malware_sighting"("SOURCE_IP");
+CREATE UNIQUE INDEX "idx_malware_sighting_cowrie_log_id" ON "malware_sighting"("COWRIE_LOG_ID");
+
+-- 创建蜜签表(honey_token)
+CREATE TABLE "honey_token" ("ID" BIGINT IDENTITY(1,1),"NAME" VARCHAR(100) NOT NULL,"TYPE" VARCHAR(20) NOT NULL,"CONTENT" text,"DESCRIPTION" VARCHAR(255),"IS_ACTIVE" BIT NOT NULL DEFAULT 1,"TRIGGER_COUNT" BIGINT NOT NULL DEFAULT 0,"CREATE_TIME" TIMESTAMP WITH TIME ZONE NOT NULL,"UPDATE_TIME" TIMESTAMP WITH TIME ZONE NOT NULL,PRIMARY KEY ("ID"));
+COMMENT ON COLUMN "honey_token"."NAME" IS '蜜签名称';
+COMMENT ON COLUMN "honey_token"."TYPE" IS '蜜签类型(credential/file/url/email)';
+COMMENT ON COLUMN "honey_token"."CONTENT" IS '蜜签内容';
+COMMENT ON COLUMN "honey_token"."DESCRIPTION" IS '描述';
+COMMENT ON COLUMN "honey_token"."IS_ACTIVE" IS '是否启用';
+COMMENT ON COLUMN "honey_token"."TRIGGER_COUNT" IS '触发次数';
+COMMENT ON COLUMN "honey_token"."CREATE_TIME" IS '创建时间';
+COMMENT ON COLUMN "honey_token"."UPDATE_TIME" IS '更新时间';
+CREATE INDEX "idx_honey_token_name" ON "honey_token"("NAME");
+CREATE INDEX "idx_honey_token_type" ON "honey_token"("TYPE");
+
+-- 创建蜜签触发记录表(honey_token_trigger)
+CREATE TABLE "honey_token_trigger" ("ID" BIGINT IDENTITY(1,1),"TOKEN_ID" BIGINT NOT NULL,"TOKEN_NAME" VARCHAR(100),"SOURCE_IP" VARCHAR(45),"USER_AGENT" VARCHAR(512),"TRIGGER_TIME" TIMESTAMP WITH TIME ZONE NOT NULL,"ACTION" VARCHAR(100),"DETAILS" text,PRIMARY KEY ("ID"));
+COMMENT ON COLUMN "honey_token_trigger"."TOKEN_ID" IS '触发的蜜签ID';
+COMMENT ON COLUMN "honey_token_trigger"."TOKEN_NAME" IS '触发时的蜜签名称';
+COMMENT ON COLUMN "honey_token_trigger"."SOURCE_IP" IS '触发者IP';
+COMMENT ON COLUMN "honey_token_trigger"."USER_AGENT" IS '客户端User-Agent';
+COMMENT ON COLUMN "honey_token_trigger"."TRIGGER_TIME" IS '触发时间';
+COMMENT ON COLUMN "honey_token_trigger"."ACTION" IS '触发动作';
+COMMENT ON COLUMN "honey_token_trigger"."DETAILS" IS '详细信息';
+CREATE INDEX "idx_honey_token_trigger_source_ip" ON "honey_token_trigger"("SOURCE_IP");
+CREATE INDEX "idx_honey_token_trigger_token_id" ON "honey_token_trigger"("TOKEN_ID");
+CREATE INDEX "idx_honey_token_trigger_trigger_time" ON "honey_token_trigger"("TRIGGER_TIME");